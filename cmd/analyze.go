@@ -11,9 +11,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/yeisme/taskbridge/internal/history"
 	"github.com/yeisme/taskbridge/internal/model"
 	"github.com/yeisme/taskbridge/internal/storage"
-	"github.com/yeisme/taskbridge/internal/storage/filestore"
 )
 
 var (
@@ -101,7 +101,7 @@ func init() {
 // getTasksForAnalysis 获取用于分析的任务
 func getTasksForAnalysis() ([]model.Task, error) {
 	ctx := context.Background()
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginCLI)
 	if err != nil {
 		return nil, fmt.Errorf("创建存储失败: %w", err)
 	}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
 
 	"github.com/yeisme/taskbridge/internal/provider"
 	"github.com/yeisme/taskbridge/internal/provider/feishu"
@@ -56,17 +57,24 @@ var authLoginCmd = &cobra.Command{
 	Short: "登录指定 Provider",
 	Long: `登录指定的 Todo Provider 进行 OAuth2 认证。
 
+OAuth2 Provider 会在本地启动回调服务并自动在浏览器中打开授权页面，授权完成后 token 保存到凭证存储；
+无图形环境时手动复制终端中的链接即可（或使用 --no-browser）。
+google 与 microsoft 还支持 --device 设备授权流程：终端显示验证地址与用户码，在手机等任意设备上完成授权，
+适用于无浏览器、无法接收本地回调的服务器。
+
 支持的 Provider:
   - google: Google Tasks API
   - microsoft: Microsoft Todo
   - feishu: 飞书任务
-  - ticktick: TickTick（国际）
-  - dida: 滴答清单（国内）
+  - ticktick: TickTick（国际）；存在 OAuth 凭证文件时走 OAuth2 授权码流程，否则输入 API Token
+  - dida: 滴答清单（国内），同 ticktick
   - todoist: Todoist
 
 示例:
   taskbridge auth login google
-  taskbridge auth login google --manual  # 手动输入授权码`,
+  taskbridge auth login google --manual  # 手动输入授权码
+  taskbridge auth login ticktick --no-browser
+  taskbridge auth login microsoft --device  # 在其他设备上授权`,
 	Args: cobra.ExactArgs(1),
 	Run:  runAuthLogin,
 }
@@ -75,10 +83,12 @@ var authLoginCmd = &cobra.Command{
 var authLogoutCmd = &cobra.Command{
 	Use:   "logout <provider>",
 	Short: "登出指定 Provider",
-	Long: `登出指定的 Todo Provider，删除本地存储的 token。
+	Long: `登出指定的 Todo Provider：Provider 支持撤销时（google）先调用撤销端点使服务端授权失效，再删除本地存储的 token。
+撤销失败（如网络不可用）时仍会删除本地 token，并提示手动移除授权的位置。
 
 示例:
-  taskbridge auth logout google`,
+  taskbridge auth logout google
+  taskbridge auth logout google --local  # 只删除本地 token`,
 	Args: cobra.ExactArgs(1),
 	Run:  runAuthLogout,
 }
@@ -87,7 +97,8 @@ var authLogoutCmd = &cobra.Command{
 var authStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "查看所有 Provider 的认证状态",
-	Long: `显示所有已配置 Provider 的认证状态。
+	Long: `显示所有 Provider 的认证状态：是否配置了应用凭证、所属账号、授予的 scope 与 token 过期时间。
+账号与 scope 在登录时记录，之前登录的 Provider 需重新登录后才会显示。
 
 示例:
   taskbridge auth status`,
@@ -122,6 +133,11 @@ var authRefreshCmd = &cobra.Command{
 var (
 	// 登录选项
 	manualAuth bool
+	noBrowser  bool
+	deviceAuth bool
+
+	// 登出选项
+	logoutLocalOnly bool
 )
 
 func init() {
@@ -133,7 +149,12 @@ func init() {
 	authCmd.AddCommand(authRefreshCmd)
 
 	// 登录命令选项
-	authLoginCmd.Flags().BoolVar(&manualAuth, "manual", false, "手动输入授权码（用于无浏览器环境）")
+	authLoginCmd.Flags().BoolVar(&manualAuth, "manual", false, "手动输入授权码（用于无浏览器环境）；ticktick/dida 为手动输入 API Token")
+	authLoginCmd.Flags().BoolVar(&noBrowser, "no-browser", false, "不自动打开浏览器，只在终端输出授权链接")
+	authLoginCmd.Flags().BoolVar(&deviceAuth, "device", false, "使用设备授权流程，在手机等其他设备上完成授权（google、microsoft）")
+
+	// 登出命令选项
+	authLogoutCmd.Flags().BoolVar(&logoutLocalOnly, "local", false, "只删除本地 token，不调用 Provider 的撤销端点")
 }
 
 // runAuthLogin 执行登录
@@ -148,6 +169,11 @@ func runAuthLogin(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if deviceAuth && providerName != "google" && providerName != "microsoft" {
+		fmt.Printf("❌ %s 不支持设备授权流程，--device 仅适用于 google 与 microsoft\n", providerName)
+		os.Exit(1)
+	}
+
 	switch providerName {
 	case "google":
 		loginGoogle()
@@ -188,7 +214,17 @@ func loginGoogle() {
 		fmt.Println("3. 配置 OAuth2 同意屏幕")
 		fmt.Println("4. 创建 OAuth2 凭证（桌面应用）")
 		fmt.Printf("5. 下载凭证文件并保存到: %s\n", credentialsPath)
+		fmt.Println(`   也可以只写入 client ID，授权码由 PKCE 保护: {"client_id": "xxx.apps.googleusercontent.com"}`)
+		os.Exit(1)
+	}
+
+	// 服务账号密钥无需交互式登录，只校验能否代为访问配置的用户
+	if serviceAccount, err := loadGoogleServiceAccount(); err != nil {
+		fmt.Printf("❌ 加载服务账号密钥失败: %v\n", err)
 		os.Exit(1)
+	} else if serviceAccount != nil {
+		verifyGoogleServiceAccount(serviceAccount)
+		return
 	}
 
 	// 加载凭证
@@ -201,8 +237,26 @@ func loginGoogle() {
 	// 设置 token 文件路径
 	tokenPath := paths.GetTokenPath("google")
 	client.SetTokenFile(tokenPath)
+	client.SetScopes(adapterScopes("google", google.ReadOnlyScopes))
 
-	if manualAuth {
+	if deviceAuth {
+		ctx, cancel := context.WithTimeout(context.Background(), deviceLoginTimeout)
+		defer cancel()
+
+		token, err := client.DeviceLogin(ctx, printDeviceCode)
+		if err != nil {
+			fmt.Printf("❌ 设备授权失败: %v\n", err)
+			fmt.Println("Google 设备授权需要“电视和受限输入设备”类型的 OAuth 客户端；若提示 invalid_scope，请改用: taskbridge auth login google --manual")
+			os.Exit(1)
+		}
+		if err := client.SaveToken(token); err != nil {
+			fmt.Printf("❌ 保存 token 失败: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println("\n✅ Google Tasks 认证成功!")
+		fmt.Printf("📁 Token 已保存到: %s\n", tokenPath)
+	} else if manualAuth {
 		// 生成授权 URL
 		state := fmt.Sprintf("taskbridge-%d", time.Now().Unix())
 		authURL := client.GetAuthURL(state)
@@ -247,6 +301,7 @@ func loginGoogle() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
 
+		client.SetOpenBrowser(!noBrowser)
 		token, err := client.StartAuthServer(ctx, 0)
 		if err != nil {
 			fmt.Printf("❌ 自动认证失败: %v\n", err)
@@ -261,6 +316,29 @@ func loginGoogle() {
 			fmt.Printf("⏰ 过期时间: %s\n", token.Expiry.Format("2006-01-02 15:04:05"))
 		}
 	}
+	// 账号邮箱取自登录返回的 ID token，凭证未包含 openid/email scope 时为空
+	if grant, err := tokenstore.LoadGrant(tokenPath, "google"); err == nil && grant.Account != "" {
+		fmt.Printf("👤 账号: %s\n", grant.Account)
+	}
+}
+
+// deviceLoginTimeout 设备授权的最长等待时间，设备码通常在 15 分钟后失效
+const deviceLoginTimeout = 20 * time.Minute
+
+// printDeviceCode 输出设备授权的验证地址与用户码
+func printDeviceCode(resp *oauth2.DeviceAuthResponse) {
+	fmt.Println("\n📱 请在手机或其他设备的浏览器中打开:")
+	fmt.Println()
+	fmt.Printf("   %s\n", resp.VerificationURI)
+	fmt.Println()
+	fmt.Printf("并输入代码: %s\n", resp.UserCode)
+	if resp.VerificationURIComplete != "" {
+		fmt.Printf("（或直接打开: %s）\n", resp.VerificationURIComplete)
+	}
+	if !resp.Expiry.IsZero() {
+		fmt.Printf("代码将于 %s 失效\n", resp.Expiry.Format("15:04:05"))
+	}
+	fmt.Println("\n等待授权...")
 }
 
 func extractGoogleAuthCode(input string) (string, error) {
@@ -338,6 +416,9 @@ func runAuthLogout(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if !logoutLocalOnly {
+		revokeProviderToken(providerName, tokenPath)
+	}
 	if err := tokenstore.Delete(tokenPath, providerName); err != nil {
 		fmt.Printf("❌ 登出失败: %v\n", err)
 		os.Exit(1)
@@ -346,6 +427,31 @@ func runAuthLogout(cmd *cobra.Command, args []string) {
 	fmt.Printf("✅ %s 已登出\n", providerName)
 }
 
+// revokeProviderToken 在 Provider 提供撤销端点时使服务端授权失效；撤销失败只提示，本地 token 仍会删除。
+// 不支持撤销的 Provider 提示在账户设置中手动移除授权
+func revokeProviderToken(providerName, tokenPath string) {
+	switch providerName {
+	case "google":
+		client := google.NewOAuth2Client(&google.OAuthConfig{TokenFile: tokenPath})
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := client.RevokeToken(ctx); err != nil {
+			fmt.Printf("⚠️ 撤销 Google 授权失败，仅删除本地 token: %v\n", err)
+			fmt.Println("   可在 https://myaccount.google.com/permissions 中手动移除授权")
+			return
+		}
+		fmt.Println("🔒 已在 Google 端撤销授权")
+	case "microsoft":
+		fmt.Println("ℹ️ Microsoft 不提供单个 token 的撤销接口，refresh token 在过期前仍然有效；")
+		fmt.Println("   如需立即失效，请在 https://account.live.com/consent/Manage（个人账户）或 https://myapps.microsoft.com（工作/学校账户）中移除应用授权")
+	case "todoist":
+		fmt.Println("ℹ️ Todoist API Token 无法通过接口撤销，如需失效请在 Todoist 设置 > 集成 > 开发者 中重新生成")
+	default:
+		def, _ := provider.GetProviderDefinition(providerName)
+		fmt.Printf("ℹ️ %s 不提供 token 撤销接口，仅删除本地 token\n", def.DisplayName)
+	}
+}
+
 // runAuthStatus 执行状态查询
 func runAuthStatus(cmd *cobra.Command, args []string) {
 	printAuthStatusTable()
@@ -386,6 +492,9 @@ func runAuthShow(cmd *cobra.Command, args []string) {
 		"Token 有效": valid,
 		"过期时间":     snapshot.ExpiresAt,
 		"建议操作":     snapshot.NextAction,
+		"应用凭证":     orDash(snapshot.Credentials),
+		"账号":       orDash(snapshot.Account),
+		"Scopes":   orDash(strings.Join(snapshot.Scopes, " ")),
 	}
 
 	fmt.Println()
@@ -424,8 +533,63 @@ func runAuthRefresh(cmd *cobra.Command, args []string) {
 	}
 }
 
+// googleImpersonateSubject 返回 adapters.google.impersonate 配置的被代为访问用户
+func googleImpersonateSubject() string {
+	if cfg == nil {
+		return ""
+	}
+	return strings.TrimSpace(cfg.Adapters.Get("google").Impersonate)
+}
+
+// adapterScopes 返回登录时申请的 scope：优先使用 adapters.<name>.scopes，
+// 开启 read_only 时使用只读 scope，都未配置时返回 nil 沿用适配器默认值
+func adapterScopes(name string, readOnlyScopes []string) []string {
+	if cfg == nil {
+		return nil
+	}
+	adapter := cfg.Adapters.Get(name)
+	if len(adapter.Scopes) > 0 {
+		return adapter.Scopes
+	}
+	if adapter.ReadOnly {
+		return readOnlyScopes
+	}
+	return nil
+}
+
+// loadGoogleServiceAccount 凭证文件为服务账号密钥时加载服务账号，否则返回 nil
+func loadGoogleServiceAccount() (*google.ServiceAccount, error) {
+	data, err := os.ReadFile(paths.GetCredentialsPath("google"))
+	if err != nil || !google.IsServiceAccountKey(data) {
+		return nil, nil
+	}
+	return google.NewServiceAccount(data, googleImpersonateSubject())
+}
+
+// verifyGoogleServiceAccount 使用服务账号换取一次 token，确认全域委派已生效
+func verifyGoogleServiceAccount(serviceAccount *google.ServiceAccount) {
+	fmt.Printf("🤖 使用服务账号 %s 代为访问 %s\n", serviceAccount.Email(), serviceAccount.Subject())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := serviceAccount.ForceRefresh(ctx); err != nil {
+		fmt.Printf("❌ 获取服务账号 token 失败: %v\n", err)
+		fmt.Println("请确认已在 Google Workspace 管理控制台为该服务账号的客户端 ID 开启全域委派，并登记 scope: " + google.ScopeTasks)
+		os.Exit(1)
+	}
+	fmt.Println("✅ 服务账号认证成功，无需登录，token 会在每次使用时自动签发")
+}
+
 // refreshGoogleToken 刷新 Google token
 func refreshGoogleToken() {
+	if serviceAccount, err := loadGoogleServiceAccount(); err != nil {
+		fmt.Printf("❌ 加载服务账号密钥失败: %v\n", err)
+		os.Exit(1)
+	} else if serviceAccount != nil {
+		verifyGoogleServiceAccount(serviceAccount)
+		return
+	}
+
 	client, err := google.NewOAuth2ClientFromHome()
 	if err != nil {
 		fmt.Printf("❌ 加载 Google OAuth2 客户端失败: %v\n", err)
@@ -465,9 +629,9 @@ func loginMicrosoft() {
 		fmt.Println("2. 注册应用程序（Azure Active Directory）")
 		fmt.Println("3. 配置重定向 URI: http://localhost:8080/callback")
 		fmt.Println("4. 添加 API 权限: Tasks.ReadWrite, User.Read")
-		fmt.Println("5. 创建客户端密钥")
+		fmt.Println("5. 创建客户端密钥；或将重定向 URI 登记在“移动和桌面应用程序”平台下并开启“允许公共客户端流”，以 PKCE 登录无需密钥")
 		fmt.Printf("6. 创建凭证文件并保存到: %s\n", credentialsPath)
-		fmt.Println("\n凭证文件格式:")
+		fmt.Println("\n凭证文件格式（公共客户端省略 client_secret）:")
 		fmt.Println(`{
 	 "client_id": "你的应用ID",
 	 "client_secret": "你的客户端密钥",
@@ -487,12 +651,26 @@ func loginMicrosoft() {
 	// 设置 token 文件路径
 	tokenPath := paths.GetTokenPath("microsoft")
 	oauthClient.SetTokenFile(tokenPath)
+	oauthClient.SetScopes(adapterScopes("microsoft", microsoft.ReadOnlyScopes))
 
-	// 启动认证服务器
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+	var token *oauth2.Token
+	if deviceAuth {
+		ctx, cancel := context.WithTimeout(context.Background(), deviceLoginTimeout)
+		defer cancel()
+		token, err = oauthClient.DeviceLogin(ctx, printDeviceCode)
+		if err != nil {
+			fmt.Printf("❌ 设备授权失败: %v\n", err)
+			fmt.Println("请确认 Azure 应用已在“身份验证”中开启“允许公共客户端流”。")
+			os.Exit(1)
+		}
+	} else {
+		// 启动认证服务器
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
 
-	token, err := oauthClient.StartAuthServer(ctx, 8080)
+		oauthClient.SetOpenBrowser(!noBrowser)
+		token, err = oauthClient.StartAuthServer(ctx, 8080)
+	}
 	if err != nil {
 		fmt.Printf("❌ 认证失败: %v\n", err)
 		os.Exit(1)
@@ -501,6 +679,18 @@ func loginMicrosoft() {
 	fmt.Println("\n✅ Microsoft To Do 认证成功!")
 	fmt.Printf("📁 Token 已保存到: %s\n", tokenPath)
 	fmt.Printf("🔑 Token 类型: %s\n", token.TokenType)
+	printLoginAccount(oauthClient.Account(context.Background()))
+}
+
+// printLoginAccount 输出登录后查询到的账号；查询失败只提示，不影响登录结果
+func printLoginAccount(account string, err error) {
+	if err != nil {
+		fmt.Printf("⚠️ 获取账号信息失败: %v\n", err)
+		return
+	}
+	if account != "" {
+		fmt.Printf("👤 账号: %s\n", account)
+	}
 }
 
 // refreshMicrosoftToken 刷新 Microsoft token
@@ -573,11 +763,13 @@ func loginFeishu() {
 
 	tokenPath := paths.GetTokenPath("feishu")
 	oauthClient.SetTokenFile(tokenPath)
+	oauthClient.SetScopes(adapterScopes("feishu", feishu.ReadOnlyScopes))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
 	// 端口由凭证中的 redirect_url 决定（不强制使用 8080）
+	oauthClient.SetOpenBrowser(!noBrowser)
 	token, err := oauthClient.StartAuthServer(ctx, 0)
 	if err != nil {
 		fmt.Printf("❌ 认证失败: %v\n", err)
@@ -587,6 +779,7 @@ func loginFeishu() {
 	fmt.Println("\n✅ 飞书 Todo 认证成功!")
 	fmt.Printf("📁 Token 已保存到: %s\n", tokenPath)
 	fmt.Printf("🔑 Token 类型: %s\n", token.TokenType)
+	printLoginAccount(oauthClient.Account(ctx))
 }
 
 // refreshFeishuToken 刷新飞书 token
@@ -638,14 +831,21 @@ func loginTickStyleProvider(providerName string) {
 		tokenHint = "dp_"
 	}
 
-	fmt.Printf("🔐 开始 %s API Token 认证...\n", displayName)
-
 	if err := paths.EnsureCredentialsDir(); err != nil {
 		fmt.Printf("❌ 创建凭证目录失败: %v\n", err)
 		os.Exit(1)
 	}
 
+	// 存在 OpenAPI 应用凭证时走 OAuth2 授权码流程，否则回退到手动输入 API Token
+	credentialsPath := paths.GetCredentialsPath(providerName)
+	if _, err := os.Stat(credentialsPath); err == nil && !manualAuth {
+		loginTickStyleOAuth(providerName, displayName, credentialsPath)
+		return
+	}
+
+	fmt.Printf("🔐 开始 %s API Token 认证...\n", displayName)
 	tokenPath := paths.GetTokenPath(providerName)
+	fmt.Printf("\n也可在开发者平台创建 OpenAPI 应用并将凭证保存到 %s，改用浏览器授权登录（见 taskbridge auth login --help）\n", credentialsPath)
 	fmt.Printf("\n请按以下步骤获取 %s API Token:\n", displayName)
 	if providerName == "dida" {
 		fmt.Println("1. 打开 dida365.com 并登录开发者平台或 OpenAPI 管理页")
@@ -690,6 +890,64 @@ func loginTickStyleProvider(providerName string) {
 	fmt.Printf("📁 Token 已保存到: %s\n", tokenPath)
 }
 
+// loginTickStyleOAuth 通过 OpenAPI 的 OAuth2 授权码流程登录 TickTick / 滴答清单
+func loginTickStyleOAuth(providerName, displayName, credentialsPath string) {
+	fmt.Printf("🔐 开始 %s OAuth2 认证...\n", displayName)
+
+	oauthClient, err := ticktick.LoadCredentials(credentialsPath, providerName)
+	if err != nil {
+		fmt.Printf("❌ 加载凭证失败: %v\n", err)
+		fmt.Println("\n凭证文件格式（redirect_url 需与开发者平台中登记的 OAuth redirect URL 一致）:")
+		fmt.Printf(`{
+  "client_id": "你的 Client ID",
+  "client_secret": "你的 Client Secret",
+  "redirect_url": "%s"
+}
+`, ticktick.DefaultRedirectURL)
+		os.Exit(1)
+	}
+
+	oauthClient.SetScopes(adapterScopes(providerName, ticktick.ReadOnlyScopes))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	oauthClient.SetOpenBrowser(!noBrowser)
+	token, err := oauthClient.StartAuthServer(ctx)
+	if err != nil {
+		fmt.Printf("❌ 认证失败: %v\n", err)
+		fmt.Printf("可改用 API Token 登录: taskbridge auth login %s --manual\n", providerName)
+		os.Exit(1)
+	}
+
+	// 通过 Provider 校验 token 并写入凭证存储
+	tokenPath := paths.GetTokenPath(providerName)
+	p, err := ticktick.NewProvider(ticktick.Config{ProviderName: providerName, TokenFile: tokenPath})
+	if err != nil {
+		fmt.Printf("❌ 初始化 %s Provider 失败: %v\n", displayName, err)
+		os.Exit(1)
+	}
+	if err := p.Authenticate(ctx, map[string]interface{}{
+		"token":    token.AccessToken,
+		"provider": providerName,
+	}); err != nil {
+		fmt.Printf("❌ %s 认证失败: %v\n", displayName, err)
+		os.Exit(1)
+	}
+
+	// Provider 只保存 access token，授予的 scope 与过期时间另行记录供 auth status 显示
+	scope, _ := token.Extra("scope").(string)
+	if err := tokenstore.SaveGrant(tokenPath, providerName, tokenstore.Grant{Scopes: strings.Fields(scope), Expiry: token.Expiry}); err != nil {
+		fmt.Printf("⚠️ 记录授权信息失败: %v\n", err)
+	}
+
+	fmt.Printf("\n✅ %s 认证成功!\n", displayName)
+	fmt.Printf("📁 Token 已保存到: %s\n", tokenPath)
+	if !token.Expiry.IsZero() {
+		fmt.Printf("⏰ 过期时间: %s（到期后需重新登录）\n", token.Expiry.Format("2006-01-02 15:04:05"))
+	}
+}
+
 // refreshTickTickToken 刷新 TickTick token（静态 token）
 func refreshTickTickToken() {
 	refreshTickStyleProvider("ticktick")
@@ -792,6 +1050,12 @@ type AuthSnapshot struct {
 	StatusText    string
 	ExpiresAt     string
 	NextAction    string
+	// Credentials 应用凭证来源（凭证文件、配置或 API Token），未配置时为空
+	Credentials string
+	// Account 登录时记录的所属账号，未知时为空
+	Account string
+	// Scopes 登录时实际授予的权限范围
+	Scopes []string
 }
 
 type providerMeta struct {
@@ -825,22 +1089,7 @@ func boolPtr(v bool) *bool {
 }
 
 func isProviderEnabled(providerName string) bool {
-	switch providerName {
-	case "google":
-		return cfg.Providers.Google.Enabled
-	case "microsoft":
-		return cfg.Providers.Microsoft.Enabled
-	case "feishu":
-		return cfg.Providers.Feishu.Enabled
-	case "ticktick":
-		return cfg.Providers.TickTick.Enabled
-	case "dida":
-		return cfg.Providers.Dida.Enabled
-	case "todoist":
-		return cfg.Providers.Todoist.Enabled
-	default:
-		return false
-	}
+	return cfg.Adapters.Enabled(providerName)
 }
 
 func getProviderAuthSnapshot(providerName string) AuthSnapshot {
@@ -855,6 +1104,24 @@ func getProviderAuthSnapshot(providerName string) AuthSnapshot {
 		StatusText:    "❌ Not configured",
 		ExpiresAt:     "-",
 		NextAction:    fmt.Sprintf("taskbridge auth login %s", meta.Name),
+		Credentials:   credentialsSource(meta.Name),
+	}
+
+	if meta.Name == "google" {
+		if serviceAccount, err := loadGoogleServiceAccount(); err != nil {
+			snapshot.StatusText = "⚠️ Service account error"
+			snapshot.NextAction = err.Error()
+			return snapshot
+		} else if serviceAccount != nil {
+			// 服务账号不保存 token，使用时按需签发
+			snapshot.Authenticated = true
+			snapshot.StatusText = "✅ Service account"
+			snapshot.Credentials = "服务账号 " + serviceAccount.Email()
+			snapshot.Account = serviceAccount.Subject()
+			snapshot.Scopes = []string{google.ScopeTasks}
+			snapshot.NextAction = "taskbridge auth refresh google"
+			return snapshot
+		}
 	}
 
 	hasToken, err := tokenstore.Has(snapshot.TokenPath, meta.Name)
@@ -874,14 +1141,22 @@ func getProviderAuthSnapshot(providerName string) AuthSnapshot {
 	snapshot.Authenticated = true
 	snapshot.StatusText = "✅ Connected"
 	snapshot.NextAction = fmt.Sprintf("taskbridge auth logout %s", meta.Name)
+	if snapshot.Credentials == "" && isTokenCredentialProvider(meta.Name) {
+		snapshot.Credentials = "API Token"
+	}
+
+	grant, err := tokenstore.LoadGrant(snapshot.TokenPath, meta.Name)
+	if err == nil {
+		snapshot.Account = grant.Account
+		snapshot.Scopes = grant.Scopes
+	}
 
 	switch meta.Name {
 	case "google":
 		client, err := google.NewOAuth2ClientFromHome()
 		if err != nil {
-			// Google 客户端加载失败时，保守认为已连接但无法判定有效性
-			snapshot.Valid = nil
-			snapshot.ExpiresAt = "有效"
+			// Google 客户端加载失败时，按 token 中记录的过期时间判断
+			applyGrantExpiry(&snapshot, grant.Expiry, "taskbridge auth refresh google")
 			return snapshot
 		}
 		info := client.GetTokenInfo()
@@ -892,7 +1167,7 @@ func getProviderAuthSnapshot(providerName string) AuthSnapshot {
 		}
 		if info.Valid {
 			snapshot.Valid = boolPtr(true)
-			snapshot.ExpiresAt = info.Expiry.Format("2006-01-02")
+			snapshot.ExpiresAt = info.Expiry.Format(authExpiryLayout)
 			return snapshot
 		}
 		snapshot.Valid = boolPtr(false)
@@ -901,42 +1176,85 @@ func getProviderAuthSnapshot(providerName string) AuthSnapshot {
 		snapshot.NextAction = "taskbridge auth refresh google"
 		return snapshot
 	case "microsoft":
-		credentialsPath := paths.GetCredentialsPath("microsoft")
-		oauthClient, err := microsoft.LoadCredentials(credentialsPath)
-		if err != nil {
-			snapshot.Valid = nil
-			snapshot.ExpiresAt = "有效"
-			return snapshot
-		}
-		oauthClient.SetTokenFile(snapshot.TokenPath)
-		if err := oauthClient.LoadToken(); err != nil {
-			snapshot.Valid = nil
-			snapshot.ExpiresAt = "有效"
-			return snapshot
-		}
-		info := oauthClient.GetTokenInfo()
-		if expiry, ok := info["expiry"].(time.Time); ok && !expiry.IsZero() {
-			snapshot.ExpiresAt = expiry.Format("2006-01-02")
-			now := time.Now()
-			if expiry.After(now) {
-				snapshot.Valid = boolPtr(true)
-			} else {
-				snapshot.Valid = boolPtr(false)
-				snapshot.StatusText = "⚠️ Expired"
-				snapshot.NextAction = "taskbridge auth refresh microsoft"
-			}
-			return snapshot
-		}
-		snapshot.Valid = nil
-		snapshot.ExpiresAt = "有效"
+		// 过期时间读取自 token 文件，凭证文件缺失（如凭证写在配置中）时同样可以判断
+		applyGrantExpiry(&snapshot, grant.Expiry, "taskbridge auth refresh microsoft")
 		return snapshot
 	default:
+		// 飞书与 TickTick 等 token 不带 expiry 字段，登录时记录了过期时间则据此判断
+		applyGrantExpiry(&snapshot, grant.Expiry, fmt.Sprintf("taskbridge auth login %s", meta.Name))
+		return snapshot
+	}
+}
+
+// applyGrantExpiry 按 token 中记录的过期时间设置有效性；没有过期时间（静态 API Token 等）时视为有效
+func applyGrantExpiry(snapshot *AuthSnapshot, expiry time.Time, renewAction string) {
+	if expiry.IsZero() {
 		snapshot.Valid = nil
 		snapshot.ExpiresAt = "有效"
-		return snapshot
+		return
+	}
+	snapshot.ExpiresAt = expiry.Local().Format(authExpiryLayout)
+	if expiry.After(time.Now()) {
+		snapshot.Valid = boolPtr(true)
+		return
+	}
+	snapshot.Valid = boolPtr(false)
+	snapshot.StatusText = "⚠️ Expired"
+	snapshot.NextAction = renewAction
+}
+
+// authExpiryLayout 认证状态中过期时间的显示格式
+const authExpiryLayout = "2006-01-02 15:04"
+
+// credentialsSource 返回 Provider 应用凭证的来源：凭证目录中的凭证文件，或配置中的 client_id、app_id、api_token 等；未配置时返回空
+func credentialsSource(providerName string) string {
+	if _, err := os.Stat(paths.GetCredentialsPath(providerName)); err == nil {
+		return "凭证文件"
+	}
+	if cfg == nil {
+		return ""
+	}
+	adapter := cfg.Adapters.Get(providerName)
+	for _, value := range []string{adapter.ClientID, adapter.AppID, adapter.APIToken, adapter.APIKey, adapter.CredentialsFile} {
+		if strings.TrimSpace(value) != "" {
+			return "配置"
+		}
+	}
+	return ""
+}
+
+// isTokenCredentialProvider 可直接使用 API Token 登录、无需应用凭证的 Provider
+func isTokenCredentialProvider(providerName string) bool {
+	switch providerName {
+	case "ticktick", "dida", "todoist":
+		return true
+	default:
+		return false
 	}
 }
 
+// formatScopes 去掉 Google 与 Microsoft Graph scope 的 URL 前缀，便于在表格中显示
+func formatScopes(scopes []string) string {
+	if len(scopes) == 0 {
+		return "-"
+	}
+	short := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimPrefix(scope, "https://www.googleapis.com/auth/")
+		scope = strings.TrimPrefix(scope, "https://graph.microsoft.com/")
+		short = append(short, scope)
+	}
+	return strings.Join(short, " ")
+}
+
+// orDash 空字符串显示为 -
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
 // getProviderStatus 获取 Provider 认证状态
 // 优先检查 token 文件是否存在，而不是配置中的 Enabled 状态
 func getProviderStatus(providerName string) (status, user, expires string) {
@@ -951,11 +1269,15 @@ func getProviderStatus(providerName string) (status, user, expires string) {
 // printAuthStatusTable 打印认证状态表格
 func printAuthStatusTable() {
 	// 使用 lipgloss table 组件
-	table := ui.NewTable("Provider", "简写", "状态", "Expires")
+	table := ui.NewTable("Provider", "简写", "状态", "凭证", "账号", "Scopes", "Expires")
 
 	for _, p := range getAuthProviderOrder() {
 		snapshot := getProviderAuthSnapshot(p)
-		table.AddRow(snapshot.DisplayName, snapshot.ShortName, snapshot.StatusText, snapshot.ExpiresAt)
+		credentials := snapshot.Credentials
+		if credentials == "" {
+			credentials = "❌ 未配置"
+		}
+		table.AddRow(snapshot.DisplayName, snapshot.ShortName, snapshot.StatusText, credentials, orDash(snapshot.Account), formatScopes(snapshot.Scopes), snapshot.ExpiresAt)
 	}
 
 	fmt.Println()
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yeisme/taskbridge/internal/backup"
+	"github.com/yeisme/taskbridge/pkg/buildinfo"
+	"github.com/yeisme/taskbridge/pkg/paths"
+)
+
+// backupCmd 备份命令
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "备份与恢复本地状态",
+	Long: `将当前 profile 的本地状态打包为单个 tar.gz 归档，或在另一台机器上从归档恢复。
+
+归档包含:
+  data         storage.path 下的全部文件：任务缓存、镜像 ID 映射与同步日志、项目、模板、
+               时间记录、离线写入队列等
+  cache        缓存目录（~/.taskbridge/cache）
+  credentials  凭证目录中的 token 与 OAuth 凭证，仅在指定 --include-credentials 时包含
+
+配置文件不在归档中，请单独复制；secrets.backend 不是 file 时 token 保存在对应后端，也不在归档中。
+
+子命令:
+  create   创建备份
+  restore  从备份恢复
+  show     显示备份的内容
+
+示例:
+  taskbridge backup create
+  taskbridge backup create -o taskbridge.tar.gz --include-credentials
+  taskbridge backup show taskbridge.tar.gz
+  taskbridge backup restore taskbridge.tar.gz`,
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "创建备份",
+	Long: `将本地状态写入 tar.gz 归档，默认为当前目录下的 taskbridge-backup-<profile>-<时间>.tar.gz。
+备份前请停止正在运行的 mcp start、sync start 等后台进程，以免打包到写入一半的文件。`,
+	Args: cobra.NoArgs,
+	Run:  runBackupCreate,
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <archive>",
+	Short: "从备份恢复",
+	Long: `将归档中的本地状态写回当前 profile 的数据、缓存与凭证目录（数据目录为当前的 storage.path）。
+目标目录已有文件时拒绝恢复，--force 覆盖同名文件（目录中归档没有的文件保留）。
+恢复前请停止正在运行的 mcp start、sync start 等后台进程。`,
+	Args: cobra.ExactArgs(1),
+	Run:  runBackupRestore,
+}
+
+var backupShowCmd = &cobra.Command{
+	Use:   "show <archive>",
+	Short: "显示备份的内容",
+	Args:  cobra.ExactArgs(1),
+	Run:   runBackupShow,
+}
+
+var (
+	backupOutput             string
+	backupIncludeCredentials bool
+	backupForce              bool
+)
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+	backupCmd.AddCommand(backupShowCmd)
+
+	backupCreateCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "归档文件路径")
+	backupCreateCmd.Flags().BoolVar(&backupIncludeCredentials, "include-credentials", false, "同时备份凭证目录中的 token（归档需妥善保管）")
+	backupRestoreCmd.Flags().BoolVar(&backupForce, "force", false, "目标目录已有文件时覆盖同名文件")
+}
+
+// backupSections 返回当前 profile 参与备份与恢复的目录
+func backupSections(includeCredentials bool) []backup.Section {
+	sections := []backup.Section{
+		{Name: "data", Dir: cfg.Storage.Path},
+		{Name: "cache", Dir: paths.GetCacheDir()},
+	}
+	if includeCredentials {
+		sections = append(sections, backup.Section{Name: "credentials", Dir: paths.GetCredentialsDir()})
+	}
+	return sections
+}
+
+func runBackupCreate(cmd *cobra.Command, args []string) {
+	output := backupOutput
+	if output == "" {
+		output = fmt.Sprintf("taskbridge-backup-%s-%s.tar.gz", currentProfileName(), time.Now().Format("20060102-150405"))
+	}
+	sections := backupSections(backupIncludeCredentials)
+	absOutput, _ := filepath.Abs(output)
+	for _, section := range sections {
+		if dir, err := filepath.Abs(section.Dir); err == nil && strings.HasPrefix(absOutput, dir+string(filepath.Separator)) {
+			fmt.Printf("❌ 归档不能写入被备份的目录: %s\n", section.Dir)
+			os.Exit(1)
+		}
+	}
+	if cfg.Storage.Type != "" && cfg.Storage.Type != "file" && cfg.Storage.Type != "sqlite" && cfg.Storage.Type != "bolt" {
+		fmt.Printf("⚠️ storage.type 为 %s，任务保存在数据库中，不在归档中\n", cfg.Storage.Type)
+	}
+
+	tmp := output + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		fmt.Printf("❌ 创建归档失败: %v\n", err)
+		os.Exit(1)
+	}
+	manifest, err := backup.Create(file, sections, backup.Manifest{AppVersion: buildinfo.Version, Profile: currentProfileName()})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, output)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		fmt.Printf("❌ 创建备份失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ 已创建备份: %s\n", output)
+	printBackupSections(manifest)
+	if backupIncludeCredentials {
+		fmt.Println("   归档包含凭证，请妥善保管")
+	}
+}
+
+func runBackupRestore(cmd *cobra.Command, args []string) {
+	file, err := os.Open(args[0])
+	if err != nil {
+		fmt.Printf("❌ 打开归档失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = file.Close() }()
+
+	manifest, err := backup.Restore(file, backupSections(true), backupForce)
+	if errors.Is(err, backup.ErrTargetNotEmpty) {
+		fmt.Printf("❌ %v\n", err)
+		fmt.Println("   目标目录已有数据，确认覆盖请使用 --force")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("❌ 恢复失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ 已从 %s 恢复（profile %s，创建于 %s）\n", args[0], manifest.Profile, manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	printBackupSections(manifest)
+	fmt.Printf("   数据目录: %s\n", cfg.Storage.Path)
+	if _, ok := manifest.Section("credentials"); !ok {
+		fmt.Println("   归档不含凭证，请重新执行 taskbridge auth login 登录各 Provider")
+	}
+}
+
+func runBackupShow(cmd *cobra.Command, args []string) {
+	file, err := os.Open(args[0])
+	if err != nil {
+		fmt.Printf("❌ 打开归档失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = file.Close() }()
+
+	manifest, err := backup.ReadManifest(file)
+	if err != nil {
+		fmt.Printf("❌ 读取归档失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Profile:  %s\n", manifest.Profile)
+	fmt.Printf("版本:     %s\n", manifest.AppVersion)
+	fmt.Printf("创建时间: %s\n", manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	printBackupSections(manifest)
+}
+
+func printBackupSections(manifest *backup.Manifest) {
+	for _, section := range manifest.Sections {
+		fmt.Printf("   %-12s %d 个文件，%.1f KB\n", section.Name, section.Files, float64(section.Bytes)/1024)
+	}
+}
@@ -4,34 +4,49 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
+
+	"github.com/yeisme/taskbridge/pkg/buildinfo"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+	"github.com/yeisme/taskbridge/pkg/paths"
 )
 
 var (
 	configShowSensitive bool
 	configFormat        string
+	configInitOutput    string
+	configDumpRedact    bool
 )
 
 // configCmd 配置命令
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "配置管理",
-	Long: `管理 TaskBridge 运行配置（已迁移到环境变量和命令行参数）。
+	Long: `管理 TaskBridge 运行配置（配置文件、环境变量与命令行参数）。
+
+show/get 读取合并后的生效配置；set/unset 修改配置文件（--config 指定的文件、
+已加载的配置文件或默认的 ~/.taskbridge/config.yaml），环境变量与命令行参数仍优先于文件。
 
 子命令:
-  show     显示当前配置
-  set      设置配置项（已弃用）
+  show     显示当前生效配置（敏感信息已隐藏）
+  dump     输出带来源标注的生效配置，用于附在问题报告中
   get      获取配置项
-  init     初始化配置文件（已弃用）
-  validate 验证配置
+  set      将配置项写入配置文件
+  unset    从配置文件中删除配置项，恢复默认值
+  init     初始化配置文件
+  validate 验证配置与配置文件
+  schema   输出配置文件的 JSON Schema
 
 示例:
   taskbridge config show
   taskbridge config set storage.path ./mydata
-  taskbridge config get providers.google.enabled
+  taskbridge config get adapters.google.enabled
+  taskbridge config unset mcp.port
   taskbridge config init`,
 }
 
@@ -39,20 +54,38 @@ var configCmd = &cobra.Command{
 var configShowCmd = &cobra.Command{
 	Use:   "show",
 	Short: "显示当前配置",
-	Long:  `显示当前加载的配置信息`,
+	Long:  `显示合并默认值、配置文件、环境变量与命令行参数后的生效配置。token、secret、password 等敏感值默认以 ****** 显示。`,
 	Run:   runConfigShow,
 }
 
+// configDumpCmd 导出配置
+var configDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "导出带来源标注的生效配置",
+	Long: `输出合并默认值、配置文件、环境变量与命令行参数后的完整生效配置，并标注每个配置项的来源
+（配置文件路径、环境变量名或命令行参数），附带版本与平台信息，适合附在问题报告中。
+
+--redact（默认开启）遮盖 token、secret、password 等敏感值以及由 secret: 引用解析出的值；
+--redact=false 输出原值，请勿公开。
+
+示例:
+  taskbridge config dump --redact > taskbridge-config.yaml
+  taskbridge config dump --format json`,
+	Run: runConfigDump,
+}
+
 // configSetCmd 设置配置
 var configSetCmd = &cobra.Command{
 	Use:   "set <key> <value>",
 	Short: "设置配置项",
-	Long: `设置指定的配置项。
+	Long: `将配置项写入配置文件。值按配置项类型解析：列表用逗号分隔，时长如 10m。
+写入前会校验修改后的配置，校验失败时不修改文件。
 
 示例:
   taskbridge config set storage.path ./mydata
-  taskbridge config set providers.google.enabled true
-  taskbridge config set sync.interval 10m`,
+  taskbridge config set adapters.google.enabled true
+  taskbridge config set sync.interval 10m
+  taskbridge config set mcp.cors.allowed_origins https://a.example.com,https://b.example.com`,
 	Args: cobra.ExactArgs(2),
 	Run:  runConfigSet,
 }
@@ -61,20 +94,33 @@ var configSetCmd = &cobra.Command{
 var configGetCmd = &cobra.Command{
 	Use:   "get <key>",
 	Short: "获取配置项",
-	Long: `获取指定配置项的值。
+	Long: `获取生效配置中指定配置项的值，键也可以是整个配置段（如 mcp.security）。
 
 示例:
   taskbridge config get storage.path
-  taskbridge config get providers.google.enabled`,
+  taskbridge config get adapters.google.enabled
+  taskbridge config get mcp.security --sensitive`,
 	Args: cobra.ExactArgs(1),
 	Run:  runConfigGet,
 }
 
+// configUnsetCmd 删除配置
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "删除配置项",
+	Long: `从配置文件中删除指定配置项，使其恢复为默认值。
+
+示例:
+  taskbridge config unset mcp.port`,
+	Args: cobra.ExactArgs(1),
+	Run:  runConfigUnset,
+}
+
 // configInitCmd 初始化配置
 var configInitCmd = &cobra.Command{
 	Use:   "init",
 	Short: "初始化配置文件",
-	Long:  `在当前目录或指定位置创建默认配置文件`,
+	Long:  `在 --output 指定位置（默认 ~/.taskbridge/config.yaml）创建包含全部默认值的配置文件，已存在时不覆盖。`,
 	Run:   runConfigInit,
 }
 
@@ -82,183 +128,297 @@ var configInitCmd = &cobra.Command{
 var configValidateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "验证配置",
-	Long:  `验证当前配置是否有效`,
+	Long:  `验证当前生效配置是否有效，并按 JSON Schema 检查配置文件中的未知配置项、类型错误与缺少的必填项。`,
 	Run:   runConfigValidate,
 }
 
+// configSchemaCmd 输出配置 JSON Schema
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "输出配置文件的 JSON Schema",
+	Long: `输出配置文件的 JSON Schema（draft 2020-12），加载配置时按它校验配置文件。
+可交给编辑器用于补全与校验，如在 config.yaml 首行加入
+# yaml-language-server: $schema=<schema 文件路径>
+
+示例:
+  taskbridge config schema > ~/.taskbridge/config.schema.json`,
+	Run: runConfigSchema,
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configDumpCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configUnsetCmd)
 	configCmd.AddCommand(configInitCmd)
 	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
 
 	configShowCmd.Flags().BoolVar(&configShowSensitive, "sensitive", false, "显示敏感信息")
 	configShowCmd.Flags().StringVarP(&configFormat, "format", "f", "yaml", "输出格式 (yaml, json)")
+	configGetCmd.Flags().BoolVar(&configShowSensitive, "sensitive", false, "显示敏感信息")
+	configDumpCmd.Flags().BoolVar(&configDumpRedact, "redact", true, "遮盖敏感信息")
+	configDumpCmd.Flags().StringVarP(&configFormat, "format", "f", "yaml", "输出格式 (yaml, json)")
 
-	configInitCmd.Flags().StringVar(&cfgFile, "output", "", "配置文件输出路径")
+	configInitCmd.Flags().StringVar(&configInitOutput, "output", "", "配置文件输出路径")
 }
 
 func runConfigShow(cmd *cobra.Command, args []string) {
-	switch configFormat {
-	case "json":
-		data, err := json.MarshalIndent(cfg, "", "  ")
-		if err != nil {
-			fmt.Printf("❌ 序列化配置失败: %v\n", err)
-			os.Exit(1)
+	if err := printConfigValue(effectiveConfigMap(), configFormat); err != nil {
+		fmt.Printf("❌ 序列化配置失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	if profile := paths.GetProfile(); profile != "" {
+		fmt.Printf("\nProfile: %s（%s）\n", profile, paths.GetAppDir())
+	}
+	if path := GetConfigFileUsed(); path != "" {
+		fmt.Printf("\n配置来源: 默认值 + 配置文件 %s + 环境变量 + 命令行参数\n", path)
+		return
+	}
+	fmt.Println("\n配置来源: 默认值 + 环境变量 + 命令行参数（未找到配置文件）")
+}
+
+// effectiveConfigMap 返回生效配置，未指定 --sensitive 时隐藏敏感值
+func effectiveConfigMap() map[string]any {
+	if !configShowSensitive {
+		return cfg.RedactedMap()
+	}
+	return pkgconfig.ToMap(cfg)
+}
+
+func runConfigDump(cmd *cobra.Command, args []string) {
+	settings := pkgconfig.ToMap(cfg)
+	if configDumpRedact {
+		settings = cfg.RedactedMap()
+	}
+	profile := paths.GetProfile()
+	if profile == "" {
+		profile = "default"
+	}
+
+	// 只标注非默认值的来源，保持输出简洁
+	sources := map[string]string{}
+	for _, key := range configDumpKeys(settings, "") {
+		if source := cfg.Source(key); source != pkgconfig.SourceDefault {
+			sources[key] = source
 		}
-		fmt.Println(string(data))
-	default:
-		data, err := yaml.Marshal(cfg)
+	}
+
+	if configFormat == "json" {
+		data, err := json.MarshalIndent(map[string]any{
+			"version":      buildinfo.Version,
+			"git_commit":   buildinfo.GitCommit,
+			"platform":     runtime.GOOS + "/" + runtime.GOARCH,
+			"config_files": cfg.Files(),
+			"profile":      profile,
+			"environment":  pkgconfig.Environment(),
+			"redacted":     configDumpRedact,
+			"config":       settings,
+			"sources":      sources,
+		}, "", "  ")
 		if err != nil {
 			fmt.Printf("❌ 序列化配置失败: %v\n", err)
 			os.Exit(1)
 		}
 		fmt.Println(string(data))
+		return
 	}
 
-	fmt.Println("\n配置来源: 默认值 + 环境变量 + 命令行参数（config.yaml 已弃用）")
+	node := configDumpNode(settings, "", sources)
+	configFiles := strings.Join(cfg.Files(), ", ")
+	if configFiles == "" {
+		configFiles = "（未找到配置文件）"
+	}
+	environment := pkgconfig.Environment()
+	if environment == "" {
+		environment = "（未指定）"
+	}
+	node.HeadComment = fmt.Sprintf("TaskBridge %s (%s) %s/%s %s\n配置文件: %s\nProfile: %s\n配置环境: %s\n行尾注释为配置项的来源，未标注的为默认值",
+		buildinfo.Version, buildinfo.GitCommit, runtime.GOOS, runtime.GOARCH, runtime.Version(), configFiles, profile, environment)
+	if configDumpRedact {
+		node.HeadComment += "；敏感值已替换为 ******"
+	}
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		fmt.Printf("❌ 序列化配置失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(string(data))
 }
 
-func runConfigSet(cmd *cobra.Command, args []string) {
-	_ = args
-	fmt.Println("❌ `taskbridge config set` 已弃用。请改用环境变量或命令行参数。")
-	fmt.Println("   示例: TASKBRIDGE_STORAGE_PATH=./data taskbridge list")
-	os.Exit(1)
+// configDumpKeys 列出配置中的叶子键（列表视为叶子）
+func configDumpKeys(value any, prefix string) []string {
+	settings, ok := value.(map[string]any)
+	if !ok {
+		return []string{prefix}
+	}
+	var keys []string
+	for key, child := range settings {
+		next := key
+		if prefix != "" {
+			next = prefix + "." + key
+		}
+		keys = append(keys, configDumpKeys(child, next)...)
+	}
+	return keys
 }
 
-func runConfigGet(cmd *cobra.Command, args []string) {
-	key := args[0]
-
-	// 简化实现，根据 key 获取值
-	var value interface{}
-	parts := strings.Split(key, ".")
-
-	switch parts[0] {
-	case "storage":
-		if len(parts) > 1 {
-			switch parts[1] {
-			case "type":
-				value = cfg.Storage.Type
-			case "path":
-				value = cfg.Storage.Path
-			case "file":
-				if len(parts) > 2 && parts[2] == "format" {
-					value = cfg.Storage.File.Format
-				} else {
-					value = cfg.Storage.File
-				}
-			case "nosql":
-				if len(parts) > 2 && parts[2] == "url" {
-					value = cfg.Storage.NoSQL.URL
-				} else {
-					value = cfg.Storage.NoSQL
-				}
-			default:
-				value = cfg.Storage
-			}
-		} else {
-			value = cfg.Storage
+// configDumpNode 将配置转换为按键名排序的 YAML 节点，叶子节点的行尾注释为其来源
+func configDumpNode(value any, prefix string, sources map[string]string) *yaml.Node {
+	settings, ok := value.(map[string]any)
+	if !ok {
+		node := &yaml.Node{}
+		_ = node.Encode(value)
+		node.LineComment = sources[prefix]
+		return node
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		next := key
+		if prefix != "" {
+			next = prefix + "." + key
 		}
-	case "sync":
-		if len(parts) > 1 {
-			switch parts[1] {
-			case "mode":
-				value = cfg.Sync.Mode
-			case "interval":
-				value = cfg.Sync.Interval.String()
-			case "conflict_resolution":
-				value = cfg.Sync.ConflictResolution
-			default:
-				value = cfg.Sync
-			}
-		} else {
-			value = cfg.Sync
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+		child := configDumpNode(settings[key], next, sources)
+		if child.Kind != yaml.ScalarNode {
+			// 列表等块结构的行尾注释需挂在键上，否则会落到下一个键之后
+			keyNode.LineComment, child.LineComment = child.LineComment, ""
 		}
-	case "mcp":
-		if len(parts) > 1 {
-			switch parts[1] {
-			case "enabled":
-				value = cfg.MCP.Enabled
-			case "transport":
-				value = cfg.MCP.Transport
-			case "port":
-				value = cfg.MCP.Port
-			default:
-				value = cfg.MCP
-			}
-		} else {
-			value = cfg.MCP
-		}
-	case "providers":
-		if len(parts) > 1 {
-			switch parts[1] {
-			case "google":
-				if len(parts) > 2 && parts[2] == "enabled" {
-					value = cfg.Providers.Google.Enabled
-				} else {
-					value = cfg.Providers.Google
-				}
-			case "microsoft":
-				value = cfg.Providers.Microsoft
-			case "feishu":
-				value = cfg.Providers.Feishu
-			case "ticktick":
-				value = cfg.Providers.TickTick
-			case "dida":
-				value = cfg.Providers.Dida
-			case "todoist":
-				value = cfg.Providers.Todoist
-			default:
-				value = cfg.Providers
-			}
+		node.Content = append(node.Content, keyNode, child)
+	}
+	return node
+}
+
+// printConfigValue 按格式输出配置值，单个值直接输出
+func printConfigValue(value any, format string) error {
+	var data []byte
+	var err error
+	switch value.(type) {
+	case map[string]any, []any:
+		if format == "json" {
+			data, err = json.MarshalIndent(value, "", "  ")
 		} else {
-			value = cfg.Providers
+			data, err = yaml.Marshal(value)
 		}
-	case "app":
-		if len(parts) > 1 {
-			switch parts[1] {
-			case "name":
-				value = cfg.App.Name
-			case "version":
-				value = cfg.App.Version
-			case "log_level":
-				value = cfg.App.LogLevel
-			default:
-				value = cfg.App
-			}
-		} else {
-			value = cfg.App
+		if err != nil {
+			return err
 		}
+		fmt.Println(string(data))
 	default:
+		fmt.Println(value)
+	}
+	return nil
+}
+
+// configTargetFile 返回 set/unset 写入的配置文件；选择了 profile 时写入 profile 自己的配置文件
+func configTargetFile() string {
+	if cfgFile != "" {
+		return cfgFile
+	}
+	if paths.GetProfile() != "" {
+		return pkgconfig.GetDefaultConfigPath()
+	}
+	if path := GetConfigFileUsed(); path != "" {
+		return path
+	}
+	return pkgconfig.GetDefaultConfigPath()
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) {
+	key, raw := args[0], args[1]
+	value, err := pkgconfig.ParseValue(key, raw)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	path := configTargetFile()
+	if err := pkgconfig.SetFileValue(path, key, value); err != nil {
+		fmt.Printf("❌ 写入配置失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ 已设置 %s = %s（%s）\n", key, raw, path)
+	if env := pkgconfig.EnvVar(key); os.Getenv(env) != "" {
+		fmt.Printf("⚠️ 环境变量 %s 已设置，将覆盖配置文件中的值\n", env)
+	}
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) {
+	key := args[0]
+	value, ok := pkgconfig.Get(effectiveConfigMap(), key)
+	if !ok {
 		fmt.Printf("❌ 未知的配置项: %s\n", key)
 		os.Exit(1)
 	}
+	if err := printConfigValue(value, "yaml"); err != nil {
+		fmt.Printf("%v\n", value)
+	}
+}
 
-	// 输出值
-	data, err := yaml.Marshal(value)
+func runConfigUnset(cmd *cobra.Command, args []string) {
+	key := args[0]
+	path := configTargetFile()
+	removed, err := pkgconfig.UnsetFileValue(path, key)
 	if err != nil {
-		fmt.Printf("%v\n", value)
-	} else {
-		fmt.Printf("%s", string(data))
+		fmt.Printf("❌ 写入配置失败: %v\n", err)
+		os.Exit(1)
 	}
+	if !removed {
+		fmt.Printf("配置文件 %s 中没有 %s\n", path, key)
+		return
+	}
+	fmt.Printf("✅ 已删除 %s（%s），恢复为默认值\n", key, path)
 }
 
 func runConfigInit(cmd *cobra.Command, args []string) {
 	_ = cmd
 	_ = args
-	fmt.Println("❌ `taskbridge config init` 已弃用。请改用环境变量或命令行参数。")
-	fmt.Println("   示例: taskbridge --providers microsoft,todoist mcp start")
-	os.Exit(1)
+	path := configInitOutput
+	if path == "" {
+		path = pkgconfig.GetDefaultConfigPath()
+	}
+	if _, err := os.Stat(path); err == nil {
+		fmt.Printf("❌ 配置文件已存在: %s\n", path)
+		os.Exit(1)
+	}
+	if err := pkgconfig.Save(pkgconfig.DefaultConfig(), path); err != nil {
+		fmt.Printf("❌ 创建配置文件失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ 已创建配置文件: %s\n", path)
 }
 
 func runConfigValidate(cmd *cobra.Command, args []string) {
 	_ = cmd
 	_ = args
 
-	exitCode := writeValidationReport(os.Stdout, cfg.Validate())
+	issues := cfg.Validate()
+	if path := GetConfigFileUsed(); path != "" {
+		fileIssues, err := pkgconfig.ValidateFile(path)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		issues = append(issues, fileIssues...)
+	}
+	exitCode := writeValidationReport(os.Stdout, issues)
 	if exitCode != 0 {
 		os.Exit(exitCode)
 	}
 }
+
+func runConfigSchema(cmd *cobra.Command, args []string) {
+	data, err := json.MarshalIndent(pkgconfig.Schema(), "", "  ")
+	if err != nil {
+		fmt.Printf("❌ 序列化 schema 失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
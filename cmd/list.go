@@ -12,10 +12,10 @@ import (
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 
+	"github.com/yeisme/taskbridge/internal/history"
 	"github.com/yeisme/taskbridge/internal/model"
 	"github.com/yeisme/taskbridge/internal/provider"
 	"github.com/yeisme/taskbridge/internal/storage"
-	"github.com/yeisme/taskbridge/internal/storage/filestore"
 	"github.com/yeisme/taskbridge/internal/sync"
 	"github.com/yeisme/taskbridge/pkg/ui"
 )
@@ -94,7 +94,7 @@ func runList(cmd *cobra.Command, args []string) {
 	}
 
 	// 创建存储
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginCLI)
 	if err != nil {
 		fmt.Printf("❌ 创建存储失败: %v\n", err)
 		os.Exit(1)
@@ -9,10 +9,10 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/yeisme/taskbridge/internal/history"
 	"github.com/yeisme/taskbridge/internal/model"
 	"github.com/yeisme/taskbridge/internal/provider"
 	"github.com/yeisme/taskbridge/internal/storage"
-	"github.com/yeisme/taskbridge/internal/storage/filestore"
 	"github.com/yeisme/taskbridge/pkg/ui"
 )
 
@@ -70,7 +70,7 @@ func runLists(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginCLI)
 	if err != nil {
 		fmt.Printf("❌ 创建存储失败: %v\n", err)
 		os.Exit(1)
@@ -5,27 +5,32 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/yeisme/taskbridge/internal/embedding"
+	"github.com/yeisme/taskbridge/internal/history"
 	taskbridgeMCP "github.com/yeisme/taskbridge/internal/mcp"
 	"github.com/yeisme/taskbridge/internal/project"
-	"github.com/yeisme/taskbridge/internal/provider"
-	"github.com/yeisme/taskbridge/internal/provider/google"
-	"github.com/yeisme/taskbridge/internal/provider/microsoft"
-	"github.com/yeisme/taskbridge/internal/provider/ticktick"
-	"github.com/yeisme/taskbridge/internal/provider/todoist"
-	"github.com/yeisme/taskbridge/internal/storage/filestore"
+	"github.com/yeisme/taskbridge/internal/sync"
+	"github.com/yeisme/taskbridge/internal/tasktemplate"
+	"github.com/yeisme/taskbridge/internal/timetrack"
 	"github.com/yeisme/taskbridge/pkg/buildinfo"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+	"github.com/yeisme/taskbridge/pkg/logger"
 )
 
 var (
 	mcpTransport string
 	mcpPort      int
+	mcpHost      string
 	mcpToolsJSON bool
 )
 
@@ -77,11 +82,13 @@ var mcpStartCmd = &cobra.Command{
 传输方式:
   - stdio: 通过标准输入/输出通信（默认）
   - sse: 通过 SSE 通信
-  - streamable: 通过 HTTP MCP 端点通信
+  - streamable: 通过 HTTP MCP 端点通信（别名 http）
+  - 逗号组合：同一进程同时服务本地 stdio 客户端与远程 HTTP 客户端，共享 provider 与缓存
 
 示例:
   taskbridge mcp start
-  taskbridge mcp start --transport sse --port 14940`,
+  taskbridge mcp start --transport sse --port 14940
+  taskbridge mcp start --transport stdio,http --port 14940`,
 	Run: runMCPStart,
 }
 
@@ -116,8 +123,9 @@ func init() {
 	mcpCmd.AddCommand(mcpToolsCmd)
 	mcpCmd.AddCommand(mcpDoctorCmd)
 
-	mcpStartCmd.Flags().StringVar(&mcpTransport, "transport", "stdio", "传输方式 (stdio, sse, streamable)")
+	mcpStartCmd.Flags().StringVar(&mcpTransport, "transport", "stdio", "传输方式 (stdio, sse, streamable/http)，逗号分隔可同时提供多个，如 stdio,http")
 	mcpStartCmd.Flags().IntVarP(&mcpPort, "port", "p", 14940, "HTTP 端口（用于 sse/streamable 模式）")
+	mcpStartCmd.Flags().StringVar(&mcpHost, "host", "", "HTTP 监听地址，如 127.0.0.1 仅允许本机访问（默认所有网卡）")
 	mcpToolsCmd.Flags().BoolVar(&mcpToolsJSON, "json", false, "以 JSON 格式输出工具列表")
 }
 
@@ -141,6 +149,12 @@ func getMCPTools() []MCPTool {
 						"type":        "string",
 						"description": "按来源筛选（支持简写）",
 					},
+					"adapter": map[string]interface{}{
+						"description": "按 provider 筛选（string 或 string[]）",
+					},
+					"project": map[string]interface{}{
+						"description": "按项目 ID 或名称筛选（string 或 string[]）",
+					},
 					"list_id": map[string]interface{}{
 						"description": "按清单 ID 筛选（string 或 string[]）",
 					},
@@ -174,6 +188,18 @@ func getMCPTools() []MCPTool {
 						"type":        "string",
 						"description": "关键词/自然语言查询",
 					},
+					"sort": map[string]interface{}{
+						"type":        "string",
+						"description": "排序，如 priority:desc,due_date",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "每页条数",
+					},
+					"cursor": map[string]interface{}{
+						"type":        "string",
+						"description": "分页游标（首次传空字符串）",
+					},
 					"detail": map[string]interface{}{
 						"type":        "string",
 						"description": "compact 或 full，默认 compact",
@@ -344,6 +370,120 @@ func getMCPTools() []MCPTool {
 				"required": []string{"project_id", "provider"},
 			},
 		},
+		{
+			Name:        "start_timer",
+			Description: "为任务启动计时器",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "任务 ID",
+					},
+				},
+				"required": []string{"task_id"},
+			},
+		},
+		{
+			Name:        "stop_timer",
+			Description: "停止计时器并累加任务耗时",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "任务 ID（可选）",
+					},
+				},
+			},
+		},
+		{
+			Name:        "log_time",
+			Description: "手动补录任务耗时",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "任务 ID",
+					},
+					"minutes": map[string]interface{}{
+						"type":        "integer",
+						"description": "耗时（分钟）",
+					},
+				},
+				"required": []string{"task_id"},
+			},
+		},
+		{
+			Name:        "time_report",
+			Description: "查询任务耗时与时间记录",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "任务 ID（可选）",
+					},
+					"since": map[string]interface{}{
+						"type":        "string",
+						"description": "起始时间（RFC3339、YYYY-MM-DD 或 7d）",
+					},
+					"until": map[string]interface{}{
+						"type":        "string",
+						"description": "结束时间（格式同 since）",
+					},
+				},
+			},
+		},
+		{
+			Name:        "save_template",
+			Description: "保存可复用的多任务模板",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "模板名称",
+					},
+					"tasks": map[string]interface{}{
+						"type":        "array",
+						"description": "模板任务列表",
+					},
+					"task_ids": map[string]interface{}{
+						"type":        "array",
+						"description": "从已有任务抓取模板",
+					},
+				},
+				"required": []string{"name"},
+			},
+		},
+		{
+			Name:        "list_templates",
+			Description: "列出已保存的任务模板",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "instantiate_template",
+			Description: "将模板展开为任务，可选推送到 provider",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"template": map[string]interface{}{
+						"type":        "string",
+						"description": "模板 ID 或名称",
+					},
+					"provider": map[string]interface{}{
+						"type":        "string",
+						"description": "目标 provider",
+					},
+				},
+				"required": []string{"template"},
+			},
+		},
 		{
 			Name:        "get_prompt",
 			Description: "获取内置提示词模板（含 json_query_commands）",
@@ -394,6 +534,70 @@ func getMCPTools() []MCPTool {
 				"required": []string{"provider"},
 			},
 		},
+		{
+			Name:        "sync_now",
+			Description: "通过同步引擎立即同步（source/target/dry_run）",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"source": map[string]interface{}{
+						"type":        "string",
+						"description": "来源（provider 或 local）",
+					},
+					"target": map[string]interface{}{
+						"type":        "string",
+						"description": "目标（provider 或 local）",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "模拟执行",
+					},
+					"delete": map[string]interface{}{
+						"type":        "boolean",
+						"description": "推送时删除远程多余任务",
+					},
+				},
+			},
+		},
+		{
+			Name:        "sync_status",
+			Description: "查看同步状态、最近一次同步结果与各镜像对的运行记录",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"provider": map[string]interface{}{
+						"type":        "string",
+						"description": "只查看指定 provider",
+					},
+				},
+			},
+		},
+		{
+			Name:        "resolve_conflict",
+			Description: "按字段选择本地或远端版本解决任务冲突",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "冲突任务 ID",
+					},
+					"keep": map[string]interface{}{
+						"type":        "string",
+						"description": "未指定字段保留哪一侧（local/remote）",
+					},
+					"fields": map[string]interface{}{
+						"type":        "object",
+						"description": "逐字段选择 local 或 remote",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "仅预览合并结果",
+					},
+				},
+				"required": []string{"task_id"},
+			},
+		},
 		{
 			Name:        "list_providers",
 			Description: "列出 Provider 状态与能力",
@@ -438,6 +642,39 @@ func getMCPTools() []MCPTool {
 				"properties": map[string]interface{}{},
 			},
 		},
+		{
+			Name:        "set_context",
+			Description: "设置当前会话的默认 adapter、项目与时区",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"adapter": map[string]interface{}{
+						"type":        "string",
+						"description": "默认 provider（支持简写）",
+					},
+					"project": map[string]interface{}{
+						"type":        "string",
+						"description": "默认项目 ID 或名称",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "默认时区（IANA 名称）",
+					},
+					"clear": map[string]interface{}{
+						"type":        "boolean",
+						"description": "先清除全部默认值",
+					},
+				},
+			},
+		},
+		{
+			Name:        "get_context",
+			Description: "查看当前会话的默认参数",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
 		{
 			Name:        "create_task",
 			Description: "创建新任务",
@@ -468,6 +705,173 @@ func getMCPTools() []MCPTool {
 				"required": []string{"title"},
 			},
 		},
+		{
+			Name:        "quick_add",
+			Description: "用一句话快速创建任务，支持 #项目 @标签 p1-p4 与自然语言日期",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"text": map[string]interface{}{
+						"type":        "string",
+						"description": "任务描述，如 \"Pay rent tomorrow 9am #finance p1 @home\"",
+					},
+					"provider": map[string]interface{}{
+						"type":        "string",
+						"description": "目标 Provider（默认 local）",
+					},
+					"list_id": map[string]interface{}{
+						"type":        "string",
+						"description": "远端清单 ID",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "解析日期使用的时区",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "仅返回解析结果",
+					},
+				},
+				"required": []string{"text"},
+			},
+		},
+		{
+			Name:        "create_tasks_from_markdown",
+			Description: "将 Markdown 列表/清单逐行创建为任务，缩进保留为子任务",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"markdown": map[string]interface{}{
+						"type":        "string",
+						"description": "Markdown 列表文本",
+					},
+					"provider": map[string]interface{}{
+						"type":        "string",
+						"description": "目标 Provider（默认 local）",
+					},
+					"list_id": map[string]interface{}{
+						"type":        "string",
+						"description": "远端清单 ID",
+					},
+					"parse_syntax": map[string]interface{}{
+						"type":        "boolean",
+						"description": "按 quick_add 语法解析每行",
+					},
+					"skip_completed": map[string]interface{}{
+						"type":        "boolean",
+						"description": "跳过已勾选项",
+					},
+					"max_tasks": map[string]interface{}{
+						"type":        "integer",
+						"description": "最多创建任务数",
+					},
+					"timezone": map[string]interface{}{
+						"type":        "string",
+						"description": "解析日期使用的时区",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "仅返回解析结果",
+					},
+				},
+				"required": []string{"markdown"},
+			},
+		},
+		{
+			Name:        "export_tasks",
+			Description: "导出任务为 CSV/JSON/Markdown/iCal(VTODO) 资源",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"csv", "json", "markdown", "ical"},
+						"description": "导出格式（默认 json）",
+					},
+					"project": map[string]interface{}{
+						"type":        "string",
+						"description": "按项目 ID 或名称筛选",
+					},
+					"source": map[string]interface{}{
+						"type":        "string",
+						"description": "按来源筛选",
+					},
+					"status": map[string]interface{}{
+						"type":        "string",
+						"description": "按状态筛选",
+					},
+					"tag": map[string]interface{}{
+						"type":        "string",
+						"description": "按标签筛选",
+					},
+					"filename": map[string]interface{}{
+						"type":        "string",
+						"description": "资源文件名",
+					},
+					"as_blob": map[string]interface{}{
+						"type":        "boolean",
+						"description": "以 blob 返回资源内容",
+					},
+				},
+			},
+		},
+		{
+			Name:        "import_tasks",
+			Description: "导入 CSV/JSON/todo.txt 内容并批量创建任务",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "导入内容",
+					},
+					"format": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"csv", "json", "todotxt"},
+						"description": "内容格式（默认自动识别）",
+					},
+					"provider": map[string]interface{}{
+						"type":        "string",
+						"description": "目标 Provider（默认 local）",
+					},
+					"list_id": map[string]interface{}{
+						"type":        "string",
+						"description": "远端清单 ID",
+					},
+					"max_tasks": map[string]interface{}{
+						"type":        "integer",
+						"description": "最多导入任务数",
+					},
+					"dry_run": map[string]interface{}{
+						"type":        "boolean",
+						"description": "仅报告将创建的任务",
+					},
+				},
+				"required": []string{"content"},
+			},
+		},
+		{
+			Name:        "get_task",
+			Description: "获取单个任务详情（默认返回本地缓存，refresh 时拉取远端最新数据）",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{
+						"type":        "string",
+						"description": "任务 ID（本地 ID 或远端原始 ID）",
+					},
+					"source": map[string]interface{}{
+						"type":        "string",
+						"description": "任务来源（默认从任务推断）",
+					},
+					"refresh": map[string]interface{}{
+						"type":        "boolean",
+						"description": "是否从远端拉取最新数据（默认 false，本地无缓存时总是拉取）",
+					},
+				},
+				"required": []string{"id"},
+			},
+		},
 		{
 			Name:        "update_task",
 			Description: "更新现有任务",
@@ -518,6 +922,151 @@ func getMCPTools() []MCPTool {
 				"required": []string{"id"},
 			},
 		},
+		{
+			Name:        "overdue_tasks",
+			Description: "按来源与清单分组列出逾期任务，并按逾期时长分桶",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"source": map[string]interface{}{
+						"type":        "string",
+						"description": "按来源筛选",
+					},
+					"list_id": map[string]interface{}{
+						"type":        "string",
+						"description": "按清单 ID 筛选",
+					},
+					"include_tasks": map[string]interface{}{
+						"type":        "boolean",
+						"description": "是否返回任务明细",
+					},
+					"limit_per_group": map[string]interface{}{
+						"type":        "integer",
+						"description": "每组最多返回任务数",
+					},
+				},
+			},
+		},
+		{
+			Name:        "snooze_task",
+			Description: "按时长或命名时间槽推迟任务截止时间",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "任务 ID",
+					},
+					"duration": map[string]interface{}{
+						"type":        "string",
+						"description": "推迟时长，如 2h/3d/1w",
+					},
+					"until": map[string]interface{}{
+						"type":        "string",
+						"description": "命名时间槽或日期",
+					},
+				},
+				"required": []string{"task_id"},
+			},
+		},
+		{
+			Name:        "reorder_tasks",
+			Description: "调整清单内任务顺序（如移到顶部）",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "要移动的任务 ID",
+					},
+					"position": map[string]interface{}{
+						"type":        "string",
+						"description": "top/bottom 或序号",
+					},
+					"task_ids": map[string]interface{}{
+						"type":        "array",
+						"description": "按给定顺序排列任务",
+					},
+				},
+			},
+		},
+		{
+			Name:        "add_blocker",
+			Description: "记录任务阻塞关系（blocker_id 阻塞 task_id）",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "被阻塞的任务 ID",
+					},
+					"blocker_id": map[string]interface{}{
+						"type":        "string",
+						"description": "阻塞它的任务 ID",
+					},
+					"remove": map[string]interface{}{
+						"type":        "boolean",
+						"description": "移除该依赖关系",
+					},
+				},
+				"required": []string{"task_id", "blocker_id"},
+			},
+		},
+		{
+			Name:        "list_blockers",
+			Description: "列出任务的阻塞项与后续任务",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "任务 ID（可选）",
+					},
+				},
+			},
+		},
+		{
+			Name:        "ready_tasks",
+			Description: "列出未被阻塞、可立即开始的任务",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"source": map[string]interface{}{
+						"type":        "string",
+						"description": "按来源筛选（支持简写）",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "返回条数上限",
+					},
+				},
+			},
+		},
+		{
+			Name:        "plan_day",
+			Description: "生成当天时间块日程（可回写截止时间或时间块任务）",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"date": map[string]interface{}{
+						"type":        "string",
+						"description": "规划日期 YYYY-MM-DD",
+					},
+					"start": map[string]interface{}{
+						"type":        "string",
+						"description": "工作开始时间 HH:MM",
+					},
+					"end": map[string]interface{}{
+						"type":        "string",
+						"description": "工作结束时间 HH:MM",
+					},
+					"write_back": map[string]interface{}{
+						"type":        "string",
+						"description": "none/due_times/tasks",
+					},
+				},
+			},
+		},
 		{
 			Name:        "analyze_quadrant",
 			Description: "按四象限（艾森豪威尔矩阵）分析任务分布",
@@ -534,6 +1083,44 @@ func getMCPTools() []MCPTool {
 				"properties": map[string]interface{}{},
 			},
 		},
+		{
+			Name:        "task_statistics",
+			Description: "统计任务数量与趋势（每日新建/完成、逾期占比、按项目分组）",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"window_days": map[string]interface{}{
+						"type":        "integer",
+						"description": "统计窗口天数（默认 14）",
+					},
+					"source": map[string]interface{}{
+						"type":        "string",
+						"description": "按来源筛选",
+					},
+				},
+			},
+		},
+		{
+			Name:        "summarize_tasks",
+			Description: "生成当日任务或项目的简明摘要（数量、重点、风险）",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"scope": map[string]interface{}{
+						"type":        "string",
+						"description": "today 或 project",
+					},
+					"project": map[string]interface{}{
+						"type":        "string",
+						"description": "项目 ID 或名称",
+					},
+					"top": map[string]interface{}{
+						"type":        "integer",
+						"description": "列出的优先任务数量",
+					},
+				},
+			},
+		},
 		{
 			Name:        "analyze_overdue_health",
 			Description: "分析逾期任务健康度，输出过载风险与建议动作",
@@ -639,6 +1226,28 @@ func getMCPTools() []MCPTool {
 				"required": []string{"task_id"},
 			},
 		},
+		{
+			Name:        "breakdown_task",
+			Description: "借助 sampling 拆解任务，确认后在来源 provider 创建子任务",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "string",
+						"description": "待拆解任务 ID",
+					},
+					"max_subtasks": map[string]interface{}{
+						"type":        "integer",
+						"description": "子任务数量上限",
+					},
+					"subtasks": map[string]interface{}{
+						"type":        "array",
+						"description": "直接指定要创建的子任务",
+					},
+				},
+				"required": []string{"task_id"},
+			},
+		},
 		{
 			Name:        "analyze_achievement",
 			Description: "分析完成情况并输出成就反馈",
@@ -656,6 +1265,45 @@ func getMCPTools() []MCPTool {
 				},
 			},
 		},
+		{
+			Name:        "find_duplicates",
+			Description: "检测疑似重复任务（标题模糊匹配 + 截止日期相近）",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"source": map[string]interface{}{
+						"type":        "string",
+						"description": "按来源筛选",
+					},
+					"threshold": map[string]interface{}{
+						"type":        "number",
+						"description": "标题相似度阈值（默认 0.8）",
+					},
+					"due_window_days": map[string]interface{}{
+						"type":        "integer",
+						"description": "截止日期相差天数上限（默认 1）",
+					},
+				},
+			},
+		},
+		{
+			Name:        "merge_tasks",
+			Description: "将重复任务合并到主任务",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"primary_id": map[string]interface{}{
+						"type":        "string",
+						"description": "保留的主任务 ID",
+					},
+					"duplicate_ids": map[string]interface{}{
+						"type":        "array",
+						"description": "要合并的重复任务 ID",
+					},
+				},
+				"required": []string{"primary_id", "duplicate_ids"},
+			},
+		},
 	}
 }
 
@@ -665,13 +1313,14 @@ func printToStderr(message string) {
 }
 
 func runMCPStart(cmd *cobra.Command, args []string) {
-	transport, warnings, err := resolveMCPStartTransport(cmd)
+	transport, warnings, err := resolveMCPStartTransport()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ 不支持的传输方式: %s\n", mcpTransport)
-		fmt.Fprintf(os.Stderr, "支持的传输方式: stdio, sse, streamable\n")
+		fmt.Fprintf(os.Stderr, "❌ 不支持的传输方式: %s\n", cfg.MCP.Transport)
+		fmt.Fprintf(os.Stderr, "支持的传输方式: stdio, sse, streamable（http 为 streamable 别名），可用逗号组合，如 stdio,http\n")
 		os.Exit(1)
 	}
-	port := resolveMCPStartPort(cmd)
+	port := resolveMCPStartPort()
+	host := resolveMCPStartHost()
 
 	// 在 stdio 模式下，所有日志信息必须输出到 stderr，因为 stdout 用于 JSON-RPC 通信
 	// 在 sse/streamable 模式下，也输出到 stderr 避免干扰 HTTP 服务
@@ -682,14 +1331,15 @@ func runMCPStart(cmd *cobra.Command, args []string) {
 	printToStderr("\n")
 	printToStderr(statusBarStyle.Render(fmt.Sprintf("传输方式: %s", transport)))
 	printToStderr("\n")
-	if transport == "sse" || transport == "streamable" {
-		printToStderr(statusBarStyle.Render(fmt.Sprintf("端口: %d", port)))
+	if httpTransport := pkgconfig.HTTPTransport(transport); httpTransport != "" {
+		printToStderr(statusBarStyle.Render(fmt.Sprintf("监听地址: %s", taskbridgeMCP.ListenAddr(host, port))))
 		printToStderr("\n")
-		if transport == "sse" {
-			printToStderr(statusBarStyle.Render(fmt.Sprintf("SSE 端点: http://localhost:%d/sse", port)))
+		endpointHost := displayHost(host, port)
+		if httpTransport == "sse" {
+			printToStderr(statusBarStyle.Render(fmt.Sprintf("SSE 端点: http://%s%s", endpointHost, taskbridgeMCP.EndpointPath(cfg.MCP.HTTP.BasePath, "/sse"))))
 			printToStderr("\n")
 		} else {
-			printToStderr(statusBarStyle.Render(fmt.Sprintf("HTTP 端点: http://localhost:%d/mcp", port)))
+			printToStderr(statusBarStyle.Render(fmt.Sprintf("HTTP 端点: http://%s%s", endpointHost, taskbridgeMCP.EndpointPath(cfg.MCP.HTTP.BasePath, "/mcp"))))
 			printToStderr("\n")
 		}
 	}
@@ -709,77 +1359,130 @@ func runMCPStart(cmd *cobra.Command, args []string) {
 	}()
 
 	// 创建存储
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginServer)
 	if err != nil {
 		printToStderr(fmt.Sprintf("❌ 初始化存储失败: %v\n", err))
 		os.Exit(1)
 	}
+	// 任务历史：启动时清理超过 storage.history.retention 的记录
+	taskHistory := openTaskHistory()
+	if taskHistory != nil && cfg.Storage.History.Retention > 0 {
+		if _, err := taskHistory.Prune(time.Now().Add(-cfg.Storage.History.Retention)); err != nil {
+			printToStderr(fmt.Sprintf("⚠️  清理任务历史失败: %v\n", err))
+		}
+	}
+	// 任务向量：启用时注册 semantic_search，打开失败不影响启动
+	var embeddingIndex *embedding.Index
+	if cfg.Storage.Embeddings.Enabled {
+		if embedder, err := embedding.New(cfg.Storage.Embeddings); err != nil {
+			printToStderr(fmt.Sprintf("⚠️  初始化向量化失败，semantic_search 不可用: %v\n", err))
+		} else if embeddingIndex, err = embedding.Open(cfg.Storage.Path, embedder, cfg.Storage.Embeddings.BatchSize); err != nil {
+			printToStderr(fmt.Sprintf("⚠️  打开向量索引失败，semantic_search 不可用: %v\n", err))
+			embeddingIndex = nil
+		}
+	}
 	projectStore, err := project.NewFileStore(cfg.Storage.Path)
 	if err != nil {
 		printToStderr(fmt.Sprintf("❌ 初始化项目存储失败: %v\n", err))
 		os.Exit(1)
 	}
+	timeStore, err := timetrack.NewFileStore(cfg.Storage.Path)
+	if err != nil {
+		printToStderr(fmt.Sprintf("❌ 初始化时间记录存储失败: %v\n", err))
+		os.Exit(1)
+	}
+	templateStore, err := tasktemplate.NewFileStore(cfg.Storage.Path)
+	if err != nil {
+		printToStderr(fmt.Sprintf("❌ 初始化任务模板存储失败: %v\n", err))
+		os.Exit(1)
+	}
 
 	// 初始化 Provider 映射
-	providers := make(map[string]provider.Provider)
+	providers := loadMCPProviders(ctx, cfg)
+	warnMCPProvidersNotReady(cfg, providers)
 
-	// 初始化 Google Provider
-	googleProvider, err := google.NewProviderFromHome()
-	if err == nil && googleProvider.IsAuthenticated() {
-		providers["google"] = googleProvider
+	// 创建 MCP 服务器
+	eventStore, err := taskbridgeMCP.NewEventStore(cfg.MCP.Session.EventStore, cfg.Storage.Path, cfg.MCP.Session.EventStoreMaxBytes)
+	if err != nil {
+		printToStderr(fmt.Sprintf("❌ 初始化事件存储失败: %v\n", err))
+		os.Exit(1)
 	}
-
-	// 初始化 Microsoft Provider（与 sync/auth 一致：优先从 HOME 凭证加载）
-	microsoftProvider, err := microsoft.NewProviderFromHome()
-	if err == nil && microsoftProvider.IsAuthenticated() {
-		providers["microsoft"] = microsoftProvider
+	if closer, ok := eventStore.(io.Closer); ok {
+		defer func() { _ = closer.Close() }()
 	}
-	todoistProvider, err := todoist.NewProviderFromHome()
-	if err == nil {
-		if authErr := todoistProvider.Authenticate(ctx, nil); authErr == nil {
-			providers["todoist"] = todoistProvider
-		}
-	}
-	tickProvider, err := ticktick.NewProviderFromHomeByName("ticktick")
-	if err == nil {
-		if authErr := tickProvider.Authenticate(ctx, nil); authErr == nil {
-			providers["ticktick"] = tickProvider
-		}
+
+	// 镜像状态仅供 sync_status 查询，打开失败不影响启动
+	var mirrorStore sync.MirrorStore
+	if opened, closeMirrorStore, err := openMirrorStore(); err != nil {
+		printToStderr(fmt.Sprintf("⚠️  打开镜像状态存储失败，sync_status 将不包含镜像状态: %v\n", err))
+	} else {
+		mirrorStore = opened
+		defer closeMirrorStore()
 	}
-	didaProvider, err := ticktick.NewProviderFromHomeByName("dida")
-	if err == nil {
-		if authErr := didaProvider.Authenticate(ctx, nil); authErr == nil {
-			providers["dida"] = didaProvider
+
+	// 离线写入队列：Provider 不可达时暂存写入，打开失败时写入直接失败
+	var writeQueue *taskbridgeMCP.WriteQueue
+	if cfg.MCP.Offline.Enabled {
+		if writeQueue, err = taskbridgeMCP.NewWriteQueue(cfg.Storage.Path); err != nil {
+			printToStderr(fmt.Sprintf("⚠️  打开离线写入队列失败，Provider 不可达时写入将直接失败: %v\n", err))
+			writeQueue = nil
 		}
 	}
 
-	if _, ok := providers["microsoft"]; !ok && cfg.Providers.Microsoft.Enabled {
-		printToStderr("⚠️ Microsoft Provider 未就绪，请运行 'taskbridge auth login microsoft'\n")
-	}
-	if _, ok := providers["todoist"]; !ok && cfg.Providers.Todoist.Enabled {
-		printToStderr("⚠️ Todoist Provider 未就绪，请运行 'taskbridge auth login todoist'\n")
-	}
-	if _, ok := providers["ticktick"]; !ok && cfg.Providers.TickTick.Enabled {
-		printToStderr("⚠️ TickTick Provider 未就绪，请运行 'taskbridge auth login ticktick'\n")
-	}
-	if _, ok := providers["dida"]; !ok && cfg.Providers.Dida.Enabled {
-		printToStderr("⚠️ Dida Provider 未就绪，请运行 'taskbridge auth login dida'\n")
+	// Provider 读取缓存：mcp.cache.enabled 时缓存清单列表与清单中的任务，TTL 为 0 时不缓存
+	adapterCacheTTL := cfg.MCP.Cache.DefaultTTL
+	if !cfg.MCP.Cache.Enabled {
+		adapterCacheTTL = 0
 	}
 
-	// 创建 MCP 服务器
 	server := taskbridgeMCP.NewServer(
 		taskbridgeMCP.WithTaskStorage(store),
+		taskbridgeMCP.WithMirrorStore(mirrorStore),
+		taskbridgeMCP.WithTaskHistory(taskHistory),
+		taskbridgeMCP.WithEmbeddingIndex(embeddingIndex),
+		taskbridgeMCP.WithWriteQueue(writeQueue),
+		taskbridgeMCP.WithAdapterCache(adapterCacheTTL, cfg.MCP.Cache.MaxEntries),
+		taskbridgeMCP.WithTaskCacheMaxAge(cfg.MCP.TaskCache.MaxAge),
 		taskbridgeMCP.WithProjectStore(projectStore),
+		taskbridgeMCP.WithTimeEntryStore(timeStore),
+		taskbridgeMCP.WithTemplateStore(templateStore),
 		taskbridgeMCP.WithConfig(&taskbridgeMCP.ServerConfig{
-			Name:      "taskbridge",
-			Version:   buildinfo.Version,
-			Transport: transport,
-			Port:      port,
+			Name:                    "taskbridge",
+			Version:                 buildinfo.Version,
+			Transport:               transport,
+			Host:                    host,
+			Port:                    port,
+			ResourcePollInterval:    cfg.MCP.Resources.PollInterval,
+			PageSize:                cfg.MCP.PageSize,
+			KeepAlive:               cfg.MCP.Session.KeepAlive,
+			SessionIdleTimeout:      cfg.MCP.Session.IdleTimeout,
+			CredentialCheckInterval: cfg.MCP.Credentials.CheckInterval,
+			CredentialRefreshBefore: cfg.MCP.Credentials.RefreshBefore,
+			WriteReplayInterval:     cfg.MCP.Offline.ReplayInterval,
+			TaskRefreshInterval:     cfg.MCP.TaskCache.RefreshInterval,
 		}),
 		taskbridgeMCP.WithProviders(providers),
-		taskbridgeMCP.WithProviderConfig(&cfg.Providers),
+		taskbridgeMCP.WithAdapterConfig(cfg.Adapters),
 		taskbridgeMCP.WithIntelligenceConfig(&cfg.MCP.Intelligence),
+		taskbridgeMCP.WithToolPolicy(&cfg.MCP.Tools),
+		taskbridgeMCP.WithCapabilityConfig(&cfg.MCP.Capabilities),
+		taskbridgeMCP.WithCompatConfig(&cfg.MCP.Compat),
+		taskbridgeMCP.WithSecurityConfig(&cfg.MCP.Security),
+		taskbridgeMCP.WithCORSConfig(&cfg.MCP.CORS),
+		taskbridgeMCP.WithRateLimitConfig(&cfg.MCP.RateLimit),
+		taskbridgeMCP.WithEventStore(eventStore),
+		taskbridgeMCP.WithHTTPConfig(&cfg.MCP.HTTP),
+		taskbridgeMCP.WithWebhookConfig(&cfg.MCP.Webhooks),
+		taskbridgeMCP.WithTenantConfig(&cfg.MCP.Tenant, newTenantProviderLoader(cfg)),
+		taskbridgeMCP.WithTenantStores(newTenantStoreLoader(cfg)),
 	)
+	// 结构化日志同时推送给设置了 logging/setLevel 的客户端
+	logger.AddOutput(server.LogWriter())
+	// 聚合代理：连接配置的上游 MCP 服务并以命名空间重新暴露其工具
+	server.ConnectUpstreams(ctx, cfg.MCP.Upstreams)
+	defer server.CloseUpstreams()
+	// 配置热加载：配置文件变化或到达 secrets.refresh_interval 时应用日志级别、Provider 与工具策略，无需重启
+	watchMCPConfig(ctx, server, cfg)
 
 	// 显示启动信息（输出到 stderr）
 	printToStderr("\n")
@@ -832,8 +1535,8 @@ func runMCPStatus(cmd *cobra.Command, args []string) {
 	for _, warning := range warnings {
 		fmt.Printf("   警告: %s\n", warning)
 	}
-	if transport == "sse" || transport == "streamable" {
-		fmt.Printf("   端口: %d\n", cfg.MCP.Port)
+	if pkgconfig.HTTPTransport(transport) != "" {
+		fmt.Printf("   监听地址: %s\n", taskbridgeMCP.ListenAddr(cfg.MCP.Host, cfg.MCP.Port))
 	}
 	if err != nil {
 		fmt.Printf("   错误: %v\n", err)
@@ -880,17 +1583,24 @@ func runMCPDoctor(cmd *cobra.Command, args []string) {
 	}
 }
 
-func resolveMCPStartTransport(cmd *cobra.Command) (string, []string, error) {
-	raw := cfg.MCP.Transport
-	if cmd.Flags().Changed("transport") {
-		raw = mcpTransport
-	}
-	return resolveTransportForDisplay(raw)
+// resolveMCPStartTransport 解析 mcp.transport；--transport 已在加载配置时按优先级合并
+func resolveMCPStartTransport() (string, []string, error) {
+	return resolveTransportForDisplay(cfg.MCP.Transport)
 }
 
-func resolveMCPStartPort(cmd *cobra.Command) int {
-	if cmd.Flags().Changed("port") {
-		return mcpPort
+func resolveMCPStartHost() string {
+	return strings.TrimSpace(cfg.MCP.Host)
+}
+
+// displayHost 返回启动信息中展示的端点地址；监听所有网卡时以 localhost 展示
+func displayHost(host string, port int) string {
+	switch strings.Trim(host, "[]") {
+	case "", "0.0.0.0", "::":
+		host = "localhost"
 	}
+	return taskbridgeMCP.ListenAddr(host, port)
+}
+
+func resolveMCPStartPort() int {
 	return cfg.MCP.Port
 }
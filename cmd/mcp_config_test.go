@@ -98,8 +98,8 @@ func TestBuildDoctorReportChecksProviderTokens(t *testing.T) {
 	}
 
 	cfg := pkgconfig.DefaultConfig()
-	cfg.Providers.Google.Enabled = true
-	cfg.Providers.Todoist.Enabled = true
+	cfg.Adapters.SetEnabled("google", true)
+	cfg.Adapters.SetEnabled("todoist", true)
 
 	report := buildDoctorReport(cfg)
 
@@ -123,3 +123,49 @@ func findFreePort(t *testing.T) int {
 	defer listener.Close()
 	return listener.Addr().(*net.TCPAddr).Port
 }
+
+func TestDisplayHostForBindAddress(t *testing.T) {
+	cases := map[string]string{
+		"":          "localhost:14940",
+		"0.0.0.0":   "localhost:14940",
+		"127.0.0.1": "127.0.0.1:14940",
+		"::1":       "[::1]:14940",
+		"[::]":      "localhost:14940",
+	}
+	for host, want := range cases {
+		if got := displayHost(host, 14940); got != want {
+			t.Fatalf("displayHost(%q): want %s, got %s", host, want, got)
+		}
+	}
+}
+
+func TestProviderEnabledFilter(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig()
+	enabled := providerEnabledFilter(cfg)
+	if !enabled("google") || !enabled("todoist") {
+		t.Fatalf("all authenticated providers should load when none is enabled explicitly")
+	}
+
+	cfg.Adapters.SetEnabled("todoist", true)
+	enabled = providerEnabledFilter(cfg)
+	if !enabled("todoist") || enabled("google") {
+		t.Fatalf("only explicitly enabled providers should load")
+	}
+}
+
+func TestRestartRequiredChanges(t *testing.T) {
+	prev := pkgconfig.DefaultConfig()
+	next := pkgconfig.DefaultConfig()
+	next.App.LogLevel = "debug"
+	next.MCP.Tools.ReadOnly = true
+	if changed := restartRequiredChanges(prev, next); len(changed) != 0 {
+		t.Fatalf("hot-reloadable changes should not require restart: %v", changed)
+	}
+
+	next.MCP.Port = prev.MCP.Port + 1
+	next.MCP.HTTP.BasePath = "/taskbridge"
+	changed := restartRequiredChanges(prev, next)
+	if len(changed) != 2 || changed[0] != "mcp.port" || changed[1] != "mcp.http" {
+		t.Fatalf("unexpected restart-required keys: %v", changed)
+	}
+}
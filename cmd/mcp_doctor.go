@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 	"strings"
 
 	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
@@ -68,10 +69,10 @@ func buildDoctorReport(cfg *pkgconfig.Config) doctorReport {
 			addFinding(&report.Transport, pkgconfig.ValidationLevelWarning, warning)
 		}
 
-		if transport == "sse" || transport == "streamable" {
+		if pkgconfig.HTTPTransport(transport) != "" {
 			if cfg.MCP.Port < 1 || cfg.MCP.Port > 65535 {
 				addFinding(&report.Transport, pkgconfig.ValidationLevelError, fmt.Sprintf("端口 %d 非法，需为 1-65535", cfg.MCP.Port))
-			} else if isPortAvailable(cfg.MCP.Port) {
+			} else if isPortAvailable(cfg.MCP.Host, cfg.MCP.Port) {
 				addFinding(&report.Transport, "info", fmt.Sprintf("端口 %d 可用", cfg.MCP.Port))
 			} else {
 				addFinding(&report.Transport, pkgconfig.ValidationLevelError, fmt.Sprintf("端口 %d 已被占用", cfg.MCP.Port))
@@ -98,12 +99,15 @@ func buildDoctorReport(cfg *pkgconfig.Config) doctorReport {
 	return report
 }
 
-func isPortAvailable(port int) bool {
+func isPortAvailable(host string, port int) bool {
 	if port < 1 || port > 65535 {
 		return false
 	}
 
-	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if strings.TrimSpace(host) == "" {
+		host = "127.0.0.1"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(strings.Trim(strings.TrimSpace(host), "[]"), strconv.Itoa(port)))
 	if err != nil {
 		return false
 	}
@@ -138,14 +142,14 @@ func providerDoctorFindings(cfg *pkgconfig.Config) []doctorFinding {
 		}
 	}
 
-	checkTokenProvider(cfg.Providers.Google.Enabled, "google", "Google")
-	checkTokenProvider(cfg.Providers.Microsoft.Enabled, "microsoft", "Microsoft")
-	checkTokenProvider(cfg.Providers.Todoist.Enabled, "todoist", "Todoist")
-	checkTokenProvider(cfg.Providers.TickTick.Enabled, "ticktick", "TickTick")
-	checkTokenProvider(cfg.Providers.Dida.Enabled, "dida", "Dida")
+	checkTokenProvider(cfg.Adapters.Enabled("google"), "google", "Google")
+	checkTokenProvider(cfg.Adapters.Enabled("microsoft"), "microsoft", "Microsoft")
+	checkTokenProvider(cfg.Adapters.Enabled("todoist"), "todoist", "Todoist")
+	checkTokenProvider(cfg.Adapters.Enabled("ticktick"), "ticktick", "TickTick")
+	checkTokenProvider(cfg.Adapters.Enabled("dida"), "dida", "Dida")
 
-	if cfg.Providers.Feishu.Enabled {
-		if strings.TrimSpace(cfg.Providers.Feishu.AppID) == "" || strings.TrimSpace(cfg.Providers.Feishu.AppSecret) == "" {
+	if cfg.Adapters.Enabled("feishu") {
+		if strings.TrimSpace(cfg.Adapters.Get("feishu").AppID) == "" || strings.TrimSpace(cfg.Adapters.Get("feishu").AppSecret) == "" {
 			findings = append(findings, doctorFinding{
 				Level:   pkgconfig.ValidationLevelWarning,
 				Message: "Feishu 已启用但缺少 appid/appsecret",
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	taskbridgeMCP "github.com/yeisme/taskbridge/internal/mcp"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/provider/google"
+	"github.com/yeisme/taskbridge/internal/provider/microsoft"
+	"github.com/yeisme/taskbridge/internal/provider/ticktick"
+	"github.com/yeisme/taskbridge/internal/provider/todoist"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+	"github.com/yeisme/taskbridge/pkg/logger"
+	"github.com/yeisme/taskbridge/pkg/paths"
+)
+
+// configReloadDebounce 合并编辑器保存时产生的多次文件事件
+const configReloadDebounce = 300 * time.Millisecond
+
+// loadMCPProviders 从 HOME 凭证加载已认证的 Provider。
+// 配置中显式启用了任一 Provider（providers.<name>.enabled 或 --providers）时只加载启用的 Provider，
+// 否则加载全部已认证的 Provider。
+func loadMCPProviders(ctx context.Context, c *pkgconfig.Config) map[string]provider.Provider {
+	return loadProvidersFromDir(ctx, c, paths.GetCredentialsDir(), strings.TrimSpace(c.Adapters.Get("google").Impersonate))
+}
+
+// loadProvidersFromDir 从指定凭证目录加载已认证的 Provider，googleSubject 为 Google 服务账号代为访问的用户
+func loadProvidersFromDir(ctx context.Context, c *pkgconfig.Config, credentialsDir, googleSubject string) map[string]provider.Provider {
+	enabled := providerEnabledFilter(c)
+	providers := make(map[string]provider.Provider)
+
+	// 初始化 Google Provider
+	if enabled("google") {
+		googleProvider, err := google.NewProviderFromDir(credentialsDir, googleSubject)
+		if err == nil && googleProvider.IsAuthenticated() {
+			providers["google"] = googleProvider
+		}
+	}
+
+	// 初始化 Microsoft Provider（与 sync/auth 一致：优先从 HOME 凭证加载）
+	if enabled("microsoft") {
+		microsoftProvider, err := microsoft.NewProviderFromDir(credentialsDir)
+		if err == nil && microsoftProvider.IsAuthenticated() {
+			providers["microsoft"] = microsoftProvider
+		}
+	}
+	if enabled("todoist") {
+		todoistProvider, err := todoist.NewProviderFromDir(credentialsDir)
+		if err == nil {
+			if authErr := todoistProvider.Authenticate(ctx, nil); authErr == nil {
+				providers["todoist"] = todoistProvider
+			}
+		}
+	}
+	for _, name := range []string{"ticktick", "dida"} {
+		if !enabled(name) {
+			continue
+		}
+		tickProvider, err := ticktick.NewProviderFromDir(credentialsDir, name)
+		if err == nil {
+			if authErr := tickProvider.Authenticate(ctx, nil); authErr == nil {
+				providers[name] = tickProvider
+			}
+		}
+	}
+	return providers
+}
+
+// providerEnabledFilter 返回 Provider 是否应被加载的判断函数
+func providerEnabledFilter(c *pkgconfig.Config) func(name string) bool {
+	explicit := c.Adapters.AnyEnabled()
+	return func(name string) bool {
+		return !explicit || c.Adapters.Enabled(name)
+	}
+}
+
+// warnMCPProvidersNotReady 提示已启用但未完成认证的 Provider
+func warnMCPProvidersNotReady(c *pkgconfig.Config, providers map[string]provider.Provider) {
+	if _, ok := providers["microsoft"]; !ok && c.Adapters.Enabled("microsoft") {
+		printToStderr("⚠️ Microsoft Provider 未就绪，请运行 'taskbridge auth login microsoft'\n")
+	}
+	if _, ok := providers["todoist"]; !ok && c.Adapters.Enabled("todoist") {
+		printToStderr("⚠️ Todoist Provider 未就绪，请运行 'taskbridge auth login todoist'\n")
+	}
+	if _, ok := providers["ticktick"]; !ok && c.Adapters.Enabled("ticktick") {
+		printToStderr("⚠️ TickTick Provider 未就绪，请运行 'taskbridge auth login ticktick'\n")
+	}
+	if _, ok := providers["dida"]; !ok && c.Adapters.Enabled("dida") {
+		printToStderr("⚠️ Dida Provider 未就绪，请运行 'taskbridge auth login dida'\n")
+	}
+}
+
+// watchMCPConfig 监听参与合并的配置文件（包括 profile 与 --env 叠加文件），变更后在不重启服务的情况下应用日志级别、
+// Provider 与工具策略；工具集合变化时 MCP 服务会向已连接会话发送 notifications/tools/list_changed。
+// secrets.refresh_interval 大于 0 时还会按该间隔重新加载配置，使 secret 后端（如 Vault）中轮换的凭证生效。
+func watchMCPConfig(ctx context.Context, server *taskbridgeMCP.Server, current *pkgconfig.Config) {
+	var mu sync.Mutex
+	reload := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		next, err := reloadMCPConfig(ctx, server, current)
+		if err != nil {
+			log.Warn().Str("component", "config").Strs("files", current.Files()).Err(err).Msg("config reload failed, keeping previous config")
+			return
+		}
+		current = next
+	}
+
+	for _, path := range current.Files() {
+		if err := pkgconfig.WatchFile(ctx, path, configReloadDebounce, reload); err != nil {
+			printToStderr(fmt.Sprintf("⚠️ 无法监听配置文件 %s，该文件的热加载已关闭: %v\n", path, err))
+		}
+	}
+	if interval := current.Secrets.RefreshInterval; interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					reload()
+				}
+			}
+		}()
+	}
+}
+
+// reloadMCPConfig 按启动时的 --config 重新读取配置并应用可热更新的部分，返回新的配置；校验失败时不做任何修改
+func reloadMCPConfig(ctx context.Context, server *taskbridgeMCP.Server, current *pkgconfig.Config) (*pkgconfig.Config, error) {
+	next, _, err := loadConfig(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range next.Validate() {
+		if issue.Level == pkgconfig.ValidationLevelError {
+			return nil, fmt.Errorf("%s: %s", issue.Field, issue.Message)
+		}
+	}
+
+	_ = logger.SetLevel(next.App.LogLevel)
+	server.SetToolPolicy(&next.MCP.Tools)
+	added, removed := server.ApplyProviders(loadMCPProviders(ctx, next))
+	if restart := restartRequiredChanges(current, next); len(restart) > 0 {
+		log.Warn().Str("component", "config").Strs("keys", restart).Msg("config changes require a restart to take effect")
+	}
+	log.Info().Str("component", "config").Strs("files", next.Files()).Strs("providers_added", added).Strs("providers_removed", removed).Msg("config reloaded")
+	return next, nil
+}
+
+// restartRequiredChanges 列出已变化但只能在重启后生效的配置段
+func restartRequiredChanges(prev, next *pkgconfig.Config) []string {
+	sections := []struct {
+		key        string
+		prev, next any
+	}{
+		{"storage", prev.Storage, next.Storage},
+		{"secrets", prev.Secrets, next.Secrets},
+		{"mcp.transport", prev.MCP.Transport, next.MCP.Transport},
+		{"mcp.host", prev.MCP.Host, next.MCP.Host},
+		{"mcp.port", prev.MCP.Port, next.MCP.Port},
+		{"mcp.security", prev.MCP.Security, next.MCP.Security},
+		{"mcp.cors", prev.MCP.CORS, next.MCP.CORS},
+		{"mcp.rate_limit", prev.MCP.RateLimit, next.MCP.RateLimit},
+		{"mcp.http", prev.MCP.HTTP, next.MCP.HTTP},
+		{"mcp.session", prev.MCP.Session, next.MCP.Session},
+		{"mcp.credentials", prev.MCP.Credentials, next.MCP.Credentials},
+		{"mcp.compat", prev.MCP.Compat, next.MCP.Compat},
+		{"mcp.capabilities", prev.MCP.Capabilities, next.MCP.Capabilities},
+		{"mcp.upstreams", prev.MCP.Upstreams, next.MCP.Upstreams},
+	}
+	var changed []string
+	for _, section := range sections {
+		if !reflect.DeepEqual(section.prev, section.next) {
+			changed = append(changed, section.key)
+		}
+	}
+	return changed
+}
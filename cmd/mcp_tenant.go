@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	taskbridgeMCP "github.com/yeisme/taskbridge/internal/mcp"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/provider/ticktick"
+	"github.com/yeisme/taskbridge/internal/provider/todoist"
+	"github.com/yeisme/taskbridge/internal/storage"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+	"github.com/yeisme/taskbridge/pkg/paths"
+)
+
+// newTenantProviderLoader 返回多租户模式下按租户加载 Provider 的函数：
+// 租户 key 对应同名 profile 的凭证目录（通过 taskbridge --profile <tenant> auth login 保存），
+// 请求头携带的 API Token 覆盖同名 Provider，不写入任何凭证文件
+func newTenantProviderLoader(c *pkgconfig.Config) taskbridgeMCP.TenantProviderLoader {
+	return func(ctx context.Context, tenant taskbridgeMCP.Tenant) (map[string]provider.Provider, error) {
+		var providers map[string]provider.Provider
+		credentialsDir := paths.GetProfileCredentialsDir(tenant.Key)
+		switch {
+		case tenant.Key == paths.DefaultProfile:
+			providers = loadMCPProviders(ctx, c)
+		case dirExists(credentialsDir):
+			providers = loadProvidersFromDir(ctx, c, credentialsDir, "")
+		case len(tenant.Tokens) == 0:
+			return nil, fmt.Errorf("no stored credentials for tenant %q", tenant.Key)
+		default:
+			providers = make(map[string]provider.Provider)
+		}
+
+		enabled := providerEnabledFilter(c)
+		for name, token := range tenant.Tokens {
+			resolved := provider.ResolveProviderName(name)
+			if !enabled(resolved) {
+				return nil, fmt.Errorf("provider %s is not enabled", resolved)
+			}
+			p, err := newProviderFromToken(ctx, resolved, token)
+			if err != nil {
+				return nil, err
+			}
+			providers[resolved] = p
+		}
+		return providers, nil
+	}
+}
+
+// newTenantStoreLoader 返回为租户打开独立任务存储的函数：存储位于 storage.path/tenants/<tenant>，
+// 请求头携带 token 的客户端按 token 摘要再分目录；租户存储不记录任务历史
+func newTenantStoreLoader(c *pkgconfig.Config) taskbridgeMCP.TenantStoreLoader {
+	return func(_ context.Context, tenant taskbridgeMCP.Tenant) (storage.Storage, error) {
+		if strings.EqualFold(strings.TrimSpace(c.Storage.Type), storage.BackendPostgres) {
+			return nil, fmt.Errorf("postgres storage cannot be split per tenant")
+		}
+		dir := filepath.Join(c.Storage.Path, "tenants", tenant.Key)
+		if digest := tenant.CredentialDigest(); digest != "" {
+			dir = filepath.Join(dir, "token-"+digest[:16])
+		}
+		return storage.Open(storage.Options{
+			Backend: c.Storage.Type,
+			Path:    dir,
+			Format:  c.Storage.File.Format,
+		})
+	}
+}
+
+// newProviderFromToken 使用请求头携带的 API Token 创建 Provider；OAuth Provider 需要保存在租户 profile 中的凭证
+func newProviderFromToken(ctx context.Context, name, token string) (provider.Provider, error) {
+	switch name {
+	case "todoist":
+		p, err := todoist.NewProvider(todoist.Config{APIToken: token})
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Authenticate(ctx, nil); err != nil {
+			return nil, err
+		}
+		return p, nil
+	case "ticktick", "dida":
+		p, err := ticktick.NewProvider(ticktick.Config{ProviderName: name, Token: token})
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Authenticate(ctx, nil); err != nil {
+			return nil, err
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("provider %s does not accept credentials in request headers", name)
+	}
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
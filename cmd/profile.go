@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yeisme/taskbridge/pkg/paths"
+)
+
+// profileCmd profile 管理命令
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Profile 管理",
+	Long: `管理命名 profile（如 work、personal）。
+
+每个 profile 使用独立目录 ~/.taskbridge/profiles/<name>，其中的 config.yaml 覆盖在共享配置文件之上，
+凭证（credentials）、任务数据（data）、缓存（cache）与日志（logs）也都保存在该目录下，互不影响。
+通过 --profile <name> 或环境变量 TASKBRIDGE_PROFILE 选择；未选择时使用默认 profile（~/.taskbridge）。
+
+子命令:
+  list    列出 profile
+  create  创建 profile
+  show    显示当前 profile 及其目录
+
+示例:
+  taskbridge profile create work
+  taskbridge --profile work auth login todoist
+  TASKBRIDGE_PROFILE=work taskbridge mcp start`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出 profile",
+	Run:   runProfileList,
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "创建 profile",
+	Args:  cobra.ExactArgs(1),
+	Run:   runProfileCreate,
+}
+
+var profileShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "显示当前 profile",
+	Run:   runProfileShow,
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileShowCmd)
+}
+
+// currentProfileName 返回当前 profile 名称，默认 profile 为 default
+func currentProfileName() string {
+	if profile := paths.GetProfile(); profile != "" {
+		return profile
+	}
+	return paths.DefaultProfile
+}
+
+func runProfileList(cmd *cobra.Command, args []string) {
+	names, err := paths.ListProfiles()
+	if err != nil {
+		fmt.Printf("❌ 读取 profile 失败: %v\n", err)
+		os.Exit(1)
+	}
+	current := currentProfileName()
+	for _, name := range append([]string{paths.DefaultProfile}, names...) {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Printf("%s %-16s %s\n", marker, name, paths.GetProfileDir(name))
+	}
+}
+
+func runProfileCreate(cmd *cobra.Command, args []string) {
+	name := args[0]
+	if err := paths.ValidateProfileName(name); err != nil || name == paths.DefaultProfile {
+		fmt.Printf("❌ 无效的 profile 名称: %s\n", name)
+		os.Exit(1)
+	}
+	dir := paths.GetProfileDir(name)
+	if _, err := os.Stat(dir); err == nil {
+		fmt.Printf("❌ profile 已存在: %s\n", dir)
+		os.Exit(1)
+	}
+	for _, sub := range []string{paths.CredentialsDir, "data", "cache", "logs"} {
+		if err := paths.EnsureDir(filepath.Join(dir, sub)); err != nil {
+			fmt.Printf("❌ 创建 profile 失败: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("✅ 已创建 profile %s: %s\n", name, dir)
+	fmt.Printf("   使用: taskbridge --profile %s <命令>，或设置 %s=%s\n", name, paths.ProfileEnv, name)
+	fmt.Printf("   配置: taskbridge --profile %s config set <key> <value>\n", name)
+}
+
+func runProfileShow(cmd *cobra.Command, args []string) {
+	fmt.Printf("Profile:  %s\n", currentProfileName())
+	fmt.Printf("目录:     %s\n", paths.GetAppDir())
+	fmt.Printf("凭证:     %s\n", paths.GetCredentialsDir())
+	fmt.Printf("数据:     %s\n", cfg.Storage.Path)
+	fmt.Printf("缓存:     %s\n", paths.GetCacheDir())
+	fmt.Printf("日志:     %s\n", paths.GetLogsDir())
+	if path := GetConfigFileUsed(); path != "" {
+		fmt.Printf("配置文件: %s\n", path)
+	}
+}
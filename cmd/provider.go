@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -13,9 +15,10 @@ import (
 
 // providerCmd Provider 管理命令
 var providerCmd = &cobra.Command{
-	Use:   "provider",
-	Short: "Provider 管理",
-	Long: `管理 Todo Provider。
+	Use:     "provider",
+	Aliases: []string{"adapter"},
+	Short:   "Provider 管理",
+	Long: `管理 Todo Provider（也可写作 adapter）。
 
 子命令:
   list       列出所有 Provider
@@ -26,6 +29,7 @@ var providerCmd = &cobra.Command{
 
 示例:
   taskbridge provider list
+  taskbridge adapter list --check
   taskbridge provider enable google
   taskbridge provider test google`,
 }
@@ -34,10 +38,15 @@ var providerCmd = &cobra.Command{
 var providerListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "列出所有 Provider",
-	Long:  `列出所有支持的 Provider 及其状态`,
-	Run:   runProviderList,
+	Long: `列出所有支持的 Provider 及其状态。
+
+--check 会加载已认证的 Provider，主动刷新临近过期（mcp.credentials.refresh_before）的 token，
+并请求远端确认凭证仍然可用，在工具调用失败之前提示需要重新登录的 Provider。`,
+	Run: runProviderList,
 }
 
+var providerListCheck bool
+
 // providerEnableCmd 启用 Provider
 var providerEnableCmd = &cobra.Command{
 	Use:   "enable <provider>",
@@ -81,6 +90,8 @@ func init() {
 	providerCmd.AddCommand(providerDisableCmd)
 	providerCmd.AddCommand(providerTestCmd)
 	providerCmd.AddCommand(providerInfoCmd)
+
+	providerListCmd.Flags().BoolVar(&providerListCheck, "check", false, "校验凭证：刷新临近过期的 token 并请求远端确认凭证可用")
 }
 
 // ProviderInfo Provider 信息
@@ -104,7 +115,7 @@ func getProviderInfos() map[string]ProviderInfo {
 			DisplayName: "Google Tasks",
 			Description: "Google 任务管理服务",
 			AuthType:    "OAuth2",
-			Enabled:     cfg.Providers.Google.Enabled,
+			Enabled:     cfg.Adapters.Enabled("google"),
 		},
 		"microsoft": {
 			Name:        "microsoft",
@@ -112,7 +123,7 @@ func getProviderInfos() map[string]ProviderInfo {
 			DisplayName: "Microsoft To Do",
 			Description: "微软任务管理服务",
 			AuthType:    "OAuth2",
-			Enabled:     cfg.Providers.Microsoft.Enabled,
+			Enabled:     cfg.Adapters.Enabled("microsoft"),
 		},
 		"feishu": {
 			Name:        "feishu",
@@ -120,7 +131,7 @@ func getProviderInfos() map[string]ProviderInfo {
 			DisplayName: "飞书任务",
 			Description: "飞书任务管理",
 			AuthType:    "App ID/Secret",
-			Enabled:     cfg.Providers.Feishu.Enabled,
+			Enabled:     cfg.Adapters.Enabled("feishu"),
 		},
 		"ticktick": {
 			Name:        "ticktick",
@@ -128,7 +139,7 @@ func getProviderInfos() map[string]ProviderInfo {
 			DisplayName: "TickTick",
 			Description: "TickTick 任务管理",
 			AuthType:    "API Token",
-			Enabled:     cfg.Providers.TickTick.Enabled,
+			Enabled:     cfg.Adapters.Enabled("ticktick"),
 		},
 		"dida": {
 			Name:        "dida",
@@ -136,7 +147,7 @@ func getProviderInfos() map[string]ProviderInfo {
 			DisplayName: "Dida365",
 			Description: "滴答清单（国内）",
 			AuthType:    "API Token",
-			Enabled:     cfg.Providers.Dida.Enabled,
+			Enabled:     cfg.Adapters.Enabled("dida"),
 		},
 		"todoist": {
 			Name:        "todoist",
@@ -144,13 +155,17 @@ func getProviderInfos() map[string]ProviderInfo {
 			DisplayName: "Todoist",
 			Description: "Todoist 任务管理",
 			AuthType:    "API Token",
-			Enabled:     cfg.Providers.Todoist.Enabled,
+			Enabled:     cfg.Adapters.Enabled("todoist"),
 		},
 	}
 }
 
 func runProviderList(_ *cobra.Command, _ []string) {
 	providers := getProviderInfos()
+	var health map[string]provider.CredentialHealth
+	if providerListCheck {
+		health = checkProviderCredentials()
+	}
 
 	// 使用 lipgloss table 组件
 	table := ui.NewTable("名称", "简写", "状态", "认证方式", "描述")
@@ -175,12 +190,20 @@ func runProviderList(_ *cobra.Command, _ []string) {
 				status = ui.StatusDisabled
 			}
 		}
+		if h, ok := health[name]; ok {
+			status = credentialHealthStatus(h, status)
+		}
 		table.AddRow(p.DisplayName, p.ShortName, status, p.AuthType, p.Description)
 	}
 
 	fmt.Println()
 	fmt.Println(table.Render())
 	fmt.Println()
+	for _, name := range order {
+		if h, ok := health[name]; ok && h.Warning != "" {
+			fmt.Println(ui.Warning(fmt.Sprintf("%s: %s", providers[name].DisplayName, h.Warning)))
+		}
+	}
 	fmt.Println(ui.Dim("提示: 使用 'taskbridge provider info <简写>' 查看详细信息"))
 	fmt.Println()
 }
@@ -196,20 +219,7 @@ func runProviderEnable(_ *cobra.Command, args []string) {
 	}
 
 	// 更新配置
-	switch providerName {
-	case "google":
-		cfg.Providers.Google.Enabled = true
-	case "microsoft":
-		cfg.Providers.Microsoft.Enabled = true
-	case "feishu":
-		cfg.Providers.Feishu.Enabled = true
-	case "ticktick":
-		cfg.Providers.TickTick.Enabled = true
-	case "dida":
-		cfg.Providers.Dida.Enabled = true
-	case "todoist":
-		cfg.Providers.Todoist.Enabled = true
-	}
+	cfg.Adapters.SetEnabled(providerName, true)
 
 	fmt.Println(ui.Success("Provider " + providerName + " 已启用"))
 	fmt.Println()
@@ -230,20 +240,7 @@ func runProviderDisable(_ *cobra.Command, args []string) {
 	}
 
 	// 更新配置
-	switch providerName {
-	case "google":
-		cfg.Providers.Google.Enabled = false
-	case "microsoft":
-		cfg.Providers.Microsoft.Enabled = false
-	case "feishu":
-		cfg.Providers.Feishu.Enabled = false
-	case "ticktick":
-		cfg.Providers.TickTick.Enabled = false
-	case "dida":
-		cfg.Providers.Dida.Enabled = false
-	case "todoist":
-		cfg.Providers.Todoist.Enabled = false
-	}
+	cfg.Adapters.SetEnabled(providerName, false)
 
 	fmt.Println(ui.Success("Provider " + providerName + " 已禁用"))
 }
@@ -394,3 +391,31 @@ func getProviderCapabilities(providerName string) []string {
 		return []string{"未知"}
 	}
 }
+
+// checkProviderCredentials 加载已认证的 Provider 并逐个检查凭证
+func checkProviderCredentials() map[string]provider.CredentialHealth {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	health := make(map[string]provider.CredentialHealth)
+	for name, p := range loadMCPProviders(ctx, cfg) {
+		health[name] = provider.CheckCredential(ctx, p, cfg.MCP.Credentials.RefreshBefore)
+	}
+	return health
+}
+
+// credentialHealthStatus 凭证检查结果对应的状态文字，正常时保留原状态
+func credentialHealthStatus(health provider.CredentialHealth, status string) string {
+	switch health.Status {
+	case provider.CredentialRefreshed:
+		return ui.Success("已刷新")
+	case provider.CredentialExpiring:
+		return ui.Warning("即将过期")
+	case provider.CredentialExpired:
+		return ui.StatusExpired
+	case provider.CredentialInvalid:
+		return ui.Error("凭证无效")
+	default:
+		return status
+	}
+}
@@ -2,9 +2,10 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
 
 	"github.com/rs/zerolog"
@@ -12,6 +13,7 @@ import (
 	"github.com/yeisme/taskbridge/internal/provider"
 	"github.com/yeisme/taskbridge/pkg/config"
 	"github.com/yeisme/taskbridge/pkg/logger"
+	"github.com/yeisme/taskbridge/pkg/paths"
 )
 
 func init() {
@@ -24,11 +26,14 @@ func init() {
 
 var (
 	cfgFile     string
+	cfgFileUsed string
 	verbose     bool
 	storagePath string
 	storageType string
 	logLevel    string
 	providers   string
+	profileName string
+	envName     string
 	cfg         *config.Config
 )
 
@@ -67,64 +72,51 @@ func Execute() {
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "配置文件路径（已弃用，不再读取）")
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "配置文件路径，支持 YAML/TOML（默认 ~/.taskbridge/config.yaml，可用环境变量 TASKBRIDGE_CONFIG）")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "详细输出")
 	rootCmd.PersistentFlags().StringVar(&storagePath, "storage-path", "", "任务存储路径（可用环境变量 TASKBRIDGE_STORAGE_PATH）")
-	rootCmd.PersistentFlags().StringVar(&storageType, "storage-type", "", "存储类型：file|mongodb（可用环境变量 TASKBRIDGE_STORAGE_TYPE）")
+	rootCmd.PersistentFlags().StringVar(&storageType, "storage-type", "", "存储类型：file|sqlite|bolt|postgres（可用环境变量 TASKBRIDGE_STORAGE_TYPE）")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "日志级别：debug|info|warn|error（可用环境变量 TASKBRIDGE_LOG_LEVEL）")
 	rootCmd.PersistentFlags().StringVar(&providers, "providers", "", "启用的 provider，逗号分隔（可用环境变量 TASKBRIDGE_PROVIDERS）")
-	_ = rootCmd.PersistentFlags().MarkDeprecated("config", "配置文件已弃用，请改用环境变量和命令行参数")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "使用的 profile，各 profile 有独立的配置、凭证、数据、缓存与日志目录（可用环境变量 TASKBRIDGE_PROFILE）")
+	rootCmd.PersistentFlags().StringVar(&envName, "env", "", "配置环境，在配置文件之上叠加同目录下的 config.<env>.yaml，如 --env prod（可用环境变量 TASKBRIDGE_ENV）")
 }
 
-// initConfig 初始化配置
+// initConfig 初始化配置，优先级：命令行参数 > 环境变量 > 配置文件 > 默认值
 func initConfig() {
-	cfg = config.DefaultConfig()
-
-	// 1) 环境变量覆盖
-	if v := strings.TrimSpace(os.Getenv("TASKBRIDGE_STORAGE_PATH")); v != "" {
-		cfg.Storage.Path = v
-	}
-	if v := strings.TrimSpace(os.Getenv("TASKBRIDGE_STORAGE_TYPE")); v != "" {
-		cfg.Storage.Type = v
-	}
-	if v := strings.TrimSpace(os.Getenv("TASKBRIDGE_STORAGE_FORMAT")); v != "" {
-		cfg.Storage.File.Format = v
+	if err := paths.SetProfile(profileName); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
 	}
-	if v := strings.TrimSpace(os.Getenv("TASKBRIDGE_LOG_LEVEL")); v != "" {
-		cfg.App.LogLevel = v
+	if err := config.SetEnvironment(envName); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		os.Exit(1)
 	}
-	if v := strings.TrimSpace(os.Getenv("TASKBRIDGE_MCP_TRANSPORT")); v != "" {
-		cfg.MCP.Transport = v
+	loaded, path, err := loadConfig(cfgFile)
+	var schemaErr *config.SchemaError
+	if errors.As(err, &schemaErr) && runningConfigCommand() {
+		// 配置文件不符合 schema 时仍允许 config 子命令查看与修复该文件，此时使用默认配置
+		fmt.Fprintf(os.Stderr, "⚠️ %v\n", err)
+		loaded, path, err = config.DefaultConfig(), schemaErr.File, nil
 	}
-	if v := strings.TrimSpace(os.Getenv("TASKBRIDGE_MCP_PORT")); v != "" {
-		if p, err := strconv.Atoi(v); err == nil && p > 0 {
-			cfg.MCP.Port = p
-		}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 加载配置文件失败: %v\n", err)
+		os.Exit(1)
 	}
-	applyProvidersFromList(strings.TrimSpace(os.Getenv("TASKBRIDGE_PROVIDERS")))
+	cfg = loaded
+	cfgFileUsed = path
+	configureSecretStore()
+	configureStorageEncryption()
 
-	// 2) 命令行参数覆盖环境变量
-	if storagePath != "" {
-		cfg.Storage.Path = storagePath
-	}
-	if storageType != "" {
-		cfg.Storage.Type = storageType
+	logOutput := cfg.App.ResolvedLogOutput()
+	if logOutput != cfg.App.LogOutput {
+		_ = paths.EnsureDir(filepath.Dir(logOutput))
 	}
-	if logLevel != "" {
-		cfg.App.LogLevel = logLevel
-	}
-	if verbose {
-		cfg.App.LogLevel = "debug"
-	}
-	if providers != "" {
-		applyProvidersFromList(providers)
-	}
-
 	// 初始化全局日志级别，避免调试日志误判为错误
 	if err := logger.Init(&logger.Config{
 		Level:      cfg.App.LogLevel,
-		Format:     "json",
-		Output:     "stderr",
+		Format:     cfg.App.LogFormat,
+		Output:     logOutput,
 		TimeFormat: "",
 		Caller:     false,
 	}); err != nil {
@@ -134,35 +126,60 @@ func initConfig() {
 	}
 }
 
-func applyProvidersFromList(value string) {
+// runningConfigCommand 判断本次执行的是否为 config 子命令
+func runningConfigCommand() bool {
+	command, _, err := rootCmd.Find(os.Args[1:])
+	return err == nil && command != nil && command.HasParent() && command.Parent() == configCmd
+}
+
+// loadConfig 合并配置文件（--config 指定，或默认位置的 config.yaml/config.toml）、TASKBRIDGE_* 环境变量与已设置的命令行参数；
+// 配置热加载时也通过它重新读取，保证命令行参数始终优先。
+func loadConfig(path string) (*config.Config, string, error) {
+	bindings := config.FlagBindings{
+		"storage.path":  rootCmd.PersistentFlags().Lookup("storage-path"),
+		"storage.type":  rootCmd.PersistentFlags().Lookup("storage-type"),
+		"app.log_level": rootCmd.PersistentFlags().Lookup("log-level"),
+	}
+	if startCmd, _, err := rootCmd.Find([]string{"mcp", "start"}); err == nil {
+		bindings["mcp.transport"] = startCmd.Flags().Lookup("transport")
+		bindings["mcp.host"] = startCmd.Flags().Lookup("host")
+		bindings["mcp.port"] = startCmd.Flags().Lookup("port")
+	}
+	loaded, used, err := config.LoadWithFlags(path, bindings)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// --providers / TASKBRIDGE_PROVIDERS 是按列表启用 provider 的快捷方式
+	applyProvidersFromList(loaded, strings.TrimSpace(os.Getenv("TASKBRIDGE_PROVIDERS")), "env TASKBRIDGE_PROVIDERS")
+	if providers != "" {
+		applyProvidersFromList(loaded, providers, "flag --providers")
+	}
+	if verbose {
+		loaded.App.LogLevel = "debug"
+		loaded.SetSource("app.log_level", "flag --verbose")
+	}
+	return loaded, used, nil
+}
+
+func applyProvidersFromList(cfg *config.Config, value, source string) {
 	if strings.TrimSpace(value) == "" {
 		return
 	}
 
 	// 清空后按列表启用
-	cfg.Providers.Google.Enabled = false
-	cfg.Providers.Microsoft.Enabled = false
-	cfg.Providers.Feishu.Enabled = false
-	cfg.Providers.TickTick.Enabled = false
-	cfg.Providers.Dida.Enabled = false
-	cfg.Providers.Todoist.Enabled = false
+	for _, name := range cfg.Adapters.Names() {
+		cfg.Adapters.SetEnabled(name, false)
+		cfg.SetSource("adapters."+name+".enabled", source)
+	}
 
 	for _, raw := range strings.Split(value, ",") {
 		name := provider.ResolveProviderName(strings.TrimSpace(raw))
-		switch name {
-		case "google":
-			cfg.Providers.Google.Enabled = true
-		case "microsoft":
-			cfg.Providers.Microsoft.Enabled = true
-		case "feishu":
-			cfg.Providers.Feishu.Enabled = true
-		case "ticktick":
-			cfg.Providers.TickTick.Enabled = true
-		case "dida":
-			cfg.Providers.Dida.Enabled = true
-		case "todoist":
-			cfg.Providers.Todoist.Enabled = true
-		case "":
+		switch {
+		case provider.IsValidProvider(name):
+			cfg.Adapters.SetEnabled(name, true)
+			cfg.SetSource("adapters."+name+".enabled", source)
+		case name == "":
 			// ignore empty entry
 		default:
 			fmt.Fprintf(os.Stderr, "警告: 忽略未知 provider: %s\n", raw)
@@ -174,3 +191,8 @@ func applyProvidersFromList(value string) {
 func GetConfig() *config.Config {
 	return cfg
 }
+
+// GetConfigFileUsed 返回本次运行读取的配置文件路径，未使用配置文件时为空
+func GetConfigFileUsed() string {
+	return cfgFileUsed
+}
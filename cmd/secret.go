@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/yeisme/taskbridge/pkg/paths"
+	"github.com/yeisme/taskbridge/pkg/secretstore"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+	"github.com/yeisme/taskbridge/pkg/tokenstore"
+)
+
+// secretCmd 敏感信息管理命令
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "敏感信息管理",
+	Long: `管理保存在 secrets.backend 中的敏感信息（client secret、API token 等）。
+
+secrets.backend 为 keychain 时使用系统凭证存储（macOS Keychain、Linux libsecret、Windows DPAPI），
+为 encrypted 时使用以口令加密的文件（适用于无头服务器，口令来自 TASKBRIDGE_SECRETS_PASSPHRASE
+或 secrets.passphrase_file），为 vault 时读写 HashiCorp Vault 的 KV v2 引擎（地址与 token 来自
+secrets.vault.address/VAULT_ADDR 与 VAULT_TOKEN/secrets.vault.token_file，敏感信息不落盘），
+以上后端都会同时保存 provider 的 OAuth token；为 file（默认）时保存在 ~/.taskbridge/credentials 下。
+配置文件中的值写作 "secret:<name>" 即可引用同名条目；vault 后端可用 "secret:<name>#<field>" 引用条目中的指定字段。
+
+子命令:
+  set     保存敏感信息（从终端或 stdin 读取，不经过命令行参数）
+  delete  删除敏感信息
+  migrate 将 token 文件中的 provider token 迁移到当前后端
+  encrypt 将明文 secrets 文件加密为 encrypted 后端的文件
+  decrypt 解密 encrypted 后端的文件
+
+示例:
+  taskbridge config set secrets.backend keychain
+  taskbridge secret set google-client-secret
+  taskbridge config set adapters.google.client_secret secret:google-client-secret
+  taskbridge secret migrate
+  TASKBRIDGE_SECRETS_PASSPHRASE=... taskbridge secret encrypt`,
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "保存敏感信息",
+	Args:  cobra.ExactArgs(1),
+	Run:   runSecretSet,
+}
+
+var secretDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "删除敏感信息",
+	Args:  cobra.ExactArgs(1),
+	Run:   runSecretDelete,
+}
+
+var secretMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "迁移 provider token 到当前后端",
+	Long:  `将 ~/.taskbridge/credentials/tokens.json 中的 provider token 写入 secrets.backend 指定的后端并从文件中移除（需先设置 secrets.backend 为 keychain、encrypted 或 vault）。`,
+	Run:   runSecretMigrate,
+}
+
+var secretEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "加密 secrets 文件",
+	Long: `将明文 secrets 文件（默认 ~/.taskbridge/credentials/secrets.json，键值均为字符串的 JSON 对象）
+加密为 encrypted 后端的文件（默认 secrets.file 或 ~/.taskbridge/credentials/secrets.enc）。
+口令依次取自 TASKBRIDGE_SECRETS_PASSPHRASE、secrets.passphrase_file 或终端输入。`,
+	Run: runSecretEncrypt,
+}
+
+var secretDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "解密 secrets 文件",
+	Long:  `解密 encrypted 后端的文件，默认输出到 stdout；--output 指定文件时以 0600 权限写入。`,
+	Run:   runSecretDecrypt,
+}
+
+var (
+	secretInput  string
+	secretOutput string
+)
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretSetCmd)
+	secretCmd.AddCommand(secretDeleteCmd)
+	secretCmd.AddCommand(secretMigrateCmd)
+	secretCmd.AddCommand(secretEncryptCmd)
+	secretCmd.AddCommand(secretDecryptCmd)
+
+	for _, c := range []*cobra.Command{secretEncryptCmd, secretDecryptCmd} {
+		c.Flags().StringVarP(&secretInput, "input", "i", "", "输入文件路径")
+		c.Flags().StringVarP(&secretOutput, "output", "o", "", "输出文件路径")
+	}
+}
+
+// openSecretStore 按配置打开敏感信息存储后端
+func openSecretStore() secretstore.Store {
+	store, err := cfg.Secrets.OpenStore()
+	if err != nil {
+		fmt.Printf("❌ 打开敏感信息存储失败: %v\n", err)
+		os.Exit(1)
+	}
+	return store
+}
+
+// configureSecretStore 在 secrets.backend 不是 file 时将 provider token 改为保存到该后端
+func configureSecretStore() {
+	if strings.EqualFold(strings.TrimSpace(cfg.Secrets.Backend), secretstore.BackendFile) || strings.TrimSpace(cfg.Secrets.Backend) == "" {
+		tokenstore.UseSecretStore(nil)
+		return
+	}
+	store, err := cfg.Secrets.OpenStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ 无法打开 %s 敏感信息存储，token 仍保存在文件中: %v\n", cfg.Secrets.Backend, err)
+		tokenstore.UseSecretStore(nil)
+		return
+	}
+	tokenstore.UseSecretStore(store)
+}
+
+// configureStorageEncryption 在 storage.encryption.enabled 时解锁数据密钥，之后本地缓存与状态文件加密读写；
+// 无法解锁时退出，避免把数据以明文写入已加密的存储目录（config 子命令仍可运行以修复配置）
+func configureStorageEncryption() {
+	if !cfg.Storage.Encryption.Enabled {
+		storecrypt.Use(nil)
+		return
+	}
+	key, err := cfg.Storage.Encryption.UnlockKey(cfg.Storage.Path, cfg.Secrets.Service)
+	if err != nil {
+		if runningConfigCommand() {
+			fmt.Fprintf(os.Stderr, "⚠️ 无法解锁静态加密: %v\n", err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "❌ 无法解锁静态加密: %v\n", err)
+		os.Exit(1)
+	}
+	storecrypt.Use(key)
+}
+
+// readSecretValue 从终端（不回显）或 stdin 读取敏感信息
+func readSecretValue(name string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "请输入 %s: ", name)
+		data, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return strings.TrimSpace(string(data)), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// encryptedSecretsPath 返回 encrypted 后端的文件路径
+func encryptedSecretsPath() string {
+	if strings.TrimSpace(cfg.Secrets.File) != "" {
+		return cfg.Secrets.File
+	}
+	return filepath.Join(paths.GetCredentialsDir(), secretstore.EncryptedFileName)
+}
+
+// readSecretsPassphrase 依次从环境变量、口令文件与终端读取口令；confirm 时终端输入需要确认
+func readSecretsPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(secretstore.PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	if strings.TrimSpace(cfg.Secrets.PassphraseFile) != "" {
+		return secretstore.ReadPassphraseFile(cfg.Secrets.PassphraseFile)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("未提供口令：设置 %s 或 secrets.passphrase_file", secretstore.PassphraseEnv)
+	}
+	passphrase, err := readSecretValue("口令")
+	if err != nil || !confirm {
+		return passphrase, err
+	}
+	again, err := readSecretValue("确认口令")
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", fmt.Errorf("两次输入的口令不一致")
+	}
+	return passphrase, nil
+}
+
+func runSecretSet(cmd *cobra.Command, args []string) {
+	name := args[0]
+	value, err := readSecretValue(name)
+	if err != nil {
+		fmt.Printf("❌ 读取输入失败: %v\n", err)
+		os.Exit(1)
+	}
+	if value == "" {
+		fmt.Println("❌ 值不能为空")
+		os.Exit(1)
+	}
+	store := openSecretStore()
+	if err := store.Set(name, value); err != nil {
+		fmt.Printf("❌ 保存失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ 已保存到 %s，在配置中使用 %s%s 引用\n", store.Name(), secretstore.RefPrefix, name)
+}
+
+func runSecretDelete(cmd *cobra.Command, args []string) {
+	store := openSecretStore()
+	if err := store.Delete(args[0]); err != nil {
+		fmt.Printf("❌ 删除失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ 已从 %s 删除 %s\n", store.Name(), args[0])
+}
+
+func runSecretMigrate(cmd *cobra.Command, args []string) {
+	if strings.EqualFold(strings.TrimSpace(cfg.Secrets.Backend), secretstore.BackendFile) || strings.TrimSpace(cfg.Secrets.Backend) == "" {
+		fmt.Println("❌ secrets.backend 为 file，无需迁移。请先将 secrets.backend 设为 keychain、encrypted 或 vault")
+		os.Exit(1)
+	}
+	configureSecretStore()
+
+	migrated := 0
+	for _, providerName := range getAuthProviderOrder() {
+		tokenPath := paths.GetTokenPath(providerName)
+		raw, err := tokenstore.LoadRaw(tokenPath, providerName)
+		if err != nil {
+			continue
+		}
+		// SaveRaw 写入 secret store 后会从 token 文件中移除该 provider
+		if err := tokenstore.SaveRaw(tokenPath, providerName, raw); err != nil {
+			fmt.Printf("❌ %s: %v\n", providerName, err)
+			continue
+		}
+		migrated++
+		fmt.Printf("✅ %s token 已迁移\n", providerName)
+	}
+	if migrated == 0 {
+		fmt.Println("没有需要迁移的 token")
+	}
+}
+
+func runSecretEncrypt(cmd *cobra.Command, args []string) {
+	input := secretInput
+	if input == "" {
+		input = filepath.Join(paths.GetCredentialsDir(), secretstore.SecretsFileName)
+	}
+	output := secretOutput
+	if output == "" {
+		output = encryptedSecretsPath()
+	}
+
+	plaintext, err := os.ReadFile(input)
+	if err != nil {
+		fmt.Printf("❌ 读取 %s 失败: %v\n", input, err)
+		os.Exit(1)
+	}
+	var entries map[string]string
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		fmt.Printf("❌ %s 不是键值均为字符串的 JSON 对象: %v\n", input, err)
+		os.Exit(1)
+	}
+	passphrase, err := readSecretsPassphrase(true)
+	if err != nil || passphrase == "" {
+		fmt.Printf("❌ 读取口令失败: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := secretstore.Encrypt(plaintext, passphrase)
+	if err != nil {
+		fmt.Printf("❌ 加密失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := secretstore.WriteFileAtomic(output, data, 0600); err != nil {
+		fmt.Printf("❌ 写入 %s 失败: %v\n", output, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ 已加密 %d 个条目到 %s\n", len(entries), output)
+	fmt.Printf("   确认无误后删除明文文件 %s，并运行: taskbridge config set secrets.backend encrypted\n", input)
+}
+
+func runSecretDecrypt(cmd *cobra.Command, args []string) {
+	input := secretInput
+	if input == "" {
+		input = encryptedSecretsPath()
+	}
+	data, err := os.ReadFile(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 读取 %s 失败: %v\n", input, err)
+		os.Exit(1)
+	}
+	passphrase, err := readSecretsPassphrase(false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 读取口令失败: %v\n", err)
+		os.Exit(1)
+	}
+	plaintext, err := secretstore.Decrypt(data, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 解密失败: %v\n", err)
+		os.Exit(1)
+	}
+	if secretOutput == "" {
+		fmt.Println(string(plaintext))
+		return
+	}
+	if err := os.WriteFile(secretOutput, plaintext, 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ 写入 %s 失败: %v\n", secretOutput, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "✅ 已解密到 %s\n", secretOutput)
+}
@@ -222,6 +222,7 @@ func createGoogleProvider() (*google.Provider, error) {
 	provider, err := google.NewProvider(google.Config{
 		CredentialsFile: credentialsPath,
 		TokenFile:       paths.GetTokenPath("google"),
+		Subject:         googleImpersonateSubject(),
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	stdsync "sync"
+
+	"github.com/yeisme/taskbridge/internal/history"
+	"github.com/yeisme/taskbridge/internal/storage"
+
+	// 注册内置的 file、sqlite、bolt、postgres 存储后端
+	_ "github.com/yeisme/taskbridge/internal/storage/boltstore"
+	_ "github.com/yeisme/taskbridge/internal/storage/filestore"
+	_ "github.com/yeisme/taskbridge/internal/storage/pgstore"
+	_ "github.com/yeisme/taskbridge/internal/storage/sqlitestore"
+)
+
+var (
+	taskHistoryOnce  stdsync.Once
+	taskHistoryStore *history.Store
+)
+
+// openTaskHistory 打开任务历史；storage.history.enabled 为 false 或打开失败时返回 nil（打开失败只提示，不影响命令执行）
+func openTaskHistory() *history.Store {
+	taskHistoryOnce.Do(func() {
+		if !cfg.Storage.History.Enabled {
+			return
+		}
+		store, err := history.Open(cfg.Storage.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  打开任务历史失败，本次修改不会记录历史: %v\n", err)
+			return
+		}
+		taskHistoryStore = store
+	})
+	return taskHistoryStore
+}
+
+// openTaskStore 按 storage.type 打开任务存储；启用任务历史时，经由它的修改记录到历史，来源为 origin
+func openTaskStore(origin string) (storage.Storage, error) {
+	store, err := storage.Open(storage.Options{
+		Backend: cfg.Storage.Type,
+		Path:    cfg.Storage.Path,
+		Format:  cfg.Storage.File.Format,
+		DSN:     cfg.Storage.Postgres.DSN,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return history.Wrap(store, openTaskHistory(), origin), nil
+}
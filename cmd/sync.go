@@ -1,22 +1,28 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/yeisme/taskbridge/internal/history"
 	"github.com/yeisme/taskbridge/internal/provider"
 	"github.com/yeisme/taskbridge/internal/provider/feishu"
 	"github.com/yeisme/taskbridge/internal/provider/google"
 	"github.com/yeisme/taskbridge/internal/provider/microsoft"
 	"github.com/yeisme/taskbridge/internal/provider/ticktick"
 	"github.com/yeisme/taskbridge/internal/provider/todoist"
-	"github.com/yeisme/taskbridge/internal/storage/filestore"
 	"github.com/yeisme/taskbridge/internal/sync"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
 	"github.com/yeisme/taskbridge/pkg/ui"
 )
 
@@ -35,7 +41,126 @@ var syncCmd = &cobra.Command{
   taskbridge sync pull google
   taskbridge sync push google --dry-run
   taskbridge sync bidirectional google
-  taskbridge sync watch google --interval 5m`,
+  taskbridge sync watch google --interval 5m
+  taskbridge sync mirror todoist microsoft
+  taskbridge sync start --pair todoist:microsoft --interval 5m
+  taskbridge sync undo <run-id>`,
+}
+
+// syncStartCmd 镜像守护进程命令
+var syncStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "以守护进程方式持续镜像 Provider 对",
+	Long: `在前台持续运行，按间隔对每个 --pair 执行增量镜像（与 sync mirror 相同）。
+
+启动后立即执行一轮，之后每隔 --interval 执行一次；配置 sync.pairs 中设置了 schedule 的对
+改为按各自的 cron 表达式运行（如 "*/15 8-18 * * 1-5" 只在工作日 8–18 点镜像）。收到 Ctrl+C 或 SIGTERM 时
+等待当前一轮完成并保存镜像状态后退出；再次按下 Ctrl+C 强制退出。
+运行状态写入存储目录的 mirror_daemon.json，可在其他终端用 sync status 查看。
+未指定 --pair 时镜像配置 sync.pairs 中的全部 Provider 对，冲突策略见 sync mirror --help。
+
+示例:
+  taskbridge sync start
+  taskbridge sync start --pair todoist:microsoft
+  taskbridge sync start --pair todoist:microsoft --pair google:ticktick --interval 10m`,
+	Args: cobra.NoArgs,
+	Run:  runSyncStart,
+}
+
+// syncMirrorCmd 两个 Provider 之间双向镜像
+var syncMirrorCmd = &cobra.Command{
+	Use:   "mirror <provider> <provider>",
+	Short: "在两个 Provider 之间双向镜像任务",
+	Long: `让两个 Provider 中的任务互为镜像：任一侧的新建、修改、完成与删除都会应用到另一侧。
+
+首次镜像时两侧同名列表中标题相同的任务直接配对，其余未完成任务复制到另一侧的同名列表（不存在时创建）。
+镜像关系保存在存储目录的 mirror_state.db 中（sync.state_store 为 file 时为 mirror_state.json）。两侧都修改了同一任务时按冲突策略处理：
+  newer             以更新时间较新的一侧为准（默认，last-write-wins）
+  left / right      以左侧或右侧为准，也可以直接填写 Provider 名称
+  merge             字段级合并，只有一侧修改的字段取该侧，两侧都修改的字段取较新的一侧
+  manual            两侧都不修改，冲突排队，用 sync conflicts 查看、sync resolve 选择保留哪一侧
+未指定 --conflict 时使用配置 sync.pairs 中该对的 conflict。
+
+支持增量读取的 Provider（Microsoft Graph delta、Todoist sync_token）在建立镜像关系后只读取变更的任务，
+--full 忽略保存的游标，全量读取两侧。
+
+--source 指定来源时为单向镜像：来源的变更写入另一侧，另一侧独有的任务不会复制到来源，
+另一侧对镜像任务的修改、完成与删除按 --mirror-edits 处理：
+  overwrite         以来源的内容覆盖，被删除的副本重新创建（默认）
+  flag              保留修改并排队，用 sync conflicts 查看、sync resolve 选择保留来源（覆盖）或镜像侧（接受修改）
+未指定时使用配置 sync.pairs 中该对的 source 与 mirror_edits。
+
+一侧删除任务后另一侧的镜像任务按 --on-delete 处理：delete 一并删除（默认），archive 标记为完成；
+未指定时使用配置 sync.pairs 中该对的 on_delete。因删除而结束的镜像关系记录为墓碑（保留 90 天），
+已删除的任务再次出现时（如 Provider 返回删除后的残留）不会被重新复制到另一侧。
+
+每次镜像应用的变更（含变更前后的任务内容）追加到存储目录的 mirror_journal.jsonl，结果中的运行 ID
+可用于 sync undo 撤销该次镜像。
+
+示例:
+  taskbridge sync mirror todoist microsoft
+  taskbridge sync mirror todoist microsoft --dry-run
+  taskbridge sync mirror todoist microsoft --conflict todoist
+  taskbridge sync mirror todoist microsoft --conflict manual
+  taskbridge sync mirror todoist microsoft --on-delete archive
+  taskbridge sync mirror microsoft todoist --source microsoft --mirror-edits flag`,
+	Args: cobra.ExactArgs(2),
+	Run:  runSyncMirror,
+}
+
+// syncConflictsCmd 查看排队的镜像冲突
+var syncConflictsCmd = &cobra.Command{
+	Use:   "conflicts <provider> <provider>",
+	Short: "查看等待人工处理的镜像冲突",
+	Long: `列出 manual 冲突策略下排队的冲突：两侧都修改了同一任务，且尚未选择保留哪一侧；
+以及单向镜像（mirror_edits 为 flag）时被标记的镜像侧修改。
+
+示例:
+  taskbridge sync conflicts todoist microsoft
+  taskbridge sync conflicts todoist microsoft -o json`,
+	Args: cobra.ExactArgs(2),
+	Run:  runSyncConflicts,
+}
+
+// syncResolveCmd 处理排队的镜像冲突
+var syncResolveCmd = &cobra.Command{
+	Use:   "resolve <provider> <provider> [conflict-id]",
+	Short: "处理排队的镜像冲突",
+	Long: `为排队的冲突选择保留哪一侧，或逐字段选择取值的一侧合并两侧的修改；
+下次 sync mirror 或 sync start 时将选择的内容写入两侧。
+
+未指定 --keep 与 --field 时逐个交互处理等待中的冲突（指定 conflict-id 时只处理该冲突），
+每个冲突可选择保留一侧、逐字段合并或跳过。
+
+可逐字段选择的字段: title, description, status, priority, due, tags
+
+示例:
+  taskbridge sync resolve todoist microsoft
+  taskbridge sync resolve todoist microsoft 3f2a9c1e --keep todoist
+  taskbridge sync resolve todoist microsoft 3f2a9c1e --keep todoist --field priority=microsoft`,
+	Args: cobra.RangeArgs(2, 3),
+	Run:  runSyncResolve,
+}
+
+// syncUndoCmd 撤销一次镜像运行
+var syncUndoCmd = &cobra.Command{
+	Use:   "undo [run-id]",
+	Short: "撤销一次镜像运行",
+	Long: `按镜像变更日志（存储目录的 mirror_journal.jsonl）逆序撤销一次 sync mirror 或 sync start 运行：
+删除该次新建的任务，恢复被修改、完成或归档的任务的字段，重新创建被删除的任务（Provider 分配新的 ID）。
+
+运行之后又被修改过的任务默认跳过，之后可用 --force 再次撤销这些被跳过的变更（已撤销的变更不会重复处理）。
+撤销只修改该次运行写入的一侧，恢复的内容作为新的同步基准，下次镜像不会把撤销的变更再应用回来；
+重新创建的任务会再复制到另一侧。撤销本身同样记录在变更日志中。
+
+未指定 run-id 时列出变更日志中最近的运行。
+
+示例:
+  taskbridge sync undo
+  taskbridge sync undo 20260301T080000-3f2a --dry-run
+  taskbridge sync undo 20260301T080000-3f2a`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runSyncUndo,
 }
 
 // syncPullCmd 拉取命令
@@ -108,6 +233,15 @@ var (
 	syncInterval     time.Duration
 	syncOutput       string
 	syncDeleteRemote bool
+	syncConflict     string
+	syncPairs        []string
+	syncKeep         string
+	syncFields       map[string]string
+	syncFull         bool
+	syncSource       string
+	syncMirrorEdits  string
+	syncOnDelete     string
+	syncUndoLimit    int
 )
 
 func init() {
@@ -117,6 +251,11 @@ func init() {
 	syncCmd.AddCommand(syncBidirectionalCmd)
 	syncCmd.AddCommand(syncWatchCmd)
 	syncCmd.AddCommand(syncStatusCmd)
+	syncCmd.AddCommand(syncMirrorCmd)
+	syncCmd.AddCommand(syncStartCmd)
+	syncCmd.AddCommand(syncConflictsCmd)
+	syncCmd.AddCommand(syncResolveCmd)
+	syncCmd.AddCommand(syncUndoCmd)
 
 	// 通用选项
 	for _, cmd := range []*cobra.Command{syncPullCmd, syncPushCmd, syncBidirectionalCmd} {
@@ -125,6 +264,32 @@ func init() {
 		cmd.Flags().StringVarP(&syncOutput, "output", "o", "text", "输出格式 (text, json)")
 	}
 
+	// mirror 命令选项
+	syncMirrorCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "模拟执行，只统计将要应用的变更")
+	syncMirrorCmd.Flags().StringVar(&syncConflict, "conflict", "newer", "冲突策略 (newer, left, right, <provider>, merge, manual)")
+	syncMirrorCmd.Flags().StringVarP(&syncOutput, "output", "o", "text", "输出格式 (text, json)")
+	syncMirrorCmd.Flags().BoolVar(&syncFull, "full", false, "忽略增量游标，全量读取两侧")
+	syncMirrorCmd.Flags().StringVar(&syncSource, "source", "", "单向镜像的来源 Provider，变更只从该侧流向另一侧")
+	syncMirrorCmd.Flags().StringVar(&syncMirrorEdits, "mirror-edits", "overwrite", "单向镜像时镜像侧修改的处理 (overwrite, flag)")
+	syncMirrorCmd.Flags().StringVar(&syncOnDelete, "on-delete", "delete", "一侧删除任务后另一侧镜像任务的处理 (delete, archive)")
+
+	// start 命令选项
+	syncStartCmd.Flags().StringArrayVar(&syncPairs, "pair", nil, "要镜像的 Provider 对，格式 <provider>:<provider>，可重复指定")
+	syncStartCmd.Flags().DurationVar(&syncInterval, "interval", 5*time.Minute, "镜像间隔")
+	syncStartCmd.Flags().StringVar(&syncConflict, "conflict", "newer", "冲突策略 (newer, left, right, <provider>, merge, manual)")
+	syncStartCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "模拟执行，只统计将要应用的变更")
+	syncStartCmd.Flags().StringVar(&syncOnDelete, "on-delete", "delete", "一侧删除任务后另一侧镜像任务的处理 (delete, archive)")
+
+	// conflicts / resolve 命令选项
+	syncConflictsCmd.Flags().StringVarP(&syncOutput, "output", "o", "text", "输出格式 (text, json)")
+	syncResolveCmd.Flags().StringVar(&syncKeep, "keep", "", "保留哪一侧的内容 (Provider 名称, left, right)")
+	// undo 命令选项
+	syncUndoCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "只统计将要撤销的变更，不修改任一侧")
+	syncUndoCmd.Flags().BoolVar(&syncForce, "force", false, "运行之后又被修改过的任务也撤销")
+	syncUndoCmd.Flags().StringVarP(&syncOutput, "output", "o", "text", "输出格式 (text, json)")
+	syncUndoCmd.Flags().IntVar(&syncUndoLimit, "limit", 20, "未指定 run-id 时列出的运行数")
+	syncResolveCmd.Flags().StringToStringVar(&syncFields, "field", nil, "逐字段选择取值的一侧，格式 <field>=<provider>，未指定的字段取 --keep 一侧")
+
 	// push 命令特有选项
 	syncPushCmd.Flags().BoolVar(&syncDeleteRemote, "delete", false, "删除远程存在但本地不存在的任务")
 
@@ -143,7 +308,7 @@ func getSyncEngineForProvider(providerName string) (*sync.Engine, error) {
 	providerName = provider.ResolveProviderName(providerName)
 
 	// 创建存储
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginSync)
 	if err != nil {
 		return nil, fmt.Errorf("创建存储失败: %w", err)
 	}
@@ -154,7 +319,7 @@ func getSyncEngineForProvider(providerName string) (*sync.Engine, error) {
 	// 初始化 Google Provider
 	if providerName == "" || providerName == "google" {
 		//即使配置中未启用，如果用户明确指定了 google，也尝试初始化
-		googleProvider, err := google.NewProviderFromHome()
+		googleProvider, err := google.NewProviderFromHomeWithSubject(googleImpersonateSubject())
 		if err != nil {
 			if providerName == "google" {
 				return nil, fmt.Errorf("初始化 Google Provider 失败: %w\n请运行 'taskbridge auth google' 进行认证", err)
@@ -358,6 +523,638 @@ func runSyncWatch(cmd *cobra.Command, args []string) {
 	}
 }
 
+// runSyncMirror 执行双向镜像
+func runSyncMirror(cmd *cobra.Command, args []string) {
+	left := provider.ResolveProviderName(args[0])
+	right := provider.ResolveProviderName(args[1])
+
+	engine, err := getSyncEngine()
+	if err != nil {
+		fmt.Printf("❌ 初始化同步引擎失败: %v\n", err)
+		os.Exit(1)
+	}
+	store, closeStore, err := openMirrorStore()
+	if err != nil {
+		fmt.Printf("❌ 初始化镜像状态存储失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeStore()
+	journal, err := openMirrorJournal()
+	if err != nil {
+		fmt.Printf("❌ 初始化镜像变更日志失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	source, mirrorEdits := mirrorOneWay(cmd, left, right)
+	result, err := engine.Mirror(context.Background(), store, sync.MirrorOptions{
+		Left:            left,
+		Right:           right,
+		DryRun:          syncDryRun,
+		Full:            syncFull,
+		ConflictResolve: mirrorConflictStrategy(cmd, left, right),
+		Filter:          mirrorFilter(left, right),
+		FieldMap:        mirrorFieldMap(left, right),
+		Source:          source,
+		MirrorEdits:     mirrorEdits,
+		OnDelete:        mirrorOnDelete(cmd, left, right),
+		Journal:         journal,
+	})
+	if err != nil {
+		fmt.Printf("❌ 镜像失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	printMirrorResult(result)
+}
+
+// runSyncStart 以守护进程方式持续镜像
+func runSyncStart(cmd *cobra.Command, args []string) {
+	values := syncPairs
+	if len(values) == 0 {
+		for _, pair := range cfg.Sync.Pairs {
+			values = append(values, pair.Left+":"+pair.Right)
+		}
+	}
+	if len(values) == 0 {
+		fmt.Println("❌ 请用 --pair 指定要镜像的 Provider 对，或在配置 sync.pairs 中添加")
+		os.Exit(1)
+	}
+	pairs := make([]sync.MirrorPair, 0, len(values))
+	for _, value := range values {
+		pair, err := sync.ParseMirrorPair(value, provider.ResolveProviderName)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		pair.Conflict = mirrorConflictStrategy(cmd, pair.Left, pair.Right)
+		pair.Filter = mirrorFilter(pair.Left, pair.Right)
+		pair.FieldMap = mirrorFieldMap(pair.Left, pair.Right)
+		pair.Source, pair.MirrorEdits = mirrorOneWay(cmd, pair.Left, pair.Right)
+		pair.OnDelete = mirrorOnDelete(cmd, pair.Left, pair.Right)
+		if configured := cfg.Sync.Pair(pair.Left, pair.Right); configured != nil {
+			pair.Schedule = strings.TrimSpace(configured.Schedule)
+		}
+		if pair.Schedule != "" {
+			if _, err := sync.ParseMirrorSchedule(pair.Schedule); err != nil {
+				fmt.Printf("❌ %s 的 schedule 无效: %v\n", pair, err)
+				os.Exit(1)
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+	if syncInterval <= 0 {
+		fmt.Println("❌ --interval 必须大于 0")
+		os.Exit(1)
+	}
+
+	engine, err := getSyncEngine()
+	if err != nil {
+		fmt.Printf("❌ 初始化同步引擎失败: %v\n", err)
+		os.Exit(1)
+	}
+	store, closeStore, err := openMirrorStore()
+	if err != nil {
+		fmt.Printf("❌ 初始化镜像状态存储失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeStore()
+	journal, err := openMirrorJournal()
+	if err != nil {
+		fmt.Printf("❌ 初始化镜像变更日志失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	daemon := sync.NewMirrorDaemon(engine, store, pairs, syncInterval, sync.MirrorOptions{
+		DryRun:          syncDryRun,
+		ConflictResolve: syncConflict,
+		Journal:         journal,
+	}, cfg.Storage.Path)
+	daemon.OnRun = printMirrorPairStatus
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		fmt.Println("\n⏳ 正在停止，等待当前一轮镜像完成（再次按 Ctrl+C 强制退出）...")
+		cancel()
+		<-sigChan
+		fmt.Println("⚠️ 强制退出")
+		os.Exit(1)
+	}()
+
+	fmt.Printf("🔄 开始持续镜像 %d 对 Provider (间隔: %v)\n", len(pairs), syncInterval)
+	for _, pair := range pairs {
+		line := fmt.Sprintf("   %s ⇄ %s", pair.Left, pair.Right)
+		if pair.Source != "" {
+			line = fmt.Sprintf("   %s → %s (单向, %s)", pair.Source, otherMirrorSide(pair.Left, pair.Right, pair.Source), pair.MirrorEdits)
+		}
+		if pair.Schedule != "" {
+			line += fmt.Sprintf(" [cron: %s]", pair.Schedule)
+		}
+		if pair.OnDelete == sync.MirrorDeleteArchive {
+			line += " [删除时归档]"
+		}
+		fmt.Println(line)
+	}
+	fmt.Println("按 Ctrl+C 停止")
+
+	if err := daemon.Run(ctx); err != nil {
+		fmt.Printf("❌ 持续镜像失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("\n✅ 已停止")
+	for _, status := range daemon.Status().Pairs {
+		fmt.Printf("   %s: 运行 %d 次，失败 %d 次\n", status.Pair, status.Runs, status.Failures)
+	}
+}
+
+// openMirrorStore 按 sync.state_store 打开镜像状态存储，返回的函数用于关闭
+func openMirrorStore() (sync.MirrorStore, func(), error) {
+	if strings.EqualFold(cfg.Sync.StateStore, "file") {
+		store, err := sync.NewFileMirrorStore(cfg.Storage.Path)
+		return store, func() {}, err
+	}
+	store, err := sync.NewSQLiteMirrorStore(cfg.Storage.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, func() { _ = store.Close() }, nil
+}
+
+// openMirrorJournal 打开镜像变更日志；启用任务历史时，镜像应用的变更同时记录到任务历史
+func openMirrorJournal() (sync.MirrorJournal, error) {
+	journal, err := sync.NewFileMirrorJournal(cfg.Storage.Path)
+	if err != nil {
+		return nil, err
+	}
+	return history.WrapMirrorJournal(journal, openTaskHistory()), nil
+}
+
+// mirrorConflictStrategy 返回一对 Provider 的冲突策略：--conflict 优先，其次是配置 sync.pairs 中的 conflict；
+// 配置中的 left、right 指配置里的顺序，与命令行顺序相反时互换
+func mirrorConflictStrategy(cmd *cobra.Command, left, right string) string {
+	if !cmd.Flags().Changed("conflict") {
+		if pair := cfg.Sync.Pair(left, right); pair != nil && pair.Conflict != "" {
+			if pair.Left == right {
+				switch strings.ToLower(strings.TrimSpace(pair.Conflict)) {
+				case sync.ConflictLeft:
+					return sync.ConflictRight
+				case sync.ConflictRight:
+					return sync.ConflictLeft
+				}
+			}
+			return pair.Conflict
+		}
+	}
+	return syncConflict
+}
+
+// mirrorOneWay 返回一对 Provider 的单向镜像来源与镜像侧修改的处理方式：--source、--mirror-edits 优先，
+// 其次是配置 sync.pairs 中的 source、mirror_edits；来源为空表示双向镜像
+func mirrorOneWay(cmd *cobra.Command, left, right string) (string, string) {
+	source, edits := "", ""
+	if pair := cfg.Sync.Pair(left, right); pair != nil {
+		source, edits = strings.TrimSpace(pair.Source), pair.MirrorEdits
+	}
+	if flag := cmd.Flags().Lookup("source"); flag != nil && flag.Changed {
+		source = provider.ResolveProviderName(syncSource)
+	}
+	if flag := cmd.Flags().Lookup("mirror-edits"); flag != nil && flag.Changed {
+		edits = syncMirrorEdits
+	}
+	if source == "" {
+		return "", ""
+	}
+	return source, edits
+}
+
+// mirrorOnDelete 返回一对 Provider 删除任务的处理方式：--on-delete 优先，其次是配置 sync.pairs 中的 on_delete
+func mirrorOnDelete(cmd *cobra.Command, left, right string) string {
+	if flag := cmd.Flags().Lookup("on-delete"); flag != nil && flag.Changed {
+		return syncOnDelete
+	}
+	if pair := cfg.Sync.Pair(left, right); pair != nil {
+		return strings.TrimSpace(pair.OnDelete)
+	}
+	return ""
+}
+
+// otherMirrorSide 镜像对中 name 之外的一侧
+func otherMirrorSide(left, right, name string) string {
+	if name == left {
+		return right
+	}
+	return left
+}
+
+// mirrorFieldMap 返回配置 sync.pairs 中一对 Provider 的字段映射，未配置时返回 nil（只使用默认映射）
+func mirrorFieldMap(left, right string) *sync.MirrorFieldMap {
+	pair := cfg.Sync.Pair(left, right)
+	if pair == nil {
+		return nil
+	}
+	values := func(pairs []pkgconfig.SyncValuePair) []sync.MirrorValuePair {
+		out := make([]sync.MirrorValuePair, 0, len(pairs))
+		for _, value := range pairs {
+			out = append(out, sync.MirrorValuePair{Left: value.Left, Right: value.Right})
+		}
+		return out
+	}
+	return &sync.MirrorFieldMap{
+		Left:     pair.Left,
+		Right:    pair.Right,
+		Priority: values(pair.FieldMap.Priority),
+		Status:   values(pair.FieldMap.Status),
+		Tags:     values(pair.FieldMap.Tags),
+		Lists:    values(pair.FieldMap.Projects),
+	}
+}
+
+// mirrorFilter 返回配置 sync.pairs 中一对 Provider 的过滤规则，未配置时返回 nil
+func mirrorFilter(left, right string) *sync.MirrorFilter {
+	pair := cfg.Sync.Pair(left, right)
+	if pair == nil {
+		return nil
+	}
+	if len(pair.Filter.Tags) == 0 && len(pair.Filter.Projects) == 0 && pair.Filter.ExcludeCompletedOlderThanDays <= 0 {
+		return nil
+	}
+	return &sync.MirrorFilter{
+		Tags:                      pair.Filter.Tags,
+		Lists:                     pair.Filter.Projects,
+		ExcludeCompletedOlderThan: time.Duration(pair.Filter.ExcludeCompletedOlderThanDays) * 24 * time.Hour,
+	}
+}
+
+// runSyncConflicts 列出排队的镜像冲突
+func runSyncConflicts(cmd *cobra.Command, args []string) {
+	left := provider.ResolveProviderName(args[0])
+	right := provider.ResolveProviderName(args[1])
+	store, closeStore, err := openMirrorStore()
+	if err != nil {
+		fmt.Printf("❌ 初始化镜像状态存储失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeStore()
+	state, err := store.LoadMirrorState(context.Background(), left, right)
+	if err != nil {
+		fmt.Printf("❌ 读取镜像状态失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	if syncOutput == "json" {
+		data, err := json.MarshalIndent(state.Conflicts, "", "  ")
+		if err != nil {
+			fmt.Printf("❌ 序列化结果失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+	if len(state.Conflicts) == 0 {
+		fmt.Printf("✅ %s ⇄ %s 没有等待处理的冲突\n", left, right)
+		return
+	}
+
+	fmt.Printf("📋 %s ⇄ %s 等待处理的冲突 (%d)\n", left, right, len(state.Conflicts))
+	for _, conflict := range state.Conflicts {
+		fmt.Println()
+		line := fmt.Sprintf("  [%s] 检测于 %s", conflict.ID, conflict.DetectedAt.Format("2006-01-02 15:04:05"))
+		switch {
+		case len(conflict.Fields) > 0:
+			line += fmt.Sprintf("，已选择逐字段合并（其余字段保留 %s，下次镜像时应用）", conflict.Resolution)
+		case conflict.Resolution != "":
+			line += fmt.Sprintf("，已选择保留 %s（下次镜像时应用）", conflict.Resolution)
+		}
+		fmt.Println(line)
+		for _, name := range []string{left, right} {
+			task, ok := conflict.Tasks[name]
+			if !ok {
+				continue
+			}
+			status := "未完成"
+			if task.Fields.Completed {
+				status = "已完成"
+			}
+			fmt.Printf("    %-10s %s (%s, 更新于 %s)\n", name, task.Fields.Title, status, task.UpdatedAt.Format("2006-01-02 15:04"))
+			if task.Fields.Description != "" {
+				fmt.Printf("    %-10s %s\n", "", truncateDisplay(task.Fields.Description, 60))
+			}
+		}
+		if differing := conflict.DifferingFields(left, right); len(differing) > 0 {
+			fmt.Printf("    不同的字段: %s\n", strings.Join(differing, ", "))
+		}
+	}
+	fmt.Printf("\n使用 taskbridge sync resolve %s %s 逐个处理，或 sync resolve %s %s <conflict-id> --keep <provider> 选择保留的一侧\n", left, right, left, right)
+}
+
+// runSyncResolve 处理排队的镜像冲突：指定 --keep 或 --field 时直接记录选择，否则交互处理
+func runSyncResolve(cmd *cobra.Command, args []string) {
+	left := provider.ResolveProviderName(args[0])
+	right := provider.ResolveProviderName(args[1])
+	store, closeStore, err := openMirrorStore()
+	if err != nil {
+		fmt.Printf("❌ 初始化镜像状态存储失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeStore()
+
+	if syncKeep == "" && len(syncFields) == 0 {
+		id := ""
+		if len(args) == 3 {
+			id = args[2]
+		}
+		resolveMirrorConflictsInteractive(store, left, right, id)
+		return
+	}
+	if len(args) < 3 {
+		fmt.Println("❌ 指定 --keep 或 --field 时需要 conflict-id")
+		os.Exit(1)
+	}
+	if syncKeep == "" {
+		fmt.Println("❌ 使用 --field 时需要通过 --keep 指定其余字段保留的一侧")
+		os.Exit(1)
+	}
+	resolveSide := func(value string) string {
+		if value != sync.ConflictLeft && value != sync.ConflictRight {
+			return provider.ResolveProviderName(value)
+		}
+		return value
+	}
+	fields := make(map[string]string, len(syncFields))
+	for name, value := range syncFields {
+		fields[strings.ToLower(strings.TrimSpace(name))] = resolveSide(value)
+	}
+	conflict, err := sync.ResolveMirrorConflictFields(context.Background(), store, left, right, args[2], resolveSide(syncKeep), fields)
+	if err != nil {
+		fmt.Printf("❌ 处理冲突失败: %v\n", err)
+		os.Exit(1)
+	}
+	printMirrorConflictResolution(conflict)
+}
+
+// resolveMirrorConflictsInteractive 逐个展示等待中的冲突并读取选择；id 非空时只处理该冲突
+func resolveMirrorConflictsInteractive(store sync.MirrorStore, left, right, id string) {
+	ctx := context.Background()
+	state, err := store.LoadMirrorState(ctx, left, right)
+	if err != nil {
+		fmt.Printf("❌ 读取镜像状态失败: %v\n", err)
+		os.Exit(1)
+	}
+	var pending []sync.MirrorConflict
+	for _, conflict := range state.Conflicts {
+		if (id == "" && conflict.Resolution == "") || conflict.ID == id {
+			pending = append(pending, conflict)
+		}
+	}
+	if len(pending) == 0 {
+		if id != "" {
+			fmt.Printf("❌ 冲突 %s 不存在\n", id)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ %s ⇄ %s 没有等待处理的冲突\n", left, right)
+		return
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	prompt := func(format string, a ...any) (string, bool) {
+		fmt.Printf(format, a...)
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Println()
+			return "", false
+		}
+		return strings.ToLower(strings.TrimSpace(line)), true
+	}
+
+	resolved := 0
+	for i, conflict := range pending {
+		fmt.Printf("\n📋 冲突 %d/%d [%s] 检测于 %s\n", i+1, len(pending), conflict.ID, conflict.DetectedAt.Format("2006-01-02 15:04:05"))
+		differing := conflict.DifferingFields(left, right)
+		for _, name := range sync.MirrorConflictFields {
+			mark := " "
+			if slices.Contains(differing, name) {
+				mark = "*"
+			}
+			fmt.Printf("  %s %-12s", mark, name)
+			for _, side := range []string{left, right} {
+				value := "（已删除）"
+				if task, ok := conflict.Tasks[side]; ok {
+					value = truncateDisplay(task.Fields.FieldValue(name), 30)
+				}
+				fmt.Printf(" %s: %-32s", side, value)
+			}
+			fmt.Println()
+		}
+
+		var keep string
+		var fields map[string]string
+	choose:
+		for {
+			options := fmt.Sprintf("[l] 保留 %s / [r] 保留 %s", left, right)
+			if len(differing) > 0 {
+				options += " / [m] 逐字段选择"
+			}
+			answer, ok := prompt("选择 %s / [s] 跳过 / [q] 退出: ", options)
+			if !ok {
+				answer = "q"
+			}
+			switch answer {
+			case "l":
+				keep = left
+				break choose
+			case "r":
+				keep = right
+				break choose
+			case "m":
+				if len(differing) == 0 {
+					continue
+				}
+				keep, fields = left, make(map[string]string, len(differing))
+				for _, name := range differing {
+					for {
+						answer, ok := prompt("  %s 取 [l] %s / [r] %s: ", name, left, right)
+						if !ok {
+							fmt.Printf("\n已处理 %d 个冲突\n", resolved)
+							return
+						}
+						if answer == "l" || answer == "r" {
+							fields[name] = map[string]string{"l": left, "r": right}[answer]
+							break
+						}
+					}
+				}
+				break choose
+			case "s":
+				break choose
+			case "q":
+				fmt.Printf("\n已处理 %d 个冲突\n", resolved)
+				return
+			}
+		}
+		if keep == "" {
+			continue
+		}
+		resolvedConflict, err := sync.ResolveMirrorConflictFields(ctx, store, left, right, conflict.ID, keep, fields)
+		if err != nil {
+			fmt.Printf("❌ 处理冲突失败: %v\n", err)
+			continue
+		}
+		printMirrorConflictResolution(resolvedConflict)
+		resolved++
+	}
+	fmt.Printf("\n已处理 %d 个冲突\n", resolved)
+}
+
+// runSyncUndo 撤销一次镜像运行；未指定 run-id 时列出最近的运行
+func runSyncUndo(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	journal, err := openMirrorJournal()
+	if err != nil {
+		fmt.Printf("❌ 初始化镜像变更日志失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(args) == 0 {
+		runs, err := sync.MirrorJournalRuns(ctx, journal)
+		if err != nil {
+			fmt.Printf("❌ 读取镜像变更日志失败: %v\n", err)
+			os.Exit(1)
+		}
+		if syncUndoLimit > 0 && len(runs) > syncUndoLimit {
+			runs = runs[:syncUndoLimit]
+		}
+		printMirrorJournalRuns(runs)
+		return
+	}
+
+	engine, err := getSyncEngine()
+	if err != nil {
+		fmt.Printf("❌ 初始化同步引擎失败: %v\n", err)
+		os.Exit(1)
+	}
+	store, closeStore, err := openMirrorStore()
+	if err != nil {
+		fmt.Printf("❌ 初始化镜像状态存储失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeStore()
+
+	result, err := engine.UndoMirrorRun(ctx, store, journal, args[0], sync.MirrorUndoOptions{DryRun: syncDryRun, Force: syncForce})
+	if err != nil {
+		fmt.Printf("❌ 撤销失败: %v\n", err)
+		os.Exit(1)
+	}
+	printMirrorUndoResult(result)
+}
+
+// printMirrorJournalRuns 打印变更日志中的运行
+func printMirrorJournalRuns(runs []sync.MirrorJournalRun) {
+	if syncOutput == "json" {
+		data, err := json.MarshalIndent(runs, "", "  ")
+		if err != nil {
+			fmt.Printf("❌ 序列化结果失败: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+	if len(runs) == 0 {
+		fmt.Println("镜像变更日志中没有记录")
+		return
+	}
+
+	table := ui.NewSimpleTable(
+		ui.Column{Header: "运行 ID", Width: 22, AlignLeft: true},
+		ui.Column{Header: "Provider 对", Width: 24, AlignLeft: true},
+		ui.Column{Header: "时间", Width: 20, AlignLeft: true},
+		ui.Column{Header: "变更", Width: 6},
+		ui.Column{Header: "备注", Width: 30, AlignLeft: true},
+	)
+	for _, run := range runs {
+		note := ""
+		switch {
+		case run.UndoOf != "":
+			note = "撤销 " + run.UndoOf
+		case run.UndoneBy != "":
+			note = "已被 " + run.UndoneBy + " 撤销"
+		}
+		table.AddRow(run.RunID, run.Left+" ⇄ "+run.Right, run.StartedAt.Local().Format("2006-01-02 15:04:05"), fmt.Sprintf("%d", run.Changes), note)
+	}
+	fmt.Println(table.Render())
+	fmt.Println("\n使用 taskbridge sync undo <run-id> 撤销一次运行")
+}
+
+// printMirrorUndoResult 打印撤销结果
+func printMirrorUndoResult(result *sync.MirrorUndoResult) {
+	if syncOutput == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Printf("❌ 序列化结果失败: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if result.DryRun {
+		fmt.Printf("📋 将撤销运行 %s（%s ⇄ %s）的 %d 项变更\n", result.UndoOf, result.Left, result.Right, result.Reverted)
+	} else {
+		fmt.Printf("✅ 已撤销运行 %s（%s ⇄ %s）的 %d 项变更\n", result.UndoOf, result.Left, result.Right, result.Reverted)
+	}
+	if len(result.Skipped) > 0 {
+		fmt.Printf("\n跳过 (%d):\n", len(result.Skipped))
+		for _, skip := range result.Skipped {
+			fmt.Printf("  - [%s] %s %s: %s\n", skip.Provider, skip.Op, skip.Title, skip.Reason)
+		}
+	}
+	if len(result.Errors) > 0 {
+		fmt.Printf("\n⚠️ 错误 (%d):\n", len(result.Errors))
+		for _, e := range result.Errors {
+			fmt.Printf("  - %s: %s\n", e.Operation, e.Error)
+		}
+	}
+	if result.DryRun {
+		fmt.Println("\nℹ️ 这是模拟执行，未实际修改数据")
+	}
+}
+
+// printMirrorConflictResolution 打印冲突记录的选择
+func printMirrorConflictResolution(conflict *sync.MirrorConflict) {
+	if len(conflict.Fields) == 0 {
+		fmt.Printf("✅ 冲突 %s 将在下次镜像时保留 %s 的内容\n", conflict.ID, conflict.Resolution)
+		return
+	}
+	choices := make([]string, 0, len(conflict.Fields))
+	for _, name := range sync.MirrorConflictFields {
+		if side, ok := conflict.Fields[name]; ok {
+			choices = append(choices, name+"="+side)
+		}
+	}
+	fmt.Printf("✅ 冲突 %s 将在下次镜像时合并：%s，其余字段保留 %s 的内容\n", conflict.ID, strings.Join(choices, ", "), conflict.Resolution)
+}
+
+// printMirrorPairStatus 打印守护进程中一轮镜像的摘要
+func printMirrorPairStatus(status sync.MirrorPairStatus) {
+	at := status.LastRunAt.Format("15:04:05")
+	if status.LastError != "" {
+		fmt.Printf("[%s] ❌ %s: %s\n", at, status.Pair, status.LastError)
+		return
+	}
+	result := status.LastResult
+	toLeft, toRight := result.ToLeft, result.ToRight
+	fmt.Printf("[%s] ✅ %s: 新建 %d, 更新 %d, 完成 %d, 删除 %d, 冲突 %d (待处理 %d), 错误 %d (%s)\n",
+		at, status.Pair,
+		toLeft.Created+toRight.Created,
+		toLeft.Updated+toRight.Updated,
+		toLeft.Completed+toRight.Completed,
+		toLeft.Deleted+toRight.Deleted,
+		result.Conflicts, result.PendingConflicts, len(result.Errors), result.Duration.Round(time.Millisecond))
+}
+
 // runSyncStatus 执行状态查询
 func runSyncStatus(cmd *cobra.Command, args []string) {
 	engine, err := getSyncEngine()
@@ -375,6 +1172,7 @@ func runSyncStatus(cmd *cobra.Command, args []string) {
 			os.Exit(1)
 		}
 		printSyncStatus(status)
+		printMirrorPairReports(provider.ResolveProviderName(providerName))
 	} else {
 		// 查询所有 Provider
 		providers := []string{"google", "microsoft", "feishu", "ticktick", "dida", "todoist"}
@@ -385,6 +1183,103 @@ func runSyncStatus(cmd *cobra.Command, args []string) {
 			}
 			printSyncStatus(status)
 		}
+		printMirrorPairReports("")
+		printMirrorDaemonStatus()
+	}
+}
+
+// printMirrorPairReports 打印镜像状态存储中各镜像对最近一次镜像的记录；providerName 非空时只打印包含它的镜像对
+func printMirrorPairReports(providerName string) {
+	store, closeStore, err := openMirrorStore()
+	if err != nil {
+		fmt.Printf("\n⚠️ 打开镜像状态存储失败: %v\n", err)
+		return
+	}
+	defer closeStore()
+	reports, err := sync.MirrorStatusReport(context.Background(), store)
+	if err != nil {
+		fmt.Printf("\n⚠️ 读取镜像状态失败: %v\n", err)
+		return
+	}
+
+	printed := false
+	for _, report := range reports {
+		if providerName != "" && report.Left != providerName && report.Right != providerName {
+			continue
+		}
+		if !printed {
+			fmt.Println()
+			fmt.Println("🔁 镜像对")
+			fmt.Println("   ─────────────────────────────────")
+			printed = true
+		}
+		if report.Source != "" {
+			fmt.Printf("   %s → %s（单向）: 已关联 %d 个任务\n", report.Source, otherMirrorSide(report.Left, report.Right, report.Source), report.Links)
+		} else {
+			fmt.Printf("   %s ↔ %s: 已关联 %d 个任务\n", report.Left, report.Right, report.Links)
+		}
+		if run := report.LastRun; run != nil {
+			line := fmt.Sprintf("     最后镜像: %s，变更 %d 项", run.FinishedAt.Local().Format("2006-01-02 15:04:05"), report.Transferred)
+			if run.Runs > 0 {
+				line += fmt.Sprintf("（累计 %d 次，失败 %d 次）", run.Runs, run.Failures)
+			}
+			fmt.Println(line)
+			if !run.LastSuccessAt.IsZero() && !run.LastSuccessAt.Equal(run.FinishedAt) {
+				fmt.Printf("     最后成功: %s\n", run.LastSuccessAt.Local().Format("2006-01-02 15:04:05"))
+			}
+		} else if !report.LastSyncTime.IsZero() {
+			fmt.Printf("     最后镜像: %s\n", report.LastSyncTime.Local().Format("2006-01-02 15:04:05"))
+		}
+		if report.PendingConflicts > 0 {
+			fmt.Printf("     待处理冲突: %d（使用 sync resolve 处理）\n", report.PendingConflicts)
+		}
+		if report.Tombstones > 0 {
+			fmt.Printf("     已删除任务的墓碑: %d\n", report.Tombstones)
+		}
+		for _, e := range report.Errors {
+			fmt.Printf("     ⚠️ %s\n", e)
+		}
+	}
+}
+
+// printMirrorDaemonStatus 打印 sync start 写入的镜像守护进程状态
+func printMirrorDaemonStatus() {
+	status, err := sync.LoadMirrorDaemonStatus(cfg.Storage.Path)
+	if err != nil {
+		fmt.Printf("\n⚠️ 读取镜像守护进程状态失败: %v\n", err)
+		return
+	}
+	if status == nil {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("📋 镜像守护进程")
+	fmt.Println("   ─────────────────────────────────")
+	if status.Running {
+		fmt.Printf("   状态: 🟢 运行中 (PID %d, 间隔 %s)\n", status.PID, status.Interval)
+	} else if status.StoppedAt != nil {
+		fmt.Printf("   状态: ⚪ 已停止于 %s\n", status.StoppedAt.Format("2006-01-02 15:04:05"))
+	}
+	for _, pair := range status.Pairs {
+		line := fmt.Sprintf("   %s: 运行 %d 次，失败 %d 次", pair.Pair, pair.Runs, pair.Failures)
+		if !pair.LastRunAt.IsZero() {
+			line += "，最后镜像 " + pair.LastRunAt.Format("2006-01-02 15:04:05")
+		}
+		if pair.Schedule != "" {
+			line += "，cron " + pair.Schedule
+		}
+		if status.Running && !pair.NextRunAt.IsZero() {
+			layout := "15:04:05"
+			if y, m, d := pair.NextRunAt.Date(); y != time.Now().Year() || m != time.Now().Month() || d != time.Now().Day() {
+				layout = "01-02 15:04"
+			}
+			line += "，下次 " + pair.NextRunAt.Format(layout)
+		}
+		fmt.Println(line)
+		if pair.LastError != "" {
+			fmt.Printf("     ⚠️ %s\n", pair.LastError)
+		}
 	}
 }
 
@@ -430,6 +1325,69 @@ func printSyncResult(result *sync.Result) {
 	fmt.Println()
 }
 
+// printMirrorResult 打印镜像结果
+func printMirrorResult(result *sync.MirrorResult) {
+	if syncOutput == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Printf("❌ 序列化结果失败: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Println()
+	if result.Source != "" {
+		fmt.Printf("📋 镜像结果 - %s → %s（单向）\n", result.Source, otherMirrorSide(result.Left, result.Right, result.Source))
+	} else {
+		fmt.Printf("📋 镜像结果 - %s ⇄ %s\n", result.Left, result.Right)
+	}
+
+	table := ui.NewSimpleTable(
+		ui.Column{Header: "变更", Width: 10, AlignLeft: true},
+		ui.Column{Header: "→ " + result.Left, Width: 16, AlignLeft: true},
+		ui.Column{Header: "→ " + result.Right, Width: 16, AlignLeft: true},
+	)
+	table.AddRow("新建", fmt.Sprintf("%d", result.ToLeft.Created), fmt.Sprintf("%d", result.ToRight.Created))
+	table.AddRow("更新", fmt.Sprintf("%d", result.ToLeft.Updated), fmt.Sprintf("%d", result.ToRight.Updated))
+	table.AddRow("完成", fmt.Sprintf("%d", result.ToLeft.Completed), fmt.Sprintf("%d", result.ToRight.Completed))
+	table.AddRow("删除", fmt.Sprintf("%d", result.ToLeft.Deleted), fmt.Sprintf("%d", result.ToRight.Deleted))
+	fmt.Println(table.Render())
+	fmt.Printf("冲突: %d  跳过: %d  耗时: %s\n", result.Conflicts, result.Skipped, result.Duration)
+	if result.Filtered > 0 {
+		fmt.Printf("不符合过滤规则: %d\n", result.Filtered)
+	}
+	if result.Overwritten > 0 {
+		fmt.Printf("覆盖镜像侧的修改: %d\n", result.Overwritten)
+	}
+	if result.Ghosts > 0 {
+		fmt.Printf("忽略已删除任务的残留: %d\n", result.Ghosts)
+	}
+	if len(result.Incremental) > 0 {
+		fmt.Printf("增量读取: %s\n", strings.Join(result.Incremental, ", "))
+	}
+	if result.PendingConflicts > 0 && result.Source != "" {
+		fmt.Printf("\n⚠️ %d 个镜像侧的修改等待处理，运行 taskbridge sync conflicts %s %s 查看\n", result.PendingConflicts, result.Left, result.Right)
+	} else if result.PendingConflicts > 0 {
+		fmt.Printf("\n⚠️ %d 个冲突等待人工处理，运行 taskbridge sync conflicts %s %s 查看\n", result.PendingConflicts, result.Left, result.Right)
+	}
+
+	if len(result.Errors) > 0 {
+		fmt.Printf("\n⚠️ 错误 (%d):\n", len(result.Errors))
+		for _, e := range result.Errors {
+			fmt.Printf("  - %s: %s\n", e.Operation, e.Error)
+		}
+	}
+
+	if syncDryRun {
+		fmt.Println("\nℹ️ 这是模拟执行，未实际修改数据")
+	} else if result.Transferred() > 0 {
+		fmt.Printf("\n运行 ID: %s（taskbridge sync undo %s 可撤销）\n", result.RunID, result.RunID)
+	}
+	fmt.Println()
+}
+
 // printSyncStatus 打印同步状态
 func printSyncStatus(status *sync.Status) {
 	providerNames := map[string]string{
@@ -9,8 +9,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/yeisme/taskbridge/internal/history"
 	"github.com/yeisme/taskbridge/internal/model"
-	"github.com/yeisme/taskbridge/internal/storage/filestore"
 )
 
 var (
@@ -150,7 +150,7 @@ func runTaskAdd(cmd *cobra.Command, args []string) {
 	title := args[0]
 
 	// 创建存储
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginCLI)
 	if err != nil {
 		fmt.Printf("❌ 创建存储失败: %v\n", err)
 		os.Exit(1)
@@ -196,7 +196,7 @@ func runTaskEdit(cmd *cobra.Command, args []string) {
 	taskID := args[0]
 
 	// 创建存储
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginCLI)
 	if err != nil {
 		fmt.Printf("❌ 创建存储失败: %v\n", err)
 		os.Exit(1)
@@ -245,7 +245,7 @@ func runTaskDelete(cmd *cobra.Command, args []string) {
 	taskID := args[0]
 
 	// 创建存储
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginCLI)
 	if err != nil {
 		fmt.Printf("❌ 创建存储失败: %v\n", err)
 		os.Exit(1)
@@ -287,7 +287,7 @@ func runTaskDone(cmd *cobra.Command, args []string) {
 	taskID := args[0]
 
 	// 创建存储
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginCLI)
 	if err != nil {
 		fmt.Printf("❌ 创建存储失败: %v\n", err)
 		os.Exit(1)
@@ -320,7 +320,7 @@ func runTaskUndo(cmd *cobra.Command, args []string) {
 	taskID := args[0]
 
 	// 创建存储
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginCLI)
 	if err != nil {
 		fmt.Printf("❌ 创建存储失败: %v\n", err)
 		os.Exit(1)
@@ -352,7 +352,7 @@ func runTaskShow(cmd *cobra.Command, args []string) {
 	taskID := args[0]
 
 	// 创建存储
-	store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+	store, err := openTaskStore(history.OriginCLI)
 	if err != nil {
 		fmt.Printf("❌ 创建存储失败: %v\n", err)
 		os.Exit(1)
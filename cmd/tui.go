@@ -12,11 +12,11 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/yeisme/taskbridge/internal/history"
 	"github.com/yeisme/taskbridge/internal/model"
 	"github.com/yeisme/taskbridge/internal/provider"
 	"github.com/yeisme/taskbridge/internal/provider/google"
 	"github.com/yeisme/taskbridge/internal/storage"
-	"github.com/yeisme/taskbridge/internal/storage/filestore"
 )
 
 // tuiCmd TUI 命令
@@ -203,7 +203,7 @@ type loadMsg struct {
 func loadData() tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		store, err := filestore.New(cfg.Storage.Path, cfg.Storage.File.Format)
+		store, err := openTaskStore(history.OriginCLI)
 		if err != nil {
 			return loadMsg{err: err}
 		}
@@ -223,7 +223,7 @@ func loadData() tea.Cmd {
 
 		// 尝试初始化 Google Provider
 		var googleProv provider.Provider
-		gp, err := google.NewProviderFromHome()
+		gp, err := google.NewProviderFromHomeWithSubject(googleImpersonateSubject())
 		if err == nil && gp.IsAuthenticated() {
 			googleProv = gp
 		}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenRefresher 为 HTTP 请求提供 access token 的 OAuth2 客户端
+type TokenRefresher interface {
+	// AccessToken 返回未过期的 access token，即将过期时先用 refresh token 刷新并保存
+	AccessToken(ctx context.Context) (string, error)
+	// ForceRefresh 无论 access token 是否过期都用 refresh token 换取新 token 并保存，用于服务端提前吊销的情况
+	ForceRefresh(ctx context.Context) (string, error)
+}
+
+// RefreshingTransport 为每个请求附加 Bearer token：token 过期前自动刷新，
+// 收到 401 时强制刷新一次并重试原请求，避免长时间运行的 MCP 服务在 access token 过期后工具调用失败。
+type RefreshingTransport struct {
+	// Base 实际发送请求的 transport，为空时使用 http.DefaultTransport
+	Base http.RoundTripper
+	// Source 提供与刷新 token 的 OAuth2 客户端
+	Source TokenRefresher
+
+	// mu 保证并发请求同时收到 401 时只刷新一次
+	mu sync.Mutex
+}
+
+// NewHTTPClient 返回使用 RefreshingTransport 的 HTTP 客户端
+func NewHTTPClient(source TokenRefresher, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &RefreshingTransport{Source: source},
+		Timeout:   timeout,
+	}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *RefreshingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	t.mu.Lock()
+	token, err := t.Source.AccessToken(ctx)
+	t.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	resp, err := t.base().RoundTrip(authorize(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	// 请求体无法重放时不重试，直接返回 401
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	refreshed, refreshErr := t.refresh(ctx, token)
+	if refreshErr != nil {
+		return resp, nil
+	}
+	retry := authorize(req, refreshed)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return t.base().RoundTrip(retry)
+}
+
+// refresh 强制刷新 token；其他请求已完成刷新（当前 token 与失败时不同）时直接使用新 token
+func (t *RefreshingTransport) refresh(ctx context.Context, failed string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	current, err := t.Source.AccessToken(ctx)
+	if err == nil && current != failed {
+		return current, nil
+	}
+	return t.Source.ForceRefresh(ctx)
+}
+
+func (t *RefreshingTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// authorize 复制请求并设置 Authorization 头，RoundTripper 不应修改原请求
+func authorize(req *http.Request, token string) *http.Request {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+token)
+	return clone
+}
+
+// PublicClientEndpoint 未配置 client secret 的公共客户端（依靠 PKCE 保护授权码）在请求参数中只发送 client_id，
+// 避免 oauth2 自动探测时先以空密码发送 Basic 认证被拒绝
+func PublicClientEndpoint(endpoint oauth2.Endpoint, clientSecret string) oauth2.Endpoint {
+	if clientSecret == "" {
+		endpoint.AuthStyle = oauth2.AuthStyleInParams
+	}
+	return endpoint
+}
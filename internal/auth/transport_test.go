@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRefresher 每次强制刷新生成新的 access token
+type fakeRefresher struct {
+	token     string
+	refreshes int
+}
+
+func (f *fakeRefresher) AccessToken(context.Context) (string, error) {
+	return f.token, nil
+}
+
+func (f *fakeRefresher) ForceRefresh(context.Context) (string, error) {
+	f.refreshes++
+	f.token = fmt.Sprintf("fresh-%d", f.refreshes)
+	return f.token, nil
+}
+
+func TestRefreshingTransportRetriesOnceAfterUnauthorized(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer fresh-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	source := &fakeRefresher{token: "revoked"}
+	client := NewHTTPClient(source, 5*time.Second)
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"title":"task"}`))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || source.refreshes != 1 {
+		t.Fatalf("expected success after one refresh, got status=%d refreshes=%d", resp.StatusCode, source.refreshes)
+	}
+	if len(bodies) != 2 || bodies[1] != `{"title":"task"}` {
+		t.Fatalf("expected request body replayed on retry, got %q", bodies)
+	}
+
+	// 刷新后仍然 401 时不再重试，将 401 返回给调用方
+	source.token, source.refreshes = "revoked", 1
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || source.refreshes != 2 || len(bodies) != 4 {
+		t.Fatalf("expected single retry, got status=%d refreshes=%d requests=%d", resp.StatusCode, source.refreshes, len(bodies))
+	}
+}
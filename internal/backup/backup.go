@@ -0,0 +1,315 @@
+// Package backup 将本地状态目录（任务数据、镜像映射、模板、同步日志、缓存等）打包为单个 tar.gz 归档，
+// 并可在另一台机器上恢复。归档中的每个目录称为一个分区（section），恢复时按分区名写回对应目录。
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatVersion 归档格式版本，恢复时拒绝更高版本的归档
+	FormatVersion = 1
+	// ManifestName 归档中清单文件的名称，始终是第一个条目
+	ManifestName = "manifest.json"
+)
+
+// ErrTargetNotEmpty 恢复目标目录已有文件且未允许覆盖
+var ErrTargetNotEmpty = errors.New("restore target is not empty")
+
+// Section 参与备份或恢复的目录
+type Section struct {
+	// Name 分区名，即归档中的顶层目录，如 data、cache
+	Name string
+	// Dir 本地目录
+	Dir string
+}
+
+// SectionInfo 归档中一个分区的统计
+type SectionInfo struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Manifest 归档清单
+type Manifest struct {
+	Version    int           `json:"version"`
+	CreatedAt  time.Time     `json:"created_at"`
+	AppVersion string        `json:"app_version,omitempty"`
+	Profile    string        `json:"profile,omitempty"`
+	Sections   []SectionInfo `json:"sections"`
+}
+
+// Section 返回指定分区的统计
+func (m *Manifest) Section(name string) (SectionInfo, bool) {
+	for _, info := range m.Sections {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return SectionInfo{}, false
+}
+
+type backupFile struct {
+	section string
+	rel     string
+	path    string
+	info    fs.FileInfo
+}
+
+// Create 将 sections 中的目录写入 w，manifest 的 Version、CreatedAt 与 Sections 由 Create 填写。
+// 不存在的目录跳过；只打包普通文件，符号链接等特殊文件忽略
+func Create(w io.Writer, sections []Section, manifest Manifest) (*Manifest, error) {
+	var files []backupFile
+	manifest.Version = FormatVersion
+	manifest.CreatedAt = time.Now().UTC()
+	manifest.Sections = nil
+	for _, section := range sections {
+		if err := validateSectionName(section.Name); err != nil {
+			return nil, err
+		}
+		found, err := collectFiles(section)
+		if err != nil {
+			return nil, err
+		}
+		if found == nil {
+			continue
+		}
+		info := SectionInfo{Name: section.Name}
+		for _, f := range found {
+			info.Files++
+			info.Bytes += f.info.Size()
+		}
+		manifest.Sections = append(manifest.Sections, info)
+		files = append(files, found...)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backup manifest: %w", err)
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0o600, Size: int64(len(data)), ModTime: manifest.CreatedAt, Typeflag: tar.TypeReg}); err != nil {
+		return nil, fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	for _, f := range files {
+		if err := writeFile(tw, f); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish backup archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish backup archive: %w", err)
+	}
+	return &manifest, nil
+}
+
+// collectFiles 列出目录下的普通文件，目录不存在时返回 nil
+func collectFiles(section Section) ([]backupFile, error) {
+	root := filepath.Clean(section.Dir)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+	files := []backupFile{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, backupFile{section: section.Name, rel: filepath.ToSlash(rel), path: p, info: info})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", root, err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].rel < files[j].rel })
+	return files, nil
+}
+
+func writeFile(tw *tar.Writer, f backupFile) error {
+	src, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.path, err)
+	}
+	defer func() { _ = src.Close() }()
+	header := &tar.Header{
+		Name:     f.section + "/" + f.rel,
+		Mode:     int64(f.info.Mode().Perm()),
+		Size:     f.info.Size(),
+		ModTime:  f.info.ModTime(),
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s: %w", f.path, err)
+	}
+	// 文件在打包期间被追加时只写入头中声明的长度，保持归档完整
+	if _, err := io.CopyN(tw, src, header.Size); err != nil {
+		return fmt.Errorf("failed to add %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// ReadManifest 只读取归档的清单
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	_, manifest, err := openArchive(r)
+	return manifest, err
+}
+
+// Restore 将归档中的分区写回 targets 中同名分区的目录，归档中有而 targets 中没有的分区跳过。
+// overwrite 为 false 时，任一目标目录已有文件即返回 ErrTargetNotEmpty 且不写入任何文件；
+// 为 true 时覆盖同名文件，目标目录中归档没有的文件保留
+func Restore(r io.Reader, targets []Section, overwrite bool) (*Manifest, error) {
+	tr, manifest, err := openArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make(map[string]string, len(targets))
+	for _, target := range targets {
+		if _, ok := manifest.Section(target.Name); !ok {
+			continue
+		}
+		if !overwrite {
+			if empty, err := dirEmpty(target.Dir); err != nil {
+				return nil, err
+			} else if !empty {
+				return nil, fmt.Errorf("%w: %s", ErrTargetNotEmpty, target.Dir)
+			}
+		}
+		dirs[target.Name] = filepath.Clean(target.Dir)
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		section, rel, err := splitEntryName(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		dir, ok := dirs[section]
+		if !ok {
+			continue
+		}
+		if err := restoreFile(tr, header, filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+func openArchive(r io.Reader) (*tar.Reader, *Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a taskbridge backup archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	header, err := tr.Next()
+	if err != nil || header.Name != ManifestName {
+		return nil, nil, fmt.Errorf("not a taskbridge backup archive: missing %s", ManifestName)
+	}
+	var manifest Manifest
+	if err := json.NewDecoder(io.LimitReader(tr, 1<<20)).Decode(&manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	if manifest.Version < 1 || manifest.Version > FormatVersion {
+		return nil, nil, fmt.Errorf("unsupported backup format version %d", manifest.Version)
+	}
+	return tr, &manifest, nil
+}
+
+// splitEntryName 拆分条目名为分区与相对路径，拒绝绝对路径与包含 .. 的路径
+func splitEntryName(name string) (string, string, error) {
+	clean := path.Clean(name)
+	section, rel, ok := strings.Cut(clean, "/")
+	// 清理后与原名不同说明含有 .、.. 或多余的分隔符；清理后的路径只可能以 .. 开头
+	if !ok || clean != name || path.IsAbs(name) || section == ".." {
+		return "", "", fmt.Errorf("invalid backup entry %q", name)
+	}
+	return section, rel, nil
+}
+
+func restoreFile(r io.Reader, header *tar.Header, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dest), err)
+	}
+	mode := os.FileMode(header.Mode).Perm()
+	if mode == 0 {
+		mode = 0o600
+	}
+	// 先写入临时文件再替换，避免中断时留下不完整的文件
+	tmp := dest + ".restore"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	_ = os.Chtimes(dest, header.ModTime, header.ModTime)
+	return nil
+}
+
+func dirEmpty(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return len(entries) == 0, nil
+}
+
+func validateSectionName(name string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." || name == ManifestName {
+		return fmt.Errorf("invalid backup section name %q", name)
+	}
+	return nil
+}
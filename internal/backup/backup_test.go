@@ -0,0 +1,138 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "data", "tasks.json"), `{"tasks":[]}`, 0o644)
+	writeTestFile(t, filepath.Join(src, "data", "mirror", "journal.json"), `[]`, 0o644)
+	writeTestFile(t, filepath.Join(src, "credentials", "tokens.json"), `{"google":{}}`, 0o600)
+
+	var archive bytes.Buffer
+	manifest, err := Create(&archive, []Section{
+		{Name: "data", Dir: filepath.Join(src, "data")},
+		{Name: "cache", Dir: filepath.Join(src, "missing")},
+		{Name: "credentials", Dir: filepath.Join(src, "credentials")},
+	}, Manifest{AppVersion: "1.0.3", Profile: "work"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if info, ok := manifest.Section("data"); !ok || info.Files != 2 {
+		t.Fatalf("unexpected data section: %+v", manifest.Sections)
+	}
+	if _, ok := manifest.Section("cache"); ok {
+		t.Fatal("missing directories should be skipped")
+	}
+
+	read, err := ReadManifest(bytes.NewReader(archive.Bytes()))
+	if err != nil || read.Profile != "work" || len(read.Sections) != 2 {
+		t.Fatalf("ReadManifest: %+v, %v", read, err)
+	}
+
+	// 只恢复 data 分区，credentials 不在目标中时跳过
+	dst := t.TempDir()
+	if _, err := Restore(bytes.NewReader(archive.Bytes()), []Section{{Name: "data", Dir: filepath.Join(dst, "data")}}, false); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dst, "data", "mirror", "journal.json")); err != nil || string(got) != "[]" {
+		t.Fatalf("nested file should be restored: %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "credentials")); !os.IsNotExist(err) {
+		t.Fatal("sections without a target should not be restored")
+	}
+
+	credDir := filepath.Join(dst, "credentials")
+	if _, err := Restore(bytes.NewReader(archive.Bytes()), []Section{{Name: "credentials", Dir: credDir}}, false); err != nil {
+		t.Fatalf("Restore credentials: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(credDir, "tokens.json")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("file mode should be kept: %v, %v", info, err)
+	}
+}
+
+func TestRestoreRefusesNonEmptyTarget(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, filepath.Join(src, "tasks.json"), "backup", 0o644)
+	var archive bytes.Buffer
+	if _, err := Create(&archive, []Section{{Name: "data", Dir: src}}, Manifest{}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	dst := t.TempDir()
+	writeTestFile(t, filepath.Join(dst, "tasks.json"), "local", 0o644)
+	writeTestFile(t, filepath.Join(dst, "other.json"), "keep", 0o644)
+	if _, err := Restore(bytes.NewReader(archive.Bytes()), []Section{{Name: "data", Dir: dst}}, false); !errors.Is(err, ErrTargetNotEmpty) {
+		t.Fatalf("expected ErrTargetNotEmpty, got %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "tasks.json")); string(got) != "local" {
+		t.Fatal("refused restore should not write files")
+	}
+
+	if _, err := Restore(bytes.NewReader(archive.Bytes()), []Section{{Name: "data", Dir: dst}}, true); err != nil {
+		t.Fatalf("Restore with overwrite: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "tasks.json")); string(got) != "backup" {
+		t.Fatalf("overwrite should replace files, got %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "other.json")); string(got) != "keep" {
+		t.Fatal("files not in the archive should be kept")
+	}
+}
+
+func TestRestoreRejectsUnsafeEntries(t *testing.T) {
+	for _, name := range []string{"data/../escape.json", "../escape.json", "/etc/passwd", "data/./x"} {
+		var archive bytes.Buffer
+		gz := gzip.NewWriter(&archive)
+		tw := tar.NewWriter(gz)
+		manifest := []byte(`{"version":1,"sections":[{"name":"data","files":1}]}`)
+		_ = tw.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0o600, Size: int64(len(manifest)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write(manifest)
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: 1, Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte("x"))
+		_ = tw.Close()
+		_ = gz.Close()
+
+		dst := t.TempDir()
+		if _, err := Restore(&archive, []Section{{Name: "data", Dir: filepath.Join(dst, "data")}}, false); err == nil {
+			t.Errorf("entry %q should be rejected", name)
+		}
+		if _, err := os.Stat(filepath.Join(dst, "escape.json")); !os.IsNotExist(err) {
+			t.Errorf("entry %q escaped the target directory", name)
+		}
+	}
+}
+
+func TestReadManifestRejectsOtherArchives(t *testing.T) {
+	if _, err := ReadManifest(bytes.NewReader([]byte("plain text"))); err == nil {
+		t.Fatal("non-gzip input should be rejected")
+	}
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	manifest := []byte(`{"version":99}`)
+	_ = tw.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0o600, Size: int64(len(manifest)), Typeflag: tar.TypeReg})
+	_, _ = tw.Write(manifest)
+	_ = tw.Close()
+	_ = gz.Close()
+	if _, err := ReadManifest(&archive); err == nil {
+		t.Fatal("newer format versions should be rejected")
+	}
+}
@@ -0,0 +1,192 @@
+// Package embedding 将任务标题与描述向量化并保存到本地，供语义搜索使用。
+//
+// 向量由 Embedder 生成：本地 Ollama 服务的嵌入模型、OpenAI 兼容的 /embeddings 接口，
+// 或不依赖模型的内置哈希向量。Index 保存每个任务的向量与内容指纹，任务内容不变时不会重复请求。
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"unicode"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+// 向量化方式
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai"
+	ProviderHash   = "hash"
+)
+
+const (
+	defaultOllamaURL   = "http://localhost:11434"
+	defaultOllamaModel = "nomic-embed-text"
+	defaultOpenAIURL   = "https://api.openai.com/v1"
+	defaultOpenAIModel = "text-embedding-3-small"
+	defaultHashDims    = 256
+	defaultBatchSize   = 32
+)
+
+// Embedder 将文本转换为向量
+type Embedder interface {
+	// Model 标识向量所属的模型（如 ollama/nomic-embed-text），模型变化后已保存的向量不再可用
+	Model() string
+	// Embed 返回与 texts 一一对应的向量
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// New 按 storage.embeddings 配置创建 Embedder
+func New(cfg pkgconfig.StorageEmbeddingsConfig) (Embedder, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+	switch strings.ToLower(strings.TrimSpace(cfg.Provider)) {
+	case "", ProviderOllama:
+		return &ollamaEmbedder{
+			baseURL: orDefault(cfg.BaseURL, defaultOllamaURL),
+			model:   orDefault(cfg.Model, defaultOllamaModel),
+			client:  client,
+		}, nil
+	case ProviderOpenAI:
+		apiKey := cfg.APIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("OPENAI_API_KEY")
+		}
+		return &openAIEmbedder{
+			baseURL:    orDefault(cfg.BaseURL, defaultOpenAIURL),
+			model:      orDefault(cfg.Model, defaultOpenAIModel),
+			apiKey:     apiKey,
+			dimensions: cfg.Dimensions,
+			client:     client,
+		}, nil
+	case ProviderHash:
+		return NewHashEmbedder(cfg.Dimensions), nil
+	default:
+		return nil, fmt.Errorf("unknown embeddings provider: %s (supported: ollama, openai, hash)", cfg.Provider)
+	}
+}
+
+func orDefault(value, fallback string) string {
+	if value = strings.TrimSpace(value); value != "" {
+		return strings.TrimRight(value, "/")
+	}
+	return fallback
+}
+
+// hashEmbedder 将字符 n-gram 哈希到固定维度的向量：不需要模型与网络，
+// 但只反映字面上的相似（共享的词与字），不理解同义词
+type hashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder 创建内置的哈希向量生成器，dims<=0 时使用 256 维
+func NewHashEmbedder(dims int) Embedder {
+	if dims <= 0 {
+		dims = defaultHashDims
+	}
+	return &hashEmbedder{dims: dims}
+}
+
+func (h *hashEmbedder) Model() string {
+	return fmt.Sprintf("%s/%d", ProviderHash, h.dims)
+}
+
+func (h *hashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, h.dims)
+		for _, feature := range hashFeatures(text) {
+			f := fnv.New64a()
+			_, _ = f.Write([]byte(feature))
+			sum := f.Sum64()
+			// 最高位决定符号，减少哈希冲突带来的偏差
+			if sum>>63 == 1 {
+				vector[sum%uint64(h.dims)]--
+			} else {
+				vector[sum%uint64(h.dims)]++
+			}
+		}
+		vectors[i] = normalize(vector)
+	}
+	return vectors, nil
+}
+
+// hashFeatures 提取文本特征：拉丁文字取整词与首尾补位的三字符片段，中日韩文字取单字与相邻两字
+func hashFeatures(text string) []string {
+	var features []string
+	var word []rune
+	var cjk []rune
+	flushWord := func() {
+		if len(word) == 0 {
+			return
+		}
+		features = append(features, "w:"+string(word))
+		padded := append(append([]rune{'^'}, word...), '$')
+		for i := 0; i+3 <= len(padded); i++ {
+			features = append(features, "g:"+string(padded[i:i+3]))
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		for i, r := range cjk {
+			features = append(features, "c:"+string(r))
+			if i+1 < len(cjk) {
+				features = append(features, "b:"+string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return features
+}
+
+// normalize 将向量缩放为单位长度，零向量原样返回
+func normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+// cosine 返回两个向量的余弦相似度，维度不同或存在零向量时为 0
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
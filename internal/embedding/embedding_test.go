@@ -0,0 +1,134 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+// countingEmbedder 统计向量化的文本数
+type countingEmbedder struct {
+	Embedder
+	texts int
+}
+
+func (c *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	c.texts += len(texts)
+	return c.Embedder.Embed(ctx, texts)
+}
+
+func TestIndexRefreshAndSearch(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	embedder := &countingEmbedder{Embedder: NewHashEmbedder(0)}
+	ix, err := Open(dir, embedder, 2)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	tasks := []model.Task{
+		{ID: "t1", Title: "Prepare Q3 budget review", Description: "collect spending per team"},
+		{ID: "t2", Title: "买牛奶"},
+		{ID: "t3", Title: "整理第三季度预算表"},
+		{ID: "t4"},
+	}
+	if n, err := ix.Refresh(ctx, tasks); err != nil || n != 3 {
+		t.Fatalf("Refresh: %d, %v", n, err)
+	}
+
+	matches, err := ix.Search(ctx, "budget for Q3", nil, 1)
+	if err != nil || len(matches) != 1 || matches[0].TaskID != "t1" {
+		t.Fatalf("Search: %+v, %v", matches, err)
+	}
+	matches, err = ix.Search(ctx, "季度预算", map[string]bool{"t2": true, "t3": true}, 0)
+	if err != nil || len(matches) != 2 || matches[0].TaskID != "t3" {
+		t.Fatalf("Search with candidates: %+v, %v", matches, err)
+	}
+
+	// 只重新向量化内容变化的任务，已删除的任务从索引移除
+	tasks[1].Description = "全脂"
+	reopened, err := Open(dir, embedder, 2)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	embedder.texts = 0
+	if n, err := reopened.Refresh(ctx, tasks[:2]); err != nil || n != 1 || embedder.texts != 1 {
+		t.Fatalf("Refresh: %d, %v (embedded %d texts)", n, err, embedder.texts)
+	}
+	if matches, _ := reopened.Search(ctx, "预算", nil, 0); len(matches) != 2 {
+		t.Fatalf("removed task should not be searched: %+v", matches)
+	}
+
+	// 换用其他模型后旧向量不再使用
+	other, err := Open(dir, NewHashEmbedder(64), 0)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if matches, _ := other.Search(ctx, "budget", nil, 0); len(matches) != 0 {
+		t.Fatalf("vectors from another model should be dropped: %+v", matches)
+	}
+}
+
+func TestHTTPEmbedders(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch r.URL.Path {
+		case "/api/embed":
+			vectors := make([][]float32, len(req.Input))
+			for i := range req.Input {
+				vectors[i] = []float32{float32(i + 1), 0}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"embeddings": vectors})
+		case "/v1/embeddings":
+			gotAuth = r.Header.Get("Authorization")
+			data := make([]map[string]any, len(req.Input))
+			for i := range req.Input {
+				// 故意倒序返回，按 index 对应输入
+				data[len(req.Input)-1-i] = map[string]any{"index": i, "embedding": []float32{0, float32(i + 1)}}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+		default:
+			http.Error(w, "model not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	ollama, err := New(pkgconfig.StorageEmbeddingsConfig{Provider: "ollama", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	vectors, err := ollama.Embed(ctx, []string{"a", "b"})
+	if err != nil || len(vectors) != 2 || vectors[1][0] != 2 || ollama.Model() != "ollama/nomic-embed-text" {
+		t.Fatalf("ollama: %v, %v", vectors, err)
+	}
+
+	openai, err := New(pkgconfig.StorageEmbeddingsConfig{Provider: "openai", BaseURL: server.URL + "/v1/", APIKey: "sk-test"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	vectors, err = openai.Embed(ctx, []string{"a", "b"})
+	if err != nil || len(vectors) != 2 || vectors[0][1] != 1 || vectors[1][1] != 2 {
+		t.Fatalf("openai: %v, %v", vectors, err)
+	}
+	if gotAuth != "Bearer sk-test" {
+		t.Fatalf("unexpected authorization header: %q", gotAuth)
+	}
+
+	broken, _ := New(pkgconfig.StorageEmbeddingsConfig{Provider: "openai", BaseURL: server.URL})
+	if _, err := broken.Embed(ctx, []string{"a"}); err == nil {
+		t.Fatal("expected error for non-2xx response")
+	}
+	if _, err := New(pkgconfig.StorageEmbeddingsConfig{Provider: "word2vec"}); err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+}
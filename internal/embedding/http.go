@@ -0,0 +1,114 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ollamaEmbedder 调用 Ollama 的 /api/embed 接口
+type ollamaEmbedder struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func (o *ollamaEmbedder) Model() string {
+	return ProviderOllama + "/" + o.model
+}
+
+func (o *ollamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	body := map[string]any{"model": o.model, "input": texts}
+	if err := postJSON(ctx, o.client, o.baseURL+"/api/embed", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedding response has %d vectors for %d inputs", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, nil
+}
+
+// openAIEmbedder 调用 OpenAI 兼容的 /embeddings 接口
+type openAIEmbedder struct {
+	baseURL    string
+	model      string
+	apiKey     string
+	dimensions int
+	client     *http.Client
+}
+
+func (o *openAIEmbedder) Model() string {
+	if o.dimensions > 0 {
+		return fmt.Sprintf("%s/%s/%d", ProviderOpenAI, o.model, o.dimensions)
+	}
+	return ProviderOpenAI + "/" + o.model
+}
+
+func (o *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	body := map[string]any{"model": o.model, "input": texts}
+	if o.dimensions > 0 {
+		body["dimensions"] = o.dimensions
+	}
+	headers := map[string]string{}
+	if o.apiKey != "" {
+		headers["Authorization"] = "Bearer " + o.apiKey
+	}
+	if err := postJSON(ctx, o.client, o.baseURL+"/embeddings", headers, body, &resp); err != nil {
+		return nil, err
+	}
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding response has out-of-range index %d", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, fmt.Errorf("embedding response is missing vector %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+// postJSON 发送 JSON 请求并解码响应，非 2xx 状态返回包含响应内容的错误
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("embedding request failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+	return nil
+}
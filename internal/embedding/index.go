@@ -0,0 +1,257 @@
+package embedding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+// FileName 向量索引文件名（位于存储目录）
+const FileName = "embeddings.json"
+
+// Match 一条搜索结果
+type Match struct {
+	TaskID string  `json:"task_id"`
+	Score  float64 `json:"score"`
+}
+
+// indexEntry 单个任务的向量与内容指纹
+type indexEntry struct {
+	hash   string
+	vector []float32
+}
+
+// indexFile 索引文件格式，向量以小端 float32 的 base64 保存
+type indexFile struct {
+	Model string                  `json:"model"`
+	Tasks map[string]storedVector `json:"tasks"`
+}
+
+type storedVector struct {
+	Hash   string `json:"hash"`
+	Vector string `json:"vector"`
+}
+
+// Index 任务向量索引，保存在 basePath/embeddings.json；
+// 是可以随时重建的缓存，多个进程同时写入时以最后一次写入为准
+type Index struct {
+	mu        sync.Mutex
+	filePath  string
+	embedder  Embedder
+	batchSize int
+	entries   map[string]indexEntry
+}
+
+// Open 打开 basePath 下的向量索引；索引由其他模型生成或无法解析时从空索引开始
+func Open(basePath string, embedder Embedder, batchSize int) (*Index, error) {
+	if err := os.MkdirAll(basePath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create embeddings dir: %w", err)
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	ix := &Index{
+		filePath:  filepath.Join(basePath, FileName),
+		embedder:  embedder,
+		batchSize: batchSize,
+		entries:   make(map[string]indexEntry),
+	}
+	if err := ix.load(); err != nil {
+		return nil, err
+	}
+	return ix, nil
+}
+
+// Model 返回索引使用的模型
+func (ix *Index) Model() string {
+	return ix.embedder.Model()
+}
+
+func (ix *Index) load() error {
+	data, err := storecrypt.ReadFile(ix.filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if errors.Is(err, storecrypt.ErrLocked) {
+		return fmt.Errorf("failed to read embeddings: %w", err)
+	}
+	var file indexFile
+	if err == nil {
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("file", ix.filePath).Msg("向量索引无法解析，将重新生成")
+		return nil
+	}
+	if file.Model != ix.embedder.Model() {
+		return nil
+	}
+	for id, stored := range file.Tasks {
+		vector, err := decodeVector(stored.Vector)
+		if err != nil {
+			continue
+		}
+		ix.entries[id] = indexEntry{hash: stored.Hash, vector: vector}
+	}
+	return nil
+}
+
+func (ix *Index) save() error {
+	file := indexFile{Model: ix.embedder.Model(), Tasks: make(map[string]storedVector, len(ix.entries))}
+	for id, entry := range ix.entries {
+		file.Tasks[id] = storedVector{Hash: entry.hash, Vector: encodeVector(entry.vector)}
+	}
+	data, err := json.Marshal(&file)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embeddings: %w", err)
+	}
+	tmp := ix.filePath + ".tmp"
+	if err := storecrypt.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write embeddings: %w", err)
+	}
+	if err := os.Rename(tmp, ix.filePath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write embeddings: %w", err)
+	}
+	return nil
+}
+
+// Refresh 使索引与 tasks 一致：向量化新增或标题、描述变化的任务，移除不在 tasks 中的任务，
+// 返回本次向量化的任务数。向量化中途失败时保留已完成的批次并返回错误
+func (ix *Index) Refresh(ctx context.Context, tasks []model.Task) (int, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	changed := false
+	seen := make(map[string]bool, len(tasks))
+	var pendingIDs, pendingTexts, pendingHashes []string
+	for i := range tasks {
+		text := taskText(&tasks[i])
+		if text == "" {
+			continue
+		}
+		id := tasks[i].ID
+		seen[id] = true
+		hash := textHash(text)
+		if entry, ok := ix.entries[id]; ok && entry.hash == hash {
+			continue
+		}
+		pendingIDs = append(pendingIDs, id)
+		pendingTexts = append(pendingTexts, text)
+		pendingHashes = append(pendingHashes, hash)
+	}
+	for id := range ix.entries {
+		if !seen[id] {
+			delete(ix.entries, id)
+			changed = true
+		}
+	}
+
+	embedded := 0
+	var embedErr error
+	for start := 0; start < len(pendingIDs); start += ix.batchSize {
+		end := min(start+ix.batchSize, len(pendingIDs))
+		vectors, err := ix.embedder.Embed(ctx, pendingTexts[start:end])
+		if err != nil {
+			embedErr = err
+			break
+		}
+		for i, vector := range vectors {
+			ix.entries[pendingIDs[start+i]] = indexEntry{hash: pendingHashes[start+i], vector: vector}
+		}
+		embedded += len(vectors)
+		changed = true
+	}
+	if changed {
+		if err := ix.save(); err != nil {
+			return embedded, err
+		}
+	}
+	return embedded, embedErr
+}
+
+// Search 返回与 query 最相似的任务，按相似度从高到低排列；
+// candidates 非 nil 时只在其中查找，limit<=0 时不限条数
+func (ix *Index) Search(ctx context.Context, query string, candidates map[string]bool, limit int) ([]Match, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	vectors, err := ix.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedding response has %d vectors for 1 input", len(vectors))
+	}
+
+	ix.mu.Lock()
+	matches := make([]Match, 0, len(ix.entries))
+	for id, entry := range ix.entries {
+		if candidates != nil && !candidates[id] {
+			continue
+		}
+		matches = append(matches, Match{TaskID: id, Score: cosine(vectors[0], entry.vector)})
+	}
+	ix.mu.Unlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].TaskID < matches[j].TaskID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// taskText 向量化的文本：标题与描述
+func taskText(task *model.Task) string {
+	return strings.TrimSpace(strings.TrimSpace(task.Title) + "\n" + strings.TrimSpace(task.Description))
+}
+
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+func encodeVector(vector []float32) string {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+func decodeVector(value string) ([]float32, error) {
+	buf, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid vector length %d", len(buf))
+	}
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vector, nil
+}
@@ -0,0 +1,228 @@
+// Package history 记录任务的字段变化历史：写类工具、同步拉取、webhook、镜像运行与资源轮询
+// 对本地任务的每次修改都按字段记录修改前后的值，用于回答“这个任务上周改了什么”。
+//
+// 各条记录路径可能观察到同一次修改（如写工具保存后轮询再次发现变化），
+// 每条记录保存修改后字段的指纹，与该任务最近一条记录相同时不再重复写入。
+package history
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// 变更来源
+const (
+	// OriginTool MCP 工具调用，Detail 为工具名
+	OriginTool = "tool"
+	// OriginCLI 命令行
+	OriginCLI = "cli"
+	// OriginSync 同步拉取或推送
+	OriginSync = "sync"
+	// OriginWebhook webhook 触发的拉取，Detail 为 Provider 名称
+	OriginWebhook = "webhook"
+	// OriginMirror Provider 之间的镜像运行，Detail 为运行 ID
+	OriginMirror = "mirror"
+	// OriginPoll 资源轮询发现的变化（如其他进程写入）
+	OriginPoll = "poll"
+	// OriginServer MCP 服务的后台任务（如重放离线写入队列）
+	OriginServer = "server"
+)
+
+// 变更类型
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+)
+
+// FieldChange 单个字段的变化，值为便于阅读的文本形式（时间为 RFC3339，列表以逗号分隔）
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// Entry 一次任务变更
+type Entry struct {
+	At     time.Time        `json:"at"`
+	TaskID string           `json:"task_id"`
+	RawID  string           `json:"raw_id,omitempty"`
+	Source model.TaskSource `json:"source,omitempty"`
+	Title  string           `json:"title"`
+	Action string           `json:"action"`
+	Origin string           `json:"origin,omitempty"`
+	Detail string           `json:"detail,omitempty"`
+	// Changes 变化的字段；新建时为全部非空字段，删除时为空
+	Changes []FieldChange `json:"changes,omitempty"`
+	// Hash 修改后字段的指纹，删除时为 deletedHash
+	Hash string `json:"hash"`
+}
+
+const deletedHash = "deleted"
+
+type originKey struct{}
+
+type origin struct {
+	name   string
+	detail string
+}
+
+// WithOrigin 在上下文中标注变更来源，之后经由该上下文的任务保存与删除记录为此来源
+func WithOrigin(ctx context.Context, name, detail string) context.Context {
+	return context.WithValue(ctx, originKey{}, origin{name: name, detail: detail})
+}
+
+// OriginFrom 返回上下文中标注的变更来源
+func OriginFrom(ctx context.Context) (string, string, bool) {
+	o, ok := ctx.Value(originKey{}).(origin)
+	return o.name, o.detail, ok
+}
+
+// trackedFields 记录历史的字段；UpdatedAt、同步元数据等每次保存都会变化或与用户无关的字段不记录
+var trackedFields = []struct {
+	name  string
+	value func(task *model.Task) string
+}{
+	{"title", func(t *model.Task) string { return t.Title }},
+	{"description", func(t *model.Task) string { return t.Description }},
+	{"status", func(t *model.Task) string { return string(t.Status) }},
+	{"due_date", func(t *model.Task) string { return formatTime(t.DueDate) }},
+	{"start_date", func(t *model.Task) string { return formatTime(t.StartDate) }},
+	{"reminder", func(t *model.Task) string { return formatTime(t.Reminder) }},
+	{"completed_at", func(t *model.Task) string { return formatTime(t.CompletedAt) }},
+	{"list", func(t *model.Task) string {
+		if t.ListName != "" {
+			return t.ListName
+		}
+		return t.ListID
+	}},
+	{"tags", func(t *model.Task) string { return strings.Join(t.Tags, ", ") }},
+	{"categories", func(t *model.Task) string { return strings.Join(t.Categories, ", ") }},
+	{"priority", func(t *model.Task) string { return formatInt(int(t.Priority)) }},
+	{"quadrant", func(t *model.Task) string { return formatInt(int(t.Quadrant)) }},
+	{"progress", func(t *model.Task) string { return formatInt(t.Progress) }},
+	{"estimated_minutes", func(t *model.Task) string { return formatInt(t.EstimatedMinutes) }},
+	{"parent_id", func(t *model.Task) string {
+		if t.ParentID == nil {
+			return ""
+		}
+		return *t.ParentID
+	}},
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatInt(v int) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
+
+// Diff 返回 before 到 after 变化的字段；before 为 nil 时返回 after 的全部非空字段，after 为 nil 时返回 nil
+func Diff(before, after *model.Task) []FieldChange {
+	if after == nil {
+		return nil
+	}
+	var changes []FieldChange
+	for _, field := range trackedFields {
+		newValue := field.value(after)
+		oldValue := ""
+		if before != nil {
+			oldValue = field.value(before)
+		}
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field.name, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
+
+// fingerprint 记录字段的指纹
+func fingerprint(task *model.Task) string {
+	h := sha256.New()
+	for _, field := range trackedFields {
+		h.Write([]byte(field.value(task)))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// newEntry 根据修改前后的任务生成变更记录，没有需要记录的变化时返回 nil
+func newEntry(ctx context.Context, before, after *model.Task, at time.Time) *Entry {
+	task := after
+	if task == nil {
+		task = before
+	}
+	if task == nil {
+		return nil
+	}
+	entry := &Entry{
+		At:     at,
+		TaskID: task.ID,
+		RawID:  task.SourceRawID,
+		Source: task.Source,
+		Title:  task.Title,
+	}
+	if entry.RawID == entry.TaskID {
+		entry.RawID = ""
+	}
+	entry.Origin, entry.Detail, _ = OriginFrom(ctx)
+	switch {
+	case after == nil:
+		entry.Action = ActionDeleted
+		entry.Hash = deletedHash
+	case before == nil:
+		entry.Action = ActionCreated
+		entry.Changes = Diff(nil, after)
+		entry.Hash = fingerprint(after)
+	default:
+		entry.Action = ActionUpdated
+		entry.Changes = Diff(before, after)
+		if len(entry.Changes) == 0 {
+			return nil
+		}
+		entry.Hash = fingerprint(after)
+	}
+	return entry
+}
+
+// Filter 历史查询条件
+type Filter struct {
+	// TaskID 任务 ID，同时匹配本地 ID 与远端原始 ID
+	TaskID string
+	// Source 任务来源，为空时不限
+	Source model.TaskSource
+	// Since、Until 时间范围，零值不限
+	Since time.Time
+	Until time.Time
+	// Limit 最多返回的条数，<=0 不限
+	Limit int
+}
+
+func (f Filter) match(entry *Entry) bool {
+	if f.TaskID != "" && entry.TaskID != f.TaskID && entry.RawID != f.TaskID {
+		return false
+	}
+	if f.Source != "" && entry.Source != f.Source {
+		return false
+	}
+	if !f.Since.IsZero() && entry.At.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.At.After(f.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package history
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+func TestWrapRecordsFieldChanges(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := filestore.New(dir, "json")
+	if err != nil {
+		t.Fatalf("filestore.New: %v", err)
+	}
+	h, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	store := Wrap(fs, h, OriginCLI)
+	ctx := context.Background()
+
+	if err := store.SaveTask(ctx, &model.Task{ID: "t1", Title: "Q3 预算", Status: model.StatusTodo, Source: model.SourceGoogle, SourceRawID: "raw-1"}); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	// 原地修改读取到的任务后保存，仍能比较出变化
+	task, err := store.GetTask(ctx, "t1")
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	task.Status = model.StatusCompleted
+	task.Tags = append(task.Tags, "finance")
+	if err := store.SaveTask(WithOrigin(ctx, OriginTool, "complete_task"), task); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	// 没有字段变化时不记录
+	if err := store.SaveTask(ctx, task); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	if err := store.DeleteTask(ctx, "t1"); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+
+	entries, err := h.Query(ctx, Filter{TaskID: "raw-1"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", entries)
+	}
+	if entries[0].Action != ActionDeleted || entries[2].Action != ActionCreated || entries[2].Origin != OriginCLI {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	updated := entries[1]
+	if updated.Origin != OriginTool || updated.Detail != "complete_task" || len(updated.Changes) != 2 {
+		t.Fatalf("unexpected update entry: %+v", updated)
+	}
+	if updated.Changes[0] != (FieldChange{Field: "status", Old: string(model.StatusTodo), New: string(model.StatusCompleted)}) {
+		t.Fatalf("unexpected status change: %+v", updated.Changes[0])
+	}
+	if entries, _ := h.Query(ctx, Filter{TaskID: "t1", Limit: 1}); len(entries) != 1 || entries[0].Action != ActionDeleted {
+		t.Fatalf("limit should keep the newest entry: %+v", entries)
+	}
+}
+
+func TestRecordSkipsChangesRecordedByOtherStores(t *testing.T) {
+	dir := t.TempDir()
+	first, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	second, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	ctx := context.Background()
+	before := &model.Task{ID: "t1", Title: "写周报"}
+	after := &model.Task{ID: "t1", Title: "写周报（终稿）"}
+	if err := first.Record(WithOrigin(ctx, OriginCLI, ""), before, after); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	// 模拟另一个进程轮询发现同一次修改
+	if err := second.Record(WithOrigin(ctx, OriginPoll, ""), before, after); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	entries, err := second.Query(ctx, Filter{TaskID: "t1"})
+	if err != nil || len(entries) != 1 || entries[0].Origin != OriginCLI {
+		t.Fatalf("expected a single cli entry: %+v, %v", entries, err)
+	}
+}
+
+func TestPruneAndEncryption(t *testing.T) {
+	t.Cleanup(func() { storecrypt.Use(nil) })
+	dir := t.TempDir()
+	raw, _ := storecrypt.GenerateKey()
+	key, err := storecrypt.UnlockRaw(dir, raw)
+	if err != nil {
+		t.Fatalf("UnlockRaw: %v", err)
+	}
+	storecrypt.Use(key)
+
+	h, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	ctx := context.Background()
+	if err := h.Record(ctx, nil, &model.Task{ID: "old", Title: "Q3 budget"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	cutoff := time.Now()
+	if err := h.Record(ctx, nil, &model.Task{ID: "new", Title: "Q4 budget"}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatalf("read history: %v", err)
+	}
+	if strings.Contains(string(data), "budget") {
+		t.Fatalf("history should be encrypted: %s", data)
+	}
+
+	removed, err := h.Prune(cutoff)
+	if err != nil || removed != 1 {
+		t.Fatalf("Prune: %d, %v", removed, err)
+	}
+	entries, err := h.Query(ctx, Filter{})
+	if err != nil || len(entries) != 1 || entries[0].TaskID != "new" {
+		t.Fatalf("unexpected entries after prune: %+v, %v", entries, err)
+	}
+}
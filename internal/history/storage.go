@@ -0,0 +1,144 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+	tbsync "github.com/yeisme/taskbridge/internal/sync"
+)
+
+// recordingStorage 在保存与删除任务时记录字段变化的任务存储
+type recordingStorage struct {
+	storage.Storage
+	history *Store
+	// origin 上下文未标注来源时使用的来源
+	origin string
+}
+
+// Wrap 包装任务存储，之后经由它的 SaveTask、SaveTasks 与 DeleteTask 都记录到 history；
+// 上下文未通过 WithOrigin 标注来源时记为 origin（如 cli、sync）。
+//
+// 返回的任务均为副本：调用方原地修改后再保存时，仍能与存储中修改前的任务比较
+func Wrap(store storage.Storage, history *Store, origin string) storage.Storage {
+	if store == nil || history == nil {
+		return store
+	}
+	return &recordingStorage{Storage: store, history: history, origin: origin}
+}
+
+// GetTask 获取任务的副本
+func (r *recordingStorage) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	task, err := r.Storage.GetTask(ctx, id)
+	if err != nil || task == nil {
+		return task, err
+	}
+	return CloneTask(task), nil
+}
+
+// SaveTask 保存任务并记录变化
+func (r *recordingStorage) SaveTask(ctx context.Context, task *model.Task) error {
+	before := r.previous(ctx, task.ID)
+	saved := CloneTask(task)
+	if err := r.Storage.SaveTask(ctx, saved); err != nil {
+		return err
+	}
+	task.UpdatedAt = saved.UpdatedAt
+	r.record(ctx, before, saved)
+	return nil
+}
+
+// SaveTasks 批量保存任务并记录变化
+func (r *recordingStorage) SaveTasks(ctx context.Context, tasks []*model.Task) error {
+	befores := make([]*model.Task, len(tasks))
+	saved := make([]*model.Task, len(tasks))
+	for i, task := range tasks {
+		befores[i] = r.previous(ctx, task.ID)
+		saved[i] = CloneTask(task)
+	}
+	if err := r.Storage.SaveTasks(ctx, saved); err != nil {
+		return err
+	}
+	for i, task := range tasks {
+		task.UpdatedAt = saved[i].UpdatedAt
+		r.record(ctx, befores[i], saved[i])
+	}
+	return nil
+}
+
+// DeleteTask 删除任务并记录
+func (r *recordingStorage) DeleteTask(ctx context.Context, id string) error {
+	before := r.previous(ctx, id)
+	if err := r.Storage.DeleteTask(ctx, id); err != nil {
+		return err
+	}
+	if before != nil {
+		r.record(ctx, before, nil)
+	}
+	return nil
+}
+
+func (r *recordingStorage) previous(ctx context.Context, id string) *model.Task {
+	task, err := r.Storage.GetTask(ctx, id)
+	if err != nil || task == nil {
+		return nil
+	}
+	return CloneTask(task)
+}
+
+// record 写入历史；失败只记录警告，不影响任务保存
+func (r *recordingStorage) record(ctx context.Context, before, after *model.Task) {
+	if _, _, ok := OriginFrom(ctx); !ok {
+		ctx = WithOrigin(ctx, r.origin, "")
+	}
+	if err := r.history.Record(ctx, before, after); err != nil {
+		log.Warn().Err(err).Msg("写入任务历史失败")
+	}
+}
+
+// CloneTask 复制任务，切片与时间指针不与原任务共享
+func CloneTask(task *model.Task) *model.Task {
+	clone := *task
+	clone.Tags = append([]string(nil), task.Tags...)
+	clone.Categories = append([]string(nil), task.Categories...)
+	clone.SubtaskIDs = append([]string(nil), task.SubtaskIDs...)
+	for _, t := range []**time.Time{&clone.DueDate, &clone.StartDate, &clone.Reminder, &clone.CompletedAt} {
+		if *t != nil {
+			value := **t
+			*t = &value
+		}
+	}
+	if task.ParentID != nil {
+		parent := *task.ParentID
+		clone.ParentID = &parent
+	}
+	return &clone
+}
+
+// mirrorJournal 同时写入镜像变更日志与任务历史
+type mirrorJournal struct {
+	tbsync.MirrorJournal
+	history *Store
+}
+
+// WrapMirrorJournal 包装镜像变更日志，镜像对 Provider 应用的每次变更同时记录到 history，来源为 mirror，Detail 为运行 ID
+func WrapMirrorJournal(journal tbsync.MirrorJournal, history *Store) tbsync.MirrorJournal {
+	if journal == nil || history == nil {
+		return journal
+	}
+	return &mirrorJournal{MirrorJournal: journal, history: history}
+}
+
+// Append 追加变更并记录任务历史
+func (j *mirrorJournal) Append(ctx context.Context, change tbsync.MirrorChange) error {
+	if err := j.MirrorJournal.Append(ctx, change); err != nil {
+		return err
+	}
+	if err := j.history.Record(WithOrigin(ctx, OriginMirror, change.RunID), change.Before, change.After); err != nil {
+		log.Warn().Err(err).Str("provider", change.Provider).Msg("写入任务历史失败")
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+// FileName 历史记录文件名（位于存储目录）
+const FileName = "task_history.jsonl"
+
+// Store 以 JSON Lines 保存的任务历史，只追加；多个进程（mcp start、sync start、命令行）可以同时写入
+type Store struct {
+	mu       sync.Mutex
+	filePath string
+	// offset 已读取到的文件位置，写入前读取其他进程追加的记录以更新 last
+	offset int64
+	// last 每个任务最近一条记录的字段指纹
+	last map[string]string
+}
+
+// Open 打开 basePath/task_history.jsonl
+func Open(basePath string) (*Store, error) {
+	if err := os.MkdirAll(basePath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create task history dir: %w", err)
+	}
+	s := &Store{filePath: filepath.Join(basePath, FileName), last: make(map[string]string)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Record 记录任务从 before 到 after 的变化（新建时 before 为 nil，删除时 after 为 nil），
+// 来源取自上下文（见 WithOrigin）；没有变化或与该任务最近一条记录相同时不写入
+func (s *Store) Record(ctx context.Context, before, after *model.Task) error {
+	entry := newEntry(ctx, before, after, time.Now())
+	if entry == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(); err != nil {
+		return err
+	}
+	if last, ok := s.last[entry.TaskID]; ok && last == entry.Hash {
+		return nil
+	}
+	if err := s.append(entry); err != nil {
+		return err
+	}
+	return s.refresh()
+}
+
+func (s *Store) append(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task history entry: %w", err)
+	}
+	// 启用存储加密时每行单独加密，保持只追加
+	line, err := storecrypt.SealString(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt task history entry: %w", err)
+	}
+	f, err := os.OpenFile(s.filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open task history: %w", err)
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write task history: %w", err)
+	}
+	return f.Close()
+}
+
+// refresh 读取 offset 之后的完整行并更新 last；末尾没有换行的半行留到下次读取
+func (s *Store) refresh() error {
+	f, err := os.Open(s.filePath)
+	if os.IsNotExist(err) {
+		s.offset = 0
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open task history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read task history: %w", err)
+	}
+	if info.Size() < s.offset {
+		// 文件被其他进程清理后重写，从头读取
+		s.offset = 0
+		s.last = make(map[string]string)
+	}
+	if _, err := f.Seek(s.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read task history: %w", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("failed to read task history: %w", err)
+	}
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil
+	}
+	entries, err := s.parse(data[:end+1])
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		s.last[entry.TaskID] = entry.Hash
+	}
+	s.offset += int64(end + 1)
+	return nil
+}
+
+// parse 解析历史记录；无法解析的行（如写入中断留下的半行）被跳过
+func (s *Store) parse(data []byte) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		line, err := storecrypt.OpenString(scanner.Text())
+		if errors.Is(err, storecrypt.ErrLocked) {
+			return nil, fmt.Errorf("failed to read task history: %w", err)
+		}
+		var entry Entry
+		if err == nil {
+			err = json.Unmarshal([]byte(line), &entry)
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("file", s.filePath).Msg("跳过无法解析的任务历史记录")
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read task history: %w", err)
+	}
+	return entries, nil
+}
+
+// readAll 读取全部记录，按写入顺序排列
+func (s *Store) readAll() ([]Entry, error) {
+	data, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task history: %w", err)
+	}
+	return s.parse(data)
+}
+
+// Query 返回满足条件的记录，最近的在前
+func (s *Store) Query(_ context.Context, filter Filter) ([]Entry, error) {
+	s.mu.Lock()
+	entries, err := s.readAll()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Entry, 0)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !filter.match(&entries[i]) {
+			continue
+		}
+		result = append(result, entries[i])
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Prune 删除早于 cutoff 的记录，返回删除的条数。
+// 通过替换文件完成，与其他进程的写入同时发生时可能丢失其间追加的记录，因此只在服务启动时调用
+func (s *Store) Prune(cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.readAll()
+	if err != nil {
+		return 0, err
+	}
+	kept := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.At.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	removed := len(entries) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	for i := range kept {
+		data, err := json.Marshal(&kept[i])
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal task history entry: %w", err)
+		}
+		line, err := storecrypt.SealString(string(data))
+		if err != nil {
+			return 0, fmt.Errorf("failed to encrypt task history entry: %w", err)
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	tmp := s.filePath + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return 0, fmt.Errorf("failed to write task history: %w", err)
+	}
+	if err := os.Rename(tmp, s.filePath); err != nil {
+		_ = os.Remove(tmp)
+		return 0, fmt.Errorf("failed to write task history: %w", err)
+	}
+	s.offset = 0
+	s.last = make(map[string]string)
+	return removed, s.refresh()
+}
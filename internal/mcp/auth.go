@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+// wrapHTTPHandler 为 HTTP 传输的路由套上公共中间件，由外到内依次为代理头还原、CORS、响应压缩、SSE 心跳、请求体上限、
+// 认证失败限流、访问认证、限流。认证之后的限流按已认证的 API key/用户区分客户端；
+// 认证之前的限流按 IP 统计认证失败的请求，这类请求到不了认证之后。
+func (s *Server) wrapHTTPHandler(handler http.Handler) (http.Handler, error) {
+	rateLimited := s.rateLimitConfig != nil && s.rateLimitConfig.Enabled
+	if rateLimited {
+		handler = newRateLimiter(s.rateLimitConfig.RequestsPerSecond, s.rateLimitConfig.Burst).middleware(handler)
+	}
+	handler, err := s.authHandler(handler)
+	if err != nil {
+		return nil, err
+	}
+	if rateLimited {
+		handler = newRateLimiter(s.rateLimitConfig.RequestsPerSecond, s.rateLimitConfig.Burst).authFailureMiddleware(handler)
+	}
+	if s.httpConfig != nil && s.httpConfig.MaxRequestBytes > 0 {
+		handler = bodyLimitMiddleware(s.httpConfig.MaxRequestBytes, handler)
+	}
+	if s.httpConfig != nil && (s.httpConfig.SSEHeartbeat > 0 || s.httpConfig.SSERetry > 0) {
+		handler = sseHeartbeatMiddleware(s.httpConfig.SSEHeartbeat, s.httpConfig.SSERetry, handler)
+	}
+	if s.httpConfig != nil && s.httpConfig.Compression {
+		handler = compressionMiddleware(handler)
+	}
+	if s.corsConfig != nil && len(s.corsConfig.AllowedOrigins) > 0 {
+		handler = corsMiddleware(s.corsConfig, handler)
+	}
+	if trusted := s.trustedProxies(); len(trusted) > 0 {
+		handler = forwardedHeadersMiddleware(trusted, handler)
+	}
+	return handler, nil
+}
+
+// authHandler 按 mcp.security.auth_mode 包装认证中间件
+func (s *Server) authHandler(handler http.Handler) (http.Handler, error) {
+	if cfg := s.securityConfig; cfg != nil && cfg.Enabled && strings.EqualFold(strings.TrimSpace(cfg.AuthMode), "oauth") {
+		return s.oauthHandler(cfg.OAuth, handler)
+	}
+	tokens, err := loadAuthTokens(s.securityConfig)
+	if err != nil {
+		return nil, err
+	}
+	if tokens != nil {
+		handler = tokenAuthMiddleware(tokens, handler)
+	}
+	return handler, nil
+}
+
+// loadAuthTokens 读取 token 认证允许的 token/API key：配置中的 tokens 与 token_file 合并。
+// 未启用 token 认证时返回 nil；启用但没有任何 token 时返回错误，避免以无认证状态对外监听。
+func loadAuthTokens(cfg *pkgconfig.SecurityConfig) ([]string, error) {
+	if cfg == nil || !cfg.Enabled || !strings.EqualFold(strings.TrimSpace(cfg.AuthMode), "token") {
+		return nil, nil
+	}
+	tokens := make([]string, 0, len(cfg.Tokens))
+	for _, token := range cfg.Tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	if path := strings.TrimSpace(cfg.TokenFile); path != "" {
+		fileTokens, err := readTokenFile(path)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, fileTokens...)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("auth mode token requires at least one token")
+	}
+	return tokens, nil
+}
+
+// readTokenFile 读取 token 文件：每行一个 token，忽略空行与 # 开头的注释
+func readTokenFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open token file: %w", err)
+	}
+	defer file.Close()
+
+	var tokens []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read token file: %w", err)
+	}
+	return tokens, nil
+}
+
+// authenticatedKeyKey 请求上下文中已通过 token 认证的 API key 摘要
+type authenticatedKeyKey struct{}
+
+// tokenAuthMiddleware 校验 Authorization: Bearer 或 X-API-Key 请求头，未通过时返回 401；
+// 通过时在上下文中记录 API key 的摘要（不保存明文），供限流按 key 区分客户端
+func tokenAuthMiddleware(tokens []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := requestToken(r)
+		if !validRequestToken(token, tokens) {
+			log.Debug().Str("remote", r.RemoteAddr).Str("path", r.URL.Path).Msg("rejected unauthenticated request")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="taskbridge"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		sum := sha256.Sum256([]byte(token))
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authenticatedKeyKey{}, hex.EncodeToString(sum[:8]))))
+	})
+}
+
+// authenticatedKey 返回 tokenAuthMiddleware 记录的 API key 摘要，未经 token 认证时为空
+func authenticatedKey(ctx context.Context) string {
+	key, _ := ctx.Value(authenticatedKeyKey{}).(string)
+	return key
+}
+
+// requestToken 从请求头中提取客户端提供的 token
+func requestToken(r *http.Request) string {
+	if auth := strings.TrimSpace(r.Header.Get("Authorization")); auth != "" {
+		scheme, token, ok := strings.Cut(auth, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// validRequestToken 使用常量时间比较，避免通过响应耗时猜测 token
+func validRequestToken(token string, tokens []string) bool {
+	if token == "" {
+		return false
+	}
+	matched := 0
+	for _, candidate := range tokens {
+		matched |= subtle.ConstantTimeCompare([]byte(token), []byte(candidate))
+	}
+	return matched == 1
+}
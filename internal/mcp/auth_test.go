@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestTokenAuthMiddleware(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(tokenFile, []byte("# 团队共享 key\nfile-key\n\n"), 0o600); err != nil {
+		t.Fatalf("write token file: %v", err)
+	}
+	s := NewServer(WithSecurityConfig(&pkgconfig.SecurityConfig{
+		Enabled: true, AuthMode: "token", Tokens: []string{"secret"}, TokenFile: tokenFile,
+	}))
+	handler, err := s.wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+
+	cases := []struct {
+		header, value string
+		want          int
+	}{
+		{"", "", http.StatusUnauthorized},
+		{"Authorization", "Bearer secret", http.StatusNoContent},
+		{"Authorization", "bearer file-key", http.StatusNoContent},
+		{"Authorization", "Bearer wrong", http.StatusUnauthorized},
+		{"Authorization", "Basic c2VjcmV0", http.StatusUnauthorized},
+		{"X-API-Key", "file-key", http.StatusNoContent},
+		{"X-API-Key", "# 团队共享 key", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if tc.header != "" {
+			req.Header.Set(tc.header, tc.value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Fatalf("%s=%q: want %d, got %d", tc.header, tc.value, tc.want, rec.Code)
+		}
+		if tc.want == http.StatusUnauthorized && !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Bearer") {
+			t.Fatalf("expected WWW-Authenticate challenge for %s=%q", tc.header, tc.value)
+		}
+	}
+}
+
+func TestLoadAuthTokens(t *testing.T) {
+	// 未启用或非 token 模式不做认证
+	for _, cfg := range []*pkgconfig.SecurityConfig{nil, {Enabled: false, AuthMode: "token", Tokens: []string{"x"}}, {Enabled: true, AuthMode: "none"}} {
+		if tokens, err := loadAuthTokens(cfg); err != nil || tokens != nil {
+			t.Fatalf("expected auth disabled for %+v, got %v %v", cfg, tokens, err)
+		}
+	}
+	// 启用 token 模式但没有 token 时拒绝启动
+	if _, err := loadAuthTokens(&pkgconfig.SecurityConfig{Enabled: true, AuthMode: "token", Tokens: []string{" "}}); err == nil {
+		t.Fatalf("expected error without tokens")
+	}
+	if _, err := loadAuthTokens(&pkgconfig.SecurityConfig{Enabled: true, AuthMode: "token", TokenFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Fatalf("expected error for missing token file")
+	}
+}
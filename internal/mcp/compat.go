@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+const (
+	// defaultProtocolVersion go-sdk 对未知版本回退的最新稳定规范版本
+	defaultProtocolVersion = "2025-06-18"
+	// structuredContentProtocolVersion 引入 structuredContent 与 outputSchema 的规范版本
+	structuredContentProtocolVersion = "2025-06-18"
+)
+
+// compatMiddleware 协议兼容层：按配置限制 initialize 协商的规范版本，
+// 并对不支持结构化输出的客户端移除 tools/list 的 outputSchema 与 tools/call 的 structuredContent。
+func (s *Server) compatMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		switch method {
+		case "initialize":
+			if init, ok := req.(*mcp.ServerRequest[*mcp.InitializeParams]); ok && init.Params != nil {
+				init.Params.ProtocolVersion = s.negotiateProtocolVersion(init.Params.ProtocolVersion)
+			}
+			return next(ctx, method, req)
+		case "tools/list", "tools/call":
+			result, err := next(ctx, method, req)
+			if err != nil || result == nil {
+				return result, err
+			}
+			session, _ := req.GetSession().(*mcp.ServerSession)
+			if s.structuredContentEnabled(session) {
+				return result, nil
+			}
+			switch res := result.(type) {
+			case *mcp.ListToolsResult:
+				stripOutputSchemas(res)
+			case *mcp.CallToolResult:
+				stripStructuredContent(res)
+			}
+			return result, nil
+		default:
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// allowedProtocolVersions 返回配置允许协商的版本；未配置时返回 nil，由 go-sdk 自行协商
+func (s *Server) allowedProtocolVersions() []string {
+	if s.compatConfig == nil {
+		return nil
+	}
+	allowed := make([]string, 0, len(s.compatConfig.ProtocolVersions))
+	// 按 SupportedProtocolVersions 的顺序（从新到旧）整理配置值
+	for _, version := range pkgconfig.SupportedProtocolVersions {
+		for _, configured := range s.compatConfig.ProtocolVersions {
+			if strings.TrimSpace(configured) == version {
+				allowed = append(allowed, version)
+				break
+			}
+		}
+	}
+	return allowed
+}
+
+// negotiateProtocolVersion 选择响应给客户端的规范版本：
+// 客户端请求的版本被允许时直接使用，否则取不晚于请求版本的最新允许版本，仍没有时取最新允许版本。
+func (s *Server) negotiateProtocolVersion(requested string) string {
+	allowed := s.allowedProtocolVersions()
+	if len(allowed) == 0 {
+		return requested
+	}
+	for _, version := range allowed {
+		if version == requested {
+			return version
+		}
+	}
+	// 规范版本号为日期，可按字符串比较先后
+	for _, version := range allowed {
+		if version <= requested {
+			return version
+		}
+	}
+	return allowed[0]
+}
+
+// sessionProtocolVersion 返回会话协商后的规范版本
+func sessionProtocolVersion(session *mcp.ServerSession) string {
+	if session == nil {
+		return defaultProtocolVersion
+	}
+	params := session.InitializeParams()
+	if params == nil || !pkgconfig.IsSupportedProtocolVersion(params.ProtocolVersion) {
+		return defaultProtocolVersion
+	}
+	return params.ProtocolVersion
+}
+
+// structuredContentEnabled 判断是否向会话返回结构化输出：auto 模式下按协商版本判断
+func (s *Server) structuredContentEnabled(session *mcp.ServerSession) bool {
+	mode := "auto"
+	if s.compatConfig != nil && strings.TrimSpace(s.compatConfig.StructuredContent) != "" {
+		mode = strings.ToLower(strings.TrimSpace(s.compatConfig.StructuredContent))
+	}
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		return sessionProtocolVersion(session) >= structuredContentProtocolVersion
+	}
+}
+
+// legacySSEEnabled 判断 streamable 模式下是否同时提供旧版 HTTP+SSE 端点
+func (s *Server) legacySSEEnabled() bool {
+	return s.compatConfig != nil && s.compatConfig.LegacySSE
+}
+
+// stripOutputSchemas 移除工具列表中的 outputSchema；工具定义为共享对象，逐个复制后修改
+func stripOutputSchemas(res *mcp.ListToolsResult) {
+	tools := make([]*mcp.Tool, 0, len(res.Tools))
+	for _, tool := range res.Tools {
+		if tool != nil && tool.OutputSchema != nil {
+			copied := *tool
+			copied.OutputSchema = nil
+			tool = &copied
+		}
+		tools = append(tools, tool)
+	}
+	res.Tools = tools
+}
+
+// stripStructuredContent 移除 structuredContent；仅有结构化输出时先转为 JSON 文本，避免旧客户端拿到空结果
+func stripStructuredContent(res *mcp.CallToolResult) {
+	if res.StructuredContent == nil {
+		return
+	}
+	if len(res.Content) == 0 {
+		if text, err := toJSON(res.StructuredContent); err == nil {
+			res.Content = []mcp.Content{&mcp.TextContent{Text: text}}
+		}
+	}
+	res.StructuredContent = nil
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func newCompatTestServer(t *testing.T, compat *pkgconfig.CompatConfig) *Server {
+	t.Helper()
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	if err := store.SaveTask(context.Background(), &model.Task{ID: "local-1", Title: "写周报", Status: model.StatusTodo, Source: model.SourceLocal}); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+	s := NewServer(WithTaskStorage(store), WithCompatConfig(compat))
+	s.server.AddTool(&sdkmcp.Tool{
+		Name:         "typed_echo",
+		InputSchema:  json.RawMessage(`{"type":"object"}`),
+		OutputSchema: json.RawMessage(`{"type":"object","properties":{"ok":{"type":"boolean"}}}`),
+	}, func(context.Context, *sdkmcp.CallToolRequest) (*sdkmcp.CallToolResult, error) {
+		return &sdkmcp.CallToolResult{StructuredContent: map[string]any{"ok": true}}, nil
+	})
+	return s
+}
+
+func callCompatTools(t *testing.T, session *sdkmcp.ClientSession) (getTask, typedEcho *sdkmcp.CallToolResult, schema any) {
+	t.Helper()
+	ctx := context.Background()
+	var err error
+	getTask, err = session.CallTool(ctx, &sdkmcp.CallToolParams{Name: "get_task", Arguments: map[string]any{"id": "local-1", "refresh": false}})
+	if err != nil || getTask.IsError {
+		t.Fatalf("call get_task: %v %+v", err, getTask)
+	}
+	typedEcho, err = session.CallTool(ctx, &sdkmcp.CallToolParams{Name: "typed_echo"})
+	if err != nil {
+		t.Fatalf("call typed_echo: %v", err)
+	}
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("list tools: %v", err)
+		}
+		if tool.Name == "typed_echo" {
+			schema = tool.OutputSchema
+		}
+	}
+	return getTask, typedEcho, schema
+}
+
+func TestCompatStripsStructuredContentForOlderProtocol(t *testing.T) {
+	s := newCompatTestServer(t, &pkgconfig.CompatConfig{ProtocolVersions: []string{"2025-03-26", "2024-11-05"}})
+	session := connectBreakdownClient(t, s, nil)
+	if got := session.InitializeResult().ProtocolVersion; got != "2025-03-26" {
+		t.Fatalf("expected negotiated 2025-03-26, got %s", got)
+	}
+
+	getTask, typedEcho, schema := callCompatTools(t, session)
+	if getTask.StructuredContent != nil || len(getTask.Content) == 0 {
+		t.Fatalf("expected structured content stripped with text kept: %+v", getTask)
+	}
+	if typedEcho.StructuredContent != nil || len(typedEcho.Content) != 1 {
+		t.Fatalf("expected structured-only result converted to text: %+v", typedEcho)
+	}
+	if text := typedEcho.Content[0].(*sdkmcp.TextContent).Text; text == "" {
+		t.Fatalf("expected JSON text fallback")
+	}
+	if schema != nil {
+		t.Fatalf("expected outputSchema stripped, got %v", schema)
+	}
+	if status := s.sessionStatus(); status["protocol_versions"].(map[string]int)["2025-03-26"] != 1 {
+		t.Fatalf("unexpected session status: %v", status)
+	}
+}
+
+func TestCompatKeepsStructuredContent(t *testing.T) {
+	for _, compat := range []*pkgconfig.CompatConfig{
+		nil,
+		{ProtocolVersions: []string{"2025-03-26"}, StructuredContent: "always"},
+	} {
+		s := newCompatTestServer(t, compat)
+		getTask, typedEcho, schema := callCompatTools(t, connectBreakdownClient(t, s, nil))
+		if getTask.StructuredContent == nil || typedEcho.StructuredContent == nil || schema == nil {
+			t.Fatalf("expected structured output kept for %+v", compat)
+		}
+	}
+
+	s := newCompatTestServer(t, &pkgconfig.CompatConfig{StructuredContent: "never"})
+	if getTask, _, _ := callCompatTools(t, connectBreakdownClient(t, s, nil)); getTask.StructuredContent != nil {
+		t.Fatalf("expected structured content stripped in never mode")
+	}
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	s := NewServer(WithCompatConfig(&pkgconfig.CompatConfig{ProtocolVersions: []string{"2024-11-05", "2025-06-18"}}))
+	cases := map[string]string{
+		"2025-06-18": "2025-06-18",
+		"2024-11-05": "2024-11-05",
+		"2025-03-26": "2024-11-05",
+		"2026-01-01": "2025-06-18",
+		"2024-01-01": "2025-06-18",
+	}
+	for requested, want := range cases {
+		if got := s.negotiateProtocolVersion(requested); got != want {
+			t.Fatalf("negotiate %s: want %s, got %s", requested, want, got)
+		}
+	}
+	if got := NewServer().negotiateProtocolVersion("2024-11-05"); got != "2024-11-05" {
+		t.Fatalf("expected passthrough without config, got %s", got)
+	}
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                      "",
+		"br":                    "",
+		"gzip":                  "gzip",
+		"deflate, gzip":         "gzip",
+		"deflate":               "deflate",
+		"gzip;q=0, deflate":     "deflate",
+		"gzip;q=0.5, deflate":   "deflate",
+		"gzip;q=0, deflate;q=0": "",
+	}
+	for header, want := range cases {
+		if got := negotiateEncoding(header); got != want {
+			t.Fatalf("negotiateEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCompressionMiddleware(t *testing.T) {
+	body := `{"tasks":["a","b","c"]}`
+	handler := compressionMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/text" {
+			w.Header().Set("Content-Type", "text/plain")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		_, _ = io.WriteString(w, body)
+	}))
+
+	request := func(path, encoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if encoding != "" {
+			req.Header.Set("Accept-Encoding", encoding)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("/mcp", "gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expected gzip response, got %v", rec.Header())
+	}
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	if decoded, _ := io.ReadAll(reader); string(decoded) != body {
+		t.Fatalf("unexpected gzip body: %q", decoded)
+	}
+
+	rec = request("/mcp", "deflate")
+	if rec.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("expected deflate response, got %v", rec.Header())
+	}
+	if decoded, _ := io.ReadAll(flate.NewReader(rec.Body)); string(decoded) != body {
+		t.Fatalf("unexpected deflate body: %q", decoded)
+	}
+
+	for path, encoding := range map[string]string{"/mcp": "", "/text": "gzip"} {
+		rec = request(path, encoding)
+		if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != body {
+			t.Fatalf("expected uncompressed response for %s %q, got %v", path, encoding, rec.Header())
+		}
+	}
+}
+
+func TestCompressionStreamableHTTP(t *testing.T) {
+	s := NewServer(
+		WithConfig(&ServerConfig{Name: "taskbridge", Transport: "streamable"}),
+		WithHTTPConfig(&pkgconfig.HTTPConfig{Compression: true}),
+	)
+	handler, err := s.wrapHTTPHandler(s.streamableMux())
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	// Go 客户端默认发送 Accept-Encoding: gzip 并透明解压，SSE 响应需要逐条刷新才能完成握手
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "gzip-client", Version: "0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), &sdkmcp.StreamableClientTransport{Endpoint: srv.URL + "/mcp"}, nil)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil || len(tools.Tools) == 0 {
+		t.Fatalf("list tools over compressed transport: %v", err)
+	}
+}
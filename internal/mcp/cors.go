@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+// corsAllowedMethods MCP HTTP 端点使用的方法：streamable 的 POST/GET/DELETE 与 SSE 的 GET/POST
+const corsAllowedMethods = "GET, POST, DELETE, OPTIONS"
+
+// corsMiddleware 按配置为允许的 Origin 返回 CORS 响应头，并直接应答预检请求。
+// 预检请求不携带凭据，需在认证之前处理。
+func corsMiddleware(cfg *pkgconfig.CORSConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		allowed, wildcard := corsOriginAllowed(cfg.AllowedOrigins, origin)
+		if !allowed {
+			// 不返回 CORS 头，由浏览器拦截跨域响应
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if wildcard && !cfg.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if len(cfg.ExposedHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+		}
+		if !preflight {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+		if len(cfg.AllowedHeaders) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+		}
+		if cfg.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// corsOriginAllowed 判断 Origin 是否在允许列表中（大小写不敏感），第二个返回值表示按 * 放行
+func corsOriginAllowed(allowed []string, origin string) (bool, bool) {
+	for _, item := range allowed {
+		item = strings.TrimSuffix(strings.TrimSpace(item), "/")
+		if item == "*" {
+			return true, true
+		}
+		if strings.EqualFold(item, origin) {
+			return true, false
+		}
+	}
+	return false, false
+}
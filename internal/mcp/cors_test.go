@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	cors := pkgconfig.DefaultConfig().MCP.CORS
+	cors.AllowedOrigins = []string{"https://app.example.com"}
+	cors.AllowCredentials = true
+	cors.MaxAge = time.Minute
+	s := NewServer(
+		WithCORSConfig(&cors),
+		WithSecurityConfig(&pkgconfig.SecurityConfig{Enabled: true, AuthMode: "token", Tokens: []string{"secret"}}),
+	)
+	handler, err := s.wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	serve := func(method, origin string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/mcp", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 预检请求不需要认证
+	rec := serve(http.MethodOptions, "https://app.example.com", map[string]string{"Access-Control-Request-Method": "POST"})
+	if rec.Code != http.StatusNoContent ||
+		rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		rec.Header().Get("Access-Control-Allow-Credentials") != "true" ||
+		rec.Header().Get("Access-Control-Max-Age") != "60" ||
+		rec.Header().Get("Access-Control-Allow-Headers") == "" {
+		t.Fatalf("unexpected preflight response: %d %v", rec.Code, rec.Header())
+	}
+
+	// 实际请求仍需认证，并暴露会话头
+	rec = serve(http.MethodPost, "https://app.example.com", map[string]string{"Authorization": "Bearer secret"})
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Expose-Headers") == "" {
+		t.Fatalf("unexpected cors response: %d %v", rec.Code, rec.Header())
+	}
+	if rec = serve(http.MethodPost, "https://app.example.com", nil); rec.Code != http.StatusUnauthorized || rec.Header().Get("Access-Control-Allow-Origin") == "" {
+		t.Fatalf("expected 401 readable by browser: %d %v", rec.Code, rec.Header())
+	}
+
+	// 未允许的来源不返回 CORS 头
+	if rec = serve(http.MethodOptions, "https://evil.example.com", map[string]string{"Access-Control-Request-Method": "POST"}); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected disallowed preflight rejected, got %d", rec.Code)
+	}
+	if rec = serve(http.MethodPost, "https://evil.example.com", map[string]string{"Authorization": "Bearer secret"}); rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("unexpected cors headers for disallowed origin: %v", rec.Header())
+	}
+}
+
+func TestCORSWildcardOrigin(t *testing.T) {
+	s := NewServer(WithCORSConfig(&pkgconfig.CORSConfig{AllowedOrigins: []string{"*"}}))
+	handler, err := s.wrapHTTPHandler(http.NotFoundHandler())
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/sse", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("expected wildcard origin, got %v", rec.Header())
+	}
+}
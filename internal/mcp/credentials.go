@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/provider"
+)
+
+// credentialCheckTimeout 单个 Provider 凭证检查（刷新与校验请求）的最长耗时
+const credentialCheckTimeout = 30 * time.Second
+
+// credentialMonitorState 后台凭证检查的最近结果，键为 Provider 名称
+type credentialMonitorState struct {
+	mu      sync.Mutex
+	results map[string]provider.CredentialHealth
+}
+
+// watchCredentials 按间隔检查服务自身 Provider 的凭证，启动时立即检查一次。
+// 临近过期的 token 会被主动刷新，无法恢复的凭证记录警告日志并在状态资源中标注。
+func (s *Server) watchCredentials(ctx context.Context, interval, refreshBefore time.Duration) {
+	s.CheckCredentials(ctx, refreshBefore)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CheckCredentials(ctx, refreshBefore)
+		}
+	}
+}
+
+// CheckCredentials 立即检查全部 Provider 的凭证并返回结果（按名称排序）
+func (s *Server) CheckCredentials(ctx context.Context, refreshBefore time.Duration) []provider.CredentialHealth {
+	s.providersMu.RLock()
+	providers := make(map[string]provider.Provider, len(s.providers))
+	for name, p := range s.providers {
+		if p != nil {
+			providers[name] = p
+		}
+	}
+	s.providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make(map[string]provider.CredentialHealth, len(names))
+	checked := make([]provider.CredentialHealth, 0, len(names))
+	for _, name := range names {
+		checkCtx, cancel := context.WithTimeout(ctx, credentialCheckTimeout)
+		health := provider.CheckCredential(checkCtx, providers[name], refreshBefore)
+		cancel()
+		if ctx.Err() != nil {
+			return checked
+		}
+		health.Provider = name
+		logCredentialHealth(health)
+		results[name] = health
+		checked = append(checked, health)
+	}
+
+	// 已移除的 Provider 不再保留旧结果
+	s.credentials.mu.Lock()
+	s.credentials.results = results
+	s.credentials.mu.Unlock()
+	return checked
+}
+
+// credentialHealth 返回 Provider 最近一次的凭证检查结果
+func (s *Server) credentialHealth(name string) (provider.CredentialHealth, bool) {
+	s.credentials.mu.Lock()
+	defer s.credentials.mu.Unlock()
+	health, ok := s.credentials.results[name]
+	return health, ok
+}
+
+func logCredentialHealth(health provider.CredentialHealth) {
+	switch health.Status {
+	case provider.CredentialOK:
+		log.Debug().Str("component", "mcp").Str("provider", health.Provider).Msg("credentials healthy")
+	case provider.CredentialRefreshed:
+		event := log.Info().Str("component", "mcp").Str("provider", health.Provider)
+		if health.ExpiresAt != nil {
+			event = event.Time("expires_at", *health.ExpiresAt)
+		}
+		event.Msg("token refreshed before expiry")
+	default:
+		log.Warn().Str("component", "mcp").Str("provider", health.Provider).Str("status", health.Status).Msg(health.Warning)
+	}
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+)
+
+// expiringMockProvider token 即将过期的 Provider，可配置是否可刷新与远端是否拒绝凭证
+type expiringMockProvider struct {
+	mockProvider
+	expiry      time.Time
+	refreshable bool
+	refreshed   int
+	listErr     error
+}
+
+func (m *expiringMockProvider) RefreshToken(context.Context) error {
+	m.refreshed++
+	m.expiry = time.Now().Add(time.Hour)
+	return nil
+}
+
+func (m *expiringMockProvider) GetTokenInfo() *provider.TokenInfo {
+	return &provider.TokenInfo{Provider: "google", HasToken: true, IsValid: time.Now().Before(m.expiry), ExpiresAt: m.expiry, Refreshable: m.refreshable}
+}
+
+func (m *expiringMockProvider) ListTaskLists(ctx context.Context) ([]model.TaskList, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	return m.mockProvider.ListTaskLists(ctx)
+}
+
+func TestCheckCredentialsRefreshesExpiringTokens(t *testing.T) {
+	refreshable := &expiringMockProvider{expiry: time.Now().Add(2 * time.Minute), refreshable: true}
+	static := &expiringMockProvider{expiry: time.Now().Add(2 * time.Minute)}
+	expired := &expiringMockProvider{expiry: time.Now().Add(-time.Minute)}
+	rejected := &expiringMockProvider{expiry: time.Now().Add(time.Hour), listErr: errors.New("401 unauthorized")}
+	s := NewServer(WithProviders(map[string]provider.Provider{
+		"google":    refreshable,
+		"microsoft": static,
+		"ticktick":  expired,
+		"todoist":   rejected,
+	}))
+
+	results := s.CheckCredentials(context.Background(), 10*time.Minute)
+	want := map[string]string{
+		"google":    provider.CredentialRefreshed,
+		"microsoft": provider.CredentialExpiring,
+		"ticktick":  provider.CredentialExpired,
+		"todoist":   provider.CredentialInvalid,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), results)
+	}
+	for _, health := range results {
+		if health.Status != want[health.Provider] {
+			t.Fatalf("%s: status %s, want %s (%s)", health.Provider, health.Status, want[health.Provider], health.Warning)
+		}
+		if health.Status != provider.CredentialRefreshed && health.Warning == "" {
+			t.Fatalf("%s: expected a warning", health.Provider)
+		}
+	}
+	if refreshable.refreshed != 1 || static.refreshed != 0 {
+		t.Fatalf("only refreshable tokens should be refreshed: %d %d", refreshable.refreshed, static.refreshed)
+	}
+
+	if health, ok := s.credentialHealth("todoist"); !ok || health.Healthy() {
+		t.Fatalf("expected unhealthy stored result for todoist, got %+v", health)
+	}
+	s.RemoveProvider("todoist")
+	s.CheckCredentials(context.Background(), 10*time.Minute)
+	if _, ok := s.credentialHealth("todoist"); ok {
+		t.Fatal("results of removed providers should be dropped")
+	}
+}
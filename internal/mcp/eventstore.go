@@ -0,0 +1,27 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// NewEventStore 按名称创建 streamable 流事件存储：memory 为进程内存储，sqlite 保存在 dir/mcp_events.db
+// （maxBytes>0 时限制容量），none 或空字符串返回 nil，表示不支持断线续传。其他实现可直接通过 WithEventStore 传入。
+func NewEventStore(kind, dir string, maxBytes int) (mcp.EventStore, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", "none":
+		return nil, nil
+	case "memory":
+		store := mcp.NewMemoryEventStore(nil)
+		if maxBytes > 0 {
+			store.SetMaxBytes(maxBytes)
+		}
+		return store, nil
+	case "sqlite":
+		return NewSQLiteEventStore(dir, maxBytes)
+	default:
+		return nil, fmt.Errorf("unsupported event store: %s", kind)
+	}
+}
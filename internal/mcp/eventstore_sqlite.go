@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	// 纯 Go 实现的 SQLite 驱动，无需 cgo
+	_ "modernc.org/sqlite"
+)
+
+// EventStoreFileName sqlite 事件存储在存储目录下的数据库文件名
+const EventStoreFileName = "mcp_events.db"
+
+// defaultEventStoreMaxBytes 未配置容量时 sqlite 事件存储保留的数据上限，与 go-sdk 内存存储一致
+const defaultEventStoreMaxBytes = 10 << 20
+
+// eventStoreSchema 每条流记录首个仍保留的事件序号与下一个序号，事件按 (会话, 流, 序号) 保存
+const eventStoreSchema = `
+CREATE TABLE IF NOT EXISTS mcp_event_streams (
+	session_id  TEXT NOT NULL,
+	stream_id   TEXT NOT NULL,
+	first_index INTEGER NOT NULL DEFAULT 0,
+	next_index  INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (session_id, stream_id)
+);
+CREATE TABLE IF NOT EXISTS mcp_events (
+	session_id TEXT NOT NULL,
+	stream_id  TEXT NOT NULL,
+	idx        INTEGER NOT NULL,
+	data       BLOB NOT NULL,
+	PRIMARY KEY (session_id, stream_id, idx)
+);
+`
+
+// SQLiteEventStore 基于 SQLite 的 streamable 流事件存储：事件写入磁盘而非进程内存，
+// 总量超过 maxBytes 时按写入顺序丢弃最早的事件。
+type SQLiteEventStore struct {
+	db       *sql.DB
+	maxBytes int
+}
+
+var _ mcp.EventStore = (*SQLiteEventStore)(nil)
+
+// NewSQLiteEventStore 打开 dir/mcp_events.db；maxBytes<=0 时使用默认容量 10 MiB
+func NewSQLiteEventStore(dir string, maxBytes int) (*SQLiteEventStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create event store directory: %w", err)
+	}
+	dsn := "file:" + filepath.Join(dir, EventStoreFileName) + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event store: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(eventStoreSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to init event store: %w", err)
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultEventStoreMaxBytes
+	}
+	return &SQLiteEventStore{db: db, maxBytes: maxBytes}, nil
+}
+
+// Close 关闭数据库
+func (s *SQLiteEventStore) Close() error {
+	return s.db.Close()
+}
+
+// Open 实现 mcp.EventStore，确保流已登记
+func (s *SQLiteEventStore) Open(ctx context.Context, sessionID, streamID string) error {
+	if _, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO mcp_event_streams (session_id, stream_id) VALUES (?, ?)`, sessionID, streamID); err != nil {
+		return fmt.Errorf("failed to open event stream: %w", err)
+	}
+	return nil
+}
+
+// Append 实现 mcp.EventStore，事件序号从 0 开始连续递增
+func (s *SQLiteEventStore) Append(ctx context.Context, sessionID, streamID string, data []byte) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO mcp_event_streams (session_id, stream_id) VALUES (?, ?)`, sessionID, streamID); err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	var next int
+	if err := tx.QueryRowContext(ctx, `SELECT next_index FROM mcp_event_streams WHERE session_id = ? AND stream_id = ?`, sessionID, streamID).Scan(&next); err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	res, err := tx.ExecContext(ctx, `INSERT INTO mcp_events (session_id, stream_id, idx, data) VALUES (?, ?, ?, ?)`, sessionID, streamID, next, data)
+	if err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE mcp_event_streams SET next_index = ? WHERE session_id = ? AND stream_id = ?`, next+1, sessionID, streamID); err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	inserted, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	if err := s.purge(ctx, tx, inserted); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// purge 总量超过上限时按写入顺序删除最早的事件，刚写入的事件始终保留；被删除事件所在流的 first_index 随之前移
+func (s *SQLiteEventStore) purge(ctx context.Context, tx *sql.Tx, keep int64) error {
+	var total int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(LENGTH(data)), 0) FROM mcp_events`).Scan(&total); err != nil {
+		return fmt.Errorf("failed to purge events: %w", err)
+	}
+	if total <= s.maxBytes {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT rowid, LENGTH(data) FROM mcp_events WHERE rowid < ? ORDER BY rowid`, keep)
+	if err != nil {
+		return fmt.Errorf("failed to purge events: %w", err)
+	}
+	var cutoff int64 = -1
+	for rows.Next() && total > s.maxBytes {
+		var rowid int64
+		var size int
+		if err := rows.Scan(&rowid, &size); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to purge events: %w", err)
+		}
+		cutoff = rowid
+		total -= size
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to purge events: %w", err)
+	}
+	if cutoff < 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mcp_events WHERE rowid <= ?`, cutoff); err != nil {
+		return fmt.Errorf("failed to purge events: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE mcp_event_streams SET first_index = COALESCE(
+		(SELECT MIN(e.idx) FROM mcp_events e WHERE e.session_id = mcp_event_streams.session_id AND e.stream_id = mcp_event_streams.stream_id),
+		next_index)`); err != nil {
+		return fmt.Errorf("failed to purge events: %w", err)
+	}
+	return nil
+}
+
+// After 实现 mcp.EventStore，返回序号 index 之后的事件；其中有事件已被丢弃时返回 mcp.ErrEventsPurged
+func (s *SQLiteEventStore) After(ctx context.Context, sessionID, streamID string, index int) iter.Seq2[[]byte, error] {
+	load := func() ([][]byte, error) {
+		var first int
+		err := s.db.QueryRowContext(ctx, `SELECT first_index FROM mcp_event_streams WHERE session_id = ? AND stream_id = ?`, sessionID, streamID).Scan(&first)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("SQLiteEventStore.After: unknown stream ID %v in session %q", streamID, sessionID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first > index+1 {
+			return nil, fmt.Errorf("SQLiteEventStore.After: index %d, stream ID %v, session %q: %w", index, streamID, sessionID, mcp.ErrEventsPurged)
+		}
+		rows, err := s.db.QueryContext(ctx, `SELECT data FROM mcp_events WHERE session_id = ? AND stream_id = ? AND idx > ? ORDER BY idx`, sessionID, streamID, index)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rows.Close() }()
+		var items [][]byte
+		for rows.Next() {
+			var data []byte
+			if err := rows.Scan(&data); err != nil {
+				return nil, err
+			}
+			items = append(items, data)
+		}
+		return items, rows.Err()
+	}
+
+	return func(yield func([]byte, error) bool) {
+		items, err := load()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, data := range items {
+			if !yield(data, nil) {
+				return
+			}
+		}
+	}
+}
+
+// SessionClosed 实现 mcp.EventStore，删除会话的全部流与事件
+func (s *SQLiteEventStore) SessionClosed(ctx context.Context, sessionID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to close event session: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mcp_events WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to close event session: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mcp_event_streams WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to close event session: %w", err)
+	}
+	return tx.Commit()
+}
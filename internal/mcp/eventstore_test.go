@@ -0,0 +1,206 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sseEvent 解析出的一条 SSE 事件
+type sseEvent struct {
+	id   string
+	data string
+}
+
+// readSSEEvent 从 SSE 流中读取下一条带数据的事件
+func readSSEEvent(t *testing.T, reader *bufio.Reader) sseEvent {
+	t.Helper()
+	var event sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read sse: %v", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case strings.HasPrefix(line, "id:"):
+			event.id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			event.data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		case line == "" && event.data != "":
+			return event
+		}
+	}
+}
+
+func postMCP(t *testing.T, url, sessionID, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post %s: %v", body, err)
+	}
+	return resp
+}
+
+func TestStreamableResumesWithLastEventID(t *testing.T) {
+	for _, kind := range []string{"memory", "sqlite"} {
+		t.Run(kind, func(t *testing.T) {
+			store, err := NewEventStore(kind, t.TempDir(), 1<<20)
+			if err != nil {
+				t.Fatalf("new event store: %v", err)
+			}
+			if closer, ok := store.(io.Closer); ok {
+				t.Cleanup(func() { _ = closer.Close() })
+			}
+			testStreamableResume(t, store)
+		})
+	}
+}
+
+func testStreamableResume(t *testing.T, store sdkmcp.EventStore) {
+	s := NewServer(WithConfig(&ServerConfig{Name: "taskbridge", Transport: "streamable"}), WithEventStore(store))
+	release := make(chan struct{})
+	s.server.AddTool(&sdkmcp.Tool{Name: "slow_echo", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		func(ctx context.Context, req *sdkmcp.CallToolRequest) (*sdkmcp.CallToolResult, error) {
+			_ = req.Session.NotifyProgress(ctx, &sdkmcp.ProgressNotificationParams{ProgressToken: req.Params.GetProgressToken(), Progress: 1, Total: 2})
+			<-release
+			return &sdkmcp.CallToolResult{Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: "done"}}}, nil
+		})
+	srv := httptest.NewServer(s.streamableMux())
+	t.Cleanup(srv.Close)
+	endpoint := srv.URL + "/mcp"
+
+	resp := postMCP(t, endpoint, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"raw","version":"0.0.1"}}}`)
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	resp.Body.Close()
+	if sessionID == "" {
+		t.Fatalf("expected session id")
+	}
+	postMCP(t, endpoint, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized","params":{}}`).Body.Close()
+
+	// 读到进度通知后断开，模拟网络抖动
+	resp = postMCP(t, endpoint, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow_echo","arguments":{},"_meta":{"progressToken":"p1"}}}`)
+	progress := readSSEEvent(t, bufio.NewReader(resp.Body))
+	resp.Body.Close()
+	if progress.id == "" || !strings.Contains(progress.data, "notifications/progress") {
+		t.Fatalf("expected progress event with id, got %+v", progress)
+	}
+	close(release)
+
+	// 携带 Last-Event-ID 重连，收到断线期间产生的工具结果
+	req, _ := http.NewRequest(http.MethodGet, endpoint, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Mcp-Session-Id", sessionID)
+	req.Header.Set("Last-Event-ID", progress.id)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("resume stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected resumed stream, got %d", resp.StatusCode)
+	}
+	result := readSSEEvent(t, bufio.NewReader(resp.Body))
+	if !strings.Contains(result.data, `"id":2`) || !strings.Contains(result.data, "done") {
+		t.Fatalf("expected replayed tool result, got %+v", result)
+	}
+	if status := s.sessionStatus(); status["resumable"] != true {
+		t.Fatalf("expected resumable session status: %v", status)
+	}
+}
+
+func TestNewEventStore(t *testing.T) {
+	for _, kind := range []string{"", "none"} {
+		if store, err := NewEventStore(kind, "", 0); err != nil || store != nil {
+			t.Fatalf("expected no event store for %q, got %v %v", kind, store, err)
+		}
+	}
+	if _, err := NewEventStore("redis", "", 0); err == nil {
+		t.Fatalf("expected unsupported event store error")
+	}
+	store, err := NewEventStore("sqlite", t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("new sqlite event store: %v", err)
+	}
+	if _, ok := store.(*SQLiteEventStore); !ok {
+		t.Fatalf("expected sqlite event store, got %T", store)
+	}
+	_ = store.(*SQLiteEventStore).Close()
+}
+
+func TestSQLiteEventStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewSQLiteEventStore(dir, 10)
+	if err != nil {
+		t.Fatalf("new sqlite event store: %v", err)
+	}
+	ctx := context.Background()
+	collect := func(store sdkmcp.EventStore, index int) ([]string, error) {
+		var out []string
+		for data, err := range store.After(ctx, "s1", "st1", index) {
+			if err != nil {
+				return out, err
+			}
+			out = append(out, string(data))
+		}
+		return out, nil
+	}
+
+	if _, err := collect(store, -1); err == nil {
+		t.Fatal("expected error for unopened stream")
+	}
+	if err := store.Open(ctx, "s1", "st1"); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for _, data := range []string{"aaaa", "bbbb"} {
+		if err := store.Append(ctx, "s1", "st1", []byte(data)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if got, err := collect(store, 0); err != nil || len(got) != 1 || got[0] != "bbbb" {
+		t.Fatalf("after 0: %v %v", got, err)
+	}
+
+	// 超过容量时丢弃最早的事件，之后从被丢弃的位置续传返回 ErrEventsPurged
+	if err := store.Append(ctx, "s1", "st1", []byte("cccc")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := collect(store, -1); !errors.Is(err, sdkmcp.ErrEventsPurged) {
+		t.Fatalf("expected purged error, got %v", err)
+	}
+	if got, err := collect(store, 0); err != nil || strings.Join(got, ",") != "bbbb,cccc" {
+		t.Fatalf("after purge: %v %v", got, err)
+	}
+
+	// 事件保存在磁盘上，重新打开后仍可读取
+	_ = store.Close()
+	store, err = NewSQLiteEventStore(dir, 10)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	if got, err := collect(store, 1); err != nil || len(got) != 1 || got[0] != "cccc" {
+		t.Fatalf("after reopen: %v %v", got, err)
+	}
+
+	if err := store.SessionClosed(ctx, "s1"); err != nil {
+		t.Fatalf("session closed: %v", err)
+	}
+	if _, err := collect(store, -1); err == nil {
+		t.Fatal("expected error after session closed")
+	}
+}
@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// federationSkippedTools 联邦模式下不代理的对端工具。
+// 所有下游会话共用同一个到对端的连接，会话级状态（set_context）会在下游会话之间串用。
+var federationSkippedTools = map[string]bool{
+	"set_context": true,
+	"get_context": true,
+}
+
+// checkFederationPeer 确认联邦上游确实是 taskbridge-mcp 实例
+func checkFederationPeer(session *mcp.ClientSession) error {
+	result := session.InitializeResult()
+	if result == nil || result.ServerInfo == nil || !strings.HasPrefix(result.ServerInfo.Name, "taskbridge") {
+		name := ""
+		if result != nil && result.ServerInfo != nil {
+			name = result.ServerInfo.Name
+		}
+		return fmt.Errorf("federated upstream is not a taskbridge-mcp instance: %q", name)
+	}
+	return nil
+}
+
+// skipFederatedTool 判断对端工具是否不应被代理：会话级工具，以及对端自身代理的工具（避免实例互相联邦时循环嵌套）
+func skipFederatedTool(name string) bool {
+	return federationSkippedTools[name] || strings.Contains(name, proxyToolSeparator)
+}
+
+// federationPeerAdapters 读取对端 taskbridge://status 资源，返回其已配置的 adapter 名称
+func federationPeerAdapters(ctx context.Context, session *mcp.ClientSession) ([]string, error) {
+	res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: statusResourceURI})
+	if err != nil {
+		return nil, fmt.Errorf("read peer status: %w", err)
+	}
+	if len(res.Contents) == 0 {
+		return nil, fmt.Errorf("peer status is empty")
+	}
+	var status struct {
+		Adapters []adapterStatus `json:"adapters"`
+	}
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &status); err != nil {
+		return nil, fmt.Errorf("decode peer status: %w", err)
+	}
+	adapters := make([]string, 0, len(status.Adapters))
+	for _, adapter := range status.Adapters {
+		adapters = append(adapters, adapter.Name)
+	}
+	sort.Strings(adapters)
+	return adapters, nil
+}
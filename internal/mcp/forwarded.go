@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+// forwardedHeadersMiddleware 在请求来自可信反向代理时采信 X-Forwarded-For/Proto/Host，
+// 将 r.RemoteAddr、r.Host 与 r.URL.Scheme 还原为原始客户端的值，供限流与日志按真实客户端区分。
+// 非可信来源的 X-Forwarded-* 头会被忽略，避免客户端伪造 IP 绕过限流。
+func forwardedHeadersMiddleware(trusted []*net.IPNet, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !trustedProxy(remoteIP(r.RemoteAddr), trusted) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		r = r.Clone(r.Context())
+		if client := forwardedClient(r, trusted); client != "" {
+			r.RemoteAddr = client
+		}
+		if proto := firstForwardedValue(r.Header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			r.URL.Scheme = proto
+		}
+		if host := firstForwardedValue(r.Header.Get("X-Forwarded-Host")); host != "" {
+			r.Host = host
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedClient 从 X-Forwarded-For 右侧开始跳过可信代理，返回第一个不可信地址；
+// 左侧的值可由客户端任意填写，不能直接使用。没有 X-Forwarded-For 时回退到 X-Real-IP。
+func forwardedClient(r *http.Request, trusted []*net.IPNet) string {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.Trim(hops[i], "[]"))
+		if ip == nil {
+			return ""
+		}
+		if i == 0 || !trustedProxy(ip, trusted) {
+			return ip.String()
+		}
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// firstForwardedValue 取多级代理追加的逗号分隔值中的第一个（最靠近客户端的一跳）
+func firstForwardedValue(value string) string {
+	first, _, _ := strings.Cut(value, ",")
+	return strings.ToLower(strings.TrimSpace(first))
+}
+
+// remoteIP 解析 RemoteAddr 中的 IP，兼容不带端口的地址
+func remoteIP(remoteAddr string) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return net.ParseIP(strings.Trim(host, "[]"))
+}
+
+func trustedProxy(ip net.IP, trusted []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedProxies 解析 mcp.http.trusted_proxies，无效项记录警告后跳过（配置校验会报告具体错误）
+func (s *Server) trustedProxies() []*net.IPNet {
+	if s.httpConfig == nil {
+		return nil
+	}
+	networks := make([]*net.IPNet, 0, len(s.httpConfig.TrustedProxies))
+	for _, value := range s.httpConfig.TrustedProxies {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		network, err := pkgconfig.ParseTrustedProxy(value)
+		if err != nil {
+			log.Warn().Str("proxy", value).Msg("ignoring invalid trusted proxy")
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// normalizeBasePath 规范化路由前缀：以 / 开头、不以 / 结尾，未配置时为空
+func normalizeBasePath(basePath string) string {
+	basePath = strings.Trim(strings.TrimSpace(basePath), "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// routePath 为 HTTP 端点加上配置的路由前缀
+func (s *Server) routePath(route string) string {
+	if s.httpConfig == nil {
+		return route
+	}
+	return EndpointPath(s.httpConfig.BasePath, route)
+}
+
+// EndpointPath 组合路由前缀与端点路径，用于启动信息展示，如 ("/taskbridge/", "/mcp") 返回 /taskbridge/mcp
+func EndpointPath(basePath, route string) string {
+	return normalizeBasePath(basePath) + route
+}
@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestForwardedHeadersMiddleware(t *testing.T) {
+	s := NewServer(WithHTTPConfig(&pkgconfig.HTTPConfig{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"}}))
+	var got *http.Request
+	handler, err := s.wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	serve := func(remoteAddr string, headers map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = remoteAddr
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return got
+	}
+
+	r := serve("10.1.2.3:4567", map[string]string{
+		"X-Forwarded-For":   "1.1.1.1, 203.0.113.7, 192.168.1.1",
+		"X-Forwarded-Proto": "https",
+		"X-Forwarded-Host":  "tasks.example.com",
+	})
+	if r.RemoteAddr != "203.0.113.7" {
+		t.Fatalf("expected rightmost untrusted hop, got %q", r.RemoteAddr)
+	}
+	if r.URL.Scheme != "https" || r.Host != "tasks.example.com" {
+		t.Fatalf("expected forwarded scheme/host, got %q %q", r.URL.Scheme, r.Host)
+	}
+
+	r = serve("10.1.2.3:4567", map[string]string{"X-Real-IP": "203.0.113.9"})
+	if r.RemoteAddr != "203.0.113.9" {
+		t.Fatalf("expected X-Real-IP fallback, got %q", r.RemoteAddr)
+	}
+
+	r = serve("203.0.113.50:1234", map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Forwarded-Host": "evil.example.com"})
+	if r.RemoteAddr != "203.0.113.50:1234" || r.Host == "evil.example.com" {
+		t.Fatalf("untrusted peer headers must be ignored, got %q %q", r.RemoteAddr, r.Host)
+	}
+}
+
+func TestRateLimitUsesForwardedClient(t *testing.T) {
+	s := NewServer(
+		WithHTTPConfig(&pkgconfig.HTTPConfig{TrustedProxies: []string{"127.0.0.1"}}),
+		WithRateLimitConfig(&pkgconfig.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.001, Burst: 1}),
+	)
+	handler, err := s.wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	serve := func(client string) int {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = "127.0.0.1:9000"
+		req.Header.Set("X-Forwarded-For", client)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := serve("203.0.113.1"); code != http.StatusOK {
+		t.Fatalf("first client request should pass, got %d", code)
+	}
+	if code := serve("203.0.113.2"); code != http.StatusOK {
+		t.Fatalf("clients behind the same proxy should be limited separately, got %d", code)
+	}
+	if code := serve("203.0.113.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 for repeated client, got %d", code)
+	}
+}
+
+func TestStreamableMuxBasePath(t *testing.T) {
+	s := NewServer(
+		WithConfig(&ServerConfig{Name: "taskbridge", Version: "test", Transport: "streamable"}),
+		WithHTTPConfig(&pkgconfig.HTTPConfig{BasePath: "/taskbridge/"}),
+	)
+	mux := s.streamableMux()
+	if _, pattern := mux.Handler(httptest.NewRequest(http.MethodPost, "/taskbridge/mcp", nil)); pattern != "/taskbridge/mcp" {
+		t.Fatalf("expected prefixed route, got %q", pattern)
+	}
+	if _, pattern := mux.Handler(httptest.NewRequest(http.MethodPost, "/mcp", nil)); pattern != "" {
+		t.Fatalf("root route should not be mounted, got %q", pattern)
+	}
+	if got := EndpointPath("", "/mcp"); got != "/mcp" {
+		t.Fatalf("expected /mcp without base path, got %q", got)
+	}
+}
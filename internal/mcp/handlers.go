@@ -34,7 +34,6 @@ func (s *Server) handleListTasks(ctx context.Context, req *mcp.CallToolRequest)
 	detail := "compact"
 	includeMeta := false
 
-	query := storage.Query{}
 	appliedFilters := map[string]interface{}{}
 
 	var rawArgs map[string]json.RawMessage
@@ -44,92 +43,60 @@ func (s *Server) handleListTasks(ctx context.Context, req *mcp.CallToolRequest)
 		}
 	}
 
-	if source := getString(rawArgs, "source"); source != "" {
-		resolvedSource, err := resolveProviderNameStrict(source)
-		if err != nil {
-			return nil, err
-		}
-		query.Sources = []model.TaskSource{model.TaskSource(resolvedSource)}
-		appliedFilters["source"] = resolvedSource
-	}
-
-	if values := getStringSlice(rawArgs, "list_id"); len(values) > 0 {
-		query.ListIDs = values
-		appliedFilters["list_id"] = values
+	query, projectIDs, err := s.parseTaskQueryArgs(ctx, rawArgs, appliedFilters)
+	if err != nil {
+		return nil, err
 	}
 
-	if values := getStringSlice(rawArgs, "list_name"); len(values) > 0 {
-		query.ListNames = values
-		appliedFilters["list_name"] = values
+	limit := 0
+	offset := 0
+	if value, ok := getInt(rawArgs, "limit"); ok {
+		limit = value
+		appliedFilters["limit"] = value
 	}
-
-	if values := getStringSlice(rawArgs, "task_id"); len(values) > 0 {
-		query.TaskIDs = values
-		appliedFilters["task_id"] = values
+	if value, ok := getInt(rawArgs, "offset"); ok {
+		offset = value
+		appliedFilters["offset"] = value
 	}
 
-	if values := getStringSlice(rawArgs, "status"); len(values) > 0 {
-		query.Statuses = make([]model.TaskStatus, 0, len(values))
-		for _, value := range values {
-			query.Statuses = append(query.Statuses, model.TaskStatus(value))
-		}
-		appliedFilters["status"] = values
+	// sort 优先于旧的 order_by/order_desc
+	sortKeys, err := parseTaskSort(getString(rawArgs, "sort"))
+	if err != nil {
+		return nil, err
 	}
-
-	if values := getIntSlice(rawArgs, "quadrant"); len(values) > 0 {
-		query.Quadrants = make([]model.Quadrant, 0, len(values))
-		for _, value := range values {
-			query.Quadrants = append(query.Quadrants, model.Quadrant(value))
+	if len(sortKeys) == 0 {
+		if value := getString(rawArgs, "order_by"); value != "" {
+			desc, _ := getBool(rawArgs, "order_desc")
+			if sortKeys, err = parseTaskSort(value); err != nil {
+				return nil, err
+			}
+			for i := range sortKeys {
+				sortKeys[i].Desc = desc
+			}
+			appliedFilters["order_by"] = value
+			appliedFilters["order_desc"] = desc
 		}
-		appliedFilters["quadrant"] = values
 	}
-
-	if values := getIntSlice(rawArgs, "priority"); len(values) > 0 {
-		query.Priorities = make([]model.Priority, 0, len(values))
-		for _, value := range values {
-			query.Priorities = append(query.Priorities, model.Priority(value))
-		}
-		appliedFilters["priority"] = values
+	if len(sortKeys) == 0 {
+		sortKeys = []taskSortKey{{Field: "updated_at", Desc: true}}
 	}
+	sortSpec := formatTaskSort(sortKeys)
+	appliedFilters["sort"] = sortSpec
 
-	if values := getStringSlice(rawArgs, "tag"); len(values) > 0 {
-		query.Tags = values
-		appliedFilters["tag"] = values
+	_, useCursor := rawArgs["cursor"]
+	// cursor 分页未指定 limit 时使用默认页大小，避免一次返回全部任务
+	if useCursor && limit <= 0 {
+		limit = defaultTaskPageSize
 	}
-
-	if v := getString(rawArgs, "due_before"); v != "" {
-		if dueBefore, err := time.Parse("2006-01-02", v); err == nil {
-			query.DueBefore = &dueBefore
-			appliedFilters["due_before"] = v
+	if cursorValue := strings.TrimSpace(getString(rawArgs, "cursor")); cursorValue != "" {
+		cursor, err := decodeListTasksCursor(cursorValue)
+		if err != nil {
+			return nil, err
 		}
-	}
-	if v := getString(rawArgs, "due_after"); v != "" {
-		if dueAfter, err := time.Parse("2006-01-02", v); err == nil {
-			query.DueAfter = &dueAfter
-			appliedFilters["due_after"] = v
+		if cursor.Sort != sortSpec {
+			return nil, fmt.Errorf("cursor does not match sort %q", sortSpec)
 		}
-	}
-
-	if value := getString(rawArgs, "query"); value != "" {
-		query.QueryText = value
-		appliedFilters["query"] = value
-	}
-
-	if value, ok := getInt(rawArgs, "limit"); ok {
-		query.Limit = value
-		appliedFilters["limit"] = value
-	}
-	if value, ok := getInt(rawArgs, "offset"); ok {
-		query.Offset = value
-		appliedFilters["offset"] = value
-	}
-	if value := getString(rawArgs, "order_by"); value != "" {
-		query.OrderBy = value
-		appliedFilters["order_by"] = value
-	}
-	if value, ok := getBool(rawArgs, "order_desc"); ok {
-		query.OrderDesc = value
-		appliedFilters["order_desc"] = value
+		offset = cursor.Offset
 	}
 
 	if value := strings.TrimSpace(getString(rawArgs, "detail")); value != "" {
@@ -139,10 +106,26 @@ func (s *Server) handleListTasks(ctx context.Context, req *mcp.CallToolRequest)
 		includeMeta = value
 	}
 
-	tasks, err := s.taskStore.QueryTasks(ctx, query)
+	matched, err := s.taskStore.QueryTasks(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tasks: %w", err)
 	}
+	matched = filterTasksByProject(matched, projectIDs)
+	sortTasksByKeys(matched, sortKeys)
+
+	total := len(matched)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	tasks := matched[offset:end]
+	nextCursor := ""
+	if end < total {
+		nextCursor = encodeListTasksCursor(listTasksCursor{Offset: end, Sort: sortSpec})
+	}
 
 	var payload interface{}
 	if detail == "full" {
@@ -151,28 +134,23 @@ func (s *Server) handleListTasks(ctx context.Context, req *mcp.CallToolRequest)
 		payload = toCompactTasks(tasks)
 	}
 
-	if includeMeta {
-		total := len(tasks)
-		if query.Limit > 0 || query.Offset > 0 {
-			countQuery := query
-			countQuery.Limit = 0
-			countQuery.Offset = 0
-			countQuery.OrderBy = ""
-			countQuery.OrderDesc = false
-			allMatched, err := s.taskStore.QueryTasks(ctx, countQuery)
-			if err == nil {
-				total = len(allMatched)
-			}
+	// 使用 cursor 分页时总是返回 meta，以便拿到 next_cursor
+	if includeMeta || useCursor {
+		meta := map[string]interface{}{
+			"returned":        len(tasks),
+			"total":           total,
+			"detail":          detail,
+			"applied_filters": appliedFilters,
+		}
+		if nextCursor != "" {
+			meta["next_cursor"] = nextCursor
+		}
+		if freshness := s.taskFreshnessSnapshot(ctx); len(freshness) > 0 {
+			meta["freshness"] = freshness
 		}
-
 		payload = map[string]interface{}{
 			"tasks": payload,
-			"meta": map[string]interface{}{
-				"returned":        len(tasks),
-				"total":           total,
-				"detail":          detail,
-				"applied_filters": appliedFilters,
-			},
+			"meta":  meta,
 		}
 	}
 
@@ -270,6 +248,208 @@ func (s *Server) handleListTaskLists(ctx context.Context, req *mcp.CallToolReque
 	}, nil
 }
 
+// handleGetTask 获取单个任务详情：默认返回本地缓存；refresh 为 true 或本地没有缓存时，
+// 根据任务 ID 或 source 参数定位 provider 拉取远端最新数据。远端数据只用于本次返回，不写回本地缓存，
+// 避免覆盖尚未同步的本地修改
+func (s *Server) handleGetTask(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	taskID := strings.TrimSpace(getString(rawArgs, "id"))
+	if taskID == "" {
+		taskID = strings.TrimSpace(getString(rawArgs, "task_id"))
+	}
+	if taskID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	source, err := resolveProviderNameStrict(getString(rawArgs, "source"))
+	if err != nil {
+		return nil, err
+	}
+	refresh, _ := getBool(rawArgs, "refresh")
+
+	local := s.findLocalTask(ctx, taskID, source)
+	if local == nil {
+		refresh = true
+	}
+	if source == "" {
+		if local != nil {
+			source = string(local.Source)
+		} else {
+			source = providerFromTaskID(taskID)
+		}
+	}
+
+	payload := map[string]interface{}{
+		"source":       source,
+		"fetched_from": "local",
+	}
+	task := local
+	// 有排队的离线写入时本地版本较新，不用远端版本覆盖
+	if local != nil && s.writeQueue != nil {
+		if queued := s.writeQueue.forTask(local.ID); len(queued) > 0 {
+			refresh = false
+			payload["queued_writes"] = len(queued)
+		}
+	}
+	if refresh && source != "" && source != string(model.SourceLocal) {
+		remote, remoteErr := s.getRemoteTask(ctx, source, taskID, getString(rawArgs, "list_id"), local)
+		switch {
+		case remoteErr == nil:
+			task = remote
+			payload["fetched_from"] = "remote"
+		case local != nil:
+			payload["remote_error"] = remoteErr.Error()
+		default:
+			return nil, fmt.Errorf("task not found: %w", remoteErr)
+		}
+	}
+	if task == nil {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+	if task.Source == "" && source != "" {
+		task.Source = model.TaskSource(source)
+	}
+	if source == "" {
+		payload["source"] = string(task.Source)
+	}
+	payload["task"] = task
+
+	jsonResult, err := toJSON(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: jsonResult},
+			&mcp.TextContent{Text: formatTaskDetail(task)},
+		},
+		StructuredContent: payload,
+	}, nil
+}
+
+// findLocalTask 按本地 ID 查找任务，找不到时再按远端原始 ID 匹配。
+func (s *Server) findLocalTask(ctx context.Context, taskID, source string) *model.Task {
+	if s.taskStore == nil {
+		return nil
+	}
+	if task, err := s.taskStore.GetTask(ctx, taskID); err == nil && task != nil {
+		return task
+	}
+	query := storage.Query{}
+	if source != "" {
+		query.Sources = []model.TaskSource{model.TaskSource(source)}
+	}
+	tasks, err := s.taskStore.QueryTasks(ctx, query)
+	if err != nil {
+		return nil
+	}
+	for i := range tasks {
+		if tasks[i].SourceRawID == taskID {
+			return &tasks[i]
+		}
+	}
+	return nil
+}
+
+// getRemoteTask 从 provider 获取任务最新数据，不写入本地缓存。
+func (s *Server) getRemoteTask(ctx context.Context, source, taskID, listID string, local *model.Task) (*model.Task, error) {
+	p, ok := s.lookupProvider(ctx, source)
+	if !ok || p == nil {
+		return nil, fmt.Errorf("provider %s not found or not authenticated", source)
+	}
+	if !p.IsAuthenticated() {
+		return nil, fmt.Errorf("provider %s not authenticated", source)
+	}
+
+	rawID := taskID
+	if local != nil {
+		if local.SourceRawID != "" {
+			rawID = local.SourceRawID
+		}
+		if listID == "" {
+			listID = local.ListID
+		}
+	}
+	remote, err := p.GetTask(ctx, listID, rawID)
+	if err != nil {
+		return nil, err
+	}
+	if remote == nil {
+		return nil, fmt.Errorf("provider %s returned no task for %s", source, rawID)
+	}
+	if remote.Source == "" {
+		remote.Source = model.TaskSource(source)
+	}
+	if remote.SourceRawID == "" {
+		remote.SourceRawID = rawID
+	}
+	if remote.ListID == "" {
+		remote.ListID = listID
+	}
+	if local != nil {
+		remote.ID = local.ID
+		if remote.ListName == "" {
+			remote.ListName = local.ListName
+		}
+		if remote.Metadata == nil {
+			remote.Metadata = local.Metadata
+		}
+	}
+	return remote, nil
+}
+
+// providerFromTaskID 从 "provider-listID-rawID" 形式的任务 ID 推断 provider。
+func providerFromTaskID(taskID string) string {
+	prefix, _, ok := strings.Cut(taskID, "-")
+	if !ok {
+		return ""
+	}
+	resolved := provider.ResolveProviderName(prefix)
+	if !provider.IsValidProvider(resolved) {
+		return ""
+	}
+	return resolved
+}
+
+// formatTaskDetail 生成便于阅读的任务详情文本
+func formatTaskDetail(task *model.Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", task.Priority.Emoji(), task.Title)
+	fmt.Fprintf(&b, "ID: %s\n", task.ID)
+	fmt.Fprintf(&b, "状态: %s\n", task.Status)
+	if task.Source != "" {
+		location := string(task.Source)
+		if task.ListName != "" {
+			location += " / " + task.ListName
+		} else if task.ListID != "" {
+			location += " / " + task.ListID
+		}
+		fmt.Fprintf(&b, "来源: %s\n", location)
+	}
+	if task.DueDate != nil {
+		fmt.Fprintf(&b, "截止: %s\n", task.DueDate.Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintf(&b, "优先级: %s，象限: %s\n", task.Priority.String(), task.Quadrant.String())
+	if task.Progress > 0 {
+		fmt.Fprintf(&b, "进度: %d%%\n", task.Progress)
+	}
+	if len(task.Tags) > 0 {
+		fmt.Fprintf(&b, "标签: %s\n", strings.Join(task.Tags, ", "))
+	}
+	if task.ParentID != nil && *task.ParentID != "" {
+		fmt.Fprintf(&b, "父任务: %s\n", *task.ParentID)
+	}
+	if desc := strings.TrimSpace(task.Description); desc != "" {
+		fmt.Fprintf(&b, "\n%s\n", desc)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
 // handleCreateTask 处理创建任务请求
 func (s *Server) handleCreateTask(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if s.taskStore == nil {
@@ -341,34 +521,19 @@ func (s *Server) handleCreateTask(ctx context.Context, req *mcp.CallToolRequest)
 		return nil, fmt.Errorf("failed to save task: %w", err)
 	}
 
-	// 尝试自动同步到 Google Tasks
-	if googleProvider, ok := s.providers["google"]; ok && googleProvider.IsAuthenticated() {
+	// 尝试自动同步到 Google Tasks；Google 不可达时加入离线队列，恢复后创建
+	if googleProvider, ok := s.lookupProvider(ctx, "google"); ok && googleProvider.IsAuthenticated() {
 		// 获取默认任务列表
-		taskLists, err := googleProvider.ListTaskLists(ctx)
-		if err == nil && len(taskLists) > 0 {
-			// 查找默认列表
-			var defaultListID string
-			for _, list := range taskLists {
-				if list.Name == "我的任务" || list.Name == "My Tasks" || list.ID == "@default" {
-					defaultListID = list.ID
-					break
-				}
-			}
-			if defaultListID == "" {
-				defaultListID = taskLists[0].ID
-			}
-
+		defaultListID, err := defaultRemoteListID(ctx, googleProvider)
+		if err == nil {
 			// 推送任务到 Google Tasks
 			taskToSync := *task
-			if taskToSync.ParentID != nil && strings.TrimSpace(*taskToSync.ParentID) != "" {
-				// 若 parent_id 是本地任务 ID，则尝试解析其 Google 远端 ID。
-				if parentTask, err := s.taskStore.GetTask(ctx, strings.TrimSpace(*taskToSync.ParentID)); err == nil && parentTask != nil && strings.TrimSpace(parentTask.SourceRawID) != "" {
-					parentRemote := strings.TrimSpace(parentTask.SourceRawID)
-					taskToSync.ParentID = &parentRemote
-				}
+			if parentRemote := s.remoteParentID(ctx, task); parentRemote != "" {
+				taskToSync.ParentID = &parentRemote
 			}
 			taskToSync = sanitizeTaskForRemote(taskToSync)
-			createdTask, err := googleProvider.CreateTask(ctx, defaultListID, &taskToSync)
+			var createdTask *model.Task
+			createdTask, err = googleProvider.CreateTask(ctx, defaultListID, &taskToSync)
 			if err == nil {
 				// 更新本地任务信息
 				task.SourceRawID = createdTask.SourceRawID
@@ -380,6 +545,9 @@ func (s *Server) handleCreateTask(ctx context.Context, req *mcp.CallToolRequest)
 				_ = s.taskStore.SaveTask(ctx, task)
 			}
 		}
+		if providerUnreachable(err) && s.queueWrite(ctx, task, string(model.SourceGoogle), queuedCreate, "") {
+			s.writeQueue.markOffline(string(model.SourceGoogle), time.Now())
+		}
 	}
 
 	result, _ := toJSON(task)
@@ -388,7 +556,36 @@ func (s *Server) handleCreateTask(ctx context.Context, req *mcp.CallToolRequest)
 	}, nil
 }
 
-// handleUpdateTask 处理更新任务请求
+// defaultRemoteListID 返回 provider 的默认任务列表：优先“我的任务”/My Tasks/@default，否则取第一个列表
+func defaultRemoteListID(ctx context.Context, p provider.Provider) (string, error) {
+	taskLists, err := p.ListTaskLists(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(taskLists) == 0 {
+		return "", fmt.Errorf("provider %s has no task lists", p.Name())
+	}
+	for _, list := range taskLists {
+		if list.Name == "我的任务" || list.Name == "My Tasks" || list.ID == "@default" {
+			return list.ID, nil
+		}
+	}
+	return taskLists[0].ID, nil
+}
+
+// remoteParentID 若 parent_id 是本地任务 ID，返回父任务的远端 ID；无法解析时返回空
+func (s *Server) remoteParentID(ctx context.Context, task *model.Task) string {
+	if task.ParentID == nil || strings.TrimSpace(*task.ParentID) == "" {
+		return ""
+	}
+	parentTask, err := s.taskStore.GetTask(ctx, strings.TrimSpace(*task.ParentID))
+	if err != nil || parentTask == nil {
+		return ""
+	}
+	return strings.TrimSpace(parentTask.SourceRawID)
+}
+
+// handleUpdateTask 处理更新任务请求，来自 provider 的任务同时回写远端
 func (s *Server) handleUpdateTask(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if s.taskStore == nil {
 		return nil, fmt.Errorf("task storage not available")
@@ -431,11 +628,26 @@ func (s *Server) handleUpdateTask(ctx context.Context, req *mcp.CallToolRequest)
 	if err := s.taskStore.SaveTask(ctx, task); err != nil {
 		return nil, fmt.Errorf("failed to save task: %w", err)
 	}
+	op := queuedUpdate
+	if task.Status == model.StatusCompleted {
+		op = queuedComplete
+	}
+	remote, note := s.pushTaskWrite(ctx, task, op)
 
+	return taskWriteResult(task, remote, note), nil
+}
+
+// taskWriteResult 返回写入后的任务；回写远端排队或失败时附加说明
+func taskWriteResult(task *model.Task, remote, note string) *mcp.CallToolResult {
 	result, _ := toJSON(task)
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: result}},
-	}, nil
+	content := []mcp.Content{&mcp.TextContent{Text: result}}
+	switch remote {
+	case "queued":
+		content = append(content, &mcp.TextContent{Text: fmt.Sprintf("%s 暂时无法连接，修改已保存到本地并加入离线队列，恢复连接后自动回写（%s）", task.Source, note)})
+	case "failed":
+		content = append(content, &mcp.TextContent{Text: fmt.Sprintf("修改已保存到本地，但回写 %s 失败: %s", task.Source, note)})
+	}
+	return &mcp.CallToolResult{Content: content}
 }
 
 // handleDeleteTask 处理删除任务请求
@@ -456,6 +668,22 @@ func (s *Server) handleDeleteTask(ctx context.Context, req *mcp.CallToolRequest)
 		return nil, fmt.Errorf("id is required")
 	}
 
+	// 客户端支持 elicitation 时先请求用户确认
+	if elicitationSession(req) != nil {
+		task, err := s.taskStore.GetTask(ctx, params.ID)
+		if err != nil {
+			return nil, fmt.Errorf("task not found: %w", err)
+		}
+		summary := s.deleteTaskSummary(ctx, task)
+		action, err := confirmDestructive(ctx, req, summary)
+		if err != nil {
+			return nil, err
+		}
+		if action != "accept" {
+			return unconfirmedResult(action, summary)
+		}
+	}
+
 	// 删除任务
 	if err := s.taskStore.DeleteTask(ctx, params.ID); err != nil {
 		return nil, fmt.Errorf("failed to delete task: %w", err)
@@ -466,7 +694,7 @@ func (s *Server) handleDeleteTask(ctx context.Context, req *mcp.CallToolRequest)
 	}, nil
 }
 
-// handleCompleteTask 处理完成任务请求
+// handleCompleteTask 处理完成任务请求，来自 provider 的任务同时回写远端
 func (s *Server) handleCompleteTask(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	if s.taskStore == nil {
 		return nil, fmt.Errorf("task storage not available")
@@ -500,11 +728,9 @@ func (s *Server) handleCompleteTask(ctx context.Context, req *mcp.CallToolReques
 	if err := s.taskStore.SaveTask(ctx, task); err != nil {
 		return nil, fmt.Errorf("failed to save task: %w", err)
 	}
+	remote, note := s.pushTaskWrite(ctx, task, queuedComplete)
 
-	result, _ := toJSON(task)
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: result}},
-	}, nil
+	return taskWriteResult(task, remote, note), nil
 }
 
 // ================ 分析工具处理器 ================
@@ -995,10 +1221,12 @@ func (s *Server) handleSyncProject(ctx context.Context, req *mcp.CallToolRequest
 		return nil, err
 	}
 
-	p, ok := s.providers[resolvedProvider]
+	p, ok := s.lookupProvider(ctx, resolvedProvider)
 	if !ok {
 		return nil, fmt.Errorf("provider %s not found or not authenticated", resolvedProvider)
 	}
+	// 同步需要远端的最新状态，不使用缓存的读取结果
+	s.invalidateAdapterReads(resolvedProvider)
 
 	localTasks, err := s.taskStore.ListTasks(ctx, storage.ListOptions{})
 	if err != nil {
@@ -1027,26 +1255,9 @@ func (s *Server) handleSyncProject(ctx context.Context, req *mcp.CallToolRequest
 		}, nil
 	}
 
-	taskLists, err := p.ListTaskLists(ctx)
+	resultPayload, err := s.pushTaskTreeToProvider(ctx, p, resolvedProvider, projectTasks, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to list remote task lists: %w", err)
-	}
-	defaultListID := s.findDefaultListID(taskLists)
-
-	resultPayload := &SyncPushResult{Provider: resolvedProvider}
-	targetSource := model.TaskSource(resolvedProvider)
-	if targetSource == model.SourceGoogle {
-		planTaskToLocalID := buildPlanTaskToLocalIDMap(projectTasks)
-		parentTasks, childTasks := splitTasksByParentRelation(projectTasks, planTaskToLocalID)
-		s.pushLocalTasks(ctx, p, parentTasks, defaultListID, targetSource, false, resultPayload)
-		if len(childTasks) > 0 {
-			if err := s.pullProviderTasksIntoLocal(ctx, p, resolvedProvider); err != nil {
-				resultPayload.Errors = append(resultPayload.Errors, fmt.Sprintf("pull-before-children: %v", err))
-			}
-			s.pushLocalTasks(ctx, p, childTasks, defaultListID, targetSource, false, resultPayload)
-		}
-	} else {
-		s.pushLocalTasks(ctx, p, projectTasks, defaultListID, targetSource, false, resultPayload)
+		return nil, err
 	}
 
 	item, err := s.projectStore.GetProject(ctx, params.ProjectID)
@@ -1071,6 +1282,39 @@ func (s *Server) handleSyncProject(ctx context.Context, req *mcp.CallToolRequest
 	}, nil
 }
 
+// pushTaskTreeToProvider 将一组带父子关系的本地任务推送到 provider。
+// listID 为空时使用 provider 的默认清单；Google Tasks 需先推父任务再推子任务。
+func (s *Server) pushTaskTreeToProvider(ctx context.Context, p provider.Provider, providerName string, tasks []model.Task, listID string) (*SyncPushResult, error) {
+	defaultListID := strings.TrimSpace(listID)
+	if defaultListID == "" {
+		taskLists, err := p.ListTaskLists(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list remote task lists: %w", err)
+		}
+		defaultListID = s.findDefaultListID(taskLists)
+	}
+
+	resultPayload := &SyncPushResult{Provider: providerName}
+	targetSource := model.TaskSource(providerName)
+	if targetSource == model.SourceGoogle {
+		planTaskToLocalID := buildPlanTaskToLocalIDMap(tasks)
+		parentTasks, childTasks := splitTasksByParentRelation(tasks, planTaskToLocalID)
+		s.pushLocalTasks(ctx, p, parentTasks, defaultListID, targetSource, false, resultPayload)
+		if len(childTasks) > 0 {
+			if err := s.pullProviderTasksIntoLocal(ctx, p, providerName); err != nil {
+				resultPayload.Errors = append(resultPayload.Errors, fmt.Sprintf("pull-before-children: %v", err))
+			}
+			s.pushLocalTasks(ctx, p, childTasks, defaultListID, targetSource, false, resultPayload)
+		}
+	} else {
+		s.pushLocalTasks(ctx, p, tasks, defaultListID, targetSource, false, resultPayload)
+	}
+	if err := ctx.Err(); err != nil {
+		return resultPayload, err
+	}
+	return resultPayload, nil
+}
+
 // ================ 提示词工具处理器 ================
 
 // handleGetPrompt 处理获取提示词请求
@@ -1284,10 +1528,12 @@ func (s *Server) handleSyncPush(ctx context.Context, req *mcp.CallToolRequest) (
 	targetSource := model.TaskSource(resolvedProvider)
 
 	// 检查 Provider 是否存在
-	p, ok := s.providers[resolvedProvider]
+	p, ok := s.lookupProvider(ctx, resolvedProvider)
 	if !ok {
 		return nil, fmt.Errorf("provider %s not found or not authenticated", resolvedProvider)
 	}
+	// 同步需要远端的最新状态，不使用缓存的读取结果
+	s.invalidateAdapterReads(resolvedProvider)
 
 	// 获取本地任务
 	localTasks, err := s.taskStore.ListTasks(ctx, storage.ListOptions{})
@@ -1308,6 +1554,24 @@ func (s *Server) handleSyncPush(ctx context.Context, req *mcp.CallToolRequest) (
 		return nil, fmt.Errorf("failed to list remote task lists: %w", err)
 	}
 
+	// 删除远程任务前请求用户确认，拒绝时整个推送不执行
+	if params.DeleteRemote && !params.DryRun && elicitationSession(req) != nil {
+		candidates, err := remoteDeletionCandidates(ctx, p, taskLists, localSourceRawIDs)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) > 0 {
+			summary := remoteDeletionSummary(resolvedProvider, candidates)
+			action, err := confirmDestructive(ctx, req, summary)
+			if err != nil {
+				return nil, err
+			}
+			if action != "accept" {
+				return unconfirmedResult(action, summary)
+			}
+		}
+	}
+
 	// 查找默认列表
 	defaultListID := s.findDefaultListID(taskLists)
 
@@ -1329,9 +1593,13 @@ func (s *Server) handleSyncPush(ctx context.Context, req *mcp.CallToolRequest) (
 	}
 
 	// 删除远程多余任务
-	if params.DeleteRemote {
+	if params.DeleteRemote && ctx.Err() == nil {
 		s.deleteRemoteTasks(ctx, p, taskLists, localSourceRawIDs, params.DryRun, result)
 	}
+	// 客户端取消（notifications/cancelled）后 ctx 被取消，已停止继续调用 provider
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	if params.DryRun {
 		result.DryRun = true
@@ -1377,6 +1645,9 @@ func (s *Server) pushLocalTasks(ctx context.Context, p provider.Provider, tasks
 	ordered := orderTasksByParent(tasks, planTaskToLocalID)
 	remoteByLocalID := make(map[string]string, len(ordered))
 	for _, task := range ordered {
+		if ctx.Err() != nil {
+			return
+		}
 		// 只同步本地任务或目标 provider 的任务，避免跨 provider 推送污染。
 		if task.Source != "" && task.Source != source && task.Source != model.SourceLocal {
 			continue
@@ -1458,7 +1729,7 @@ func (s *Server) pushLocalTasks(ctx context.Context, p provider.Provider, tasks
 }
 
 func (s *Server) syncMicrosoftChecklistStep(ctx context.Context, p provider.Provider, listID, parentRemoteID string, task *model.Task, dryRun bool, result *SyncPushResult) error {
-	msProvider, ok := p.(*msprovider.Provider)
+	msProvider, ok := unwrapProvider(p).(*msprovider.Provider)
 	if !ok {
 		return fmt.Errorf("provider is not microsoft")
 	}
@@ -1746,12 +2017,18 @@ func buildMicrosoftStepLocalID(parentRemoteID, stepID string) string {
 // deleteRemoteTasks 删除远程多余任务
 func (s *Server) deleteRemoteTasks(ctx context.Context, p provider.Provider, taskLists []model.TaskList, localSourceRawIDs map[string]bool, dryRun bool, result *SyncPushResult) {
 	for _, list := range taskLists {
+		if ctx.Err() != nil {
+			return
+		}
 		remoteTasks, err := p.ListTasks(ctx, list.ID, provider.ListOptions{})
 		if err != nil {
 			continue
 		}
 
 		for _, remoteTask := range remoteTasks {
+			if ctx.Err() != nil {
+				return
+			}
 			if !localSourceRawIDs[remoteTask.SourceRawID] {
 				if !dryRun {
 					err := p.DeleteTask(ctx, list.ID, remoteTask.SourceRawID)
@@ -1785,10 +2062,12 @@ func (s *Server) handleSyncPull(ctx context.Context, req *mcp.CallToolRequest) (
 	}
 
 	// 检查 Provider 是否存在
-	p, ok := s.providers[resolvedProvider]
+	p, ok := s.lookupProvider(ctx, resolvedProvider)
 	if !ok {
 		return nil, fmt.Errorf("provider %s not found or not authenticated", resolvedProvider)
 	}
+	// 同步需要远端的最新状态，不使用缓存的读取结果
+	s.invalidateAdapterReads(resolvedProvider)
 
 	result := map[string]interface{}{
 		"provider": resolvedProvider,
@@ -1804,6 +2083,9 @@ func (s *Server) handleSyncPull(ctx context.Context, req *mcp.CallToolRequest) (
 
 	// 从每个列表拉取任务
 	for _, list := range taskLists {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		_ = s.taskStore.SaveTaskList(ctx, &list)
 
 		tasks, err := p.ListTasks(ctx, list.ID, provider.ListOptions{})
@@ -1860,6 +2142,23 @@ type ServerInfo struct {
 	Tools        []string            `json:"tools"`
 	Prompts      []string            `json:"prompts"`
 	Resources    []string            `json:"resources"`
+	Upstreams    []upstreamInfo      `json:"upstreams,omitempty"`
+}
+
+// toolCapabilityGroups 按能力分组的工具，用于 get_server_info 与服务说明
+var toolCapabilityGroups = map[string][]string{
+	"task_management":    {"list_tasks", "list_task_lists", "get_task", "create_task", "update_task", "delete_task", "complete_task", "overdue_tasks", "snooze_task", "reorder_tasks", "add_blocker", "list_blockers", "ready_tasks", "plan_day", "quick_add", "create_tasks_from_markdown", "task_history", "semantic_search"},
+	"analysis":           {"analyze_quadrant", "analyze_priority", "analyze_overdue_health", "analyze_achievement", "detect_decomposition_candidates", "task_statistics", "summarize_tasks"},
+	"intelligence":       {"analyze_overdue_health", "resolve_overdue_tasks", "rebalance_longterm_tasks", "detect_decomposition_candidates", "decompose_task_with_provider", "analyze_achievement", "find_duplicates", "merge_tasks", "breakdown_task"},
+	"project_management": {"create_project", "list_projects", "split_project", "split_project_from_markdown", "confirm_project", "sync_project"},
+	"time_tracking":      {"start_timer", "stop_timer", "log_time", "time_report"},
+	"templates":          {"save_template", "list_templates", "instantiate_template"},
+	"import_export":      {"export_tasks", "import_tasks"},
+	"sync":               {"sync_pull", "sync_push", "sync_now", "sync_status", "resolve_conflict"},
+	"provider":           {"list_providers", "get_provider_info", "get_provider_config_template"},
+	"prompt":             {"get_prompt"},
+	"server_meta":        {"get_server_info"},
+	"session":            {"set_context", "get_context"},
 }
 
 // handleGetServerInfo 返回 MCP 版本和能力信息，供 AI 识别当前功能范围
@@ -1881,23 +2180,32 @@ func (s *Server) handleGetServerInfo(ctx context.Context, req *mcp.CallToolReque
 	}
 	sort.Strings(prompts)
 
+	// 能力分组只列出当前已注册的工具
+	capabilities := make(map[string][]string, len(toolCapabilityGroups))
+	for group, names := range toolCapabilityGroups {
+		available := make([]string, 0, len(names))
+		for _, name := range names {
+			if toolsMap[name] {
+				available = append(available, name)
+			}
+		}
+		capabilities[group] = available
+	}
+
+	resources := []string{}
+	if s.resourcesEnabled() {
+		resources = []string{"taskbridge://tasks", "taskbridge://projects", "taskbridge://prompts", statusResourceURI}
+	}
+
 	info := ServerInfo{
-		Name:      s.config.Name,
-		Version:   s.config.Version,
-		Transport: s.config.Transport,
-		Capabilities: map[string][]string{
-			"task_management":    {"list_tasks", "list_task_lists", "create_task", "update_task", "delete_task", "complete_task"},
-			"analysis":           {"analyze_quadrant", "analyze_priority", "analyze_overdue_health", "analyze_achievement", "detect_decomposition_candidates"},
-			"intelligence":       {"analyze_overdue_health", "resolve_overdue_tasks", "rebalance_longterm_tasks", "detect_decomposition_candidates", "decompose_task_with_provider", "analyze_achievement"},
-			"project_management": {"create_project", "list_projects", "split_project", "split_project_from_markdown", "confirm_project", "sync_project"},
-			"sync":               {"sync_pull", "sync_push"},
-			"provider":           {"list_providers", "get_provider_info", "get_provider_config_template"},
-			"prompt":             {"get_prompt"},
-			"server_meta":        {"get_server_info"},
-		},
-		Tools:     tools,
-		Prompts:   prompts,
-		Resources: []string{"taskbridge://tasks", "taskbridge://projects", "taskbridge://prompts"},
+		Name:         s.config.Name,
+		Version:      s.config.Version,
+		Transport:    s.config.Transport,
+		Capabilities: capabilities,
+		Tools:        tools,
+		Prompts:      prompts,
+		Resources:    resources,
+		Upstreams:    s.upstreamSnapshot(),
 	}
 
 	result, _ := toJSON(info)
@@ -1909,21 +2217,7 @@ func (s *Server) handleGetServerInfo(ctx context.Context, req *mcp.CallToolReque
 // handleListProviders 处理列出 Providers 请求
 func (s *Server) handleListProviders(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// 从配置读取启用状态
-	googleEnabled := false
-	microsoftEnabled := false
-	feishuEnabled := false
-	ticktickEnabled := false
-	didaEnabled := false
-	todoistEnabled := false
-
-	if s.providerConfig != nil {
-		googleEnabled = s.providerConfig.Google.Enabled
-		microsoftEnabled = s.providerConfig.Microsoft.Enabled
-		feishuEnabled = s.providerConfig.Feishu.Enabled
-		ticktickEnabled = s.providerConfig.TickTick.Enabled
-		didaEnabled = s.providerConfig.Dida.Enabled
-		todoistEnabled = s.providerConfig.Todoist.Enabled
-	}
+	enabled := s.adapterConfig.Enabled
 
 	providers := []ProviderInfo{
 		{
@@ -1932,7 +2226,7 @@ func (s *Server) handleListProviders(ctx context.Context, req *mcp.CallToolReque
 			DisplayName:  "Google Tasks",
 			Description:  "Google 任务管理服务",
 			AuthType:     "OAuth2",
-			Enabled:      googleEnabled,
+			Enabled:      enabled("google"),
 			Capabilities: []string{"due_date", "task_lists", "subtasks_limited"},
 		},
 		{
@@ -1941,7 +2235,7 @@ func (s *Server) handleListProviders(ctx context.Context, req *mcp.CallToolReque
 			DisplayName:  "Microsoft To Do",
 			Description:  "微软任务管理服务",
 			AuthType:     "OAuth2",
-			Enabled:      microsoftEnabled,
+			Enabled:      enabled("microsoft"),
 			Capabilities: []string{"due_date", "task_lists", "subtasks", "priority", "reminder"},
 		},
 		{
@@ -1950,7 +2244,7 @@ func (s *Server) handleListProviders(ctx context.Context, req *mcp.CallToolReque
 			DisplayName:  "飞书任务",
 			Description:  "飞书任务管理",
 			AuthType:     "App ID/Secret",
-			Enabled:      feishuEnabled,
+			Enabled:      enabled("feishu"),
 			Capabilities: []string{"due_date", "task_lists", "priority", "tags"},
 		},
 		{
@@ -1959,7 +2253,7 @@ func (s *Server) handleListProviders(ctx context.Context, req *mcp.CallToolReque
 			DisplayName:  "TickTick",
 			Description:  "TickTick 任务管理",
 			AuthType:     "API Token",
-			Enabled:      ticktickEnabled,
+			Enabled:      enabled("ticktick"),
 			Capabilities: []string{"due_date", "task_lists", "subtasks", "priority", "tags", "reminder"},
 		},
 		{
@@ -1968,7 +2262,7 @@ func (s *Server) handleListProviders(ctx context.Context, req *mcp.CallToolReque
 			DisplayName:  "Dida365",
 			Description:  "滴答清单（国内）",
 			AuthType:     "API Token",
-			Enabled:      didaEnabled,
+			Enabled:      enabled("dida"),
 			Capabilities: []string{"due_date", "task_lists", "subtasks", "priority", "tags", "reminder"},
 		},
 		{
@@ -1977,14 +2271,14 @@ func (s *Server) handleListProviders(ctx context.Context, req *mcp.CallToolReque
 			DisplayName:  "Todoist",
 			Description:  "Todoist 任务管理",
 			AuthType:     "API Token",
-			Enabled:      todoistEnabled,
+			Enabled:      enabled("todoist"),
 			Capabilities: []string{"due_date", "projects", "subtasks", "priority", "tags"},
 		},
 	}
 
 	// 检查已认证的 Provider
 	for i := range providers {
-		if p, ok := s.providers[providers[i].Name]; ok {
+		if p, ok := s.lookupProvider(ctx, providers[i].Name); ok {
 			providers[i].Connected = p.IsAuthenticated()
 		}
 	}
@@ -2108,7 +2402,7 @@ func (s *Server) handleGetProviderInfo(ctx context.Context, req *mcp.CallToolReq
 	}
 
 	// 检查认证状态
-	if p, ok := s.providers[providerName]; ok {
+	if p, ok := s.lookupProvider(ctx, providerName); ok {
 		info.Enabled = true
 		info.Connected = p.IsAuthenticated()
 	}
@@ -0,0 +1,190 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+)
+
+const (
+	taskAttachmentsResourceTemplate = "task://{adapter}/{task_id}/attachments"
+	taskAttachmentResourceTemplate  = "task://{adapter}/{task_id}/attachments/{attachment_id}"
+
+	defaultAttachmentMIMEType = "application/octet-stream"
+)
+
+// taskAttachmentEntry 附件列表资源中的单个附件
+type taskAttachmentEntry struct {
+	provider.Attachment
+	URI string `json:"uri"`
+}
+
+// attachmentTarget 附件资源 URI 解析出的 provider 与远端任务定位
+type attachmentTarget struct {
+	source   string
+	reader   provider.TaskAttachmentReader
+	taskID   string
+	listID   string
+	rawID    string
+	attachID string
+}
+
+// resolveAttachmentTarget 解析 task://{adapter}/{task_id}/attachments[/{attachment_id}]，
+// 优先通过本地缓存将任务 ID 映射为远端原始 ID 与清单 ID。
+func (s *Server) resolveAttachmentTarget(ctx context.Context, uri string) (*attachmentTarget, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource uri: %w", err)
+	}
+	segments := strings.Split(strings.TrimPrefix(parsed.EscapedPath(), "/"), "/")
+	if len(segments) < 2 || len(segments) > 3 || segments[1] != "attachments" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	for i := range segments {
+		if segments[i], err = url.PathUnescape(segments[i]); err != nil {
+			return nil, fmt.Errorf("invalid resource uri: %w", err)
+		}
+	}
+	source, err := resolveResourceAdapter(parsed.Host)
+	if err != nil {
+		return nil, err
+	}
+	target := &attachmentTarget{taskID: segments[0], rawID: segments[0]}
+	if target.taskID == "" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	if len(segments) == 3 {
+		if target.attachID = segments[2]; target.attachID == "" {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+	}
+
+	if local := s.findLocalTask(ctx, target.taskID, string(source)); local != nil && taskMatchesSource(*local, source) {
+		if source == "" {
+			source = local.Source
+		}
+		if local.SourceRawID != "" {
+			target.rawID = local.SourceRawID
+		}
+		target.listID = local.ListID
+	} else if source == "" {
+		source = model.TaskSource(providerFromTaskID(target.taskID))
+	}
+	if source == "" || source == model.SourceLocal {
+		return nil, fmt.Errorf("task %s has no remote provider for attachments", target.taskID)
+	}
+	target.source = string(source)
+
+	p, ok := s.lookupProvider(ctx, target.source)
+	if !ok || p == nil || !p.IsAuthenticated() {
+		return nil, fmt.Errorf("provider %s not found or not authenticated", target.source)
+	}
+	reader, ok := unwrapProvider(p).(provider.TaskAttachmentReader)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support attachments", target.source)
+	}
+	target.reader = reader
+	return target, nil
+}
+
+// handleTaskAttachmentsResource 处理 task://{adapter}/{task_id}/attachments 模板资源，返回附件列表及各附件的资源 URI。
+func (s *Server) handleTaskAttachmentsResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	target, err := s.resolveAttachmentTarget(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	if target.attachID != "" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	attachments, err := target.reader.ListTaskAttachments(ctx, target.listID, target.rawID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	entries := make([]taskAttachmentEntry, 0, len(attachments))
+	for _, attachment := range attachments {
+		attachment.MIMEType = attachmentMIMEType(attachment)
+		entries = append(entries, taskAttachmentEntry{
+			Attachment: attachment,
+			URI:        taskAttachmentURI(target.source, target.taskID, attachment.ID),
+		})
+	}
+	output, err := toJSON(map[string]interface{}{
+		"adapter":     target.source,
+		"task_id":     target.taskID,
+		"total":       len(entries),
+		"attachments": entries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: output}},
+	}, nil
+}
+
+// handleTaskAttachmentResource 处理 task://{adapter}/{task_id}/attachments/{attachment_id} 模板资源，
+// 以附件自身的 MIME 类型返回文件内容（blob）。
+func (s *Server) handleTaskAttachmentResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	target, err := s.resolveAttachmentTarget(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	if target.attachID == "" {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	content, err := target.reader.GetTaskAttachment(ctx, target.listID, target.rawID, target.attachID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	if content == nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	data := content.Data
+	if data == nil {
+		data = []byte{}
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: attachmentMIMEType(content.Attachment), Blob: data}},
+	}, nil
+}
+
+// attachmentMIMEType 返回附件的 MIME 类型；provider 未提供时按文件扩展名推断
+func attachmentMIMEType(attachment provider.Attachment) string {
+	if value := strings.TrimSpace(attachment.MIMEType); value != "" {
+		return value
+	}
+	if byExt := mime.TypeByExtension(strings.ToLower(path.Ext(attachment.Name))); byExt != "" {
+		return byExt
+	}
+	return defaultAttachmentMIMEType
+}
+
+// taskAttachmentURI 构造单个附件的资源 URI
+func taskAttachmentURI(source, taskID, attachmentID string) string {
+	return fmt.Sprintf("task://%s/%s/attachments/%s", source, escapeResourceSegment(taskID), escapeResourceSegment(attachmentID))
+}
+
+// escapeResourceSegment 对 URI 模板简单变量不允许的字符做百分号编码，保证生成的 URI 能匹配资源模板
+func escapeResourceSegment(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("-._~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
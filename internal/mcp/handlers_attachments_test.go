@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+type attachmentProvider struct {
+	mockProvider
+	files    map[string]provider.AttachmentContent
+	requests []string
+}
+
+func (p *attachmentProvider) ListTaskAttachments(ctx context.Context, listID, taskID string) ([]provider.Attachment, error) {
+	p.requests = append(p.requests, listID+"/"+taskID)
+	result := make([]provider.Attachment, 0, len(p.files))
+	for _, id := range []string{"a1", "a/2"} {
+		if file, ok := p.files[id]; ok {
+			result = append(result, file.Attachment)
+		}
+	}
+	return result, nil
+}
+
+func (p *attachmentProvider) GetTaskAttachment(ctx context.Context, listID, taskID, attachmentID string) (*provider.AttachmentContent, error) {
+	p.requests = append(p.requests, listID+"/"+taskID+"/"+attachmentID)
+	file, ok := p.files[attachmentID]
+	if !ok {
+		return nil, fmt.Errorf("attachment not found: %s", attachmentID)
+	}
+	return &file, nil
+}
+
+func TestReadTaskAttachmentResources(t *testing.T) {
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	ctx := context.Background()
+	if err := store.SaveTask(ctx, &model.Task{
+		ID: "todoist-p1-r1", Title: "整理发票", Status: model.StatusTodo, Source: model.SourceTodoist, SourceRawID: "r1", ListID: "p1",
+	}); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+	remote := &attachmentProvider{files: map[string]provider.AttachmentContent{
+		"a1":  {Attachment: provider.Attachment{ID: "a1", Name: "invoice.pdf", MIMEType: "application/pdf", Size: 4}, Data: []byte("%PDF")},
+		"a/2": {Attachment: provider.Attachment{ID: "a/2", Name: "notes.txt"}, Data: []byte("hello")},
+	}}
+	s := NewServer(WithTaskStorage(store), WithProviders(map[string]provider.Provider{"todoist": remote}))
+	session := connectBreakdownClient(t, s, nil)
+
+	res, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "task://todoist/todoist-p1-r1/attachments"})
+	if err != nil {
+		t.Fatalf("read attachments: %v", err)
+	}
+	var listing struct {
+		Total       int                   `json:"total"`
+		Attachments []taskAttachmentEntry `json:"attachments"`
+	}
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &listing); err != nil {
+		t.Fatalf("decode attachments: %v", err)
+	}
+	if listing.Total != 2 || listing.Attachments[1].MIMEType != "text/plain; charset=utf-8" {
+		t.Fatalf("unexpected attachments: %+v", listing)
+	}
+	if remote.requests[0] != "p1/r1" {
+		t.Fatalf("expected remote ids resolved from local cache, got %v", remote.requests)
+	}
+
+	for _, entry := range listing.Attachments {
+		res, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: entry.URI})
+		if err != nil {
+			t.Fatalf("read attachment %s: %v", entry.URI, err)
+		}
+		content := res.Contents[0]
+		want := remote.files[entry.ID]
+		if content.MIMEType != entry.MIMEType || string(content.Blob) != string(want.Data) {
+			t.Fatalf("unexpected attachment content for %s: %+v", entry.URI, content)
+		}
+	}
+
+	// 不支持附件的 provider 返回错误
+	s = NewServer(WithTaskStorage(store), WithProviders(map[string]provider.Provider{"todoist": &mockProvider{}}))
+	session = connectBreakdownClient(t, s, nil)
+	if _, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "task://todoist/todoist-p1-r1/attachments"}); err == nil {
+		t.Fatalf("expected error for provider without attachments")
+	}
+}
@@ -0,0 +1,354 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+const (
+	defaultBreakdownSubtasks = 5
+	maxBreakdownSubtasks     = 12
+)
+
+var breakdownListPrefixPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)、])\s*(?:\[[ xX]\]\s*)?`)
+
+// breakdownSubtask 拆解出的单个子任务建议
+type breakdownSubtask struct {
+	Title           string `json:"title"`
+	Description     string `json:"description,omitempty"`
+	EstimateMinutes int    `json:"estimate_minutes,omitempty"`
+	DueOffsetDays   int    `json:"due_offset_days,omitempty"`
+}
+
+// handleBreakdownTask 借助客户端 sampling 生成子任务，经 elicitation 让用户勾选后在来源 provider 中创建。
+func (s *Server) handleBreakdownTask(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	taskID := strings.TrimSpace(getString(rawArgs, "task_id"))
+	if taskID == "" {
+		return nil, fmt.Errorf("task_id is required")
+	}
+	parent, err := s.taskStore.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+
+	maxSubtasks, ok := getInt(rawArgs, "max_subtasks")
+	if !ok || maxSubtasks <= 0 {
+		maxSubtasks = defaultBreakdownSubtasks
+	}
+	if maxSubtasks > maxBreakdownSubtasks {
+		maxSubtasks = maxBreakdownSubtasks
+	}
+	autoConfirm, _ := getBool(rawArgs, "auto_confirm")
+	dryRun, _ := getBool(rawArgs, "dry_run")
+
+	result := map[string]interface{}{
+		"task_id": parent.ID,
+		"title":   parent.Title,
+		"dry_run": dryRun,
+	}
+	notes := make([]string, 0)
+
+	// 1. 生成子任务建议：显式传入 > 客户端 sampling > 内置启发式
+	proposals, err := parseBreakdownSubtasks(rawArgs["subtasks"])
+	if err != nil {
+		return nil, err
+	}
+	origin := "provided"
+	if len(proposals) > 0 {
+		autoConfirm = true
+	} else if session := samplingSession(req); session != nil {
+		origin = "sampling"
+		proposals, err = s.sampleSubtasks(ctx, session, parent, getString(rawArgs, "guidance"), maxSubtasks)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("sampling failed, fell back to heuristic breakdown: %v", err))
+			proposals = nil
+		}
+	} else {
+		notes = append(notes, "client does not support sampling, used heuristic breakdown")
+	}
+	if len(proposals) == 0 {
+		origin = "heuristic"
+		for _, item := range buildDecomposePreview(*parent, true) {
+			proposals = append(proposals, breakdownSubtask{
+				Title:         fmt.Sprint(item["title"]),
+				Description:   fmt.Sprint(item["description"]),
+				DueOffsetDays: item["due_offset_days"].(int),
+			})
+		}
+	}
+	if len(proposals) > maxSubtasks {
+		proposals = proposals[:maxSubtasks]
+	}
+	result["origin"] = origin
+	result["proposed"] = proposals
+
+	// 2. 让用户确认要创建哪些子任务
+	chosen := proposals
+	if !autoConfirm {
+		session := elicitationSession(req)
+		if session == nil {
+			result["status"] = "pending_confirmation"
+			result["hint"] = "client does not support elicitation; call breakdown_task again with the chosen subtasks"
+			result["notes"] = notes
+			return breakdownResult(result)
+		}
+		selected, action, err := elicitSubtaskSelection(ctx, session, parent, proposals)
+		if err != nil {
+			return nil, fmt.Errorf("elicitation failed: %w", err)
+		}
+		if action != "accept" {
+			result["status"] = "cancelled"
+			if action == "decline" {
+				result["status"] = "declined"
+			}
+			result["notes"] = notes
+			return breakdownResult(result)
+		}
+		chosen = selected
+	}
+	if len(chosen) == 0 {
+		result["status"] = "nothing_selected"
+		result["notes"] = notes
+		return breakdownResult(result)
+	}
+	if dryRun {
+		result["status"] = "dry_run"
+		result["selected"] = chosen
+		result["notes"] = notes
+		return breakdownResult(result)
+	}
+
+	// 3. 在本地创建子任务，并推送到父任务所在 provider
+	now := time.Now()
+	children := make([]model.Task, 0, len(chosen))
+	for i, item := range chosen {
+		parentID := parent.ID
+		child := model.Task{
+			ID:               fmt.Sprintf("%s_%d", generateID(), i+1),
+			Title:            item.Title,
+			Description:      item.Description,
+			Status:           model.StatusTodo,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+			Source:           model.SourceLocal,
+			ListID:           parent.ListID,
+			ListName:         parent.ListName,
+			Priority:         parent.Priority,
+			Quadrant:         parent.Quadrant,
+			EstimatedMinutes: item.EstimateMinutes,
+			ParentID:         &parentID,
+		}
+		child.Metadata = &model.TaskMetadata{
+			Version:      "1.0",
+			LocalID:      child.ID,
+			SyncSource:   "local",
+			CustomFields: map[string]interface{}{"tb_breakdown_of": parent.ID},
+		}
+		if item.DueOffsetDays > 0 {
+			due := now.AddDate(0, 0, item.DueOffsetDays)
+			if parent.DueDate != nil && due.After(*parent.DueDate) {
+				due = *parent.DueDate
+			}
+			child.DueDate = &due
+		}
+		if err := s.taskStore.SaveTask(ctx, &child); err != nil {
+			return nil, fmt.Errorf("failed to save task: %w", err)
+		}
+		parent.SubtaskIDs = append(parent.SubtaskIDs, child.ID)
+		children = append(children, child)
+	}
+	parent.UpdatedAt = now
+	if err := s.taskStore.SaveTask(ctx, parent); err != nil {
+		return nil, fmt.Errorf("failed to save task: %w", err)
+	}
+	result["status"] = "created"
+	result["created"] = toCompactTasks(children)
+
+	if parent.Source != "" && parent.Source != model.SourceLocal {
+		p, ok := s.lookupProvider(ctx, string(parent.Source))
+		if ok && p != nil && p.IsAuthenticated() {
+			push := &SyncPushResult{Provider: string(parent.Source)}
+			s.pushLocalTasks(ctx, p, children, parent.ListID, parent.Source, false, push)
+			result["remote"] = push
+		} else {
+			notes = append(notes, fmt.Sprintf("provider %s not available, subtasks kept locally", parent.Source))
+		}
+	}
+	result["notes"] = notes
+	return breakdownResult(result)
+}
+
+func breakdownResult(result map[string]interface{}) (*mcp.CallToolResult, error) {
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// samplingSession 返回支持 sampling 的客户端会话，不支持时返回 nil。
+func samplingSession(req *mcp.CallToolRequest) *mcp.ServerSession {
+	if req == nil || req.Session == nil {
+		return nil
+	}
+	params := req.Session.InitializeParams()
+	if params == nil || params.Capabilities == nil || params.Capabilities.Sampling == nil {
+		return nil
+	}
+	return req.Session
+}
+
+// elicitationSession 返回支持 elicitation 的客户端会话，不支持时返回 nil。
+func elicitationSession(req *mcp.CallToolRequest) *mcp.ServerSession {
+	if req == nil || req.Session == nil {
+		return nil
+	}
+	params := req.Session.InitializeParams()
+	if params == nil || params.Capabilities == nil || params.Capabilities.Elicitation == nil {
+		return nil
+	}
+	return req.Session
+}
+
+// sampleSubtasks 请求客户端 LLM 生成子任务列表。
+func (s *Server) sampleSubtasks(ctx context.Context, session *mcp.ServerSession, task *model.Task, guidance string, maxSubtasks int) ([]breakdownSubtask, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "请把下面的任务拆解为不超过 %d 个可独立执行的子任务。\n\n", maxSubtasks)
+	fmt.Fprintf(&prompt, "任务标题：%s\n", task.Title)
+	if desc := strings.TrimSpace(task.Description); desc != "" {
+		fmt.Fprintf(&prompt, "任务描述：%s\n", desc)
+	}
+	if task.DueDate != nil {
+		fmt.Fprintf(&prompt, "截止日期：%s\n", task.DueDate.Format("2006-01-02"))
+	}
+	if guidance = strings.TrimSpace(guidance); guidance != "" {
+		fmt.Fprintf(&prompt, "额外要求：%s\n", guidance)
+	}
+
+	res, err := session.CreateMessage(ctx, &mcp.CreateMessageParams{
+		SystemPrompt: `你是任务拆解助手。只输出 JSON 数组，每项格式为 {"title": "...", "description": "...", "estimate_minutes": 30, "due_offset_days": 1}，不要输出其他内容。`,
+		Messages: []*mcp.SamplingMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: prompt.String()}},
+		},
+		MaxTokens:   1024,
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return nil, err
+	}
+	text, ok := res.Content.(*mcp.TextContent)
+	if !ok {
+		return nil, fmt.Errorf("sampling returned non-text content")
+	}
+	subtasks := parseSampledSubtasks(text.Text)
+	if len(subtasks) == 0 {
+		return nil, fmt.Errorf("sampling returned no subtasks")
+	}
+	return subtasks, nil
+}
+
+// elicitSubtaskSelection 通过表单让用户勾选要创建的子任务，返回选中项与用户动作。
+func elicitSubtaskSelection(ctx context.Context, session *mcp.ServerSession, task *model.Task, proposals []breakdownSubtask) ([]breakdownSubtask, string, error) {
+	properties := make(map[string]interface{}, len(proposals))
+	for i, item := range proposals {
+		properties[fmt.Sprintf("subtask_%d", i+1)] = map[string]interface{}{
+			"type":        "boolean",
+			"title":       item.Title,
+			"description": item.Description,
+			"default":     true,
+		}
+	}
+	res, err := session.Elicit(ctx, &mcp.ElicitParams{
+		Message: fmt.Sprintf("为任务「%s」生成了 %d 个子任务，请勾选需要创建的子任务", task.Title, len(proposals)),
+		RequestedSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if res.Action != "accept" {
+		return nil, res.Action, nil
+	}
+	selected := make([]breakdownSubtask, 0, len(proposals))
+	for i, item := range proposals {
+		value, ok := res.Content[fmt.Sprintf("subtask_%d", i+1)]
+		if !ok {
+			// 客户端未回传的字段按默认值（勾选）处理
+			selected = append(selected, item)
+			continue
+		}
+		if checked, _ := value.(bool); checked {
+			selected = append(selected, item)
+		}
+	}
+	return selected, res.Action, nil
+}
+
+// parseBreakdownSubtasks 解析显式传入的子任务（字符串或对象数组）。
+func parseBreakdownSubtasks(raw json.RawMessage) ([]breakdownSubtask, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("invalid subtasks: %w", err)
+	}
+	out := make([]breakdownSubtask, 0, len(items))
+	for _, item := range items {
+		var title string
+		if err := json.Unmarshal(item, &title); err == nil {
+			if title = strings.TrimSpace(title); title != "" {
+				out = append(out, breakdownSubtask{Title: title})
+			}
+			continue
+		}
+		var subtask breakdownSubtask
+		if err := json.Unmarshal(item, &subtask); err != nil {
+			return nil, fmt.Errorf("invalid subtasks: %w", err)
+		}
+		if subtask.Title = strings.TrimSpace(subtask.Title); subtask.Title != "" {
+			out = append(out, subtask)
+		}
+	}
+	return out, nil
+}
+
+// parseSampledSubtasks 从 LLM 输出中提取子任务：优先解析 JSON 数组，失败时按列表行解析。
+func parseSampledSubtasks(text string) []breakdownSubtask {
+	if start, end := strings.Index(text, "["), strings.LastIndex(text, "]"); start >= 0 && end > start {
+		if subtasks, err := parseBreakdownSubtasks(json.RawMessage(text[start : end+1])); err == nil && len(subtasks) > 0 {
+			return subtasks
+		}
+	}
+	out := make([]breakdownSubtask, 0)
+	for _, line := range strings.Split(text, "\n") {
+		if !breakdownListPrefixPattern.MatchString(line) {
+			continue
+		}
+		if title := strings.TrimSpace(breakdownListPrefixPattern.ReplaceAllString(line, "")); title != "" {
+			out = append(out, breakdownSubtask{Title: sanitizeMarkdownText(title)})
+		}
+	}
+	return out
+}
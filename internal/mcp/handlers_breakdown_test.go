@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+// connectBreakdownClient 通过内存传输连接一个支持 sampling 与 elicitation 的测试客户端。
+func connectBreakdownClient(t *testing.T, s *Server, opts *sdkmcp.ClientOptions) *sdkmcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := sdkmcp.NewInMemoryTransports()
+	if _, err := s.server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("connect server: %v", err)
+	}
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "test-client", Version: "0.0.1"}, opts)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+func TestBreakdownTaskWithSamplingAndElicitation(t *testing.T) {
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	ctx := context.Background()
+	parent := &model.Task{
+		ID:          "google-list-1-raw-parent",
+		Title:       "发布新版官网",
+		Status:      model.StatusTodo,
+		Source:      model.SourceGoogle,
+		SourceRawID: "raw-parent",
+		ListID:      "list-1",
+		CreatedAt:   time.Now(),
+	}
+	if err := store.SaveTask(ctx, parent); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+	mock := &mockProvider{}
+	s := NewServer(WithTaskStorage(store), WithProviders(map[string]provider.Provider{"google": mock}))
+
+	var samplingPrompt string
+	var elicitMessage string
+	session := connectBreakdownClient(t, s, &sdkmcp.ClientOptions{
+		CreateMessageHandler: func(ctx context.Context, req *sdkmcp.CreateMessageRequest) (*sdkmcp.CreateMessageResult, error) {
+			samplingPrompt = req.Params.Messages[0].Content.(*sdkmcp.TextContent).Text
+			return &sdkmcp.CreateMessageResult{
+				Model: "test",
+				Role:  "assistant",
+				Content: &sdkmcp.TextContent{Text: "```json\n" +
+					`[{"title":"整理页面结构","estimate_minutes":60},{"title":"编写文案"},{"title":"上线验证","due_offset_days":2}]` +
+					"\n```"},
+			}, nil
+		},
+		ElicitationHandler: func(ctx context.Context, req *sdkmcp.ElicitRequest) (*sdkmcp.ElicitResult, error) {
+			elicitMessage = req.Params.Message
+			return &sdkmcp.ElicitResult{Action: "accept", Content: map[string]any{
+				"subtask_1": true,
+				"subtask_2": false,
+				"subtask_3": true,
+			}}, nil
+		},
+	})
+
+	res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{
+		Name:      "breakdown_task",
+		Arguments: map[string]any{"task_id": parent.ID},
+	})
+	if err != nil {
+		t.Fatalf("call breakdown_task: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("breakdown_task returned error: %v", res.Content)
+	}
+	out := parseJSONResult(t, res)
+	if out["origin"] != "sampling" || out["status"] != "created" {
+		t.Fatalf("unexpected result: %v", out)
+	}
+	if !strings.Contains(samplingPrompt, "发布新版官网") || !strings.Contains(elicitMessage, "3 个子任务") {
+		t.Fatalf("unexpected prompts: %q / %q", samplingPrompt, elicitMessage)
+	}
+	created, _ := out["created"].([]interface{})
+	if len(created) != 2 {
+		t.Fatalf("expected 2 created subtasks, got %v", created)
+	}
+	if len(mock.created) != 2 || mock.created[0].ParentID == nil || *mock.created[0].ParentID != "raw-parent" {
+		t.Fatalf("expected subtasks pushed under remote parent, got %+v", mock.created)
+	}
+
+	updated, err := store.GetTask(ctx, parent.ID)
+	if err != nil || len(updated.SubtaskIDs) != 2 {
+		t.Fatalf("expected parent to record subtasks, got %+v (%v)", updated, err)
+	}
+}
+
+func TestBreakdownTaskWithoutClientCapabilities(t *testing.T) {
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	ctx := context.Background()
+	parent := &model.Task{ID: "local-1", Title: "准备季度汇报", Status: model.StatusTodo, Source: model.SourceLocal}
+	if err := store.SaveTask(ctx, parent); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+	s := NewServer(WithTaskStorage(store))
+
+	res, err := s.handleBreakdownTask(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": parent.ID}))
+	if err != nil {
+		t.Fatalf("breakdown without session: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	if out["origin"] != "heuristic" || out["status"] != "pending_confirmation" {
+		t.Fatalf("unexpected result: %v", out)
+	}
+
+	res, err = s.handleBreakdownTask(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id":  parent.ID,
+		"subtasks": []interface{}{"收集数据", map[string]interface{}{"title": "制作幻灯片", "estimate_minutes": 90}},
+	}))
+	if err != nil {
+		t.Fatalf("breakdown with explicit subtasks: %v", err)
+	}
+	out = parseJSONResult(t, res)
+	created, _ := out["created"].([]interface{})
+	if out["status"] != "created" || len(created) != 2 {
+		t.Fatalf("unexpected explicit result: %v", out)
+	}
+}
+
+func TestParseSampledSubtasksFallsBackToList(t *testing.T) {
+	got := parseSampledSubtasks("建议如下：\n1. 确认需求\n- [ ] **设计方案**\n随便一句话")
+	if len(got) != 2 || got[0].Title != "确认需求" || got[1].Title != "设计方案" {
+		t.Fatalf("unexpected subtasks: %+v", got)
+	}
+}
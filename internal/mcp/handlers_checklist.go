@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// 任务清单复选框：[ ] / [x] / [X]。
+var markdownCheckboxPattern = regexp.MustCompile(`^\[([ xX])\]\s*`)
+
+// checklistItem 清单中单行解析结果，Depth 从 0 开始。
+type checklistItem struct {
+	Title     string `json:"title"`
+	Depth     int    `json:"depth"`
+	Completed bool   `json:"completed,omitempty"`
+	ParentRef int    `json:"parent_ref"`
+}
+
+// handleCreateTasksFromMarkdown 将 Markdown 列表/清单逐行创建为任务，缩进层级保留为子任务。
+func (s *Server) handleCreateTasksFromMarkdown(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	markdown := getString(rawArgs, "markdown")
+	if strings.TrimSpace(markdown) == "" {
+		return nil, fmt.Errorf("markdown is required")
+	}
+	providerName, err := resolveProviderNameStrict(getString(rawArgs, "provider"))
+	if err != nil {
+		return nil, err
+	}
+	if providerName == "" {
+		providerName = string(model.SourceLocal)
+	}
+	listID := strings.TrimSpace(getString(rawArgs, "list_id"))
+	parseSyntax, _ := getBool(rawArgs, "parse_syntax")
+	skipCompleted, _ := getBool(rawArgs, "skip_completed")
+	dryRun, _ := getBool(rawArgs, "dry_run")
+	maxTasks, _ := getInt(rawArgs, "max_tasks")
+	maxTasks = normalizeMarkdownMaxTasks(maxTasks)
+	timezone := getString(rawArgs, "timezone")
+	if timezone == "" {
+		timezone = s.effectiveIntelligenceConfig().Timezone
+	}
+	now := time.Now().In(resolveLocation(timezone))
+
+	root, stats, warnings := parseMarkdownTaskTree(markdown)
+	items := flattenChecklist(root, skipCompleted)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no markdown list items found")
+	}
+	if len(items) > maxTasks {
+		items = items[:maxTasks]
+		warnings = append(warnings, fmt.Sprintf("items truncated by max_tasks=%d", maxTasks))
+	}
+
+	// 按深度优先顺序生成任务：父任务总在子任务之前，ParentRef 指向 tasks 中的下标
+	baseID := generateID()
+	created := time.Now()
+	projectNames := s.projectNameIndex(ctx)
+	tasks := make([]model.Task, 0, len(items))
+	for i, item := range items {
+		task := model.Task{
+			ID:        fmt.Sprintf("%s_%d", baseID, i+1),
+			Title:     item.Title,
+			Status:    model.StatusTodo,
+			CreatedAt: created,
+			UpdatedAt: created,
+			Source:    model.SourceLocal,
+			ListID:    listID,
+		}
+		if item.Completed {
+			task.Status = model.StatusCompleted
+			task.CompletedAt = &created
+		}
+		if parseSyntax {
+			parsed := parseQuickAdd(item.Title, now)
+			if parsed.Title != "" {
+				task.Title = parsed.Title
+			}
+			task.Tags = parsed.Labels
+			task.Priority = model.Priority(parsed.Priority)
+			task.DueDate = parsed.Due
+			if parsed.Project != "" {
+				for id := range s.resolveProjectFilter(ctx, []string{parsed.Project}) {
+					if _, ok := projectNames[id]; ok {
+						setTaskCustomField(&task, "tb_project_id", id)
+					}
+				}
+			}
+		}
+		task.Quadrant = model.CalculateQuadrantFromTask(&task)
+		if task.Metadata == nil {
+			task.Metadata = &model.TaskMetadata{}
+		}
+		task.Metadata.Version = "1.0"
+		task.Metadata.Quadrant = int(task.Quadrant)
+		task.Metadata.Priority = int(task.Priority)
+		task.Metadata.LocalID = task.ID
+		task.Metadata.SyncSource = "local"
+		if item.ParentRef >= 0 {
+			parentID := tasks[item.ParentRef].ID
+			task.ParentID = &parentID
+			tasks[item.ParentRef].SubtaskIDs = append(tasks[item.ParentRef].SubtaskIDs, task.ID)
+		}
+		tasks = append(tasks, task)
+	}
+
+	result := map[string]interface{}{
+		"provider": providerName,
+		"dry_run":  dryRun,
+		"stats":    stats,
+		"count":    len(tasks),
+		"items":    items,
+	}
+	if dryRun {
+		result["warnings"] = warnings
+		return quickAddResult(result)
+	}
+
+	for i := range tasks {
+		if err := s.taskStore.SaveTask(ctx, &tasks[i]); err != nil {
+			return nil, fmt.Errorf("failed to save task: %w", err)
+		}
+	}
+	result["created"] = toCompactTasks(tasks)
+
+	if providerName != string(model.SourceLocal) {
+		p, ok := s.lookupProvider(ctx, providerName)
+		if !ok || p == nil || !p.IsAuthenticated() {
+			warnings = append(warnings, fmt.Sprintf("provider %s not found or not authenticated, tasks kept locally", providerName))
+		} else {
+			push, err := s.pushTaskTreeToProvider(ctx, p, providerName, tasks, listID)
+			if err != nil {
+				warnings = append(warnings, err.Error())
+			} else {
+				result["remote"] = push
+			}
+		}
+	}
+	result["warnings"] = warnings
+	return quickAddResult(result)
+}
+
+// flattenChecklist 深度优先展开列表树，剥离复选框标记；跳过的已完成项连同其子项一起忽略。
+func flattenChecklist(root *markdownNode, skipCompleted bool) []checklistItem {
+	items := make([]checklistItem, 0)
+	var walk func(node *markdownNode, depth, parentRef int)
+	walk = func(node *markdownNode, depth, parentRef int) {
+		title := node.Title
+		completed := false
+		if match := markdownCheckboxPattern.FindStringSubmatch(title); match != nil {
+			completed = match[1] != " "
+			title = strings.TrimSpace(title[len(match[0]):])
+		}
+		if title == "" || (completed && skipCompleted) {
+			return
+		}
+		ref := len(items)
+		items = append(items, checklistItem{Title: title, Depth: depth, Completed: completed, ParentRef: parentRef})
+		for _, child := range node.Children {
+			walk(child, depth+1, ref)
+		}
+	}
+	for _, top := range root.Children {
+		walk(top, 0, -1)
+	}
+	return items
+}
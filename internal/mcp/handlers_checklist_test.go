@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+const meetingNotes = `会议纪要
+- [ ] 准备发布说明 @docs p2
+  - [ ] 整理变更日志
+  - [x] 确认版本号
+- [x] 预订会议室
+  - [ ] 发送邀请
+1. 跟进客户反馈 tomorrow
+`
+
+func TestHandleCreateTasksFromMarkdownPreservesNesting(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	cfg.Timezone = "UTC"
+	mock := &mockProvider{}
+	s, store, ctx := newIntelligenceTestServer(t, cfg, map[string]provider.Provider{"google": mock})
+
+	res, err := s.handleCreateTasksFromMarkdown(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"markdown":     meetingNotes,
+		"provider":     "google",
+		"parse_syntax": true,
+	}))
+	if err != nil {
+		t.Fatalf("create from markdown: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	if count, _ := out["count"].(float64); count != 6 {
+		t.Fatalf("unexpected count: %v", out)
+	}
+
+	tasks, err := store.ListTasks(ctx, storage.ListOptions{})
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	byTitle := make(map[string]model.Task, len(tasks))
+	for _, task := range tasks {
+		byTitle[task.Title] = task
+	}
+	release, ok := byTitle["准备发布说明"]
+	if !ok || release.Priority != model.PriorityHigh || len(release.Tags) != 1 || release.Tags[0] != "docs" {
+		t.Fatalf("unexpected parent task: %+v", release)
+	}
+	changelog := byTitle["整理变更日志"]
+	if changelog.ParentID == nil || *changelog.ParentID != release.ID {
+		t.Fatalf("subtask should reference parent: %+v", changelog)
+	}
+	if byTitle["确认版本号"].Status != model.StatusCompleted {
+		t.Fatalf("checked item should be completed: %+v", byTitle["确认版本号"])
+	}
+	if byTitle["跟进客户反馈"].DueDate == nil {
+		t.Fatalf("due date should be parsed: %+v", byTitle["跟进客户反馈"])
+	}
+	if len(mock.created) != 6 {
+		t.Fatalf("expected 6 remote creates, got %d", len(mock.created))
+	}
+}
+
+func TestHandleCreateTasksFromMarkdownSkipCompleted(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	res, err := s.handleCreateTasksFromMarkdown(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"markdown":       meetingNotes,
+		"skip_completed": true,
+		"dry_run":        true,
+	}))
+	if err != nil {
+		t.Fatalf("create from markdown: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	items, _ := out["items"].([]interface{})
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %v", items)
+	}
+	if title := items[0].(map[string]interface{})["title"]; title != "准备发布说明 @docs p2" {
+		t.Fatalf("syntax should be kept without parse_syntax: %v", title)
+	}
+	tasks, _ := store.ListTasks(ctx, storage.ListOptions{})
+	if len(tasks) != 0 {
+		t.Fatalf("dry run should not save tasks")
+	}
+
+	if _, err := s.handleCreateTasksFromMarkdown(ctx, buildCallToolRequest(t, map[string]interface{}{"markdown": "just text"})); err == nil {
+		t.Fatalf("expected error for markdown without list items")
+	}
+}
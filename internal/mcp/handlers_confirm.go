@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+	tbsync "github.com/yeisme/taskbridge/internal/sync"
+)
+
+// maxConfirmPreviewItems 确认摘要中最多列出的条目数
+const maxConfirmPreviewItems = 10
+
+// confirmDestructive 在客户端支持 elicitation 时请求用户确认破坏性操作。
+// 客户端不支持时返回 "accept" 以保持原有行为；否则返回用户动作，
+// 只有用户接受且勾选确认时才返回 "accept"。
+func confirmDestructive(ctx context.Context, req *mcp.CallToolRequest, summary string) (string, error) {
+	session := elicitationSession(req)
+	if session == nil {
+		return "accept", nil
+	}
+	// 不设置 default：go-sdk 会对拒绝/取消时为空的 content 应用默认值而 panic
+	res, err := session.Elicit(ctx, &mcp.ElicitParams{
+		Message: summary,
+		RequestedSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"confirm": map[string]interface{}{
+					"type":        "boolean",
+					"title":       "确认执行",
+					"description": "该操作不可撤销",
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("elicitation failed: %w", err)
+	}
+	if res.Action != "accept" {
+		return res.Action, nil
+	}
+	// 未勾选或未回传确认字段均视为拒绝
+	if confirmed, _ := res.Content["confirm"].(bool); !confirmed {
+		return "decline", nil
+	}
+	return "accept", nil
+}
+
+// unconfirmedResult 用户未确认时返回的结果，不视为工具错误
+func unconfirmedResult(action, summary string) (*mcp.CallToolResult, error) {
+	status := "cancelled"
+	if action == "decline" {
+		status = "declined"
+	}
+	text, err := toJSON(map[string]interface{}{
+		"success": false,
+		"status":  status,
+		"summary": summary,
+		"message": "用户未确认，操作未执行",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}, nil
+}
+
+// deleteTaskSummary 生成删除任务的确认摘要
+func (s *Server) deleteTaskSummary(ctx context.Context, task *model.Task) string {
+	var b strings.Builder
+	source := task.Source
+	if source == "" {
+		source = model.SourceLocal
+	}
+	fmt.Fprintf(&b, "即将删除任务「%s」（ID: %s，来源: %s，状态: %s）", task.Title, task.ID, source, task.Status)
+	if tasks, err := s.taskStore.ListTasks(ctx, storage.ListOptions{}); err == nil {
+		children := 0
+		for _, item := range tasks {
+			if item.ParentID != nil && *item.ParentID == task.ID {
+				children++
+			}
+		}
+		if children > 0 {
+			fmt.Fprintf(&b, "，另有 %d 个子任务以其为父任务", children)
+		}
+	}
+	b.WriteString("。")
+	return b.String()
+}
+
+// remoteDeletionCandidates 列出远程存在但本地不存在、将被 sync_push 删除的任务
+func remoteDeletionCandidates(ctx context.Context, p provider.Provider, taskLists []model.TaskList, localSourceRawIDs map[string]bool) ([]model.Task, error) {
+	candidates := make([]model.Task, 0)
+	for _, list := range taskLists {
+		remoteTasks, err := p.ListTasks(ctx, list.ID, provider.ListOptions{})
+		if err != nil {
+			// 与 deleteRemoteTasks 一致，读取失败的清单不会被删除
+			continue
+		}
+		for _, remoteTask := range remoteTasks {
+			if !localSourceRawIDs[remoteTask.SourceRawID] {
+				candidates = append(candidates, remoteTask)
+			}
+		}
+	}
+	return candidates, ctx.Err()
+}
+
+// remoteDeletionSummary 生成删除远程任务的确认摘要
+func remoteDeletionSummary(providerName string, candidates []model.Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "同步将删除 %s 上 %d 个本地不存在的远程任务：", providerName, len(candidates))
+	for i, task := range candidates {
+		if i == maxConfirmPreviewItems {
+			fmt.Fprintf(&b, "\n- ……以及另外 %d 个", len(candidates)-maxConfirmPreviewItems)
+			break
+		}
+		fmt.Fprintf(&b, "\n- %s", task.Title)
+	}
+	return b.String()
+}
+
+// syncDeletionSummary 以模拟推送统计 sync_now 将删除的远程任务数，没有删除时返回空字符串
+func syncDeletionSummary(ctx context.Context, engine *tbsync.Engine, opts tbsync.Options) (string, error) {
+	preview := opts
+	preview.DryRun = true
+	preview.Direction = tbsync.DirectionPush
+
+	results := make(map[string]*tbsync.Result)
+	if preview.Provider == "" {
+		all, err := engine.SyncAll(ctx, preview)
+		if err != nil {
+			return "", err
+		}
+		results = all
+	} else {
+		result, err := engine.Sync(ctx, preview)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		if err != nil {
+			// 无法预估时仍需确认，避免未经确认删除远程任务
+			return fmt.Sprintf("同步将删除 %s 上本地不存在的远程任务（无法预估数量: %v）。", preview.Provider, err), nil
+		}
+		results[preview.Provider] = result
+	}
+
+	names := make([]string, 0, len(results))
+	total := 0
+	for name, result := range results {
+		if result == nil || result.Deleted == 0 {
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s %d 个", name, result.Deleted))
+		total += result.Deleted
+	}
+	if total == 0 {
+		return "", nil
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("同步将删除 %d 个本地不存在的远程任务（%s）。", total, strings.Join(names, "，")), nil
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+type deletingProvider struct {
+	remoteTasksProvider
+	deleted []string
+}
+
+func (p *deletingProvider) DeleteTask(ctx context.Context, listID, taskID string) error {
+	p.deleted = append(p.deleted, taskID)
+	return nil
+}
+
+func confirmHandler(messages *[]string, result *sdkmcp.ElicitResult) func(context.Context, *sdkmcp.ElicitRequest) (*sdkmcp.ElicitResult, error) {
+	return func(_ context.Context, req *sdkmcp.ElicitRequest) (*sdkmcp.ElicitResult, error) {
+		*messages = append(*messages, req.Params.Message)
+		return result, nil
+	}
+}
+
+func TestDeleteTaskRequestsConfirmation(t *testing.T) {
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	ctx := context.Background()
+	if err := store.SaveTask(ctx, &model.Task{ID: "t1", Title: "季度预算", Status: model.StatusTodo, Source: model.SourceLocal}); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+	s := NewServer(WithTaskStorage(store))
+
+	var messages []string
+	declined := connectBreakdownClient(t, s, &sdkmcp.ClientOptions{
+		ElicitationHandler: confirmHandler(&messages, &sdkmcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": false}}),
+	})
+	res, err := declined.CallTool(ctx, &sdkmcp.CallToolParams{Name: "delete_task", Arguments: map[string]any{"id": "t1"}})
+	if err != nil {
+		t.Fatalf("call delete_task: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	if out["status"] != "declined" {
+		t.Fatalf("expected declined result, got %v", out)
+	}
+	if len(messages) != 1 || !strings.Contains(messages[0], "季度预算") {
+		t.Fatalf("unexpected confirmation messages: %v", messages)
+	}
+	if _, err := store.GetTask(ctx, "t1"); err != nil {
+		t.Fatalf("task should not be deleted without confirmation: %v", err)
+	}
+
+	confirmed := connectBreakdownClient(t, s, &sdkmcp.ClientOptions{
+		ElicitationHandler: confirmHandler(&messages, &sdkmcp.ElicitResult{Action: "accept", Content: map[string]any{"confirm": true}}),
+	})
+	if _, err := confirmed.CallTool(ctx, &sdkmcp.CallToolParams{Name: "delete_task", Arguments: map[string]any{"id": "t1"}}); err != nil {
+		t.Fatalf("call delete_task: %v", err)
+	}
+	if _, err := store.GetTask(ctx, "t1"); err == nil {
+		t.Fatalf("expected task deleted after confirmation")
+	}
+}
+
+func TestSyncPushDeleteRequestsConfirmation(t *testing.T) {
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	ctx := context.Background()
+	remote := &deletingProvider{remoteTasksProvider: remoteTasksProvider{remote: []model.Task{
+		{ID: "google-@default-r1", Title: "远端孤儿任务", Status: model.StatusTodo, Source: model.SourceGoogle, SourceRawID: "r1"},
+	}}}
+	s := NewServer(WithTaskStorage(store), WithProviders(map[string]provider.Provider{"google": remote}))
+
+	var messages []string
+	session := connectBreakdownClient(t, s, &sdkmcp.ClientOptions{
+		ElicitationHandler: confirmHandler(&messages, &sdkmcp.ElicitResult{Action: "cancel"}),
+	})
+	for _, tool := range []string{"sync_push", "sync_now"} {
+		args := map[string]any{"provider": "google", "delete": true}
+		if tool == "sync_now" {
+			args = map[string]any{"target": "google", "delete": true}
+		}
+		res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: tool, Arguments: args})
+		if err != nil {
+			t.Fatalf("call %s: %v", tool, err)
+		}
+		if out := parseJSONResult(t, res); out["status"] != "cancelled" {
+			t.Fatalf("expected %s cancelled, got %v", tool, out)
+		}
+	}
+	if len(remote.deleted) != 0 {
+		t.Fatalf("remote tasks deleted without confirmation: %v", remote.deleted)
+	}
+	if len(messages) != 2 || !strings.Contains(messages[0], "远端孤儿任务") || !strings.Contains(messages[1], "google 1 个") {
+		t.Fatalf("unexpected confirmation messages: %v", messages)
+	}
+
+	// dry_run 不需要确认
+	if _, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: "sync_push", Arguments: map[string]any{"provider": "google", "delete": true, "dry_run": true}}); err != nil {
+		t.Fatalf("call sync_push dry run: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("dry run should not request confirmation: %v", messages)
+	}
+}
@@ -0,0 +1,436 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	tbsync "github.com/yeisme/taskbridge/internal/sync"
+)
+
+// conflictFieldNames 冲突比对与解决支持的字段，按展示顺序排列
+var conflictFieldNames = []string{"title", "description", "status", "priority", "due_date", "start_date", "tags"}
+
+// conflictField 单个字段在本地与远端的取值
+type conflictField struct {
+	Name    string `json:"name"`
+	Local   string `json:"local"`
+	Remote  string `json:"remote"`
+	Differs bool   `json:"differs"`
+}
+
+// taskConflict 同一任务的本地缓存版本与远端最新版本
+type taskConflict struct {
+	Local  *model.Task
+	Remote *model.Task
+	Fields []conflictField
+}
+
+// differingFields 返回两侧取值不同的字段名
+func (c *taskConflict) differingFields() []string {
+	names := make([]string, 0, len(c.Fields))
+	for _, field := range c.Fields {
+		if field.Differs {
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
+
+// loadTaskConflict 读取任务的本地版本并从来源 provider 拉取远端版本，逐字段比对。
+func (s *Server) loadTaskConflict(ctx context.Context, taskID, source string) (*taskConflict, error) {
+	local := s.findLocalTask(ctx, taskID, source)
+	if local == nil {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+	if source == "" {
+		source = string(local.Source)
+	}
+	if source == "" || source == string(model.SourceLocal) {
+		return nil, fmt.Errorf("task %s is local only and has no remote version", taskID)
+	}
+	// 远端版本不写入本地缓存，否则会覆盖待比对的本地版本
+	localCopy := cloneConflictTask(local)
+	remote, err := s.getRemoteTask(ctx, source, taskID, "", local)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote task: %w", err)
+	}
+
+	loc := resolveLocation(s.effectiveIntelligenceConfig().Timezone)
+	conflict := &taskConflict{Local: localCopy, Remote: remote}
+	for _, name := range conflictFieldNames {
+		localValue := conflictFieldValue(localCopy, name, loc)
+		remoteValue := conflictFieldValue(remote, name, loc)
+		conflict.Fields = append(conflict.Fields, conflictField{
+			Name:    name,
+			Local:   localValue,
+			Remote:  remoteValue,
+			Differs: localValue != remoteValue,
+		})
+	}
+	return conflict, nil
+}
+
+// conflictFieldValue 将字段格式化为便于比对与展示的文本
+func conflictFieldValue(task *model.Task, field string, loc *time.Location) string {
+	formatTime := func(t *time.Time) string {
+		if t == nil || t.IsZero() {
+			return ""
+		}
+		return t.In(loc).Format("2006-01-02 15:04")
+	}
+	switch field {
+	case "title":
+		return strings.TrimSpace(task.Title)
+	case "description":
+		return strings.TrimSpace(task.Description)
+	case "status":
+		return string(task.Status)
+	case "priority":
+		return fmt.Sprintf("%d", task.Priority)
+	case "due_date":
+		return formatTime(task.DueDate)
+	case "start_date":
+		return formatTime(task.StartDate)
+	case "tags":
+		return strings.Join(task.Tags, ", ")
+	default:
+		return ""
+	}
+}
+
+// applyConflictField 将 src 的字段值复制到 dst
+func applyConflictField(dst, src *model.Task, field string) {
+	switch field {
+	case "title":
+		dst.Title = src.Title
+	case "description":
+		dst.Description = src.Description
+	case "status":
+		dst.Status = src.Status
+		dst.CompletedAt = src.CompletedAt
+	case "priority":
+		dst.Priority = src.Priority
+	case "due_date":
+		dst.DueDate = src.DueDate
+	case "start_date":
+		dst.StartDate = src.StartDate
+	case "tags":
+		dst.Tags = append([]string(nil), src.Tags...)
+	}
+}
+
+// cloneConflictTask 复制任务，标签切片单独拷贝
+func cloneConflictTask(task *model.Task) *model.Task {
+	clone := *task
+	clone.Tags = append([]string(nil), task.Tags...)
+	return &clone
+}
+
+// handleResolveConflictPrompt 处理冲突解决提示词请求，并排展示任务的本地与远端版本。
+func (s *Server) handleResolveConflictPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	var args map[string]string
+	if req != nil && req.Params != nil {
+		args = req.Params.Arguments
+	}
+	taskID := strings.TrimSpace(args["task_id"])
+	if taskID == "" {
+		return nil, fmt.Errorf("task_id is required")
+	}
+	source, err := resolveProviderNameStrict(args["source"])
+	if err != nil {
+		return nil, err
+	}
+	conflict, err := s.loadTaskConflict(ctx, taskID, source)
+	if err != nil {
+		return nil, err
+	}
+
+	cell := func(value string) string {
+		if value == "" {
+			return "（空）"
+		}
+		return strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>").Replace(value)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## 冲突任务\n\n- 任务 ID: %s\n- 来源: %s\n", conflict.Local.ID, conflict.Remote.Source)
+	if !conflict.Local.UpdatedAt.IsZero() {
+		fmt.Fprintf(&b, "- 本地更新时间: %s\n", conflict.Local.UpdatedAt.Format(time.RFC3339))
+	}
+	if !conflict.Remote.UpdatedAt.IsZero() {
+		fmt.Fprintf(&b, "- 远端更新时间: %s\n", conflict.Remote.UpdatedAt.Format(time.RFC3339))
+	}
+	b.WriteString("\n| 字段 | 本地版本 | 远端版本 | 是否不同 |\n| --- | --- | --- | --- |\n")
+	for _, field := range conflict.Fields {
+		mark := ""
+		if field.Differs {
+			mark = "⚠️ 不同"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", field.Name, cell(field.Local), cell(field.Remote), mark)
+	}
+	b.WriteString("\n")
+	if differing := conflict.differingFields(); len(differing) == 0 {
+		b.WriteString("两侧版本一致，无需解决冲突。\n\n")
+	} else {
+		fmt.Fprintf(&b, "存在差异的字段: %s\n\n", strings.Join(differing, ", "))
+	}
+	b.WriteString(EmbeddedPrompts["resolve_conflict"])
+
+	return promptMessageResult("冲突解决提示词", b.String()), nil
+}
+
+// handleResolveConflict 按字段选择本地或远端取值合并任务，保存到本地并回写远端。
+func (s *Server) handleResolveConflict(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	if getString(rawArgs, "left") != "" || getString(rawArgs, "right") != "" {
+		return s.handleResolveMirrorConflict(ctx, rawArgs)
+	}
+	taskID := getString(rawArgs, "task_id")
+	if taskID == "" {
+		return nil, fmt.Errorf("task_id is required (or left and right for mirror conflicts)")
+	}
+	source, err := resolveProviderNameStrict(getString(rawArgs, "source"))
+	if err != nil {
+		return nil, err
+	}
+	keep := strings.ToLower(getString(rawArgs, "keep"))
+	if keep == "" {
+		keep = "local"
+	}
+	if keep != "local" && keep != "remote" {
+		return nil, fmt.Errorf("invalid keep: %s (expected local or remote)", keep)
+	}
+	choices := make(map[string]string)
+	if raw, ok := rawArgs["fields"]; ok && len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &choices); err != nil {
+			return nil, fmt.Errorf("invalid fields: %w", err)
+		}
+	}
+	known := make(map[string]bool, len(conflictFieldNames))
+	for _, name := range conflictFieldNames {
+		known[name] = true
+	}
+	for field, choice := range choices {
+		if !known[field] {
+			return nil, fmt.Errorf("unsupported field: %s", field)
+		}
+		choice = strings.ToLower(strings.TrimSpace(choice))
+		if choice != "local" && choice != "remote" {
+			return nil, fmt.Errorf("invalid choice for %s: %s (expected local or remote)", field, choice)
+		}
+		choices[field] = choice
+	}
+	dryRun, _ := getBool(rawArgs, "dry_run")
+
+	conflict, err := s.loadTaskConflict(ctx, taskID, source)
+	if err != nil {
+		return nil, err
+	}
+	merged := cloneConflictTask(conflict.Local)
+	applied := make(map[string]string, len(conflictFieldNames))
+	for _, field := range conflict.Fields {
+		choice := keep
+		if value, ok := choices[field.Name]; ok {
+			choice = value
+		}
+		if choice == "remote" {
+			applyConflictField(merged, conflict.Remote, field.Name)
+		}
+		if field.Differs {
+			applied[field.Name] = choice
+		}
+	}
+
+	payload := map[string]interface{}{
+		"success": true,
+		"task_id": merged.ID,
+		"dry_run": dryRun,
+		"choices": applied,
+		"task":    merged,
+	}
+	if dryRun {
+		return conflictResult(payload)
+	}
+
+	merged.UpdatedAt = time.Now()
+	if err := s.taskStore.SaveTask(ctx, merged); err != nil {
+		return nil, fmt.Errorf("failed to save task: %w", err)
+	}
+	// 冲突已处理，离线队列中该任务的写入不再重放
+	if s.writeQueue != nil {
+		if err := s.writeQueue.dropTask(merged.ID); err != nil {
+			return nil, fmt.Errorf("failed to update write queue: %w", err)
+		}
+	}
+
+	// 合并结果与远端一致时无需回写
+	loc := resolveLocation(s.effectiveIntelligenceConfig().Timezone)
+	needsPush := false
+	for _, name := range conflictFieldNames {
+		if conflictFieldValue(merged, name, loc) != conflictFieldValue(conflict.Remote, name, loc) {
+			needsPush = true
+			break
+		}
+	}
+	remoteStatus := "unchanged"
+	if needsPush {
+		p, ok := s.lookupProvider(ctx, string(conflict.Remote.Source))
+		switch {
+		case !ok || p == nil || !p.IsAuthenticated():
+			remoteStatus = "skipped"
+			payload["remote_error"] = fmt.Sprintf("provider %s not available", conflict.Remote.Source)
+		default:
+			remoteTask := sanitizeTaskForRemote(*merged)
+			if _, err := p.UpdateTask(ctx, merged.ListID, &remoteTask); err != nil {
+				remoteStatus = "failed"
+				payload["remote_error"] = err.Error()
+				payload["success"] = false
+				// 刚读取过远端版本，以当前时间作为冲突检测的基准
+				if providerUnreachable(err) && s.queueWrite(ctx, withLastSync(merged, time.Now()), string(conflict.Remote.Source), queuedUpdate, merged.ListID) {
+					s.writeQueue.markOffline(string(conflict.Remote.Source), time.Now())
+					remoteStatus = "queued"
+					payload["success"] = true
+				}
+			} else {
+				remoteStatus = "updated"
+				s.markRemoteSynced(ctx, merged)
+			}
+		}
+	}
+	payload["remote"] = remoteStatus
+	return conflictResult(payload)
+}
+
+// handleResolveMirrorConflict 处理两个 Provider 镜像时排队的冲突：未指定 conflict_id 时列出等待中的冲突，
+// 未指定 keep 或 dry_run 时返回冲突两侧的字段，否则记录选择，下次镜像时应用
+func (s *Server) handleResolveMirrorConflict(ctx context.Context, rawArgs map[string]json.RawMessage) (*mcp.CallToolResult, error) {
+	if s.mirrorStore == nil {
+		return nil, fmt.Errorf("mirror state storage not available")
+	}
+	left, err := resolveProviderNameStrict(getString(rawArgs, "left"))
+	if err != nil {
+		return nil, err
+	}
+	right, err := resolveProviderNameStrict(getString(rawArgs, "right"))
+	if err != nil {
+		return nil, err
+	}
+	if left == "" || right == "" || left == right {
+		return nil, fmt.Errorf("left and right must be two different providers")
+	}
+	state, err := s.mirrorStore.LoadMirrorState(ctx, left, right)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mirror state: %w", err)
+	}
+
+	id := getString(rawArgs, "conflict_id")
+	if id == "" {
+		conflicts := make([]map[string]interface{}, 0, len(state.Conflicts))
+		for _, conflict := range state.Conflicts {
+			conflicts = append(conflicts, mirrorConflictPayload(conflict, left, right))
+		}
+		return conflictResult(map[string]interface{}{"left": left, "right": right, "count": len(conflicts), "conflicts": conflicts})
+	}
+
+	keep := strings.ToLower(getString(rawArgs, "keep"))
+	choices := make(map[string]string)
+	if raw, ok := rawArgs["fields"]; ok && len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &choices); err != nil {
+			return nil, fmt.Errorf("invalid fields: %w", err)
+		}
+	}
+	side := func(value string) string {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == tbsync.ConflictLeft || value == tbsync.ConflictRight {
+			return value
+		}
+		return provider.ResolveProviderName(value)
+	}
+	for field, choice := range choices {
+		choices[field] = side(choice)
+	}
+	dryRun, _ := getBool(rawArgs, "dry_run")
+
+	if keep == "" || dryRun {
+		for _, conflict := range state.Conflicts {
+			if conflict.ID == id {
+				payload := mirrorConflictPayload(conflict, left, right)
+				payload["dry_run"] = dryRun
+				return conflictResult(payload)
+			}
+		}
+		return nil, fmt.Errorf("conflict %s not found", id)
+	}
+
+	conflict, err := tbsync.ResolveMirrorConflictFields(ctx, s.mirrorStore, left, right, id, side(keep), choices)
+	if err != nil {
+		return nil, err
+	}
+	payload := mirrorConflictPayload(*conflict, left, right)
+	payload["success"] = true
+	return conflictResult(payload)
+}
+
+// mirrorConflictPayload 镜像冲突的 JSON 表示，逐字段列出两侧的取值
+func mirrorConflictPayload(conflict tbsync.MirrorConflict, left, right string) map[string]interface{} {
+	differing := conflict.DifferingFields(left, right)
+	fields := make([]map[string]interface{}, 0, len(tbsync.MirrorConflictFields))
+	for _, name := range tbsync.MirrorConflictFields {
+		field := map[string]interface{}{"name": name, "differs": slices.Contains(differing, name)}
+		for _, side := range []string{left, right} {
+			if task, ok := conflict.Tasks[side]; ok {
+				field[side] = task.Fields.FieldValue(name)
+			}
+		}
+		fields = append(fields, field)
+	}
+	deleted := make([]string, 0, 1)
+	for _, side := range []string{left, right} {
+		if _, ok := conflict.Tasks[side]; !ok {
+			deleted = append(deleted, side)
+		}
+	}
+	payload := map[string]interface{}{
+		"conflict_id": conflict.ID,
+		"left":        left,
+		"right":       right,
+		"detected_at": conflict.DetectedAt,
+		"fields":      fields,
+	}
+	if len(deleted) > 0 {
+		payload["deleted"] = deleted
+	}
+	if conflict.Resolution != "" {
+		payload["resolution"] = conflict.Resolution
+	}
+	if len(conflict.Fields) > 0 {
+		payload["field_choices"] = conflict.Fields
+	}
+	return payload
+}
+
+// conflictResult 构造 resolve_conflict 的 JSON 结果
+func conflictResult(payload map[string]interface{}) (*mcp.CallToolResult, error) {
+	text, err := toJSON(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}, nil
+}
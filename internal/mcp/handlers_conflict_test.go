@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+	tbsync "github.com/yeisme/taskbridge/internal/sync"
+)
+
+type conflictProvider struct {
+	mockProvider
+	remote  model.Task
+	updated []model.Task
+}
+
+func (p *conflictProvider) GetTask(ctx context.Context, listID, taskID string) (*model.Task, error) {
+	cp := p.remote
+	return &cp, nil
+}
+
+func (p *conflictProvider) UpdateTask(ctx context.Context, listID string, task *model.Task) (*model.Task, error) {
+	p.updated = append(p.updated, *task)
+	return task, nil
+}
+
+func newConflictTestServer(t *testing.T) (*Server, *conflictProvider, *filestore.FileStorage) {
+	t.Helper()
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	localDue := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	remoteDue := time.Date(2026, 3, 5, 9, 0, 0, 0, time.Local)
+	local := &model.Task{
+		ID: "google-l1-r1", Title: "提交季度报告", Description: "本地补充的说明", Status: model.StatusTodo,
+		Priority: model.PriorityHigh, DueDate: &localDue, Source: model.SourceGoogle, SourceRawID: "r1", ListID: "l1",
+	}
+	if err := store.SaveTask(context.Background(), local); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+	remote := &conflictProvider{remote: model.Task{
+		ID: "r1", Title: "提交 Q1 报告", Description: "本地补充的说明", Status: model.StatusInProgress,
+		Priority: model.PriorityHigh, DueDate: &remoteDue, Source: model.SourceGoogle, SourceRawID: "r1", ListID: "l1",
+	}}
+	s := NewServer(WithTaskStorage(store), WithProviders(map[string]provider.Provider{"google": remote}))
+	return s, remote, store
+}
+
+func TestResolveConflictPromptRendersBothVersions(t *testing.T) {
+	s, _, _ := newConflictTestServer(t)
+	session := connectBreakdownClient(t, s, nil)
+	res, err := session.GetPrompt(context.Background(), &sdkmcp.GetPromptParams{
+		Name:      "resolve_conflict",
+		Arguments: map[string]string{"task_id": "google-l1-r1"},
+	})
+	if err != nil {
+		t.Fatalf("get prompt: %v", err)
+	}
+	text := res.Messages[0].Content.(*sdkmcp.TextContent).Text
+	for _, want := range []string{"| title | 提交季度报告 | 提交 Q1 报告 | ⚠️ 不同 |", "存在差异的字段: title, status, due_date", "`resolve_conflict`"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("prompt missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "| description | 本地补充的说明 | 本地补充的说明 | ⚠️") {
+		t.Fatalf("identical fields should not be marked as different:\n%s", text)
+	}
+}
+
+func TestResolveConflictMergesFields(t *testing.T) {
+	s, remote, store := newConflictTestServer(t)
+	ctx := context.Background()
+
+	res, err := s.handleResolveConflict(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id": "google-l1-r1",
+		"keep":    "remote",
+		"fields":  map[string]interface{}{"due_date": "local"},
+		"dry_run": true,
+	}))
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	choices := out["choices"].(map[string]interface{})
+	if choices["title"] != "remote" || choices["status"] != "remote" || choices["due_date"] != "local" {
+		t.Fatalf("unexpected choices: %v", choices)
+	}
+	if len(remote.updated) != 0 {
+		t.Fatalf("dry run should not update remote")
+	}
+
+	res, err = s.handleResolveConflict(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id": "google-l1-r1",
+		"keep":    "remote",
+		"fields":  map[string]interface{}{"due_date": "local"},
+	}))
+	if err != nil {
+		t.Fatalf("resolve conflict: %v", err)
+	}
+	if out := parseJSONResult(t, res); out["remote"] != "updated" {
+		t.Fatalf("expected remote updated, got %v", out)
+	}
+	saved, err := store.GetTask(ctx, "google-l1-r1")
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if saved.Title != "提交 Q1 报告" || saved.Status != model.StatusInProgress || saved.DueDate.Day() != 1 {
+		t.Fatalf("unexpected merged task: %+v", saved)
+	}
+	if len(remote.updated) != 1 || remote.updated[0].DueDate.Day() != 1 {
+		t.Fatalf("expected merged due date pushed to remote: %+v", remote.updated)
+	}
+
+	if _, err := s.handleResolveConflict(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id": "google-l1-r1",
+		"fields":  map[string]interface{}{"owner": "local"},
+	})); err == nil {
+		t.Fatalf("expected error for unsupported field")
+	}
+}
+
+func TestResolveConflictMirrorQueue(t *testing.T) {
+	s, _, _ := newConflictTestServer(t)
+	ctx := context.Background()
+	mirrorStore, err := tbsync.NewFileMirrorStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("mirror store: %v", err)
+	}
+	s.mirrorStore = mirrorStore
+	state := &tbsync.MirrorState{Conflicts: []tbsync.MirrorConflict{{
+		ID: "c1",
+		Tasks: map[string]tbsync.MirrorConflictTask{
+			"google":  {TaskID: "g1", Fields: tbsync.MirrorFields{Title: "报告", Description: "左侧说明", Priority: model.PriorityHigh}},
+			"todoist": {TaskID: "t1", Fields: tbsync.MirrorFields{Title: "报告", Description: "右侧说明", Priority: model.PriorityLow}},
+		},
+	}}}
+	if err := mirrorStore.SaveMirrorState(ctx, "google", "todoist", state); err != nil {
+		t.Fatalf("seed state: %v", err)
+	}
+
+	res, err := s.handleResolveConflict(ctx, buildCallToolRequest(t, map[string]interface{}{"left": "google", "right": "todoist"}))
+	if err != nil {
+		t.Fatalf("list mirror conflicts: %v", err)
+	}
+	listed := parseJSONResult(t, res)
+	if listed["count"].(float64) != 1 {
+		t.Fatalf("expected one queued conflict, got %v", listed)
+	}
+
+	res, err = s.handleResolveConflict(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"left": "google", "right": "todoist", "conflict_id": "c1", "keep": "left", "fields": map[string]string{"priority": "todoist"},
+	}))
+	if err != nil {
+		t.Fatalf("resolve mirror conflict: %v", err)
+	}
+	payload := parseJSONResult(t, res)
+	if payload["resolution"] != "google" || payload["field_choices"].(map[string]interface{})["priority"] != "todoist" {
+		t.Fatalf("unexpected resolution: %v", payload)
+	}
+	saved, err := mirrorStore.LoadMirrorState(ctx, "google", "todoist")
+	if err != nil || saved.Conflicts[0].Resolution != "google" || saved.Conflicts[0].Fields["priority"] != "todoist" {
+		t.Fatalf("resolution should be persisted: %+v (%v)", saved, err)
+	}
+
+	if _, err := s.handleResolveConflict(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"left": "google", "right": "todoist", "conflict_id": "c1", "keep": "left", "fields": map[string]string{"due_date": "todoist"},
+	})); err == nil {
+		t.Fatal("task conflict field names are not valid for mirror conflicts")
+	}
+}
@@ -0,0 +1,253 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionDefaults 单个 MCP 会话的默认参数，后续工具调用省略对应参数时自动补全
+type sessionDefaults struct {
+	Adapter  string `json:"adapter,omitempty"`
+	Project  string `json:"project,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// sessionContextState 按会话保存的默认参数；会话结束后自动清理
+type sessionContextState struct {
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]*sessionDefaults
+}
+
+// sessionDefaultArgs 默认参数对应的工具参数名，按优先级排列；工具声明了其中任一参数且调用方均未传入时补全第一个
+var sessionDefaultArgs = []struct {
+	field string
+	args  []string
+}{
+	{"adapter", []string{"adapter", "provider", "source"}},
+	{"project", []string{"project", "project_id"}},
+	{"timezone", []string{"timezone"}},
+}
+
+// sessionContextTools 自身管理会话上下文、不需要补全默认参数的工具
+var sessionContextTools = map[string]bool{
+	"set_context": true,
+	"get_context": true,
+}
+
+// sessionContext 返回会话当前的默认参数副本
+func (s *Server) sessionContext(session *mcp.ServerSession) sessionDefaults {
+	if session == nil {
+		return sessionDefaults{}
+	}
+	s.sessionContexts.mu.Lock()
+	defer s.sessionContexts.mu.Unlock()
+	if current := s.sessionContexts.sessions[session]; current != nil {
+		return *current
+	}
+	return sessionDefaults{}
+}
+
+// storeSessionContext 保存会话默认参数；首次保存时在会话结束后清理
+func (s *Server) storeSessionContext(session *mcp.ServerSession, defaults sessionDefaults) {
+	s.sessionContexts.mu.Lock()
+	defer s.sessionContexts.mu.Unlock()
+	if s.sessionContexts.sessions == nil {
+		s.sessionContexts.sessions = make(map[*mcp.ServerSession]*sessionDefaults)
+	}
+	if _, exists := s.sessionContexts.sessions[session]; !exists {
+		go func() {
+			_ = session.Wait()
+			s.sessionContexts.mu.Lock()
+			delete(s.sessionContexts.sessions, session)
+			s.sessionContexts.mu.Unlock()
+		}()
+	}
+	s.sessionContexts.sessions[session] = &defaults
+}
+
+// withSessionDefaults 包装工具处理器：按工具 schema 声明的参数，将会话默认值补全到未传入的参数中。
+func (s *Server) withSessionDefaults(tool *mcp.Tool, handler mcp.ToolHandler) mcp.ToolHandler {
+	if sessionContextTools[tool.Name] {
+		return handler
+	}
+	properties := schemaProperties(tool.InputSchema)
+	targets := make(map[string]string)
+	for _, item := range sessionDefaultArgs {
+		for _, arg := range item.args {
+			if properties[arg] {
+				targets[item.field] = arg
+				break
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return handler
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if req == nil || req.Params == nil {
+			return handler(ctx, req)
+		}
+		defaults := s.sessionContext(req.Session)
+		values := map[string]string{"adapter": defaults.Adapter, "project": defaults.Project, "timezone": defaults.Timezone}
+		var rawArgs map[string]json.RawMessage
+		if len(req.Params.Arguments) > 0 {
+			if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+				// 参数格式由工具自身报错
+				return handler(ctx, req)
+			}
+		}
+		if rawArgs == nil {
+			rawArgs = make(map[string]json.RawMessage)
+		}
+		changed := false
+		for _, item := range sessionDefaultArgs {
+			arg, ok := targets[item.field]
+			if !ok || values[item.field] == "" || argsProvided(rawArgs, item.args) {
+				continue
+			}
+			encoded, err := json.Marshal(values[item.field])
+			if err != nil {
+				return nil, err
+			}
+			rawArgs[arg] = encoded
+			changed = true
+		}
+		if changed {
+			data, err := json.Marshal(rawArgs)
+			if err != nil {
+				return nil, err
+			}
+			req.Params.Arguments = data
+		}
+		return handler(ctx, req)
+	}
+}
+
+// argsProvided 判断调用方是否传入了任一参数（null 视为未传入）
+func argsProvided(rawArgs map[string]json.RawMessage, keys []string) bool {
+	for _, key := range keys {
+		if value, ok := rawArgs[key]; ok && len(value) > 0 && string(value) != "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaProperties 返回对象 schema 声明的参数名
+func schemaProperties(schema any) map[string]bool {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	var decoded struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil
+	}
+	properties := make(map[string]bool, len(decoded.Properties))
+	for name := range decoded.Properties {
+		properties[name] = true
+	}
+	return properties
+}
+
+// handleSetContext 设置当前会话的默认 adapter、项目与时区；传空字符串清除对应字段，clear=true 清除全部。
+func (s *Server) handleSetContext(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if req == nil || req.Session == nil {
+		return nil, fmt.Errorf("session context requires an MCP session")
+	}
+	var rawArgs map[string]json.RawMessage
+	if req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	defaults := s.sessionContext(req.Session)
+	if clear, ok := getBool(rawArgs, "clear"); ok && clear {
+		defaults = sessionDefaults{}
+	}
+	if _, ok := rawArgs["adapter"]; ok {
+		adapter, err := resolveProviderNameStrict(getString(rawArgs, "adapter"))
+		if err != nil {
+			return nil, err
+		}
+		if adapter != "" {
+			if _, configured := s.lookupProvider(ctx, adapter); !configured {
+				return nil, fmt.Errorf("provider not configured: %s", adapter)
+			}
+		}
+		defaults.Adapter = adapter
+	}
+	if _, ok := rawArgs["project"]; ok {
+		project, err := s.resolveContextProject(ctx, getString(rawArgs, "project"))
+		if err != nil {
+			return nil, err
+		}
+		defaults.Project = project
+	}
+	if _, ok := rawArgs["timezone"]; ok {
+		timezone := getString(rawArgs, "timezone")
+		if timezone != "" {
+			if _, err := time.LoadLocation(timezone); err != nil {
+				return nil, fmt.Errorf("invalid timezone: %s", timezone)
+			}
+		}
+		defaults.Timezone = timezone
+	}
+	s.storeSessionContext(req.Session, defaults)
+
+	text, err := toJSON(map[string]interface{}{
+		"success": true,
+		"context": defaults,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}, nil
+}
+
+// handleGetContext 返回当前会话的默认参数
+func (s *Server) handleGetContext(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	_ = ctx
+	var session *mcp.ServerSession
+	if req != nil {
+		session = req.Session
+	}
+	text, err := toJSON(map[string]interface{}{
+		"context": s.sessionContext(session),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}, nil
+}
+
+// resolveContextProject 将项目 ID 或名称解析为项目 ID；没有项目存储时原样保存
+func (s *Server) resolveContextProject(ctx context.Context, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || s.projectStore == nil {
+		return value, nil
+	}
+	names := s.projectNameIndex(ctx)
+	if _, ok := names[value]; ok {
+		return value, nil
+	}
+	for id, name := range names {
+		if strings.EqualFold(strings.TrimSpace(name), value) {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("project not found: %s", value)
+}
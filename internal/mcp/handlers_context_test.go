@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+func listedTaskIDs(t *testing.T, session *sdkmcp.ClientSession, args map[string]any) []string {
+	t.Helper()
+	// 传入 cursor 以获得带 meta 的分页结果
+	paged := map[string]any{"cursor": ""}
+	for key, value := range args {
+		paged[key] = value
+	}
+	res, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: "list_tasks", Arguments: paged})
+	if err != nil {
+		t.Fatalf("call list_tasks: %v", err)
+	}
+	tasks, _ := parseJSONResult(t, res)["tasks"].([]interface{})
+	ids := make([]string, 0, len(tasks))
+	for _, item := range tasks {
+		ids = append(ids, item.(map[string]interface{})["id"].(string))
+	}
+	return ids
+}
+
+func TestSessionContextDefaultsArguments(t *testing.T) {
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	ctx := context.Background()
+	for _, task := range []*model.Task{
+		{ID: "g1", Title: "google task", Status: model.StatusTodo, Source: model.SourceGoogle},
+		{ID: "t1", Title: "todoist task", Status: model.StatusTodo, Source: model.SourceTodoist},
+	} {
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+	s := NewServer(WithTaskStorage(store), WithProviders(map[string]provider.Provider{
+		"google":  &mockProvider{},
+		"todoist": &mockProvider{},
+	}))
+	first := connectBreakdownClient(t, s, nil)
+	second := connectBreakdownClient(t, s, nil)
+
+	res, err := first.CallTool(ctx, &sdkmcp.CallToolParams{Name: "set_context", Arguments: map[string]any{"adapter": "g", "timezone": "Asia/Shanghai"}})
+	if err != nil || res.IsError {
+		t.Fatalf("set_context failed: %v %+v", err, res)
+	}
+	got := parseJSONResult(t, res)["context"].(map[string]interface{})
+	if got["adapter"] != "google" || got["timezone"] != "Asia/Shanghai" {
+		t.Fatalf("unexpected context: %v", got)
+	}
+
+	if ids := listedTaskIDs(t, first, nil); len(ids) != 1 || ids[0] != "g1" {
+		t.Fatalf("expected session default adapter applied, got %v", ids)
+	}
+	if ids := listedTaskIDs(t, first, map[string]any{"source": "todoist"}); len(ids) != 1 || ids[0] != "t1" {
+		t.Fatalf("explicit arguments should override session defaults, got %v", ids)
+	}
+	if ids := listedTaskIDs(t, second, nil); len(ids) != 2 {
+		t.Fatalf("other sessions should not inherit defaults, got %v", ids)
+	}
+
+	res, err = second.CallTool(ctx, &sdkmcp.CallToolParams{Name: "get_context"})
+	if err != nil {
+		t.Fatalf("get_context: %v", err)
+	}
+	if got := parseJSONResult(t, res)["context"].(map[string]interface{}); len(got) != 0 {
+		t.Fatalf("expected empty context for second session, got %v", got)
+	}
+
+	// 清除单项后不再补全
+	if _, err := first.CallTool(ctx, &sdkmcp.CallToolParams{Name: "set_context", Arguments: map[string]any{"adapter": ""}}); err != nil {
+		t.Fatalf("clear adapter: %v", err)
+	}
+	if ids := listedTaskIDs(t, first, nil); len(ids) != 2 {
+		t.Fatalf("expected adapter default cleared, got %v", ids)
+	}
+	res, _ = first.CallTool(ctx, &sdkmcp.CallToolParams{Name: "get_context"})
+	if got := parseJSONResult(t, res)["context"].(map[string]interface{}); got["timezone"] != "Asia/Shanghai" || got["adapter"] != nil {
+		t.Fatalf("unexpected context after clearing adapter: %v", got)
+	}
+}
+
+func TestSetContextValidatesValues(t *testing.T) {
+	s := NewServer(WithProviders(map[string]provider.Provider{"google": &mockProvider{}}))
+	session := connectBreakdownClient(t, s, nil)
+	for _, args := range []map[string]any{
+		{"adapter": "todoist"},
+		{"adapter": "unknown"},
+		{"timezone": "Mars/Base"},
+	} {
+		res, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: "set_context", Arguments: args})
+		if err == nil && !res.IsError {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+const (
+	defaultDuplicateThreshold     = 0.8
+	defaultDuplicateDueWindowDays = 1
+)
+
+type duplicateCandidate struct {
+	TaskID   string     `json:"task_id"`
+	Title    string     `json:"title"`
+	Source   string     `json:"source"`
+	ListID   string     `json:"list_id,omitempty"`
+	ListName string     `json:"list_name,omitempty"`
+	Status   string     `json:"status"`
+	DueDate  *time.Time `json:"due_date,omitempty"`
+}
+
+type duplicateGroup struct {
+	Similarity      float64              `json:"similarity"`
+	CrossSource     bool                 `json:"cross_source"`
+	SuggestedKeepID string               `json:"suggested_keep_id"`
+	Tasks           []duplicateCandidate `json:"tasks"`
+}
+
+// handleFindDuplicates 检测疑似重复任务：标题模糊匹配且截止日期相近。
+func (s *Server) handleFindDuplicates(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: `{"group_count":0,"groups":[]}`}},
+		}, nil
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	threshold := defaultDuplicateThreshold
+	if raw, ok := rawArgs["threshold"]; ok {
+		var v float64
+		if err := json.Unmarshal(raw, &v); err == nil && v > 0 && v <= 1 {
+			threshold = v
+		}
+	}
+	dueWindowDays, ok := getInt(rawArgs, "due_window_days")
+	if !ok || dueWindowDays < 0 {
+		dueWindowDays = defaultDuplicateDueWindowDays
+	}
+	includeCompleted, _ := getBool(rawArgs, "include_completed")
+
+	query := storage.Query{ListIDs: getStringSlice(rawArgs, "list_id")}
+	for _, source := range getStringSlice(rawArgs, "source") {
+		resolved, err := resolveProviderNameStrict(source)
+		if err != nil {
+			return nil, err
+		}
+		query.Sources = append(query.Sources, model.TaskSource(resolved))
+	}
+	if !includeCompleted {
+		query.Statuses = []model.TaskStatus{model.StatusTodo, model.StatusInProgress, model.StatusDeferred}
+	}
+
+	tasks, err := s.taskStore.QueryTasks(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	groups := findDuplicateGroups(tasks, threshold, dueWindowDays)
+	payload := map[string]interface{}{
+		"group_count":     len(groups),
+		"scanned":         len(tasks),
+		"threshold":       threshold,
+		"due_window_days": dueWindowDays,
+		"groups":          groups,
+	}
+	jsonResult, err := toJSON(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// handleMergeTasks 将重复任务合并到主任务：合并描述、标签与子任务，其余任务标记为已合并。
+// 来自 provider 的任务同时回写远端：主任务更新合并后的内容，重复任务在远端完成或删除
+func (s *Server) handleMergeTasks(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var params struct {
+		PrimaryID        string   `json:"primary_id"`
+		DuplicateIDs     []string `json:"duplicate_ids"`
+		DeleteDuplicates bool     `json:"delete_duplicates"`
+		DryRun           bool     `json:"dry_run"`
+	}
+	if req == nil || req.Params == nil || len(req.Params.Arguments) == 0 {
+		return nil, fmt.Errorf("primary_id is required")
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	params.PrimaryID = strings.TrimSpace(params.PrimaryID)
+	if params.PrimaryID == "" {
+		return nil, fmt.Errorf("primary_id is required")
+	}
+	if len(params.DuplicateIDs) == 0 {
+		return nil, fmt.Errorf("duplicate_ids is required")
+	}
+
+	primary, err := s.taskStore.GetTask(ctx, params.PrimaryID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+
+	now := time.Now()
+	duplicates := make([]*model.Task, 0, len(params.DuplicateIDs))
+	errs := []string{}
+	for _, rawID := range params.DuplicateIDs {
+		id := strings.TrimSpace(rawID)
+		if id == "" || id == primary.ID {
+			continue
+		}
+		dup, err := s.taskStore.GetTask(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("task not found: %s", id))
+			continue
+		}
+		mergeTaskInto(primary, dup)
+		duplicates = append(duplicates, dup)
+	}
+	if len(duplicates) == 0 {
+		return nil, fmt.Errorf("no duplicate tasks to merge")
+	}
+	primary.UpdatedAt = now
+
+	merged := make([]string, 0, len(duplicates))
+	for _, dup := range duplicates {
+		merged = append(merged, dup.ID)
+	}
+	result := map[string]interface{}{
+		"primary":           primary,
+		"merged_ids":        merged,
+		"delete_duplicates": params.DeleteDuplicates,
+		"dry_run":           params.DryRun,
+		"errors":            errs,
+	}
+	if params.DryRun {
+		jsonResult, err := toJSON(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal result: %w", err)
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+	}
+
+	if err := s.taskStore.SaveTask(ctx, primary); err != nil {
+		return nil, fmt.Errorf("failed to save task: %w", err)
+	}
+	remote := map[string]string{}
+	remote[primary.ID], _ = s.pushTaskWrite(ctx, primary, queuedUpdate)
+	for _, dup := range duplicates {
+		if params.DeleteDuplicates {
+			// 先删除远端任务，失败时保留本地任务以便重试，避免远端留下无人管理的重复任务
+			status, note := s.deleteRemoteTask(ctx, dup)
+			remote[dup.ID] = status
+			if status == "failed" {
+				errs = append(errs, fmt.Sprintf("delete %s on %s failed: %s", dup.ID, dup.Source, note))
+				continue
+			}
+			if err := s.taskStore.DeleteTask(ctx, dup.ID); err != nil {
+				errs = append(errs, fmt.Sprintf("delete %s failed: %v", dup.ID, err))
+			}
+			continue
+		}
+		// 默认保留重复任务并标记为已完成，记录合并去向便于追溯；远端任务同时完成
+		dup.Status = model.StatusCompleted
+		dup.CompletedAt = &now
+		dup.UpdatedAt = now
+		if dup.Metadata == nil {
+			dup.Metadata = &model.TaskMetadata{Version: "1.0"}
+		}
+		if dup.Metadata.CustomFields == nil {
+			dup.Metadata.CustomFields = map[string]interface{}{}
+		}
+		dup.Metadata.CustomFields["tb_merged_into"] = primary.ID
+		dup.Metadata.CustomFields["tb_merged_at"] = now.Format(time.RFC3339)
+		if err := s.taskStore.SaveTask(ctx, dup); err != nil {
+			errs = append(errs, fmt.Sprintf("mark %s merged failed: %v", dup.ID, err))
+			continue
+		}
+		status, note := s.pushTaskWrite(ctx, dup, queuedComplete)
+		remote[dup.ID] = status
+		if status == "failed" {
+			errs = append(errs, fmt.Sprintf("complete %s on %s failed: %s", dup.ID, dup.Source, note))
+		}
+	}
+	result["remote"] = remote
+	result["errors"] = errs
+
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// findDuplicateGroups 两两比较任务并用并查集聚合为重复组。
+func findDuplicateGroups(tasks []model.Task, threshold float64, dueWindowDays int) []duplicateGroup {
+	parent := make([]int, len(tasks))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	normalized := make([]string, len(tasks))
+	for i, task := range tasks {
+		normalized[i] = normalizeTitleForMatch(task.Title)
+	}
+
+	bestScore := make(map[int]float64)
+	for i := 0; i < len(tasks); i++ {
+		if normalized[i] == "" {
+			continue
+		}
+		for j := i + 1; j < len(tasks); j++ {
+			if normalized[j] == "" || !dueDatesClose(tasks[i].DueDate, tasks[j].DueDate, dueWindowDays) {
+				continue
+			}
+			score := titleSimilarity(normalized[i], normalized[j])
+			if score < threshold {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			if ri != rj {
+				parent[rj] = ri
+			}
+			root := find(i)
+			if score > bestScore[root] {
+				bestScore[root] = score
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for i := range tasks {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+
+	groups := make([]duplicateGroup, 0)
+	for root, idxs := range members {
+		if len(idxs) < 2 {
+			continue
+		}
+		// 合并过程中根节点可能变化，取组内任一成员记录的最高分。
+		score := bestScore[root]
+		for _, idx := range idxs {
+			if bestScore[idx] > score {
+				score = bestScore[idx]
+			}
+		}
+		group := duplicateGroup{Similarity: math.Round(score*100) / 100}
+		sources := make(map[model.TaskSource]bool)
+		keep := tasks[idxs[0]]
+		for _, idx := range idxs {
+			task := tasks[idx]
+			sources[task.Source] = true
+			if preferAsMergeTarget(task, keep) {
+				keep = task
+			}
+			group.Tasks = append(group.Tasks, duplicateCandidate{
+				TaskID:   task.ID,
+				Title:    task.Title,
+				Source:   string(task.Source),
+				ListID:   task.ListID,
+				ListName: task.ListName,
+				Status:   string(task.Status),
+				DueDate:  task.DueDate,
+			})
+		}
+		group.CrossSource = len(sources) > 1
+		group.SuggestedKeepID = keep.ID
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Similarity == groups[j].Similarity {
+			return groups[i].SuggestedKeepID < groups[j].SuggestedKeepID
+		}
+		return groups[i].Similarity > groups[j].Similarity
+	})
+	return groups
+}
+
+// preferAsMergeTarget 优先保留已同步到远端、信息更完整、更早创建的任务。
+func preferAsMergeTarget(candidate, current model.Task) bool {
+	candidateRemote := strings.TrimSpace(candidate.SourceRawID) != ""
+	currentRemote := strings.TrimSpace(current.SourceRawID) != ""
+	if candidateRemote != currentRemote {
+		return candidateRemote
+	}
+	if len(candidate.Description) != len(current.Description) {
+		return len(candidate.Description) > len(current.Description)
+	}
+	return candidate.CreatedAt.Before(current.CreatedAt)
+}
+
+// mergeTaskInto 将 dup 的信息合并到 primary，保留 primary 已有字段。
+func mergeTaskInto(primary, dup *model.Task) {
+	desc := strings.TrimSpace(dup.Description)
+	if desc != "" && !strings.Contains(primary.Description, desc) {
+		if strings.TrimSpace(primary.Description) == "" {
+			primary.Description = desc
+		} else {
+			primary.Description = strings.TrimSpace(primary.Description) + "\n\n" + desc
+		}
+	}
+	for _, tag := range dup.Tags {
+		if !containsFold(primary.Tags, tag) {
+			primary.Tags = append(primary.Tags, tag)
+		}
+	}
+	for _, subtaskID := range dup.SubtaskIDs {
+		if !containsFold(primary.SubtaskIDs, subtaskID) {
+			primary.SubtaskIDs = append(primary.SubtaskIDs, subtaskID)
+		}
+	}
+	if primary.DueDate == nil || (dup.DueDate != nil && dup.DueDate.Before(*primary.DueDate)) {
+		primary.DueDate = dup.DueDate
+	}
+	if dup.Priority > primary.Priority {
+		primary.Priority = dup.Priority
+	}
+	if primary.EstimatedMinutes == 0 {
+		primary.EstimatedMinutes = dup.EstimatedMinutes
+	}
+	primary.ActualMinutes += dup.ActualMinutes
+}
+
+// normalizeTitleForMatch 统一大小写，去掉 Markdown 装饰与标点，便于模糊匹配。
+func normalizeTitleForMatch(title string) string {
+	text := strings.ToLower(sanitizeMarkdownText(title))
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// titleSimilarity 基于字符二元组的 Dice 系数，兼顾中英文标题。
+func titleSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ga, gb := runeBigrams(a), runeBigrams(b)
+	if len(ga) == 0 || len(gb) == 0 {
+		return 0
+	}
+	counts := make(map[string]int, len(ga))
+	for _, g := range ga {
+		counts[g]++
+	}
+	overlap := 0
+	for _, g := range gb {
+		if counts[g] > 0 {
+			counts[g]--
+			overlap++
+		}
+	}
+	return 2 * float64(overlap) / float64(len(ga)+len(gb))
+}
+
+func runeBigrams(text string) []string {
+	runes := []rune(strings.ReplaceAll(text, " ", ""))
+	if len(runes) < 2 {
+		if len(runes) == 1 {
+			return []string{string(runes)}
+		}
+		return nil
+	}
+	out := make([]string, 0, len(runes)-1)
+	for i := 0; i < len(runes)-1; i++ {
+		out = append(out, string(runes[i:i+2]))
+	}
+	return out
+}
+
+// dueDatesClose 判断两个截止日期是否在窗口内；均未设置截止日期也视为相近。
+func dueDatesClose(a, b *time.Time, windowDays int) bool {
+	if a == nil && b == nil {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	diff := a.Sub(*b)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= time.Duration(windowDays)*24*time.Hour
+}
+
+// deleteRemoteTask 删除任务在所属 provider 上的远端任务，返回状态（deleted、skipped、failed）和说明；
+// 远端任务已不存在时视为已删除
+func (s *Server) deleteRemoteTask(ctx context.Context, task *model.Task) (string, string) {
+	if task.Source == "" || task.Source == model.SourceLocal || strings.TrimSpace(task.SourceRawID) == "" {
+		return "skipped", "local task"
+	}
+	if parseMicrosoftStepID(task.SourceRawID) != "" {
+		return "skipped", "microsoft checklist step is synced with its parent task"
+	}
+	p, ok := s.lookupProvider(ctx, string(task.Source))
+	if !ok || p == nil || !p.IsAuthenticated() {
+		return "failed", fmt.Sprintf("provider %s not available", task.Source)
+	}
+	if err := p.DeleteTask(ctx, task.ListID, task.SourceRawID); err != nil && !errors.Is(err, provider.ErrNotFound) {
+		return "failed", err.Error()
+	}
+	return "deleted", ""
+}
@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestHandleFindDuplicatesAcrossSources(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+
+	now := time.Now()
+	due := now.AddDate(0, 0, 3)
+	dueNear := due.Add(6 * time.Hour)
+	dueFar := due.AddDate(0, 0, 10)
+	seed := []model.Task{
+		{ID: "g1", Title: "Write quarterly report", Source: model.SourceGoogle, SourceRawID: "raw-g1", DueDate: &due},
+		{ID: "m1", Title: "**Write quarterly report!**", Source: model.SourceMicrosoft, DueDate: &dueNear},
+		{ID: "m2", Title: "Write quarterly report", Source: model.SourceMicrosoft, DueDate: &dueFar},
+		{ID: "l1", Title: "整理周报材料", Source: model.SourceLocal},
+		{ID: "l2", Title: "整理周报材料。", Source: model.SourceLocal},
+		{ID: "l3", Title: "Buy milk", Source: model.SourceLocal},
+	}
+	for i := range seed {
+		seed[i].Status = model.StatusTodo
+		seed[i].CreatedAt = now
+		seed[i].UpdatedAt = now
+		if err := store.SaveTask(ctx, &seed[i]); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+	}
+
+	res, err := s.handleFindDuplicates(ctx, buildCallToolRequest(t, map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("find duplicates: %v", err)
+	}
+	payload := parseJSONResult(t, res)
+	groups := payload["groups"].([]interface{})
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 duplicate groups, got %#v", groups)
+	}
+	var crossSource map[string]interface{}
+	for _, raw := range groups {
+		group := raw.(map[string]interface{})
+		if group["cross_source"] == true {
+			crossSource = group
+		}
+	}
+	if crossSource == nil {
+		t.Fatalf("expected a cross-source group: %#v", groups)
+	}
+	if len(crossSource["tasks"].([]interface{})) != 2 {
+		t.Fatalf("expected far due date to be excluded: %#v", crossSource)
+	}
+	if crossSource["suggested_keep_id"] != "g1" {
+		t.Fatalf("expected remote-synced task kept, got %v", crossSource["suggested_keep_id"])
+	}
+}
+
+func TestHandleMergeTasks(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+
+	now := time.Now()
+	early := now.AddDate(0, 0, 1)
+	late := now.AddDate(0, 0, 5)
+	for _, task := range []*model.Task{
+		{ID: "p", Title: "Plan trip", Description: "book hotel", Tags: []string{"travel"}, DueDate: &late, Priority: model.PriorityLow},
+		{ID: "d", Title: "plan trip", Description: "book flight", Tags: []string{"Travel", "family"}, DueDate: &early, Priority: model.PriorityHigh},
+	} {
+		task.Status = model.StatusTodo
+		task.Source = model.SourceLocal
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+	}
+
+	if _, err := s.handleMergeTasks(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"primary_id":    "p",
+		"duplicate_ids": []string{"d"},
+	})); err != nil {
+		t.Fatalf("merge tasks: %v", err)
+	}
+
+	primary, err := store.GetTask(ctx, "p")
+	if err != nil {
+		t.Fatalf("get primary: %v", err)
+	}
+	if primary.Description != "book hotel\n\nbook flight" {
+		t.Fatalf("unexpected merged description: %q", primary.Description)
+	}
+	if len(primary.Tags) != 2 || primary.Priority != model.PriorityHigh || !primary.DueDate.Equal(early) {
+		t.Fatalf("unexpected merged fields: %#v", primary)
+	}
+	dup, err := store.GetTask(ctx, "d")
+	if err != nil {
+		t.Fatalf("get duplicate: %v", err)
+	}
+	if dup.Status != model.StatusCompleted || getCustomFieldString(*dup, "tb_merged_into") != "p" {
+		t.Fatalf("expected duplicate marked merged: %#v", dup)
+	}
+}
+
+type mergeRecordingProvider struct {
+	mockProvider
+	updated []model.Task
+	deleted []string
+}
+
+func (p *mergeRecordingProvider) UpdateTask(ctx context.Context, listID string, task *model.Task) (*model.Task, error) {
+	p.updated = append(p.updated, *task)
+	cp := *task
+	return &cp, nil
+}
+
+func (p *mergeRecordingProvider) DeleteTask(ctx context.Context, listID, taskID string) error {
+	p.deleted = append(p.deleted, taskID)
+	return nil
+}
+
+func TestHandleMergeTasksWritesProvider(t *testing.T) {
+	remote := &mergeRecordingProvider{}
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, map[string]provider.Provider{"google": remote})
+
+	now := time.Now()
+	for _, id := range []string{"p", "d1", "d2"} {
+		if err := store.SaveTask(ctx, &model.Task{
+			ID: "google-@default-" + id, Title: "Plan trip", Status: model.StatusTodo,
+			Source: model.SourceGoogle, SourceRawID: id, ListID: "@default", CreatedAt: now, UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+	}
+
+	res, err := s.handleMergeTasks(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"primary_id":    "google-@default-p",
+		"duplicate_ids": []string{"google-@default-d1"},
+	}))
+	if err != nil {
+		t.Fatalf("merge tasks: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	if rs, _ := out["remote"].(map[string]interface{}); rs["google-@default-d1"] != "updated" {
+		t.Fatalf("expected duplicate completed on provider, got %v", out["remote"])
+	}
+	if len(remote.updated) != 2 || remote.updated[1].SourceRawID != "d1" || remote.updated[1].Status != model.StatusCompleted {
+		t.Fatalf("expected primary update and duplicate completion, got %#v", remote.updated)
+	}
+
+	res, err = s.handleMergeTasks(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"primary_id":        "google-@default-p",
+		"duplicate_ids":     []string{"google-@default-d2"},
+		"delete_duplicates": true,
+	}))
+	if err != nil {
+		t.Fatalf("merge tasks: %v", err)
+	}
+	out = parseJSONResult(t, res)
+	if rs, _ := out["remote"].(map[string]interface{}); rs["google-@default-d2"] != "deleted" {
+		t.Fatalf("expected duplicate deleted on provider, got %v", out["remote"])
+	}
+	if len(remote.deleted) != 1 || remote.deleted[0] != "d2" {
+		t.Fatalf("expected remote delete of d2, got %v", remote.deleted)
+	}
+	if _, err := store.GetTask(ctx, "google-@default-d2"); err == nil {
+		t.Fatal("deleted duplicate should be removed locally")
+	}
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	if got := titleSimilarity(normalizeTitleForMatch("整理周报"), normalizeTitleForMatch("整理周报。")); got != 1 {
+		t.Fatalf("expected identical after normalize, got %v", got)
+	}
+	if got := titleSimilarity("buy milk", "write report"); got > 0.3 {
+		t.Fatalf("expected low similarity, got %v", got)
+	}
+}
@@ -0,0 +1,386 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+// taskBlockedByField 记录阻塞当前任务的任务 ID 列表（本地 ID）。
+const taskBlockedByField = "tb_blocked_by"
+
+type blockerView struct {
+	ID       string           `json:"id"`
+	Title    string           `json:"title,omitempty"`
+	Status   model.TaskStatus `json:"status,omitempty"`
+	Source   string           `json:"source,omitempty"`
+	Resolved bool             `json:"resolved"`
+	Missing  bool             `json:"missing,omitempty"`
+}
+
+// handleAddBlocker 记录“task A 阻塞 task B”，支持的 provider 同时写入原生依赖。
+func (s *Server) handleAddBlocker(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	blockerID := strings.TrimSpace(getString(rawArgs, "blocker_id"))
+	blockedID := strings.TrimSpace(getString(rawArgs, "task_id"))
+	if blockerID == "" || blockedID == "" {
+		return nil, fmt.Errorf("task_id and blocker_id are required")
+	}
+	if blockerID == blockedID {
+		return nil, fmt.Errorf("a task cannot block itself")
+	}
+	remove, _ := getBool(rawArgs, "remove")
+
+	blocked, err := s.taskStore.GetTask(ctx, blockedID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+	blocker, err := s.taskStore.GetTask(ctx, blockerID)
+	if err != nil {
+		return nil, fmt.Errorf("blocker task not found: %w", err)
+	}
+
+	current := taskBlockedBy(*blocked)
+	next := make([]string, 0, len(current)+1)
+	exists := false
+	for _, id := range current {
+		if id == blockerID {
+			exists = true
+			if remove {
+				continue
+			}
+		}
+		next = append(next, id)
+	}
+
+	changed := false
+	switch {
+	case remove && exists:
+		changed = true
+	case !remove && !exists:
+		tasks, err := s.taskStore.QueryTasks(ctx, storage.Query{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query tasks: %w", err)
+		}
+		if path := findBlockerPath(tasks, blockedID, blockerID); len(path) > 0 {
+			return nil, fmt.Errorf("dependency cycle: %s already depends on %s (%s)", blockerID, blockedID, strings.Join(path, " -> "))
+		}
+		next = append(next, blockerID)
+		changed = true
+	}
+
+	if changed {
+		setTaskCustomField(blocked, taskBlockedByField, next)
+		blocked.UpdatedAt = time.Now()
+		if err := s.taskStore.SaveTask(ctx, blocked); err != nil {
+			return nil, fmt.Errorf("failed to save task: %w", err)
+		}
+	}
+
+	storedIn := "local"
+	result := map[string]interface{}{
+		"task_id":    blockedID,
+		"blocker_id": blockerID,
+		"removed":    remove,
+		"changed":    changed,
+		"blocked_by": next,
+	}
+	if changed {
+		native, note := s.pushTaskDependency(ctx, blocker, blocked, remove)
+		if native {
+			storedIn = "native+local"
+		}
+		if note != "" {
+			result["native_note"] = note
+		}
+	}
+	result["stored_in"] = storedIn
+
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// handleListBlockers 列出任务的阻塞项及其被阻塞的后续任务；未指定任务时列出全部依赖关系。
+func (s *Server) handleListBlockers(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: `{"dependencies":[]}`}},
+		}, nil
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	tasks, err := s.taskStore.QueryTasks(ctx, storage.Query{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	byID := make(map[string]*model.Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+
+	taskID := strings.TrimSpace(getString(rawArgs, "task_id"))
+	var result map[string]interface{}
+	if taskID != "" {
+		task, ok := byID[taskID]
+		if !ok {
+			return nil, fmt.Errorf("task not found: %s", taskID)
+		}
+		blockers := make([]blockerView, 0)
+		unresolved := 0
+		for _, id := range taskBlockedBy(*task) {
+			view := newBlockerView(id, byID[id])
+			if !view.Resolved {
+				unresolved++
+			}
+			blockers = append(blockers, view)
+		}
+		blocking := make([]blockerView, 0)
+		for i := range tasks {
+			if containsFold(taskBlockedBy(tasks[i]), taskID) {
+				blocking = append(blocking, newBlockerView(tasks[i].ID, &tasks[i]))
+			}
+		}
+		sort.Slice(blocking, func(i, j int) bool { return blocking[i].ID < blocking[j].ID })
+		result = map[string]interface{}{
+			"task_id":    taskID,
+			"title":      task.Title,
+			"blocked":    unresolved > 0,
+			"blocked_by": blockers,
+			"blocking":   blocking,
+		}
+	} else {
+		deps := make([]map[string]interface{}, 0)
+		for i := range tasks {
+			for _, id := range taskBlockedBy(tasks[i]) {
+				view := newBlockerView(id, byID[id])
+				deps = append(deps, map[string]interface{}{
+					"blocker_id":    id,
+					"blocker_title": view.Title,
+					"task_id":       tasks[i].ID,
+					"task_title":    tasks[i].Title,
+					"resolved":      view.Resolved,
+				})
+			}
+		}
+		sort.Slice(deps, func(i, j int) bool {
+			if deps[i]["task_id"] == deps[j]["task_id"] {
+				return deps[i]["blocker_id"].(string) < deps[j]["blocker_id"].(string)
+			}
+			return deps[i]["task_id"].(string) < deps[j]["task_id"].(string)
+		})
+		result = map[string]interface{}{"dependencies": deps}
+	}
+
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// handleReadyTasks 列出未被阻塞、可以立即开始的任务。
+func (s *Server) handleReadyTasks(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: `{"ready":[],"blocked_count":0}`}},
+		}, nil
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	tasks, err := s.taskStore.QueryTasks(ctx, storage.Query{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	byID := make(map[string]*model.Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+
+	source := ""
+	if value := getString(rawArgs, "source"); value != "" {
+		if source, err = resolveProviderNameStrict(value); err != nil {
+			return nil, err
+		}
+	}
+
+	ready := make([]model.Task, 0)
+	blockedCount := 0
+	for _, task := range tasks {
+		if task.Status != model.StatusTodo && task.Status != model.StatusInProgress {
+			continue
+		}
+		if source != "" && string(task.Source) != source {
+			continue
+		}
+		unblocked := true
+		for _, id := range taskBlockedBy(task) {
+			if !newBlockerView(id, byID[id]).Resolved {
+				unblocked = false
+				break
+			}
+		}
+		if !unblocked {
+			blockedCount++
+			continue
+		}
+		ready = append(ready, task)
+	}
+	sortTasksByKeys(ready, []taskSortKey{{Field: "priority", Desc: true}, {Field: "due_date"}})
+	if limit, ok := getInt(rawArgs, "limit"); ok && limit > 0 && len(ready) > limit {
+		ready = ready[:limit]
+	}
+
+	result := map[string]interface{}{
+		"ready":         toCompactTasks(ready),
+		"ready_count":   len(ready),
+		"blocked_count": blockedCount,
+	}
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// pushTaskDependency 当两个任务来自同一个支持原生依赖的 provider 时同步写入远端。
+func (s *Server) pushTaskDependency(ctx context.Context, blocker, blocked *model.Task, remove bool) (bool, string) {
+	if blocked.Source == "" || blocked.Source == model.SourceLocal {
+		return false, ""
+	}
+	if blocker.Source != blocked.Source {
+		return false, "tasks come from different sources, stored locally only"
+	}
+	p, ok := s.lookupProvider(ctx, string(blocked.Source))
+	if !ok || p == nil || !p.IsAuthenticated() {
+		return false, fmt.Sprintf("provider %s not available, stored locally only", blocked.Source)
+	}
+	linker, ok := unwrapProvider(p).(provider.TaskDependencyLinker)
+	if !ok {
+		return false, ""
+	}
+	if blocker.SourceRawID == "" || blocked.SourceRawID == "" {
+		return false, "tasks not synced to remote yet, stored locally only"
+	}
+	var err error
+	if remove {
+		err = linker.RemoveTaskDependency(ctx, blocker.SourceRawID, blocked.SourceRawID)
+	} else {
+		err = linker.AddTaskDependency(ctx, blocker.SourceRawID, blocked.SourceRawID)
+	}
+	if err != nil {
+		return false, fmt.Sprintf("native dependency failed: %v", err)
+	}
+	s.invalidateAdapterReads(string(blocked.Source), blocked.ListID)
+	return true, ""
+}
+
+// taskBlockedBy 读取任务的阻塞项 ID 列表。
+func taskBlockedBy(task model.Task) []string {
+	if task.Metadata == nil || task.Metadata.CustomFields == nil {
+		return nil
+	}
+	switch v := task.Metadata.CustomFields[taskBlockedByField].(type) {
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if id := strings.TrimSpace(fmt.Sprint(item)); id != "" {
+				out = append(out, id)
+			}
+		}
+		return out
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil
+		}
+		return []string{strings.TrimSpace(v)}
+	default:
+		return nil
+	}
+}
+
+// findBlockerPath 从 to 出发沿“被谁阻塞”的关系查找 from，找到即说明新增 to 阻塞 from 会形成循环。
+func findBlockerPath(tasks []model.Task, from, to string) []string {
+	edges := make(map[string][]string, len(tasks))
+	for _, task := range tasks {
+		edges[task.ID] = taskBlockedBy(task)
+	}
+	visited := map[string]bool{}
+	var walk func(id string, path []string) []string
+	walk = func(id string, path []string) []string {
+		if id == from {
+			return append(path, id)
+		}
+		if visited[id] {
+			return nil
+		}
+		visited[id] = true
+		for _, next := range edges[id] {
+			if found := walk(next, append(path, id)); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return walk(to, nil)
+}
+
+func newBlockerView(id string, task *model.Task) blockerView {
+	if task == nil {
+		// 阻塞任务已被删除，视为已解除
+		return blockerView{ID: id, Resolved: true, Missing: true}
+	}
+	return blockerView{
+		ID:       id,
+		Title:    task.Title,
+		Status:   task.Status,
+		Source:   string(task.Source),
+		Resolved: task.Status == model.StatusCompleted || task.Status == model.StatusCancelled,
+	}
+}
+
+// setTaskCustomField 写入任务自定义字段，必要时初始化 Metadata。
+func setTaskCustomField(task *model.Task, key string, value interface{}) {
+	if task.Metadata == nil {
+		task.Metadata = &model.TaskMetadata{Version: "1.0"}
+	}
+	if task.Metadata.CustomFields == nil {
+		task.Metadata.CustomFields = map[string]interface{}{}
+	}
+	task.Metadata.CustomFields[key] = value
+}
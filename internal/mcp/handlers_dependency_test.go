@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+type dependencyMockProvider struct {
+	mockProvider
+	links map[string]bool
+}
+
+func (m *dependencyMockProvider) AddTaskDependency(ctx context.Context, blockerTaskID, blockedTaskID string) error {
+	m.links[blockerTaskID+">"+blockedTaskID] = true
+	return nil
+}
+
+func (m *dependencyMockProvider) RemoveTaskDependency(ctx context.Context, blockerTaskID, blockedTaskID string) error {
+	delete(m.links, blockerTaskID+">"+blockedTaskID)
+	return nil
+}
+
+func TestDependencyToolsAndReadyTasks(t *testing.T) {
+	mock := &dependencyMockProvider{links: map[string]bool{}}
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, map[string]provider.Provider{"google": mock})
+
+	now := time.Now()
+	seed := []model.Task{
+		{ID: "design", Title: "Design", Status: model.StatusTodo, Source: model.SourceGoogle, SourceRawID: "raw-design", Priority: model.PriorityHigh},
+		{ID: "build", Title: "Build", Status: model.StatusTodo, Source: model.SourceGoogle, SourceRawID: "raw-build", Priority: model.PriorityUrgent},
+		{ID: "ship", Title: "Ship", Status: model.StatusTodo, Source: model.SourceLocal},
+		{ID: "misc", Title: "Misc", Status: model.StatusTodo, Source: model.SourceLocal, Priority: model.PriorityLow},
+	}
+	for i := range seed {
+		seed[i].CreatedAt = now
+		if err := store.SaveTask(ctx, &seed[i]); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+
+	res, err := s.handleAddBlocker(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "build", "blocker_id": "design"}))
+	if err != nil {
+		t.Fatalf("add blocker: %v", err)
+	}
+	if out := parseJSONResult(t, res); out["stored_in"] != "native+local" || !mock.links["raw-design>raw-build"] {
+		t.Fatalf("expected native dependency, got %v (links %v)", out, mock.links)
+	}
+	res, err = s.handleAddBlocker(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "ship", "blocker_id": "build"}))
+	if err != nil {
+		t.Fatalf("add cross-source blocker: %v", err)
+	}
+	if out := parseJSONResult(t, res); out["stored_in"] != "local" {
+		t.Fatalf("expected local dependency, got %v", out)
+	}
+
+	// design -> build -> ship，再添加 ship 阻塞 design 会形成循环
+	if _, err := s.handleAddBlocker(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "design", "blocker_id": "ship"})); err == nil {
+		t.Fatalf("expected dependency cycle error")
+	}
+
+	res, err = s.handleListBlockers(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "build"}))
+	if err != nil {
+		t.Fatalf("list blockers: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	blockers, _ := out["blocked_by"].([]interface{})
+	blocking, _ := out["blocking"].([]interface{})
+	if out["blocked"] != true || len(blockers) != 1 || len(blocking) != 1 {
+		t.Fatalf("unexpected blockers payload: %v", out)
+	}
+
+	readyIDs := func() []string {
+		res, err := s.handleReadyTasks(ctx, buildCallToolRequest(t, map[string]interface{}{}))
+		if err != nil {
+			t.Fatalf("ready tasks: %v", err)
+		}
+		items, _ := parseJSONResult(t, res)["ready"].([]interface{})
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.(map[string]interface{})["id"].(string))
+		}
+		return ids
+	}
+	if ids := readyIDs(); len(ids) != 2 || ids[0] != "design" || ids[1] != "misc" {
+		t.Fatalf("unexpected ready tasks: %v", ids)
+	}
+
+	design, _ := store.GetTask(ctx, "design")
+	design.Status = model.StatusCompleted
+	if err := store.SaveTask(ctx, design); err != nil {
+		t.Fatalf("complete design: %v", err)
+	}
+	if ids := readyIDs(); len(ids) != 2 || ids[0] != "build" {
+		t.Fatalf("expected build to become ready, got %v", ids)
+	}
+
+	if _, err := s.handleAddBlocker(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "build", "blocker_id": "design", "remove": true})); err != nil {
+		t.Fatalf("remove blocker: %v", err)
+	}
+	if len(mock.links) != 0 {
+		t.Fatalf("expected native dependency removed, got %v", mock.links)
+	}
+}
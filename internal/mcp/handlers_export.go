@@ -0,0 +1,345 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// exportFormat 导出格式描述
+type exportFormat struct {
+	Extension string
+	MIMEType  string
+	Render    func(tasks []model.Task, now time.Time) (string, error)
+}
+
+var exportFormats = map[string]exportFormat{
+	"csv":      {Extension: "csv", MIMEType: "text/csv", Render: renderTasksCSV},
+	"json":     {Extension: "json", MIMEType: "application/json", Render: renderTasksJSON},
+	"markdown": {Extension: "md", MIMEType: "text/markdown", Render: renderTasksMarkdown},
+	"ical":     {Extension: "ics", MIMEType: "text/calendar", Render: renderTasksICal},
+}
+
+var exportFormatAliases = map[string]string{
+	"md":    "markdown",
+	"ics":   "ical",
+	"vtodo": "ical",
+}
+
+// handleExportTasks 按 list_tasks 过滤条件导出任务，以嵌入资源形式返回 CSV/JSON/Markdown/iCal 内容。
+func (s *Server) handleExportTasks(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	formatName := strings.ToLower(strings.TrimSpace(getString(rawArgs, "format")))
+	if formatName == "" {
+		formatName = "json"
+	}
+	if alias, ok := exportFormatAliases[formatName]; ok {
+		formatName = alias
+	}
+	format, ok := exportFormats[formatName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported export format: %s (supported: csv, json, markdown, ical)", formatName)
+	}
+
+	appliedFilters := map[string]interface{}{}
+	query, projectIDs, err := s.parseTaskQueryArgs(ctx, rawArgs, appliedFilters)
+	if err != nil {
+		return nil, err
+	}
+	sortKeys, err := parseTaskSort(getString(rawArgs, "sort"))
+	if err != nil {
+		return nil, err
+	}
+	if len(sortKeys) == 0 {
+		sortKeys = []taskSortKey{{Field: "due_date"}, {Field: "priority", Desc: true}}
+	}
+
+	tasks, err := s.taskStore.QueryTasks(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	tasks = filterTasksByProject(tasks, projectIDs)
+	sortTasksByKeys(tasks, sortKeys)
+
+	now := time.Now().UTC()
+	output, err := format.Render(tasks, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s export: %w", formatName, err)
+	}
+
+	filename := strings.TrimSpace(getString(rawArgs, "filename"))
+	if filename == "" {
+		filename = "tasks-" + now.Format("20060102-150405")
+	}
+	if !strings.HasSuffix(filename, "."+format.Extension) {
+		filename += "." + format.Extension
+	}
+	resource := &mcp.ResourceContents{
+		URI:      "taskbridge://exports/" + filename,
+		MIMEType: format.MIMEType,
+	}
+	if asBlob, _ := getBool(rawArgs, "as_blob"); asBlob {
+		resource.Blob = []byte(output)
+	} else {
+		resource.Text = output
+	}
+
+	summary, err := toJSON(map[string]interface{}{
+		"format":   formatName,
+		"count":    len(tasks),
+		"bytes":    len(output),
+		"uri":      resource.URI,
+		"filename": filename,
+		"filters":  appliedFilters,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: summary},
+			&mcp.EmbeddedResource{Resource: resource},
+		},
+	}, nil
+}
+
+func renderTasksJSON(tasks []model.Task, _ time.Time) (string, error) {
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func renderTasksCSV(tasks []model.Task, _ time.Time) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"id", "title", "status", "priority", "quadrant", "due_date", "completed_at", "source", "list_name", "tags", "parent_id", "project_id", "description"}
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+	for _, task := range tasks {
+		parentID := ""
+		if task.ParentID != nil {
+			parentID = *task.ParentID
+		}
+		row := []string{
+			task.ID,
+			task.Title,
+			string(task.Status),
+			strconv.Itoa(int(task.Priority)),
+			strconv.Itoa(int(task.Quadrant)),
+			formatExportTime(task.DueDate),
+			formatExportTime(task.CompletedAt),
+			string(task.Source),
+			task.ListName,
+			strings.Join(task.Tags, ";"),
+			parentID,
+			getCustomFieldString(task, "tb_project_id"),
+			task.Description,
+		}
+		if err := w.Write(row); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// renderTasksMarkdown 按清单分组输出任务清单，语法与 quick_add / create_tasks_from_markdown 兼容，
+// 子任务缩进在父任务之下。
+func renderTasksMarkdown(tasks []model.Task, now time.Time) (string, error) {
+	inSet := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		inSet[task.ID] = true
+	}
+	children := make(map[string][]model.Task)
+	groups := make(map[string][]model.Task)
+	groupOrder := make([]string, 0)
+	for _, task := range tasks {
+		if task.ParentID != nil && inSet[*task.ParentID] {
+			children[*task.ParentID] = append(children[*task.ParentID], task)
+			continue
+		}
+		group := strings.TrimSpace(task.ListName)
+		if group == "" {
+			group = "未分组"
+		}
+		if _, ok := groups[group]; !ok {
+			groupOrder = append(groupOrder, group)
+		}
+		groups[group] = append(groups[group], task)
+	}
+	sort.Strings(groupOrder)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# 任务导出\n\n> 导出时间：%s，共 %d 个任务\n", now.Format("2006-01-02 15:04 MST"), len(tasks))
+	var write func(task model.Task, depth int)
+	write = func(task model.Task, depth int) {
+		mark := " "
+		if task.Status == model.StatusCompleted {
+			mark = "x"
+		}
+		fmt.Fprintf(&b, "%s- [%s] %s", strings.Repeat("  ", depth), mark, sanitizeMarkdownText(task.Title))
+		if task.DueDate != nil {
+			b.WriteString(" " + task.DueDate.Format("2006-01-02"))
+		}
+		if task.Priority > 0 && task.Priority <= 4 {
+			fmt.Fprintf(&b, " p%d", 5-int(task.Priority))
+		}
+		for _, tag := range task.Tags {
+			b.WriteString(" @" + strings.ReplaceAll(tag, " ", "_"))
+		}
+		b.WriteString("\n")
+		for _, child := range children[task.ID] {
+			write(child, depth+1)
+		}
+	}
+	for _, group := range groupOrder {
+		fmt.Fprintf(&b, "\n## %s\n\n", sanitizeMarkdownText(group))
+		for _, task := range groups[group] {
+			write(task, 0)
+		}
+	}
+	return b.String(), nil
+}
+
+// renderTasksICal 输出 RFC 5545 VCALENDAR，每个任务一个 VTODO。
+func renderTasksICal(tasks []model.Task, now time.Time) (string, error) {
+	var b strings.Builder
+	writeLine := func(line string) {
+		b.WriteString(foldICalLine(line))
+		b.WriteString("\r\n")
+	}
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//TaskBridge//Task Export//EN")
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, task := range tasks {
+		writeLine("BEGIN:VTODO")
+		writeLine("UID:" + escapeICalText(task.ID) + "@taskbridge")
+		writeLine("DTSTAMP:" + stamp)
+		writeLine("SUMMARY:" + escapeICalText(task.Title))
+		if task.Description != "" {
+			writeLine("DESCRIPTION:" + escapeICalText(task.Description))
+		}
+		writeLine("STATUS:" + icalTodoStatus(task.Status))
+		if priority := icalPriority(task.Priority); priority > 0 {
+			writeLine("PRIORITY:" + strconv.Itoa(priority))
+		}
+		if task.DueDate != nil {
+			due := task.DueDate.UTC()
+			if due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0 {
+				writeLine("DUE;VALUE=DATE:" + due.Format("20060102"))
+			} else {
+				writeLine("DUE:" + due.Format("20060102T150405Z"))
+			}
+		}
+		if task.CompletedAt != nil {
+			writeLine("COMPLETED:" + task.CompletedAt.UTC().Format("20060102T150405Z"))
+		}
+		if !task.CreatedAt.IsZero() {
+			writeLine("CREATED:" + task.CreatedAt.UTC().Format("20060102T150405Z"))
+		}
+		if !task.UpdatedAt.IsZero() {
+			writeLine("LAST-MODIFIED:" + task.UpdatedAt.UTC().Format("20060102T150405Z"))
+		}
+		if task.Progress > 0 {
+			writeLine("PERCENT-COMPLETE:" + strconv.Itoa(task.Progress))
+		}
+		if len(task.Tags) > 0 {
+			escaped := make([]string, 0, len(task.Tags))
+			for _, tag := range task.Tags {
+				escaped = append(escaped, escapeICalText(tag))
+			}
+			writeLine("CATEGORIES:" + strings.Join(escaped, ","))
+		}
+		if task.ParentID != nil && *task.ParentID != "" {
+			writeLine("RELATED-TO;RELTYPE=PARENT:" + escapeICalText(*task.ParentID) + "@taskbridge")
+		}
+		writeLine("END:VTODO")
+	}
+	writeLine("END:VCALENDAR")
+	return b.String(), nil
+}
+
+func icalTodoStatus(status model.TaskStatus) string {
+	switch status {
+	case model.StatusCompleted:
+		return "COMPLETED"
+	case model.StatusInProgress:
+		return "IN-PROCESS"
+	case model.StatusCancelled:
+		return "CANCELLED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// icalPriority 将 1-4（4 最高）映射到 iCal 的 1-9（1 最高）。
+func icalPriority(priority model.Priority) int {
+	switch priority {
+	case model.PriorityUrgent:
+		return 1
+	case model.PriorityHigh:
+		return 3
+	case model.PriorityMedium:
+		return 5
+	case model.PriorityLow:
+		return 9
+	default:
+		return 0
+	}
+}
+
+func escapeICalText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// foldICalLine 按 RFC 5545 把超过 75 字节的行折叠，且不截断 UTF-8 字符。
+func foldICalLine(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			// 续行以一个空格开头，空格也计入 75 字节
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func seedExportTasks(t *testing.T) (*Server, func(args map[string]interface{}) *mcp.ResourceContents) {
+	t.Helper()
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	due := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	meeting := time.Date(2026, 10, 21, 9, 30, 0, 0, time.UTC)
+	parentID := "a"
+	seed := []model.Task{
+		{ID: "a", Title: "发布 v2, 准备", Status: model.StatusTodo, Priority: model.PriorityUrgent, DueDate: &due, ListName: "Work", Tags: []string{"release"}},
+		{ID: "b", Title: "写变更日志", Status: model.StatusCompleted, ParentID: &parentID, ListName: "Work"},
+		{ID: "c", Title: "Dentist", Status: model.StatusTodo, DueDate: &meeting, ListName: "Personal"},
+	}
+	for i := range seed {
+		seed[i].Source = model.SourceLocal
+		if err := store.SaveTask(ctx, &seed[i]); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+
+	export := func(args map[string]interface{}) *mcp.ResourceContents {
+		res, err := s.handleExportTasks(ctx, buildCallToolRequest(t, args))
+		if err != nil {
+			t.Fatalf("export tasks: %v", err)
+		}
+		if len(res.Content) != 2 {
+			t.Fatalf("expected summary and resource, got %d contents", len(res.Content))
+		}
+		embedded, ok := res.Content[1].(*mcp.EmbeddedResource)
+		if !ok || embedded.Resource == nil {
+			t.Fatalf("second content should be an embedded resource: %T", res.Content[1])
+		}
+		return embedded.Resource
+	}
+	return s, export
+}
+
+func TestHandleExportTasksFormats(t *testing.T) {
+	_, export := seedExportTasks(t)
+
+	csvRes := export(map[string]interface{}{"format": "csv", "filename": "work"})
+	if csvRes.MIMEType != "text/csv" || csvRes.URI != "taskbridge://exports/work.csv" {
+		t.Fatalf("unexpected csv resource: %s %s", csvRes.URI, csvRes.MIMEType)
+	}
+	rows, err := csv.NewReader(strings.NewReader(csvRes.Text)).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 4 || rows[0][0] != "id" {
+		t.Fatalf("unexpected csv rows: %v", rows)
+	}
+
+	md := export(map[string]interface{}{"format": "md"}).Text
+	for _, want := range []string{"## Work", "- [ ] 发布 v2, 准备 2026-10-20 p1 @release", "  - [x] 写变更日志", "## Personal"} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	ics := export(map[string]interface{}{"format": "ical", "status": "todo"}).Text
+	for _, want := range []string{"BEGIN:VCALENDAR\r\n", "SUMMARY:发布 v2\\, 准备\r\n", "PRIORITY:1\r\n", "DUE;VALUE=DATE:20261020\r\n", "DUE:20261021T093000Z\r\n", "END:VCALENDAR\r\n"} {
+		if !strings.Contains(ics, want) {
+			t.Fatalf("ical missing %q:\n%s", want, ics)
+		}
+	}
+	if strings.Contains(ics, "写变更日志") {
+		t.Fatalf("status filter should exclude completed task")
+	}
+
+	blob := export(map[string]interface{}{"format": "json", "as_blob": true})
+	if blob.Text != "" || !strings.Contains(string(blob.Blob), `"id": "c"`) {
+		t.Fatalf("unexpected blob resource: %+v", blob)
+	}
+}
+
+func TestHandleExportTasksRejectsUnknownFormat(t *testing.T) {
+	s, _ := seedExportTasks(t)
+	if _, err := s.handleExportTasks(t.Context(), buildCallToolRequest(t, map[string]interface{}{"format": "xlsx"})); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+}
+
+func TestFoldICalLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("任务", 30)
+	folded := foldICalLine(line)
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > 75 {
+			t.Fatalf("folded line too long: %d", len(part))
+		}
+	}
+	if strings.ReplaceAll(folded, "\r\n ", "") != line {
+		t.Fatalf("unfolding should restore the original line")
+	}
+}
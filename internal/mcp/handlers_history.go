@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/history"
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// taskHistoryResourceTemplate 任务历史资源模板
+const taskHistoryResourceTemplate = "task://{adapter}/{task_id}/history"
+
+// defaultTaskHistoryLimit task_history 默认返回的记录数
+const defaultTaskHistoryLimit = 50
+
+// withToolOrigin 将工具调用标注为任务历史的来源，工具内保存的任务修改记录为 tool 与工具名
+func withToolOrigin(name string, handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handler(history.WithOrigin(ctx, history.OriginTool, name), req)
+	}
+}
+
+// handleTaskHistory 查询任务的字段变化历史，最近的在前
+func (s *Server) handleTaskHistory(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	if s.taskHistory == nil {
+		return nil, fmt.Errorf("task history is disabled (storage.history.enabled)")
+	}
+
+	taskID := strings.TrimSpace(getString(rawArgs, "id"))
+	if taskID == "" {
+		taskID = strings.TrimSpace(getString(rawArgs, "task_id"))
+	}
+	if taskID == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	source, err := resolveProviderNameStrict(getString(rawArgs, "source"))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	filter := history.Filter{TaskID: taskID, Source: model.TaskSource(source), Limit: defaultTaskHistoryLimit}
+	if filter.Since, err = parseHistoryTime(getString(rawArgs, "since"), now); err != nil {
+		return nil, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.Until, err = parseHistoryTime(getString(rawArgs, "until"), now); err != nil {
+		return nil, fmt.Errorf("invalid until: %w", err)
+	}
+	if limit, ok := getInt(rawArgs, "limit"); ok {
+		filter.Limit = limit
+	}
+	// 远端原始 ID 的记录也按本地 ID 查询
+	if local := s.findLocalTask(ctx, taskID, source); local != nil {
+		filter.TaskID = local.ID
+	}
+
+	entries, err := s.taskHistory.Query(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]interface{}{
+		"task_id": filter.TaskID,
+		"total":   len(entries),
+		"entries": entries,
+	}
+	jsonResult, err := toJSON(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: jsonResult},
+			&mcp.TextContent{Text: formatTaskHistory(entries)},
+		},
+		StructuredContent: payload,
+	}, nil
+}
+
+// handleTaskHistoryResource 处理 task://{adapter}/{task_id}/history 模板资源，返回任务最近的变化记录
+func (s *Server) handleTaskHistoryResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource uri: %w", err)
+	}
+	source, err := resolveResourceAdapter(parsed.Host)
+	if err != nil {
+		return nil, err
+	}
+	taskID, ok := strings.CutSuffix(strings.Trim(parsed.Path, "/"), "/history")
+	if !ok || taskID == "" || s.taskHistory == nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	entries, err := s.taskHistory.Query(ctx, history.Filter{TaskID: taskID, Source: source, Limit: defaultTaskHistoryLimit})
+	if err != nil {
+		return nil, err
+	}
+	output, err := toJSON(map[string]interface{}{
+		"adapter": parsed.Host,
+		"task_id": taskID,
+		"total":   len(entries),
+		"entries": entries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: output}},
+	}, nil
+}
+
+// parseHistoryTime 解析时间参数：RFC3339、YYYY-MM-DD（本地时区零点），
+// 或表示多久之前的时长，如 36h、7d
+func parseHistoryTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q (use RFC3339, YYYY-MM-DD or a duration like 7d)", value)
+}
+
+// formatTaskHistory 将历史记录格式化为可读文本
+func formatTaskHistory(entries []history.Entry) string {
+	if len(entries) == 0 {
+		return "没有找到该任务的变更记录"
+	}
+	var b strings.Builder
+	for i, entry := range entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		origin := entry.Origin
+		if entry.Detail != "" {
+			origin += ":" + entry.Detail
+		}
+		fmt.Fprintf(&b, "%s %s %s", entry.At.Local().Format("2006-01-02 15:04"), entry.Action, entry.Title)
+		if origin != "" {
+			fmt.Fprintf(&b, " (%s)", origin)
+		}
+		for _, change := range entry.Changes {
+			fmt.Fprintf(&b, "\n  - %s: %s → %s", change.Field, historyValue(change.Old), historyValue(change.New))
+		}
+	}
+	return b.String()
+}
+
+func historyValue(value string) string {
+	if value == "" {
+		return "(空)"
+	}
+	return value
+}
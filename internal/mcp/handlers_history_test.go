@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/history"
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+func TestTaskHistoryRecordsWriteToolsAndPolling(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := filestore.New(dir, "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	taskHistory, err := history.Open(dir)
+	if err != nil {
+		t.Fatalf("open history: %v", err)
+	}
+	ctx := context.Background()
+	if err := fs.SaveTask(ctx, &model.Task{ID: "local-a", Title: "写周报", Status: model.StatusTodo, Source: model.SourceLocal}); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+
+	s := NewServer(WithTaskStorage(history.Wrap(fs, taskHistory, history.OriginServer)), WithTaskHistory(taskHistory))
+	session := connectBreakdownClient(t, s, nil)
+	// 首次检查建立基线快照
+	s.CheckResourceChanges(ctx)
+
+	if _, err := session.CallTool(ctx, &sdkmcp.CallToolParams{
+		Name:      "update_task",
+		Arguments: map[string]any{"id": "local-a", "title": "写周报（终稿）"},
+	}); err != nil {
+		t.Fatalf("call update_task: %v", err)
+	}
+
+	// 其他进程绕过历史直接修改存储，由轮询补记
+	task, err := fs.GetTask(ctx, "local-a")
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	task.Status = model.StatusCompleted
+	if err := fs.SaveTask(ctx, task); err != nil {
+		t.Fatalf("save task: %v", err)
+	}
+	s.CheckResourceChanges(ctx)
+
+	res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{
+		Name:      "task_history",
+		Arguments: map[string]any{"id": "local-a", "since": "7d"},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("call task_history: %v %+v", err, res)
+	}
+	var payload struct {
+		Total   int             `json:"total"`
+		Entries []history.Entry `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(*sdkmcp.TextContent).Text), &payload); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if payload.Total != 2 {
+		t.Fatalf("expected 2 entries, got %+v", payload.Entries)
+	}
+	polled, updated := payload.Entries[0], payload.Entries[1]
+	if polled.Origin != history.OriginPoll || polled.Changes[0].Field != "status" || polled.Changes[0].New != string(model.StatusCompleted) {
+		t.Fatalf("unexpected polled entry: %+v", polled)
+	}
+	if updated.Origin != history.OriginTool || updated.Detail != "update_task" || updated.Changes[0].Old != "写周报" {
+		t.Fatalf("unexpected tool entry: %+v", updated)
+	}
+	if text := res.Content[1].(*sdkmcp.TextContent).Text; !strings.Contains(text, "写周报 → 写周报（终稿）") {
+		t.Fatalf("unexpected text: %s", text)
+	}
+
+	resource, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "task://local/local-a/history"})
+	if err != nil {
+		t.Fatalf("read history resource: %v", err)
+	}
+	if !strings.Contains(resource.Contents[0].Text, `"total": 2`) {
+		t.Fatalf("unexpected resource: %s", resource.Contents[0].Text)
+	}
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"":                     {},
+		"7d":                   now.AddDate(0, 0, -7),
+		"36h":                  now.Add(-36 * time.Hour),
+		"2026-10-01":           time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		"2026-10-01T08:00:00Z": time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+	}
+	for value, want := range cases {
+		got, err := parseHistoryTime(value, now)
+		if err != nil || !got.Equal(want) {
+			t.Fatalf("parseHistoryTime(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	if _, err := parseHistoryTime("last week", now); err == nil {
+		t.Fatal("expected error for unrecognized time")
+	}
+}
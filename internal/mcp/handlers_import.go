@@ -0,0 +1,485 @@
+package mcp
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+var todoTxtPriorityPattern = regexp.MustCompile(`^\(([A-Z])\)\s+`)
+
+// importRecord 各导入格式的统一中间表示
+type importRecord struct {
+	Ref         string     `json:"ref,omitempty"`
+	ParentRef   string     `json:"parent_ref,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Priority    int        `json:"priority,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Categories  []string   `json:"categories,omitempty"`
+	ListName    string     `json:"list_name,omitempty"`
+	Project     string     `json:"project,omitempty"`
+}
+
+// handleImportTasks 解析 CSV/JSON/todo.txt 内容并批量创建任务，可推送到目标 provider。
+func (s *Server) handleImportTasks(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	content := getString(rawArgs, "content")
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("content is required")
+	}
+	formatName := strings.ToLower(strings.TrimSpace(getString(rawArgs, "format")))
+	if formatName == "" {
+		formatName = detectImportFormat(content)
+	}
+	providerName, err := resolveProviderNameStrict(getString(rawArgs, "provider"))
+	if err != nil {
+		return nil, err
+	}
+	if providerName == "" {
+		providerName = string(model.SourceLocal)
+	}
+	listID := strings.TrimSpace(getString(rawArgs, "list_id"))
+	dryRun, _ := getBool(rawArgs, "dry_run")
+	maxTasks, _ := getInt(rawArgs, "max_tasks")
+	maxTasks = normalizeMarkdownMaxTasks(maxTasks)
+
+	var records []importRecord
+	var warnings []string
+	switch formatName {
+	case "json":
+		records, warnings, err = parseImportJSON(content)
+	case "csv":
+		records, warnings, err = parseImportCSV(content)
+	case "todotxt", "todo.txt", "todo":
+		formatName = "todotxt"
+		records, warnings = parseImportTodoTxt(content)
+	default:
+		return nil, fmt.Errorf("unsupported import format: %s (supported: csv, json, todotxt)", formatName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s content: %w", formatName, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no tasks found in %s content", formatName)
+	}
+	if len(records) > maxTasks {
+		records = records[:maxTasks]
+		warnings = append(warnings, fmt.Sprintf("records truncated by max_tasks=%d", maxTasks))
+	}
+
+	tasks, buildWarnings := s.buildImportedTasks(ctx, records, listID)
+	warnings = append(warnings, buildWarnings...)
+
+	result := map[string]interface{}{
+		"format":   formatName,
+		"provider": providerName,
+		"dry_run":  dryRun,
+		"count":    len(tasks),
+	}
+	if dryRun {
+		result["would_create"] = toCompactTasks(tasks)
+		result["warnings"] = warnings
+		return importResult(result)
+	}
+
+	for i := range tasks {
+		if err := s.taskStore.SaveTask(ctx, &tasks[i]); err != nil {
+			return nil, fmt.Errorf("failed to save task: %w", err)
+		}
+	}
+	result["created"] = toCompactTasks(tasks)
+
+	if providerName != string(model.SourceLocal) {
+		p, ok := s.lookupProvider(ctx, providerName)
+		if !ok || p == nil || !p.IsAuthenticated() {
+			warnings = append(warnings, fmt.Sprintf("provider %s not found or not authenticated, tasks kept locally", providerName))
+		} else {
+			push, err := s.pushTaskTreeToProvider(ctx, p, providerName, tasks, listID)
+			if err != nil {
+				warnings = append(warnings, err.Error())
+			} else {
+				result["remote"] = push
+			}
+		}
+	}
+	result["warnings"] = warnings
+	return importResult(result)
+}
+
+func importResult(result map[string]interface{}) (*mcp.CallToolResult, error) {
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// buildImportedTasks 将导入记录转换为本地任务，父子引用重映射为新生成的任务 ID。
+func (s *Server) buildImportedTasks(ctx context.Context, records []importRecord, listID string) ([]model.Task, []string) {
+	baseID := generateID()
+	now := time.Now()
+	projectNames := s.projectNameIndex(ctx)
+	refToID := make(map[string]string, len(records))
+	for i, record := range records {
+		if record.Ref != "" {
+			refToID[record.Ref] = fmt.Sprintf("%s_%d", baseID, i+1)
+		}
+	}
+
+	warnings := make([]string, 0)
+	tasks := make([]model.Task, 0, len(records))
+	index := make(map[string]int, len(records))
+	for i, record := range records {
+		task := model.Task{
+			ID:          fmt.Sprintf("%s_%d", baseID, i+1),
+			Title:       record.Title,
+			Description: record.Description,
+			Status:      normalizeImportStatus(record.Status),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Source:      model.SourceLocal,
+			ListID:      listID,
+			ListName:    record.ListName,
+			Priority:    model.Priority(record.Priority),
+			DueDate:     record.DueDate,
+			CompletedAt: record.CompletedAt,
+			Tags:        record.Tags,
+			Categories:  record.Categories,
+		}
+		if task.Status == model.StatusCompleted && task.CompletedAt == nil {
+			task.CompletedAt = &now
+		}
+		task.Quadrant = model.CalculateQuadrantFromTask(&task)
+		task.Metadata = &model.TaskMetadata{
+			Version:    "1.0",
+			Quadrant:   int(task.Quadrant),
+			Priority:   int(task.Priority),
+			LocalID:    task.ID,
+			SyncSource: "local",
+		}
+		if record.Project != "" {
+			for id := range s.resolveProjectFilter(ctx, []string{record.Project}) {
+				if _, ok := projectNames[id]; ok {
+					setTaskCustomField(&task, "tb_project_id", id)
+				}
+			}
+		}
+		if record.ParentRef != "" {
+			parentID, ok := refToID[record.ParentRef]
+			if parentIdx, seen := index[parentID]; ok && seen {
+				task.ParentID = &parentID
+				tasks[parentIdx].SubtaskIDs = append(tasks[parentIdx].SubtaskIDs, task.ID)
+			} else {
+				warnings = append(warnings, fmt.Sprintf("task %q: parent %q not found before it, imported as top-level", record.Title, record.ParentRef))
+			}
+		}
+		index[task.ID] = len(tasks)
+		tasks = append(tasks, task)
+	}
+	return tasks, warnings
+}
+
+// detectImportFormat 根据内容猜测导入格式：JSON 以 [ 或 { 开头，首行含逗号且有 title 列视为 CSV，其余按 todo.txt。
+func detectImportFormat(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		return "json"
+	}
+	firstLine := strings.ToLower(strings.SplitN(trimmed, "\n", 2)[0])
+	if strings.Contains(firstLine, ",") && strings.Contains(firstLine, "title") {
+		return "csv"
+	}
+	return "todotxt"
+}
+
+// parseImportJSON 支持任务数组或 {"tasks": [...]}，字段与 export_tasks 的 JSON 输出兼容。
+func parseImportJSON(content string) ([]importRecord, []string, error) {
+	type jsonTask struct {
+		ID          string      `json:"id"`
+		ParentID    *string     `json:"parent_id"`
+		Title       string      `json:"title"`
+		Description string      `json:"description"`
+		Status      string      `json:"status"`
+		Priority    int         `json:"priority"`
+		DueDate     string      `json:"due_date"`
+		CompletedAt string      `json:"completed_at"`
+		Tags        interface{} `json:"tags"`
+		Categories  []string    `json:"categories"`
+		ListName    string      `json:"list_name"`
+		Project     string      `json:"project"`
+	}
+	var items []jsonTask
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") {
+		var wrapper struct {
+			Tasks []jsonTask `json:"tasks"`
+		}
+		if err := json.Unmarshal([]byte(trimmed), &wrapper); err != nil {
+			return nil, nil, err
+		}
+		items = wrapper.Tasks
+	} else if err := json.Unmarshal([]byte(trimmed), &items); err != nil {
+		return nil, nil, err
+	}
+
+	records := make([]importRecord, 0, len(items))
+	warnings := make([]string, 0)
+	for i, item := range items {
+		title := strings.TrimSpace(item.Title)
+		if title == "" {
+			warnings = append(warnings, fmt.Sprintf("item %d skipped: missing title", i+1))
+			continue
+		}
+		record := importRecord{
+			Ref:         item.ID,
+			Title:       title,
+			Description: item.Description,
+			Status:      item.Status,
+			Priority:    item.Priority,
+			Categories:  item.Categories,
+			ListName:    item.ListName,
+			Project:     item.Project,
+		}
+		if item.ParentID != nil {
+			record.ParentRef = *item.ParentID
+		}
+		switch tags := item.Tags.(type) {
+		case string:
+			record.Tags = splitImportList(tags)
+		case []interface{}:
+			for _, tag := range tags {
+				if value, ok := tag.(string); ok && strings.TrimSpace(value) != "" {
+					record.Tags = append(record.Tags, strings.TrimSpace(value))
+				}
+			}
+		}
+		var warn string
+		record.DueDate, warn = parseImportTime(item.DueDate, fmt.Sprintf("item %d due_date", i+1))
+		if warn != "" {
+			warnings = append(warnings, warn)
+		}
+		record.CompletedAt, warn = parseImportTime(item.CompletedAt, fmt.Sprintf("item %d completed_at", i+1))
+		if warn != "" {
+			warnings = append(warnings, warn)
+		}
+		records = append(records, record)
+	}
+	return records, warnings, nil
+}
+
+// parseImportCSV 按表头列名解析 CSV（大小写不敏感），至少需要 title 列；列名与 export_tasks 的 CSV 输出兼容。
+func parseImportCSV(content string) ([]importRecord, []string, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, nil, fmt.Errorf("csv header must contain a title column")
+	}
+	field := func(row []string, names ...string) string {
+		for _, name := range names {
+			if idx, ok := columns[name]; ok && idx < len(row) {
+				return strings.TrimSpace(row[idx])
+			}
+		}
+		return ""
+	}
+
+	records := make([]importRecord, 0)
+	warnings := make([]string, 0)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		title := field(row, "title")
+		if title == "" {
+			warnings = append(warnings, fmt.Sprintf("row %d skipped: missing title", line))
+			continue
+		}
+		record := importRecord{
+			Ref:         field(row, "id"),
+			ParentRef:   field(row, "parent_id"),
+			Title:       title,
+			Description: field(row, "description", "notes"),
+			Status:      field(row, "status"),
+			Tags:        splitImportList(field(row, "tags", "labels")),
+			ListName:    field(row, "list_name", "list"),
+			Project:     field(row, "project_id", "project"),
+		}
+		if value := field(row, "priority"); value != "" {
+			if priority, err := strconv.Atoi(value); err == nil && priority >= 0 && priority <= 4 {
+				record.Priority = priority
+			} else {
+				warnings = append(warnings, fmt.Sprintf("row %d: invalid priority %q ignored", line, value))
+			}
+		}
+		var warn string
+		record.DueDate, warn = parseImportTime(field(row, "due_date", "due"), fmt.Sprintf("row %d due_date", line))
+		if warn != "" {
+			warnings = append(warnings, warn)
+		}
+		record.CompletedAt, warn = parseImportTime(field(row, "completed_at"), fmt.Sprintf("row %d completed_at", line))
+		if warn != "" {
+			warnings = append(warnings, warn)
+		}
+		records = append(records, record)
+	}
+	return records, warnings, nil
+}
+
+// parseImportTodoTxt 解析 todo.txt：x 完成标记、(A) 优先级、完成/创建日期、+project、@context 与 due:YYYY-MM-DD。
+func parseImportTodoTxt(content string) ([]importRecord, []string) {
+	records := make([]importRecord, 0)
+	warnings := make([]string, 0)
+	for i, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		record := importRecord{Status: string(model.StatusTodo)}
+		if strings.HasPrefix(line, "x ") {
+			record.Status = string(model.StatusCompleted)
+			line = strings.TrimSpace(line[2:])
+			// 已完成任务的第一个日期是完成日期
+			if completed, rest, ok := cutTodoTxtDate(line); ok {
+				record.CompletedAt = &completed
+				line = rest
+			}
+		}
+		if match := todoTxtPriorityPattern.FindStringSubmatch(line); match != nil {
+			record.Priority = todoTxtPriority(match[1][0])
+			line = line[len(match[0]):]
+		}
+		if _, rest, ok := cutTodoTxtDate(line); ok {
+			line = rest
+		}
+
+		titleParts := make([]string, 0)
+		for _, word := range strings.Fields(line) {
+			switch {
+			case strings.HasPrefix(word, "+") && len(word) > 1:
+				record.Categories = append(record.Categories, word[1:])
+				if record.Project == "" {
+					record.Project = word[1:]
+				}
+			case strings.HasPrefix(word, "@") && len(word) > 1:
+				record.Tags = append(record.Tags, word[1:])
+			case strings.HasPrefix(word, "due:"):
+				if due, err := time.Parse("2006-01-02", word[4:]); err == nil {
+					record.DueDate = &due
+				} else {
+					warnings = append(warnings, fmt.Sprintf("line %d: invalid due date %q ignored", i+1, word[4:]))
+				}
+			case strings.HasPrefix(word, "pri:") && len(word) == 5:
+				record.Priority = todoTxtPriority(strings.ToUpper(word[4:])[0])
+			default:
+				titleParts = append(titleParts, word)
+			}
+		}
+		record.Title = strings.Join(titleParts, " ")
+		if record.Title == "" {
+			warnings = append(warnings, fmt.Sprintf("line %d skipped: missing title", i+1))
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, warnings
+}
+
+// cutTodoTxtDate 去掉行首的 YYYY-MM-DD 日期。
+func cutTodoTxtDate(line string) (time.Time, string, bool) {
+	head, rest, _ := strings.Cut(line, " ")
+	date, err := time.Parse("2006-01-02", head)
+	if err != nil {
+		return time.Time{}, line, false
+	}
+	return date, strings.TrimSpace(rest), true
+}
+
+// todoTxtPriority 将 todo.txt 的 A-D 映射为 4-1，E 及以后视为低优先级。
+func todoTxtPriority(letter byte) int {
+	if letter < 'A' || letter > 'Z' {
+		return 0
+	}
+	if letter >= 'D' {
+		return int(model.PriorityLow)
+	}
+	return int(model.PriorityUrgent) - int(letter-'A')
+}
+
+func normalizeImportStatus(value string) model.TaskStatus {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "completed", "done", "x", "true":
+		return model.StatusCompleted
+	case "in_progress", "doing", "in-process":
+		return model.StatusInProgress
+	case "cancelled", "canceled":
+		return model.StatusCancelled
+	case "deferred":
+		return model.StatusDeferred
+	default:
+		return model.StatusTodo
+	}
+}
+
+func parseImportTime(value, label string) (*time.Time, string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, ""
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, ""
+		}
+	}
+	return nil, fmt.Sprintf("%s: invalid time %q ignored", label, value)
+}
+
+func splitImportList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	parts := strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == ',' })
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestParseImportTodoTxt(t *testing.T) {
+	records, warnings := parseImportTodoTxt(`(A) 2026-10-01 Call mom +family @phone due:2026-10-20
+x 2026-10-05 2026-10-01 Pay rent +finance
+(C) Review PR pri:B
+
++onlyproject`)
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %+v", records)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+	call := records[0]
+	if call.Title != "Call mom" || call.Priority != 4 || call.Project != "family" || len(call.Tags) != 1 || call.Tags[0] != "phone" || call.DueDate == nil || call.DueDate.Format("2006-01-02") != "2026-10-20" {
+		t.Fatalf("unexpected first record: %+v", call)
+	}
+	rent := records[1]
+	if rent.Status != string(model.StatusCompleted) || rent.CompletedAt == nil || rent.CompletedAt.Format("2006-01-02") != "2026-10-05" || rent.Title != "Pay rent" {
+		t.Fatalf("unexpected completed record: %+v", rent)
+	}
+	if records[2].Priority != 3 {
+		t.Fatalf("pri: tag should override (C): %+v", records[2])
+	}
+}
+
+func TestHandleImportTasksCSVDryRun(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	content := "id,title,status,priority,due_date,tags,parent_id\n" +
+		"1,发布版本,todo,4,2026-10-20,release;ops,\n" +
+		"2,写变更日志,completed,,,,1\n" +
+		",,todo,,,,\n"
+	res, err := s.handleImportTasks(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"content": content,
+		"dry_run": true,
+	}))
+	if err != nil {
+		t.Fatalf("import tasks: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	if out["format"] != "csv" || out["count"].(float64) != 2 {
+		t.Fatalf("unexpected dry run result: %v", out)
+	}
+	if warnings, _ := out["warnings"].([]interface{}); len(warnings) != 1 {
+		t.Fatalf("expected one warning for the empty row: %v", out["warnings"])
+	}
+	tasks, _ := store.ListTasks(ctx, storage.ListOptions{})
+	if len(tasks) != 0 {
+		t.Fatalf("dry run should not save tasks")
+	}
+}
+
+func TestHandleImportTasksJSONToProvider(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	mock := &mockProvider{}
+	s, store, ctx := newIntelligenceTestServer(t, cfg, map[string]provider.Provider{"google": mock})
+
+	content := `{"tasks": [
+		{"id": "p", "title": "迁移数据库", "priority": 3, "due_date": "2026-10-20T09:00:00Z", "tags": ["db"]},
+		{"id": "c", "parent_id": "p", "title": "备份", "status": "completed"}
+	]}`
+	res, err := s.handleImportTasks(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"content":  content,
+		"provider": "google",
+	}))
+	if err != nil {
+		t.Fatalf("import tasks: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	if out["format"] != "json" {
+		t.Fatalf("format should be detected as json: %v", out)
+	}
+
+	tasks, err := store.ListTasks(ctx, storage.ListOptions{})
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	byTitle := make(map[string]model.Task, len(tasks))
+	for _, task := range tasks {
+		byTitle[task.Title] = task
+	}
+	parent, child := byTitle["迁移数据库"], byTitle["备份"]
+	if parent.Priority != model.PriorityHigh || parent.DueDate == nil || len(parent.Tags) != 1 {
+		t.Fatalf("unexpected parent: %+v", parent)
+	}
+	if child.ParentID == nil || *child.ParentID != parent.ID || child.Status != model.StatusCompleted || child.CompletedAt == nil {
+		t.Fatalf("unexpected child: %+v", child)
+	}
+	if len(mock.created) != 2 {
+		t.Fatalf("expected 2 remote creates, got %d", len(mock.created))
+	}
+
+	if _, err := s.handleImportTasks(ctx, buildCallToolRequest(t, map[string]interface{}{"content": "a,b", "format": "xml"})); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+}
@@ -493,7 +493,7 @@ func (s *Server) handleDetectDecompositionCandidates(ctx context.Context, req *m
 		if score < cfg.Decompose.ComplexityThreshold || hasSubtasks {
 			continue
 		}
-		recommendedProvider, supportsSubtasks := s.recommendProviderForTask(task, "")
+		recommendedProvider, supportsSubtasks := s.recommendProviderForTask(ctx, task, "")
 		strategy := strings.TrimSpace(cfg.Decompose.PreferredStrategy)
 		if strategy == "" {
 			strategy = "project_split"
@@ -566,7 +566,7 @@ func (s *Server) handleDecomposeTaskWithProvider(ctx context.Context, req *mcp.C
 	}
 
 	cfg := s.effectiveIntelligenceConfig()
-	providerName, supportsSubtasks := s.recommendProviderForTask(*task, params.Provider)
+	providerName, supportsSubtasks := s.recommendProviderForTask(ctx, *task, params.Provider)
 	strategy := strings.TrimSpace(params.Strategy)
 	if strategy == "" {
 		strategy = strings.TrimSpace(cfg.Decompose.PreferredStrategy)
@@ -946,11 +946,11 @@ func computeTaskComplexity(task model.Task, cfg pkgconfig.DecomposeConfig) (int,
 	return score, reasons
 }
 
-func (s *Server) recommendProviderForTask(task model.Task, preferred string) (string, bool) {
+func (s *Server) recommendProviderForTask(ctx context.Context, task model.Task, preferred string) (string, bool) {
 	preferred = strings.TrimSpace(preferred)
 	if preferred != "" {
 		resolved := provider.ResolveProviderName(preferred)
-		if p, ok := s.providers[resolved]; ok {
+		if p, ok := s.lookupProvider(ctx, resolved); ok {
 			return resolved, p.Capabilities().SupportsSubtasks
 		}
 		if provider.IsValidProvider(resolved) {
@@ -960,24 +960,21 @@ func (s *Server) recommendProviderForTask(task model.Task, preferred string) (st
 
 	taskSource := strings.TrimSpace(string(task.Source))
 	if provider.IsValidProvider(taskSource) {
-		if p, ok := s.providers[taskSource]; ok {
+		if p, ok := s.lookupProvider(ctx, taskSource); ok {
 			return taskSource, p.Capabilities().SupportsSubtasks
 		}
 		return taskSource, false
 	}
 
-	names := make([]string, 0, len(s.providers))
-	for name := range s.providers {
-		names = append(names, name)
-	}
-	sort.Strings(names)
+	names := s.providerNames(ctx)
 	for _, name := range names {
-		if s.providers[name].Capabilities().SupportsSubtasks {
+		if p, ok := s.lookupProvider(ctx, name); ok && p.Capabilities().SupportsSubtasks {
 			return name, true
 		}
 	}
 	if len(names) > 0 {
-		return names[0], s.providers[names[0]].Capabilities().SupportsSubtasks
+		p, _ := s.lookupProvider(ctx, names[0])
+		return names[0], p.Capabilities().SupportsSubtasks
 	}
 
 	return "local", false
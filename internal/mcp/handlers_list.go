@@ -0,0 +1,302 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+// taskSortKey 单个排序键
+type taskSortKey struct {
+	Field string
+	Desc  bool
+}
+
+var validTaskSortFields = map[string]bool{
+	"due_date":   true,
+	"priority":   true,
+	"created_at": true,
+	"updated_at": true,
+	"title":      true,
+	"quadrant":   true,
+	"status":     true,
+}
+
+// defaultTaskPageSize cursor 分页未指定 limit 时的每页条数
+const defaultTaskPageSize = 50
+
+// listTasksCursor 分页游标内容，编码为不透明字符串返回给调用方
+type listTasksCursor struct {
+	Offset int    `json:"o"`
+	Sort   string `json:"s,omitempty"`
+}
+
+// parseTaskSort 解析排序参数，如 "priority:desc,due_date" 或 "-priority,due_date"。
+func parseTaskSort(spec string) ([]taskSortKey, error) {
+	keys := make([]taskSortKey, 0)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		key := taskSortKey{}
+		switch {
+		case strings.HasPrefix(part, "-"):
+			key.Desc = true
+			part = strings.TrimPrefix(part, "-")
+		case strings.HasPrefix(part, "+"):
+			part = strings.TrimPrefix(part, "+")
+		}
+		if field, dir, ok := strings.Cut(part, ":"); ok {
+			part = strings.TrimSpace(field)
+			switch strings.TrimSpace(dir) {
+			case "desc":
+				key.Desc = true
+			case "asc", "":
+			default:
+				return nil, fmt.Errorf("invalid sort direction: %s", dir)
+			}
+		}
+		if part == "due" {
+			part = "due_date"
+		}
+		if !validTaskSortFields[part] {
+			return nil, fmt.Errorf("invalid sort field: %s", part)
+		}
+		key.Field = part
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// formatTaskSort 将排序键还原为规范化字符串，用于游标校验与回显。
+func formatTaskSort(keys []taskSortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Desc {
+			parts = append(parts, key.Field+":desc")
+		} else {
+			parts = append(parts, key.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortTasksByKeys 按多个排序键排序，最后以任务 ID 兜底保证分页稳定。
+func sortTasksByKeys(tasks []model.Task, keys []taskSortKey) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		for _, key := range keys {
+			cmp := compareTaskField(a, b, key.Field)
+			if cmp == 0 {
+				continue
+			}
+			// 无截止日期的任务始终排在最后
+			if key.Field == "due_date" && (a.DueDate == nil || b.DueDate == nil) {
+				return a.DueDate != nil
+			}
+			if key.Desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return a.ID < b.ID
+	})
+}
+
+func compareTaskField(a, b model.Task, field string) int {
+	switch field {
+	case "due_date":
+		switch {
+		case a.DueDate == nil && b.DueDate == nil:
+			return 0
+		case a.DueDate == nil:
+			return 1
+		case b.DueDate == nil:
+			return -1
+		default:
+			return a.DueDate.Compare(*b.DueDate)
+		}
+	case "priority":
+		return int(a.Priority) - int(b.Priority)
+	case "quadrant":
+		return int(a.Quadrant) - int(b.Quadrant)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	case "title":
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case "status":
+		return strings.Compare(string(a.Status), string(b.Status))
+	default:
+		return 0
+	}
+}
+
+func encodeListTasksCursor(cursor listTasksCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeListTasksCursor(value string) (listTasksCursor, error) {
+	var cursor listTasksCursor
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return cursor, fmt.Errorf("invalid cursor")
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Offset < 0 {
+		return cursor, fmt.Errorf("invalid cursor")
+	}
+	return cursor, nil
+}
+
+// resolveProjectFilter 将项目 ID 或名称解析为项目 ID 集合；无法识别的值按 ID 处理。
+func (s *Server) resolveProjectFilter(ctx context.Context, values []string) map[string]bool {
+	ids := make(map[string]bool, len(values))
+	names := s.projectNameIndex(ctx)
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if _, ok := names[value]; ok {
+			ids[value] = true
+			continue
+		}
+		matched := false
+		for id, name := range names {
+			if strings.EqualFold(strings.TrimSpace(name), value) {
+				ids[id] = true
+				matched = true
+			}
+		}
+		if !matched {
+			ids[value] = true
+		}
+	}
+	return ids
+}
+
+// parseTaskQueryArgs 解析 list_tasks 风格的过滤参数（source/adapter/list/status/quadrant/priority/tag/due/query/project），
+// 命中的过滤项写入 appliedFilters；项目过滤需在查询后用 filterTasksByProject 应用。
+func (s *Server) parseTaskQueryArgs(ctx context.Context, rawArgs map[string]json.RawMessage, appliedFilters map[string]interface{}) (storage.Query, map[string]bool, error) {
+	query := storage.Query{}
+	var projectIDs map[string]bool
+
+	if source := getString(rawArgs, "source"); source != "" {
+		resolvedSource, err := resolveProviderNameStrict(source)
+		if err != nil {
+			return query, nil, err
+		}
+		query.Sources = []model.TaskSource{model.TaskSource(resolvedSource)}
+		appliedFilters["source"] = resolvedSource
+	}
+
+	// adapter 与 source 等价，支持同时指定多个 provider
+	if values := getStringSlice(rawArgs, "adapter"); len(values) > 0 {
+		adapters := make([]string, 0, len(values))
+		for _, value := range values {
+			resolved, err := resolveProviderNameStrict(value)
+			if err != nil {
+				return query, nil, err
+			}
+			if resolved == "" {
+				continue
+			}
+			query.Sources = append(query.Sources, model.TaskSource(resolved))
+			adapters = append(adapters, resolved)
+		}
+		appliedFilters["adapter"] = adapters
+	}
+
+	if values := getStringSlice(rawArgs, "list_id"); len(values) > 0 {
+		query.ListIDs = values
+		appliedFilters["list_id"] = values
+	}
+
+	if values := getStringSlice(rawArgs, "list_name"); len(values) > 0 {
+		query.ListNames = values
+		appliedFilters["list_name"] = values
+	}
+
+	if values := getStringSlice(rawArgs, "task_id"); len(values) > 0 {
+		query.TaskIDs = values
+		appliedFilters["task_id"] = values
+	}
+
+	if values := getStringSlice(rawArgs, "status"); len(values) > 0 {
+		query.Statuses = make([]model.TaskStatus, 0, len(values))
+		for _, value := range values {
+			query.Statuses = append(query.Statuses, model.TaskStatus(value))
+		}
+		appliedFilters["status"] = values
+	}
+
+	if values := getIntSlice(rawArgs, "quadrant"); len(values) > 0 {
+		query.Quadrants = make([]model.Quadrant, 0, len(values))
+		for _, value := range values {
+			query.Quadrants = append(query.Quadrants, model.Quadrant(value))
+		}
+		appliedFilters["quadrant"] = values
+	}
+
+	if values := getIntSlice(rawArgs, "priority"); len(values) > 0 {
+		query.Priorities = make([]model.Priority, 0, len(values))
+		for _, value := range values {
+			query.Priorities = append(query.Priorities, model.Priority(value))
+		}
+		appliedFilters["priority"] = values
+	}
+
+	if values := getStringSlice(rawArgs, "tag"); len(values) > 0 {
+		query.Tags = values
+		appliedFilters["tag"] = values
+	}
+
+	if v := getString(rawArgs, "due_before"); v != "" {
+		if dueBefore, err := time.Parse("2006-01-02", v); err == nil {
+			query.DueBefore = &dueBefore
+			appliedFilters["due_before"] = v
+		}
+	}
+	if v := getString(rawArgs, "due_after"); v != "" {
+		if dueAfter, err := time.Parse("2006-01-02", v); err == nil {
+			query.DueAfter = &dueAfter
+			appliedFilters["due_after"] = v
+		}
+	}
+
+	if value := getString(rawArgs, "query"); value != "" {
+		query.QueryText = value
+		appliedFilters["query"] = value
+	}
+
+	if values := getStringSlice(rawArgs, "project"); len(values) > 0 {
+		projectIDs = s.resolveProjectFilter(ctx, values)
+		appliedFilters["project"] = values
+	}
+
+	return query, projectIDs, nil
+}
+
+// filterTasksByProject 按 tb_project_id 过滤任务；projectIDs 为 nil 时原样返回。
+func filterTasksByProject(tasks []model.Task, projectIDs map[string]bool) []model.Task {
+	if projectIDs == nil {
+		return tasks
+	}
+	filtered := tasks[:0]
+	for _, task := range tasks {
+		if projectIDs[getCustomFieldString(task, "tb_project_id")] {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
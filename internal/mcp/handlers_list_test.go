@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/project"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestParseTaskSort(t *testing.T) {
+	keys, err := parseTaskSort("-priority, due:asc,title:desc")
+	if err != nil {
+		t.Fatalf("parse sort: %v", err)
+	}
+	if got := formatTaskSort(keys); got != "priority:desc,due_date,title:desc" {
+		t.Fatalf("unexpected sort keys: %s", got)
+	}
+	if _, err := parseTaskSort("owner"); err == nil {
+		t.Fatalf("expected error for unknown sort field")
+	}
+}
+
+func TestHandleListTasksFiltersSortsAndPaginates(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+	projectStore, err := project.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new project store: %v", err)
+	}
+	s.projectStore = projectStore
+	if err := projectStore.SaveProject(context.Background(), &project.Project{ID: "proj-1", Name: "官网改版"}); err != nil {
+		t.Fatalf("save project: %v", err)
+	}
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		due := now.AddDate(0, 0, i)
+		task := &model.Task{
+			ID:       fmt.Sprintf("p%d", i),
+			Title:    fmt.Sprintf("project task %d", i),
+			Status:   model.StatusTodo,
+			Source:   model.SourceGoogle,
+			Priority: model.Priority(i % 3),
+			DueDate:  &due,
+			Metadata: &model.TaskMetadata{CustomFields: map[string]interface{}{"tb_project_id": "proj-1"}},
+		}
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+	others := []*model.Task{
+		{ID: "o1", Title: "other", Status: model.StatusTodo, Source: model.SourceGoogle},
+		{ID: "o2", Title: "todoist", Status: model.StatusTodo, Source: model.SourceTodoist, Metadata: &model.TaskMetadata{CustomFields: map[string]interface{}{"tb_project_id": "proj-1"}}},
+		{ID: "o3", Title: "done", Status: model.StatusCompleted, Source: model.SourceGoogle, Metadata: &model.TaskMetadata{CustomFields: map[string]interface{}{"tb_project_id": "proj-1"}}},
+	}
+	for _, task := range others {
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+
+	args := map[string]interface{}{
+		"adapter": "g",
+		"project": "官网改版",
+		"status":  "todo",
+		"sort":    "-priority,due_date",
+		"limit":   2,
+		"cursor":  "",
+	}
+	seen := make([]string, 0)
+	for page := 0; page < 5; page++ {
+		res, err := s.handleListTasks(ctx, buildCallToolRequest(t, args))
+		if err != nil {
+			t.Fatalf("list tasks page %d: %v", page, err)
+		}
+		out := parseJSONResult(t, res)
+		tasks, _ := out["tasks"].([]interface{})
+		for _, item := range tasks {
+			seen = append(seen, item.(map[string]interface{})["id"].(string))
+		}
+		meta, _ := out["meta"].(map[string]interface{})
+		if meta["total"] != float64(5) {
+			t.Fatalf("expected total 5, got %v", meta["total"])
+		}
+		next, _ := meta["next_cursor"].(string)
+		if next == "" {
+			break
+		}
+		args["cursor"] = next
+	}
+
+	// priority: p2=2, p1=1, p4=1, p0=0, p3=0；同优先级按截止日期升序
+	want := []string{"p2", "p1", "p4", "p0", "p3"}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Fatalf("unexpected paged order: %v", seen)
+	}
+
+	args["cursor"] = encodeListTasksCursor(listTasksCursor{Offset: 2, Sort: "title"})
+	if _, err := s.handleListTasks(ctx, buildCallToolRequest(t, args)); err == nil {
+		t.Fatalf("expected error for cursor with mismatched sort")
+	}
+}
+
+func TestHandleListTasksCursorDefaultPageSize(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+	for i := 0; i < defaultTaskPageSize+5; i++ {
+		task := &model.Task{ID: fmt.Sprintf("t%02d", i), Title: fmt.Sprintf("任务 %02d", i), Status: model.StatusTodo, Source: model.SourceLocal}
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+
+	res, err := s.handleListTasks(ctx, buildCallToolRequest(t, map[string]interface{}{"cursor": ""}))
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	tasks, _ := out["tasks"].([]interface{})
+	meta, _ := out["meta"].(map[string]interface{})
+	if len(tasks) != defaultTaskPageSize || meta["next_cursor"] == nil {
+		t.Fatalf("expected default page of %d with next_cursor, got %d (%v)", defaultTaskPageSize, len(tasks), meta)
+	}
+}
@@ -0,0 +1,446 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+const (
+	defaultPlanDayStart   = "09:00"
+	defaultPlanDayEnd     = "18:00"
+	defaultPlanBlockMins  = 30
+	defaultPlanBufferMins = 5
+)
+
+// planInterval 一段时间区间 [Start, End)
+type planInterval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// planBlock 日程中的一个时间块
+type planBlock struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Minutes  int    `json:"minutes"`
+	Kind     string `json:"kind"` // task / fixed / break
+	TaskID   string `json:"task_id,omitempty"`
+	Title    string `json:"title"`
+	Source   string `json:"source,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+	Overdue  bool   `json:"overdue,omitempty"`
+
+	start time.Time
+	end   time.Time
+}
+
+// handlePlanDay 根据工作时间与合并后的待办生成时间块日程，可选回写为截止时间或时间块任务。
+func (s *Server) handlePlanDay(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	timezone := getString(rawArgs, "timezone")
+	if timezone == "" {
+		timezone = s.effectiveIntelligenceConfig().Timezone
+	}
+	loc := resolveLocation(timezone)
+	now := time.Now().In(loc)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if value := strings.TrimSpace(getString(rawArgs, "date")); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date: %s", value)
+		}
+		day = parsed
+	}
+
+	workStart, err := parseClockOnDay(day, getString(rawArgs, "start"), defaultPlanDayStart)
+	if err != nil {
+		return nil, err
+	}
+	workEnd, err := parseClockOnDay(day, getString(rawArgs, "end"), defaultPlanDayEnd)
+	if err != nil {
+		return nil, err
+	}
+	if !workEnd.After(workStart) {
+		return nil, fmt.Errorf("end must be after start")
+	}
+	// 规划今天时，已过去的时间不再安排
+	if day.Equal(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)) && now.After(workStart) {
+		workStart = now.Truncate(5 * time.Minute).Add(5 * time.Minute)
+	}
+
+	defaultMinutes, ok := getInt(rawArgs, "default_minutes")
+	if !ok || defaultMinutes <= 0 {
+		defaultMinutes = defaultPlanBlockMins
+	}
+	bufferMinutes, ok := getInt(rawArgs, "buffer_minutes")
+	if !ok || bufferMinutes < 0 {
+		bufferMinutes = defaultPlanBufferMins
+	}
+	maxTasks, _ := getInt(rawArgs, "max_tasks")
+	writeBack := strings.ToLower(strings.TrimSpace(getString(rawArgs, "write_back")))
+	if writeBack == "" {
+		writeBack = "none"
+	}
+	if writeBack != "none" && writeBack != "due_times" && writeBack != "tasks" {
+		return nil, fmt.Errorf("invalid write_back: %s", writeBack)
+	}
+	dryRun, _ := getBool(rawArgs, "dry_run")
+
+	// 休息时间
+	blocks := make([]planBlock, 0)
+	busy := make([]planInterval, 0)
+	var breaks []struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+		Title string `json:"title"`
+	}
+	if raw, ok := rawArgs["breaks"]; ok && len(raw) > 0 {
+		if err := json.Unmarshal(raw, &breaks); err != nil {
+			return nil, fmt.Errorf("invalid breaks: %w", err)
+		}
+	}
+	for _, item := range breaks {
+		start, err := parseClockOnDay(day, item.Start, "")
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClockOnDay(day, item.End, "")
+		if err != nil {
+			return nil, err
+		}
+		if !end.After(start) {
+			continue
+		}
+		title := item.Title
+		if title == "" {
+			title = "休息"
+		}
+		blocks = append(blocks, newPlanBlock("break", title, start, end, nil))
+		busy = append(busy, planInterval{Start: start, End: end})
+	}
+
+	// 合并后的待办：所有来源中未完成、未被阻塞、截止不晚于当天或进行中的任务
+	query := storage.Query{Statuses: []model.TaskStatus{model.StatusTodo, model.StatusInProgress}}
+	if source := getString(rawArgs, "source"); source != "" {
+		resolvedSource, err := resolveProviderNameStrict(source)
+		if err != nil {
+			return nil, err
+		}
+		query.Sources = []model.TaskSource{model.TaskSource(resolvedSource)}
+	}
+	tasks, err := s.taskStore.QueryTasks(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	all, err := s.taskStore.QueryTasks(ctx, storage.Query{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	byID := make(map[string]*model.Task, len(all))
+	for i := range all {
+		byID[all[i].ID] = &all[i]
+	}
+
+	nextDay := day.AddDate(0, 0, 1)
+	candidates := make([]model.Task, 0)
+	for _, task := range tasks {
+		if getCustomFieldString(task, "tb_time_block_for") != "" {
+			continue
+		}
+		blocked := false
+		for _, id := range taskBlockedBy(task) {
+			if !newBlockerView(id, byID[id]).Resolved {
+				blocked = true
+				break
+			}
+		}
+		if blocked {
+			continue
+		}
+		due, timed, hasDue := planDueDate(task, loc)
+		// 当天带具体时间的任务视为固定日程
+		if hasDue && timed && !due.Before(day) && due.Before(nextDay) {
+			end := due.Add(time.Duration(planTaskMinutes(task, defaultMinutes)) * time.Minute)
+			blocks = append(blocks, newPlanBlock("fixed", task.Title, due, end, &task))
+			busy = append(busy, planInterval{Start: due, End: end})
+			continue
+		}
+		if task.Status == model.StatusInProgress || (hasDue && due.Before(nextDay)) {
+			candidates = append(candidates, task)
+		}
+	}
+	isOverdue := func(task model.Task) bool {
+		due, _, hasDue := planDueDate(task, loc)
+		return hasDue && due.Before(day)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		oi, oj := isOverdue(candidates[i]), isOverdue(candidates[j])
+		if oi != oj {
+			return oi
+		}
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority > candidates[j].Priority
+		}
+		return compareTaskField(candidates[i], candidates[j], "due_date") < 0
+	})
+
+	free := subtractIntervals(planInterval{Start: workStart, End: workEnd}, busy)
+	unscheduled := make([]compactTask, 0)
+	scheduled := make([]*model.Task, 0)
+	buffer := time.Duration(bufferMinutes) * time.Minute
+	for i := range candidates {
+		task := candidates[i]
+		if maxTasks > 0 && len(scheduled) >= maxTasks {
+			unscheduled = append(unscheduled, toCompactTasks([]model.Task{task})...)
+			continue
+		}
+		need := time.Duration(planTaskMinutes(task, defaultMinutes)) * time.Minute
+		placed := false
+		for k := range free {
+			if free[k].End.Sub(free[k].Start) < need {
+				continue
+			}
+			start := free[k].Start
+			end := start.Add(need)
+			block := newPlanBlock("task", task.Title, start, end, &task)
+			block.Overdue = isOverdue(task)
+			blocks = append(blocks, block)
+			free[k].Start = end.Add(buffer)
+			if free[k].Start.After(free[k].End) {
+				free[k].Start = free[k].End
+			}
+			scheduled = append(scheduled, &candidates[i])
+			placed = true
+			break
+		}
+		if !placed {
+			unscheduled = append(unscheduled, toCompactTasks([]model.Task{task})...)
+		}
+	}
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].start.Before(blocks[j].start) })
+
+	plannedMinutes := 0
+	for _, block := range blocks {
+		if block.Kind == "task" {
+			plannedMinutes += block.Minutes
+		}
+	}
+	freeMinutes := 0
+	for _, interval := range free {
+		if interval.End.After(interval.Start) {
+			freeMinutes += int(interval.End.Sub(interval.Start).Minutes())
+		}
+	}
+
+	result := map[string]interface{}{
+		"date":            day.Format("2006-01-02"),
+		"timezone":        loc.String(),
+		"working_hours":   map[string]string{"start": workStart.Format("15:04"), "end": workEnd.Format("15:04")},
+		"blocks":          blocks,
+		"unscheduled":     unscheduled,
+		"planned_minutes": plannedMinutes,
+		"free_minutes":    freeMinutes,
+		"write_back":      writeBack,
+		"dry_run":         dryRun,
+	}
+
+	if writeBack != "none" && !dryRun {
+		written, errs := s.writeBackPlan(ctx, blocks, writeBack, getString(rawArgs, "provider"), getString(rawArgs, "list_id"))
+		result["written"] = written
+		if len(errs) > 0 {
+			result["errors"] = errs
+		}
+	}
+
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// writeBackPlan 回写日程：due_times 更新原任务截止时间，tasks 创建独立的时间块任务。
+func (s *Server) writeBackPlan(ctx context.Context, blocks []planBlock, mode, providerName, listID string) (int, []string) {
+	written := 0
+	errs := make([]string, 0)
+	created := make([]model.Task, 0)
+	for _, block := range blocks {
+		if block.Kind != "task" {
+			continue
+		}
+		task, err := s.taskStore.GetTask(ctx, block.TaskID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("load %s: %v", block.TaskID, err))
+			continue
+		}
+		now := time.Now()
+		setTaskCustomField(task, "tb_planned_start", block.start.Format(time.RFC3339))
+		setTaskCustomField(task, "tb_planned_end", block.end.Format(time.RFC3339))
+
+		switch mode {
+		case "due_times":
+			due := normalizeDueForProvider(task.Source, block.start)
+			task.DueDate = &due
+			task.UpdatedAt = now
+			if err := s.taskStore.SaveTask(ctx, task); err != nil {
+				errs = append(errs, fmt.Sprintf("save %s: %v", task.ID, err))
+				continue
+			}
+			if remote, note := s.pushRescheduledTask(ctx, task); remote == "failed" {
+				errs = append(errs, fmt.Sprintf("push %s: %s", task.ID, note))
+			}
+		case "tasks":
+			task.UpdatedAt = now
+			if err := s.taskStore.SaveTask(ctx, task); err != nil {
+				errs = append(errs, fmt.Sprintf("save %s: %v", task.ID, err))
+				continue
+			}
+			start := block.start
+			blockTask := model.Task{
+				ID:               fmt.Sprintf("%s_%d", generateID(), written+1),
+				Title:            fmt.Sprintf("%s-%s %s", block.Start, block.End, task.Title),
+				Status:           model.StatusTodo,
+				CreatedAt:        now,
+				UpdatedAt:        now,
+				DueDate:          &start,
+				Source:           model.SourceLocal,
+				Priority:         task.Priority,
+				Quadrant:         task.Quadrant,
+				EstimatedMinutes: block.Minutes,
+			}
+			blockTask.Metadata = &model.TaskMetadata{
+				Version:    "1.0",
+				LocalID:    blockTask.ID,
+				SyncSource: "local",
+				CustomFields: map[string]interface{}{
+					"tb_time_block_for": task.ID,
+					"tb_planned_start":  block.start.Format(time.RFC3339),
+					"tb_planned_end":    block.end.Format(time.RFC3339),
+				},
+			}
+			if err := s.taskStore.SaveTask(ctx, &blockTask); err != nil {
+				errs = append(errs, fmt.Sprintf("save block %s: %v", task.ID, err))
+				continue
+			}
+			created = append(created, blockTask)
+		}
+		written++
+	}
+
+	if mode == "tasks" && len(created) > 0 && strings.TrimSpace(providerName) != "" {
+		resolved, err := resolveProviderNameStrict(providerName)
+		if err != nil {
+			return written, append(errs, err.Error())
+		}
+		p, ok := s.lookupProvider(ctx, resolved)
+		if !ok || p == nil || !p.IsAuthenticated() {
+			return written, append(errs, fmt.Sprintf("provider %s not found or not authenticated", resolved))
+		}
+		push, err := s.pushTaskTreeToProvider(ctx, p, resolved, created, listID)
+		if err != nil {
+			return written, append(errs, err.Error())
+		}
+		errs = append(errs, push.Errors...)
+	}
+	return written, errs
+}
+
+func newPlanBlock(kind, title string, start, end time.Time, task *model.Task) planBlock {
+	block := planBlock{
+		Start:   start.Format("15:04"),
+		End:     end.Format("15:04"),
+		Minutes: int(end.Sub(start).Minutes()),
+		Kind:    kind,
+		Title:   title,
+		start:   start,
+		end:     end,
+	}
+	if task != nil {
+		block.TaskID = task.ID
+		block.Source = string(task.Source)
+		block.Priority = int(task.Priority)
+	}
+	return block
+}
+
+// planTaskMinutes 返回任务需要安排的时长：优先使用剩余预估时间，否则使用默认时长。
+func planTaskMinutes(task model.Task, defaultMinutes int) int {
+	if task.EstimatedMinutes > 0 {
+		remaining := task.EstimatedMinutes - task.ActualMinutes
+		if remaining >= 15 {
+			return remaining
+		}
+		return 15
+	}
+	return defaultMinutes
+}
+
+// parseClockOnDay 将 HH:MM 解析为指定日期上的时间点。
+func parseClockOnDay(day time.Time, value, fallback string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = fallback
+	}
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, day.Location()), nil
+}
+
+// planDueDate 返回任务在 loc 中的截止时间及是否带具体时刻。
+// 仅保存日期的截止时间（如 Google Tasks 的 UTC 零点）按日期解释，避免时区偏移成固定时刻。
+func planDueDate(task model.Task, loc *time.Location) (time.Time, bool, bool) {
+	if task.DueDate == nil {
+		return time.Time{}, false, false
+	}
+	utc := task.DueDate.UTC()
+	if task.Source == model.SourceGoogle || (utc.Hour() == 0 && utc.Minute() == 0 && utc.Second() == 0) {
+		return time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, loc), false, true
+	}
+	local := task.DueDate.In(loc)
+	return local, local.Hour() != 0 || local.Minute() != 0, true
+}
+
+// subtractIntervals 从 window 中扣除 busy 区间，返回按时间排序的空闲区间。
+func subtractIntervals(window planInterval, busy []planInterval) []planInterval {
+	sorted := append([]planInterval(nil), busy...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+	free := make([]planInterval, 0, len(sorted)+1)
+	cursor := window.Start
+	for _, b := range sorted {
+		if !b.End.After(cursor) {
+			continue
+		}
+		if b.Start.After(window.End) {
+			break
+		}
+		if b.Start.After(cursor) {
+			free = append(free, planInterval{Start: cursor, End: b.Start})
+		}
+		cursor = b.End
+	}
+	if window.End.After(cursor) {
+		free = append(free, planInterval{Start: cursor, End: window.End})
+	}
+	return free
+}
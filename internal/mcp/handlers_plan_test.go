@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestHandlePlanDayBuildsTimeBlocks(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	cfg.Timezone = "UTC"
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	yesterday := day.AddDate(0, 0, -2)
+	meeting := day.Add(14 * time.Hour)
+	nextWeek := day.AddDate(0, 0, 7)
+	seed := []model.Task{
+		{ID: "a", Title: "写周报", Status: model.StatusTodo, Priority: model.PriorityUrgent, DueDate: &day, EstimatedMinutes: 60},
+		{ID: "b", Title: "回复邮件", Status: model.StatusTodo, Priority: model.PriorityMedium, DueDate: &yesterday},
+		{ID: "c", Title: "评审会", Status: model.StatusTodo, DueDate: &meeting, EstimatedMinutes: 60},
+		{ID: "d", Title: "下周任务", Status: model.StatusTodo, Priority: model.PriorityUrgent, DueDate: &nextWeek},
+		{ID: "e", Title: "长任务", Status: model.StatusInProgress, Priority: model.PriorityLow, EstimatedMinutes: 240},
+	}
+	for i := range seed {
+		seed[i].Source = model.SourceLocal
+		if err := store.SaveTask(ctx, &seed[i]); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+
+	res, err := s.handlePlanDay(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"date":       day.Format("2006-01-02"),
+		"breaks":     []map[string]string{{"start": "12:00", "end": "13:00", "title": "午饭"}},
+		"write_back": "due_times",
+	}))
+	if err != nil {
+		t.Fatalf("plan day: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	blocks, _ := out["blocks"].([]interface{})
+	type row struct{ start, end, kind, id string }
+	want := []row{
+		{"09:00", "09:30", "task", "b"},
+		{"09:35", "10:35", "task", "a"},
+		{"12:00", "13:00", "break", ""},
+		{"14:00", "15:00", "fixed", "c"},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("unexpected blocks: %v", blocks)
+	}
+	for i, item := range blocks {
+		block := item.(map[string]interface{})
+		id, _ := block["task_id"].(string)
+		got := row{block["start"].(string), block["end"].(string), block["kind"].(string), id}
+		if got != want[i] {
+			t.Fatalf("block %d: got %+v want %+v", i, got, want[i])
+		}
+	}
+	unscheduled, _ := out["unscheduled"].([]interface{})
+	if len(unscheduled) != 1 || unscheduled[0].(map[string]interface{})["id"] != "e" {
+		t.Fatalf("expected long task unscheduled, got %v", unscheduled)
+	}
+	if out["written"] != float64(2) {
+		t.Fatalf("expected 2 tasks written back, got %v (%v)", out["written"], out["errors"])
+	}
+
+	updated, err := store.GetTask(ctx, "b")
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if updated.DueDate == nil || !updated.DueDate.Equal(day.Add(9*time.Hour)) {
+		t.Fatalf("expected due time 09:00, got %v", updated.DueDate)
+	}
+	if getCustomFieldString(*updated, "tb_planned_end") == "" {
+		t.Fatalf("expected planned end recorded")
+	}
+}
+
+func TestSubtractIntervals(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	free := subtractIntervals(planInterval{Start: at(9), End: at(18)}, []planInterval{
+		{Start: at(12), End: at(13)},
+		{Start: at(8), End: at(10)},
+		{Start: at(17), End: at(19)},
+	})
+	if len(free) != 2 || !free[0].Start.Equal(at(10)) || !free[0].End.Equal(at(12)) || !free[1].Start.Equal(at(13)) || !free[1].End.Equal(at(17)) {
+		t.Fatalf("unexpected free intervals: %+v", free)
+	}
+}
@@ -0,0 +1,339 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+// maxPromptTasksPerSection 每个数据段最多嵌入的任务数，避免提示词过长
+const maxPromptTasksPerSection = 50
+
+// promptScopedTasks 按提示词参数 adapter/project 过滤本地缓存中的任务，并返回范围描述。
+func (s *Server) promptScopedTasks(ctx context.Context, args map[string]string) ([]model.Task, string, error) {
+	if s.taskStore == nil {
+		return nil, "全部任务", nil
+	}
+	source, err := resolveResourceAdapter(args["adapter"])
+	if err != nil {
+		return nil, "", err
+	}
+
+	rawArgs := make(map[string]json.RawMessage)
+	scope := make([]string, 0, 2)
+	if source != "" {
+		scope = append(scope, "来源 "+string(source))
+	}
+	if project := strings.TrimSpace(args["project"]); project != "" {
+		data, _ := json.Marshal(project)
+		rawArgs["project"] = data
+		scope = append(scope, "项目 "+project)
+	}
+	query, projectIDs, err := s.parseTaskQueryArgs(ctx, rawArgs, make(map[string]interface{}))
+	if err != nil {
+		return nil, "", err
+	}
+	found, err := s.taskStore.QueryTasks(ctx, query)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query tasks: %w", err)
+	}
+	tasks := make([]model.Task, 0, len(found))
+	for _, task := range filterTasksByProject(found, projectIDs) {
+		if taskMatchesSource(task, source) {
+			tasks = append(tasks, task)
+		}
+	}
+	if len(scope) == 0 {
+		return tasks, "全部任务", nil
+	}
+	return tasks, strings.Join(scope, "，"), nil
+}
+
+// writePromptTaskSection 以 Markdown 列表写入一组任务，超过上限时注明省略数量。
+func writePromptTaskSection(b *strings.Builder, title string, tasks []model.Task, projectNames map[string]string, loc *time.Location) {
+	fmt.Fprintf(b, "### %s（%d）\n\n", title, len(tasks))
+	if len(tasks) == 0 {
+		b.WriteString("- 无\n\n")
+		return
+	}
+	for i, task := range tasks {
+		if i == maxPromptTasksPerSection {
+			fmt.Fprintf(b, "- …另有 %d 项未列出\n", len(tasks)-maxPromptTasksPerSection)
+			break
+		}
+		b.WriteString(formatPromptTaskLine(task, projectNames, loc))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+}
+
+func formatPromptTaskLine(task model.Task, projectNames map[string]string, loc *time.Location) string {
+	details := make([]string, 0, 4)
+	source := string(task.Source)
+	if source == "" {
+		source = string(model.SourceLocal)
+	}
+	details = append(details, "来源: "+source)
+	if projectID := getCustomFieldString(task, "tb_project_id"); projectID != "" {
+		name := projectNames[projectID]
+		if name == "" {
+			name = projectID
+		}
+		details = append(details, "项目: "+name)
+	} else if task.ListName != "" {
+		details = append(details, "清单: "+task.ListName)
+	}
+	if task.DueDate != nil {
+		details = append(details, "截止: "+task.DueDate.In(loc).Format("2006-01-02"))
+	}
+	if task.Priority > 0 {
+		details = append(details, fmt.Sprintf("优先级: %d", task.Priority))
+	}
+	return fmt.Sprintf("- [%s] %s（%s）", task.ID, task.Title, strings.Join(details, "，"))
+}
+
+// promptMessageResult 构造单条 user 消息的提示词结果
+func promptMessageResult(description, text string) *mcp.GetPromptResult {
+	return &mcp.GetPromptResult{
+		Description: description,
+		Messages: []*mcp.PromptMessage{
+			{
+				Role:    "user",
+				Content: &mcp.TextContent{Text: text},
+			},
+		},
+	}
+}
+
+// handleWeeklyReviewPrompt 处理每周回顾提示词请求，嵌入上一自然周（周一至周日）完成与延误的任务。
+func (s *Server) handleWeeklyReviewPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	var args map[string]string
+	if req != nil && req.Params != nil {
+		args = req.Params.Arguments
+	}
+	tasks, scope, err := s.promptScopedTasks(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := resolveLocation(s.effectiveIntelligenceConfig().Timezone)
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	weekdayOffset := (int(today.Weekday()) + 6) % 7
+	weekEnd := today.AddDate(0, 0, -weekdayOffset)
+	weekStart := weekEnd.AddDate(0, 0, -7)
+	inWeek := func(t time.Time) bool {
+		return !t.Before(weekStart) && t.Before(weekEnd)
+	}
+
+	completed := make([]model.Task, 0)
+	slipped := make([]model.Task, 0)
+	for _, task := range tasks {
+		completedAt := completionTime(task)
+		if task.Status == model.StatusCompleted && completedAt != nil && inWeek(completedAt.In(loc)) {
+			completed = append(completed, task)
+		}
+		// 延误：截止日期落在上周，但截止时仍未完成（含至今未完成与逾期后才完成）
+		if task.DueDate == nil || task.Status == model.StatusCancelled || !inWeek(task.DueDate.In(loc)) {
+			continue
+		}
+		if task.Status != model.StatusCompleted || (completedAt != nil && completedAt.After(*task.DueDate)) {
+			slipped = append(slipped, task)
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completionTime(completed[i]).Before(*completionTime(completed[j]))
+	})
+	sort.SliceStable(slipped, func(i, j int) bool {
+		return slipped[i].DueDate.Before(*slipped[j].DueDate)
+	})
+
+	projectNames := s.projectNameIndex(ctx)
+	var b strings.Builder
+	fmt.Fprintf(&b, "## 上周回顾数据\n\n- 周期: %s ~ %s\n- 范围: %s\n\n",
+		weekStart.Format("2006-01-02"), weekEnd.AddDate(0, 0, -1).Format("2006-01-02"), scope)
+	writePromptTaskSection(&b, "上周已完成", completed, projectNames, loc)
+	writePromptTaskSection(&b, "上周延误", slipped, projectNames, loc)
+	b.WriteString(EmbeddedPrompts["weekly_review"])
+
+	return promptMessageResult("每周回顾提示词", b.String()), nil
+}
+
+const defaultTriageBacklogLimit = 10
+
+// handleTriageBacklogPrompt 处理积压任务分诊提示词请求，嵌入最久未更新的未完成任务。
+func (s *Server) handleTriageBacklogPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	var args map[string]string
+	if req != nil && req.Params != nil {
+		args = req.Params.Arguments
+	}
+	limit := defaultTriageBacklogLimit
+	if v := strings.TrimSpace(args["limit"]); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid limit: %s", v)
+		}
+		limit = parsed
+	}
+	if limit > maxPromptTasksPerSection {
+		limit = maxPromptTasksPerSection
+	}
+
+	tasks, scope, err := s.promptScopedTasks(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	backlog := make([]model.Task, 0, len(tasks))
+	for _, task := range tasks {
+		switch task.Status {
+		case model.StatusCompleted, model.StatusCancelled:
+			continue
+		}
+		backlog = append(backlog, task)
+	}
+	lastTouched := func(task model.Task) time.Time {
+		if task.UpdatedAt.IsZero() {
+			return task.CreatedAt
+		}
+		return task.UpdatedAt
+	}
+	sort.SliceStable(backlog, func(i, j int) bool {
+		a, b := lastTouched(backlog[i]), lastTouched(backlog[j])
+		if a.Equal(b) {
+			return backlog[i].ID < backlog[j].ID
+		}
+		return a.Before(b)
+	})
+	total := len(backlog)
+	if len(backlog) > limit {
+		backlog = backlog[:limit]
+	}
+
+	loc := resolveLocation(s.effectiveIntelligenceConfig().Timezone)
+	now := time.Now().In(loc)
+	projectNames := s.projectNameIndex(ctx)
+	var b strings.Builder
+	fmt.Fprintf(&b, "## 待分诊任务\n\n- 范围: %s\n- 未完成任务共 %d 项，以下为最久未更新的 %d 项\n\n", scope, total, len(backlog))
+	if len(backlog) == 0 {
+		b.WriteString("- 无\n")
+	}
+	for _, task := range backlog {
+		touched := lastTouched(task)
+		idle := "未知"
+		if !touched.IsZero() {
+			idle = fmt.Sprintf("%s（%d 天前）", touched.In(loc).Format("2006-01-02"), int(now.Sub(touched).Hours()/24))
+		}
+		fmt.Fprintf(&b, "%s，上次更新: %s\n", formatPromptTaskLine(task, projectNames, loc), idle)
+	}
+	b.WriteString("\n")
+	b.WriteString(EmbeddedPrompts["triage_backlog"])
+
+	return promptMessageResult("积压任务分诊提示词", b.String()), nil
+}
+
+// handleStandupSummaryPrompt 处理站会摘要提示词请求，嵌入昨天完成、今天计划（今日到期、逾期或进行中）与被阻塞的任务。
+func (s *Server) handleStandupSummaryPrompt(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	var args map[string]string
+	if req != nil && req.Params != nil {
+		args = req.Params.Arguments
+	}
+	tasks, scope, err := s.promptScopedTasks(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	// 阻塞项可能不在过滤范围内，需在全部任务中判断是否已解决
+	byID := make(map[string]*model.Task)
+	if s.taskStore != nil {
+		all, err := s.taskStore.ListTasks(ctx, storage.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tasks: %w", err)
+		}
+		for i := range all {
+			byID[all[i].ID] = &all[i]
+		}
+	}
+
+	loc := resolveLocation(s.effectiveIntelligenceConfig().Timezone)
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	yesterday := today.AddDate(0, 0, -1)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	done := make([]model.Task, 0)
+	planned := make([]model.Task, 0)
+	blocked := make([]model.Task, 0)
+	blockerNotes := make(map[string][]string)
+	for _, task := range tasks {
+		if task.Status == model.StatusCompleted {
+			if completedAt := completionTime(task); completedAt != nil {
+				local := completedAt.In(loc)
+				if !local.Before(yesterday) && local.Before(today) {
+					done = append(done, task)
+				}
+			}
+			continue
+		}
+		if task.Status != model.StatusTodo && task.Status != model.StatusInProgress {
+			continue
+		}
+		pending := make([]string, 0)
+		for _, id := range taskBlockedBy(task) {
+			if view := newBlockerView(id, byID[id]); !view.Resolved {
+				label := id
+				if view.Title != "" {
+					label = view.Title
+				}
+				pending = append(pending, label)
+			}
+		}
+		if len(pending) > 0 {
+			blocked = append(blocked, task)
+			blockerNotes[task.ID] = pending
+			continue
+		}
+		if task.Status == model.StatusInProgress || (task.DueDate != nil && task.DueDate.In(loc).Before(tomorrow)) {
+			planned = append(planned, task)
+		}
+	}
+	sortTasksByKeys(planned, []taskSortKey{{Field: "priority", Desc: true}, {Field: "due_date"}})
+
+	projectNames := s.projectNameIndex(ctx)
+	var b strings.Builder
+	fmt.Fprintf(&b, "## 站会数据\n\n- 日期: %s\n- 范围: %s\n\n", today.Format("2006-01-02"), scope)
+	writePromptTaskSection(&b, "昨天完成", done, projectNames, loc)
+	fmt.Fprintf(&b, "### 今天计划（%d）\n\n", len(planned))
+	if len(planned) == 0 {
+		b.WriteString("- 无\n")
+	}
+	for i, task := range planned {
+		if i == maxPromptTasksPerSection {
+			fmt.Fprintf(&b, "- …另有 %d 项未列出\n", len(planned)-maxPromptTasksPerSection)
+			break
+		}
+		line := formatPromptTaskLine(task, projectNames, loc)
+		if task.DueDate != nil && task.DueDate.In(loc).Before(today) {
+			line += "，逾期"
+		}
+		b.WriteString(line + "\n")
+	}
+	fmt.Fprintf(&b, "\n### 阻塞（%d）\n\n", len(blocked))
+	if len(blocked) == 0 {
+		b.WriteString("- 无\n")
+	}
+	for _, task := range blocked {
+		fmt.Fprintf(&b, "%s，被阻塞于: %s\n", formatPromptTaskLine(task, projectNames, loc), strings.Join(blockerNotes[task.ID], "、"))
+	}
+	b.WriteString("\n")
+	b.WriteString(EmbeddedPrompts["standup_summary"])
+
+	return promptMessageResult("站会摘要提示词", b.String()), nil
+}
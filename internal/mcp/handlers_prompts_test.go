@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func promptText(t *testing.T, result *sdkmcp.GetPromptResult) string {
+	t.Helper()
+	if result == nil || len(result.Messages) != 1 {
+		t.Fatalf("unexpected prompt result: %+v", result)
+	}
+	text, ok := result.Messages[0].Content.(*sdkmcp.TextContent)
+	if !ok {
+		t.Fatalf("unexpected prompt content: %T", result.Messages[0].Content)
+	}
+	return text.Text
+}
+
+func TestHandleWeeklyReviewPrompt(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	loc := resolveLocation(cfg.Timezone)
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	lastWeekMid := today.AddDate(0, 0, -((int(today.Weekday())+6)%7)-5).Add(12 * time.Hour)
+	lateDone := lastWeekMid.Add(24 * time.Hour)
+	older := lastWeekMid.AddDate(0, 0, -14)
+
+	tasks := []*model.Task{
+		{ID: "done-last-week", Title: "发布 v1.2", Status: model.StatusCompleted, Source: model.SourceLocal, CompletedAt: &lastWeekMid},
+		{ID: "slipped-open", Title: "更新文档", Status: model.StatusTodo, Source: model.SourceLocal, DueDate: &lastWeekMid},
+		{ID: "slipped-late", Title: "提交报销", Status: model.StatusCompleted, Source: model.SourceGoogle, DueDate: &lastWeekMid, CompletedAt: &lateDone},
+		{ID: "done-long-ago", Title: "旧任务", Status: model.StatusCompleted, Source: model.SourceLocal, CompletedAt: &older},
+		{ID: "cancelled", Title: "取消的会议", Status: model.StatusCancelled, Source: model.SourceLocal, DueDate: &lastWeekMid},
+	}
+	for _, task := range tasks {
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+
+	result, err := s.handleWeeklyReviewPrompt(ctx, &sdkmcp.GetPromptRequest{Params: &sdkmcp.GetPromptParams{Name: "weekly_review"}})
+	if err != nil {
+		t.Fatalf("weekly review prompt: %v", err)
+	}
+	text := promptText(t, result)
+	for _, want := range []string{"上周已完成（2）", "[done-last-week] 发布 v1.2", "上周延误（2）", "[slipped-open] 更新文档", "[slipped-late] 提交报销", "GTD"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected prompt to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "done-long-ago") || strings.Contains(text, "[cancelled]") {
+		t.Fatalf("unexpected tasks outside last week in prompt:\n%s", text)
+	}
+
+	result, err = s.handleWeeklyReviewPrompt(ctx, &sdkmcp.GetPromptRequest{Params: &sdkmcp.GetPromptParams{
+		Name:      "weekly_review",
+		Arguments: map[string]string{"adapter": "google"},
+	}})
+	if err != nil {
+		t.Fatalf("weekly review prompt with adapter: %v", err)
+	}
+	text = promptText(t, result)
+	if !strings.Contains(text, "范围: 来源 google") || !strings.Contains(text, "上周延误（1）") || strings.Contains(text, "slipped-open") {
+		t.Fatalf("expected google-only review, got:\n%s", text)
+	}
+}
+
+func TestHandleTriageBacklogPrompt(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	now := time.Now()
+	seeds := []struct {
+		task    *model.Task
+		idleDay int
+	}{
+		{&model.Task{ID: "stale-1", Title: "整理旧文档", Status: model.StatusTodo, Source: model.SourceLocal, ListName: "工作"}, 120},
+		{&model.Task{ID: "stale-2", Title: "研究新框架", Status: model.StatusDeferred, Source: model.SourceLocal, ListName: "工作"}, 90},
+		{&model.Task{ID: "fresh", Title: "回复邮件", Status: model.StatusTodo, Source: model.SourceLocal, ListName: "工作"}, 1},
+		{&model.Task{ID: "done", Title: "已完成", Status: model.StatusCompleted, Source: model.SourceLocal, ListName: "工作"}, 200},
+	}
+	for _, seed := range seeds {
+		if err := store.SaveTask(ctx, seed.task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+		// SaveTask 会刷新 UpdatedAt，这里直接回写模拟长期未更新
+		seed.task.UpdatedAt = now.AddDate(0, 0, -seed.idleDay)
+	}
+
+	result, err := s.handleTriageBacklogPrompt(ctx, &sdkmcp.GetPromptRequest{Params: &sdkmcp.GetPromptParams{
+		Name:      "triage_backlog",
+		Arguments: map[string]string{"limit": "2", "adapter": "local"},
+	}})
+	if err != nil {
+		t.Fatalf("triage backlog prompt: %v", err)
+	}
+	text := promptText(t, result)
+	first := strings.Index(text, "[stale-1]")
+	second := strings.Index(text, "[stale-2]")
+	if first < 0 || second < 0 || first > second {
+		t.Fatalf("expected stalest tasks in order, got:\n%s", text)
+	}
+	if strings.Contains(text, "[fresh]") || strings.Contains(text, "[done]") {
+		t.Fatalf("unexpected tasks in triage prompt:\n%s", text)
+	}
+	if !strings.Contains(text, "未完成任务共 3 项") || !strings.Contains(text, "delete_task") {
+		t.Fatalf("missing summary or instructions:\n%s", text)
+	}
+
+	if _, err := s.handleTriageBacklogPrompt(ctx, &sdkmcp.GetPromptRequest{Params: &sdkmcp.GetPromptParams{
+		Name:      "triage_backlog",
+		Arguments: map[string]string{"limit": "abc"},
+	}}); err == nil {
+		t.Fatalf("expected error for invalid limit")
+	}
+}
+
+func TestHandleStandupSummaryPrompt(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	loc := resolveLocation(cfg.Timezone)
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	yesterdayNoon := today.Add(-12 * time.Hour)
+	todayNoon := today.Add(12 * time.Hour)
+	lastWeek := today.AddDate(0, 0, -7)
+	nextWeek := today.AddDate(0, 0, 7)
+
+	blockedTask := &model.Task{ID: "blocked", Title: "上线发布", Status: model.StatusTodo, Source: model.SourceLocal, DueDate: &todayNoon}
+	setTaskCustomField(blockedTask, taskBlockedByField, []string{"review"})
+	tasks := []*model.Task{
+		{ID: "done-yesterday", Title: "修复登录问题", Status: model.StatusCompleted, Source: model.SourceLocal, CompletedAt: &yesterdayNoon},
+		{ID: "done-earlier", Title: "旧的完成项", Status: model.StatusCompleted, Source: model.SourceLocal, CompletedAt: &lastWeek},
+		{ID: "due-today", Title: "写测试", Status: model.StatusTodo, Source: model.SourceLocal, DueDate: &todayNoon, Priority: 3},
+		{ID: "overdue", Title: "补文档", Status: model.StatusTodo, Source: model.SourceLocal, DueDate: &lastWeek},
+		{ID: "later", Title: "下周计划", Status: model.StatusTodo, Source: model.SourceLocal, DueDate: &nextWeek},
+		{ID: "review", Title: "代码评审", Status: model.StatusInProgress, Source: model.SourceGoogle},
+		blockedTask,
+	}
+	for _, task := range tasks {
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+
+	result, err := s.handleStandupSummaryPrompt(ctx, &sdkmcp.GetPromptRequest{Params: &sdkmcp.GetPromptParams{Name: "standup_summary"}})
+	if err != nil {
+		t.Fatalf("standup summary prompt: %v", err)
+	}
+	text := promptText(t, result)
+	for _, want := range []string{"昨天完成（1）", "[done-yesterday]", "今天计划（3）", "[due-today]", "[overdue] 补文档", "逾期", "[review]", "阻塞（1）", "被阻塞于: 代码评审", "Blockers"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected prompt to contain %q, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "[later]") || strings.Contains(text, "[done-earlier]") {
+		t.Fatalf("unexpected tasks in standup prompt:\n%s", text)
+	}
+
+	// 按来源过滤时，范围外的阻塞项仍用于判断阻塞状态
+	result, err = s.handleStandupSummaryPrompt(ctx, &sdkmcp.GetPromptRequest{Params: &sdkmcp.GetPromptParams{
+		Name:      "standup_summary",
+		Arguments: map[string]string{"adapter": "local"},
+	}})
+	if err != nil {
+		t.Fatalf("standup summary prompt with adapter: %v", err)
+	}
+	text = promptText(t, result)
+	if strings.Contains(text, "[review]") || !strings.Contains(text, "被阻塞于: 代码评审") {
+		t.Fatalf("expected local-only standup with cross-source blocker, got:\n%s", text)
+	}
+}
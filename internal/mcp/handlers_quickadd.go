@@ -0,0 +1,533 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+var (
+	quickAddPriorityPattern = regexp.MustCompile(`^(?i)p([1-4])$`)
+	quickAddClockPattern    = regexp.MustCompile(`^(?i)(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	quickAddInPattern       = regexp.MustCompile(`^(?i)(\d+)\s*(d|day|days|w|week|weeks|h|hour|hours)$`)
+	quickAddSlashDate       = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})$`)
+	quickAddCNWeekday       = regexp.MustCompile(`^(下|这|本)?(?:周|星期)([一二三四五六日天])$`)
+)
+
+var quickAddWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var quickAddCNWeekdays = map[string]time.Weekday{
+	"一": time.Monday, "二": time.Tuesday, "三": time.Wednesday, "四": time.Thursday,
+	"五": time.Friday, "六": time.Saturday, "日": time.Sunday, "天": time.Sunday,
+}
+
+var quickAddMonths = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "sept": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// quickAddParsed quick_add 语法解析结果
+type quickAddParsed struct {
+	Title    string     `json:"title"`
+	Project  string     `json:"project,omitempty"`
+	Labels   []string   `json:"labels,omitempty"`
+	Priority int        `json:"priority,omitempty"`
+	Due      *time.Time `json:"due,omitempty"`
+	HasTime  bool       `json:"has_time,omitempty"`
+	DateText string     `json:"date_text,omitempty"`
+}
+
+// handleQuickAdd 解析 Todoist 风格的单行输入（#项目 @标签 p1 明天 9am）并创建任务。
+func (s *Server) handleQuickAdd(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	text := strings.TrimSpace(getString(rawArgs, "text"))
+	if text == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+	providerName, err := resolveProviderNameStrict(getString(rawArgs, "provider"))
+	if err != nil {
+		return nil, err
+	}
+	if providerName == "" {
+		providerName = string(model.SourceLocal)
+	}
+	timezone := getString(rawArgs, "timezone")
+	if timezone == "" {
+		timezone = s.effectiveIntelligenceConfig().Timezone
+	}
+	now := time.Now().In(resolveLocation(timezone))
+
+	parsed := parseQuickAdd(text, now)
+	if parsed.Title == "" {
+		return nil, fmt.Errorf("title is empty after parsing %q", text)
+	}
+	dryRun, _ := getBool(rawArgs, "dry_run")
+	notes := make([]string, 0)
+
+	// 可选：借助客户端 sampling 整理杂乱的输入
+	var enrichment *quickAddEnrichment
+	if enrich, _ := getBool(rawArgs, "enrich"); enrich {
+		if session := samplingSession(req); session == nil {
+			notes = append(notes, "client does not support sampling, enrichment skipped")
+		} else if enrichment, err = s.sampleQuickAddEnrichment(ctx, session, text, parsed); err != nil {
+			notes = append(notes, fmt.Sprintf("sampling failed, enrichment skipped: %v", err))
+			enrichment = nil
+		}
+	}
+
+	task := &model.Task{
+		ID:        generateID(),
+		Title:     parsed.Title,
+		Status:    model.StatusTodo,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Source:    model.SourceLocal,
+		Tags:      parsed.Labels,
+		Priority:  model.Priority(parsed.Priority),
+		DueDate:   parsed.Due,
+	}
+	if enrichment != nil {
+		enrichment.apply(task)
+	}
+	task.Quadrant = model.CalculateQuadrantFromTask(task)
+	task.Metadata = &model.TaskMetadata{
+		Version:    "1.0",
+		Quadrant:   int(task.Quadrant),
+		Priority:   int(task.Priority),
+		LocalID:    task.ID,
+		SyncSource: "local",
+	}
+
+	// #项目：优先匹配本地项目，再匹配目标 provider 的清单
+	listID := strings.TrimSpace(getString(rawArgs, "list_id"))
+	if parsed.Project != "" {
+		matchedProject := false
+		names := s.projectNameIndex(ctx)
+		for id := range s.resolveProjectFilter(ctx, []string{parsed.Project}) {
+			if _, ok := names[id]; ok {
+				setTaskCustomField(task, "tb_project_id", id)
+				matchedProject = true
+			}
+		}
+		matchedList := false
+		if providerName != string(model.SourceLocal) && listID == "" {
+			if p, ok := s.lookupProvider(ctx, providerName); ok && p != nil && p.IsAuthenticated() {
+				if lists, err := p.ListTaskLists(ctx); err == nil {
+					for _, list := range lists {
+						if strings.EqualFold(strings.TrimSpace(list.Name), parsed.Project) {
+							listID = list.ID
+							task.ListName = list.Name
+							matchedList = true
+							break
+						}
+					}
+				}
+			}
+		}
+		if providerName == string(model.SourceLocal) && !matchedProject {
+			task.ListName = parsed.Project
+			matchedList = true
+		}
+		if !matchedProject && !matchedList {
+			notes = append(notes, fmt.Sprintf("project %q not found, using default list", parsed.Project))
+		}
+	}
+	task.ListID = listID
+
+	result := map[string]interface{}{
+		"parsed":   parsed,
+		"provider": providerName,
+		"dry_run":  dryRun,
+	}
+	if enrichment != nil {
+		result["enrichment"] = enrichment
+	}
+	if dryRun {
+		result["task"] = task
+		result["notes"] = notes
+		return quickAddResult(result)
+	}
+
+	if err := s.taskStore.SaveTask(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to save task: %w", err)
+	}
+	if providerName != string(model.SourceLocal) {
+		p, ok := s.lookupProvider(ctx, providerName)
+		if !ok || p == nil || !p.IsAuthenticated() {
+			notes = append(notes, fmt.Sprintf("provider %s not found or not authenticated, task kept locally", providerName))
+		} else {
+			push, err := s.pushTaskTreeToProvider(ctx, p, providerName, []model.Task{*task}, listID)
+			if err != nil {
+				notes = append(notes, err.Error())
+			} else {
+				result["remote"] = push
+			}
+			if saved, err := s.taskStore.GetTask(ctx, task.ID); err == nil {
+				task = saved
+			}
+		}
+	}
+	result["task"] = task
+	result["notes"] = notes
+	return quickAddResult(result)
+}
+
+// quickAddEnrichment sampling 生成的标题、描述与建议标签
+type quickAddEnrichment struct {
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// maxQuickAddSuggestedLabels sampling 建议标签的上限
+const maxQuickAddSuggestedLabels = 5
+
+// sampleQuickAddEnrichment 请求客户端 LLM 把原始输入整理为干净的标题、描述与标签。
+func (s *Server) sampleQuickAddEnrichment(ctx context.Context, session *mcp.ServerSession, text string, parsed quickAddParsed) (*quickAddEnrichment, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "原始输入：%s\n", text)
+	fmt.Fprintf(&prompt, "解析出的标题：%s\n", parsed.Title)
+	if len(parsed.Labels) > 0 {
+		fmt.Fprintf(&prompt, "已有标签：%s\n", strings.Join(parsed.Labels, ", "))
+	}
+	if labels := s.labelCompletions(ctx, ""); len(labels) > 0 {
+		if len(labels) > 20 {
+			labels = labels[:20]
+		}
+		fmt.Fprintf(&prompt, "常用标签（优先复用）：%s\n", strings.Join(labels, ", "))
+	}
+
+	res, err := session.CreateMessage(ctx, &mcp.CreateMessageParams{
+		SystemPrompt: `你是任务整理助手。把用户随手记下的文本整理为一条待办：标题简洁、以动词开头、不含日期/优先级/标签语法；描述补充原文中的细节（没有则留空）；建议不超过 5 个小写标签。只输出 JSON 对象 {"title": "...", "description": "...", "labels": ["..."]}，不要输出其他内容。`,
+		Messages: []*mcp.SamplingMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: prompt.String()}},
+		},
+		MaxTokens:   512,
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return nil, err
+	}
+	content, ok := res.Content.(*mcp.TextContent)
+	if !ok {
+		return nil, fmt.Errorf("sampling returned non-text content")
+	}
+	return parseQuickAddEnrichment(content.Text)
+}
+
+// parseQuickAddEnrichment 从 sampling 输出中提取 JSON 对象并清理字段。
+func parseQuickAddEnrichment(text string) (*quickAddEnrichment, error) {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("sampling returned no JSON object")
+	}
+	var raw quickAddEnrichment
+	if err := json.Unmarshal([]byte(text[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("invalid sampling output: %w", err)
+	}
+	out := &quickAddEnrichment{
+		Title:       sanitizeMarkdownText(raw.Title),
+		Description: strings.TrimSpace(raw.Description),
+	}
+	seen := make(map[string]bool)
+	for _, label := range raw.Labels {
+		label = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(label), "@#"))
+		key := strings.ToLower(label)
+		if label == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out.Labels = append(out.Labels, label)
+		if len(out.Labels) == maxQuickAddSuggestedLabels {
+			break
+		}
+	}
+	if out.Title == "" && out.Description == "" && len(out.Labels) == 0 {
+		return nil, fmt.Errorf("sampling returned empty enrichment")
+	}
+	return out, nil
+}
+
+// apply 写入整理结果；显式语法中的 @标签 保留，建议标签去重后追加。
+func (e *quickAddEnrichment) apply(task *model.Task) {
+	if e.Title != "" {
+		task.Title = e.Title
+	}
+	if e.Description != "" {
+		task.Description = e.Description
+	}
+	seen := make(map[string]bool, len(task.Tags))
+	for _, tag := range task.Tags {
+		seen[strings.ToLower(tag)] = true
+	}
+	for _, label := range e.Labels {
+		if !seen[strings.ToLower(label)] {
+			seen[strings.ToLower(label)] = true
+			task.Tags = append(task.Tags, label)
+		}
+	}
+}
+
+func quickAddResult(result map[string]interface{}) (*mcp.CallToolResult, error) {
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// parseQuickAdd 解析单行任务输入：#项目、@标签、p1-p4 优先级（p1 最高）以及日期/时间短语，其余部分作为标题。
+func parseQuickAdd(text string, now time.Time) quickAddParsed {
+	parsed := quickAddParsed{}
+	tokens := strings.Fields(text)
+	titleParts := make([]string, 0, len(tokens))
+	var day *time.Time
+	hour, minute := -1, 0
+	dateParts := make([]string, 0)
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		lower := strings.ToLower(token)
+
+		switch {
+		case strings.HasPrefix(token, "#") && len(token) > 1:
+			parsed.Project = strings.ReplaceAll(token[1:], "_", " ")
+			continue
+		case strings.HasPrefix(token, "@") && len(token) > 1:
+			parsed.Labels = append(parsed.Labels, token[1:])
+			continue
+		case quickAddPriorityPattern.MatchString(token):
+			level, _ := strconv.Atoi(token[1:])
+			parsed.Priority = 5 - level
+			continue
+		}
+
+		if day == nil {
+			if d, used := parseQuickAddDate(tokens[i:], now); used > 0 {
+				day = &d
+				dateParts = append(dateParts, tokens[i:i+used]...)
+				i += used - 1
+				continue
+			}
+		}
+		if hour < 0 {
+			rest := tokens[i:]
+			offset := 0
+			if lower == "at" && len(rest) > 1 {
+				offset = 1
+			}
+			if h, m, used := parseQuickAddClock(rest[offset:]); used > 0 {
+				hour, minute = h, m
+				dateParts = append(dateParts, rest[:offset+used]...)
+				i += offset + used - 1
+				continue
+			}
+		}
+		titleParts = append(titleParts, token)
+	}
+
+	parsed.Title = strings.Join(titleParts, " ")
+	if day == nil && hour < 0 {
+		return parsed
+	}
+	base := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if day != nil {
+		base = *day
+	}
+	due := base
+	if hour >= 0 {
+		due = time.Date(base.Year(), base.Month(), base.Day(), hour, minute, 0, 0, base.Location())
+		// 只给出时间且已过去时，顺延到明天
+		if day == nil && due.Before(now) {
+			due = due.AddDate(0, 0, 1)
+		}
+		parsed.HasTime = true
+	}
+	parsed.Due = &due
+	parsed.DateText = strings.Join(dateParts, " ")
+	return parsed
+}
+
+// parseQuickAddDate 从 tokens 开头识别日期短语，返回日期（当天零点）和消耗的 token 数。
+func parseQuickAddDate(tokens []string, now time.Time) (time.Time, int) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	first := strings.ToLower(strings.TrimRight(tokens[0], ",."))
+	second := ""
+	if len(tokens) > 1 {
+		second = strings.ToLower(strings.TrimRight(tokens[1], ",."))
+	}
+
+	switch first {
+	case "today", "tod", "今天", "tonight", "今晚":
+		return today, 1
+	case "tomorrow", "tmr", "tom", "明天":
+		return today.AddDate(0, 0, 1), 1
+	case "后天":
+		return today.AddDate(0, 0, 2), 1
+	case "下周":
+		return nextWeekday(today, time.Monday, true), 1
+	case "下个月":
+		return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), 1
+	case "next":
+		if weekday, ok := quickAddWeekdays[second]; ok {
+			return nextWeekday(today, weekday, true), 2
+		}
+		switch second {
+		case "week":
+			return nextWeekday(today, time.Monday, true), 2
+		case "month":
+			return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), 2
+		}
+		return time.Time{}, 0
+	case "this":
+		if weekday, ok := quickAddWeekdays[second]; ok {
+			return nextWeekday(today, weekday, false), 2
+		}
+		return time.Time{}, 0
+	case "in":
+		if len(tokens) > 2 {
+			if _, err := strconv.Atoi(second); err == nil {
+				second += strings.ToLower(tokens[2])
+				if d, ok := applyQuickAddOffset(now, second); ok {
+					return d, 3
+				}
+			}
+		}
+		if d, ok := applyQuickAddOffset(now, second); ok {
+			return d, 2
+		}
+		return time.Time{}, 0
+	}
+
+	if weekday, ok := quickAddWeekdays[first]; ok {
+		return nextWeekday(today, weekday, false), 1
+	}
+	if m := quickAddCNWeekday.FindStringSubmatch(first); m != nil {
+		return nextWeekday(today, quickAddCNWeekdays[m[2]], m[1] == "下"), 1
+	}
+	if d, err := time.ParseInLocation("2006-01-02", first, now.Location()); err == nil {
+		return d, 1
+	}
+	if m := quickAddSlashDate.FindStringSubmatch(first); m != nil {
+		month, _ := strconv.Atoi(m[1])
+		dayNum, _ := strconv.Atoi(m[2])
+		return rollForwardDate(today, time.Month(month), dayNum), 1
+	}
+	if month, ok := quickAddMonths[strings.TrimSuffix(first, ".")]; ok && second != "" {
+		if dayNum, err := strconv.Atoi(strings.TrimRight(second, "stndrh")); err == nil && dayNum >= 1 && dayNum <= 31 {
+			return rollForwardDate(today, month, dayNum), 2
+		}
+	}
+	return time.Time{}, 0
+}
+
+// parseQuickAddClock 识别 9am / 9:30pm / 14:00 / "9 am"，返回时、分和消耗的 token 数。
+func parseQuickAddClock(tokens []string) (int, int, int) {
+	if len(tokens) == 0 {
+		return 0, 0, 0
+	}
+	value := strings.ToLower(tokens[0])
+	used := 1
+	if len(tokens) > 1 {
+		if next := strings.ToLower(tokens[1]); next == "am" || next == "pm" {
+			value += next
+			used = 2
+		}
+	}
+	m := quickAddClockPattern.FindStringSubmatch(value)
+	if m == nil {
+		return 0, 0, 0
+	}
+	// 纯数字（如 "3"）不带 am/pm 且没有分钟时不视为时间，避免误吞标题中的数字
+	if m[2] == "" && m[3] == "" {
+		return 0, 0, 0
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, 0
+	}
+	return hour, minute, used
+}
+
+func applyQuickAddOffset(now time.Time, value string) (time.Time, bool) {
+	m := quickAddInPattern.FindStringSubmatch(value)
+	if m == nil {
+		return time.Time{}, false
+	}
+	n, _ := strconv.Atoi(m[1])
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch m[2][0] {
+	case 'd':
+		return today.AddDate(0, 0, n), true
+	case 'w':
+		return today.AddDate(0, 0, 7*n), true
+	default:
+		// 小时偏移只取落点日期，具体时间仍由时间短语决定
+		target := now.Add(time.Duration(n) * time.Hour)
+		return time.Date(target.Year(), target.Month(), target.Day(), 0, 0, 0, 0, now.Location()), true
+	}
+}
+
+// nextWeekday 返回今天之后（strictNext 时为下周）的指定星期几。
+func nextWeekday(today time.Time, weekday time.Weekday, strictNext bool) time.Time {
+	delta := (int(weekday) - int(today.Weekday()) + 7) % 7
+	if delta == 0 {
+		delta = 7
+	}
+	if strictNext {
+		// “下周 X” 指下一个自然周（周一开始）内的 X
+		mondayNext := today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7)
+		return mondayNext.AddDate(0, 0, (int(weekday)+6)%7)
+	}
+	return today.AddDate(0, 0, delta)
+}
+
+// rollForwardDate 返回今年的 month/day，已过去则顺延到明年。
+func rollForwardDate(today time.Time, month time.Month, day int) time.Time {
+	d := time.Date(today.Year(), month, day, 0, 0, 0, 0, today.Location())
+	if d.Before(today) {
+		d = d.AddDate(1, 0, 0)
+	}
+	return d
+}
@@ -0,0 +1,189 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	// 2026-10-14 是周三
+	now := time.Date(2026, 10, 14, 15, 0, 0, 0, time.UTC)
+	cases := []struct {
+		text     string
+		title    string
+		project  string
+		priority int
+		due      string
+	}{
+		{"Pay rent tomorrow 9am #finance p1 @home", "Pay rent", "finance", 4, "2026-10-15 09:00"},
+		{"Call mom at 6:30pm", "Call mom", "", 0, "2026-10-14 18:30"},
+		{"写周报 明天 p2", "写周报", "", 3, "2026-10-15 00:00"},
+		{"Review PR fri 14:00", "Review PR", "", 0, "2026-10-16 14:00"},
+		{"Plan sprint next monday", "Plan sprint", "", 0, "2026-10-19 00:00"},
+		{"整理文档 下周三 #work_notes", "整理文档", "work notes", 0, "2026-10-21 00:00"},
+		{"Renew passport in 3 days", "Renew passport", "", 0, "2026-10-17 00:00"},
+		{"Dentist 2026-11-02 10am p4", "Dentist", "", 1, "2026-11-02 10:00"},
+		{"Buy 3 apples", "Buy 3 apples", "", 0, ""},
+		{"Standup 9am", "Standup", "", 0, "2026-10-15 09:00"},
+	}
+	for _, tc := range cases {
+		got := parseQuickAdd(tc.text, now)
+		if got.Title != tc.title || got.Project != tc.project || got.Priority != tc.priority {
+			t.Fatalf("%q: unexpected parse %+v", tc.text, got)
+		}
+		due := ""
+		if got.Due != nil {
+			due = got.Due.Format("2006-01-02 15:04")
+		}
+		if due != tc.due {
+			t.Fatalf("%q: due = %q, want %q", tc.text, due, tc.due)
+		}
+	}
+
+	labels := parseQuickAdd("Pay rent @home @money", now).Labels
+	if len(labels) != 2 || labels[0] != "home" || labels[1] != "money" {
+		t.Fatalf("unexpected labels: %v", labels)
+	}
+}
+
+func TestHandleQuickAddPushesToProviderList(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	cfg.Timezone = "UTC"
+	mock := &mockProvider{taskList: []model.TaskList{
+		{ID: "@default", Name: "My Tasks", Source: model.SourceGoogle},
+		{ID: "fin", Name: "Finance", Source: model.SourceGoogle},
+	}}
+	s, store, ctx := newIntelligenceTestServer(t, cfg, map[string]provider.Provider{"google": mock})
+
+	res, err := s.handleQuickAdd(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"text":     "Pay rent tomorrow 9am #finance p1 @home",
+		"provider": "google",
+	}))
+	if err != nil {
+		t.Fatalf("quick add: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	taskOut, _ := out["task"].(map[string]interface{})
+	id, _ := taskOut["id"].(string)
+	if id == "" {
+		t.Fatalf("missing task in result: %v", out)
+	}
+	if len(mock.created) != 1 || mock.created[0].Title != "Pay rent" || mock.created[0].ListID != "fin" {
+		t.Fatalf("unexpected remote create: %+v", mock.created)
+	}
+
+	saved, err := store.GetTask(ctx, id)
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if saved.Priority != model.PriorityUrgent || len(saved.Tags) != 1 || saved.Tags[0] != "home" || saved.DueDate == nil {
+		t.Fatalf("unexpected saved task: %+v", saved)
+	}
+}
+
+func TestHandleQuickAddDryRun(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	res, err := s.handleQuickAdd(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"text":    "Buy milk today @errands",
+		"dry_run": true,
+	}))
+	if err != nil {
+		t.Fatalf("quick add: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	parsed, _ := out["parsed"].(map[string]interface{})
+	if parsed["title"] != "Buy milk" {
+		t.Fatalf("unexpected parsed: %v", parsed)
+	}
+	tasks, err := store.ListTasks(ctx, storage.ListOptions{})
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Fatalf("dry run should not save tasks, got %d", len(tasks))
+	}
+
+	if _, err := s.handleQuickAdd(ctx, buildCallToolRequest(t, map[string]interface{}{"text": "#inbox p1"})); err == nil {
+		t.Fatalf("expected error for empty title")
+	}
+}
+
+func TestHandleQuickAddEnrichWithSampling(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	cfg.Timezone = "UTC"
+	_, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+	s := NewServer(WithTaskStorage(store), WithIntelligenceConfig(&cfg))
+
+	var samplingPrompt string
+	session := connectBreakdownClient(t, s, &sdkmcp.ClientOptions{
+		CreateMessageHandler: func(ctx context.Context, req *sdkmcp.CreateMessageRequest) (*sdkmcp.CreateMessageResult, error) {
+			samplingPrompt = req.Params.Messages[0].Content.(*sdkmcp.TextContent).Text
+			return &sdkmcp.CreateMessageResult{
+				Model: "test",
+				Role:  "assistant",
+				Content: &sdkmcp.TextContent{Text: "```json\n" +
+					`{"title":"给房东转账缴纳房租","description":"本月房租，记得备注门牌号","labels":["#finance","Home","finance"]}` +
+					"\n```"},
+			}, nil
+		},
+	})
+
+	res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{
+		Name:      "quick_add",
+		Arguments: map[string]any{"text": "ugh rent!!! pay landlord asap tomorrow @home", "enrich": true},
+	})
+	if err != nil {
+		t.Fatalf("call quick_add: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	if _, ok := out["enrichment"]; !ok {
+		t.Fatalf("expected enrichment in result: %v", out)
+	}
+	if !strings.Contains(samplingPrompt, "pay landlord asap") {
+		t.Fatalf("unexpected sampling prompt: %q", samplingPrompt)
+	}
+	taskOut, _ := out["task"].(map[string]interface{})
+	saved, err := store.GetTask(ctx, taskOut["id"].(string))
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if saved.Title != "给房东转账缴纳房租" || saved.Description != "本月房租，记得备注门牌号" || saved.DueDate == nil {
+		t.Fatalf("unexpected enriched task: %+v", saved)
+	}
+	if strings.Join(saved.Tags, ",") != "home,finance" {
+		t.Fatalf("expected parsed labels kept and suggestions deduped, got %v", saved.Tags)
+	}
+}
+
+func TestHandleQuickAddEnrichWithoutSampling(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, _, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	res, err := s.handleQuickAdd(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"text":    "Buy milk today",
+		"enrich":  true,
+		"dry_run": true,
+	}))
+	if err != nil {
+		t.Fatalf("quick add: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	notes, _ := out["notes"].([]interface{})
+	if len(notes) != 1 || !strings.Contains(notes[0].(string), "does not support sampling") {
+		t.Fatalf("expected sampling note, got %v", out["notes"])
+	}
+	if taskOut, _ := out["task"].(map[string]interface{}); taskOut["title"] != "Buy milk" {
+		t.Fatalf("expected parsed title kept, got %v", taskOut)
+	}
+}
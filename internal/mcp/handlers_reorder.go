@@ -0,0 +1,271 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+// taskPositionField 本地记录的清单内排序位置（从 1 开始）。
+const taskPositionField = "tb_position"
+
+// handleReorderTasks 调整清单内同级任务的顺序，并在 provider 支持时回写远端排序。
+func (s *Server) handleReorderTasks(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	orderedIDs := getStringSlice(rawArgs, "task_ids")
+	taskID := strings.TrimSpace(getString(rawArgs, "task_id"))
+	if len(orderedIDs) == 0 && taskID == "" {
+		return nil, fmt.Errorf("task_id or task_ids is required")
+	}
+
+	anchorID := taskID
+	if anchorID == "" {
+		anchorID = strings.TrimSpace(orderedIDs[0])
+	}
+	anchor, err := s.taskStore.GetTask(ctx, anchorID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+
+	siblings, err := s.listSiblingTasks(ctx, anchor)
+	if err != nil {
+		return nil, err
+	}
+	current := make([]string, 0, len(siblings))
+	byID := make(map[string]*model.Task, len(siblings))
+	for i := range siblings {
+		current = append(current, siblings[i].ID)
+		byID[siblings[i].ID] = &siblings[i]
+	}
+
+	var next []string
+	if len(orderedIDs) > 0 {
+		next, err = applyExplicitOrder(current, orderedIDs)
+	} else {
+		next, err = moveTaskInOrder(current, taskID, rawArgs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dryRun, _ := getBool(rawArgs, "dry_run")
+	now := time.Now()
+	changed := 0
+	for idx, id := range next {
+		task := byID[id]
+		if taskCustomInt(*task, taskPositionField) == idx+1 {
+			continue
+		}
+		changed++
+		if dryRun {
+			continue
+		}
+		setTaskCustomField(task, taskPositionField, idx+1)
+		task.UpdatedAt = now
+		if err := s.taskStore.SaveTask(ctx, task); err != nil {
+			return nil, fmt.Errorf("failed to save task: %w", err)
+		}
+	}
+
+	result := map[string]interface{}{
+		"source":   string(anchor.Source),
+		"list_id":  anchor.ListID,
+		"order":    next,
+		"changed":  changed,
+		"dry_run":  dryRun,
+		"remote":   "skipped",
+		"position": indexOf(next, anchorID) + 1,
+	}
+	if !dryRun {
+		remote, note := s.pushTaskOrder(ctx, anchor, next, byID)
+		result["remote"] = remote
+		if note != "" {
+			result["remote_note"] = note
+		}
+	}
+
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// listSiblingTasks 返回与 anchor 同来源、同清单、同父任务的未完成任务，按当前顺序排列。
+func (s *Server) listSiblingTasks(ctx context.Context, anchor *model.Task) ([]model.Task, error) {
+	query := storage.Query{
+		Statuses: []model.TaskStatus{model.StatusTodo, model.StatusInProgress, model.StatusDeferred},
+	}
+	if anchor.Source != "" {
+		query.Sources = []model.TaskSource{anchor.Source}
+	}
+	if anchor.ListID != "" {
+		query.ListIDs = []string{anchor.ListID}
+	}
+	tasks, err := s.taskStore.QueryTasks(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	parentOf := func(task model.Task) string {
+		if task.ParentID == nil {
+			return ""
+		}
+		return strings.TrimSpace(*task.ParentID)
+	}
+	anchorParent := parentOf(*anchor)
+	siblings := make([]model.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.ListID == anchor.ListID && parentOf(task) == anchorParent {
+			siblings = append(siblings, task)
+		}
+	}
+	sortTasksByPosition(siblings)
+	return siblings, nil
+}
+
+// pushTaskOrder 将新顺序回写到实现了 provider.TaskReorderer 的 provider。
+func (s *Server) pushTaskOrder(ctx context.Context, anchor *model.Task, order []string, byID map[string]*model.Task) (string, string) {
+	if anchor.Source == "" || anchor.Source == model.SourceLocal {
+		return "skipped", "local task"
+	}
+	p, ok := s.lookupProvider(ctx, string(anchor.Source))
+	if !ok || p == nil || !p.IsAuthenticated() {
+		return "skipped", fmt.Sprintf("provider %s not available", anchor.Source)
+	}
+	reorderer, ok := unwrapProvider(p).(provider.TaskReorderer)
+	if !ok {
+		return "unsupported", fmt.Sprintf("provider %s does not expose task ordering", anchor.Source)
+	}
+	rawIDs := make([]string, 0, len(order))
+	for _, id := range order {
+		if raw := strings.TrimSpace(byID[id].SourceRawID); raw != "" {
+			rawIDs = append(rawIDs, raw)
+		}
+	}
+	if len(rawIDs) == 0 {
+		return "skipped", "tasks not synced to remote yet"
+	}
+	if err := reorderer.ReorderTasks(ctx, anchor.ListID, rawIDs); err != nil {
+		return "failed", err.Error()
+	}
+	s.invalidateAdapterReads(string(anchor.Source), anchor.ListID)
+	return "updated", ""
+}
+
+// applyExplicitOrder 将 ordered 中的任务按给定顺序放在最前，其余任务保持原有相对顺序。
+func applyExplicitOrder(current, ordered []string) ([]string, error) {
+	known := make(map[string]bool, len(current))
+	for _, id := range current {
+		known[id] = true
+	}
+	placed := make(map[string]bool, len(ordered))
+	next := make([]string, 0, len(current))
+	for _, raw := range ordered {
+		id := strings.TrimSpace(raw)
+		if id == "" || placed[id] {
+			continue
+		}
+		if !known[id] {
+			return nil, fmt.Errorf("task %s is not in the same list", id)
+		}
+		placed[id] = true
+		next = append(next, id)
+	}
+	for _, id := range current {
+		if !placed[id] {
+			next = append(next, id)
+		}
+	}
+	return next, nil
+}
+
+// moveTaskInOrder 根据 position（top/bottom/序号）或 before/after 移动单个任务。
+func moveTaskInOrder(current []string, taskID string, rawArgs map[string]json.RawMessage) ([]string, error) {
+	rest := make([]string, 0, len(current))
+	for _, id := range current {
+		if id != taskID {
+			rest = append(rest, id)
+		}
+	}
+	if len(rest) == len(current) {
+		return nil, fmt.Errorf("task %s is not an active task in its list", taskID)
+	}
+
+	target := -1
+	if before := strings.TrimSpace(getString(rawArgs, "before")); before != "" {
+		if target = indexOf(rest, before); target < 0 {
+			return nil, fmt.Errorf("task %s is not in the same list", before)
+		}
+	} else if after := strings.TrimSpace(getString(rawArgs, "after")); after != "" {
+		if target = indexOf(rest, after); target < 0 {
+			return nil, fmt.Errorf("task %s is not in the same list", after)
+		}
+		target++
+	} else if pos, ok := getInt(rawArgs, "position"); ok {
+		target = pos - 1
+	} else {
+		switch strings.ToLower(strings.TrimSpace(getString(rawArgs, "position"))) {
+		case "top", "first", "":
+			target = 0
+		case "bottom", "last":
+			target = len(rest)
+		default:
+			return nil, fmt.Errorf("invalid position: %s", getString(rawArgs, "position"))
+		}
+	}
+	if target < 0 {
+		target = 0
+	}
+	if target > len(rest) {
+		target = len(rest)
+	}
+
+	next := make([]string, 0, len(current))
+	next = append(next, rest[:target]...)
+	next = append(next, taskID)
+	next = append(next, rest[target:]...)
+	return next, nil
+}
+
+// sortTasksByPosition 有 tb_position 的任务在前并按位置排序，其余按创建时间排序。
+func sortTasksByPosition(tasks []model.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		pi, pj := taskCustomInt(tasks[i], taskPositionField), taskCustomInt(tasks[j], taskPositionField)
+		if pi > 0 && pj > 0 && pi != pj {
+			return pi < pj
+		}
+		if (pi > 0) != (pj > 0) {
+			return pi > 0
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+}
+
+func indexOf(values []string, target string) int {
+	for i, v := range values {
+		if v == target {
+			return i
+		}
+	}
+	return -1
+}
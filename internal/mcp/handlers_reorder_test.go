@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+type reorderMockProvider struct {
+	mockProvider
+	listID  string
+	ordered []string
+}
+
+func (m *reorderMockProvider) ReorderTasks(ctx context.Context, listID string, orderedTaskIDs []string) error {
+	m.listID = listID
+	m.ordered = append([]string(nil), orderedTaskIDs...)
+	return nil
+}
+
+func seedReorderTasks(t *testing.T, s *Server, ctx context.Context) {
+	t.Helper()
+	base := time.Now().Add(-time.Hour)
+	for i, id := range []string{"a", "b", "c"} {
+		task := &model.Task{
+			ID:          id,
+			Title:       "task " + id,
+			Status:      model.StatusTodo,
+			Source:      model.SourceGoogle,
+			SourceRawID: "raw-" + id,
+			ListID:      "list-1",
+			CreatedAt:   base.Add(time.Duration(i) * time.Minute),
+			UpdatedAt:   base,
+		}
+		if err := s.taskStore.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+	other := &model.Task{ID: "x", Title: "other list", Status: model.StatusTodo, Source: model.SourceGoogle, ListID: "list-2", CreatedAt: base}
+	if err := s.taskStore.SaveTask(ctx, other); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+}
+
+func TestHandleReorderTasksMoveToTop(t *testing.T) {
+	mock := &reorderMockProvider{}
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, map[string]provider.Provider{"google": mock})
+	seedReorderTasks(t, s, ctx)
+
+	res, err := s.handleReorderTasks(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id":  "c",
+		"position": "top",
+	}))
+	if err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	if out["remote"] != "updated" {
+		t.Fatalf("expected remote updated, got %v (%v)", out["remote"], out["remote_note"])
+	}
+	order, _ := out["order"].([]interface{})
+	if len(order) != 3 || order[0] != "c" || order[1] != "a" || order[2] != "b" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+	if mock.listID != "list-1" || len(mock.ordered) != 3 || mock.ordered[0] != "raw-c" {
+		t.Fatalf("unexpected remote call: %s %v", mock.listID, mock.ordered)
+	}
+
+	moved, err := store.GetTask(ctx, "c")
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if taskCustomInt(*moved, taskPositionField) != 1 {
+		t.Fatalf("expected position 1, got %d", taskCustomInt(*moved, taskPositionField))
+	}
+
+	// 再次移动时应以已保存的位置为准
+	res, err = s.handleReorderTasks(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id": "a",
+		"after":   "b",
+	}))
+	if err != nil {
+		t.Fatalf("reorder after: %v", err)
+	}
+	order, _ = parseJSONResult(t, res)["order"].([]interface{})
+	if len(order) != 3 || order[0] != "c" || order[1] != "b" || order[2] != "a" {
+		t.Fatalf("unexpected order after move: %v", order)
+	}
+}
+
+func TestHandleReorderTasksExplicitOrderDryRun(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, map[string]provider.Provider{"google": &mockProvider{}})
+	seedReorderTasks(t, s, ctx)
+
+	res, err := s.handleReorderTasks(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_ids": []string{"b", "a"},
+		"dry_run":  true,
+	}))
+	if err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	order, _ := out["order"].([]interface{})
+	if len(order) != 3 || order[0] != "b" || order[1] != "a" || order[2] != "c" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+	task, _ := store.GetTask(ctx, "b")
+	if taskCustomInt(*task, taskPositionField) != 0 {
+		t.Fatalf("dry run should not persist position")
+	}
+
+	if _, err := s.handleReorderTasks(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_ids": []string{"a", "x"},
+	})); err == nil {
+		t.Fatalf("expected error for task in another list")
+	}
+
+	res, err = s.handleReorderTasks(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id":  "a",
+		"position": 3,
+	}))
+	if err != nil {
+		t.Fatalf("reorder by index: %v", err)
+	}
+	if got := parseJSONResult(t, res)["remote"]; got != "unsupported" {
+		t.Fatalf("expected unsupported remote, got %v", got)
+	}
+}
@@ -0,0 +1,401 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+const (
+	taskResourceTemplate       = "task://{adapter}/{task_id}"
+	taskSearchResourceTemplate = "task://{adapter}/search{?q,status,project,tag,list,limit,cursor}"
+
+	defaultResourceSearchLimit = 50
+	maxResourceSearchLimit     = 500
+	// maxCompletionValues MCP 规范限制单次补全最多返回 100 个候选值
+	maxCompletionValues = 100
+)
+
+// resolveResourceAdapter 解析资源 URI 中的 adapter 段；all/* 表示不限来源。
+func resolveResourceAdapter(adapter string) (model.TaskSource, error) {
+	adapter = strings.ToLower(strings.TrimSpace(adapter))
+	switch adapter {
+	case "", "all", "*":
+		return "", nil
+	case string(model.SourceLocal):
+		return model.SourceLocal, nil
+	}
+	resolved, err := resolveProviderNameStrict(adapter)
+	if err != nil {
+		return "", err
+	}
+	return model.TaskSource(resolved), nil
+}
+
+func taskMatchesSource(task model.Task, source model.TaskSource) bool {
+	if source == "" {
+		return true
+	}
+	taskSource := task.Source
+	if taskSource == "" {
+		taskSource = model.SourceLocal
+	}
+	return taskSource == source
+}
+
+// handleTaskResource 处理 task://{adapter}/{task_id} 模板资源，返回单个任务详情。
+func (s *Server) handleTaskResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource uri: %w", err)
+	}
+	if strings.TrimPrefix(parsed.Path, "/") == "search" {
+		return s.handleTaskSearchResource(ctx, req)
+	}
+	source, err := resolveResourceAdapter(parsed.Host)
+	if err != nil {
+		return nil, err
+	}
+	taskID := strings.TrimPrefix(parsed.Path, "/")
+	if taskID == "" || s.taskStore == nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	task, err := s.taskStore.GetTask(ctx, taskID)
+	if err != nil || task == nil || !taskMatchesSource(*task, source) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	output, err := toJSON(task)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: output}},
+	}, nil
+}
+
+// handleTaskSearchResource 处理 task://{adapter}/search?q=... 模板资源，
+// 查询参数与 list_tasks 的过滤语义一致（list 对应 list_name）。
+func (s *Server) handleTaskSearchResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource uri: %w", err)
+	}
+	source, err := resolveResourceAdapter(parsed.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	params := parsed.Query()
+	rawArgs := make(map[string]json.RawMessage)
+	setArg := func(key string, value interface{}) {
+		data, _ := json.Marshal(value)
+		rawArgs[key] = data
+	}
+	if source != "" && source != model.SourceLocal {
+		setArg("source", string(source))
+	}
+	if q := strings.TrimSpace(params.Get("q")); q != "" {
+		setArg("query", q)
+	}
+	for param, key := range map[string]string{"status": "status", "project": "project", "tag": "tag", "list": "list_name"} {
+		if values := splitResourceParam(params[param]); len(values) > 0 {
+			setArg(key, values)
+		}
+	}
+
+	limit := defaultResourceSearchLimit
+	if v := strings.TrimSpace(params.Get("limit")); v != "" {
+		parsedLimit, err := strconv.Atoi(v)
+		if err != nil || parsedLimit <= 0 {
+			return nil, fmt.Errorf("invalid limit: %s", v)
+		}
+		limit = parsedLimit
+	}
+	if limit > maxResourceSearchLimit {
+		limit = maxResourceSearchLimit
+	}
+
+	appliedFilters := make(map[string]interface{})
+	if source != "" {
+		appliedFilters["adapter"] = string(source)
+	}
+	tasks := []model.Task{}
+	if s.taskStore != nil {
+		query, projectIDs, err := s.parseTaskQueryArgs(ctx, rawArgs, appliedFilters)
+		if err != nil {
+			return nil, err
+		}
+		found, err := s.taskStore.QueryTasks(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query tasks: %w", err)
+		}
+		for _, task := range filterTasksByProject(found, projectIDs) {
+			if taskMatchesSource(task, source) {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	sortKeys := []taskSortKey{{Field: "updated_at", Desc: true}}
+	sortSpec := formatTaskSort(sortKeys)
+	sortTasksByKeys(tasks, sortKeys)
+
+	// 与 list_tasks 相同的 cursor/next_cursor 约定
+	offset := 0
+	if v := strings.TrimSpace(params.Get("cursor")); v != "" {
+		cursor, err := decodeListTasksCursor(v)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Sort != sortSpec {
+			return nil, fmt.Errorf("cursor does not match sort %q", sortSpec)
+		}
+		offset = cursor.Offset
+	}
+	total := len(tasks)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if offset+limit < end {
+		end = offset + limit
+	}
+	tasks = tasks[offset:end]
+	payload := map[string]interface{}{
+		"filters":   appliedFilters,
+		"total":     total,
+		"returned":  len(tasks),
+		"truncated": end < total,
+		"tasks":     toCompactTasks(tasks),
+	}
+	if end < total {
+		payload["next_cursor"] = encodeListTasksCursor(listTasksCursor{Offset: end, Sort: sortSpec})
+	}
+	output, err := toJSON(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: output}},
+	}, nil
+}
+
+// splitResourceParam 展开重复或逗号分隔的查询参数。
+func splitResourceParam(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// handleComplete 处理 completion/complete 请求，为资源模板变量与提示词参数提供候选值（基于本地缓存）。
+// MCP 规范只定义了 ref/prompt 与 ref/resource 两种引用，工具参数无法通过该接口补全。
+func (s *Server) handleComplete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	result := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{}}}
+	if req == nil || req.Params == nil || req.Params.Ref == nil {
+		return result, nil
+	}
+	argument := req.Params.Argument
+	var contextArgs map[string]string
+	if req.Params.Context != nil {
+		contextArgs = req.Params.Context.Arguments
+	}
+
+	switch req.Params.Ref.Type {
+	case "ref/resource":
+		if !strings.HasPrefix(req.Params.Ref.URI, "task://") {
+			return result, nil
+		}
+	case "ref/prompt":
+		if !s.GetPrompts()[req.Params.Ref.Name] {
+			return result, nil
+		}
+	default:
+		return result, nil
+	}
+
+	candidates, prefiltered := s.completionCandidates(ctx, argument.Name, argument.Value, contextArgs)
+	if !prefiltered {
+		candidates = filterCompletionPrefix(candidates, argument.Value)
+	}
+	result.Completion.Total = len(candidates)
+	if len(candidates) > maxCompletionValues {
+		candidates = candidates[:maxCompletionValues]
+		result.Completion.HasMore = true
+	}
+	result.Completion.Values = candidates
+	return result, nil
+}
+
+// completionCandidates 按参数名返回候选值；prefiltered 为 true 表示已在查询时完成匹配，无需再做前缀过滤。
+func (s *Server) completionCandidates(ctx context.Context, name, value string, contextArgs map[string]string) ([]string, bool) {
+	switch name {
+	case "adapter", "source":
+		return s.adapterCompletions(ctx), false
+	case "task_id", "id":
+		return s.taskIDCompletions(ctx, contextArgs["adapter"], value), true
+	case "status":
+		return []string{
+			string(model.StatusTodo), string(model.StatusInProgress), string(model.StatusCompleted),
+			string(model.StatusCancelled), string(model.StatusDeferred),
+		}, false
+	case "project", "project_name", "project_id":
+		return s.projectCompletions(ctx), false
+	case "tag", "label":
+		return s.labelCompletions(ctx, contextArgs["adapter"]), false
+	case "list", "list_name":
+		return s.listNameCompletions(ctx, contextArgs["adapter"]), false
+	case "complexity":
+		return []string{"simple", "medium", "complex"}, false
+	default:
+		return nil, false
+	}
+}
+
+func filterCompletionPrefix(candidates []string, prefix string) []string {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	out := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			out = append(out, candidate)
+		}
+	}
+	return out
+}
+
+// adapterCompletions 返回 all、local、已注册 Provider 以及其余受支持的 Provider 名称。
+func (s *Server) adapterCompletions(ctx context.Context) []string {
+	values := []string{"all", string(model.SourceLocal)}
+	seen := map[string]bool{"all": true, string(model.SourceLocal): true}
+	names := s.providerNames(ctx)
+	for _, def := range provider.GetAllProviders() {
+		names = append(names, def.Name)
+	}
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			values = append(values, name)
+		}
+	}
+	return values
+}
+
+func (s *Server) taskIDCompletions(ctx context.Context, adapter, value string) []string {
+	if s.taskStore == nil {
+		return nil
+	}
+	source, err := resolveResourceAdapter(adapter)
+	if err != nil {
+		return nil
+	}
+	tasks, err := s.taskStore.ListTasks(ctx, storage.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	sortTasksByKeys(tasks, []taskSortKey{{Field: "updated_at", Desc: true}})
+	value = strings.ToLower(strings.TrimSpace(value))
+	out := make([]string, 0)
+	for _, task := range tasks {
+		if !taskMatchesSource(task, source) {
+			continue
+		}
+		if value == "" || strings.HasPrefix(strings.ToLower(task.ID), value) || strings.Contains(strings.ToLower(task.Title), value) {
+			out = append(out, task.ID)
+		}
+	}
+	return out
+}
+
+func (s *Server) projectCompletions(ctx context.Context) []string {
+	names := s.projectNameIndex(ctx)
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" && !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (s *Server) listNameCompletions(ctx context.Context, adapter string) []string {
+	if s.taskStore == nil {
+		return nil
+	}
+	source, err := resolveResourceAdapter(adapter)
+	if err != nil {
+		return nil
+	}
+	tasks, err := s.taskStore.ListTasks(ctx, storage.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	out := make([]string, 0)
+	for _, task := range tasks {
+		name := strings.TrimSpace(task.ListName)
+		if name == "" || seen[name] || !taskMatchesSource(task, source) {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// labelCompletions 返回本地缓存中出现过的标签，按使用次数降序排列。
+func (s *Server) labelCompletions(ctx context.Context, adapter string) []string {
+	if s.taskStore == nil {
+		return nil
+	}
+	source, err := resolveResourceAdapter(adapter)
+	if err != nil {
+		return nil
+	}
+	tasks, err := s.taskStore.ListTasks(ctx, storage.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, task := range tasks {
+		if !taskMatchesSource(task, source) {
+			continue
+		}
+		for _, tag := range task.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				counts[tag]++
+			}
+		}
+	}
+	out := make([]string, 0, len(counts))
+	for tag := range counts {
+		out = append(out, tag)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if counts[out[i]] == counts[out[j]] {
+			return out[i] < out[j]
+		}
+		return counts[out[i]] > counts[out[j]]
+	})
+	return out
+}
@@ -0,0 +1,230 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+func newResourceTemplateTestSession(t *testing.T) (*sdkmcp.ClientSession, context.Context) {
+	t.Helper()
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	ctx := context.Background()
+	now := time.Now()
+	seeds := []*model.Task{
+		{ID: "local-report", Title: "整理周报", Status: model.StatusTodo, Source: model.SourceLocal, ListName: "工作", Tags: []string{"work", "weekly"}, UpdatedAt: now},
+		{ID: "local-gym", Title: "健身", Status: model.StatusCompleted, Source: model.SourceLocal, ListName: "生活", UpdatedAt: now.Add(-time.Hour)},
+		{ID: "google-report", Title: "Quarter report", Status: model.StatusTodo, Source: model.SourceGoogle, ListName: "Inbox", Tags: []string{"work"}, UpdatedAt: now},
+	}
+	for _, task := range seeds {
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+	s := NewServer(WithTaskStorage(store))
+	return connectBreakdownClient(t, s, nil), ctx
+}
+
+func TestResourceTemplatesListed(t *testing.T) {
+	session, ctx := newResourceTemplateTestSession(t)
+	res, err := session.ListResourceTemplates(ctx, nil)
+	if err != nil {
+		t.Fatalf("list resource templates: %v", err)
+	}
+	got := map[string]bool{}
+	for _, tmpl := range res.ResourceTemplates {
+		got[tmpl.URITemplate] = true
+	}
+	if !got[taskResourceTemplate] || !got[taskSearchResourceTemplate] {
+		t.Fatalf("expected task templates, got %v", got)
+	}
+}
+
+func TestReadTaskResourceTemplate(t *testing.T) {
+	session, ctx := newResourceTemplateTestSession(t)
+	res, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "task://local/local-report"})
+	if err != nil {
+		t.Fatalf("read task resource: %v", err)
+	}
+	var task model.Task
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &task); err != nil {
+		t.Fatalf("decode task: %v", err)
+	}
+	if task.ID != "local-report" || task.Title != "整理周报" {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+
+	// 来源不匹配时视为资源不存在
+	if _, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "task://google/local-report"}); err == nil {
+		t.Fatalf("expected not found for mismatched adapter")
+	}
+}
+
+func TestReadTaskSearchResourceTemplate(t *testing.T) {
+	session, ctx := newResourceTemplateTestSession(t)
+	read := func(uri string) map[string]interface{} {
+		t.Helper()
+		res, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			t.Fatalf("read %s: %v", uri, err)
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(res.Contents[0].Text), &payload); err != nil {
+			t.Fatalf("decode search result: %v", err)
+		}
+		return payload
+	}
+
+	payload := read("task://all/search?q=report")
+	if int(payload["total"].(float64)) != 2 {
+		t.Fatalf("expected 2 matches across adapters, got %v", payload["total"])
+	}
+
+	payload = read("task://local/search?status=todo,completed")
+	if int(payload["total"].(float64)) != 2 {
+		t.Fatalf("expected 2 local tasks, got %v", payload["total"])
+	}
+
+	payload = read("task://google/search?q=report&limit=1")
+	tasks := payload["tasks"].([]interface{})
+	if len(tasks) != 1 || tasks[0].(map[string]interface{})["id"] != "google-report" {
+		t.Fatalf("unexpected google search result: %v", tasks)
+	}
+}
+
+func TestCompleteResourceTemplateArguments(t *testing.T) {
+	session, ctx := newResourceTemplateTestSession(t)
+	complete := func(name, value string, args map[string]string) []string {
+		t.Helper()
+		params := &sdkmcp.CompleteParams{
+			Ref:      &sdkmcp.CompleteReference{Type: "ref/resource", URI: taskResourceTemplate},
+			Argument: sdkmcp.CompleteParamsArgument{Name: name, Value: value},
+		}
+		if args != nil {
+			params.Context = &sdkmcp.CompleteContext{Arguments: args}
+		}
+		res, err := session.Complete(ctx, params)
+		if err != nil {
+			t.Fatalf("complete %s: %v", name, err)
+		}
+		return res.Completion.Values
+	}
+
+	adapters := complete("adapter", "go", nil)
+	if len(adapters) != 1 || adapters[0] != "google" {
+		t.Fatalf("unexpected adapter completion: %v", adapters)
+	}
+
+	ids := complete("task_id", "", map[string]string{"adapter": "local"})
+	sort.Strings(ids)
+	if strings.Join(ids, ",") != "local-gym,local-report" {
+		t.Fatalf("unexpected task id completion: %v", ids)
+	}
+
+	ids = complete("task_id", "周报", nil)
+	if len(ids) != 1 || ids[0] != "local-report" {
+		t.Fatalf("expected title match, got %v", ids)
+	}
+
+	statuses := complete("status", "in", nil)
+	if len(statuses) != 1 || statuses[0] != string(model.StatusInProgress) {
+		t.Fatalf("unexpected status completion: %v", statuses)
+	}
+}
+
+func TestCompletePromptArguments(t *testing.T) {
+	session, ctx := newResourceTemplateTestSession(t)
+	complete := func(prompt, name, value string) []string {
+		t.Helper()
+		res, err := session.Complete(ctx, &sdkmcp.CompleteParams{
+			Ref:      &sdkmcp.CompleteReference{Type: "ref/prompt", Name: prompt},
+			Argument: sdkmcp.CompleteParamsArgument{Name: name, Value: value},
+		})
+		if err != nil {
+			t.Fatalf("complete %s.%s: %v", prompt, name, err)
+		}
+		return res.Completion.Values
+	}
+
+	if got := complete("weekly_review", "adapter", "lo"); len(got) != 1 || got[0] != "local" {
+		t.Fatalf("unexpected adapter completion: %v", got)
+	}
+	if got := complete("ai_split_guide", "complexity", "c"); len(got) != 1 || got[0] != "complex" {
+		t.Fatalf("unexpected complexity completion: %v", got)
+	}
+	if got := complete("standup_summary", "tag", "w"); strings.Join(got, ",") != "work,weekly" {
+		t.Fatalf("expected labels ordered by usage, got %v", got)
+	}
+	if got := complete("unknown_prompt", "adapter", ""); len(got) != 0 {
+		t.Fatalf("expected no completion for unknown prompt, got %v", got)
+	}
+}
+
+func TestReadTaskSearchResourceCursor(t *testing.T) {
+	session, ctx := newResourceTemplateTestSession(t)
+	uri := "task://all/search?limit=2"
+	seen := make([]string, 0)
+	for page := 0; page < 3; page++ {
+		res, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			t.Fatalf("read %s: %v", uri, err)
+		}
+		var payload struct {
+			Tasks      []map[string]interface{} `json:"tasks"`
+			NextCursor string                   `json:"next_cursor"`
+		}
+		if err := json.Unmarshal([]byte(res.Contents[0].Text), &payload); err != nil {
+			t.Fatalf("decode search result: %v", err)
+		}
+		for _, task := range payload.Tasks {
+			seen = append(seen, task["id"].(string))
+		}
+		if payload.NextCursor == "" {
+			break
+		}
+		uri = "task://all/search?limit=2&cursor=" + payload.NextCursor
+	}
+	sort.Strings(seen)
+	if strings.Join(seen, ",") != "google-report,local-gym,local-report" {
+		t.Fatalf("unexpected paged tasks: %v", seen)
+	}
+
+	if _, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "task://all/search?cursor=bad!"}); err == nil {
+		t.Fatalf("expected error for invalid cursor")
+	}
+}
+
+func TestListToolsPaginated(t *testing.T) {
+	s := NewServer(WithConfig(&ServerConfig{Name: "taskbridge", Transport: "inmemory", PageSize: 10}))
+	session := connectBreakdownClient(t, s, nil)
+	ctx := context.Background()
+
+	first, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	if len(first.Tools) != 10 || first.NextCursor == "" {
+		t.Fatalf("expected first page of 10 tools with cursor, got %d (%q)", len(first.Tools), first.NextCursor)
+	}
+	total := 0
+	for _, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("iterate tools: %v", err)
+		}
+		total++
+	}
+	if total != len(s.GetTools()) {
+		t.Fatalf("expected all %d tools across pages, got %d", len(s.GetTools()), total)
+	}
+}
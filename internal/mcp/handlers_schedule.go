@@ -0,0 +1,392 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+// overdueAgeBuckets 逾期天数分桶，按顺序匹配上限。
+var overdueAgeBuckets = []struct {
+	Key     string
+	MaxDays int
+}{
+	{Key: "1_day", MaxDays: 1},
+	{Key: "2_7_days", MaxDays: 7},
+	{Key: "8_30_days", MaxDays: 30},
+	{Key: "over_30_days", MaxDays: 0},
+}
+
+type overdueTaskItem struct {
+	TaskID      string     `json:"task_id"`
+	Title       string     `json:"title"`
+	Status      string     `json:"status"`
+	Priority    int        `json:"priority,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	DaysOverdue int        `json:"days_overdue"`
+	AgeBucket   string     `json:"age_bucket"`
+}
+
+type overdueTaskGroup struct {
+	Source   string            `json:"source"`
+	ListID   string            `json:"list_id,omitempty"`
+	ListName string            `json:"list_name,omitempty"`
+	Count    int               `json:"count"`
+	Buckets  map[string]int    `json:"buckets"`
+	Tasks    []overdueTaskItem `json:"tasks,omitempty"`
+}
+
+// handleOverdueTasks 按 provider 与清单分组返回逾期任务，并附带逾期时长分桶。
+func (s *Server) handleOverdueTasks(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: `{"summary":{"overdue_count":0,"group_count":0},"groups":[]}`}},
+		}, nil
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	includeTasks := true
+	if v, ok := getBool(rawArgs, "include_tasks"); ok {
+		includeTasks = v
+	}
+	limitPerGroup, ok := getInt(rawArgs, "limit_per_group")
+	if !ok || limitPerGroup <= 0 {
+		limitPerGroup = 20
+	}
+
+	query := storage.Query{
+		Statuses: []model.TaskStatus{model.StatusTodo, model.StatusInProgress},
+		ListIDs:  getStringSlice(rawArgs, "list_id"),
+	}
+	if source := getString(rawArgs, "source"); source != "" {
+		resolvedSource, err := resolveProviderNameStrict(source)
+		if err != nil {
+			return nil, err
+		}
+		query.Sources = []model.TaskSource{model.TaskSource(resolvedSource)}
+	}
+
+	tasks, err := s.taskStore.QueryTasks(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	now := time.Now()
+	groups := make(map[string]*overdueTaskGroup)
+	totalBuckets := newOverdueBucketCounts()
+	overdueCount := 0
+	for _, task := range tasks {
+		days := calcOverdueDays(task.DueDate, now)
+		if days <= 0 {
+			continue
+		}
+		overdueCount++
+		bucket := overdueAgeBucket(days)
+		totalBuckets[bucket]++
+
+		key := buildProviderListKey(string(task.Source), task.ListID)
+		group, ok := groups[key]
+		if !ok {
+			group = &overdueTaskGroup{
+				Source:   string(task.Source),
+				ListID:   task.ListID,
+				ListName: task.ListName,
+				Buckets:  newOverdueBucketCounts(),
+			}
+			groups[key] = group
+		}
+		group.Count++
+		group.Buckets[bucket]++
+		if includeTasks {
+			group.Tasks = append(group.Tasks, overdueTaskItem{
+				TaskID:      task.ID,
+				Title:       task.Title,
+				Status:      string(task.Status),
+				Priority:    int(task.Priority),
+				DueDate:     task.DueDate,
+				DaysOverdue: days,
+				AgeBucket:   bucket,
+			})
+		}
+	}
+
+	result := make([]overdueTaskGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.SliceStable(group.Tasks, func(i, j int) bool {
+			if group.Tasks[i].DaysOverdue == group.Tasks[j].DaysOverdue {
+				return group.Tasks[i].Priority > group.Tasks[j].Priority
+			}
+			return group.Tasks[i].DaysOverdue > group.Tasks[j].DaysOverdue
+		})
+		if len(group.Tasks) > limitPerGroup {
+			group.Tasks = group.Tasks[:limitPerGroup]
+		}
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Source == result[j].Source {
+			if result[i].Count == result[j].Count {
+				return result[i].ListName < result[j].ListName
+			}
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Source < result[j].Source
+	})
+
+	payload := map[string]interface{}{
+		"summary": map[string]interface{}{
+			"overdue_count": overdueCount,
+			"group_count":   len(result),
+			"buckets":       totalBuckets,
+			"generated_at":  now,
+		},
+		"groups": result,
+	}
+
+	jsonResult, err := toJSON(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+func newOverdueBucketCounts() map[string]int {
+	counts := make(map[string]int, len(overdueAgeBuckets))
+	for _, bucket := range overdueAgeBuckets {
+		counts[bucket.Key] = 0
+	}
+	return counts
+}
+
+func overdueAgeBucket(days int) string {
+	for _, bucket := range overdueAgeBuckets {
+		if bucket.MaxDays == 0 || days <= bucket.MaxDays {
+			return bucket.Key
+		}
+	}
+	return overdueAgeBuckets[len(overdueAgeBuckets)-1].Key
+}
+
+// snoozeDurationPattern 匹配 30m/2h/3d/1w 形式的推迟时长。
+var snoozeDurationPattern = regexp.MustCompile(`^(\d+)\s*([mhdw])$`)
+
+// handleSnoozeTask 将任务截止时间推迟一段时长或到命名时间槽，并按 provider 规则回写远端。
+func (s *Server) handleSnoozeTask(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	taskID := strings.TrimSpace(getString(rawArgs, "task_id"))
+	if taskID == "" {
+		return nil, fmt.Errorf("task_id is required")
+	}
+	duration := strings.TrimSpace(getString(rawArgs, "duration"))
+	until := strings.TrimSpace(getString(rawArgs, "until"))
+	if duration == "" && until == "" {
+		return nil, fmt.Errorf("duration or until is required")
+	}
+	dryRun, _ := getBool(rawArgs, "dry_run")
+	syncRemote := true
+	if v, ok := getBool(rawArgs, "sync_remote"); ok {
+		syncRemote = v
+	}
+
+	task, err := s.taskStore.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+
+	loc := resolveLocation(getString(rawArgs, "timezone"))
+	now := time.Now().In(loc)
+	var newDue time.Time
+	if until != "" {
+		newDue, err = resolveSnoozeSlot(until, now)
+	} else {
+		base := now
+		if task.DueDate != nil && task.DueDate.After(now) {
+			base = task.DueDate.In(loc)
+		}
+		newDue, err = applySnoozeDuration(base, duration)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previousDue := task.DueDate
+	newDue = normalizeDueForProvider(task.Source, newDue)
+	if duration != "" && until == "" {
+		// 只精确到日期的 provider（如 Google）会截掉时间部分，不足一天的推迟可能使截止日期不变甚至提前，
+		// 此时向上取整到原截止日期（已过期或未设置时为今天）的下一天
+		floor := normalizeDueForProvider(task.Source, now)
+		if previousDue != nil && previousDue.After(now) {
+			floor = normalizeDueForProvider(task.Source, previousDue.UTC())
+		}
+		if !newDue.After(floor) {
+			newDue = floor.AddDate(0, 0, 1)
+		}
+	}
+	task.DueDate = &newDue
+	if task.Status == model.StatusDeferred {
+		task.Status = model.StatusTodo
+	}
+	task.UpdatedAt = time.Now()
+	if task.Metadata == nil {
+		task.Metadata = &model.TaskMetadata{Version: "1.0"}
+	}
+	if task.Metadata.CustomFields == nil {
+		task.Metadata.CustomFields = map[string]interface{}{}
+	}
+	task.Metadata.CustomFields["tb_snoozed_at"] = task.UpdatedAt.Format(time.RFC3339)
+	task.Metadata.CustomFields["tb_snooze_count"] = taskCustomInt(*task, "tb_snooze_count") + 1
+
+	result := map[string]interface{}{
+		"task_id":      task.ID,
+		"source":       string(task.Source),
+		"previous_due": previousDue,
+		"new_due":      newDue,
+		"dry_run":      dryRun,
+		"remote":       "skipped",
+	}
+
+	if dryRun {
+		return snoozeResult(result)
+	}
+	if err := s.taskStore.SaveTask(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to save task: %w", err)
+	}
+	if syncRemote {
+		remote, note := s.pushRescheduledTask(ctx, task)
+		result["remote"] = remote
+		if note != "" {
+			result["remote_note"] = note
+		}
+	}
+	return snoozeResult(result)
+}
+
+// pushRescheduledTask 将改期结果回写到任务所属 provider，返回回写状态和说明；provider 不可达时加入离线队列。
+func (s *Server) pushRescheduledTask(ctx context.Context, task *model.Task) (string, string) {
+	if task.Source == "" || task.Source == model.SourceLocal || strings.TrimSpace(task.SourceRawID) == "" {
+		return "skipped", "local task"
+	}
+	if parseMicrosoftStepID(task.SourceRawID) != "" {
+		return "skipped", "microsoft checklist step has no due date"
+	}
+	p, ok := s.lookupProvider(ctx, string(task.Source))
+	if !ok || p == nil || !p.IsAuthenticated() {
+		return "skipped", fmt.Sprintf("provider %s not available", task.Source)
+	}
+	if !p.Capabilities().SupportsDueDate {
+		return "skipped", fmt.Sprintf("provider %s does not support due date", task.Source)
+	}
+	return s.pushTaskWrite(ctx, task, queuedUpdate)
+}
+
+// resolveSnoozeSlot 解析命名时间槽（tomorrow morning/next week 等）或显式日期。
+func resolveSnoozeSlot(slot string, now time.Time) (time.Time, error) {
+	normalized := strings.ToLower(strings.TrimSpace(slot))
+	normalized = strings.NewReplacer("-", "_", " ", "_").Replace(normalized)
+	at := func(day time.Time, hour int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, now.Location())
+	}
+
+	switch normalized {
+	case "later_today", "this_evening", "tonight":
+		if now.Hour() >= 18 {
+			return now.Add(3 * time.Hour), nil
+		}
+		return at(now, 18), nil
+	case "tomorrow", "tomorrow_morning":
+		return at(now.AddDate(0, 0, 1), 9), nil
+	case "tomorrow_afternoon":
+		return at(now.AddDate(0, 0, 1), 14), nil
+	case "tomorrow_evening":
+		return at(now.AddDate(0, 0, 1), 18), nil
+	case "this_weekend", "weekend":
+		days := (int(time.Saturday) - int(now.Weekday()) + 7) % 7
+		if days == 0 && now.Hour() >= 10 {
+			days = 7
+		}
+		return at(now.AddDate(0, 0, days), 10), nil
+	case "next_week":
+		days := (int(time.Monday) - int(now.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return at(now.AddDate(0, 0, days), 9), nil
+	case "next_month":
+		first := time.Date(now.Year(), now.Month()+1, 1, 9, 0, 0, 0, now.Location())
+		return first, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", slot, now.Location()); err == nil {
+		return at(t, 9), nil
+	}
+	if t, err := time.Parse(time.RFC3339, slot); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unsupported snooze slot: %s", slot)
+}
+
+// applySnoozeDuration 在基准时间上叠加时长，支持 Go duration 与 d/w 后缀。
+func applySnoozeDuration(base time.Time, duration string) (time.Time, error) {
+	value := strings.ToLower(strings.TrimSpace(duration))
+	if m := snoozeDurationPattern.FindStringSubmatch(value); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "m":
+			return base.Add(time.Duration(n) * time.Minute), nil
+		case "h":
+			return base.Add(time.Duration(n) * time.Hour), nil
+		case "d":
+			return base.AddDate(0, 0, n), nil
+		case "w":
+			return base.AddDate(0, 0, 7*n), nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid duration: %s", duration)
+	}
+	return base.Add(d), nil
+}
+
+// normalizeDueForProvider 按 provider 的截止时间精度调整结果。
+// Google Tasks 只保存日期部分，这里提前截断，避免本地与远端出现时间差。
+func normalizeDueForProvider(source model.TaskSource, due time.Time) time.Time {
+	if source == model.SourceGoogle {
+		return time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return due
+}
+
+func snoozeResult(result map[string]interface{}) (*mcp.CallToolResult, error) {
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestHandleOverdueTasksGroupsByListWithBuckets(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+
+	now := time.Now()
+	seed := []struct {
+		id     string
+		listID string
+		days   int
+		status model.TaskStatus
+	}{
+		{id: "a1", listID: "list-a", days: 1, status: model.StatusTodo},
+		{id: "a2", listID: "list-a", days: 40, status: model.StatusInProgress},
+		{id: "b1", listID: "list-b", days: 5, status: model.StatusTodo},
+		{id: "b2", listID: "list-b", days: 10, status: model.StatusCompleted},
+		{id: "c1", listID: "list-c", days: -3, status: model.StatusTodo},
+	}
+	for _, item := range seed {
+		due := now.AddDate(0, 0, -item.days)
+		if err := store.SaveTask(ctx, &model.Task{
+			ID:        item.id,
+			Title:     item.id,
+			Status:    item.status,
+			ListID:    item.listID,
+			ListName:  item.listID,
+			DueDate:   &due,
+			Source:    model.SourceMicrosoft,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+	}
+
+	res, err := s.handleOverdueTasks(ctx, buildCallToolRequest(t, map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("overdue tasks: %v", err)
+	}
+	payload := parseJSONResult(t, res)
+	summary := payload["summary"].(map[string]interface{})
+	if int(summary["overdue_count"].(float64)) != 3 {
+		t.Fatalf("unexpected overdue_count: %#v", summary)
+	}
+	buckets := summary["buckets"].(map[string]interface{})
+	if int(buckets["1_day"].(float64)) != 1 || int(buckets["2_7_days"].(float64)) != 1 || int(buckets["over_30_days"].(float64)) != 1 {
+		t.Fatalf("unexpected buckets: %#v", buckets)
+	}
+
+	groups := payload["groups"].([]interface{})
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+	first := groups[0].(map[string]interface{})
+	if first["list_id"] != "list-a" || int(first["count"].(float64)) != 2 {
+		t.Fatalf("unexpected first group: %#v", first)
+	}
+	tasks := first["tasks"].([]interface{})
+	if tasks[0].(map[string]interface{})["task_id"] != "a2" {
+		t.Fatalf("expected most overdue task first: %#v", tasks)
+	}
+}
+
+func TestOverdueAgeBucket(t *testing.T) {
+	cases := map[int]string{1: "1_day", 2: "2_7_days", 7: "2_7_days", 8: "8_30_days", 30: "8_30_days", 31: "over_30_days"}
+	for days, want := range cases {
+		if got := overdueAgeBucket(days); got != want {
+			t.Fatalf("days=%d got=%s want=%s", days, got, want)
+		}
+	}
+}
+
+func TestHandleSnoozeTaskByDurationAndSlot(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+
+	now := time.Now()
+	due := now.AddDate(0, 0, 2)
+	if err := store.SaveTask(ctx, &model.Task{
+		ID:        "task-snooze",
+		Title:     "推迟任务",
+		Status:    model.StatusDeferred,
+		DueDate:   &due,
+		Source:    model.SourceLocal,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("save task: %v", err)
+	}
+
+	if _, err := s.handleSnoozeTask(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id":  "task-snooze",
+		"duration": "3d",
+	})); err != nil {
+		t.Fatalf("snooze by duration: %v", err)
+	}
+	updated, err := store.GetTask(ctx, "task-snooze")
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if !updated.DueDate.Equal(due.AddDate(0, 0, 3)) {
+		t.Fatalf("expected due pushed by 3 days from current due, got %v", updated.DueDate)
+	}
+	if updated.Status != model.StatusTodo {
+		t.Fatalf("expected deferred task to return to todo, got %s", updated.Status)
+	}
+	if taskCustomInt(*updated, "tb_snooze_count") != 1 {
+		t.Fatalf("expected snooze count 1: %#v", updated.Metadata.CustomFields)
+	}
+
+	res, err := s.handleSnoozeTask(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id": "task-snooze",
+		"until":   "tomorrow morning",
+		"dry_run": true,
+	}))
+	if err != nil {
+		t.Fatalf("snooze by slot: %v", err)
+	}
+	payload := parseJSONResult(t, res)
+	if payload["remote"] != "skipped" || payload["dry_run"] != true {
+		t.Fatalf("unexpected payload: %#v", payload)
+	}
+
+	if _, err := s.handleSnoozeTask(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id": "task-snooze",
+		"until":   "someday",
+	})); err == nil {
+		t.Fatalf("expected error for unsupported slot")
+	}
+}
+
+func TestHandleSnoozeTaskRoundsUpForDateOnlyProvider(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	due := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, time.UTC)
+	if err := store.SaveTask(ctx, &model.Task{ID: "google-none", Title: "无截止日期", Status: model.StatusTodo, Source: model.SourceGoogle}); err != nil {
+		t.Fatalf("save task: %v", err)
+	}
+
+	// Google 只保存日期，2h 的推迟向上取整到下一天，而不是保持原日期
+	for _, tz := range []string{"UTC", "America/New_York", "Asia/Shanghai"} {
+		taskDue := due
+		if err := store.SaveTask(ctx, &model.Task{ID: "google-" + tz, Title: "有截止日期", Status: model.StatusTodo, DueDate: &taskDue, Source: model.SourceGoogle}); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+		res, err := s.handleSnoozeTask(ctx, buildCallToolRequest(t, map[string]interface{}{
+			"task_id":  "google-" + tz,
+			"duration": "2h",
+			"timezone": tz,
+			"dry_run":  true,
+		}))
+		if err != nil {
+			t.Fatalf("snooze (%s): %v", tz, err)
+		}
+		if got := parseJSONResult(t, res)["new_due"]; got != due.AddDate(0, 0, 1).Format(time.RFC3339) {
+			t.Fatalf("expected due moved to the next day in %s, got %v", tz, got)
+		}
+	}
+
+	if _, err := s.handleSnoozeTask(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id":  "google-none",
+		"duration": "30m",
+		"timezone": "UTC",
+	})); err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+	updated, err := store.GetTask(ctx, "google-none")
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if updated.DueDate == nil || !updated.DueDate.Equal(due) {
+		t.Fatalf("expected due rounded up to tomorrow, got %v", updated.DueDate)
+	}
+}
+
+func TestResolveSnoozeSlot(t *testing.T) {
+	// 2026-03-04 是周三
+	now := time.Date(2026, 3, 4, 11, 30, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"tomorrow_morning": time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC),
+		"next week":        time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC),
+		"this-weekend":     time.Date(2026, 3, 7, 10, 0, 0, 0, time.UTC),
+		"next_month":       time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC),
+		"later today":      time.Date(2026, 3, 4, 18, 0, 0, 0, time.UTC),
+		"2026-03-20":       time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC),
+	}
+	for slot, want := range cases {
+		got, err := resolveSnoozeSlot(slot, now)
+		if err != nil {
+			t.Fatalf("slot %s: %v", slot, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("slot %s: got=%v want=%v", slot, got, want)
+		}
+	}
+}
+
+func TestNormalizeDueForProviderTruncatesGoogle(t *testing.T) {
+	due := time.Date(2026, 3, 5, 9, 30, 0, 0, time.UTC)
+	if got := normalizeDueForProvider(model.SourceGoogle, due); got.Hour() != 0 || got.Minute() != 0 {
+		t.Fatalf("expected google due truncated to date, got %v", got)
+	}
+	if got := normalizeDueForProvider(model.SourceMicrosoft, due); !got.Equal(due) {
+		t.Fatalf("expected microsoft due unchanged, got %v", got)
+	}
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+// defaultSemanticSearchLimit semantic_search 默认返回的任务数
+const defaultSemanticSearchLimit = 10
+
+// semanticMatch 语义搜索的一条结果
+type semanticMatch struct {
+	Score float64     `json:"score"`
+	Task  compactTask `json:"task"`
+}
+
+// handleSemanticSearch 按语义检索本地任务：先为新增或内容变化的任务补充向量，再按与查询的相似度排序
+func (s *Server) handleSemanticSearch(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	if s.embeddings == nil {
+		return nil, fmt.Errorf("semantic search is disabled (storage.embeddings.enabled)")
+	}
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage is not configured")
+	}
+	query := strings.TrimSpace(getString(rawArgs, "query"))
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	source, err := resolveProviderNameStrict(getString(rawArgs, "source"))
+	if err != nil {
+		return nil, err
+	}
+	limit := defaultSemanticSearchLimit
+	if value, ok := getInt(rawArgs, "limit"); ok && value > 0 {
+		limit = value
+	}
+	includeCompleted, _ := getBool(rawArgs, "include_completed")
+	minScore := 0.0
+	if raw, ok := rawArgs["min_score"]; ok {
+		if err := json.Unmarshal(raw, &minScore); err != nil {
+			return nil, fmt.Errorf("invalid min_score: %w", err)
+		}
+	}
+
+	tasks, err := s.taskStore.ListTasks(ctx, storage.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	payload := map[string]interface{}{
+		"query": query,
+		"model": s.embeddings.Model(),
+	}
+	// 向量化失败时仍用已有的向量检索，只是新任务暂时搜不到
+	embedded, err := s.embeddings.Refresh(ctx, tasks)
+	if err != nil {
+		log.Warn().Str("component", "mcp").Err(err).Msg("failed to refresh task embeddings")
+		payload["index_error"] = err.Error()
+	}
+	if embedded > 0 {
+		payload["newly_embedded"] = embedded
+	}
+
+	byID := make(map[string]*model.Task, len(tasks))
+	candidates := make(map[string]bool, len(tasks))
+	for i := range tasks {
+		task := &tasks[i]
+		if source != "" && string(task.Source) != source {
+			continue
+		}
+		if !includeCompleted && (task.Status == model.StatusCompleted || task.Status == model.StatusCancelled) {
+			continue
+		}
+		byID[task.ID] = task
+		candidates[task.ID] = true
+	}
+	matches, err := s.embeddings.Search(ctx, query, candidates, limit)
+	if err != nil {
+		return nil, fmt.Errorf("semantic search failed: %w", err)
+	}
+
+	results := make([]semanticMatch, 0, len(matches))
+	for _, match := range matches {
+		if match.Score < minScore {
+			break
+		}
+		compact := toCompactTasks([]model.Task{*byID[match.TaskID]})[0]
+		results = append(results, semanticMatch{Score: math.Round(match.Score*1000) / 1000, Task: compact})
+	}
+	payload["total"] = len(results)
+	payload["results"] = results
+
+	jsonResult, err := toJSON(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: jsonResult},
+			&mcp.TextContent{Text: formatSemanticMatches(query, results)},
+		},
+		StructuredContent: payload,
+	}, nil
+}
+
+// formatSemanticMatches 将搜索结果格式化为可读文本
+func formatSemanticMatches(query string, results []semanticMatch) string {
+	if len(results) == 0 {
+		return fmt.Sprintf("没有找到与“%s”相关的任务", query)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "与“%s”最相关的 %d 个任务：", query, len(results))
+	for i, result := range results {
+		fmt.Fprintf(&b, "\n%d. [%.2f] %s (%s, %s)", i+1, result.Score, result.Task.Title, result.Task.Source, result.Task.ID)
+	}
+	return b.String()
+}
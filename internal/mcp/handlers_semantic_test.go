@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/embedding"
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+func TestSemanticSearch(t *testing.T) {
+	dir := t.TempDir()
+	store, err := filestore.New(dir, "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	ctx := context.Background()
+	for _, task := range []*model.Task{
+		{ID: "t1", Title: "Review Q3 budget", Description: "spending per team", Status: model.StatusTodo, Source: model.SourceLocal},
+		{ID: "t2", Title: "Book flights", Status: model.StatusTodo, Source: model.SourceLocal},
+		{ID: "t3", Title: "Draft Q3 budget memo", Status: model.StatusCompleted, Source: model.SourceGoogle},
+	} {
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+	index, err := embedding.Open(dir, embedding.NewHashEmbedder(0), 0)
+	if err != nil {
+		t.Fatalf("open index: %v", err)
+	}
+
+	s := NewServer(WithTaskStorage(store), WithEmbeddingIndex(index))
+	session := connectBreakdownClient(t, s, nil)
+	res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{
+		Name:      "semantic_search",
+		Arguments: map[string]any{"query": "tasks about the Q3 budget", "limit": 1},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("call semantic_search: %v %+v", err, res)
+	}
+	var payload struct {
+		Total         int             `json:"total"`
+		NewlyEmbedded int             `json:"newly_embedded"`
+		Results       []semanticMatch `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(res.Content[0].(*sdkmcp.TextContent).Text), &payload); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if payload.NewlyEmbedded != 3 || payload.Total != 1 || payload.Results[0].Task.ID != "t1" {
+		t.Fatalf("unexpected result: %+v", payload)
+	}
+
+	res, err = session.CallTool(ctx, &sdkmcp.CallToolParams{
+		Name:      "semantic_search",
+		Arguments: map[string]any{"query": "Q3 budget", "source": "google", "include_completed": true},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("call semantic_search: %v %+v", err, res)
+	}
+	payload.NewlyEmbedded = 0
+	if err := json.Unmarshal([]byte(res.Content[0].(*sdkmcp.TextContent).Text), &payload); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if payload.NewlyEmbedded != 0 || payload.Total != 1 || payload.Results[0].Task.ID != "t3" {
+		t.Fatalf("unexpected filtered result: %+v", payload)
+	}
+}
+
+func TestSemanticSearchRequiresEmbeddings(t *testing.T) {
+	s := NewServer()
+	if s.GetTools()["semantic_search"] {
+		t.Fatal("semantic_search should not be registered without an embedding index")
+	}
+}
@@ -0,0 +1,224 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+const (
+	defaultStatisticsWindowDays = 14
+	maxStatisticsWindowDays     = 90
+)
+
+type dailyTaskStat struct {
+	Date      string `json:"date"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+type groupTaskStat struct {
+	Key            string  `json:"key"`
+	Name           string  `json:"name,omitempty"`
+	Total          int     `json:"total"`
+	Active         int     `json:"active"`
+	Completed      int     `json:"completed"`
+	Overdue        int     `json:"overdue"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// handleTaskStatistics 基于本地缓存统计任务数量与趋势，供 AI 生成效率总结。
+func (s *Server) handleTaskStatistics(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: `{"totals":{"total":0},"daily":[],"by_project":[],"by_source":[]}`}},
+		}, nil
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	windowDays, ok := getInt(rawArgs, "window_days")
+	if !ok || windowDays <= 0 {
+		windowDays = defaultStatisticsWindowDays
+	}
+	if windowDays > maxStatisticsWindowDays {
+		windowDays = maxStatisticsWindowDays
+	}
+
+	query := storage.Query{}
+	if source := getString(rawArgs, "source"); source != "" {
+		resolvedSource, err := resolveProviderNameStrict(source)
+		if err != nil {
+			return nil, err
+		}
+		query.Sources = []model.TaskSource{model.TaskSource(resolvedSource)}
+	}
+	tasks, err := s.taskStore.QueryTasks(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	timezone := getString(rawArgs, "timezone")
+	if timezone == "" {
+		timezone = s.effectiveIntelligenceConfig().Timezone
+	}
+	loc := resolveLocation(timezone)
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	start := today.AddDate(0, 0, -(windowDays - 1))
+	prevStart := start.AddDate(0, 0, -windowDays)
+
+	daily := make([]dailyTaskStat, windowDays)
+	dayIndex := make(map[string]int, windowDays)
+	for i := 0; i < windowDays; i++ {
+		key := start.AddDate(0, 0, i).Format("2006-01-02")
+		daily[i] = dailyTaskStat{Date: key}
+		dayIndex[key] = i
+	}
+
+	projectNames := s.projectNameIndex(ctx)
+	byProject := make(map[string]*groupTaskStat)
+	bySource := make(map[string]*groupTaskStat)
+	byStatus := make(map[string]int)
+
+	activeCount := 0
+	overdueCount := 0
+	createdInWindow := 0
+	completedInWindow := 0
+	completedPrevious := 0
+	for _, task := range tasks {
+		byStatus[string(task.Status)]++
+		completed := task.Status == model.StatusCompleted
+		active := task.Status == model.StatusTodo || task.Status == model.StatusInProgress
+		overdue := active && calcOverdueDays(task.DueDate, now) > 0
+		if active {
+			activeCount++
+		}
+		if overdue {
+			overdueCount++
+		}
+
+		if idx, ok := dayIndex[task.CreatedAt.In(loc).Format("2006-01-02")]; ok && !task.CreatedAt.IsZero() {
+			daily[idx].Created++
+			createdInWindow++
+		}
+		if completedAt := completionTime(task); completedAt != nil && completed {
+			local := completedAt.In(loc)
+			if idx, ok := dayIndex[local.Format("2006-01-02")]; ok {
+				daily[idx].Completed++
+				completedInWindow++
+			} else if !local.Before(prevStart) && local.Before(start) {
+				completedPrevious++
+			}
+		}
+
+		projectKey := strings.TrimSpace(getCustomFieldString(task, "tb_project_id"))
+		if projectKey == "" {
+			projectKey = "unassigned"
+		}
+		accumulateGroupStat(byProject, projectKey, projectNames[projectKey], completed, active, overdue)
+		sourceKey := string(task.Source)
+		if sourceKey == "" {
+			sourceKey = string(model.SourceLocal)
+		}
+		accumulateGroupStat(bySource, sourceKey, "", completed, active, overdue)
+	}
+
+	overdueRatio := 0.0
+	if activeCount > 0 {
+		overdueRatio = float64(overdueCount) / float64(activeCount)
+	}
+
+	result := map[string]interface{}{
+		"window_days": windowDays,
+		"timezone":    loc.String(),
+		"totals": map[string]interface{}{
+			"total":         len(tasks),
+			"active":        activeCount,
+			"overdue":       overdueCount,
+			"overdue_ratio": overdueRatio,
+			"by_status":     byStatus,
+		},
+		"trend": map[string]interface{}{
+			"created":            createdInWindow,
+			"completed":          completedInWindow,
+			"net":                createdInWindow - completedInWindow,
+			"previous_completed": completedPrevious,
+			"delta_completed":    completedInWindow - completedPrevious,
+			"avg_completed_day":  float64(completedInWindow) / float64(windowDays),
+		},
+		"daily":      daily,
+		"by_project": sortedGroupStats(byProject),
+		"by_source":  sortedGroupStats(bySource),
+	}
+
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// projectNameIndex 返回项目 ID 到名称的映射；项目存储不可用时返回空映射。
+func (s *Server) projectNameIndex(ctx context.Context) map[string]string {
+	names := make(map[string]string)
+	if s.projectStore == nil {
+		return names
+	}
+	projects, err := s.projectStore.ListProjects(ctx, "")
+	if err != nil {
+		return names
+	}
+	for _, item := range projects {
+		names[item.ID] = item.Name
+	}
+	return names
+}
+
+func accumulateGroupStat(groups map[string]*groupTaskStat, key, name string, completed, active, overdue bool) {
+	stat, ok := groups[key]
+	if !ok {
+		stat = &groupTaskStat{Key: key, Name: name}
+		groups[key] = stat
+	}
+	stat.Total++
+	if completed {
+		stat.Completed++
+	}
+	if active {
+		stat.Active++
+	}
+	if overdue {
+		stat.Overdue++
+	}
+}
+
+func sortedGroupStats(groups map[string]*groupTaskStat) []groupTaskStat {
+	out := make([]groupTaskStat, 0, len(groups))
+	for _, stat := range groups {
+		if stat.Total > 0 {
+			stat.CompletionRate = float64(stat.Completed) / float64(stat.Total)
+		}
+		out = append(out, *stat)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Total == out[j].Total {
+			return out[i].Key < out[j].Key
+		}
+		return out[i].Total > out[j].Total
+	})
+	return out
+}
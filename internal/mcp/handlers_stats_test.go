@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/project"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestHandleTaskStatistics(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	cfg.Timezone = "UTC"
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	projectStore, err := project.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new project store: %v", err)
+	}
+	s.projectStore = projectStore
+	if err := projectStore.SaveProject(context.Background(), &project.Project{ID: "proj-1", Name: "官网改版"}); err != nil {
+		t.Fatalf("save project: %v", err)
+	}
+
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	overdue := now.AddDate(0, 0, -3)
+	old := now.AddDate(0, 0, -60)
+	tasks := []*model.Task{
+		{ID: "t1", Title: "done", Status: model.StatusCompleted, CreatedAt: yesterday, CompletedAt: &now},
+		{ID: "t2", Title: "overdue", Status: model.StatusTodo, CreatedAt: now, DueDate: &overdue,
+			Metadata: &model.TaskMetadata{CustomFields: map[string]interface{}{"tb_project_id": "proj-1"}}},
+		{ID: "t3", Title: "active", Status: model.StatusInProgress, CreatedAt: old,
+			Metadata: &model.TaskMetadata{CustomFields: map[string]interface{}{"tb_project_id": "proj-1"}}},
+	}
+	for _, task := range tasks {
+		task.UpdatedAt = now
+		task.Source = model.SourceLocal
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+	}
+
+	res, err := s.handleTaskStatistics(ctx, buildCallToolRequest(t, map[string]interface{}{"window_days": 7}))
+	if err != nil {
+		t.Fatalf("task statistics: %v", err)
+	}
+	payload := parseJSONResult(t, res)
+
+	totals := payload["totals"].(map[string]interface{})
+	if int(totals["total"].(float64)) != 3 || int(totals["overdue"].(float64)) != 1 {
+		t.Fatalf("unexpected totals: %#v", totals)
+	}
+	if totals["overdue_ratio"].(float64) != 0.5 {
+		t.Fatalf("unexpected overdue_ratio: %#v", totals)
+	}
+
+	trend := payload["trend"].(map[string]interface{})
+	if int(trend["created"].(float64)) != 2 || int(trend["completed"].(float64)) != 1 {
+		t.Fatalf("unexpected trend: %#v", trend)
+	}
+	daily := payload["daily"].([]interface{})
+	if len(daily) != 7 {
+		t.Fatalf("expected 7 daily buckets, got %d", len(daily))
+	}
+	last := daily[len(daily)-1].(map[string]interface{})
+	if last["date"] != now.Format("2006-01-02") || int(last["completed"].(float64)) != 1 {
+		t.Fatalf("unexpected last day: %#v", last)
+	}
+
+	byProject := payload["by_project"].([]interface{})
+	first := byProject[0].(map[string]interface{})
+	if first["key"] != "proj-1" || first["name"] != "官网改版" || int(first["total"].(float64)) != 2 {
+		t.Fatalf("unexpected project breakdown: %#v", byProject)
+	}
+}
@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+// statusResourceURI 服务状态资源
+const statusResourceURI = "taskbridge://status"
+
+// adapterCacheStatus 单个来源在本地缓存中的概况
+type adapterCacheStatus struct {
+	Tasks         int        `json:"tasks"`
+	LastUpdatedAt *time.Time `json:"last_updated_at,omitempty"`
+	LastSyncTime  *time.Time `json:"last_sync_time,omitempty"`
+	// Fresh 启用 mcp.task_cache 时最近一次拉取是否仍在 max_age 内；Refreshing 正在后台刷新
+	Fresh      *bool `json:"fresh,omitempty"`
+	Refreshing bool  `json:"refreshing,omitempty"`
+}
+
+// adapterStatus 已配置 Provider 的健康状态
+type adapterStatus struct {
+	Name          string     `json:"name"`
+	Authenticated bool       `json:"authenticated"`
+	Healthy       bool       `json:"healthy"`
+	TokenValid    *bool      `json:"token_valid,omitempty"`
+	TokenExpires  *time.Time `json:"token_expires_at,omitempty"`
+	// ReadOnly 登录时只获得只读 scope，写入类工具不会修改该 Provider
+	ReadOnly bool `json:"read_only,omitempty"`
+	// Credential 后台凭证检查的最近结果，未启用检查或尚未检查时为空
+	Credential *provider.CredentialHealth `json:"credential,omitempty"`
+	Cache      adapterCacheStatus         `json:"cache"`
+	LastRun    *syncRunRecord             `json:"last_run,omitempty"`
+	// QueuedWrites 离线队列中等待写入该 Provider 的任务数；OfflineSince 最近一次写入因无法连接失败的时间，恢复后清除
+	QueuedWrites int        `json:"queued_writes,omitempty"`
+	OfflineSince *time.Time `json:"offline_since,omitempty"`
+}
+
+// handleStatusResource 返回服务版本、运行时长、Provider 健康状态、缓存新鲜度、最近同步时间、离线写入队列与 Provider 读取缓存，供客户端自检。
+func (s *Server) handleStatusResource(ctx context.Context, _ *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	now := time.Now()
+	cache := make(map[string]*adapterCacheStatus)
+	totalTasks := 0
+	var newest *time.Time
+	if s.taskStore != nil {
+		tasks, err := s.taskStore.ListTasks(ctx, storage.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		totalTasks = len(tasks)
+		for _, task := range tasks {
+			source := string(task.Source)
+			if source == "" {
+				source = string(model.SourceLocal)
+			}
+			entry, ok := cache[source]
+			if !ok {
+				entry = &adapterCacheStatus{}
+				cache[source] = entry
+			}
+			entry.Tasks++
+			updated := task.UpdatedAt
+			if entry.LastUpdatedAt == nil || updated.After(*entry.LastUpdatedAt) {
+				entry.LastUpdatedAt = &updated
+			}
+			if newest == nil || updated.After(*newest) {
+				newest = &updated
+			}
+		}
+	}
+
+	s.syncState.mu.Lock()
+	running, startedAt := s.syncState.running, s.syncState.startedAt
+	lastRun := make(map[string]*syncRunRecord, len(s.syncState.lastRun))
+	for name, record := range s.syncState.lastRun {
+		lastRun[name] = record
+	}
+	s.syncState.mu.Unlock()
+
+	providers := s.providerSnapshot(ctx)
+	// 后台凭证检查只覆盖服务自身的 Provider，不适用于租户的 Provider
+	_, tenantScoped := tenantProvidersFrom(ctx)
+	adapters := make([]adapterStatus, 0, len(providers))
+	for _, name := range s.providerNames(ctx) {
+		p := providers[name]
+		item := adapterStatus{Name: name, LastRun: lastRun[name]}
+		if entry, ok := cache[name]; ok {
+			item.Cache = *entry
+		}
+		if p != nil {
+			item.Authenticated = p.IsAuthenticated()
+			item.Healthy = item.Authenticated
+			item.ReadOnly = provider.IsReadOnly(p)
+			if info := p.GetTokenInfo(); info != nil && info.HasToken {
+				valid := info.IsValid
+				item.TokenValid = &valid
+				item.Healthy = item.Healthy && (valid || info.Refreshable)
+				if !info.ExpiresAt.IsZero() {
+					expires := info.ExpiresAt
+					item.TokenExpires = &expires
+				}
+			}
+			if health, ok := s.credentialHealth(name); ok && !tenantScoped {
+				item.Credential = &health
+				item.Healthy = item.Healthy && health.Healthy()
+			}
+		}
+		if !tenantScoped {
+			item.QueuedWrites = s.queuedWritesFor(name)
+			if s.writeQueue != nil {
+				if at, ok := s.writeQueue.offlineSince(name); ok {
+					item.OfflineSince = &at
+					item.Healthy = false
+				}
+			}
+		}
+		if s.taskStore != nil {
+			freshness := s.providerFreshness(ctx, name, now)
+			item.Cache.LastSyncTime = freshness.LastSyncTime
+			if s.taskCacheEnabled() && !tenantScoped {
+				fresh := freshness.Fresh
+				item.Cache.Fresh = &fresh
+				item.Cache.Refreshing = freshness.Refreshing
+			}
+		}
+		adapters = append(adapters, item)
+	}
+
+	status := map[string]interface{}{
+		"name":           s.config.Name,
+		"version":        s.config.Version,
+		"transport":      s.config.Transport,
+		"started_at":     s.startedAt,
+		"uptime_seconds": int64(now.Sub(s.startedAt).Seconds()),
+		"adapters":       adapters,
+		"cache": map[string]interface{}{
+			"available":       s.taskStore != nil,
+			"tasks":           totalTasks,
+			"last_updated_at": newest,
+			"by_source":       cache,
+		},
+		"sync": map[string]interface{}{
+			"running":    running,
+			"started_at": optionalTime(running, startedAt),
+		},
+		"sessions": s.sessionStatus(),
+	}
+	// 离线队列与读取缓存只作用于服务自身的 Provider
+	if !tenantScoped {
+		status["offline"] = s.writeQueueStatus()
+		status["adapter_cache"] = s.adapterCache.status()
+	}
+	output, err := toJSON(status)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: statusResourceURI, Text: output}},
+	}, nil
+}
+
+// optionalTime 条件成立时返回时间，否则返回 nil 以在 JSON 中输出 null
+func optionalTime(ok bool, t time.Time) *time.Time {
+	if !ok {
+		return nil
+	}
+	return &t
+}
+
+// sessionStatus 汇总当前活跃会话数、协商的协议版本分布与保活配置；断线会话被保活 ping 关闭后不再计入
+func (s *Server) sessionStatus() map[string]interface{} {
+	active := 0
+	versions := make(map[string]int)
+	for session := range s.server.Sessions() {
+		active++
+		versions[sessionProtocolVersion(session)]++
+	}
+	status := map[string]interface{}{"active": active, "protocol_versions": versions}
+	if s.config.KeepAlive > 0 && s.httpTransport() != "" {
+		status["keep_alive"] = s.config.KeepAlive.String()
+	}
+	if s.config.SessionIdleTimeout > 0 && s.httpTransport() == "streamable" {
+		status["idle_timeout"] = s.config.SessionIdleTimeout.String()
+	}
+	if s.eventStore != nil && s.httpTransport() == "streamable" {
+		status["resumable"] = true
+	}
+	if s.httpConfig != nil && s.httpConfig.SSEHeartbeat > 0 && s.httpTransport() != "" {
+		status["sse_heartbeat"] = s.httpConfig.SSEHeartbeat.String()
+	}
+	return status
+}
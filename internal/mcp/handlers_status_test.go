@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+func TestReadStatusResource(t *testing.T) {
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	ctx := context.Background()
+	for _, task := range []*model.Task{
+		{ID: "local-1", Title: "本地任务", Status: model.StatusTodo, Source: model.SourceLocal},
+		{ID: "google-1", Title: "远端任务", Status: model.StatusTodo, Source: model.SourceGoogle},
+		{ID: "google-2", Title: "远端任务 2", Status: model.StatusCompleted, Source: model.SourceGoogle},
+	} {
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+	lastSync := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := store.SetLastSyncTime(ctx, model.SourceGoogle, lastSync); err != nil {
+		t.Fatalf("set last sync time: %v", err)
+	}
+
+	s := NewServer(WithTaskStorage(store), WithProviders(map[string]provider.Provider{"google": &mockProvider{}}))
+	session := connectBreakdownClient(t, s, nil)
+	res, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "taskbridge://status"})
+	if err != nil {
+		t.Fatalf("read status: %v", err)
+	}
+
+	var status struct {
+		Version       string          `json:"version"`
+		UptimeSeconds int64           `json:"uptime_seconds"`
+		Adapters      []adapterStatus `json:"adapters"`
+		Cache         struct {
+			Tasks    int                           `json:"tasks"`
+			BySource map[string]adapterCacheStatus `json:"by_source"`
+		} `json:"cache"`
+	}
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if status.Version == "" || status.UptimeSeconds < 0 {
+		t.Fatalf("unexpected server info: %+v", status)
+	}
+	if status.Cache.Tasks != 3 || status.Cache.BySource["local"].Tasks != 1 || status.Cache.BySource["google"].Tasks != 2 {
+		t.Fatalf("unexpected cache status: %+v", status.Cache)
+	}
+	if len(status.Adapters) != 1 {
+		t.Fatalf("expected one adapter, got %+v", status.Adapters)
+	}
+	google := status.Adapters[0]
+	if google.Name != "google" || !google.Healthy || google.Cache.Tasks != 2 {
+		t.Fatalf("unexpected adapter status: %+v", google)
+	}
+	if google.Cache.LastSyncTime == nil || !google.Cache.LastSyncTime.Equal(lastSync) {
+		t.Fatalf("expected last sync time %v, got %v", lastSync, google.Cache.LastSyncTime)
+	}
+}
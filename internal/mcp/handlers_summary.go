@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+const (
+	defaultSummaryTop = 3
+	// summaryOverloadMinutes 当日预估工时超过该值时提示过载
+	summaryOverloadMinutes = 8 * 60
+)
+
+// taskSummaryStats summarize_tasks 的结构化统计
+type taskSummaryStats struct {
+	Scope          string        `json:"scope"`
+	Project        string        `json:"project,omitempty"`
+	Date           string        `json:"date"`
+	Total          int           `json:"total"`
+	Active         int           `json:"active"`
+	InProgress     int           `json:"in_progress"`
+	DueToday       int           `json:"due_today"`
+	Overdue        int           `json:"overdue"`
+	CompletedToday int           `json:"completed_today"`
+	Completed      int           `json:"completed"`
+	Blocked        int           `json:"blocked"`
+	EstimatedMins  int           `json:"estimated_minutes"`
+	TopPriorities  []compactTask `json:"top_priorities"`
+	Risks          []string      `json:"risks"`
+}
+
+// handleSummarizeTasks 生成项目或当日任务的简明摘要（数量、重点任务、风险），可直接用于回复。
+func (s *Server) handleSummarizeTasks(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	projectArg := strings.TrimSpace(getString(rawArgs, "project"))
+	scope := strings.ToLower(strings.TrimSpace(getString(rawArgs, "scope")))
+	if scope == "" {
+		scope = "today"
+		if projectArg != "" {
+			scope = "project"
+		}
+	}
+	if scope != "today" && scope != "project" {
+		return nil, fmt.Errorf("invalid scope: %s", scope)
+	}
+	if scope == "project" && projectArg == "" {
+		return nil, fmt.Errorf("project is required when scope is project")
+	}
+	top, ok := getInt(rawArgs, "top")
+	if !ok || top <= 0 {
+		top = defaultSummaryTop
+	}
+
+	query := storage.Query{}
+	if source := getString(rawArgs, "source"); source != "" {
+		resolvedSource, err := resolveProviderNameStrict(source)
+		if err != nil {
+			return nil, err
+		}
+		query.Sources = []model.TaskSource{model.TaskSource(resolvedSource)}
+	}
+	tasks, err := s.taskStore.QueryTasks(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	byID := make(map[string]*model.Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+
+	timezone := getString(rawArgs, "timezone")
+	if timezone == "" {
+		timezone = s.effectiveIntelligenceConfig().Timezone
+	}
+	loc := resolveLocation(timezone)
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	stats := taskSummaryStats{Scope: scope, Date: today.Format("2006-01-02"), Risks: []string{}}
+	projectIDs := map[string]bool{}
+	if scope == "project" {
+		projectIDs = s.resolveProjectFilter(ctx, []string{projectArg})
+		stats.Project = projectArg
+		names := s.projectNameIndex(ctx)
+		for id := range projectIDs {
+			if name := names[id]; name != "" {
+				stats.Project = name
+			}
+		}
+	}
+
+	focus := make([]model.Task, 0)
+	highDueToday := 0
+	for _, task := range tasks {
+		active := task.Status == model.StatusTodo || task.Status == model.StatusInProgress
+		dueToday := task.DueDate != nil && !task.DueDate.In(loc).Before(today) && task.DueDate.In(loc).Before(tomorrow)
+		overdue := active && task.DueDate != nil && task.DueDate.In(loc).Before(today)
+		completedToday := false
+		if at := completionTime(task); at != nil && task.Status == model.StatusCompleted {
+			completedToday = !at.In(loc).Before(today) && at.In(loc).Before(tomorrow)
+		}
+
+		if scope == "project" {
+			if !projectIDs[getCustomFieldString(task, "tb_project_id")] {
+				continue
+			}
+		} else if !completedToday && !(active && (dueToday || overdue || task.Status == model.StatusInProgress)) {
+			continue
+		}
+
+		stats.Total++
+		if task.Status == model.StatusCompleted {
+			stats.Completed++
+		}
+		if completedToday {
+			stats.CompletedToday++
+		}
+		if !active {
+			continue
+		}
+		stats.Active++
+		if task.Status == model.StatusInProgress {
+			stats.InProgress++
+		}
+		if dueToday {
+			stats.DueToday++
+			if task.Priority >= model.PriorityHigh && task.Status == model.StatusTodo {
+				highDueToday++
+			}
+		}
+		if overdue {
+			stats.Overdue++
+		}
+		for _, id := range taskBlockedBy(task) {
+			if !newBlockerView(id, byID[id]).Resolved {
+				stats.Blocked++
+				break
+			}
+		}
+		stats.EstimatedMins += task.EstimatedMinutes
+		focus = append(focus, task)
+	}
+
+	sortTasksByKeys(focus, []taskSortKey{{Field: "priority", Desc: true}, {Field: "due_date"}})
+	if len(focus) > top {
+		focus = focus[:top]
+	}
+	stats.TopPriorities = toCompactTasks(focus)
+
+	if stats.Overdue > 0 {
+		stats.Risks = append(stats.Risks, fmt.Sprintf("%d 个任务已逾期", stats.Overdue))
+	}
+	if highDueToday > 0 {
+		stats.Risks = append(stats.Risks, fmt.Sprintf("%d 个高优先级任务今天到期但尚未开始", highDueToday))
+	}
+	if stats.Blocked > 0 {
+		stats.Risks = append(stats.Risks, fmt.Sprintf("%d 个任务被阻塞", stats.Blocked))
+	}
+	if scope == "today" && stats.EstimatedMins > summaryOverloadMinutes {
+		stats.Risks = append(stats.Risks, fmt.Sprintf("预估工时 %.1f 小时，超过一天容量", float64(stats.EstimatedMins)/60))
+	}
+
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: formatTaskSummary(stats, loc)}},
+		StructuredContent: stats,
+	}, nil
+}
+
+// formatTaskSummary 将统计结果渲染为简短的 Markdown 摘要。
+func formatTaskSummary(stats taskSummaryStats, loc *time.Location) string {
+	var b strings.Builder
+	if stats.Scope == "project" {
+		fmt.Fprintf(&b, "**项目「%s」概览**：共 %d 个任务，进行中 %d，待完成 %d，已完成 %d", stats.Project, stats.Total, stats.InProgress, stats.Active, stats.Completed)
+		if stats.Total > 0 {
+			fmt.Fprintf(&b, "（完成率 %d%%）", stats.Completed*100/stats.Total)
+		}
+		b.WriteString("。\n")
+	} else {
+		fmt.Fprintf(&b, "**今日任务（%s）**：今天到期 %d，逾期 %d，进行中 %d，今日已完成 %d。\n", stats.Date, stats.DueToday, stats.Overdue, stats.InProgress, stats.CompletedToday)
+	}
+	if stats.Total == 0 {
+		b.WriteString("暂无相关任务。")
+		return b.String()
+	}
+
+	if len(stats.TopPriorities) > 0 {
+		b.WriteString("\n**优先处理**：\n")
+		for i, task := range stats.TopPriorities {
+			fmt.Fprintf(&b, "%d. %s %s", i+1, model.Priority(task.Priority).Emoji(), task.Title)
+			if task.DueDate != nil {
+				fmt.Fprintf(&b, "（截止 %s）", task.DueDate.In(loc).Format("01-02"))
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(stats.Risks) > 0 {
+		fmt.Fprintf(&b, "\n**风险**：%s。", strings.Join(stats.Risks, "；"))
+	} else {
+		b.WriteString("\n暂无明显风险。")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
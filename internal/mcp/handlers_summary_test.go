@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/project"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func summaryText(t *testing.T, res *sdkmcp.CallToolResult) string {
+	t.Helper()
+	if len(res.Content) == 0 {
+		t.Fatalf("empty result content")
+	}
+	text, ok := res.Content[0].(*sdkmcp.TextContent)
+	if !ok {
+		t.Fatalf("result content is not text")
+	}
+	return text.Text
+}
+
+func TestHandleSummarizeTasksToday(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	cfg.Timezone = "UTC"
+	s, store, ctx := newIntelligenceTestServer(t, cfg, nil)
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+	nextWeek := today.AddDate(0, 0, 7)
+	seed := []model.Task{
+		{ID: "t1", Title: "提交报销", Status: model.StatusTodo, Priority: model.PriorityUrgent, DueDate: &today},
+		{ID: "t2", Title: "回复客户", Status: model.StatusTodo, Priority: model.PriorityMedium, DueDate: &yesterday},
+		{ID: "t3", Title: "重构模块", Status: model.StatusInProgress, Priority: model.PriorityLow, DueDate: &nextWeek},
+		{ID: "t4", Title: "下周计划", Status: model.StatusTodo, Priority: model.PriorityHigh, DueDate: &nextWeek},
+		{ID: "t5", Title: "晨会", Status: model.StatusCompleted, CompletedAt: &now},
+	}
+	for i := range seed {
+		seed[i].Source = model.SourceLocal
+		if err := store.SaveTask(ctx, &seed[i]); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+
+	res, err := s.handleSummarizeTasks(ctx, buildCallToolRequest(t, map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("summarize today: %v", err)
+	}
+	stats, ok := res.StructuredContent.(taskSummaryStats)
+	if !ok {
+		t.Fatalf("unexpected structured content: %T", res.StructuredContent)
+	}
+	if stats.DueToday != 1 || stats.Overdue != 1 || stats.InProgress != 1 || stats.CompletedToday != 1 || stats.Total != 4 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if len(stats.TopPriorities) != 3 || stats.TopPriorities[0].ID != "t1" {
+		t.Fatalf("unexpected top priorities: %+v", stats.TopPriorities)
+	}
+	text := summaryText(t, res)
+	for _, want := range []string{"今天到期 1", "逾期 1", "提交报销", "1 个任务已逾期", "高优先级任务今天到期"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("summary missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "下周计划") {
+		t.Fatalf("summary should not include future tasks:\n%s", text)
+	}
+}
+
+func TestHandleSummarizeTasksProject(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+	projectStore, err := project.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new project store: %v", err)
+	}
+	s.projectStore = projectStore
+	if err := projectStore.SaveProject(context.Background(), &project.Project{ID: "proj-1", Name: "官网改版"}); err != nil {
+		t.Fatalf("save project: %v", err)
+	}
+
+	inProject := func() *model.TaskMetadata {
+		return &model.TaskMetadata{CustomFields: map[string]interface{}{"tb_project_id": "proj-1"}}
+	}
+	seed := []model.Task{
+		{ID: "p1", Title: "设计稿", Status: model.StatusCompleted, Metadata: inProject()},
+		{ID: "p2", Title: "前端开发", Status: model.StatusInProgress, Priority: model.PriorityHigh, Metadata: inProject()},
+		{ID: "p3", Title: "上线", Status: model.StatusTodo, Metadata: inProject()},
+		{ID: "x1", Title: "无关任务", Status: model.StatusTodo},
+	}
+	for i := range seed {
+		if err := store.SaveTask(ctx, &seed[i]); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+	if _, err := s.handleAddBlocker(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "p3", "blocker_id": "p2"})); err != nil {
+		t.Fatalf("add blocker: %v", err)
+	}
+
+	res, err := s.handleSummarizeTasks(ctx, buildCallToolRequest(t, map[string]interface{}{"project": "官网改版"}))
+	if err != nil {
+		t.Fatalf("summarize project: %v", err)
+	}
+	text := summaryText(t, res)
+	for _, want := range []string{"项目「官网改版」", "共 3 个任务", "完成率 33%", "1. 🟠 前端开发", "1 个任务被阻塞"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("summary missing %q:\n%s", want, text)
+		}
+	}
+
+	if _, err := s.handleSummarizeTasks(ctx, buildCallToolRequest(t, map[string]interface{}{"scope": "project"})); err == nil {
+		t.Fatalf("expected error when project is missing")
+	}
+}
@@ -0,0 +1,313 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	tbsync "github.com/yeisme/taskbridge/internal/sync"
+)
+
+// syncRunState 记录 sync_now 的运行状态与最近一次结果，供 sync_status 查询
+type syncRunState struct {
+	mu        gosync.Mutex
+	running   bool
+	startedAt time.Time
+	lastRun   map[string]*syncRunRecord
+}
+
+// syncRunRecord 单个 Provider 最近一次 sync_now 的结果
+type syncRunRecord struct {
+	Direction  tbsync.Direction `json:"direction"`
+	DryRun     bool             `json:"dry_run"`
+	FinishedAt time.Time        `json:"finished_at"`
+	Result     *tbsync.Result   `json:"result,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// handleSyncNow 通过同步引擎立即执行同步：source→local 为拉取，local→target 为推送，source 与 target 相同为双向同步；
+// 均省略时对所有已认证 Provider 执行双向同步。
+func (s *Server) handleSyncNow(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	source, err := resolveSyncEndpoint(getString(rawArgs, "source"))
+	if err != nil {
+		return nil, err
+	}
+	target, err := resolveSyncEndpoint(getString(rawArgs, "target"))
+	if err != nil {
+		return nil, err
+	}
+	dryRun, _ := getBool(rawArgs, "dry_run")
+	deleteRemote, _ := getBool(rawArgs, "delete")
+
+	opts := tbsync.Options{DryRun: dryRun, DeleteRemote: deleteRemote}
+	local := string(model.SourceLocal)
+	switch {
+	case source == "" && target == "":
+		opts.Direction = tbsync.DirectionBidirectional
+	case source == local && target == local:
+		return nil, fmt.Errorf("source and target cannot both be local")
+	case target == "" || target == local:
+		opts.Direction = tbsync.DirectionPull
+		opts.Provider = source
+	case source == "" || source == local:
+		opts.Direction = tbsync.DirectionPush
+		opts.Provider = target
+	case source == target:
+		opts.Direction = tbsync.DirectionBidirectional
+		opts.Provider = source
+	default:
+		return nil, fmt.Errorf("cross-provider sync from %s to %s is not supported; pull from %s into local first, then push local tasks to %s", source, target, source, target)
+	}
+	if opts.Provider != "" {
+		if p, ok := s.lookupProvider(ctx, opts.Provider); !ok || p == nil || !p.IsAuthenticated() {
+			return nil, fmt.Errorf("provider %s not found or not authenticated", opts.Provider)
+		}
+	}
+
+	if deleteRemote && !dryRun && opts.Direction != tbsync.DirectionPull && elicitationSession(req) != nil {
+		summary, err := syncDeletionSummary(ctx, tbsync.NewEngine(s.providerSnapshot(ctx), s.taskStore), opts)
+		if err != nil {
+			return nil, err
+		}
+		if summary != "" {
+			action, err := confirmDestructive(ctx, req, summary)
+			if err != nil {
+				return nil, err
+			}
+			if action != "accept" {
+				return unconfirmedResult(action, summary)
+			}
+		}
+	}
+
+	state := &s.syncState
+	state.mu.Lock()
+	if state.running {
+		startedAt := state.startedAt
+		state.mu.Unlock()
+		return nil, fmt.Errorf("sync already running since %s", startedAt.Format(time.RFC3339))
+	}
+	state.running = true
+	state.startedAt = time.Now()
+	state.mu.Unlock()
+
+	engine := tbsync.NewEngine(s.providerSnapshot(ctx), s.taskStore)
+	// 同步引擎直接使用 Provider，写入不经过读取缓存，同步后失效
+	defer s.invalidateAdapterReads(opts.Provider)
+	results := make(map[string]*tbsync.Result)
+	errs := make(map[string]string)
+	if opts.Provider == "" {
+		all, err := engine.SyncAll(ctx, opts)
+		if err != nil {
+			errs["*"] = err.Error()
+		}
+		for name, result := range all {
+			results[name] = result
+		}
+	} else {
+		result, err := engine.Sync(ctx, opts)
+		if result != nil {
+			results[opts.Provider] = result
+		}
+		if err != nil {
+			errs[opts.Provider] = err.Error()
+		}
+	}
+
+	finishedAt := time.Now()
+	state.mu.Lock()
+	state.running = false
+	if state.lastRun == nil {
+		state.lastRun = make(map[string]*syncRunRecord)
+	}
+	// dry_run 不覆盖真实同步记录
+	if !dryRun {
+		for name, result := range results {
+			state.lastRun[name] = &syncRunRecord{Direction: opts.Direction, FinishedAt: finishedAt, Result: result, Error: errs[name]}
+		}
+		for name, message := range errs {
+			if _, ok := results[name]; !ok && name != "*" {
+				state.lastRun[name] = &syncRunRecord{Direction: opts.Direction, FinishedAt: finishedAt, Error: message}
+			}
+		}
+	}
+	state.mu.Unlock()
+
+	response := map[string]interface{}{
+		"direction": opts.Direction,
+		"dry_run":   dryRun,
+		"results":   results,
+	}
+	if opts.Provider != "" {
+		response["provider"] = opts.Provider
+	}
+	if len(errs) > 0 {
+		response["errors"] = errs
+	}
+	jsonResult, err := toJSON(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}},
+		IsError: len(results) == 0 && len(errs) > 0,
+	}, nil
+}
+
+// handleSyncStatus 报告各 Provider 的认证状态、最后同步时间、待推送变更数与最近一次 sync_now 结果，
+// 以及镜像状态存储中各镜像对最近一次镜像的记录。
+func (s *Server) handleSyncStatus(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+
+	names := s.providerNames(ctx)
+	filter := ""
+	if value := getString(rawArgs, "provider"); value != "" {
+		resolved, err := resolveProviderNameStrict(value)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.lookupProvider(ctx, resolved); !ok {
+			return nil, fmt.Errorf("provider %s not found or not authenticated", resolved)
+		}
+		names = []string{resolved}
+		filter = resolved
+	}
+
+	engine := tbsync.NewEngine(s.providerSnapshot(ctx), s.taskStore)
+	state := &s.syncState
+	state.mu.Lock()
+	running, startedAt := state.running, state.startedAt
+	lastRun := make(map[string]*syncRunRecord, len(state.lastRun))
+	for name, record := range state.lastRun {
+		lastRun[name] = record
+	}
+	state.mu.Unlock()
+
+	providers := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		item := map[string]interface{}{"provider": name}
+		status, err := engine.GetStatus(ctx, name)
+		if err != nil {
+			item["error"] = err.Error()
+		} else {
+			item["authenticated"] = status.Authenticated
+			item["pending_changes"] = status.PendingChanges
+			if !status.LastSyncTime.IsZero() {
+				item["last_sync_time"] = status.LastSyncTime
+			}
+		}
+		if record, ok := lastRun[name]; ok {
+			item["last_run"] = record
+		}
+		providers = append(providers, item)
+	}
+
+	response := map[string]interface{}{
+		"running":   running,
+		"providers": providers,
+	}
+	if running {
+		response["started_at"] = startedAt
+	}
+	if s.mirrorStore != nil {
+		reports, err := tbsync.MirrorStatusReport(ctx, s.mirrorStore)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mirror status: %w", err)
+		}
+		pairs := make([]tbsync.MirrorPairReport, 0, len(reports))
+		for _, report := range reports {
+			if filter == "" || report.Left == filter || report.Right == filter {
+				pairs = append(pairs, report)
+			}
+		}
+		response["mirror_pairs"] = pairs
+	}
+	jsonResult, err := toJSON(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
+
+// resolveSyncEndpoint 解析 source/target：空值保持为空，local 原样返回，其余按 Provider 简写解析。
+func resolveSyncEndpoint(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if strings.EqualFold(value, string(model.SourceLocal)) {
+		return string(model.SourceLocal), nil
+	}
+	return resolveProviderNameStrict(value)
+}
+
+// errSyncBusy 等待正在进行的同步结束超时
+var errSyncBusy = errors.New("another sync is still running")
+
+// pullProvider 等待正在进行的同步结束后从 Provider 拉取任务到本地存储，记录运行结果并检查资源变化。
+// ctx 在等待期间结束时不拉取，返回 errSyncBusy。
+func (s *Server) pullProvider(ctx context.Context, name string) error {
+	state := &s.syncState
+	for {
+		state.mu.Lock()
+		if !state.running {
+			state.running = true
+			state.startedAt = time.Now()
+			state.mu.Unlock()
+			break
+		}
+		state.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return errSyncBusy
+		case <-time.After(time.Second):
+		}
+	}
+
+	// 拉取会改变远端视图，缓存的读取结果过时
+	s.invalidateAdapterReads(name)
+	opts := tbsync.Options{Direction: tbsync.DirectionPull, Provider: name}
+	result, err := tbsync.NewEngine(s.providerSnapshot(ctx), s.taskStore).Sync(ctx, opts)
+
+	record := &syncRunRecord{Direction: opts.Direction, FinishedAt: time.Now(), Result: result}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	state.mu.Lock()
+	state.running = false
+	if state.lastRun == nil {
+		state.lastRun = make(map[string]*syncRunRecord)
+	}
+	state.lastRun[name] = record
+	state.mu.Unlock()
+
+	s.CheckResourceChanges(ctx)
+	return err
+}
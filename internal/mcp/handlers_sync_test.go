@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+	tbsync "github.com/yeisme/taskbridge/internal/sync"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+// remoteTasksProvider 在 mockProvider 基础上返回固定的远端任务
+type remoteTasksProvider struct {
+	mockProvider
+	remote []model.Task
+}
+
+func (p *remoteTasksProvider) ListTasks(ctx context.Context, listID string, opts provider.ListOptions) ([]model.Task, error) {
+	return p.remote, nil
+}
+
+func TestHandleSyncNowPullAndStatus(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	remote := &remoteTasksProvider{remote: []model.Task{
+		{ID: "google-@default-r1", Title: "远端任务", Status: model.StatusTodo, Source: model.SourceGoogle, SourceRawID: "r1"},
+	}}
+	s, store, ctx := newIntelligenceTestServer(t, cfg, map[string]provider.Provider{"google": remote})
+
+	res, err := s.handleSyncNow(ctx, buildCallToolRequest(t, map[string]interface{}{"source": "g"}))
+	if err != nil {
+		t.Fatalf("sync now: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	if out["direction"] != "pull" || out["provider"] != "google" {
+		t.Fatalf("unexpected sync result: %v", out)
+	}
+	if _, err := store.GetTask(ctx, "google-@default-r1"); err != nil {
+		t.Fatalf("pulled task should be stored: %v", err)
+	}
+
+	res, err = s.handleSyncStatus(ctx, buildCallToolRequest(t, map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("sync status: %v", err)
+	}
+	status := parseJSONResult(t, res)
+	providers, _ := status["providers"].([]interface{})
+	if len(providers) != 1 || status["running"] != false {
+		t.Fatalf("unexpected status: %v", status)
+	}
+	item := providers[0].(map[string]interface{})
+	lastRun, _ := item["last_run"].(map[string]interface{})
+	if item["provider"] != "google" || item["last_sync_time"] == nil || lastRun["direction"] != "pull" {
+		t.Fatalf("unexpected provider status: %v", item)
+	}
+}
+
+func TestHandleSyncNowPushDryRun(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	mock := &mockProvider{}
+	s, store, ctx := newIntelligenceTestServer(t, cfg, map[string]provider.Provider{"google": mock})
+	if err := store.SaveTask(ctx, &model.Task{ID: "local-1", Title: "本地任务", Status: model.StatusTodo, Source: model.SourceLocal}); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+
+	res, err := s.handleSyncNow(ctx, buildCallToolRequest(t, map[string]interface{}{"source": "local", "target": "google", "dry_run": true}))
+	if err != nil {
+		t.Fatalf("sync now: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	results, _ := out["results"].(map[string]interface{})
+	google, _ := results["google"].(map[string]interface{})
+	if out["direction"] != "push" || google["pushed"].(float64) != 1 {
+		t.Fatalf("unexpected dry run result: %v", out)
+	}
+	if len(mock.created) != 0 {
+		t.Fatalf("dry run should not create remote tasks")
+	}
+
+	// dry_run 不记录到 sync_status
+	res, _ = s.handleSyncStatus(ctx, buildCallToolRequest(t, map[string]interface{}{"provider": "google"}))
+	item := parseJSONResult(t, res)["providers"].([]interface{})[0].(map[string]interface{})
+	if _, ok := item["last_run"]; ok {
+		t.Fatalf("dry run should not be recorded: %v", item)
+	}
+}
+
+func TestHandleSyncStatusReportsMirrorPairs(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, _, ctx := newIntelligenceTestServer(t, cfg, map[string]provider.Provider{"google": &mockProvider{}})
+	mirrorStore, err := tbsync.NewFileMirrorStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("mirror store: %v", err)
+	}
+	s.mirrorStore = mirrorStore
+
+	state := &tbsync.MirrorState{Conflicts: []tbsync.MirrorConflict{{ID: "c1"}}}
+	if err := mirrorStore.SaveMirrorState(ctx, "google", "todoist", state); err != nil {
+		t.Fatalf("seed state: %v", err)
+	}
+	result := &tbsync.MirrorResult{Left: "google", Right: "todoist", ToRight: tbsync.MirrorCounts{Created: 2, Updated: 1}}
+	if err := mirrorStore.SaveMirrorRun(ctx, "google", "todoist", &tbsync.MirrorRun{FinishedAt: time.Now(), Result: result, Runs: 1}); err != nil {
+		t.Fatalf("seed run: %v", err)
+	}
+	if err := mirrorStore.SaveMirrorRun(ctx, "microsoft", "todoist", &tbsync.MirrorRun{FinishedAt: time.Now(), Error: "boom", Runs: 1, Failures: 1}); err != nil {
+		t.Fatalf("seed run: %v", err)
+	}
+
+	res, err := s.handleSyncStatus(ctx, buildCallToolRequest(t, map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("sync status: %v", err)
+	}
+	pairs, _ := parseJSONResult(t, res)["mirror_pairs"].([]interface{})
+	if len(pairs) != 2 {
+		t.Fatalf("expected two mirror pairs, got %v", pairs)
+	}
+
+	res, _ = s.handleSyncStatus(ctx, buildCallToolRequest(t, map[string]interface{}{"provider": "google"}))
+	pairs, _ = parseJSONResult(t, res)["mirror_pairs"].([]interface{})
+	if len(pairs) != 1 {
+		t.Fatalf("provider filter should keep only pairs with google, got %v", pairs)
+	}
+	pair := pairs[0].(map[string]interface{})
+	if pair["left"] != "google" || pair["transferred"].(float64) != 3 || pair["pending_conflicts"].(float64) != 1 || pair["last_run"] == nil {
+		t.Fatalf("unexpected mirror pair status: %v", pair)
+	}
+}
+
+func TestHandleSyncNowRejectsInvalidDirections(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	s, _, ctx := newIntelligenceTestServer(t, cfg, map[string]provider.Provider{"google": &mockProvider{}})
+
+	for _, args := range []map[string]interface{}{
+		{"source": "google", "target": "todoist"},
+		{"source": "local", "target": "local"},
+		{"source": "todoist"},
+	} {
+		if _, err := s.handleSyncNow(ctx, buildCallToolRequest(t, args)); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+// blockingListsProvider 拉取清单时阻塞直到上下文取消，用于验证取消是否传递到 provider 调用
+type blockingListsProvider struct {
+	mockProvider
+	entered   chan struct{}
+	cancelled chan struct{}
+}
+
+func (p *blockingListsProvider) ListTaskLists(ctx context.Context) ([]model.TaskList, error) {
+	close(p.entered)
+	<-ctx.Done()
+	close(p.cancelled)
+	return nil, ctx.Err()
+}
+
+func TestSyncPullHonorsClientCancellation(t *testing.T) {
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	p := &blockingListsProvider{entered: make(chan struct{}), cancelled: make(chan struct{})}
+	s := NewServer(WithTaskStorage(store), WithProviders(map[string]provider.Provider{"google": p}))
+	session := connectBreakdownClient(t, s, nil)
+
+	callCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := session.CallTool(callCtx, &sdkmcp.CallToolParams{Name: "sync_pull", Arguments: map[string]any{"provider": "google"}})
+		done <- err
+	}()
+
+	select {
+	case <-p.entered:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("provider was not called")
+	}
+	// 客户端取消请求时，SDK 发送 notifications/cancelled，服务端处理器的 ctx 随之取消
+	cancel()
+	select {
+	case <-p.cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("provider call was not cancelled")
+	}
+	if err := <-done; err == nil {
+		t.Fatalf("expected cancelled call to return an error")
+	}
+}
@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+type getTaskMockProvider struct {
+	mockProvider
+	remote   map[string]model.Task
+	requests []string
+}
+
+func (m *getTaskMockProvider) GetTask(ctx context.Context, listID, taskID string) (*model.Task, error) {
+	m.requests = append(m.requests, listID+"/"+taskID)
+	task, ok := m.remote[taskID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &task, nil
+}
+
+func TestHandleGetTaskRefreshesFromProvider(t *testing.T) {
+	mock := &getTaskMockProvider{remote: map[string]model.Task{
+		"raw-1": {ID: "google-list-1-raw-1", Title: "Remote title", Status: model.StatusInProgress, Priority: model.PriorityHigh},
+	}}
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, map[string]provider.Provider{"google": mock})
+
+	local := &model.Task{
+		ID:          "google-list-1-raw-1",
+		Title:       "Stale title",
+		Status:      model.StatusTodo,
+		Source:      model.SourceGoogle,
+		SourceRawID: "raw-1",
+		ListID:      "list-1",
+		ListName:    "Inbox",
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := store.SaveTask(ctx, local); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+
+	// 默认返回本地缓存，不访问 provider
+	res, err := s.handleGetTask(ctx, buildCallToolRequest(t, map[string]interface{}{"id": local.ID}))
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if out := parseJSONResult(t, res); out["fetched_from"] != "local" || len(mock.requests) != 0 {
+		t.Fatalf("expected local copy without provider request, got %v (%v)", out["fetched_from"], mock.requests)
+	}
+
+	res, err = s.handleGetTask(ctx, buildCallToolRequest(t, map[string]interface{}{"id": local.ID, "refresh": true}))
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if len(res.Content) != 2 || res.StructuredContent == nil {
+		t.Fatalf("expected json + text content and structured content, got %d items", len(res.Content))
+	}
+	out := parseJSONResult(t, res)
+	if out["fetched_from"] != "remote" {
+		t.Fatalf("expected remote fetch, got %v (%v)", out["fetched_from"], out["remote_error"])
+	}
+	task, _ := out["task"].(map[string]interface{})
+	if task["title"] != "Remote title" || task["list_name"] != "Inbox" {
+		t.Fatalf("unexpected task payload: %v", task)
+	}
+	if len(mock.requests) != 1 || mock.requests[0] != "list-1/raw-1" {
+		t.Fatalf("unexpected provider requests: %v", mock.requests)
+	}
+	text, _ := res.Content[1].(*sdkmcp.TextContent)
+	if text == nil || !strings.Contains(text.Text, "Remote title") || !strings.Contains(text.Text, "google / Inbox") {
+		t.Fatalf("unexpected text content: %v", res.Content[1])
+	}
+
+	// 远端数据不写回本地缓存，避免覆盖未同步的本地修改
+	cached, err := store.GetTask(ctx, local.ID)
+	if err != nil || cached.Title != "Stale title" {
+		t.Fatalf("expected local cache untouched, got %+v (%v)", cached, err)
+	}
+}
+
+func TestHandleGetTaskFallbacks(t *testing.T) {
+	mock := &getTaskMockProvider{remote: map[string]model.Task{
+		"raw-remote-only": {Title: "Only on remote", Status: model.StatusTodo},
+	}}
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, map[string]provider.Provider{"google": mock})
+
+	local := &model.Task{ID: "google-list-1-gone", Title: "Cached", Status: model.StatusTodo, Source: model.SourceGoogle, SourceRawID: "gone", ListID: "list-1"}
+	if err := store.SaveTask(ctx, local); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+
+	// 远端失败时回退到本地缓存
+	res, err := s.handleGetTask(ctx, buildCallToolRequest(t, map[string]interface{}{"id": "gone", "refresh": true}))
+	if err != nil {
+		t.Fatalf("get task by raw id: %v", err)
+	}
+	out := parseJSONResult(t, res)
+	if out["fetched_from"] != "local" || out["remote_error"] == nil {
+		t.Fatalf("expected local fallback with remote error, got %v", out)
+	}
+
+	// 本地不存在时通过 source 参数直接查询 provider
+	res, err = s.handleGetTask(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"id":      "raw-remote-only",
+		"source":  "g",
+		"list_id": "list-2",
+	}))
+	if err != nil {
+		t.Fatalf("get remote-only task: %v", err)
+	}
+	task, _ := parseJSONResult(t, res)["task"].(map[string]interface{})
+	if task["title"] != "Only on remote" || task["source"] != "google" || task["list_id"] != "list-2" {
+		t.Fatalf("unexpected remote-only task: %v", task)
+	}
+
+	if _, err := s.handleGetTask(ctx, buildCallToolRequest(t, map[string]interface{}{"id": "missing"})); err == nil {
+		t.Fatalf("expected error for unknown task")
+	}
+}
+
+func TestProviderFromTaskID(t *testing.T) {
+	if got := providerFromTaskID("google-list-raw"); got != "google" {
+		t.Fatalf("expected google, got %q", got)
+	}
+	if got := providerFromTaskID("task-123"); got != "" {
+		t.Fatalf("expected empty provider, got %q", got)
+	}
+}
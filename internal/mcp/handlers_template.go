@@ -0,0 +1,384 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/tasktemplate"
+)
+
+// handleSaveTemplate 保存任务模板：可直接传入 tasks，或从现有任务（含子任务）抓取结构。
+func (s *Server) handleSaveTemplate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.templateStore == nil {
+		return nil, fmt.Errorf("template storage not available")
+	}
+
+	var params struct {
+		Name        string                      `json:"name"`
+		Description string                      `json:"description"`
+		Tasks       []tasktemplate.TemplateTask `json:"tasks"`
+		TaskIDs     []string                    `json:"task_ids"`
+		Overwrite   bool                        `json:"overwrite"`
+	}
+	if req == nil || req.Params == nil || len(req.Params.Arguments) == 0 {
+		return nil, fmt.Errorf("name is required")
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	tasks := params.Tasks
+	if len(tasks) == 0 && len(params.TaskIDs) > 0 {
+		captured, err := s.captureTemplateTasks(ctx, params.TaskIDs)
+		if err != nil {
+			return nil, err
+		}
+		tasks = captured
+	}
+	tasks, err := normalizeTemplateTasks(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl := &tasktemplate.Template{
+		ID:          generateTemplateID(),
+		Name:        params.Name,
+		Description: strings.TrimSpace(params.Description),
+		Tasks:       tasks,
+	}
+	if existing, err := s.templateStore.GetTemplate(ctx, params.Name); err == nil {
+		if !params.Overwrite {
+			return nil, fmt.Errorf("template already exists: %s (set overwrite=true to replace)", existing.Name)
+		}
+		tpl.ID = existing.ID
+		tpl.CreatedAt = existing.CreatedAt
+	}
+	if err := s.templateStore.SaveTemplate(ctx, tpl); err != nil {
+		return nil, fmt.Errorf("failed to save template: %w", err)
+	}
+
+	result, err := toJSON(tpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: result}}}, nil
+}
+
+// handleListTemplates 列出已保存的任务模板。
+func (s *Server) handleListTemplates(ctx context.Context, _ *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.templateStore == nil {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "[]"}}}, nil
+	}
+	items, err := s.templateStore.ListTemplates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	summaries := make([]map[string]interface{}, 0, len(items))
+	for _, tpl := range items {
+		summaries = append(summaries, map[string]interface{}{
+			"id":          tpl.ID,
+			"name":        tpl.Name,
+			"description": tpl.Description,
+			"task_count":  len(tpl.Tasks),
+			"variables":   templateVariables(tpl),
+			"updated_at":  tpl.UpdatedAt,
+		})
+	}
+	result, err := toJSON(summaries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: result}}}, nil
+}
+
+// handleInstantiateTemplate 将模板展开为本地任务，并可选推送到指定 provider。
+func (s *Server) handleInstantiateTemplate(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.templateStore == nil {
+		return nil, fmt.Errorf("template storage not available")
+	}
+	if s.taskStore == nil {
+		return nil, fmt.Errorf("task storage not available")
+	}
+
+	var params struct {
+		Template  string            `json:"template"`
+		StartDate string            `json:"start_date"`
+		Variables map[string]string `json:"variables"`
+		Provider  string            `json:"provider"`
+		ListID    string            `json:"list_id"`
+		DryRun    bool              `json:"dry_run"`
+	}
+	if req == nil || req.Params == nil || len(req.Params.Arguments) == 0 {
+		return nil, fmt.Errorf("template is required")
+	}
+	if err := json.Unmarshal(req.Params.Arguments, &params); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(params.Template) == "" {
+		return nil, fmt.Errorf("template is required")
+	}
+	tpl, err := s.templateStore.GetTemplate(ctx, params.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedProvider := ""
+	if strings.TrimSpace(params.Provider) != "" {
+		resolvedProvider, err = resolveProviderNameStrict(params.Provider)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.lookupProvider(ctx, resolvedProvider); !ok && !params.DryRun {
+			return nil, fmt.Errorf("provider %s not found or not authenticated", resolvedProvider)
+		}
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if strings.TrimSpace(params.StartDate) != "" {
+		start, err = time.ParseInLocation("2006-01-02", strings.TrimSpace(params.StartDate), now.Location())
+		if err != nil {
+			return nil, fmt.Errorf("invalid start_date: %s", params.StartDate)
+		}
+	}
+
+	instanceID := fmt.Sprintf("tpli_%d", now.UnixNano())
+	keyToLocalID := make(map[string]string, len(tpl.Tasks))
+	tasks := make([]model.Task, 0, len(tpl.Tasks))
+	for idx, item := range tpl.Tasks {
+		task := model.Task{
+			ID:               fmt.Sprintf("%s_%d", generateID(), idx),
+			Title:            tasktemplate.Render(item.Title, params.Variables),
+			Description:      tasktemplate.Render(item.Description, params.Variables),
+			Status:           model.StatusTodo,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+			Source:           model.SourceLocal,
+			ListID:           strings.TrimSpace(params.ListID),
+			Priority:         clampPriority(item.Priority),
+			EstimatedMinutes: item.EstimateMinutes,
+			Tags:             append([]string{}, item.Tags...),
+		}
+		// 未指定 start_date 时，偏移为 0 的任务不设置截止日期。
+		if item.DueOffsetDays > 0 || strings.TrimSpace(params.StartDate) != "" {
+			due := start.AddDate(0, 0, item.DueOffsetDays)
+			task.DueDate = &due
+		}
+		if item.Quadrant > 0 {
+			task.Quadrant = clampQuadrant(item.Quadrant)
+		} else {
+			task.Quadrant = model.CalculateQuadrantFromTask(&task)
+		}
+		task.Metadata = &model.TaskMetadata{
+			Version:    "1.0",
+			Quadrant:   int(task.Quadrant),
+			Priority:   int(task.Priority),
+			LocalID:    task.ID,
+			SyncSource: "local",
+			CustomFields: map[string]interface{}{
+				"tb_template_id":       tpl.ID,
+				"tb_template_instance": instanceID,
+				"tb_plan_task_id":      instanceID + ":" + item.Key,
+			},
+		}
+		if item.ParentKey != "" {
+			parentID := keyToLocalID[item.ParentKey]
+			task.ParentID = &parentID
+			task.Metadata.CustomFields["tb_parent_plan_task_id"] = instanceID + ":" + item.ParentKey
+		}
+		keyToLocalID[item.Key] = task.ID
+		tasks = append(tasks, task)
+	}
+
+	response := map[string]interface{}{
+		"template_id": tpl.ID,
+		"instance_id": instanceID,
+		"count":       len(tasks),
+		"dry_run":     params.DryRun,
+	}
+	if params.DryRun {
+		response["tasks"] = toCompactTasks(tasks)
+		result, _ := toJSON(response)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: result}}}, nil
+	}
+
+	createdIDs := make([]string, 0, len(tasks))
+	for i := range tasks {
+		if err := s.taskStore.SaveTask(ctx, &tasks[i]); err != nil {
+			return nil, fmt.Errorf("failed to save task from template: %w", err)
+		}
+		createdIDs = append(createdIDs, tasks[i].ID)
+	}
+	response["created_task_ids"] = createdIDs
+
+	if resolvedProvider != "" {
+		p, _ := s.lookupProvider(ctx, resolvedProvider)
+		pushResult, err := s.pushTaskTreeToProvider(ctx, p, resolvedProvider, tasks, params.ListID)
+		if err != nil {
+			return nil, err
+		}
+		response["provider"] = resolvedProvider
+		response["pushed"] = pushResult.Pushed
+		response["errors"] = pushResult.Errors
+	}
+
+	result, _ := toJSON(response)
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: result}}}, nil
+}
+
+// captureTemplateTasks 从现有任务及其子任务生成模板结构，截止日期转为相对最早截止日的偏移。
+func (s *Server) captureTemplateTasks(ctx context.Context, taskIDs []string) ([]tasktemplate.TemplateTask, error) {
+	collected := make([]model.Task, 0, len(taskIDs))
+	seen := make(map[string]bool)
+	var visit func(id string) error
+	visit = func(id string) error {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			return nil
+		}
+		task, err := s.taskStore.GetTask(ctx, id)
+		if err != nil {
+			return fmt.Errorf("task not found: %s", id)
+		}
+		seen[id] = true
+		collected = append(collected, *task)
+		for _, childID := range task.SubtaskIDs {
+			if err := visit(childID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, id := range taskIDs {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+
+	var base *time.Time
+	for _, task := range collected {
+		if task.DueDate != nil && (base == nil || task.DueDate.Before(*base)) {
+			base = task.DueDate
+		}
+	}
+	out := make([]tasktemplate.TemplateTask, 0, len(collected))
+	for idx, task := range collected {
+		item := tasktemplate.TemplateTask{
+			Key:             task.ID,
+			Title:           task.Title,
+			Description:     task.Description,
+			EstimateMinutes: task.EstimatedMinutes,
+			Priority:        int(task.Priority),
+			Quadrant:        int(task.Quadrant),
+			Tags:            append([]string{}, task.Tags...),
+		}
+		if task.ParentID != nil && seen[strings.TrimSpace(*task.ParentID)] {
+			item.ParentKey = strings.TrimSpace(*task.ParentID)
+		}
+		if task.DueDate != nil && base != nil {
+			item.DueOffsetDays = int(task.DueDate.Sub(*base).Hours() / 24)
+		}
+		if item.Key == "" {
+			item.Key = strconv.Itoa(idx + 1)
+		}
+		out = append(out, item)
+	}
+	return orderTemplateParentsFirst(out), nil
+}
+
+// orderTemplateParentsFirst 调整顺序使父任务排在子任务之前（task_ids 中子任务可能先于父任务出现），
+// 其余保持原有顺序；父子关系成环时保留环内顺序，交由 normalizeTemplateTasks 报错。
+func orderTemplateParentsFirst(items []tasktemplate.TemplateTask) []tasktemplate.TemplateTask {
+	index := make(map[string]int, len(items))
+	for i, item := range items {
+		index[item.Key] = i
+	}
+	placed := make([]bool, len(items))
+	out := make([]tasktemplate.TemplateTask, 0, len(items))
+	var place func(i int)
+	place = func(i int) {
+		if placed[i] {
+			return
+		}
+		placed[i] = true
+		if parent, ok := index[items[i].ParentKey]; ok && items[i].ParentKey != "" {
+			place(parent)
+		}
+		out = append(out, items[i])
+	}
+	for i := range items {
+		place(i)
+	}
+	return out
+}
+
+// normalizeTemplateTasks 校验模板任务：补齐 Key、确保父任务在子任务之前出现。
+func normalizeTemplateTasks(tasks []tasktemplate.TemplateTask) ([]tasktemplate.TemplateTask, error) {
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("tasks or task_ids is required")
+	}
+	seen := make(map[string]bool, len(tasks))
+	out := make([]tasktemplate.TemplateTask, 0, len(tasks))
+	for idx, item := range tasks {
+		item.Title = strings.TrimSpace(item.Title)
+		if item.Title == "" {
+			return nil, fmt.Errorf("tasks[%d].title is required", idx)
+		}
+		item.Key = strings.TrimSpace(item.Key)
+		if item.Key == "" {
+			item.Key = strconv.Itoa(idx + 1)
+		}
+		if seen[item.Key] {
+			return nil, fmt.Errorf("duplicate template task key: %s", item.Key)
+		}
+		item.ParentKey = strings.TrimSpace(item.ParentKey)
+		if item.ParentKey != "" && !seen[item.ParentKey] {
+			return nil, fmt.Errorf("tasks[%d].parent_key %s must reference an earlier task", idx, item.ParentKey)
+		}
+		seen[item.Key] = true
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// templateVariables 提取模板中出现的 {{变量}} 名称。
+func templateVariables(tpl tasktemplate.Template) []string {
+	seen := make(map[string]bool)
+	vars := make([]string, 0)
+	for _, item := range tpl.Tasks {
+		for _, text := range []string{item.Title, item.Description} {
+			for {
+				start := strings.Index(text, "{{")
+				if start < 0 {
+					break
+				}
+				end := strings.Index(text[start:], "}}")
+				if end < 0 {
+					break
+				}
+				name := strings.TrimSpace(text[start+2 : start+end])
+				if name != "" && !seen[name] {
+					seen[name] = true
+					vars = append(vars, name)
+				}
+				text = text[start+end+2:]
+			}
+		}
+	}
+	return vars
+}
+
+func generateTemplateID() string {
+	return fmt.Sprintf("tpl_%d", time.Now().UnixNano())
+}
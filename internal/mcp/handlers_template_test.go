@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/tasktemplate"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestTemplateSaveAndInstantiateToProvider(t *testing.T) {
+	providerMock := &mockProvider{}
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, map[string]provider.Provider{
+		"google": providerMock,
+	})
+	templateStore, err := tasktemplate.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new template store: %v", err)
+	}
+	s.templateStore = templateStore
+
+	if _, err := s.handleSaveTemplate(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"name": "Release Checklist",
+		"tasks": []map[string]interface{}{
+			{"key": "release", "title": "发布 {{version}}", "due_offset_days": 3},
+			{"key": "changelog", "parent_key": "release", "title": "更新 {{version}} CHANGELOG", "due_offset_days": 1},
+		},
+	})); err != nil {
+		t.Fatalf("save template: %v", err)
+	}
+	if _, err := s.handleSaveTemplate(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"name":  "release checklist",
+		"tasks": []map[string]interface{}{{"title": "x"}},
+	})); err == nil {
+		t.Fatalf("expected duplicate template name to fail without overwrite")
+	}
+
+	res, err := s.handleInstantiateTemplate(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"template":   "release checklist",
+		"start_date": "2026-05-01",
+		"variables":  map[string]string{"version": "v1.2.0"},
+		"provider":   "g",
+	}))
+	if err != nil {
+		t.Fatalf("instantiate template: %v", err)
+	}
+	payload := parseJSONResult(t, res)
+	if int(payload["count"].(float64)) != 2 || int(payload["pushed"].(float64)) != 2 {
+		t.Fatalf("unexpected instantiate payload: %#v", payload)
+	}
+	if len(providerMock.created) != 2 {
+		t.Fatalf("expected 2 remote creates, got %d", len(providerMock.created))
+	}
+	child := providerMock.created[1]
+	if child.Title != "更新 v1.2.0 CHANGELOG" || child.ParentID == nil || *child.ParentID == "" {
+		t.Fatalf("expected child pushed under parent: %#v", child)
+	}
+
+	ids := payload["created_task_ids"].([]interface{})
+	parent, err := store.GetTask(ctx, ids[0].(string))
+	if err != nil {
+		t.Fatalf("get parent: %v", err)
+	}
+	wantDue := time.Date(2026, 5, 4, 0, 0, 0, 0, time.Local)
+	if parent.DueDate == nil || !parent.DueDate.Equal(wantDue) {
+		t.Fatalf("unexpected parent due: %v", parent.DueDate)
+	}
+}
+
+func TestSaveTemplateFromExistingTasks(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+	templateStore, err := tasktemplate.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new template store: %v", err)
+	}
+	s.templateStore = templateStore
+
+	now := time.Now()
+	due := now.AddDate(0, 0, 2)
+	later := due.AddDate(0, 0, 5)
+	parentID := "parent"
+	for _, task := range []*model.Task{
+		{ID: "parent", Title: "搬家", DueDate: &due, SubtaskIDs: []string{"child"}},
+		{ID: "child", Title: "打包", DueDate: &later, ParentID: &parentID},
+	} {
+		task.Status = model.StatusTodo
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+	}
+
+	res, err := s.handleSaveTemplate(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"name":     "move",
+		"task_ids": []string{"parent"},
+	}))
+	if err != nil {
+		t.Fatalf("save template: %v", err)
+	}
+	tasks := parseJSONResult(t, res)["tasks"].([]interface{})
+	if len(tasks) != 2 {
+		t.Fatalf("expected parent and child captured: %#v", tasks)
+	}
+	child := tasks[1].(map[string]interface{})
+	if child["parent_key"] != "parent" || int(child["due_offset_days"].(float64)) != 5 {
+		t.Fatalf("unexpected captured child: %#v", child)
+	}
+}
+
+func TestSaveTemplateCapturesChildBeforeParent(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+	templateStore, err := tasktemplate.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new template store: %v", err)
+	}
+	s.templateStore = templateStore
+
+	now := time.Now()
+	parentID := "parent"
+	for _, task := range []*model.Task{
+		{ID: "parent", Title: "搬家", SubtaskIDs: []string{"child"}},
+		{ID: "child", Title: "打包", ParentID: &parentID},
+	} {
+		task.Status = model.StatusTodo
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+	}
+
+	res, err := s.handleSaveTemplate(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"name":     "move",
+		"task_ids": []string{"child", "parent"},
+	}))
+	if err != nil {
+		t.Fatalf("save template with child listed first: %v", err)
+	}
+	tasks := parseJSONResult(t, res)["tasks"].([]interface{})
+	if len(tasks) != 2 {
+		t.Fatalf("expected parent and child captured: %#v", tasks)
+	}
+	first := tasks[0].(map[string]interface{})
+	second := tasks[1].(map[string]interface{})
+	if first["key"] != "parent" || second["key"] != "child" || second["parent_key"] != "parent" {
+		t.Fatalf("expected parent ordered before child: %#v", tasks)
+	}
+}
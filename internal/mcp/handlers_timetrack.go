@@ -0,0 +1,331 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/timetrack"
+)
+
+// handleStartTimer 为任务启动计时器；默认先停止其他运行中的计时器。
+func (s *Server) handleStartTimer(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil || s.timeStore == nil {
+		return nil, fmt.Errorf("time tracking storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	taskID := strings.TrimSpace(getString(rawArgs, "task_id"))
+	if taskID == "" {
+		return nil, fmt.Errorf("task_id is required")
+	}
+	task, err := s.taskStore.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+
+	now := time.Now()
+	if running, err := s.timeStore.GetRunning(ctx, taskID); err == nil {
+		return nil, fmt.Errorf("timer already running for task %s (entry %s)", taskID, running.ID)
+	}
+	stopped := make([]timetrack.Entry, 0)
+	stopOthers := true
+	if v, ok := getBool(rawArgs, "stop_running"); ok {
+		stopOthers = v
+	}
+	if stopOthers {
+		for {
+			running, err := s.timeStore.GetRunning(ctx, "")
+			if err != nil {
+				break
+			}
+			entry, err := s.finishTimeEntry(ctx, running, now, "")
+			if err != nil {
+				return nil, err
+			}
+			stopped = append(stopped, *entry)
+		}
+	}
+
+	entry := &timetrack.Entry{
+		ID:        generateTimeEntryID(),
+		TaskID:    task.ID,
+		TaskTitle: task.Title,
+		Note:      strings.TrimSpace(getString(rawArgs, "note")),
+		StartedAt: now,
+	}
+	if err := s.timeStore.SaveEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to save time entry: %w", err)
+	}
+
+	return timeTrackResult(map[string]interface{}{
+		"entry":   entry,
+		"stopped": stopped,
+	})
+}
+
+// handleStopTimer 停止运行中的计时器，并把时长累加到任务的 actual_minutes。
+func (s *Server) handleStopTimer(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil || s.timeStore == nil {
+		return nil, fmt.Errorf("time tracking storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	taskID := strings.TrimSpace(getString(rawArgs, "task_id"))
+	running, err := s.timeStore.GetRunning(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := s.finishTimeEntry(ctx, running, time.Now(), strings.TrimSpace(getString(rawArgs, "note")))
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.taskTrackedMinutes(ctx, entry.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	return timeTrackResult(map[string]interface{}{
+		"entry":              entry,
+		"task_total_minutes": total,
+	})
+}
+
+// handleLogTime 手动补录一段时间记录。
+func (s *Server) handleLogTime(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.taskStore == nil || s.timeStore == nil {
+		return nil, fmt.Errorf("time tracking storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	taskID := strings.TrimSpace(getString(rawArgs, "task_id"))
+	if taskID == "" {
+		return nil, fmt.Errorf("task_id is required")
+	}
+
+	minutes, _ := getInt(rawArgs, "minutes")
+	if duration := strings.TrimSpace(getString(rawArgs, "duration")); duration != "" && minutes <= 0 {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration: %s", duration)
+		}
+		minutes = int(d.Minutes())
+	}
+	if minutes <= 0 {
+		return nil, fmt.Errorf("minutes or duration is required")
+	}
+
+	task, err := s.taskStore.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+
+	endedAt := time.Now()
+	if date := strings.TrimSpace(getString(rawArgs, "date")); date != "" {
+		day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date: %s", date)
+		}
+		// 补录到指定日期时，以当天 18:00 作为结束时间，避免跨日。
+		endedAt = day.Add(18 * time.Hour)
+	}
+	entry := &timetrack.Entry{
+		ID:        generateTimeEntryID(),
+		TaskID:    task.ID,
+		TaskTitle: task.Title,
+		Note:      strings.TrimSpace(getString(rawArgs, "note")),
+		StartedAt: endedAt.Add(-time.Duration(minutes) * time.Minute),
+		EndedAt:   &endedAt,
+		Minutes:   minutes,
+		Manual:    true,
+	}
+	if err := s.timeStore.SaveEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to save time entry: %w", err)
+	}
+	if err := s.addActualMinutes(ctx, task, minutes); err != nil {
+		return nil, err
+	}
+	total, err := s.taskTrackedMinutes(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	return timeTrackResult(map[string]interface{}{
+		"entry":              entry,
+		"task_total_minutes": total,
+	})
+}
+
+// timeReportTask 时间报告中单个任务的汇总
+type timeReportTask struct {
+	TaskID    string `json:"task_id"`
+	TaskTitle string `json:"task_title,omitempty"`
+	Minutes   int    `json:"minutes"`
+	Entries   int    `json:"entries"`
+	Running   bool   `json:"running,omitempty"`
+}
+
+// handleTimeReport 只读查询时间记录：按任务和/或开始时间范围筛选，返回记录明细以及按任务、按天的汇总。
+func (s *Server) handleTimeReport(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if s.timeStore == nil {
+		return nil, fmt.Errorf("time tracking storage not available")
+	}
+
+	var rawArgs map[string]json.RawMessage
+	if req != nil && req.Params != nil && len(req.Params.Arguments) > 0 {
+		if err := json.Unmarshal(req.Params.Arguments, &rawArgs); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	now := time.Now()
+	since, err := parseHistoryTime(getString(rawArgs, "since"), now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid since: %w", err)
+	}
+	until, err := parseHistoryTime(getString(rawArgs, "until"), now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid until: %w", err)
+	}
+	taskID := strings.TrimSpace(getString(rawArgs, "task_id"))
+	if taskID != "" {
+		// 远端原始 ID 也按本地 ID 查询
+		if local := s.findLocalTask(ctx, taskID, ""); local != nil {
+			taskID = local.ID
+		}
+	}
+
+	all, err := s.timeStore.ListEntries(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list time entries: %w", err)
+	}
+	entries := make([]timetrack.Entry, 0, len(all))
+	byTask := make(map[string]*timeReportTask)
+	taskOrder := make([]string, 0)
+	byDay := make(map[string]int)
+	total := 0
+	for _, entry := range all {
+		if (!since.IsZero() && entry.StartedAt.Before(since)) || (!until.IsZero() && !entry.StartedAt.Before(until)) {
+			continue
+		}
+		minutes := entry.ElapsedMinutes(now)
+		if entry.Running() {
+			entry.Minutes = minutes
+		}
+		entries = append(entries, entry)
+		total += minutes
+		byDay[entry.StartedAt.In(now.Location()).Format("2006-01-02")] += minutes
+
+		summary, ok := byTask[entry.TaskID]
+		if !ok {
+			summary = &timeReportTask{TaskID: entry.TaskID, TaskTitle: entry.TaskTitle}
+			byTask[entry.TaskID] = summary
+			taskOrder = append(taskOrder, entry.TaskID)
+		}
+		summary.Minutes += minutes
+		summary.Entries++
+		summary.Running = summary.Running || entry.Running()
+		if entry.TaskTitle != "" {
+			summary.TaskTitle = entry.TaskTitle
+		}
+	}
+
+	tasks := make([]timeReportTask, 0, len(taskOrder))
+	for _, id := range taskOrder {
+		tasks = append(tasks, *byTask[id])
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Minutes > tasks[j].Minutes })
+
+	result := map[string]interface{}{
+		"total_minutes": total,
+		"tasks":         tasks,
+		"by_day":        byDay,
+		"entries":       entries,
+	}
+	if taskID != "" {
+		result["task_id"] = taskID
+	}
+	if !since.IsZero() {
+		result["since"] = since
+	}
+	if !until.IsZero() {
+		result["until"] = until
+	}
+	return timeTrackResult(result)
+}
+
+// finishTimeEntry 结束计时记录并同步任务的 actual_minutes。
+func (s *Server) finishTimeEntry(ctx context.Context, entry *timetrack.Entry, now time.Time, note string) (*timetrack.Entry, error) {
+	entry.Minutes = entry.ElapsedMinutes(now)
+	entry.EndedAt = &now
+	if note != "" {
+		entry.Note = note
+	}
+	if err := s.timeStore.SaveEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to save time entry: %w", err)
+	}
+	task, err := s.taskStore.GetTask(ctx, entry.TaskID)
+	if err != nil {
+		// 任务已被删除时仍保留时间记录。
+		return entry, nil
+	}
+	if err := s.addActualMinutes(ctx, task, entry.Minutes); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *Server) addActualMinutes(ctx context.Context, task *model.Task, minutes int) error {
+	if minutes <= 0 {
+		return nil
+	}
+	task.ActualMinutes += minutes
+	task.UpdatedAt = time.Now()
+	if err := s.taskStore.SaveTask(ctx, task); err != nil {
+		return fmt.Errorf("failed to save task: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) taskTrackedMinutes(ctx context.Context, taskID string) (int, error) {
+	entries, err := s.timeStore.ListEntries(ctx, taskID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list time entries: %w", err)
+	}
+	now := time.Now()
+	total := 0
+	for i := range entries {
+		total += entries[i].ElapsedMinutes(now)
+	}
+	return total, nil
+}
+
+func generateTimeEntryID() string {
+	return fmt.Sprintf("te_%d", time.Now().UnixNano())
+}
+
+func timeTrackResult(result map[string]interface{}) (*mcp.CallToolResult, error) {
+	jsonResult, err := toJSON(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: jsonResult}}}, nil
+}
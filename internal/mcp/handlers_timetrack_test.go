@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/timetrack"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestTimeTrackingTools(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+	timeStore, err := timetrack.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new time store: %v", err)
+	}
+	s.timeStore = timeStore
+
+	now := time.Now()
+	for _, id := range []string{"task-a", "task-b"} {
+		if err := store.SaveTask(ctx, &model.Task{ID: id, Title: id, Status: model.StatusTodo, Source: model.SourceLocal, CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+	}
+
+	if _, err := s.handleStartTimer(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "task-a"})); err != nil {
+		t.Fatalf("start timer a: %v", err)
+	}
+	if _, err := s.handleStartTimer(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "task-a"})); err == nil {
+		t.Fatalf("expected error when timer already running")
+	}
+
+	// 把运行中的计时器回拨 25 分钟，模拟已计时一段时间。
+	running, err := timeStore.GetRunning(ctx, "task-a")
+	if err != nil {
+		t.Fatalf("get running: %v", err)
+	}
+	running.StartedAt = now.Add(-25 * time.Minute)
+	if err := timeStore.SaveEntry(ctx, running); err != nil {
+		t.Fatalf("save entry: %v", err)
+	}
+
+	res, err := s.handleStartTimer(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "task-b"}))
+	if err != nil {
+		t.Fatalf("start timer b: %v", err)
+	}
+	payload := parseJSONResult(t, res)
+	if stopped := payload["stopped"].([]interface{}); len(stopped) != 1 {
+		t.Fatalf("expected timer a to be stopped: %#v", payload)
+	}
+	taskA, _ := store.GetTask(ctx, "task-a")
+	if taskA.ActualMinutes != 25 {
+		t.Fatalf("expected 25 actual minutes on task-a, got %d", taskA.ActualMinutes)
+	}
+
+	res, err = s.handleLogTime(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "task-a", "duration": "1h5m", "date": "2026-01-02"}))
+	if err != nil {
+		t.Fatalf("log time: %v", err)
+	}
+	payload = parseJSONResult(t, res)
+	if int(payload["task_total_minutes"].(float64)) != 90 {
+		t.Fatalf("unexpected total minutes: %#v", payload)
+	}
+
+	res, err = s.handleStopTimer(ctx, buildCallToolRequest(t, map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("stop timer: %v", err)
+	}
+	entry := parseJSONResult(t, res)["entry"].(map[string]interface{})
+	if entry["task_id"] != "task-b" || entry["ended_at"] == nil {
+		t.Fatalf("unexpected stopped entry: %#v", entry)
+	}
+	if _, err := s.handleStopTimer(ctx, buildCallToolRequest(t, map[string]interface{}{})); err == nil {
+		t.Fatalf("expected error when no timer running")
+	}
+}
+
+func TestTimeReport(t *testing.T) {
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, nil)
+	timeStore, err := timetrack.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new time store: %v", err)
+	}
+	s.timeStore = timeStore
+
+	now := time.Now()
+	for _, id := range []string{"task-a", "task-b"} {
+		if err := store.SaveTask(ctx, &model.Task{ID: id, Title: id, Status: model.StatusTodo, Source: model.SourceLocal, CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+	}
+	day := func(offset int) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, now.Location()).AddDate(0, 0, offset)
+	}
+	ended := func(start time.Time, minutes int) *time.Time {
+		end := start.Add(time.Duration(minutes) * time.Minute)
+		return &end
+	}
+	for _, entry := range []*timetrack.Entry{
+		{ID: "e1", TaskID: "task-a", TaskTitle: "task-a", StartedAt: day(-10), EndedAt: ended(day(-10), 60), Minutes: 60},
+		{ID: "e2", TaskID: "task-a", TaskTitle: "task-a", StartedAt: day(-2), EndedAt: ended(day(-2), 30), Minutes: 30},
+		{ID: "e3", TaskID: "task-b", TaskTitle: "task-b", StartedAt: day(-1), EndedAt: ended(day(-1), 45), Minutes: 45},
+		// 运行中的计时器按当前时间计算
+		{ID: "e4", TaskID: "task-a", TaskTitle: "task-a", StartedAt: now.Add(-20 * time.Minute)},
+	} {
+		if err := timeStore.SaveEntry(ctx, entry); err != nil {
+			t.Fatalf("save entry: %v", err)
+		}
+	}
+
+	res, err := s.handleTimeReport(ctx, buildCallToolRequest(t, map[string]interface{}{"task_id": "task-a"}))
+	if err != nil {
+		t.Fatalf("time report: %v", err)
+	}
+	payload := parseJSONResult(t, res)
+	if int(payload["total_minutes"].(float64)) != 110 || len(payload["entries"].([]interface{})) != 3 {
+		t.Fatalf("unexpected task report: %#v", payload)
+	}
+	tasks := payload["tasks"].([]interface{})
+	if len(tasks) != 1 || tasks[0].(map[string]interface{})["running"] != true {
+		t.Fatalf("expected a single running task summary: %#v", tasks)
+	}
+
+	res, err = s.handleTimeReport(ctx, buildCallToolRequest(t, map[string]interface{}{"since": "7d"}))
+	if err != nil {
+		t.Fatalf("time report since: %v", err)
+	}
+	payload = parseJSONResult(t, res)
+	if int(payload["total_minutes"].(float64)) != 95 {
+		t.Fatalf("expected only the last 7 days, got %#v", payload)
+	}
+	tasks = payload["tasks"].([]interface{})
+	if len(tasks) != 2 || tasks[0].(map[string]interface{})["task_id"] != "task-a" || int(tasks[1].(map[string]interface{})["minutes"].(float64)) != 45 {
+		t.Fatalf("expected task summaries sorted by minutes: %#v", tasks)
+	}
+	if byDay := payload["by_day"].(map[string]interface{}); int(byDay[day(-1).Format("2006-01-02")].(float64)) != 45 {
+		t.Fatalf("unexpected daily totals: %#v", byDay)
+	}
+
+	res, err = s.handleTimeReport(ctx, buildCallToolRequest(t, map[string]interface{}{"until": day(-5).Format("2006-01-02")}))
+	if err != nil {
+		t.Fatalf("time report until: %v", err)
+	}
+	if total := int(parseJSONResult(t, res)["total_minutes"].(float64)); total != 60 {
+		t.Fatalf("expected only entries before until, got %d", total)
+	}
+
+	// 只读工具不修改任务的 actual_minutes
+	if task, _ := store.GetTask(ctx, "task-a"); task.ActualMinutes != 0 {
+		t.Fatalf("time_report should not modify tasks, got %d actual minutes", task.ActualMinutes)
+	}
+	if _, err := s.handleTimeReport(ctx, buildCallToolRequest(t, map[string]interface{}{"since": "sometime"})); err == nil {
+		t.Fatal("expected error for invalid since")
+	}
+	if !readOnlyTools["time_report"] {
+		t.Fatal("time_report should be available in read-only mode")
+	}
+}
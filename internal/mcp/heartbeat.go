@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sseHeartbeatMiddleware 为 SSE 响应定期写入注释行（": keepalive"），避免代理与负载均衡
+// 因流空闲而断开连接；retry > 0 时在首条事件中附带 retry 字段，提示客户端的重连间隔。
+// 注释行会被 SSE 客户端忽略，不影响 MCP 消息。
+func sseHeartbeatMiddleware(interval, retry time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hw := &heartbeatWriter{ResponseWriter: w, interval: interval, retry: retry, stop: make(chan struct{})}
+		defer hw.close()
+		next.ServeHTTP(hw, r)
+	})
+}
+
+// heartbeatWriter 识别 SSE 响应并在后台发送心跳；SDK 每次 Write 都是完整事件，
+// 心跳与事件写入通过互斥锁串行化，不会插入到事件中间。
+type heartbeatWriter struct {
+	http.ResponseWriter
+	interval time.Duration
+	retry    time.Duration
+
+	mu          sync.Mutex
+	wroteHeader bool
+	stream      bool
+	retrySent   bool
+	closed      bool
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+func (hw *heartbeatWriter) WriteHeader(code int) {
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+	hw.writeHeaderLocked(code)
+}
+
+func (hw *heartbeatWriter) writeHeaderLocked(code int) {
+	if hw.wroteHeader {
+		return
+	}
+	hw.wroteHeader = true
+	mediaType, _, _ := mime.ParseMediaType(hw.Header().Get("Content-Type"))
+	hw.stream = code == http.StatusOK && mediaType == "text/event-stream"
+	if hw.stream {
+		// 关闭 nginx 等反向代理对 SSE 的缓冲
+		hw.Header().Set("X-Accel-Buffering", "no")
+	}
+	hw.ResponseWriter.WriteHeader(code)
+	if hw.stream && hw.interval > 0 {
+		hw.done = make(chan struct{})
+		go hw.loop()
+	}
+}
+
+func (hw *heartbeatWriter) Write(p []byte) (int, error) {
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+	hw.writeHeaderLocked(http.StatusOK)
+	if hw.stream && hw.retry > 0 && !hw.retrySent {
+		hw.retrySent = true
+		if _, err := hw.ResponseWriter.Write([]byte("retry: " + strconv.FormatInt(hw.retry.Milliseconds(), 10) + "\n")); err != nil {
+			return 0, err
+		}
+	}
+	return hw.ResponseWriter.Write(p)
+}
+
+func (hw *heartbeatWriter) Flush() {
+	hw.mu.Lock()
+	defer hw.mu.Unlock()
+	hw.flushLocked()
+}
+
+func (hw *heartbeatWriter) flushLocked() {
+	if flusher, ok := hw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// loop 按间隔写入心跳，写失败（连接已断开）时退出
+func (hw *heartbeatWriter) loop() {
+	defer close(hw.done)
+	ticker := time.NewTicker(hw.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-hw.stop:
+			return
+		case <-ticker.C:
+			hw.mu.Lock()
+			if hw.closed {
+				hw.mu.Unlock()
+				return
+			}
+			_, err := hw.ResponseWriter.Write([]byte(": keepalive\n\n"))
+			if err == nil {
+				hw.flushLocked()
+			}
+			hw.mu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// close 在处理函数返回后停止心跳，之后不再写入 ResponseWriter
+func (hw *heartbeatWriter) close() {
+	hw.mu.Lock()
+	hw.closed = true
+	done := hw.done
+	hw.mu.Unlock()
+	close(hw.stop)
+	if done != nil {
+		<-done
+	}
+}
+
+// Unwrap 供 http.ResponseController 访问底层 ResponseWriter
+func (hw *heartbeatWriter) Unwrap() http.ResponseWriter {
+	return hw.ResponseWriter
+}
@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEHeartbeatMiddleware(t *testing.T) {
+	release := make(chan struct{})
+	handler := sseHeartbeatMiddleware(10*time.Millisecond, 3*time.Second, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/json" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"ok":true}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, "event: endpoint\ndata: /message\n\n")
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/sse")
+	if err != nil {
+		t.Fatalf("get sse: %v", err)
+	}
+	defer resp.Body.Close()
+	defer close(release)
+	if resp.Header.Get("X-Accel-Buffering") != "no" {
+		t.Fatalf("expected proxy buffering disabled, got %v", resp.Header)
+	}
+
+	// 首条事件带 retry 提示，随后持续收到心跳注释
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	heartbeats := 0
+	for heartbeats < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read sse: %v (lines %q)", err, lines)
+		}
+		line = strings.TrimRight(line, "\n")
+		lines = append(lines, line)
+		if line == ": keepalive" {
+			heartbeats++
+		}
+	}
+	if lines[0] != "retry: 3000" || lines[1] != "event: endpoint" || lines[2] != "data: /message" {
+		t.Fatalf("unexpected stream prefix: %q", lines)
+	}
+
+	// 非 SSE 响应原样返回
+	resp2, err := http.Get(srv.URL + "/json")
+	if err != nil {
+		t.Fatalf("get json: %v", err)
+	}
+	body, _ := io.ReadAll(resp2.Body)
+	resp2.Body.Close()
+	if string(body) != `{"ok":true}` || resp2.Header.Get("X-Accel-Buffering") != "" {
+		t.Fatalf("unexpected json response: %q %v", body, resp2.Header)
+	}
+}
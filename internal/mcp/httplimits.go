@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newHTTPServer 创建 sse/streamable 使用的 http.Server，并应用 mcp.http 中的连接超时
+func (s *Server) newHTTPServer(addr string, handler http.Handler) *http.Server {
+	server := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+	if cfg := s.httpConfig; cfg != nil {
+		server.ReadHeaderTimeout = cfg.ReadHeaderTimeout
+		server.ReadTimeout = cfg.ReadTimeout
+		server.WriteTimeout = cfg.WriteTimeout
+		server.IdleTimeout = cfg.IdleTimeout
+	}
+	return server
+}
+
+// bodyLimitMiddleware 限制请求体大小：声明的 Content-Length 超限时直接返回 413，
+// 分块传输的请求体在读取超过上限时报错，避免恶意客户端占满内存。
+func bodyLimitMiddleware(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestTimeoutMiddleware 为网络客户端的每个 MCP 请求设置处理时限；
+// stdio 会话属于本机客户端，不受限制（如耗时较长的 sync_now）。
+func (s *Server) requestTimeoutMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		timeout := s.requestTimeout()
+		if timeout <= 0 || strings.HasPrefix(method, "notifications/") {
+			return next(ctx, method, req)
+		}
+		if session, ok := req.GetSession().(*mcp.ServerSession); ok && session == s.stdioSession.Load() {
+			return next(ctx, method, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return next(ctx, method, req)
+	}
+}
+
+// requestTimeout 返回网络传输下单个请求的处理时限，0 表示不限制
+func (s *Server) requestTimeout() time.Duration {
+	if s.httpConfig == nil || s.httpTransport() == "" {
+		return 0
+	}
+	return s.httpConfig.RequestTimeout
+}
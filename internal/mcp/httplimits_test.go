@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	s := NewServer(WithHTTPConfig(&pkgconfig.HTTPConfig{MaxRequestBytes: 16}))
+	handler, err := s.wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	serve := func(body string, chunked bool) int {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		if chunked {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := serve(`{"ok":true}`, false); code != http.StatusOK {
+		t.Fatalf("small body should pass, got %d", code)
+	}
+	if code := serve(strings.Repeat("x", 32), false); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", code)
+	}
+	if code := serve(strings.Repeat("x", 32), true); code != http.StatusBadRequest {
+		t.Fatalf("expected chunked oversize body rejected, got %d", code)
+	}
+}
+
+func TestNewHTTPServerAppliesTimeouts(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.HTTP
+	srv := NewServer(WithHTTPConfig(&cfg)).newHTTPServer(":0", http.NotFoundHandler())
+	if srv.ReadHeaderTimeout != 10*time.Second || srv.ReadTimeout != 30*time.Second || srv.IdleTimeout != 2*time.Minute || srv.WriteTimeout != 0 {
+		t.Fatalf("unexpected server timeouts: %+v", srv)
+	}
+}
+
+func TestRequestTimeoutForNetworkSessions(t *testing.T) {
+	for _, tc := range []struct {
+		transport    string
+		wantDeadline bool
+	}{
+		{"streamable", true},
+		{"stdio", false},
+	} {
+		s := NewServer(
+			WithConfig(&ServerConfig{Name: "taskbridge", Transport: tc.transport}),
+			WithHTTPConfig(&pkgconfig.HTTPConfig{RequestTimeout: time.Minute}),
+		)
+		s.server.AddTool(&sdkmcp.Tool{Name: "deadline_probe", InputSchema: json.RawMessage(`{"type":"object"}`)},
+			func(ctx context.Context, _ *sdkmcp.CallToolRequest) (*sdkmcp.CallToolResult, error) {
+				_, ok := ctx.Deadline()
+				return &sdkmcp.CallToolResult{StructuredContent: map[string]any{"deadline": ok}}, nil
+			})
+		res, err := connectBreakdownClient(t, s, nil).CallTool(context.Background(), &sdkmcp.CallToolParams{Name: "deadline_probe"})
+		if err != nil {
+			t.Fatalf("call deadline_probe: %v", err)
+		}
+		if got := res.StructuredContent.(map[string]any)["deadline"]; got != tc.wantDeadline {
+			t.Fatalf("%s: expected deadline=%v, got %v", tc.transport, tc.wantDeadline, got)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// instructionGroupTitles initialize 说明中工具分组的显示顺序与名称
+var instructionGroupTitles = []struct {
+	group string
+	title string
+}{
+	{"task_management", "任务管理"},
+	{"analysis", "分析"},
+	{"intelligence", "智能治理"},
+	{"project_management", "项目规划"},
+	{"time_tracking", "时间记录"},
+	{"templates", "任务模板"},
+	{"import_export", "导入导出"},
+	{"sync", "同步"},
+	{"provider", "Provider"},
+	{"session", "会话上下文"},
+}
+
+// resourcesEnabled 是否启用资源、资源模板与订阅
+func (s *Server) resourcesEnabled() bool {
+	return s.capabilityConfig == nil || s.capabilityConfig.Resources
+}
+
+// promptsEnabled 是否启用提示词
+func (s *Server) promptsEnabled() bool {
+	return s.capabilityConfig == nil || s.capabilityConfig.Prompts
+}
+
+// loggingEnabled 是否启用 notifications/message 日志推送
+func (s *Server) loggingEnabled() bool {
+	return s.capabilityConfig == nil || s.capabilityConfig.Logging
+}
+
+// instructionsMiddleware 在 initialize 时为会话生成 instructions：多租户会话使用所属租户的 Provider，
+// 热加载替换 Provider 或调整工具策略后新建的会话看到的是当时的状态。
+func (s *Server) instructionsMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+		if method != "initialize" || err != nil {
+			return result, err
+		}
+		if init, ok := result.(*mcp.InitializeResult); ok && init != nil {
+			init.Instructions = s.buildInstructions(ctx)
+		}
+		return result, nil
+	}
+}
+
+// buildInstructions 根据请求可用的 Provider、工具策略与能力开关生成 initialize 返回的 instructions。
+func (s *Server) buildInstructions(ctx context.Context) string {
+	var b strings.Builder
+	b.WriteString("TaskBridge 将多个待办平台的任务汇总到本地缓存，读类工具基于本地缓存，写类工具可选择同时写入远端平台。\n")
+
+	providers := s.providerSnapshot(ctx)
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		b.WriteString("\n当前未配置任何 Provider：任务仅保存在本地，同步类工具不可用。\n")
+	} else {
+		parts := make([]string, 0, len(names))
+		for _, name := range names {
+			state := "已认证"
+			if p := providers[name]; p == nil || !p.IsAuthenticated() {
+				state = "未认证"
+			}
+			parts = append(parts, fmt.Sprintf("%s（%s）", name, state))
+		}
+		fmt.Fprintf(&b, "\n已配置的 Provider：%s。工具中的 provider/adapter 参数取这些名称，local 表示仅本地。\n", strings.Join(parts, "、"))
+	}
+
+	b.WriteString("\n可用操作：\n")
+	s.toolMu.Lock()
+	readOnly := s.toolPolicy != nil && s.toolPolicy.ReadOnly
+	for _, item := range instructionGroupTitles {
+		available := make([]string, 0)
+		for _, name := range toolCapabilityGroups[item.group] {
+			if s.toolAvailable(name) {
+				available = append(available, name)
+			}
+		}
+		if len(available) > 0 {
+			fmt.Fprintf(&b, "- %s：%s\n", item.title, strings.Join(available, ", "))
+		}
+	}
+	s.toolMu.Unlock()
+	if readOnly {
+		b.WriteString("\n服务处于只读模式，所有写类工具均已禁用。\n")
+	}
+
+	b.WriteString("\n使用建议：先用 list_tasks（detail=compact、cursor 分页）了解现状，再调用写类工具；删除与带 delete 的同步会在客户端支持时请求用户确认。")
+	if s.resourcesEnabled() {
+		b.WriteString("可读取或订阅 taskbridge://tasks、taskbridge://projects 以及 task://{adapter}/{task_id} 资源。")
+	}
+	if s.promptsEnabled() {
+		b.WriteString("weekly_review、triage_backlog、standup_summary、resolve_conflict 等提示词可直接用于周回顾、清理积压、站会与同步冲突处理。")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yeisme/taskbridge/internal/provider"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestInitializeInstructionsDescribeAdapters(t *testing.T) {
+	s := NewServer(WithProviders(map[string]provider.Provider{"google": &mockProvider{}}))
+	session := connectBreakdownClient(t, s, nil)
+
+	init := session.InitializeResult()
+	for _, want := range []string{"google（已认证）", "sync_now", "quick_add", "taskbridge://tasks", "weekly_review"} {
+		if !strings.Contains(init.Instructions, want) {
+			t.Fatalf("expected instructions to contain %q, got:\n%s", want, init.Instructions)
+		}
+	}
+	caps := init.Capabilities
+	if caps.Logging == nil || caps.Prompts == nil || caps.Resources == nil || !caps.Resources.Subscribe || caps.Completions == nil {
+		t.Fatalf("expected all capabilities advertised, got %+v", caps)
+	}
+
+	local := NewServer(WithToolPolicy(&pkgconfig.ToolGovernanceConfig{ReadOnly: true}))
+	instructions := connectBreakdownClient(t, local, nil).InitializeResult().Instructions
+	if !strings.Contains(instructions, "未配置任何 Provider") || !strings.Contains(instructions, "只读模式") || strings.Contains(instructions, "delete_task,") {
+		t.Fatalf("unexpected read-only instructions:\n%s", instructions)
+	}
+}
+
+func TestCapabilityConfigDisablesFeatures(t *testing.T) {
+	s := NewServer(WithCapabilityConfig(&pkgconfig.CapabilityConfig{Prompts: true}))
+	session := connectBreakdownClient(t, s, nil)
+
+	caps := session.InitializeResult().Capabilities
+	if caps.Logging != nil || caps.Resources != nil {
+		t.Fatalf("expected logging and resources disabled, got %+v", caps)
+	}
+	if caps.Prompts == nil || caps.Tools == nil {
+		t.Fatalf("expected prompts and tools advertised, got %+v", caps)
+	}
+	if strings.Contains(session.InitializeResult().Instructions, "taskbridge://tasks") {
+		t.Fatalf("instructions should not mention disabled resources")
+	}
+}
+
+func TestInstructionsFollowProviderChanges(t *testing.T) {
+	s := NewServer(WithProviders(map[string]provider.Provider{"google": &mockProvider{}}))
+	if got := connectBreakdownClient(t, s, nil).InitializeResult().Instructions; !strings.Contains(got, "google（已认证）") {
+		t.Fatalf("expected google in instructions, got:\n%s", got)
+	}
+
+	// 热加载替换 Provider 后，新会话的说明反映新的集合
+	s.ApplyProviders(map[string]provider.Provider{"todoist": &mockProvider{}})
+	got := connectBreakdownClient(t, s, nil).InitializeResult().Instructions
+	if !strings.Contains(got, "todoist（已认证）") || strings.Contains(got, "google") {
+		t.Fatalf("expected instructions to follow hot reload, got:\n%s", got)
+	}
+
+	// 租户会话只看到所属租户的 Provider
+	ctx := context.WithValue(context.Background(), tenantProvidersKey{}, map[string]provider.Provider{"feishu": &mockProvider{}})
+	if got := s.buildInstructions(ctx); !strings.Contains(got, "feishu") || strings.Contains(got, "todoist") {
+		t.Fatalf("expected tenant providers only, got:\n%s", got)
+	}
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// clientLogTimeout 单条日志推送的超时，避免阻塞写日志的调用方
+const clientLogTimeout = 2 * time.Second
+
+// clientLogWriter 将 zerolog 的 JSON 日志转发为 notifications/message。
+// 每个会话按客户端通过 logging/setLevel 设置的级别过滤，未设置级别的会话不会收到日志。
+type clientLogWriter struct {
+	server *Server
+}
+
+// LogWriter 返回可接入全局 logger 的输出，用于把服务端结构化日志推送给已连接的客户端。
+// 推送内容同样受全局日志级别限制。
+func (s *Server) LogWriter() io.Writer {
+	return clientLogWriter{server: s}
+}
+
+// Write 实现 io.Writer，从日志内容中解析级别
+func (w clientLogWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel 实现 zerolog.LevelWriter
+func (w clientLogWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if w.server == nil || w.server.server == nil || !w.server.loggingEnabled() {
+		return len(p), nil
+	}
+	params := clientLogParams(level, p)
+	if params == nil {
+		return len(p), nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), clientLogTimeout)
+	defer cancel()
+	for session := range w.server.server.Sessions() {
+		// 推送失败不能再写日志，否则会递归回到本 writer
+		_ = session.Log(ctx, params)
+	}
+	return len(p), nil
+}
+
+// clientLogParams 将一行 zerolog JSON 日志转换为 MCP 日志消息；非 JSON 内容按字符串发送。
+func clientLogParams(level zerolog.Level, p []byte) *mcp.LoggingMessageParams {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(p, &fields); err != nil {
+		text := string(p)
+		if text == "" {
+			return nil
+		}
+		return &mcp.LoggingMessageParams{Logger: "taskbridge", Level: mcpLogLevel(level), Data: text}
+	}
+	if level == zerolog.NoLevel {
+		if raw, ok := fields[zerolog.LevelFieldName].(string); ok {
+			if parsed, err := zerolog.ParseLevel(raw); err == nil {
+				level = parsed
+			}
+		}
+	}
+	// 级别已体现在 Level 字段中
+	delete(fields, zerolog.LevelFieldName)
+
+	logger := "taskbridge"
+	if component, ok := fields["component"].(string); ok && component != "" {
+		logger = component
+	}
+	return &mcp.LoggingMessageParams{Logger: logger, Level: mcpLogLevel(level), Data: fields}
+}
+
+// mcpLogLevel 将 zerolog 级别映射为 MCP（RFC 5424）日志级别
+func mcpLogLevel(level zerolog.Level) mcp.LoggingLevel {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return "debug"
+	case zerolog.WarnLevel:
+		return "warning"
+	case zerolog.ErrorLevel:
+		return "error"
+	case zerolog.FatalLevel:
+		return "critical"
+	case zerolog.PanicLevel:
+		return "emergency"
+	default:
+		return "info"
+	}
+}
+
+// logToolCall 包装工具处理函数，记录调用耗时与失败原因，便于客户端通过日志通知排查问题。
+func logToolCall(name string, handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := handler(ctx, req)
+		elapsed := time.Since(start)
+		switch {
+		case err != nil:
+			log.Warn().Str("component", "mcp").Str("tool", name).Dur("elapsed", elapsed).Err(err).Msg("tool call failed")
+		case result != nil && result.IsError:
+			log.Warn().Str("component", "mcp").Str("tool", name).Dur("elapsed", elapsed).Msg("tool call returned error result")
+		default:
+			log.Debug().Str("component", "mcp").Str("tool", name).Dur("elapsed", elapsed).Msg("tool call completed")
+		}
+		return result, err
+	}
+}
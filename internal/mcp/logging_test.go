@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+)
+
+func TestLogWriterStreamsAtClientLevel(t *testing.T) {
+	messages := make(chan *sdkmcp.LoggingMessageParams, 8)
+	s := NewServer()
+	session := connectBreakdownClient(t, s, &sdkmcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *sdkmcp.LoggingMessageRequest) {
+			messages <- req.Params
+		},
+	})
+	ctx := context.Background()
+	logger := zerolog.New(s.LogWriter())
+
+	// 客户端未设置级别前不推送
+	logger.Error().Msg("before set level")
+	if err := session.SetLoggingLevel(ctx, &sdkmcp.SetLoggingLevelParams{Level: "warning"}); err != nil {
+		t.Fatalf("set logging level: %v", err)
+	}
+	logger.Info().Msg("filtered info")
+	logger.Warn().Str("component", "sync").Str("provider", "google").Msg("pull slow")
+
+	select {
+	case msg := <-messages:
+		if msg.Level != "warning" || msg.Logger != "sync" {
+			t.Fatalf("unexpected log message: %+v", msg)
+		}
+		data, ok := msg.Data.(map[string]interface{})
+		if !ok || data["message"] != "pull slow" || data["provider"] != "google" {
+			t.Fatalf("unexpected log data: %#v", msg.Data)
+		}
+		if _, exists := data["level"]; exists {
+			t.Fatalf("expected level field stripped from data: %#v", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for log message")
+	}
+	select {
+	case msg := <-messages:
+		t.Fatalf("unexpected extra log message: %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestClientLogParamsMapsLevels(t *testing.T) {
+	params := clientLogParams(zerolog.NoLevel, []byte(`{"level":"error","message":"boom"}`))
+	if params.Level != "error" || params.Logger != "taskbridge" {
+		t.Fatalf("unexpected params: %+v", params)
+	}
+	params = clientLogParams(zerolog.DebugLevel, []byte("plain text"))
+	if params.Level != "debug" || params.Data != "plain text" {
+		t.Fatalf("unexpected plain params: %+v", params)
+	}
+}
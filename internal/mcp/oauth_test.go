@@ -0,0 +1,236 @@
+package mcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+const (
+	testIssuer   = "https://auth.example.com"
+	testResource = "https://mcp.example.com/mcp"
+)
+
+// testAuthServer 提供 JWKS 与授权服务器元数据，并签发 RS256 测试令牌
+type testAuthServer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+}
+
+func newTestAuthServer(t *testing.T) *testAuthServer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	as := &testAuthServer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": as.URL, "jwks_uri": as.URL + "/jwks"})
+	})
+	as.Server = httptest.NewServer(mux)
+	t.Cleanup(as.Close)
+	return as
+}
+
+func (as *testAuthServer) sign(t *testing.T, kid string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, as.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims(scope string) map[string]any {
+	return map[string]any{
+		"iss": testIssuer, "sub": "user-1", "aud": []string{testResource},
+		"exp": time.Now().Add(time.Hour).Unix(), "scope": scope,
+	}
+}
+
+func TestJWTVerifier(t *testing.T) {
+	as := newTestAuthServer(t)
+	verifier := newJWTVerifier(pkgconfig.OAuthConfig{Issuer: testIssuer, JWKSURL: as.URL + "/jwks", Resource: testResource})
+	ctx := context.Background()
+
+	info, err := verifier.verify(ctx, as.sign(t, "k1", validClaims("tasks:read tasks:write")), nil)
+	if err != nil {
+		t.Fatalf("verify valid token: %v", err)
+	}
+	if info.UserID != "user-1" || len(info.Scopes) != 2 || info.Scopes[1] != "tasks:write" {
+		t.Fatalf("unexpected token info: %+v", info)
+	}
+
+	invalid := map[string]string{}
+	claims := validClaims("")
+	claims["iss"] = "https://evil.example.com"
+	invalid["issuer"] = as.sign(t, "k1", claims)
+	claims = validClaims("")
+	claims["aud"] = "other"
+	invalid["audience"] = as.sign(t, "k1", claims)
+	claims = validClaims("")
+	claims["exp"] = time.Now().Add(-time.Hour).Unix()
+	invalid["expired"] = as.sign(t, "k1", claims)
+	claims = validClaims("")
+	delete(claims, "exp")
+	invalid["no exp"] = as.sign(t, "k1", claims)
+	invalid["unknown kid"] = as.sign(t, "k2", validClaims(""))
+	token := as.sign(t, "k1", validClaims("tasks:read"))
+	invalid["tampered"] = token[:strings.LastIndex(token, ".")-2] + "xx" + token[strings.LastIndex(token, "."):]
+	invalid["malformed"] = "not-a-jwt"
+	for name, token := range invalid {
+		if _, err := verifier.verify(ctx, token, nil); err == nil {
+			t.Fatalf("expected %s token rejected", name)
+		}
+	}
+
+	// 显式配置 audience 时以其为准，签发给 resource 的 token 不再被接受
+	explicit := newJWTVerifier(pkgconfig.OAuthConfig{Issuer: testIssuer, JWKSURL: as.URL + "/jwks", Resource: testResource, Audience: "taskbridge"})
+	if _, err := explicit.verify(ctx, as.sign(t, "k1", validClaims("")), nil); err == nil {
+		t.Fatal("expected token for another audience rejected")
+	}
+	claims = validClaims("")
+	claims["aud"] = "taskbridge"
+	if _, err := explicit.verify(ctx, as.sign(t, "k1", claims), nil); err != nil {
+		t.Fatalf("verify token with configured audience: %v", err)
+	}
+	// audience 与 resource 都为空时拒绝所有 token
+	unbound := newJWTVerifier(pkgconfig.OAuthConfig{Issuer: testIssuer, JWKSURL: as.URL + "/jwks"})
+	if _, err := unbound.verify(ctx, as.sign(t, "k1", validClaims("")), nil); err == nil {
+		t.Fatal("expected token rejected without an expected audience")
+	}
+
+	// 未配置 jwks_url 时通过授权服务器元数据发现
+	claims = validClaims("")
+	claims["iss"] = as.URL
+	discovering := newJWTVerifier(pkgconfig.OAuthConfig{Issuer: as.URL, Resource: testResource})
+	if _, err := discovering.verify(ctx, as.sign(t, "k1", claims), nil); err != nil {
+		t.Fatalf("verify with discovered jwks: %v", err)
+	}
+}
+
+// bearerTransport 为请求附加 Authorization 头
+type bearerTransport struct {
+	token string
+}
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestOAuthStreamableHTTP(t *testing.T) {
+	as := newTestAuthServer(t)
+	s := NewServer(
+		WithConfig(&ServerConfig{Name: "taskbridge", Transport: "streamable"}),
+		WithSecurityConfig(&pkgconfig.SecurityConfig{Enabled: true, AuthMode: "oauth", OAuth: pkgconfig.OAuthConfig{
+			Issuer: testIssuer, JWKSURL: as.URL + "/jwks", Resource: testResource,
+			ScopeTools: map[string][]string{"tasks:read": {"list_tasks", "get_task"}, "admin": {"*"}},
+		}}),
+	)
+	handler, err := s.wrapHTTPHandler(sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server { return s.server }, nil))
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	// 受保护资源元数据无需认证
+	resp, err := http.Get(srv.URL + "/.well-known/oauth-protected-resource/mcp")
+	if err != nil {
+		t.Fatalf("get metadata: %v", err)
+	}
+	var metadata map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&metadata)
+	resp.Body.Close()
+	if metadata["resource"] != testResource || metadata["authorization_servers"].([]any)[0] != testIssuer {
+		t.Fatalf("unexpected metadata: %v", metadata)
+	}
+
+	// 未携带 token 返回 401 并指向元数据
+	resp, err = http.Post(srv.URL+"/mcp", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("post without token: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized ||
+		!strings.Contains(resp.Header.Get("WWW-Authenticate"), "https://mcp.example.com/.well-known/oauth-protected-resource/mcp") {
+		t.Fatalf("expected 401 with resource metadata, got %d %q", resp.StatusCode, resp.Header.Get("WWW-Authenticate"))
+	}
+
+	connect := func(scope string) *sdkmcp.ClientSession {
+		client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "oauth-client", Version: "0.0.1"}, nil)
+		session, err := client.Connect(context.Background(), &sdkmcp.StreamableClientTransport{
+			Endpoint:   srv.URL + "/mcp",
+			HTTPClient: &http.Client{Transport: bearerTransport{token: as.sign(t, "k1", validClaims(scope))}},
+		}, nil)
+		if err != nil {
+			t.Fatalf("connect with scope %q: %v", scope, err)
+		}
+		t.Cleanup(func() { _ = session.Close() })
+		return session
+	}
+
+	// tasks:read 只能看到并调用映射的工具
+	session := connect("tasks:read")
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	for _, tool := range tools.Tools {
+		if tool.Name != "list_tasks" && tool.Name != "get_task" {
+			t.Fatalf("tool %s should be hidden for tasks:read", tool.Name)
+		}
+	}
+	if _, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{Name: "create_task", Arguments: map[string]any{"title": "x"}}); err == nil {
+		t.Fatalf("expected create_task rejected for tasks:read")
+	}
+
+	// admin 映射到全部工具
+	all, err := connect("admin").ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools as admin: %v", err)
+	}
+	if len(all.Tools) <= len(tools.Tools) {
+		t.Fatalf("expected admin to see more tools: %d vs %d", len(all.Tools), len(tools.Tools))
+	}
+}
+
+func TestOAuthRequiresStreamable(t *testing.T) {
+	s := NewServer(
+		WithConfig(&ServerConfig{Name: "taskbridge", Transport: "sse"}),
+		WithSecurityConfig(&pkgconfig.SecurityConfig{Enabled: true, AuthMode: "oauth", OAuth: pkgconfig.OAuthConfig{
+			Issuer: testIssuer, Resource: testResource,
+		}}),
+	)
+	if _, err := s.wrapHTTPHandler(http.NotFoundHandler()); err == nil {
+		t.Fatalf("expected oauth rejected for sse transport")
+	}
+}
@@ -7,6 +7,10 @@ var EmbeddedPrompts = map[string]string{
 	"project_planning":    ProjectPlanningPrompt,
 	"ai_split_guide":      AISplitGuidePrompt,
 	"json_query_commands": JSONQueryCommandsPrompt,
+	"weekly_review":       WeeklyReviewPrompt,
+	"triage_backlog":      TriageBacklogPrompt,
+	"standup_summary":     StandupSummaryPrompt,
+	"resolve_conflict":    ResolveConflictPrompt,
 }
 
 // QuadrantAnalysisPrompt 四象限分析提示词
@@ -345,3 +349,116 @@ Bash:
 grep -n '"source":"microsoft"' data/tasks.json
 ` + "```" + `
 `
+
+// WeeklyReviewPrompt 每周回顾提示词（GTD 风格）
+const WeeklyReviewPrompt = `# 每周回顾提示词
+
+请基于上方的「上周回顾数据」，按 GTD 每周回顾流程与我逐步完成回顾。每一步先给出你的观察，再向我提出问题并等待回答。
+
+## 回顾流程
+
+### 1. 清空（Get Clear）
+- 上周是否有尚未记录到任务系统的承诺、想法或待办？
+- 收件箱/未分类任务是否需要补充项目、截止日期或优先级？
+
+### 2. 回顾已完成（Celebrate）
+- 总结上周完成的任务，归纳 2-3 个主要成果
+- 哪些完成的任务对长期目标贡献最大？
+
+### 3. 检查延误（Slipped）
+- 逐项分析延误任务：原因是低估工作量、依赖阻塞，还是优先级变化？
+- 对每个延误任务给出建议：重新排期 / 拆分 / 委托 / 删除
+
+### 4. 展望下周（Get Current）
+- 下周最重要的 3 件事是什么？
+- 是否有需要提前准备的截止日期或会议？
+
+### 5. 创造性思考（Get Creative）
+- 有没有应该开始但一直搁置的事情（将来/也许清单）？
+
+## MCP 工具调用建议
+
+- 重新排期延误任务：` + "`snooze_task`" + ` 或 ` + "`update_task`" + `（设置新的 due_date）
+- 拆分过大的任务：` + "`breakdown_task`" + `
+- 删除不再需要的任务：` + "`delete_task`" + `
+- 安排下周重点：` + "`plan_day`" + `、` + "`update_task`" + `（调整 priority/quadrant）
+
+在执行任何修改前，先列出计划调用的工具与参数并征得我的确认。
+`
+
+// TriageBacklogPrompt 积压任务分诊提示词
+const TriageBacklogPrompt = `# 积压任务分诊提示词
+
+上方列出了所选范围内最久未更新的任务。请逐项给出分诊决定，并附上执行该决定的 MCP 工具调用。
+
+## 决定类型
+
+| 决定 | 含义 | 工具调用 |
+|------|------|----------|
+| keep 保留 | 仍然重要，尽快推进 | ` + "`update_task`" + `（status 设为 in_progress 或修改标题使其更可执行） |
+| delegate 委托 | 应由他人完成 | ` + "`update_task`" + `（标题前加 "[委托: 对象]"，status 设为 in_progress）并提醒我沟通 |
+| delete 删除 | 已失去价值或不再相关 | ` + "`delete_task`" + ` |
+| defer 延后 | 有价值但不是现在 | ` + "`snooze_task`" + `（duration/until）或 ` + "`update_task`" + `（status 设为 deferred） |
+
+## 判断要点
+- 长期未更新且无截止日期的任务，优先考虑 delete 或 defer
+- 标题含糊的任务，keep 时应改写为以动词开头的下一步行动
+- 体量过大的任务，建议先用 ` + "`breakdown_task`" + ` 拆分
+
+## 输出格式
+
+先输出一张表（任务 ID、标题、决定、理由），再按决定分组列出工具调用，例如：
+
+` + "```json" + `
+{
+  "tool": "snooze_task",
+  "arguments": {"task_id": "<任务 ID>", "until": "next_month"}
+}
+` + "```" + `
+
+在我确认之前不要执行任何删除操作。
+`
+
+// StandupSummaryPrompt 站会摘要提示词
+const StandupSummaryPrompt = `# 站会摘要提示词
+
+请根据上方的「站会数据」生成一份简洁的每日站会更新，严格使用以下三段结构：
+
+## 输出格式
+
+**昨天（Yesterday）**
+- 用 1 句话概括每个已完成任务的成果，合并同类项，最多 5 条
+
+**今天（Today）**
+- 列出今天计划推进的任务，按优先级排序，最多 5 条
+- 逾期任务需标注“逾期”
+
+**阻塞（Blockers）**
+- 列出被阻塞的任务及阻塞原因；没有则写“无”
+- 如需他人协助，明确写出需要谁做什么
+
+## 要求
+- 面向团队同事，语气简洁、客观，不超过 150 字
+- 不要编造数据中没有的任务
+- 如果“今天”为空，建议调用 ` + "`ready_tasks`" + ` 或 ` + "`plan_day`" + ` 选择今日任务
+`
+
+// ResolveConflictPrompt 冲突解决提示词
+const ResolveConflictPrompt = `# 冲突解决提示词
+
+上方表格并排列出了同一任务的本地缓存版本与远端最新版本。请帮助用户决定每个存在差异的字段保留哪一侧。
+
+## 步骤
+
+1. 逐个说明「⚠️ 不同」的字段：两侧取值分别是什么、哪一侧更可能是用户最近的真实意图（参考更新时间与内容完整度）
+2. 给出建议的选择，并询问用户确认或调整；用户可以整体保留一侧，也可以逐字段混合
+3. 用户确认后调用 ` + "`resolve_conflict`" + ` 工具应用选择：
+   - ` + "`task_id`" + `: 任务 ID
+   - ` + "`keep`" + `: 未单独指定的字段默认保留的一侧（local 或 remote）
+   - ` + "`fields`" + `: 逐字段的选择，如 ` + "`{\"title\": \"remote\", \"due_date\": \"local\"}`" + `
+   - 不确定时可先传 ` + "`dry_run: true`" + ` 预览合并结果
+
+## 要求
+- 不要编造表格中没有的取值
+- 如果两侧版本一致，直接告知用户无需处理
+`
@@ -0,0 +1,343 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+// proxyToolSeparator 命名空间与上游工具名之间的分隔符
+const proxyToolSeparator = "__"
+
+// upstreamState 聚合代理模式下已连接的上游 MCP 服务
+type upstreamState struct {
+	mu    sync.Mutex
+	conns map[string]*upstreamConn
+}
+
+// upstreamConn 单个上游服务的连接与其被代理的工具
+type upstreamConn struct {
+	name      string
+	namespace string
+	session   *mcp.ClientSession
+	// tools 代理工具名 -> 上游工具名
+	tools map[string]string
+	// federated 上游为 taskbridge-mcp 实例；adapters 为其已配置的 Provider
+	federated bool
+	adapters  []string
+}
+
+// upstreamInfo get_server_info 中展示的上游服务概况
+type upstreamInfo struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Tools     []string `json:"tools"`
+	Federated bool     `json:"federated,omitempty"`
+	Adapters  []string `json:"adapters,omitempty"`
+}
+
+// ConnectUpstreams 作为 MCP 客户端连接配置的上游服务，并以 namespace__tool 的名称重新暴露其工具。
+// 单个上游连接失败只记录警告，不影响其余上游与 TaskBridge 自身的工具。
+func (s *Server) ConnectUpstreams(ctx context.Context, upstreams []pkgconfig.UpstreamConfig) {
+	for _, cfg := range upstreams {
+		if cfg.Disabled {
+			continue
+		}
+		if err := s.connectUpstream(ctx, cfg); err != nil {
+			log.Warn().Str("component", "proxy").Str("upstream", cfg.Name).Err(err).Msg("connect upstream MCP server failed")
+		}
+	}
+}
+
+// connectUpstream 连接单个上游服务并注册其工具
+func (s *Server) connectUpstream(ctx context.Context, cfg pkgconfig.UpstreamConfig) error {
+	if cfg.Name == "" {
+		return fmt.Errorf("upstream name is required")
+	}
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = cfg.Name
+	}
+	if !validProxyToolName(namespace) {
+		return fmt.Errorf("invalid upstream namespace: %s", namespace)
+	}
+
+	s.upstreams.mu.Lock()
+	if _, exists := s.upstreams.conns[cfg.Name]; exists {
+		s.upstreams.mu.Unlock()
+		return fmt.Errorf("duplicate upstream name: %s", cfg.Name)
+	}
+	for _, conn := range s.upstreams.conns {
+		if conn.namespace == namespace {
+			s.upstreams.mu.Unlock()
+			return fmt.Errorf("duplicate upstream namespace: %s", namespace)
+		}
+	}
+	s.upstreams.mu.Unlock()
+
+	transport, err := upstreamTransport(cfg)
+	if err != nil {
+		return err
+	}
+	client := mcp.NewClient(&mcp.Implementation{
+		Name:    s.config.Name + "-proxy",
+		Version: s.config.Version,
+	}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(ctx context.Context, req *mcp.ToolListChangedRequest) {
+			if err := s.syncUpstreamTools(ctx, cfg.Name); err != nil {
+				log.Warn().Str("component", "proxy").Str("upstream", cfg.Name).Err(err).Msg("refresh upstream tools failed")
+			}
+		},
+	})
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	if cfg.Federated {
+		if err := checkFederationPeer(session); err != nil {
+			_ = session.Close()
+			return err
+		}
+	}
+
+	s.upstreams.mu.Lock()
+	if s.upstreams.conns == nil {
+		s.upstreams.conns = make(map[string]*upstreamConn)
+	}
+	s.upstreams.conns[cfg.Name] = &upstreamConn{
+		name:      cfg.Name,
+		namespace: namespace,
+		session:   session,
+		tools:     make(map[string]string),
+		federated: cfg.Federated,
+	}
+	s.upstreams.mu.Unlock()
+
+	return s.syncUpstreamTools(ctx, cfg.Name)
+}
+
+// upstreamTransport 按配置创建客户端传输
+func upstreamTransport(cfg pkgconfig.UpstreamConfig) (mcp.Transport, error) {
+	switch cfg.Transport {
+	case "", "stdio":
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("upstream %s: command is required for stdio transport", cfg.Name)
+		}
+		cmd := exec.Command(cfg.Command, cfg.Args...)
+		if len(cfg.Env) > 0 {
+			cmd.Env = os.Environ()
+			for key, value := range cfg.Env {
+				cmd.Env = append(cmd.Env, key+"="+value)
+			}
+		}
+		return &mcp.CommandTransport{Command: cmd}, nil
+	case "streamable":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("upstream %s: url is required for streamable transport", cfg.Name)
+		}
+		return &mcp.StreamableClientTransport{Endpoint: cfg.URL}, nil
+	case "sse":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("upstream %s: url is required for sse transport", cfg.Name)
+		}
+		return &mcp.SSEClientTransport{Endpoint: cfg.URL}, nil
+	default:
+		return nil, fmt.Errorf("upstream %s: unsupported transport: %s", cfg.Name, cfg.Transport)
+	}
+}
+
+// syncUpstreamTools 重新拉取上游工具列表，增删对应的代理工具；上游发送 tools/list_changed 时也会调用。
+func (s *Server) syncUpstreamTools(ctx context.Context, name string) error {
+	s.upstreams.mu.Lock()
+	conn := s.upstreams.conns[name]
+	s.upstreams.mu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("upstream not connected: %s", name)
+	}
+
+	if conn.federated {
+		// 对端 Provider 变化时其工具列表随之变化，一并刷新 adapter 列表
+		adapters, err := federationPeerAdapters(ctx, conn.session)
+		if err != nil {
+			log.Warn().Str("component", "proxy").Str("upstream", name).Err(err).Msg("read federated peer adapters failed")
+		}
+		s.upstreams.mu.Lock()
+		conn.adapters = adapters
+		s.upstreams.mu.Unlock()
+	}
+
+	fetched := make(map[string]*mcp.Tool)
+	for tool, err := range conn.session.Tools(ctx, nil) {
+		if err != nil {
+			return fmt.Errorf("list tools: %w", err)
+		}
+		if conn.federated && skipFederatedTool(tool.Name) {
+			continue
+		}
+		proxyName := conn.namespace + proxyToolSeparator + tool.Name
+		if !validProxyToolName(proxyName) || !objectSchema(tool.InputSchema) {
+			log.Warn().Str("component", "proxy").Str("upstream", name).Str("tool", tool.Name).Msg("skip upstream tool with invalid name or input schema")
+			continue
+		}
+		fetched[proxyName] = tool
+	}
+
+	s.upstreams.mu.Lock()
+	removed := make([]string, 0)
+	for proxyName := range conn.tools {
+		if _, ok := fetched[proxyName]; !ok {
+			removed = append(removed, proxyName)
+			delete(conn.tools, proxyName)
+		}
+	}
+	for proxyName, tool := range fetched {
+		conn.tools[proxyName] = tool.Name
+	}
+	s.upstreams.mu.Unlock()
+
+	s.removeToolDefs(removed)
+	for proxyName, tool := range fetched {
+		s.addProxyTool(proxyName, conn, tool)
+	}
+	s.RefreshTools()
+	return nil
+}
+
+// addProxyTool 记录代理工具定义：描述前加上游名称，调用时原样转发给上游。
+// 代理工具的读写语义由上游负责，不触发本地资源变更通知。
+func (s *Server) addProxyTool(proxyName string, conn *upstreamConn, upstream *mcp.Tool) {
+	tool := *upstream
+	tool.Name = proxyName
+	tool.Description = fmt.Sprintf("[%s] %s", conn.name, upstream.Description)
+	if tool.OutputSchema != nil && !objectSchema(tool.OutputSchema) {
+		tool.OutputSchema = nil
+	}
+	if upstream.Annotations != nil {
+		annotations := *upstream.Annotations
+		tool.Annotations = &annotations
+	}
+
+	upstreamName := upstream.Name
+	handler := func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		params := &mcp.CallToolParams{Name: upstreamName}
+		if len(req.Params.Arguments) > 0 {
+			params.Arguments = req.Params.Arguments
+		}
+		return conn.session.CallTool(ctx, params)
+	}
+
+	s.toolMu.Lock()
+	defer s.toolMu.Unlock()
+	if s.toolDefs == nil {
+		s.toolDefs = make(map[string]registeredTool)
+	}
+	if _, exists := s.toolDefs[proxyName]; !exists {
+		s.toolOrder = append(s.toolOrder, proxyName)
+	}
+	s.toolDefs[proxyName] = registeredTool{tool: &tool, handler: logToolCall(proxyName, handler)}
+	// 已注册的工具直接以新定义覆盖，未注册的交由 RefreshTools 添加
+	if s.activeTools[proxyName] {
+		s.server.AddTool(&tool, s.toolDefs[proxyName].handler)
+	}
+}
+
+// removeToolDefs 从工具注册表与 MCP 服务中移除工具
+func (s *Server) removeToolDefs(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	s.toolMu.Lock()
+	defer s.toolMu.Unlock()
+	drop := make(map[string]bool, len(names))
+	active := make([]string, 0, len(names))
+	for _, name := range names {
+		drop[name] = true
+		delete(s.toolDefs, name)
+		if s.activeTools[name] {
+			active = append(active, name)
+			delete(s.activeTools, name)
+		}
+	}
+	order := s.toolOrder[:0]
+	for _, name := range s.toolOrder {
+		if !drop[name] {
+			order = append(order, name)
+		}
+	}
+	s.toolOrder = order
+	if len(active) > 0 {
+		s.server.RemoveTools(active...)
+	}
+}
+
+// upstreamSnapshot 返回已连接上游及其代理工具，按名称排序
+func (s *Server) upstreamSnapshot() []upstreamInfo {
+	s.upstreams.mu.Lock()
+	defer s.upstreams.mu.Unlock()
+	infos := make([]upstreamInfo, 0, len(s.upstreams.conns))
+	for _, conn := range s.upstreams.conns {
+		tools := make([]string, 0, len(conn.tools))
+		for proxyName := range conn.tools {
+			tools = append(tools, proxyName)
+		}
+		sort.Strings(tools)
+		infos = append(infos, upstreamInfo{
+			Name:      conn.name,
+			Namespace: conn.namespace,
+			Tools:     tools,
+			Federated: conn.federated,
+			Adapters:  append([]string(nil), conn.adapters...),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// CloseUpstreams 关闭所有上游连接（stdio 上游的子进程随之退出）
+func (s *Server) CloseUpstreams() {
+	s.upstreams.mu.Lock()
+	conns := s.upstreams.conns
+	s.upstreams.conns = nil
+	s.upstreams.mu.Unlock()
+	for name, conn := range conns {
+		if err := conn.session.Close(); err != nil {
+			log.Debug().Str("component", "proxy").Str("upstream", name).Err(err).Msg("close upstream session")
+		}
+	}
+}
+
+// objectSchema 判断 schema 是否为 type=object 的 JSON 对象；go-sdk 注册工具时对其他 schema 会 panic
+func objectSchema(schema any) bool {
+	if schema == nil {
+		return false
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return false
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return false
+	}
+	return m["type"] == "object"
+}
+
+// validProxyToolName 判断名称是否满足 MCP 工具名规则（1-128 个字母、数字、_、-、.）
+func validProxyToolName(name string) bool {
+	if name == "" || len(name) > 128 {
+		return false
+	}
+	return strings.IndexFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.')
+	}) < 0
+}
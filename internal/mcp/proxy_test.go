@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/provider"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func newUpstreamServer(t *testing.T) (*sdkmcp.Server, string) {
+	t.Helper()
+	upstream := sdkmcp.NewServer(&sdkmcp.Implementation{Name: "notes", Version: "1.0.0"}, nil)
+	upstream.AddTool(&sdkmcp.Tool{
+		Name:        "echo",
+		Description: "回显输入",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}}}`),
+	}, func(_ context.Context, req *sdkmcp.CallToolRequest) (*sdkmcp.CallToolResult, error) {
+		var args struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(req.Params.Arguments, &args); err != nil {
+			return nil, err
+		}
+		return &sdkmcp.CallToolResult{Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: "echo: " + args.Text}}}, nil
+	})
+	httpServer := httptest.NewServer(sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server {
+		return upstream
+	}, nil))
+	t.Cleanup(httpServer.Close)
+	return upstream, httpServer.URL
+}
+
+func TestConnectUpstreamsProxiesTools(t *testing.T) {
+	upstream, url := newUpstreamServer(t)
+	s := NewServer()
+	ctx := context.Background()
+	s.ConnectUpstreams(ctx, []pkgconfig.UpstreamConfig{
+		{Name: "notes", Transport: "streamable", URL: url},
+		{Name: "broken", Transport: "stdio"},
+		{Name: "skipped", Transport: "streamable", URL: url, Disabled: true},
+	})
+	t.Cleanup(s.CloseUpstreams)
+
+	if tools := s.GetTools(); !tools["notes__echo"] || !tools["list_tasks"] {
+		t.Fatalf("expected proxied and native tools, got %v", tools)
+	}
+	infos := s.upstreamSnapshot()
+	if len(infos) != 1 || infos[0].Name != "notes" || len(infos[0].Tools) != 1 {
+		t.Fatalf("unexpected upstreams: %+v", infos)
+	}
+
+	session := connectBreakdownClient(t, s, nil)
+	res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: "notes__echo", Arguments: map[string]any{"text": "hi"}})
+	if err != nil {
+		t.Fatalf("call proxied tool: %v", err)
+	}
+	if text := res.Content[0].(*sdkmcp.TextContent).Text; text != "echo: hi" {
+		t.Fatalf("unexpected proxied result: %q", text)
+	}
+
+	// 上游工具变化后代理工具随之增删
+	upstream.AddTool(&sdkmcp.Tool{
+		Name:        "ping",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, func(context.Context, *sdkmcp.CallToolRequest) (*sdkmcp.CallToolResult, error) {
+		return &sdkmcp.CallToolResult{Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: "pong"}}}, nil
+	})
+	upstream.RemoveTools("echo")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		tools := s.GetTools()
+		if tools["notes__ping"] && !tools["notes__echo"] {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("proxied tools not refreshed: %v", tools)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestUpstreamTransportValidation(t *testing.T) {
+	cases := []pkgconfig.UpstreamConfig{
+		{Name: "a", Transport: "stdio"},
+		{Name: "b", Transport: "streamable"},
+		{Name: "c", Transport: "websocket", URL: "ws://localhost"},
+	}
+	for _, cfg := range cases {
+		if _, err := upstreamTransport(cfg); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+	if _, err := upstreamTransport(pkgconfig.UpstreamConfig{Name: "d", Command: "notes-mcp"}); err != nil {
+		t.Fatalf("expected default stdio transport: %v", err)
+	}
+}
+
+func TestFederatedUpstreamMergesPeerAdapters(t *testing.T) {
+	peer := NewServer(WithProviders(map[string]provider.Provider{"google": &mockProvider{}}))
+	httpServer := httptest.NewServer(sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server {
+		return peer.server
+	}, nil))
+	t.Cleanup(httpServer.Close)
+	_, notesURL := newUpstreamServer(t)
+
+	s := NewServer()
+	ctx := context.Background()
+	s.ConnectUpstreams(ctx, []pkgconfig.UpstreamConfig{
+		{Name: "home", Transport: "streamable", URL: httpServer.URL, Federated: true},
+		{Name: "notes", Transport: "streamable", URL: notesURL, Federated: true},
+	})
+	t.Cleanup(s.CloseUpstreams)
+
+	tools := s.GetTools()
+	if !tools["home__list_tasks"] || !tools["home__sync_pull"] {
+		t.Fatalf("expected peer tools under namespace, got %v", tools)
+	}
+	if tools["home__set_context"] || tools["notes__echo"] {
+		t.Fatalf("session tools and non-taskbridge peers should be skipped: %v", tools)
+	}
+	infos := s.upstreamSnapshot()
+	if len(infos) != 1 || !infos[0].Federated || len(infos[0].Adapters) != 1 || infos[0].Adapters[0] != "google" {
+		t.Fatalf("unexpected federated upstreams: %+v", infos)
+	}
+
+	session := connectBreakdownClient(t, s, nil)
+	res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: "home__list_task_lists", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("call federated tool: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("federated tool returned error: %+v", res.Content)
+	}
+}
@@ -0,0 +1,174 @@
+package mcp
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+// rateLimitIdleTTL 令牌桶闲置超过该时长（已补满）后被清理
+const rateLimitIdleTTL = 10 * time.Minute
+
+// rateLimiter 按客户端区分的令牌桶限流器
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket 单个客户端的令牌桶
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), now: time.Now, buckets: make(map[string]*tokenBucket)}
+}
+
+// allow 消耗一个令牌；令牌不足时返回需要等待的时长
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+
+	bucket := l.refill(key, now)
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	return false, l.wait(bucket)
+}
+
+// available 判断是否还有令牌但不消耗；令牌不足时返回需要等待的时长
+func (l *rateLimiter) available(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+
+	bucket := l.refill(key, now)
+	if bucket.tokens >= 1 {
+		return true, 0
+	}
+	return false, l.wait(bucket)
+}
+
+// refill 按流逝的时间补充令牌，调用方持有 l.mu
+func (l *rateLimiter) refill(key string, now time.Time) *tokenBucket {
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+	return bucket
+}
+
+// wait 令牌补充到 1 个需要的时长
+func (l *rateLimiter) wait(bucket *tokenBucket) time.Duration {
+	return time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+}
+
+// sweep 定期清理闲置的令牌桶，避免按 IP 区分时桶数量无限增长
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) > rateLimitIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// middleware 超出限额的请求返回 429 与 Retry-After
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := l.allow(rateLimitKey(r))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authFailureMiddleware 位于认证之前，按客户端 IP 对认证失败（401）的请求计数：
+// 配额耗尽后该 IP 的请求在恢复前一律返回 429，暴力猜测 token 与未认证请求的洪泛同样受到限制。
+// 通过认证的请求不消耗这里的配额，由认证之后的 middleware 按 API key/用户限流
+func (l *rateLimiter) authFailureMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := clientIPKey(r)
+		if ok, wait := l.available(key); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == http.StatusUnauthorized {
+			l.allow(key)
+		}
+	})
+}
+
+// statusRecorder 记录响应状态码，保留 Flush 以支持 SSE 流
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap 供 http.ResponseController 访问底层 ResponseWriter
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// rateLimitKey 确定限流的客户端标识：OAuth 用户 > 已认证的 API key > 客户端 IP。
+// 未经认证的请求头由客户端任意填写，不能用来区分客户端，否则轮换请求头即可绕过限流；
+// 客户端 IP 取 r.RemoteAddr，位于可信代理之后时已由 forwardedHeadersMiddleware 还原
+func rateLimitKey(r *http.Request) string {
+	if info := auth.TokenInfoFromContext(r.Context()); info != nil && info.UserID != "" {
+		return "user:" + info.UserID
+	}
+	if key := authenticatedKey(r.Context()); key != "" {
+		return "key:" + key
+	}
+	return clientIPKey(r)
+}
+
+// clientIPKey 按客户端 IP 区分的限流标识
+func clientIPKey(r *http.Request) string {
+	if ip := remoteIP(r.RemoteAddr); ip != nil {
+		return "ip:" + ip.String()
+	}
+	return "ip:" + r.RemoteAddr
+}
@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func TestRateLimiterTokenBucket(t *testing.T) {
+	now := time.Unix(1000, 0)
+	limiter := newRateLimiter(2, 3)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.allow("a"); !ok {
+			t.Fatalf("request %d within burst should pass", i)
+		}
+	}
+	ok, wait := limiter.allow("a")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("expected limited with 500ms wait, got %v %v", ok, wait)
+	}
+	if ok, _ := limiter.allow("b"); !ok {
+		t.Fatalf("other clients have their own bucket")
+	}
+
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow("a"); !ok {
+			t.Fatalf("expected refilled token %d", i)
+		}
+	}
+	if ok, _ := limiter.allow("a"); ok {
+		t.Fatalf("expected bucket drained again")
+	}
+
+	// 闲置的桶被清理
+	now = now.Add(rateLimitIdleTTL + time.Minute)
+	limiter.allow("c")
+	if _, ok := limiter.buckets["b"]; ok {
+		t.Fatalf("expected idle bucket swept")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	s := NewServer(
+		WithRateLimitConfig(&pkgconfig.RateLimitConfig{Enabled: true, RequestsPerSecond: 1, Burst: 1}),
+		WithSecurityConfig(&pkgconfig.SecurityConfig{Enabled: true, AuthMode: "token", Tokens: []string{"key-1", "key-2"}}),
+	)
+	handler, err := s.wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	serve := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("key-1"); rec.Code != http.StatusOK {
+		t.Fatalf("first request should pass, got %d", rec.Code)
+	}
+	rec := serve("key-1")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected 429 with Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+	// 同一 IP 的不同 API key 分别计数
+	if rec := serve("key-2"); rec.Code != http.StatusOK {
+		t.Fatalf("other api key should pass, got %d", rec.Code)
+	}
+	// 认证失败的请求按 IP 单独计数，配额耗尽后返回 429，不影响已认证 key 的配额
+	if rec := serve("bad"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for bad key, got %d", rec.Code)
+	}
+	rec = serve("bad")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected repeated auth failures to be throttled, got %d %v", rec.Code, rec.Header())
+	}
+	// 配额耗尽期间同一 IP 的其他请求同样被拒绝，避免继续猜测 token
+	if rec := serve("key-2"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 while auth failures are throttled, got %d", rec.Code)
+	}
+}
+
+func TestRateLimitThrottlesAuthFailuresPerIP(t *testing.T) {
+	s := NewServer(
+		WithRateLimitConfig(&pkgconfig.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.001, Burst: 2}),
+		WithSecurityConfig(&pkgconfig.SecurityConfig{Enabled: true, AuthMode: "token", Tokens: []string{"key-1"}}),
+	)
+	handler, err := s.wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	serve := func(ip, key string) int {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = ip + ":5000"
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// 成功的请求不消耗认证失败配额
+	for i := 0; i < 2; i++ {
+		if code := serve("10.0.0.1", "key-1"); code != http.StatusOK {
+			t.Fatalf("authenticated request %d should pass, got %d", i, code)
+		}
+	}
+	for i, key := range []string{"", "guess-1"} {
+		if code := serve("10.0.0.1", key); code != http.StatusUnauthorized {
+			t.Fatalf("auth failure %d should return 401, got %d", i, code)
+		}
+	}
+	if code := serve("10.0.0.1", "guess-2"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 after repeated 401s, got %d", code)
+	}
+	if code := serve("10.0.0.2", "guess-3"); code != http.StatusUnauthorized {
+		t.Fatalf("other IPs have their own auth failure bucket, got %d", code)
+	}
+}
+
+func TestRateLimitIgnoresUnauthenticatedKeys(t *testing.T) {
+	s := NewServer(WithRateLimitConfig(&pkgconfig.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.001, Burst: 1}))
+	handler, err := s.wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	serve := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		req.Header.Set(header, value)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve("X-API-Key", "a"); code != http.StatusOK {
+		t.Fatalf("first request should pass, got %d", code)
+	}
+	// 未启用认证时轮换 API key 或 Authorization 不能绕过按 IP 的限流
+	if code := serve("X-API-Key", "b"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 for rotated api key, got %d", code)
+	}
+	if code := serve("Authorization", "Bearer c"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 for rotated bearer token, got %d", code)
+	}
+}
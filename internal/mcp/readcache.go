@@ -0,0 +1,263 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+)
+
+// adapterReadCache 在 Provider 前缓存热点读取（清单列表、清单中的任务），条目在 TTL 后过期。
+// 经过同一 Provider 的写入只失效受影响的清单；同步、webhook 与 Provider 替换失效整个 Provider
+type adapterReadCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]adapterReadEntry
+	hits       int
+	misses     int
+}
+
+type adapterReadEntry struct {
+	adapter string
+	// listID 缓存的任务所属清单；清单列表为 adapterListsKey
+	listID  string
+	value   interface{}
+	expires time.Time
+}
+
+// adapterListsKey 清单列表条目所用的 listID 占位，不会与真实清单 ID 冲突
+const adapterListsKey = "\x00lists"
+
+// WithAdapterCache 启用 Provider 读取缓存：清单列表与清单中的任务缓存 ttl，最多 maxEntries 条（不大于 0 时不限制）。
+// 多租户请求不使用缓存
+func WithAdapterCache(ttl time.Duration, maxEntries int) ServerOption {
+	return func(s *Server) {
+		if ttl <= 0 {
+			return
+		}
+		s.adapterCache = &adapterReadCache{ttl: ttl, maxEntries: maxEntries, entries: make(map[string]adapterReadEntry)}
+	}
+}
+
+func adapterReadKey(adapter, listID, variant string) string {
+	return adapter + "\x00" + listID + "\x00" + variant
+}
+
+func (c *adapterReadCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return entry.value, true
+}
+
+func (c *adapterReadCache) put(key, adapter, listID string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		// 先清理过期条目，仍然已满时淘汰最早过期的条目
+		oldestKey, oldest := "", time.Time{}
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+				continue
+			}
+			if oldestKey == "" || entry.expires.Before(oldest) {
+				oldestKey, oldest = k, entry.expires
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			delete(c.entries, oldestKey)
+		}
+	}
+	c.entries[key] = adapterReadEntry{adapter: adapter, listID: listID, value: value, expires: now.Add(c.ttl)}
+}
+
+// invalidate 失效 adapter 的缓存：listIDs 为空时失效该 Provider 的全部条目，否则只失效这些清单中的任务；
+// 空字符串表示写入了默认清单，失效全部清单中的任务；adapterListsKey 失效清单列表
+func (c *adapterReadCache) invalidate(adapter string, listIDs ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if entry.adapter == adapter && (len(listIDs) == 0 || containsListID(listIDs, entry.listID)) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// invalidateAll 清空缓存
+func (c *adapterReadCache) invalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]adapterReadEntry)
+}
+
+func (c *adapterReadCache) status() map[string]interface{} {
+	if c == nil {
+		return map[string]interface{}{"enabled": false}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return map[string]interface{}{
+		"enabled":     true,
+		"ttl_seconds": int64(c.ttl / time.Second),
+		"entries":     len(c.entries),
+		"hits":        c.hits,
+		"misses":      c.misses,
+	}
+}
+
+func containsListID(listIDs []string, listID string) bool {
+	for _, id := range listIDs {
+		if id == listID || (id == "" && listID != adapterListsKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedReads 为服务自身的 Provider 套上读取缓存；未启用缓存或多租户请求时原样返回
+func (s *Server) cachedReads(ctx context.Context, name string, p provider.Provider) provider.Provider {
+	if s.adapterCache == nil || p == nil {
+		return p
+	}
+	if _, tenantScoped := tenantProvidersFrom(ctx); tenantScoped {
+		return p
+	}
+	return &cachedProvider{Provider: p, name: name, cache: s.adapterCache}
+}
+
+// invalidateAdapterReads 失效 Provider 的读取缓存，listIDs 为空时失效整个 Provider
+func (s *Server) invalidateAdapterReads(name string, listIDs ...string) {
+	if s.adapterCache == nil {
+		return
+	}
+	if name == "" {
+		s.adapterCache.invalidateAll()
+		return
+	}
+	s.adapterCache.invalidate(name, listIDs...)
+}
+
+// unwrapProvider 返回读取缓存包装下的 Provider，用于检查附件、依赖、排序等可选接口
+func unwrapProvider(p provider.Provider) provider.Provider {
+	if cached, ok := p.(*cachedProvider); ok {
+		return cached.Provider
+	}
+	return p
+}
+
+// cachedProvider 缓存 ListTaskLists 与 ListTasks 的结果；写入成功后失效所写清单的缓存
+type cachedProvider struct {
+	provider.Provider
+	name  string
+	cache *adapterReadCache
+}
+
+// ReadOnly 保留被包装 Provider 的只读状态
+func (p *cachedProvider) ReadOnly() bool {
+	return provider.IsReadOnly(p.Provider)
+}
+
+func (p *cachedProvider) ListTaskLists(ctx context.Context) ([]model.TaskList, error) {
+	key := adapterReadKey(p.name, adapterListsKey, "")
+	if value, ok := p.cache.get(key); ok {
+		return append([]model.TaskList(nil), value.([]model.TaskList)...), nil
+	}
+	lists, err := p.Provider.ListTaskLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.put(key, p.name, adapterListsKey, append([]model.TaskList(nil), lists...))
+	return lists, nil
+}
+
+func (p *cachedProvider) ListTasks(ctx context.Context, listID string, opts provider.ListOptions) ([]model.Task, error) {
+	variant, _ := json.Marshal(opts)
+	key := adapterReadKey(p.name, listID, string(variant))
+	if value, ok := p.cache.get(key); ok {
+		return append([]model.Task(nil), value.([]model.Task)...), nil
+	}
+	tasks, err := p.Provider.ListTasks(ctx, listID, opts)
+	if err != nil {
+		return nil, err
+	}
+	p.cache.put(key, p.name, listID, append([]model.Task(nil), tasks...))
+	return tasks, nil
+}
+
+func (p *cachedProvider) CreateTaskList(ctx context.Context, name string) (*model.TaskList, error) {
+	list, err := p.Provider.CreateTaskList(ctx, name)
+	if err == nil {
+		p.cache.invalidate(p.name, adapterListsKey)
+	}
+	return list, err
+}
+
+func (p *cachedProvider) DeleteTaskList(ctx context.Context, listID string) error {
+	err := p.Provider.DeleteTaskList(ctx, listID)
+	if err == nil {
+		p.cache.invalidate(p.name, adapterListsKey, listID)
+	}
+	return err
+}
+
+func (p *cachedProvider) CreateTask(ctx context.Context, listID string, task *model.Task) (*model.Task, error) {
+	created, err := p.Provider.CreateTask(ctx, listID, task)
+	if err == nil {
+		p.cache.invalidate(p.name, taskListIDs(listID, task)...)
+	}
+	return created, err
+}
+
+func (p *cachedProvider) UpdateTask(ctx context.Context, listID string, task *model.Task) (*model.Task, error) {
+	updated, err := p.Provider.UpdateTask(ctx, listID, task)
+	if err == nil {
+		// 任务可能被移到其他清单，原清单与目标清单都失效
+		p.cache.invalidate(p.name, taskListIDs(listID, task, updated)...)
+	}
+	return updated, err
+}
+
+func (p *cachedProvider) DeleteTask(ctx context.Context, listID, taskID string) error {
+	err := p.Provider.DeleteTask(ctx, listID, taskID)
+	if err == nil {
+		p.cache.invalidate(p.name, listID)
+	}
+	return err
+}
+
+func (p *cachedProvider) BatchCreate(ctx context.Context, listID string, tasks []*model.Task) ([]model.Task, error) {
+	created, err := p.Provider.BatchCreate(ctx, listID, tasks)
+	// 批量写入可能部分成功，出错时同样失效
+	p.cache.invalidate(p.name, taskListIDs(listID, tasks...)...)
+	return created, err
+}
+
+func (p *cachedProvider) BatchUpdate(ctx context.Context, listID string, tasks []*model.Task) ([]model.Task, error) {
+	updated, err := p.Provider.BatchUpdate(ctx, listID, tasks)
+	p.cache.invalidate(p.name, taskListIDs(listID, tasks...)...)
+	return updated, err
+}
+
+// taskListIDs 返回写入涉及的清单 ID；listID 为空（使用默认清单）时包含空字符串
+func taskListIDs(listID string, tasks ...*model.Task) []string {
+	ids := []string{strings.TrimSpace(listID)}
+	for _, task := range tasks {
+		if task != nil && strings.TrimSpace(task.ListID) != "" {
+			ids = append(ids, task.ListID)
+		}
+	}
+	return ids
+}
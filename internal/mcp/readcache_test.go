@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+// countingProvider 记录清单与任务的远端读取次数
+type countingProvider struct {
+	mockProvider
+	listCalls int
+	taskCalls map[string]int
+}
+
+func (p *countingProvider) ListTaskLists(ctx context.Context) ([]model.TaskList, error) {
+	p.listCalls++
+	return []model.TaskList{
+		{ID: "l1", Name: "My Tasks", Source: model.SourceGoogle},
+		{ID: "l2", Name: "Work", Source: model.SourceGoogle},
+	}, nil
+}
+
+func (p *countingProvider) ListTasks(ctx context.Context, listID string, opts provider.ListOptions) ([]model.Task, error) {
+	if p.taskCalls == nil {
+		p.taskCalls = make(map[string]int)
+	}
+	p.taskCalls[listID]++
+	return []model.Task{{ID: listID + "-t1", Title: "task in " + listID, ListID: listID}}, nil
+}
+
+func newReadCacheTestServer(t *testing.T, ttl time.Duration) (*Server, *countingProvider) {
+	t.Helper()
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	remote := &countingProvider{}
+	s := NewServer(WithTaskStorage(store), WithProviders(map[string]provider.Provider{"google": remote}), WithAdapterCache(ttl, 0))
+	return s, remote
+}
+
+func TestAdapterCacheServesHotReads(t *testing.T) {
+	s, remote := newReadCacheTestServer(t, time.Minute)
+	ctx := context.Background()
+	p, _ := s.lookupProvider(ctx, "google")
+
+	for i := 0; i < 3; i++ {
+		if lists, err := p.ListTaskLists(ctx); err != nil || len(lists) != 2 {
+			t.Fatalf("ListTaskLists: %v, %v", lists, err)
+		}
+		if _, err := p.ListTasks(ctx, "l1", provider.ListOptions{}); err != nil {
+			t.Fatalf("ListTasks: %v", err)
+		}
+	}
+	if remote.listCalls != 1 || remote.taskCalls["l1"] != 1 {
+		t.Fatalf("repeated reads should be served from cache: lists=%d tasks=%v", remote.listCalls, remote.taskCalls)
+	}
+
+	// 不同的查询条件分别缓存
+	completed := true
+	if _, err := p.ListTasks(ctx, "l1", provider.ListOptions{Completed: &completed}); err != nil || remote.taskCalls["l1"] != 2 {
+		t.Fatalf("different options should not share an entry: %v", remote.taskCalls)
+	}
+
+	// 调用方修改返回的切片不影响缓存
+	lists, _ := p.ListTaskLists(ctx)
+	lists[0].Name = "changed"
+	if again, _ := p.ListTaskLists(ctx); again[0].Name != "My Tasks" {
+		t.Fatalf("cached lists should not be shared with callers: %+v", again)
+	}
+
+	// 多租户请求不使用缓存
+	tenantCtx := context.WithValue(ctx, tenantProvidersKey{}, map[string]provider.Provider{"google": remote})
+	tenantProvider, _ := s.lookupProvider(tenantCtx, "google")
+	_, _ = tenantProvider.ListTaskLists(tenantCtx)
+	if remote.listCalls != 2 {
+		t.Fatalf("tenant reads should bypass the cache, got %d calls", remote.listCalls)
+	}
+}
+
+func TestAdapterCacheInvalidatesWrittenList(t *testing.T) {
+	s, remote := newReadCacheTestServer(t, time.Minute)
+	ctx := context.Background()
+	p, _ := s.lookupProvider(ctx, "google")
+	_, _ = p.ListTaskLists(ctx)
+	_, _ = p.ListTasks(ctx, "l1", provider.ListOptions{})
+	_, _ = p.ListTasks(ctx, "l2", provider.ListOptions{})
+
+	if _, err := p.UpdateTask(ctx, "l1", &model.Task{ID: "l1-t1", Title: "renamed", ListID: "l1"}); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	_, _ = p.ListTaskLists(ctx)
+	_, _ = p.ListTasks(ctx, "l1", provider.ListOptions{})
+	_, _ = p.ListTasks(ctx, "l2", provider.ListOptions{})
+	if remote.listCalls != 1 || remote.taskCalls["l1"] != 2 || remote.taskCalls["l2"] != 1 {
+		t.Fatalf("only the written list should be refetched: lists=%d tasks=%v", remote.listCalls, remote.taskCalls)
+	}
+
+	// 删除清单失效清单列表
+	if err := p.DeleteTaskList(ctx, "l2"); err != nil {
+		t.Fatalf("DeleteTaskList: %v", err)
+	}
+	_, _ = p.ListTaskLists(ctx)
+	_, _ = p.ListTasks(ctx, "l2", provider.ListOptions{})
+	if remote.listCalls != 2 || remote.taskCalls["l2"] != 2 {
+		t.Fatalf("deleting a list should refetch the catalog: lists=%d tasks=%v", remote.listCalls, remote.taskCalls)
+	}
+
+	// 替换 Provider 后整个 Provider 的缓存失效
+	s.SetProvider("google", remote)
+	_, _ = p.ListTasks(ctx, "l1", provider.ListOptions{})
+	if remote.taskCalls["l1"] != 3 {
+		t.Fatalf("replacing the provider should clear its cache: %v", remote.taskCalls)
+	}
+}
+
+func TestAdapterCacheExpires(t *testing.T) {
+	s, remote := newReadCacheTestServer(t, 20*time.Millisecond)
+	ctx := context.Background()
+	p, _ := s.lookupProvider(ctx, "google")
+	_, _ = p.ListTaskLists(ctx)
+	time.Sleep(40 * time.Millisecond)
+	_, _ = p.ListTaskLists(ctx)
+	if remote.listCalls != 2 {
+		t.Fatalf("expired entry should be refetched, got %d calls", remote.listCalls)
+	}
+}
+
+func TestAdapterCacheMaxEntries(t *testing.T) {
+	cache := &adapterReadCache{ttl: time.Minute, maxEntries: 2, entries: make(map[string]adapterReadEntry)}
+	for _, listID := range []string{"l1", "l2", "l3"} {
+		cache.put(adapterReadKey("google", listID, ""), "google", listID, []model.Task{})
+	}
+	if len(cache.entries) != 2 {
+		t.Fatalf("cache should hold at most 2 entries, got %d", len(cache.entries))
+	}
+	if _, ok := cache.get(adapterReadKey("google", "l1", "")); ok {
+		t.Fatal("oldest entry should be evicted")
+	}
+}
+
+func TestStatusReportsAdapterCache(t *testing.T) {
+	s, _ := newReadCacheTestServer(t, time.Minute)
+	ctx := context.Background()
+	p, _ := s.lookupProvider(ctx, "google")
+	_, _ = p.ListTaskLists(ctx)
+	_, _ = p.ListTaskLists(ctx)
+
+	session := connectBreakdownClient(t, s, nil)
+	res, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "taskbridge://status"})
+	if err != nil {
+		t.Fatalf("read status: %v", err)
+	}
+	var status struct {
+		AdapterCache struct {
+			Enabled bool `json:"enabled"`
+			Entries int  `json:"entries"`
+			Hits    int  `json:"hits"`
+			Misses  int  `json:"misses"`
+		} `json:"adapter_cache"`
+	}
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if c := status.AdapterCache; !c.Enabled || c.Entries != 1 || c.Hits != 1 || c.Misses != 1 {
+		t.Fatalf("unexpected adapter cache status: %+v", c)
+	}
+}
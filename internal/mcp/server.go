@@ -4,15 +4,28 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/yeisme/taskbridge/internal/embedding"
+	"github.com/yeisme/taskbridge/internal/history"
 	"github.com/yeisme/taskbridge/internal/project"
 	"github.com/yeisme/taskbridge/internal/provider"
 	"github.com/yeisme/taskbridge/internal/storage"
+	tbsync "github.com/yeisme/taskbridge/internal/sync"
+	"github.com/yeisme/taskbridge/internal/tasktemplate"
+	"github.com/yeisme/taskbridge/internal/timetrack"
 	"github.com/yeisme/taskbridge/pkg/buildinfo"
 	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
 )
@@ -22,10 +35,67 @@ type Server struct {
 	server             *mcp.Server
 	taskStore          storage.Storage
 	projectStore       project.Store
+	timeStore          timetrack.Store
+	templateStore      tasktemplate.Store
 	config             *ServerConfig
 	providers          map[string]provider.Provider
-	providerConfig     *pkgconfig.ProvidersConfig
+	adapterConfig      pkgconfig.AdaptersConfig
 	intelligenceConfig *pkgconfig.IntelligenceConfig
+	toolPolicy         *pkgconfig.ToolGovernanceConfig
+	capabilityConfig   *pkgconfig.CapabilityConfig
+	compatConfig       *pkgconfig.CompatConfig
+	securityConfig     *pkgconfig.SecurityConfig
+	corsConfig         *pkgconfig.CORSConfig
+	rateLimitConfig    *pkgconfig.RateLimitConfig
+	eventStore         mcp.EventStore
+	httpConfig         *pkgconfig.HTTPConfig
+	tenantConfig       *pkgconfig.TenantConfig
+	tenantLoader       TenantProviderLoader
+	tenantStoreLoader  TenantStoreLoader
+	webhookConfig      *pkgconfig.WebhookConfig
+	mirrorStore        tbsync.MirrorStore
+	taskHistory        *history.Store
+	embeddings         *embedding.Index
+	writeQueue         *WriteQueue
+	adapterCache       *adapterReadCache
+
+	// stdioSession stdio 传输的会话，网络请求的处理时限不作用于它
+	stdioSession atomic.Pointer[mcp.ServerSession]
+
+	// providersMu 保护 providers，支持运行时启用/禁用 Provider
+	providersMu sync.RWMutex
+
+	// 工具注册表：toolDefs 保存全部定义，activeTools 为当前已注册到 MCP 服务的工具
+	toolMu      sync.Mutex
+	toolDefs    map[string]registeredTool
+	toolOrder   []string
+	activeTools map[string]bool
+
+	// syncState sync_now 运行状态
+	syncState syncRunState
+	// taskCache 本地任务存储的新鲜度与后台刷新状态
+	taskCache taskCacheState
+
+	// webhooks webhook 触发的拉取状态
+	webhooks webhookState
+
+	// resourceWatch 资源订阅与变化检测状态
+	resourceWatch resourceWatchState
+
+	// upstreams 聚合代理模式下连接的上游 MCP 服务
+	upstreams upstreamState
+
+	// sessionContexts set_context 保存的会话默认参数
+	sessionContexts sessionContextState
+
+	// tenants 多租户模式下按租户加载的 Provider 与会话绑定的租户
+	tenants tenantState
+
+	// credentials 后台凭证检查的最近结果
+	credentials credentialMonitorState
+
+	// startedAt 服务创建时间，用于计算运行时长
+	startedAt time.Time
 }
 
 // ServerConfig 服务器配置
@@ -36,8 +106,26 @@ type ServerConfig struct {
 	Version string
 	// Transport 传输方式 (stdio, sse, streamable, inmemory)
 	Transport string
+	// Host HTTP 监听地址，空表示所有网卡
+	Host string
 	// Port HTTP 端口（用于 sse 和 streamable 模式）
 	Port int
+	// ResourcePollInterval 检查订阅资源变化的轮询间隔，<=0 时不轮询
+	ResourcePollInterval time.Duration
+	// PageSize tools/list、prompts/list、resources/list 每页条数，<=0 使用 SDK 默认值
+	PageSize int
+	// KeepAlive sse/streamable 会话的服务端 ping 间隔，ping 失败时关闭会话；<=0 不发送
+	KeepAlive time.Duration
+	// SessionIdleTimeout streamable 会话空闲超时，<=0 不超时
+	SessionIdleTimeout time.Duration
+	// CredentialCheckInterval 后台检查 Provider 凭证的间隔，<=0 不检查
+	CredentialCheckInterval time.Duration
+	// CredentialRefreshBefore token 剩余有效期不足该时长时主动刷新
+	CredentialRefreshBefore time.Duration
+	// WriteReplayInterval 重放离线写入队列的间隔，<=0 不在后台重放
+	WriteReplayInterval time.Duration
+	// TaskRefreshInterval 后台检查本地任务新鲜度并刷新过期 Provider 的间隔，<=0 只在读类工具调用时检查
+	TaskRefreshInterval time.Duration
 }
 
 // ServerOption 服务器选项
@@ -50,6 +138,27 @@ func WithTaskStorage(store storage.Storage) ServerOption {
 	}
 }
 
+// WithMirrorStore 设置镜像状态存储，sync_status 据此报告各镜像对的运行记录
+func WithMirrorStore(store tbsync.MirrorStore) ServerOption {
+	return func(s *Server) {
+		s.mirrorStore = store
+	}
+}
+
+// WithTaskHistory 设置任务历史，task_history 据此查询任务的字段变化，资源轮询发现的变化也记录到其中
+func WithTaskHistory(store *history.Store) ServerOption {
+	return func(s *Server) {
+		s.taskHistory = store
+	}
+}
+
+// WithEmbeddingIndex 设置任务向量索引，设置后注册 semantic_search
+func WithEmbeddingIndex(index *embedding.Index) ServerOption {
+	return func(s *Server) {
+		s.embeddings = index
+	}
+}
+
 // WithProjectStore 设置项目存储
 func WithProjectStore(store project.Store) ServerOption {
 	return func(s *Server) {
@@ -57,6 +166,20 @@ func WithProjectStore(store project.Store) ServerOption {
 	}
 }
 
+// WithTimeEntryStore 设置时间记录存储
+func WithTimeEntryStore(store timetrack.Store) ServerOption {
+	return func(s *Server) {
+		s.timeStore = store
+	}
+}
+
+// WithTemplateStore 设置任务模板存储
+func WithTemplateStore(store tasktemplate.Store) ServerOption {
+	return func(s *Server) {
+		s.templateStore = store
+	}
+}
+
 // WithConfig 设置配置
 func WithConfig(cfg *ServerConfig) ServerOption {
 	return func(s *Server) {
@@ -71,10 +194,10 @@ func WithProviders(providers map[string]provider.Provider) ServerOption {
 	}
 }
 
-// WithProviderConfig 设置 Provider 配置
-func WithProviderConfig(cfg *pkgconfig.ProvidersConfig) ServerOption {
+// WithAdapterConfig 设置适配器（Provider）配置
+func WithAdapterConfig(cfg pkgconfig.AdaptersConfig) ServerOption {
 	return func(s *Server) {
-		s.providerConfig = cfg
+		s.adapterConfig = cfg
 	}
 }
 
@@ -85,6 +208,62 @@ func WithIntelligenceConfig(cfg *pkgconfig.IntelligenceConfig) ServerOption {
 	}
 }
 
+// WithToolPolicy 设置工具治理配置（allow/deny 名单与只读模式）
+func WithToolPolicy(cfg *pkgconfig.ToolGovernanceConfig) ServerOption {
+	return func(s *Server) {
+		s.toolPolicy = cfg
+	}
+}
+
+// WithCapabilityConfig 设置 MCP 能力开关（资源、提示词、日志），未设置时全部启用
+func WithCapabilityConfig(cfg *pkgconfig.CapabilityConfig) ServerOption {
+	return func(s *Server) {
+		s.capabilityConfig = cfg
+	}
+}
+
+// WithCompatConfig 设置面向旧版客户端的协议兼容配置（协商版本、结构化输出、旧版 SSE 端点）
+func WithCompatConfig(cfg *pkgconfig.CompatConfig) ServerOption {
+	return func(s *Server) {
+		s.compatConfig = cfg
+	}
+}
+
+// WithSecurityConfig 设置 HTTP 传输的访问控制配置（token / API key 认证）
+func WithSecurityConfig(cfg *pkgconfig.SecurityConfig) ServerOption {
+	return func(s *Server) {
+		s.securityConfig = cfg
+	}
+}
+
+// WithCORSConfig 设置 HTTP/SSE 端点的跨域策略，供浏览器中的 MCP 客户端直接连接
+func WithCORSConfig(cfg *pkgconfig.CORSConfig) ServerOption {
+	return func(s *Server) {
+		s.corsConfig = cfg
+	}
+}
+
+// WithRateLimitConfig 设置 HTTP 传输的按客户端限流
+func WithRateLimitConfig(cfg *pkgconfig.RateLimitConfig) ServerOption {
+	return func(s *Server) {
+		s.rateLimitConfig = cfg
+	}
+}
+
+// WithEventStore 设置 streamable 流事件存储，客户端断线重连时可按 Last-Event-ID 重放未收到的消息
+func WithEventStore(store mcp.EventStore) ServerOption {
+	return func(s *Server) {
+		s.eventStore = store
+	}
+}
+
+// WithHTTPConfig 设置 HTTP 监听的请求体上限、连接超时与单请求处理时限
+func WithHTTPConfig(cfg *pkgconfig.HTTPConfig) ServerOption {
+	return func(s *Server) {
+		s.httpConfig = cfg
+	}
+}
+
 // NewServer 创建 MCP 服务器
 func NewServer(opts ...ServerOption) *Server {
 	s := &Server{
@@ -94,33 +273,93 @@ func NewServer(opts ...ServerOption) *Server {
 			Transport: "stdio",
 		},
 		providers: make(map[string]provider.Provider),
+		startedAt: time.Now(),
 	}
 
 	for _, opt := range opts {
 		opt(s)
 	}
+	// 多租户时任务存储按请求所属租户分派
+	if s.tenantEnabled() && s.taskStore != nil {
+		s.taskStore = tenantTaskStore{base: s.taskStore}
+	}
+
+	// go-sdk 对负数页大小会 panic，统一回退到默认值
+	pageSize := s.config.PageSize
+	if pageSize < 0 {
+		pageSize = 0
+	}
 
-	// 创建 MCP 服务器实例
+	// 创建 MCP 服务器实例；只声明配置中启用的能力。
+	// 工具集合会随 Provider 与工具策略在运行时变化，始终声明 tools.listChanged
+	capabilities := &mcp.ServerCapabilities{
+		Tools: &mcp.ToolCapabilities{ListChanged: true},
+	}
+	if s.loggingEnabled() {
+		capabilities.Logging = &mcp.LoggingCapabilities{}
+	}
+	serverOpts := &mcp.ServerOptions{
+		PageSize:     pageSize,
+		Capabilities: capabilities,
+	}
+	// 配置了网络传输时开启保活，及时清理断线的客户端；保活作用于全部会话，同时提供 stdio 时 stdio 客户端同样需要响应 ping
+	if s.config.KeepAlive > 0 && s.httpTransport() != "" {
+		serverOpts.KeepAlive = s.config.KeepAlive
+	}
+	if s.resourcesEnabled() {
+		serverOpts.SubscribeHandler = s.handleSubscribe
+		serverOpts.UnsubscribeHandler = s.handleUnsubscribe
+	}
+	if s.resourcesEnabled() || s.promptsEnabled() {
+		serverOpts.CompletionHandler = s.handleComplete
+	}
 	s.server = mcp.NewServer(&mcp.Implementation{
 		Name:    s.config.Name,
 		Version: s.config.Version,
-	}, nil)
+	}, serverOpts)
+	s.server.AddReceivingMiddleware(s.compatMiddleware, s.scopeMiddleware, s.tenantMiddleware, s.instructionsMiddleware, s.requestTimeoutMiddleware)
 
 	// 注册工具
 	s.registerTools()
 
 	// 注册提示词
-	s.registerPrompts()
+	if s.promptsEnabled() {
+		s.registerPrompts()
+	}
 
 	// 注册资源
-	s.registerResources()
+	if s.resourcesEnabled() {
+		s.registerResources()
+	}
 
 	return s
 }
 
 // Start 启动 MCP 服务
 func (s *Server) Start(ctx context.Context) error {
-	switch s.config.Transport {
+	if s.config.ResourcePollInterval > 0 && s.resourcesEnabled() {
+		go s.watchResourceChanges(ctx, s.config.ResourcePollInterval)
+	}
+	if s.config.CredentialCheckInterval > 0 {
+		go s.watchCredentials(ctx, s.config.CredentialCheckInterval, s.config.CredentialRefreshBefore)
+	}
+	if s.writeQueue != nil && s.config.WriteReplayInterval > 0 {
+		go s.watchWriteQueue(ctx, s.config.WriteReplayInterval)
+	}
+	if s.taskCacheEnabled() && s.config.TaskRefreshInterval > 0 {
+		go s.watchTaskCache(ctx, s.config.TaskRefreshInterval)
+	}
+
+	transports := s.transports()
+	if len(transports) == 1 {
+		return s.startTransport(ctx, transports[0])
+	}
+	return s.startTransports(ctx, transports)
+}
+
+// startTransport 启动单个传输并阻塞到其结束
+func (s *Server) startTransport(ctx context.Context, transport string) error {
+	switch transport {
 	case "stdio":
 		return s.startStdio(ctx)
 	case "sse":
@@ -130,22 +369,95 @@ func (s *Server) Start(ctx context.Context) error {
 	case "inmemory":
 		return s.startInMemory(ctx)
 	default:
-		return fmt.Errorf("unsupported transport: %s", s.config.Transport)
+		return fmt.Errorf("unsupported transport: %s", transport)
 	}
 }
 
-// startStdio 启动 stdio 传输
+// startTransports 同时启动多个传输，共享同一个 MCP 服务（provider、缓存与订阅状态）。
+// 任一传输结束（如 stdio 客户端关闭 stdin）时停止其余传输，避免进程在父进程退出后残留。
+func (s *Server) startTransports(ctx context.Context, transports []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan error, len(transports))
+	for _, transport := range transports {
+		go func(transport string) {
+			if err := s.startTransport(ctx, transport); err != nil {
+				results <- fmt.Errorf("%s: %w", transport, err)
+				return
+			}
+			results <- nil
+		}(transport)
+	}
+	var errs []error
+	for range transports {
+		if err := <-results; err != nil {
+			errs = append(errs, err)
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// transports 返回配置的传输列表（transport 可为逗号分隔的多个值）
+func (s *Server) transports() []string {
+	parts := strings.Split(s.config.Transport, ",")
+	transports := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			transports = append(transports, part)
+		}
+	}
+	if len(transports) == 0 {
+		return []string{s.config.Transport}
+	}
+	return transports
+}
+
+// httpTransport 返回配置中的网络传输（sse/streamable），仅 stdio 时返回空字符串
+func (s *Server) httpTransport() string {
+	return pkgconfig.HTTPTransport(strings.Join(s.transports(), ","))
+}
+
+// ListenAddr 组合 HTTP 监听地址；host 为空时监听所有网卡，IPv6 地址可带或不带方括号
+func ListenAddr(host string, port int) string {
+	return net.JoinHostPort(strings.Trim(strings.TrimSpace(host), "[]"), strconv.Itoa(port))
+}
+
+// startStdio 启动 stdio 传输：stdin/stdout 专用于 JSON-RPC 通信，
+// 运行期间 os.Stdout 指向 stderr，其他代码误写 stdout 的内容不会破坏协议帧。
 func (s *Server) startStdio(ctx context.Context) error {
-	transport := &mcp.StdioTransport{}
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+
+	transport := &mcp.IOTransport{Reader: os.Stdin, Writer: nopWriteCloser{stdout}}
 	session, err := s.server.Connect(ctx, transport, nil)
 	if err != nil {
 		return err
 	}
-	// 等待上下文取消
-	<-ctx.Done()
-	return session.Close()
+	s.stdioSession.Store(session)
+	// 客户端关闭 stdin（进程退出）时会话结束，服务随之退出；否则等待上下文取消
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+	select {
+	case err := <-done:
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		return session.Close()
+	}
 }
 
+// nopWriteCloser 关闭会话时不关闭进程的标准输出
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
 // startInMemory 启动内存传输（用于测试）
 func (s *Server) startInMemory(ctx context.Context) error {
 	// 内存传输主要用于测试，这里简单返回
@@ -155,7 +467,7 @@ func (s *Server) startInMemory(ctx context.Context) error {
 
 // startSSE 启动 SSE 传输
 func (s *Server) startSSE(ctx context.Context) error {
-	addr := fmt.Sprintf(":%d", s.config.Port)
+	addr := ListenAddr(s.config.Host, s.config.Port)
 
 	// 创建 SSE Handler
 	sseHandler := mcp.NewSSEHandler(func(_ *http.Request) *mcp.Server {
@@ -164,64 +476,76 @@ func (s *Server) startSSE(ctx context.Context) error {
 
 	// 设置路由
 	mux := http.NewServeMux()
-	mux.Handle("/sse", sseHandler)
-	mux.Handle("/message", sseHandler)
+	mux.Handle(s.routePath("/sse"), sseHandler)
+	mux.Handle(s.routePath("/message"), sseHandler)
 
-	// 创建 HTTP 服务器
-	httpServer := &http.Server{
-		Addr:    addr,
-		Handler: mux,
+	handler, err := s.wrapHTTPHandler(mux)
+	if err != nil {
+		return err
 	}
 
-	// 启动服务器
-	go func() {
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("SSE server error: %v\n", err)
-		}
-	}()
+	// 创建 HTTP 服务器
+	httpServer := s.newHTTPServer(addr, s.withWebhooks(handler))
 
-	// 等待上下文取消
-	<-ctx.Done()
-	// 使用独立超时上下文进行优雅关闭，避免直接传入已取消的 ctx 导致返回 context canceled
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	return httpServer.Shutdown(shutdownCtx)
+	return serveHTTP(ctx, httpServer)
 }
 
 // startStreamableHTTP 启动 Streamable HTTP 传输
 func (s *Server) startStreamableHTTP(ctx context.Context) error {
-	addr := fmt.Sprintf(":%d", s.config.Port)
-
-	// 创建 Streamable HTTP Handler
-	httpHandler := mcp.NewStreamableHTTPHandler(func(_ *http.Request) *mcp.Server {
-		return s.server
-	}, nil)
+	addr := ListenAddr(s.config.Host, s.config.Port)
 
-	// 设置路由
-	mux := http.NewServeMux()
-	mux.Handle("/mcp", httpHandler)
+	handler, err := s.wrapHTTPHandler(s.streamableMux())
+	if err != nil {
+		return err
+	}
 
 	// 创建 HTTP 服务器
-	httpServer := &http.Server{
-		Addr:    addr,
-		Handler: mux,
-	}
+	httpServer := s.newHTTPServer(addr, s.withWebhooks(handler))
 
-	// 启动服务器
-	go func() {
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("Streamable HTTP server error: %v\n", err)
-		}
-	}()
+	return serveHTTP(ctx, httpServer)
+}
 
-	// 等待上下文取消
-	<-ctx.Done()
+// serveHTTP 启动 HTTP 服务并阻塞到上下文取消；监听失败（如端口被占用）时立即返回错误，
+// 多传输模式下由 startTransports 停止其余传输并把错误交给 Start 的调用方
+func serveHTTP(ctx context.Context, httpServer *http.Server) error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- httpServer.ListenAndServe() }()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
 	// 使用独立超时上下文进行优雅关闭，避免直接传入已取消的 ctx 导致返回 context canceled
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	return httpServer.Shutdown(shutdownCtx)
 }
 
+// streamableMux 构建 streamable 模式的路由：/mcp 端点，以及按需提供的旧版 SSE 端点；均挂载在 mcp.http.base_path 之下
+func (s *Server) streamableMux() *http.ServeMux {
+	// 创建 Streamable HTTP Handler；配置事件存储后服务端为每条 SSE 消息分配事件 ID，支持断线续传
+	httpHandler := mcp.NewStreamableHTTPHandler(func(_ *http.Request) *mcp.Server {
+		return s.server
+	}, &mcp.StreamableHTTPOptions{SessionTimeout: s.config.SessionIdleTimeout, EventStore: s.eventStore})
+
+	// 设置路由
+	mux := http.NewServeMux()
+	mux.Handle(s.routePath("/mcp"), httpHandler)
+	// 兼容只支持旧版 HTTP+SSE 传输的客户端
+	if s.legacySSEEnabled() {
+		sseHandler := mcp.NewSSEHandler(func(_ *http.Request) *mcp.Server {
+			return s.server
+		}, nil)
+		mux.Handle(s.routePath("/sse"), sseHandler)
+		mux.Handle(s.routePath("/message"), sseHandler)
+	}
+	return mux
+}
+
 // registerTools 注册所有工具
 func (s *Server) registerTools() {
 	// 任务管理工具
@@ -236,6 +560,12 @@ func (s *Server) registerTools() {
 	// 项目管理工具
 	s.registerProjectTools()
 
+	// 时间记录工具
+	s.registerTimeTrackingTools()
+
+	// 任务模板工具
+	s.registerTemplateTools()
+
 	// 提示词工具
 	s.registerPromptTools()
 
@@ -247,18 +577,35 @@ func (s *Server) registerTools() {
 
 	// 元信息工具（版本/能力）
 	s.registerMetaTools()
+
+	// 按 Provider 状态注册可用工具
+	s.RefreshTools()
 }
 
 // registerTaskTools 注册任务管理工具
 func (s *Server) registerTaskTools() {
 	// 列出任务工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "list_tasks",
 		Description: "列出任务，支持来源、清单、状态、优先级、时间范围、query 文本等复杂过滤",
 		InputSchema: json.RawMessage(`{
 			"type": "object",
 			"properties": {
 				"source": {"type": "string", "description": "按来源筛选（支持简写：g/ms/tick/todo）"},
+				"adapter": {
+					"oneOf": [
+						{"type": "string"},
+						{"type": "array", "items": {"type": "string"}}
+					],
+					"description": "按 provider 筛选，可指定多个（与 source 等价）"
+				},
+				"project": {
+					"oneOf": [
+						{"type": "string"},
+						{"type": "array", "items": {"type": "string"}}
+					],
+					"description": "按项目 ID 或名称筛选"
+				},
 				"list_id": {
 					"oneOf": [
 						{"type": "string"},
@@ -311,10 +658,12 @@ func (s *Server) registerTaskTools() {
 				"due_before": {"type": "string", "description": "截止日期上限，格式 YYYY-MM-DD"},
 				"due_after": {"type": "string", "description": "截止日期下限，格式 YYYY-MM-DD"},
 				"query": {"type": "string", "description": "关键词/自然语言文本过滤"},
-				"limit": {"type": "integer", "description": "返回条数上限"},
+				"limit": {"type": "integer", "description": "返回条数上限（每页条数）"},
 				"offset": {"type": "integer", "description": "分页偏移量"},
-				"order_by": {"type": "string", "description": "排序字段：due_date/priority/created_at/updated_at"},
-				"order_desc": {"type": "boolean", "description": "是否降序排序"},
+				"cursor": {"type": "string", "description": "分页游标：首次传空字符串（未指定 limit 时每页 50 条），之后传 meta.next_cursor"},
+				"sort": {"type": "string", "description": "排序：逗号分隔的 due_date/priority/created_at/updated_at/title/quadrant/status，可加 :desc 或 - 前缀，默认 updated_at:desc"},
+				"order_by": {"type": "string", "description": "排序字段（旧参数，建议使用 sort）"},
+				"order_desc": {"type": "boolean", "description": "是否降序排序（配合 order_by）"},
 				"detail": {"type": "string", "description": "返回字段级别：compact/full，默认 compact"},
 				"include_meta": {"type": "boolean", "description": "是否返回 meta 信息（包含过滤条件与统计）"}
 			}
@@ -322,7 +671,7 @@ func (s *Server) registerTaskTools() {
 	}, s.handleListTasks)
 
 	// 列出清单工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "list_task_lists",
 		Description: "列出任务清单，包含 provider/list_id/list_name/task_count_local",
 		InputSchema: json.RawMessage(`{
@@ -333,8 +682,58 @@ func (s *Server) registerTaskTools() {
 		}`),
 	}, s.handleListTaskLists)
 
+	// 获取任务详情工具
+	s.addTool(&mcp.Tool{
+		Name:        "get_task",
+		Description: "获取单个任务详情：默认返回本地缓存，refresh 为 true 或本地无缓存时根据任务 ID（或 source 参数）定位 provider 拉取最新数据（不写回本地缓存），返回可读文本与结构化 JSON",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "description": "任务 ID（本地 ID 或远端原始 ID）"},
+				"source": {"type": "string", "description": "任务来源（支持简写：g/ms/tick/todo），默认从任务推断"},
+				"list_id": {"type": "string", "description": "远端清单 ID（本地无缓存时用于定位）"},
+				"refresh": {"type": "boolean", "description": "是否从远端拉取最新数据（默认 false，本地无缓存时总是拉取）"}
+			},
+			"required": ["id"]
+		}`),
+	}, s.handleGetTask)
+
+	// 任务历史工具
+	s.addTool(&mcp.Tool{
+		Name:        "task_history",
+		Description: "查询任务的字段变化历史（写类工具、同步、webhook、镜像与轮询记录的修改），最近的在前，用于回答“这个任务上周改了什么”",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "description": "任务 ID（本地 ID 或远端原始 ID）"},
+				"source": {"type": "string", "description": "任务来源（支持简写：g/ms/tick/todo）"},
+				"since": {"type": "string", "description": "起始时间：RFC3339、YYYY-MM-DD 或多久之前（如 7d、36h）"},
+				"until": {"type": "string", "description": "结束时间，格式同 since"},
+				"limit": {"type": "integer", "description": "最多返回的记录数（默认 50，0 不限）"}
+			},
+			"required": ["id"]
+		}`),
+	}, s.handleTaskHistory)
+
+	// 语义搜索工具
+	s.addTool(&mcp.Tool{
+		Name:        "semantic_search",
+		Description: "按语义搜索本地任务（基于任务标题与描述的向量），适合“找出与 Q3 预算有关的任务”这类不确定关键字的查询；结果按相似度从高到低排列",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"query": {"type": "string", "description": "自然语言查询"},
+				"source": {"type": "string", "description": "按来源筛选（支持简写：g/ms/tick/todo）"},
+				"limit": {"type": "integer", "description": "最多返回的任务数（默认 10）"},
+				"min_score": {"type": "number", "description": "最低相似度（-1 到 1），低于该值的结果不返回"},
+				"include_completed": {"type": "boolean", "description": "是否包含已完成与已取消的任务（默认 false）"}
+			},
+			"required": ["query"]
+		}`),
+	}, s.handleSemanticSearch)
+
 	// 创建任务工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "create_task",
 		Description: "创建新任务",
 		InputSchema: json.RawMessage(`{
@@ -350,10 +749,113 @@ func (s *Server) registerTaskTools() {
 		}`),
 	}, s.handleCreateTask)
 
+	// 快速添加工具
+	s.addTool(&mcp.Tool{
+		Name:        "quick_add",
+		Description: "用一句话快速创建任务，支持 Todoist 风格语法：#项目 @标签 p1-p4 优先级 以及 today/tomorrow/明天/周五/next monday/9am 等日期时间",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"text": {"type": "string", "description": "任务描述，如 \"Pay rent tomorrow 9am #finance p1 @home\""},
+				"provider": {"type": "string", "description": "目标 Provider（默认 local，仅保存本地）"},
+				"list_id": {"type": "string", "description": "远端清单 ID（默认按 #项目 匹配清单名称）"},
+				"timezone": {"type": "string", "description": "解析日期使用的时区（默认使用配置时区）"},
+				"enrich": {"type": "boolean", "description": "借助客户端 sampling 整理杂乱输入，生成标题、描述与建议标签（默认 false）"},
+				"dry_run": {"type": "boolean", "description": "仅返回解析结果，不创建任务"}
+			},
+			"required": ["text"]
+		}`),
+	}, s.handleQuickAdd)
+
+	// Markdown 清单批量创建工具
+	s.addTool(&mcp.Tool{
+		Name:        "create_tasks_from_markdown",
+		Description: "将 Markdown 列表/清单（- item、1. item、- [ ] item）逐行创建为任务，缩进层级保留为子任务；适合把会议纪要一次性转成待办",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"markdown": {"type": "string", "description": "Markdown 列表文本"},
+				"provider": {"type": "string", "description": "目标 Provider（默认 local，仅保存本地）"},
+				"list_id": {"type": "string", "description": "远端清单 ID（默认使用 provider 默认清单）"},
+				"parse_syntax": {"type": "boolean", "description": "按 quick_add 语法解析每行的 #项目 @标签 p1-p4 与日期（默认 false）"},
+				"skip_completed": {"type": "boolean", "description": "跳过已勾选的 [x] 项及其子项（默认 false，导入为已完成）"},
+				"max_tasks": {"type": "integer", "description": "最多创建任务数（默认 200，上限 500）"},
+				"timezone": {"type": "string", "description": "解析日期使用的时区"},
+				"dry_run": {"type": "boolean", "description": "仅返回解析结果，不创建任务"}
+			},
+			"required": ["markdown"]
+		}`),
+	}, s.handleCreateTasksFromMarkdown)
+
+	// 任务导出工具
+	s.addTool(&mcp.Tool{
+		Name:        "export_tasks",
+		Description: "按项目或过滤条件导出任务为 CSV/JSON/Markdown/iCal(VTODO)，以嵌入资源返回，便于客户端保存或附加",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"format": {"type": "string", "enum": ["csv", "json", "markdown", "ical"], "description": "导出格式（默认 json）"},
+				"project": {
+					"oneOf": [
+						{"type": "string"},
+						{"type": "array", "items": {"type": "string"}}
+					],
+					"description": "按项目 ID 或名称筛选"
+				},
+				"source": {"type": "string", "description": "按来源筛选"},
+				"list_id": {
+					"oneOf": [
+						{"type": "string"},
+						{"type": "array", "items": {"type": "string"}}
+					],
+					"description": "按清单 ID 筛选"
+				},
+				"status": {
+					"oneOf": [
+						{"type": "string"},
+						{"type": "array", "items": {"type": "string"}}
+					],
+					"description": "按状态筛选"
+				},
+				"tag": {
+					"oneOf": [
+						{"type": "string"},
+						{"type": "array", "items": {"type": "string"}}
+					],
+					"description": "按标签筛选"
+				},
+				"due_before": {"type": "string", "description": "截止日期早于 (YYYY-MM-DD)"},
+				"due_after": {"type": "string", "description": "截止日期晚于 (YYYY-MM-DD)"},
+				"query": {"type": "string", "description": "标题/描述关键字"},
+				"sort": {"type": "string", "description": "排序，格式同 list_tasks（默认 due_date,priority:desc）"},
+				"filename": {"type": "string", "description": "资源文件名（默认 tasks-<时间戳>.<扩展名>）"},
+				"as_blob": {"type": "boolean", "description": "以 base64 blob 而非文本返回资源内容"}
+			}
+		}`),
+	}, s.handleExportTasks)
+
+	// 任务导入工具
+	s.addTool(&mcp.Tool{
+		Name:        "import_tasks",
+		Description: "导入 CSV/JSON/todo.txt 内容并批量创建任务，可推送到目标 Provider；dry_run 仅报告将创建的任务",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"content": {"type": "string", "description": "导入内容（CSV 需包含 title 列；JSON 为任务数组或 {\"tasks\": [...]}；todo.txt 每行一个任务）"},
+				"format": {"type": "string", "enum": ["csv", "json", "todotxt"], "description": "内容格式（默认自动识别）"},
+				"provider": {"type": "string", "description": "目标 Provider（默认 local，仅保存本地）"},
+				"list_id": {"type": "string", "description": "远端清单 ID（默认使用 provider 默认清单）"},
+				"max_tasks": {"type": "integer", "description": "最多导入任务数（默认 200，上限 500）"},
+				"dry_run": {"type": "boolean", "description": "仅报告将创建的任务，不写入"}
+			},
+			"required": ["content"]
+		}`),
+	}, s.handleImportTasks)
+
 	// 更新任务工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "update_task",
-		Description: "更新现有任务",
+		Description: "更新现有任务；来自 Provider 的任务同时回写远端，Provider 不可达时加入离线队列，恢复连接后重放",
 		InputSchema: json.RawMessage(`{
 			"type": "object",
 			"properties": {
@@ -366,9 +868,9 @@ func (s *Server) registerTaskTools() {
 	}, s.handleUpdateTask)
 
 	// 删除任务工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "delete_task",
-		Description: "删除任务",
+		Description: "删除任务（客户端支持 elicitation 时会先请求用户确认）",
 		InputSchema: json.RawMessage(`{
 			"type": "object",
 			"properties": {
@@ -379,9 +881,9 @@ func (s *Server) registerTaskTools() {
 	}, s.handleDeleteTask)
 
 	// 完成任务工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "complete_task",
-		Description: "将任务标记为已完成",
+		Description: "将任务标记为已完成；来自 Provider 的任务同时回写远端，Provider 不可达时加入离线队列，恢复连接后重放",
 		InputSchema: json.RawMessage(`{
 			"type": "object",
 			"properties": {
@@ -390,28 +892,198 @@ func (s *Server) registerTaskTools() {
 			"required": ["id"]
 		}`),
 	}, s.handleCompleteTask)
+
+	// 逾期任务工具
+	s.addTool(&mcp.Tool{
+		Name:        "overdue_tasks",
+		Description: "按来源与清单分组列出逾期任务，并按逾期时长分桶（1_day/2_7_days/8_30_days/over_30_days）",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"source": {"type": "string", "description": "按来源筛选（支持简写）"},
+				"list_id": {
+					"oneOf": [
+						{"type": "string"},
+						{"type": "array", "items": {"type": "string"}}
+					],
+					"description": "按清单 ID 筛选"
+				},
+				"include_tasks": {"type": "boolean", "description": "是否返回每组的任务明细（默认 true）"},
+				"limit_per_group": {"type": "integer", "description": "每组最多返回任务数（默认 20）"}
+			}
+		}`),
+	}, s.handleOverdueTasks)
+
+	// 推迟任务工具
+	s.addTool(&mcp.Tool{
+		Name:        "snooze_task",
+		Description: "推迟任务截止时间：按时长（30m/2h/3d/1w）或命名时间槽（tomorrow morning/next week），并按 provider 规则回写",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"task_id": {"type": "string", "description": "任务 ID"},
+				"duration": {"type": "string", "description": "推迟时长，如 30m/2h/3d/1w；只保存日期的 provider（如 Google）至少推迟到下一天"},
+				"until": {"type": "string", "description": "命名时间槽（later_today/tomorrow_morning/tomorrow_afternoon/this_weekend/next_week/next_month）或 YYYY-MM-DD/RFC3339"},
+				"timezone": {"type": "string", "description": "解析时间槽使用的时区（默认本地）"},
+				"sync_remote": {"type": "boolean", "description": "是否回写到 provider（默认 true）"},
+				"dry_run": {"type": "boolean", "description": "仅预览，不保存"}
+			},
+			"required": ["task_id"]
+		}`),
+	}, s.handleSnoozeTask)
+
+	// 任务排序工具
+	s.addTool(&mcp.Tool{
+		Name:        "reorder_tasks",
+		Description: "调整清单内同级任务的顺序（如移到顶部），支持的 provider（Google Tasks、Todoist）会同步远端排序",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"task_id": {"type": "string", "description": "要移动的任务 ID"},
+				"position": {
+					"oneOf": [
+						{"type": "integer"},
+						{"type": "string", "enum": ["top", "bottom"]}
+					],
+					"description": "目标位置：top/bottom 或从 1 开始的序号（默认 top）"
+				},
+				"before": {"type": "string", "description": "移动到该任务之前"},
+				"after": {"type": "string", "description": "移动到该任务之后"},
+				"task_ids": {
+					"type": "array",
+					"items": {"type": "string"},
+					"description": "按给定顺序排列这些任务（置于清单最前，其余保持原顺序）"
+				},
+				"dry_run": {"type": "boolean", "description": "仅预览新顺序"}
+			}
+		}`),
+	}, s.handleReorderTasks)
+
+	// 任务依赖工具
+	s.addTool(&mcp.Tool{
+		Name:        "add_blocker",
+		Description: "记录“blocker_id 阻塞 task_id”的依赖关系（remove=true 时移除），支持原生依赖的 provider 同步写入远端",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"task_id": {"type": "string", "description": "被阻塞的任务 ID"},
+				"blocker_id": {"type": "string", "description": "阻塞它的任务 ID"},
+				"remove": {"type": "boolean", "description": "移除该依赖关系"}
+			},
+			"required": ["task_id", "blocker_id"]
+		}`),
+	}, s.handleAddBlocker)
+
+	s.addTool(&mcp.Tool{
+		Name:        "list_blockers",
+		Description: "列出任务的阻塞项与其阻塞的后续任务；不传 task_id 时列出全部依赖关系",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"task_id": {"type": "string", "description": "任务 ID（可选）"}
+			}
+		}`),
+	}, s.handleListBlockers)
+
+	s.addTool(&mcp.Tool{
+		Name:        "ready_tasks",
+		Description: "列出所有阻塞项均已完成、可以立即开始的任务（按优先级与截止日期排序）",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"source": {"type": "string", "description": "按来源筛选（支持简写：g/ms/tick/todo）"},
+				"limit": {"type": "integer", "description": "返回条数上限"}
+			}
+		}`),
+	}, s.handleReadyTasks)
+
+	// 日程规划工具
+	s.addTool(&mcp.Tool{
+		Name:        "plan_day",
+		Description: "根据工作时间与所有来源合并后的待办生成时间块日程，可选回写为截止时间或时间块任务",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"date": {"type": "string", "description": "规划日期 YYYY-MM-DD（默认今天）"},
+				"start": {"type": "string", "description": "工作开始时间 HH:MM（默认 09:00）"},
+				"end": {"type": "string", "description": "工作结束时间 HH:MM（默认 18:00）"},
+				"breaks": {
+					"type": "array",
+					"items": {
+						"type": "object",
+						"properties": {
+							"start": {"type": "string"},
+							"end": {"type": "string"},
+							"title": {"type": "string"}
+						},
+						"required": ["start", "end"]
+					},
+					"description": "休息/会议等不可安排的时间段"
+				},
+				"timezone": {"type": "string", "description": "时区（默认使用智能治理配置）"},
+				"source": {"type": "string", "description": "只规划某个来源的任务（支持简写）"},
+				"default_minutes": {"type": "integer", "description": "无预估时间任务的默认时长（默认 30）"},
+				"buffer_minutes": {"type": "integer", "description": "时间块之间的缓冲（默认 5）"},
+				"max_tasks": {"type": "integer", "description": "最多安排的任务数"},
+				"write_back": {"type": "string", "enum": ["none", "due_times", "tasks"], "description": "回写方式：none 仅预览；due_times 更新任务截止时间；tasks 创建时间块任务"},
+				"provider": {"type": "string", "description": "write_back=tasks 时推送时间块任务的 provider"},
+				"list_id": {"type": "string", "description": "推送时间块任务的目标清单 ID"},
+				"dry_run": {"type": "boolean", "description": "只返回日程，不回写"}
+			}
+		}`),
+	}, s.handlePlanDay)
 }
 
 // registerAnalysisTools 注册分析工具
 func (s *Server) registerAnalysisTools() {
 	// 四象限分析工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "analyze_quadrant",
 		Description: "按四象限（艾森豪威尔矩阵）分析任务分布",
 		InputSchema: json.RawMessage(`{"type": "object"}`),
 	}, s.handleAnalyzeQuadrant)
 
 	// 优先级分析工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "analyze_priority",
 		Description: "按优先级分析任务分布",
 		InputSchema: json.RawMessage(`{"type": "object"}`),
 	}, s.handleAnalyzePriority)
+
+	// 任务统计工具
+	s.addTool(&mcp.Tool{
+		Name:        "task_statistics",
+		Description: "基于本地缓存统计任务数量与趋势：每日新建/完成、逾期占比、按项目与来源分组",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"window_days": {"type": "integer", "description": "统计窗口天数（默认 14，最大 90）"},
+				"source": {"type": "string", "description": "按来源筛选"},
+				"timezone": {"type": "string", "description": "按日聚合使用的时区（默认取智能治理配置）"}
+			}
+		}`),
+	}, s.handleTaskStatistics)
+
+	// 任务摘要工具
+	s.addTool(&mcp.Tool{
+		Name:        "summarize_tasks",
+		Description: "生成当日任务或某个项目的简明摘要（数量、优先处理任务、风险），可直接放入回复",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"scope": {"type": "string", "enum": ["today", "project"], "description": "摘要范围（默认 today；传 project 参数时为 project）"},
+				"project": {"type": "string", "description": "项目 ID 或名称"},
+				"source": {"type": "string", "description": "按来源筛选（支持简写：g/ms/tick/todo）"},
+				"top": {"type": "integer", "description": "列出的优先任务数量（默认 3）"},
+				"timezone": {"type": "string", "description": "时区（默认使用智能治理配置）"}
+			}
+		}`),
+	}, s.handleSummarizeTasks)
 }
 
 // registerIntelligenceTools 注册智能治理工具
 func (s *Server) registerIntelligenceTools() {
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "analyze_overdue_health",
 		Description: "分析逾期任务健康度，输出过载风险、候选处理动作与提问建议",
 		InputSchema: json.RawMessage(`{
@@ -430,7 +1102,7 @@ func (s *Server) registerIntelligenceTools() {
 		}`),
 	}, s.handleAnalyzeOverdueHealth)
 
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "resolve_overdue_tasks",
 		Description: "批量处理逾期任务（延期/重排/删除/标记拆分）",
 		InputSchema: json.RawMessage(`{
@@ -455,7 +1127,7 @@ func (s *Server) registerIntelligenceTools() {
 		}`),
 	}, s.handleResolveOverdueTasks)
 
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "rebalance_longterm_tasks",
 		Description: "根据短期任务负载自动调配长期无排期任务",
 		InputSchema: json.RawMessage(`{
@@ -474,7 +1146,7 @@ func (s *Server) registerIntelligenceTools() {
 		}`),
 	}, s.handleRebalanceLongTermTasks)
 
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "detect_decomposition_candidates",
 		Description: "识别复杂/抽象且缺少子任务的候选任务，给出拆分建议",
 		InputSchema: json.RawMessage(`{
@@ -486,7 +1158,7 @@ func (s *Server) registerIntelligenceTools() {
 		}`),
 	}, s.handleDetectDecompositionCandidates)
 
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "decompose_task_with_provider",
 		Description: "基于 provider 能力将任务拆分为子任务建议，并可选落地写入",
 		InputSchema: json.RawMessage(`{
@@ -501,7 +1173,33 @@ func (s *Server) registerIntelligenceTools() {
 		}`),
 	}, s.handleDecomposeTaskWithProvider)
 
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
+		Name:        "breakdown_task",
+		Description: "借助客户端 sampling 将大任务拆解为子任务，经 elicitation 勾选确认后在任务来源 provider 中创建",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"task_id": {"type": "string", "description": "待拆解任务 ID"},
+				"max_subtasks": {"type": "integer", "description": "子任务数量上限（默认 5，最多 12）"},
+				"guidance": {"type": "string", "description": "给拆解模型的额外要求"},
+				"subtasks": {
+					"type": "array",
+					"items": {
+						"oneOf": [
+							{"type": "string"},
+							{"type": "object", "properties": {"title": {"type": "string"}, "description": {"type": "string"}, "estimate_minutes": {"type": "integer"}, "due_offset_days": {"type": "integer"}}, "required": ["title"]}
+						]
+					},
+					"description": "直接指定要创建的子任务（跳过 sampling 与确认）"
+				},
+				"auto_confirm": {"type": "boolean", "description": "跳过 elicitation，创建全部建议子任务"},
+				"dry_run": {"type": "boolean", "description": "仅返回建议与选择结果，不创建"}
+			},
+			"required": ["task_id"]
+		}`),
+	}, s.handleBreakdownTask)
+
+	s.addTool(&mcp.Tool{
 		Name:        "analyze_achievement",
 		Description: "分析完成情况并输出成就反馈（趋势、连续性、徽章）",
 		InputSchema: json.RawMessage(`{
@@ -512,12 +1210,55 @@ func (s *Server) registerIntelligenceTools() {
 			}
 		}`),
 	}, s.handleAnalyzeAchievement)
+
+	// 重复任务检测与合并工具
+	s.addTool(&mcp.Tool{
+		Name:        "find_duplicates",
+		Description: "检测疑似重复任务（标题模糊匹配 + 截止日期相近），支持跨来源比较",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"source": {
+					"oneOf": [
+						{"type": "string"},
+						{"type": "array", "items": {"type": "string"}}
+					],
+					"description": "按来源筛选；不传则跨所有来源检测"
+				},
+				"list_id": {
+					"oneOf": [
+						{"type": "string"},
+						{"type": "array", "items": {"type": "string"}}
+					],
+					"description": "按清单 ID 筛选"
+				},
+				"threshold": {"type": "number", "description": "标题相似度阈值 0-1（默认 0.8）"},
+				"due_window_days": {"type": "integer", "description": "截止日期相差天数上限（默认 1）"},
+				"include_completed": {"type": "boolean", "description": "是否包含已完成/已取消任务"}
+			}
+		}`),
+	}, s.handleFindDuplicates)
+
+	s.addTool(&mcp.Tool{
+		Name:        "merge_tasks",
+		Description: "将重复任务合并到主任务（合并描述/标签/子任务，取最早截止日期与最高优先级）；来自 provider 的任务同时回写远端",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"primary_id": {"type": "string", "description": "保留的主任务 ID"},
+				"duplicate_ids": {"type": "array", "items": {"type": "string"}, "description": "要合并的重复任务 ID"},
+				"delete_duplicates": {"type": "boolean", "description": "是否删除重复任务（同时删除远端任务；默认在本地与远端标记为已完成并记录 tb_merged_into）"},
+				"dry_run": {"type": "boolean", "description": "仅预览合并结果"}
+			},
+			"required": ["primary_id", "duplicate_ids"]
+		}`),
+	}, s.handleMergeTasks)
 }
 
 // registerProjectTools 注册项目管理工具
 func (s *Server) registerProjectTools() {
 	// 创建项目工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "create_project",
 		Description: "创建新项目（草稿状态）",
 		InputSchema: json.RawMessage(`{
@@ -536,7 +1277,7 @@ func (s *Server) registerProjectTools() {
 	}, s.handleCreateProject)
 
 	// 列出项目工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "list_projects",
 		Description: "列出所有项目",
 		InputSchema: json.RawMessage(`{
@@ -548,7 +1289,7 @@ func (s *Server) registerProjectTools() {
 	}, s.handleListProjects)
 
 	// 拆分项目工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "split_project",
 		Description: "使用 AI 辅助将项目拆分为子任务",
 		InputSchema: json.RawMessage(`{
@@ -577,7 +1318,7 @@ func (s *Server) registerProjectTools() {
 	}, s.handleSplitProject)
 
 	// Markdown 拆分项目工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "split_project_from_markdown",
 		Description: "将 Markdown 列表任务树解析为可确认的任务预览（含稳定任务 ID）",
 		InputSchema: json.RawMessage(`{
@@ -593,7 +1334,7 @@ func (s *Server) registerProjectTools() {
 	}, s.handleSplitProjectFromMarkdown)
 
 	// 确认项目工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "confirm_project",
 		Description: "确认项目，准备同步",
 		InputSchema: json.RawMessage(`{
@@ -608,7 +1349,7 @@ func (s *Server) registerProjectTools() {
 	}, s.handleConfirmProject)
 
 	// 同步项目工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "sync_project",
 		Description: "同步项目到指定平台",
 		InputSchema: json.RawMessage(`{
@@ -622,10 +1363,128 @@ func (s *Server) registerProjectTools() {
 	}, s.handleSyncProject)
 }
 
+// registerTimeTrackingTools 注册时间记录工具
+func (s *Server) registerTimeTrackingTools() {
+	s.addTool(&mcp.Tool{
+		Name:        "start_timer",
+		Description: "为任务启动计时器（默认先停止其他运行中的计时器）",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"task_id": {"type": "string", "description": "任务 ID"},
+				"note": {"type": "string", "description": "备注"},
+				"stop_running": {"type": "boolean", "description": "是否先停止其他运行中的计时器（默认 true）"}
+			},
+			"required": ["task_id"]
+		}`),
+	}, s.handleStartTimer)
+
+	s.addTool(&mcp.Tool{
+		Name:        "stop_timer",
+		Description: "停止计时器并把时长累加到任务 actual_minutes，返回任务累计耗时",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"task_id": {"type": "string", "description": "任务 ID（不传则停止最近启动的计时器）"},
+				"note": {"type": "string", "description": "备注"}
+			}
+		}`),
+	}, s.handleStopTimer)
+
+	s.addTool(&mcp.Tool{
+		Name:        "log_time",
+		Description: "手动补录任务耗时，返回任务累计耗时",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"task_id": {"type": "string", "description": "任务 ID"},
+				"minutes": {"type": "integer", "description": "耗时（分钟）"},
+				"duration": {"type": "string", "description": "耗时，Go duration 格式，如 1h30m"},
+				"date": {"type": "string", "description": "记录日期 YYYY-MM-DD（默认今天）"},
+				"note": {"type": "string", "description": "备注"}
+			},
+			"required": ["task_id"]
+		}`),
+	}, s.handleLogTime)
+
+	s.addTool(&mcp.Tool{
+		Name:        "time_report",
+		Description: "查询时间记录：按任务和/或时间范围返回记录明细、每个任务的累计耗时与按天汇总（只读，运行中的计时器按当前时间计算），用于回答“在某个任务上花了多久”",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"task_id": {"type": "string", "description": "任务 ID（不传则统计全部任务）"},
+				"since": {"type": "string", "description": "按记录开始时间筛选的起点：RFC3339、YYYY-MM-DD 或多久之前（如 7d、36h）"},
+				"until": {"type": "string", "description": "按记录开始时间筛选的终点（不含），格式同 since"}
+			}
+		}`),
+	}, s.handleTimeReport)
+}
+
+// registerTemplateTools 注册任务模板工具
+func (s *Server) registerTemplateTools() {
+	s.addTool(&mcp.Tool{
+		Name:        "save_template",
+		Description: "保存可复用的多任务模板（如发布检查清单），可直接传 tasks 或从已有任务（含子任务）抓取",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "description": "模板名称（唯一，忽略大小写）"},
+				"description": {"type": "string", "description": "模板描述"},
+				"tasks": {
+					"type": "array",
+					"items": {
+						"type": "object",
+						"properties": {
+							"key": {"type": "string", "description": "模板内任务标识"},
+							"parent_key": {"type": "string", "description": "父任务标识（须在前面出现）"},
+							"title": {"type": "string", "description": "标题，支持 {{变量}}"},
+							"description": {"type": "string"},
+							"due_offset_days": {"type": "integer", "description": "相对 start_date 的截止偏移天数"},
+							"estimate_minutes": {"type": "integer"},
+							"priority": {"type": "integer"},
+							"quadrant": {"type": "integer"},
+							"tags": {"type": "array", "items": {"type": "string"}}
+						},
+						"required": ["title"]
+					},
+					"description": "模板任务列表"
+				},
+				"task_ids": {"type": "array", "items": {"type": "string"}, "description": "从已有任务抓取模板（tasks 为空时生效）"},
+				"overwrite": {"type": "boolean", "description": "同名模板存在时是否覆盖"}
+			},
+			"required": ["name"]
+		}`),
+	}, s.handleSaveTemplate)
+
+	s.addTool(&mcp.Tool{
+		Name:        "list_templates",
+		Description: "列出已保存的任务模板及其变量",
+		InputSchema: json.RawMessage(`{"type": "object", "properties": {}}`),
+	}, s.handleListTemplates)
+
+	s.addTool(&mcp.Tool{
+		Name:        "instantiate_template",
+		Description: "将模板展开为任务（保留父子关系），可选推送到指定 provider",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"template": {"type": "string", "description": "模板 ID 或名称"},
+				"start_date": {"type": "string", "description": "基准日期 YYYY-MM-DD（默认今天）"},
+				"variables": {"type": "object", "additionalProperties": {"type": "string"}, "description": "模板变量"},
+				"provider": {"type": "string", "description": "目标 provider（不传则仅写入本地）"},
+				"list_id": {"type": "string", "description": "目标清单 ID（默认 provider 默认清单）"},
+				"dry_run": {"type": "boolean", "description": "仅预览展开结果"}
+			},
+			"required": ["template"]
+		}`),
+	}, s.handleInstantiateTemplate)
+}
+
 // registerPromptTools 注册提示词工具
 func (s *Server) registerPromptTools() {
 	// 获取提示词工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "get_prompt",
 		Description: "获取内置提示词模板",
 		InputSchema: json.RawMessage(`{
@@ -641,7 +1500,7 @@ func (s *Server) registerPromptTools() {
 // registerSyncTools 注册同步工具
 func (s *Server) registerSyncTools() {
 	// 推送同步工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "sync_push",
 		Description: "推送本地任务到远程平台，可选择删除远程多余任务",
 		InputSchema: json.RawMessage(`{
@@ -656,7 +1515,7 @@ func (s *Server) registerSyncTools() {
 	}, s.handleSyncPush)
 
 	// 拉取同步工具
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "sync_pull",
 		Description: "从远程平台拉取任务到本地",
 		InputSchema: json.RawMessage(`{
@@ -667,19 +1526,69 @@ func (s *Server) registerSyncTools() {
 			"required": ["provider"]
 		}`),
 	}, s.handleSyncPull)
+
+	// 立即同步工具（同步引擎）
+	s.addTool(&mcp.Tool{
+		Name:        "sync_now",
+		Description: "通过同步引擎立即同步：source=provider 为拉取到本地，target=provider 为推送本地任务，source 与 target 相同为双向同步；均省略时对所有已认证 Provider 双向同步",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"source": {"type": "string", "description": "来源（provider 名称/简写或 local）"},
+				"target": {"type": "string", "description": "目标（provider 名称/简写或 local，默认 local）"},
+				"dry_run": {"type": "boolean", "description": "模拟执行，只统计不修改"},
+				"delete": {"type": "boolean", "description": "推送时删除远程存在但本地不存在的任务"}
+			}
+		}`),
+	}, s.handleSyncNow)
+
+	// 同步状态工具
+	s.addTool(&mcp.Tool{
+		Name:        "sync_status",
+		Description: "查看同步状态：各 Provider 认证情况、最后同步时间、待推送变更数与最近一次 sync_now 结果，以及各镜像对最近一次镜像的时间、变更数、待处理冲突与错误",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"provider": {"type": "string", "description": "只查看指定 provider（默认全部）"}
+			}
+		}`),
+	}, s.handleSyncStatus)
+
+	// 冲突解决工具
+	s.addTool(&mcp.Tool{
+		Name:        "resolve_conflict",
+		Description: "解决任务冲突。传 task_id 时解决任务的本地与远端版本冲突：按字段选择保留 local 或 remote，合并后保存到本地并回写远端，可配合 resolve_conflict 提示词使用；传 left、right 时处理两个 Provider 镜像时排队的冲突：不传 conflict_id 列出等待中的冲突，传 conflict_id 与 keep（Provider 名称、left 或 right）及可选的 fields 记录选择，下次镜像时将合并结果写入两侧",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"task_id": {"type": "string", "description": "冲突任务 ID（本地与远端版本冲突）"},
+				"source": {"type": "string", "description": "任务来源 Provider（默认从任务推断）"},
+				"left": {"type": "string", "description": "镜像冲突：镜像对的一侧 Provider"},
+				"right": {"type": "string", "description": "镜像冲突：镜像对的另一侧 Provider"},
+				"conflict_id": {"type": "string", "description": "镜像冲突 ID（不传时列出等待中的冲突）"},
+				"keep": {"type": "string", "description": "未在 fields 中指定的字段保留哪一侧：任务冲突为 local|remote（默认 local）；镜像冲突为 Provider 名称、left 或 right（不传时只返回冲突详情）"},
+				"fields": {
+					"type": "object",
+					"additionalProperties": {"type": "string"},
+					"description": "逐字段选择。任务冲突：title/description/status/priority/due_date/start_date/tags -> local|remote；镜像冲突：title/description/status/priority/due/tags -> Provider 名称|left|right"
+				},
+				"dry_run": {"type": "boolean", "description": "仅预览，不保存"}
+			}
+		}`),
+	}, s.handleResolveConflict)
 }
 
 // registerProviderTools 注册 Provider 工具
 func (s *Server) registerProviderTools() {
 	// 列出 Providers
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "list_providers",
 		Description: "列出所有支持的 Provider 及其状态",
 		InputSchema: json.RawMessage(`{"type": "object"}`),
 	}, s.handleListProviders)
 
 	// 获取 Provider 详情
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "get_provider_info",
 		Description: "获取指定 Provider 的详细信息和能力（支持简写：google, ms, feishu, tick, todo）",
 		InputSchema: json.RawMessage(`{
@@ -692,7 +1601,7 @@ func (s *Server) registerProviderTools() {
 	}, s.handleGetProviderInfo)
 
 	// 获取配置模板
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "get_provider_config_template",
 		Description: "获取 Provider 的配置模板，AI agent 可据此生成配置",
 		InputSchema: json.RawMessage(`{
@@ -707,11 +1616,31 @@ func (s *Server) registerProviderTools() {
 
 // registerMetaTools 注册元信息工具
 func (s *Server) registerMetaTools() {
-	s.server.AddTool(&mcp.Tool{
+	s.addTool(&mcp.Tool{
 		Name:        "get_server_info",
 		Description: "获取 MCP 服务版本、能力、工具与提示词清单，供 AI 判断可用功能",
 		InputSchema: json.RawMessage(`{"type": "object"}`),
 	}, s.handleGetServerInfo)
+
+	s.addTool(&mcp.Tool{
+		Name:        "set_context",
+		Description: "设置当前会话的默认 adapter、项目与时区，后续工具调用省略这些参数时自动使用；传空字符串清除单项，clear=true 清除全部",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"adapter": {"type": "string", "description": "默认 provider（支持简写，须已配置）"},
+				"project": {"type": "string", "description": "默认项目 ID 或名称"},
+				"timezone": {"type": "string", "description": "默认时区（IANA 名称，如 Asia/Shanghai）"},
+				"clear": {"type": "boolean", "description": "先清除全部默认值"}
+			}
+		}`),
+	}, s.handleSetContext)
+
+	s.addTool(&mcp.Tool{
+		Name:        "get_context",
+		Description: "查看当前会话通过 set_context 设置的默认参数",
+		InputSchema: json.RawMessage(`{"type": "object"}`),
+	}, s.handleGetContext)
 }
 
 // registerPrompts 注册所有提示词
@@ -785,6 +1714,83 @@ func (s *Server) registerPrompts() {
 			},
 		},
 	}, s.handleJSONQueryCommandsPrompt)
+
+	// 每周回顾提示词
+	s.server.AddPrompt(&mcp.Prompt{
+		Name:        "weekly_review",
+		Description: "每周回顾提示词 - 嵌入上周已完成与延误任务，引导 GTD 风格回顾",
+		Arguments: []*mcp.PromptArgument{
+			{
+				Name:        "adapter",
+				Description: "仅回顾指定来源（local 或 Provider 名称）",
+				Required:    false,
+			},
+			{
+				Name:        "project",
+				Description: "仅回顾指定项目（ID 或名称）",
+				Required:    false,
+			},
+		},
+	}, s.handleWeeklyReviewPrompt)
+
+	// 积压任务分诊提示词
+	s.server.AddPrompt(&mcp.Prompt{
+		Name:        "triage_backlog",
+		Description: "积压任务分诊提示词 - 嵌入最久未更新的任务，引导给出保留/委托/删除/延后决定及对应工具调用",
+		Arguments: []*mcp.PromptArgument{
+			{
+				Name:        "project",
+				Description: "要分诊的项目（ID 或名称），留空为全部任务",
+				Required:    false,
+			},
+			{
+				Name:        "limit",
+				Description: "嵌入的任务数量（默认 10，最多 50）",
+				Required:    false,
+			},
+			{
+				Name:        "adapter",
+				Description: "仅分诊指定来源（local 或 Provider 名称）",
+				Required:    false,
+			},
+		},
+	}, s.handleTriageBacklogPrompt)
+
+	// 站会摘要提示词
+	s.server.AddPrompt(&mcp.Prompt{
+		Name:        "standup_summary",
+		Description: "站会摘要提示词 - 汇总昨天完成、今天计划与阻塞任务，生成 yesterday/today/blockers 更新",
+		Arguments: []*mcp.PromptArgument{
+			{
+				Name:        "adapter",
+				Description: "仅汇总指定来源（local 或 Provider 名称）",
+				Required:    false,
+			},
+			{
+				Name:        "project",
+				Description: "仅汇总指定项目（ID 或名称）",
+				Required:    false,
+			},
+		},
+	}, s.handleStandupSummaryPrompt)
+
+	// 冲突解决提示词
+	s.server.AddPrompt(&mcp.Prompt{
+		Name:        "resolve_conflict",
+		Description: "冲突解决提示词 - 并排展示任务的本地与远端版本，引导逐字段选择后调用 resolve_conflict 工具应用",
+		Arguments: []*mcp.PromptArgument{
+			{
+				Name:        "task_id",
+				Description: "冲突任务 ID",
+				Required:    true,
+			},
+			{
+				Name:        "source",
+				Description: "任务来源 Provider（默认从任务推断）",
+				Required:    false,
+			},
+		},
+	}, s.handleResolveConflictPrompt)
 }
 
 // registerResources 注册所有资源
@@ -812,6 +1818,49 @@ func (s *Server) registerResources() {
 		Description: "所有内置提示词",
 		MIMEType:    "application/json",
 	}, s.handlePromptsResource)
+
+	// 注册服务状态资源
+	s.server.AddResource(&mcp.Resource{
+		URI:         statusResourceURI,
+		Name:        "服务状态",
+		Description: "服务版本、运行时长、Provider 健康状态、缓存新鲜度与最近同步时间",
+		MIMEType:    "application/json",
+	}, s.handleStatusResource)
+
+	// 注册参数化任务资源模板，adapter 可取 all、local 或 Provider 名称
+	s.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: taskSearchResourceTemplate,
+		Name:        "任务搜索",
+		Description: "按关键字与过滤条件搜索任务，如 task://google/search?q=report&status=todo",
+		MIMEType:    "application/json",
+	}, s.handleTaskSearchResource)
+
+	s.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: taskResourceTemplate,
+		Name:        "任务详情",
+		Description: "读取指定来源的单个任务，如 task://local/{task_id}",
+		MIMEType:    "application/json",
+	}, s.handleTaskResource)
+
+	s.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: taskAttachmentsResourceTemplate,
+		Name:        "任务附件列表",
+		Description: "列出任务的附件及其资源 URI，如 task://todoist/{task_id}/attachments",
+		MIMEType:    "application/json",
+	}, s.handleTaskAttachmentsResource)
+
+	s.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: taskHistoryResourceTemplate,
+		Name:        "任务历史",
+		Description: "任务最近的字段变化记录，如 task://google/{task_id}/history",
+		MIMEType:    "application/json",
+	}, s.handleTaskHistoryResource)
+
+	s.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: taskAttachmentResourceTemplate,
+		Name:        "任务附件内容",
+		Description: "以附件自身的 MIME 类型读取文件内容，支持 Microsoft To Do 与 Todoist",
+	}, s.handleTaskAttachmentResource)
 }
 
 // GetServer 获取底层 MCP 服务器
@@ -824,42 +1873,22 @@ func (s *Server) GetConfig() *ServerConfig {
 	return s.config
 }
 
-// GetTools 获取所有工具名称
+// GetTools 获取当前已注册（可用）的工具名称
 func (s *Server) GetTools() map[string]bool {
-	// 返回工具名称集合
-	return map[string]bool{
-		"list_tasks":                      true,
-		"list_task_lists":                 true,
-		"create_task":                     true,
-		"update_task":                     true,
-		"delete_task":                     true,
-		"complete_task":                   true,
-		"analyze_quadrant":                true,
-		"analyze_priority":                true,
-		"analyze_overdue_health":          true,
-		"resolve_overdue_tasks":           true,
-		"rebalance_longterm_tasks":        true,
-		"detect_decomposition_candidates": true,
-		"decompose_task_with_provider":    true,
-		"analyze_achievement":             true,
-		"create_project":                  true,
-		"list_projects":                   true,
-		"split_project":                   true,
-		"split_project_from_markdown":     true,
-		"confirm_project":                 true,
-		"sync_project":                    true,
-		"get_prompt":                      true,
-		"sync_push":                       true,
-		"sync_pull":                       true,
-		"list_providers":                  true,
-		"get_provider_info":               true,
-		"get_provider_config_template":    true,
-		"get_server_info":                 true,
+	s.toolMu.Lock()
+	defer s.toolMu.Unlock()
+	tools := make(map[string]bool, len(s.activeTools))
+	for name := range s.activeTools {
+		tools[name] = true
 	}
+	return tools
 }
 
 // GetPrompts 获取所有提示词名称
 func (s *Server) GetPrompts() map[string]bool {
+	if !s.promptsEnabled() {
+		return map[string]bool{}
+	}
 	// 返回提示词名称集合
 	return map[string]bool{
 		"quadrant_analysis":   true,
@@ -867,6 +1896,10 @@ func (s *Server) GetPrompts() map[string]bool {
 		"project_planning":    true,
 		"ai_split_guide":      true,
 		"json_query_commands": true,
+		"weekly_review":       true,
+		"triage_backlog":      true,
+		"standup_summary":     true,
+		"resolve_conflict":    true,
 	}
 }
 
@@ -0,0 +1,240 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectSilentClient 完成 initialize 握手后只读取消息、不响应服务端 ping，模拟断线的客户端
+func connectSilentClient(t *testing.T, s *Server) *sdkmcp.ServerSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := sdkmcp.NewInMemoryTransports()
+	session, err := s.server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("connect server: %v", err)
+	}
+	conn, err := clientTransport.Connect(ctx)
+	if err != nil {
+		t.Fatalf("connect client transport: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	id, _ := jsonrpc.MakeID(float64(1))
+	params, _ := json.Marshal(map[string]any{
+		"protocolVersion": "2025-06-18",
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "silent", "version": "0.0.1"},
+	})
+	if err := conn.Write(ctx, &jsonrpc.Request{ID: id, Method: "initialize", Params: params}); err != nil {
+		t.Fatalf("write initialize: %v", err)
+	}
+	if _, err := conn.Read(ctx); err != nil {
+		t.Fatalf("read initialize result: %v", err)
+	}
+	if err := conn.Write(ctx, &jsonrpc.Request{Method: "notifications/initialized", Params: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("write initialized: %v", err)
+	}
+	go func() {
+		for {
+			if _, err := conn.Read(ctx); err != nil {
+				return
+			}
+		}
+	}()
+	return session
+}
+
+func TestKeepAliveClosesUnresponsiveSessions(t *testing.T) {
+	s := NewServer(WithConfig(&ServerConfig{Name: "taskbridge", Transport: "streamable", KeepAlive: 40 * time.Millisecond}))
+	healthy := connectBreakdownClient(t, s, nil)
+	silent := connectSilentClient(t, s)
+
+	done := make(chan error, 1)
+	go func() { done <- silent.Wait() }()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("unresponsive session was not closed by keepalive")
+	}
+
+	// 正常响应 ping 的会话保持可用
+	if err := healthy.Ping(context.Background(), nil); err != nil {
+		t.Fatalf("healthy session should stay alive: %v", err)
+	}
+	if status := s.sessionStatus(); status["active"] != 1 || status["keep_alive"] != "40ms" {
+		t.Fatalf("unexpected session status: %v", status)
+	}
+}
+
+func TestKeepAliveDisabledForStdio(t *testing.T) {
+	s := NewServer(WithConfig(&ServerConfig{Name: "taskbridge", Transport: "stdio", KeepAlive: 20 * time.Millisecond}))
+	silent := connectSilentClient(t, s)
+
+	done := make(chan error, 1)
+	go func() { done <- silent.Wait() }()
+	select {
+	case <-done:
+		t.Fatalf("stdio sessions should not be closed by keepalive")
+	case <-time.After(150 * time.Millisecond):
+	}
+	if _, ok := s.sessionStatus()["keep_alive"]; ok {
+		t.Fatalf("keep_alive should not be reported for stdio")
+	}
+}
+
+func TestStartStdioServesOverStandardStreams(t *testing.T) {
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	origStdin, origStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdinR, stdoutW
+	t.Cleanup(func() { os.Stdin, os.Stdout = origStdin, origStdout })
+
+	s := NewServer()
+	done := make(chan error, 1)
+	go func() { done <- s.Start(context.Background()) }()
+
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "stdio-client", Version: "0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), &sdkmcp.IOTransport{Reader: stdoutR, Writer: stdinW}, nil)
+	if err != nil {
+		t.Fatalf("connect over stdio: %v", err)
+	}
+	if _, err := session.ListTools(context.Background(), nil); err != nil {
+		t.Fatalf("list tools over stdio: %v", err)
+	}
+	// 服务运行期间 os.Stdout 被重定向，误写不会混入协议流
+	if os.Stdout == stdoutW {
+		t.Fatalf("expected os.Stdout redirected while serving stdio")
+	}
+
+	// 客户端关闭 stdin 后服务退出
+	_ = session.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected clean shutdown on stdin close, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("stdio server did not exit after stdin closed")
+	}
+	if os.Stdout != stdoutW {
+		t.Fatalf("expected os.Stdout restored after serving")
+	}
+}
+
+// freePort 返回一个当前空闲的本地端口
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestStartMultipleTransports(t *testing.T) {
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	origStdin, origStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdinR, stdoutW
+	t.Cleanup(func() { os.Stdin, os.Stdout = origStdin, origStdout })
+
+	port := freePort(t)
+	s := NewServer(WithConfig(&ServerConfig{Name: "taskbridge", Transport: "stdio,streamable", Host: "127.0.0.1", Port: port}))
+	done := make(chan error, 1)
+	go func() { done <- s.Start(context.Background()) }()
+
+	ctx := context.Background()
+	stdioSession, err := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "stdio-client", Version: "0.0.1"}, nil).
+		Connect(ctx, &sdkmcp.IOTransport{Reader: stdoutR, Writer: stdinW}, nil)
+	if err != nil {
+		t.Fatalf("connect over stdio: %v", err)
+	}
+	httpClient := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "http-client", Version: "0.0.1"}, nil)
+	var httpSession *sdkmcp.ClientSession
+	for i := 0; i < 50; i++ {
+		httpSession, err = httpClient.Connect(ctx, &sdkmcp.StreamableClientTransport{Endpoint: fmt.Sprintf("http://127.0.0.1:%d/mcp", port), MaxRetries: -1}, nil)
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("connect over http: %v", err)
+	}
+
+	// 两个传输共享同一个 MCP 服务
+	for _, session := range []*sdkmcp.ClientSession{stdioSession, httpSession} {
+		if _, err := session.ListTools(ctx, nil); err != nil {
+			t.Fatalf("list tools: %v", err)
+		}
+	}
+	if active := s.sessionStatus()["active"]; active != 2 {
+		t.Fatalf("expected 2 active sessions, got %v", active)
+	}
+
+	// stdio 客户端退出后整个服务停止（先断开 HTTP 客户端，避免优雅关闭等待其 SSE 流）
+	_ = httpSession.Close()
+	_ = stdioSession.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("server did not stop after stdio closed")
+	}
+}
+
+func TestStartMultipleTransportsReportsListenError(t *testing.T) {
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	t.Cleanup(func() { _ = stdinW.Close() })
+	origStdin := os.Stdin
+	os.Stdin = stdinR
+	t.Cleanup(func() { os.Stdin = origStdin })
+
+	// 端口已被占用时 HTTP 传输启动失败，Start 应返回错误并停止 stdio 传输
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	s := NewServer(WithConfig(&ServerConfig{Name: "taskbridge", Transport: "stdio,streamable", Host: "127.0.0.1", Port: port}))
+	done := make(chan error, 1)
+	go func() { done <- s.Start(context.Background()) }()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "streamable") {
+			t.Fatalf("expected streamable listen error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server kept running after the HTTP transport failed")
+	}
+}
@@ -0,0 +1,268 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/history"
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+// taskDigest 任务快照摘要，用于比对变化并判断变化所属来源
+type taskDigest struct {
+	hash   uint64
+	source model.TaskSource
+	// task 启用任务历史时保存的任务副本，用于记录轮询发现的字段变化
+	task *model.Task
+}
+
+// resourceWatchState 资源订阅与变化检测状态
+type resourceWatchState struct {
+	mu sync.Mutex
+	// checkMu 保证同一时间只有一次变化检测，避免轮询与写工具触发的检测交错更新快照
+	checkMu sync.Mutex
+	// subscribed 记录每个 URI 的订阅次数；具体会话由 go-sdk 跟踪
+	subscribed    map[string]int
+	tasks         map[string]taskDigest
+	projectDigest uint64
+	primed        bool
+}
+
+// handleSubscribe 处理 resources/subscribe，仅接受已注册的任务/项目资源与任务模板 URI。
+func (s *Server) handleSubscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	uri := req.Params.URI
+	if !s.isSubscribableResource(uri) {
+		return mcp.ResourceNotFoundError(uri)
+	}
+	s.resourceWatch.mu.Lock()
+	if s.resourceWatch.subscribed == nil {
+		s.resourceWatch.subscribed = make(map[string]int)
+	}
+	first := len(s.resourceWatch.subscribed) == 0
+	s.resourceWatch.subscribed[uri]++
+	s.resourceWatch.mu.Unlock()
+
+	// 首个订阅建立基线快照，之后的变化才会触发通知
+	if first {
+		s.resourceWatch.checkMu.Lock()
+		defer s.resourceWatch.checkMu.Unlock()
+		s.snapshotResources(ctx)
+	}
+	return nil
+}
+
+// handleUnsubscribe 处理 resources/unsubscribe。
+func (s *Server) handleUnsubscribe(_ context.Context, req *mcp.UnsubscribeRequest) error {
+	s.resourceWatch.mu.Lock()
+	defer s.resourceWatch.mu.Unlock()
+	uri := req.Params.URI
+	if s.resourceWatch.subscribed[uri] <= 1 {
+		delete(s.resourceWatch.subscribed, uri)
+		return nil
+	}
+	s.resourceWatch.subscribed[uri]--
+	return nil
+}
+
+func (s *Server) isSubscribableResource(uri string) bool {
+	switch uri {
+	case "taskbridge://tasks", "taskbridge://projects":
+		return true
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "task" || strings.Trim(parsed.Path, "/") == "" {
+		return false
+	}
+	_, err = resolveResourceAdapter(parsed.Host)
+	return err == nil
+}
+
+func (s *Server) subscribedResources() []string {
+	s.resourceWatch.mu.Lock()
+	defer s.resourceWatch.mu.Unlock()
+	uris := make([]string, 0, len(s.resourceWatch.subscribed))
+	for uri := range s.resourceWatch.subscribed {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// CheckResourceChanges 比对本地任务与项目快照，并向订阅了受影响资源的会话发送
+// notifications/resources/updated，返回已通知的 URI。
+// 轮询协程、写类工具以及 webhook 接收方在写入变更后均可调用。
+// 启用任务历史时即使没有订阅也会比对，变化的任务记录到任务历史。
+func (s *Server) CheckResourceChanges(ctx context.Context) []string {
+	uris := s.subscribedResources()
+	if len(uris) == 0 && s.taskHistory == nil {
+		return nil
+	}
+
+	s.resourceWatch.checkMu.Lock()
+	defer s.resourceWatch.checkMu.Unlock()
+	if !s.resourceWatch.primed {
+		s.snapshotResources(ctx)
+		return nil
+	}
+	previousTasks := s.resourceWatch.tasks
+	previousProjects := s.resourceWatch.projectDigest
+	if !s.snapshotResources(ctx) {
+		return nil
+	}
+
+	changed := make(map[string]model.TaskSource)
+	for id, digest := range s.resourceWatch.tasks {
+		if old, ok := previousTasks[id]; !ok || old.hash != digest.hash {
+			changed[id] = digest.source
+		}
+	}
+	for id, digest := range previousTasks {
+		if _, ok := s.resourceWatch.tasks[id]; !ok {
+			changed[id] = digest.source
+		}
+	}
+	projectsChanged := s.resourceWatch.projectDigest != previousProjects
+	s.recordTaskChanges(ctx, previousTasks, changed)
+
+	notified := make([]string, 0)
+	for _, uri := range uris {
+		if !resourceAffected(uri, changed, projectsChanged) {
+			continue
+		}
+		if s.server != nil {
+			_ = s.server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+		}
+		notified = append(notified, uri)
+	}
+	return notified
+}
+
+// snapshotResources 重新计算任务与项目摘要；读取失败时保留旧快照并返回 false。
+func (s *Server) snapshotResources(ctx context.Context) bool {
+	tasks := make(map[string]taskDigest)
+	if s.taskStore != nil {
+		list, err := s.taskStore.ListTasks(ctx, storage.ListOptions{})
+		if err != nil {
+			return false
+		}
+		for _, task := range list {
+			source := task.Source
+			if source == "" {
+				source = model.SourceLocal
+			}
+			digest := taskDigest{hash: digestJSON(task), source: source}
+			if s.taskHistory != nil {
+				digest.task = history.CloneTask(&task)
+			}
+			tasks[task.ID] = digest
+		}
+	}
+	var projectDigest uint64
+	if s.projectStore != nil {
+		projects, err := s.projectStore.ListProjects(ctx, "")
+		if err != nil {
+			return false
+		}
+		projectDigest = digestJSON(projects)
+	}
+	s.resourceWatch.tasks = tasks
+	s.resourceWatch.projectDigest = projectDigest
+	s.resourceWatch.primed = true
+	return true
+}
+
+// recordTaskChanges 将两次快照之间变化的任务记录到任务历史，上下文未标注来源时记为 poll。
+// 经由本服务保存的修改已在保存时记录，这里主要补上其他进程或直接修改存储文件带来的变化
+func (s *Server) recordTaskChanges(ctx context.Context, previous map[string]taskDigest, changed map[string]model.TaskSource) {
+	if s.taskHistory == nil || len(changed) == 0 {
+		return
+	}
+	if _, _, ok := history.OriginFrom(ctx); !ok {
+		ctx = history.WithOrigin(ctx, history.OriginPoll, "")
+	}
+	ids := make([]string, 0, len(changed))
+	for id := range changed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		before, after := previous[id].task, s.resourceWatch.tasks[id].task
+		if before == nil && after == nil {
+			continue
+		}
+		if err := s.taskHistory.Record(ctx, before, after); err != nil {
+			log.Warn().Err(err).Str("task_id", id).Msg("写入任务历史失败")
+			return
+		}
+	}
+}
+
+func digestJSON(value interface{}) uint64 {
+	data, _ := json.Marshal(value)
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	return h.Sum64()
+}
+
+// resourceAffected 判断订阅的 URI 是否受本次变化影响。
+func resourceAffected(uri string, changed map[string]model.TaskSource, projectsChanged bool) bool {
+	switch uri {
+	case "taskbridge://tasks":
+		return len(changed) > 0
+	case "taskbridge://projects":
+		return projectsChanged
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	source, err := resolveResourceAdapter(parsed.Host)
+	if err != nil {
+		return false
+	}
+	if path := strings.Trim(parsed.Path, "/"); path != "search" {
+		changedSource, ok := changed[path]
+		return ok && (source == "" || changedSource == source)
+	}
+	for _, changedSource := range changed {
+		if source == "" || changedSource == source {
+			return true
+		}
+	}
+	return false
+}
+
+// watchResourceChanges 按间隔轮询任务变化（包括同步拉取写入的上游变更），直到上下文取消。
+func (s *Server) watchResourceChanges(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CheckResourceChanges(ctx)
+		}
+	}
+}
+
+// notifyAfterWrite 包装写类工具，调用成功后立即检查订阅资源是否变化。
+func (s *Server) notifyAfterWrite(handler mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, req)
+		if err == nil && (result == nil || !result.IsError) {
+			s.CheckResourceChanges(ctx)
+		}
+		return result, err
+	}
+}
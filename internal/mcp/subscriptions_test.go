@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+func newSubscriptionTestServer(t *testing.T) (*Server, *filestore.FileStorage, *sdkmcp.ClientSession, <-chan string) {
+	t.Helper()
+	store, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	ctx := context.Background()
+	for _, task := range []*model.Task{
+		{ID: "local-a", Title: "写周报", Status: model.StatusTodo, Source: model.SourceLocal},
+		{ID: "google-b", Title: "Review PR", Status: model.StatusTodo, Source: model.SourceGoogle},
+	} {
+		if err := store.SaveTask(ctx, task); err != nil {
+			t.Fatalf("seed task: %v", err)
+		}
+	}
+
+	updates := make(chan string, 16)
+	s := NewServer(WithTaskStorage(store))
+	session := connectBreakdownClient(t, s, &sdkmcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *sdkmcp.ResourceUpdatedNotificationRequest) {
+			updates <- req.Params.URI
+		},
+	})
+	return s, store, session, updates
+}
+
+func waitResourceUpdate(t *testing.T, updates <-chan string, want string) {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case uri := <-updates:
+			if uri == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for resource update %s", want)
+		}
+	}
+}
+
+func TestResourceSubscriptionNotifiesOnChange(t *testing.T) {
+	s, store, session, updates := newSubscriptionTestServer(t)
+	ctx := context.Background()
+
+	for _, uri := range []string{"taskbridge://tasks", "task://local/local-a", "task://google/search?q=review"} {
+		if err := session.Subscribe(ctx, &sdkmcp.SubscribeParams{URI: uri}); err != nil {
+			t.Fatalf("subscribe %s: %v", uri, err)
+		}
+	}
+	if notified := s.CheckResourceChanges(ctx); len(notified) != 0 {
+		t.Fatalf("expected no notifications without changes, got %v", notified)
+	}
+
+	// 模拟同步拉取写入的上游变更
+	task, err := store.GetTask(ctx, "google-b")
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	task.Title = "Review PR #42"
+	if err := store.SaveTask(ctx, task); err != nil {
+		t.Fatalf("save task: %v", err)
+	}
+
+	notified := s.CheckResourceChanges(ctx)
+	if len(notified) != 2 || notified[0] != "task://google/search?q=review" || notified[1] != "taskbridge://tasks" {
+		t.Fatalf("unexpected notified uris: %v", notified)
+	}
+	waitResourceUpdate(t, updates, "task://google/search?q=review")
+}
+
+func TestResourceSubscriptionNotifiesAfterWriteTool(t *testing.T) {
+	_, _, session, updates := newSubscriptionTestServer(t)
+	ctx := context.Background()
+
+	if err := session.Subscribe(ctx, &sdkmcp.SubscribeParams{URI: "task://local/local-a"}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if _, err := session.CallTool(ctx, &sdkmcp.CallToolParams{
+		Name:      "update_task",
+		Arguments: map[string]any{"id": "local-a", "title": "写周报（终稿）"},
+	}); err != nil {
+		t.Fatalf("call update_task: %v", err)
+	}
+	waitResourceUpdate(t, updates, "task://local/local-a")
+}
+
+func TestResourceSubscribeRejectsUnknownURI(t *testing.T) {
+	s, _, session, _ := newSubscriptionTestServer(t)
+	ctx := context.Background()
+
+	if err := session.Subscribe(ctx, &sdkmcp.SubscribeParams{URI: "task://unknown/local-a"}); err == nil {
+		t.Fatalf("expected error for unknown adapter")
+	}
+	if err := session.Subscribe(ctx, &sdkmcp.SubscribeParams{URI: "taskbridge://tasks"}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := session.Unsubscribe(ctx, &sdkmcp.UnsubscribeParams{URI: "taskbridge://tasks"}); err != nil {
+		t.Fatalf("unsubscribe: %v", err)
+	}
+	if uris := s.subscribedResources(); len(uris) != 0 {
+		t.Fatalf("expected no subscriptions, got %v", uris)
+	}
+}
@@ -0,0 +1,167 @@
+package mcp
+
+import (
+	"context"
+	gosync "sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/history"
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+const (
+	// taskRefreshTimeout 后台刷新单个 Provider（含等待正在运行的同步）的时限
+	taskRefreshTimeout = 2 * time.Minute
+	// taskRefreshRetryAfter 刷新失败后再次尝试同一 Provider 的最短间隔，避免 Provider 不可用时每次读取都重试
+	taskRefreshRetryAfter = time.Minute
+)
+
+// taskCacheState 本地任务存储的新鲜度：Provider 在 maxAge 内拉取过即视为新鲜，
+// 读类工具直接读取本地存储；过期时在后台拉取，读取不等待刷新完成
+type taskCacheState struct {
+	maxAge     time.Duration
+	mu         gosync.Mutex
+	refreshing map[string]bool
+	attempted  map[string]time.Time
+}
+
+// taskFreshness 单个 Provider 在本地存储中的新鲜度
+type taskFreshness struct {
+	LastSyncTime *time.Time `json:"last_sync_time,omitempty"`
+	Fresh        bool       `json:"fresh"`
+	Refreshing   bool       `json:"refreshing,omitempty"`
+}
+
+// WithTaskCacheMaxAge 设置本地任务存储的最长新鲜时间，超过后读类工具触发后台拉取；<=0 不自动刷新
+func WithTaskCacheMaxAge(maxAge time.Duration) ServerOption {
+	return func(s *Server) {
+		s.taskCache.maxAge = maxAge
+	}
+}
+
+// taskCacheEnabled 是否按新鲜度自动刷新本地任务存储
+func (s *Server) taskCacheEnabled() bool {
+	return s.taskCache.maxAge > 0 && s.taskStore != nil
+}
+
+// providerFreshness 返回 Provider 最近一次拉取时间及是否仍在 maxAge 内
+func (s *Server) providerFreshness(ctx context.Context, name string, now time.Time) taskFreshness {
+	var out taskFreshness
+	if s.taskStore == nil {
+		return out
+	}
+	if last, err := s.taskStore.GetLastSyncTime(ctx, model.TaskSource(name)); err == nil && last != nil && !last.IsZero() {
+		out.LastSyncTime = last
+		out.Fresh = s.taskCache.maxAge <= 0 || now.Sub(*last) < s.taskCache.maxAge
+	}
+	s.taskCache.mu.Lock()
+	out.Refreshing = s.taskCache.refreshing[name]
+	s.taskCache.mu.Unlock()
+	return out
+}
+
+// taskFreshnessSnapshot 返回已认证 Provider 的新鲜度；未启用自动刷新或租户请求时为空
+func (s *Server) taskFreshnessSnapshot(ctx context.Context) map[string]taskFreshness {
+	if !s.taskCacheEnabled() {
+		return nil
+	}
+	if _, tenantScoped := tenantProvidersFrom(ctx); tenantScoped {
+		return nil
+	}
+	now := time.Now()
+	out := make(map[string]taskFreshness)
+	for _, name := range s.providerNames(ctx) {
+		if p, ok := s.lookupProvider(ctx, name); ok && p != nil && p.IsAuthenticated() {
+			out[name] = s.providerFreshness(ctx, name, now)
+		}
+	}
+	return out
+}
+
+// refreshStaleProviders 为已认证且超过 maxAge 未拉取的 Provider 启动后台拉取，不等待完成。
+// 租户的 Provider 不写入服务自身的本地存储，因此只刷新服务自身的 Provider。
+func (s *Server) refreshStaleProviders(ctx context.Context) {
+	if !s.taskCacheEnabled() {
+		return
+	}
+	if _, tenantScoped := tenantProvidersFrom(ctx); tenantScoped {
+		return
+	}
+	now := time.Now()
+	for _, name := range s.providerNames(ctx) {
+		p, ok := s.lookupProvider(ctx, name)
+		if !ok || p == nil || !p.IsAuthenticated() {
+			continue
+		}
+		if s.providerFreshness(ctx, name, now).Fresh {
+			continue
+		}
+		if !s.beginTaskRefresh(name, now) {
+			continue
+		}
+		go s.refreshProvider(name)
+	}
+}
+
+// beginTaskRefresh 标记 Provider 正在刷新；已在刷新或最近刚尝试过时返回 false
+func (s *Server) beginTaskRefresh(name string, now time.Time) bool {
+	state := &s.taskCache
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.refreshing[name] {
+		return false
+	}
+	if at, ok := state.attempted[name]; ok && now.Sub(at) < taskRefreshRetryAfter {
+		return false
+	}
+	if state.refreshing == nil {
+		state.refreshing = make(map[string]bool)
+		state.attempted = make(map[string]time.Time)
+	}
+	state.refreshing[name] = true
+	state.attempted[name] = now
+	return true
+}
+
+// refreshProvider 后台拉取单个 Provider，结果记入 sync_status 的最近运行
+func (s *Server) refreshProvider(name string) {
+	defer func() {
+		s.taskCache.mu.Lock()
+		delete(s.taskCache.refreshing, name)
+		s.taskCache.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(history.WithOrigin(context.Background(), history.OriginServer, "task_cache"), taskRefreshTimeout)
+	defer cancel()
+	if err := s.pullProvider(ctx, name); err != nil {
+		log.Warn().Err(err).Str("provider", name).Msg("后台刷新本地任务失败")
+		return
+	}
+	log.Debug().Str("provider", name).Msg("已在后台刷新本地任务")
+}
+
+// refreshStaleOnRead 读类工具调用时检查新鲜度，过期的 Provider 在后台刷新，本次调用仍读取当前的本地存储
+func (s *Server) refreshStaleOnRead(next mcp.ToolHandler) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		s.refreshStaleProviders(ctx)
+		return next(ctx, req)
+	}
+}
+
+// watchTaskCache 按间隔检查并刷新过期的 Provider，启动时先检查一次
+func (s *Server) watchTaskCache(ctx context.Context, interval time.Duration) {
+	s.refreshStaleProviders(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refreshStaleProviders(ctx)
+		}
+	}
+}
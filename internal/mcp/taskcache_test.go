@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+// countingTasksProvider 记录 ListTasks 调用次数
+type countingTasksProvider struct {
+	remoteTasksProvider
+	calls atomic.Int32
+}
+
+func (p *countingTasksProvider) ListTasks(ctx context.Context, listID string, opts provider.ListOptions) ([]model.Task, error) {
+	p.calls.Add(1)
+	return p.remoteTasksProvider.ListTasks(ctx, listID, opts)
+}
+
+// waitTaskRefresh 等待后台刷新结束
+func waitTaskRefresh(t *testing.T, s *Server, name string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.taskCache.mu.Lock()
+		refreshing := s.taskCache.refreshing[name]
+		s.taskCache.mu.Unlock()
+		if !refreshing {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("refresh of %s did not finish", name)
+}
+
+func TestReadToolRefreshesStaleProvider(t *testing.T) {
+	remote := &countingTasksProvider{remoteTasksProvider: remoteTasksProvider{remote: []model.Task{
+		{ID: "google-@default-r1", Title: "远端任务", Status: model.StatusTodo, Source: model.SourceGoogle, SourceRawID: "r1"},
+	}}}
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, map[string]provider.Provider{"google": remote})
+	WithTaskCacheMaxAge(time.Hour)(s)
+	listTasks := s.refreshStaleOnRead(s.handleListTasks)
+
+	// 从未拉取过：本次读取返回当前本地数据，同时在后台拉取
+	if _, err := listTasks(ctx, buildCallToolRequest(t, map[string]interface{}{})); err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	waitTaskRefresh(t, s, "google")
+	if _, err := store.GetTask(ctx, "google-@default-r1"); err != nil {
+		t.Fatalf("background refresh should pull remote task: %v", err)
+	}
+	calls := remote.calls.Load()
+	if calls == 0 {
+		t.Fatal("expected provider to be called by background refresh")
+	}
+
+	// 新鲜时直接读取本地存储，不再调用 Provider
+	res, err := listTasks(ctx, buildCallToolRequest(t, map[string]interface{}{"include_meta": true}))
+	if err != nil {
+		t.Fatalf("list tasks: %v", err)
+	}
+	waitTaskRefresh(t, s, "google")
+	if got := remote.calls.Load(); got != calls {
+		t.Fatalf("fresh cache should not call provider again, calls %d -> %d", calls, got)
+	}
+	out := parseJSONResult(t, res)
+	meta, _ := out["meta"].(map[string]interface{})
+	freshness, _ := meta["freshness"].(map[string]interface{})
+	google, _ := freshness["google"].(map[string]interface{})
+	if google["fresh"] != true || google["last_sync_time"] == nil {
+		t.Fatalf("expected fresh google cache in meta, got %v", meta)
+	}
+	if s.syncState.lastRun["google"] == nil {
+		t.Fatal("background refresh should be recorded for sync_status")
+	}
+}
+
+func TestRefreshStaleProvidersSkipsFreshAndDisabled(t *testing.T) {
+	remote := &countingTasksProvider{}
+	s, store, ctx := newIntelligenceTestServer(t, pkgconfig.DefaultConfig().MCP.Intelligence, map[string]provider.Provider{"google": remote})
+
+	// 未设置 max_age 时不自动刷新
+	s.refreshStaleProviders(ctx)
+	waitTaskRefresh(t, s, "google")
+	if remote.calls.Load() != 0 {
+		t.Fatal("refresh should be disabled without max_age")
+	}
+
+	WithTaskCacheMaxAge(time.Hour)(s)
+	if err := store.SetLastSyncTime(ctx, model.SourceGoogle, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("set last sync time: %v", err)
+	}
+	s.refreshStaleProviders(ctx)
+	waitTaskRefresh(t, s, "google")
+	if remote.calls.Load() != 0 {
+		t.Fatal("fresh provider should not be refreshed")
+	}
+
+	if err := store.SetLastSyncTime(ctx, model.SourceGoogle, time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatalf("set last sync time: %v", err)
+	}
+	if got := s.providerFreshness(ctx, "google", time.Now()); got.Fresh {
+		t.Fatalf("expected stale provider, got %+v", got)
+	}
+	s.refreshStaleProviders(ctx)
+	waitTaskRefresh(t, s, "google")
+	if remote.calls.Load() == 0 {
+		t.Fatal("stale provider should be refreshed")
+	}
+}
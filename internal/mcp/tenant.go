@@ -0,0 +1,359 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+	"github.com/yeisme/taskbridge/pkg/paths"
+)
+
+// TenantTokenHeaderPrefix 请求头直接携带 Provider token 时的前缀，如 X-TaskBridge-Token-Todoist
+const TenantTokenHeaderPrefix = "X-TaskBridge-Token-"
+
+// tenantCacheTTL 租户 Provider 的缓存时间，过期后下次请求重新从凭证加载
+const tenantCacheTTL = 10 * time.Minute
+
+// tenantSharedTools 读写服务自身的项目、计时、模板、任务历史、向量索引或镜像状态的工具。
+// 这些数据不按租户拆分，使用独立存储的租户请求不能调用
+var tenantSharedTools = map[string]bool{
+	"create_project":              true,
+	"list_projects":               true,
+	"split_project":               true,
+	"split_project_from_markdown": true,
+	"confirm_project":             true,
+	"sync_project":                true,
+	"start_timer":                 true,
+	"stop_timer":                  true,
+	"log_time":                    true,
+	"time_report":                 true,
+	"save_template":               true,
+	"list_templates":              true,
+	"instantiate_template":        true,
+	"task_history":                true,
+	"semantic_search":             true,
+	"resolve_conflict":            true,
+}
+
+// Tenant HTTP 请求所属的租户
+type Tenant struct {
+	// Key 租户 key，对应同名 profile 中保存的 Provider 凭证
+	Key string
+	// Tokens 请求头直接携带的 Provider token，键为小写 Provider 名称
+	Tokens map[string]string
+}
+
+// CredentialDigest 请求头携带的 token 的摘要，不含 token 时为空；用于区分同一租户 key 下使用不同 token 的客户端
+func (t Tenant) CredentialDigest() string {
+	if len(t.Tokens) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(t.Tokens))
+	for name := range t.Tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s=%s\n", name, t.Tokens[name])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// TenantProviderLoader 按租户加载 Provider 集合，由调用方决定凭证的来源
+type TenantProviderLoader func(ctx context.Context, tenant Tenant) (map[string]provider.Provider, error)
+
+// TenantStoreLoader 为租户打开独立的本地任务存储，由调用方决定存储的位置
+type TenantStoreLoader func(ctx context.Context, tenant Tenant) (storage.Storage, error)
+
+// tenantState 按租户缓存的 Provider、任务存储与会话绑定的租户
+type tenantState struct {
+	mu       sync.Mutex
+	entries  map[string]*tenantEntry
+	stores   map[string]storage.Storage
+	sessions map[*mcp.ServerSession]Tenant
+}
+
+type tenantEntry struct {
+	providers map[string]provider.Provider
+	loadedAt  time.Time
+}
+
+// tenantProvidersKey 请求 context 中保存所属租户 Provider 集合的键
+type tenantProvidersKey struct{}
+
+// tenantStoreKey 请求 context 中保存所属租户任务存储的键
+type tenantStoreKey struct{}
+
+// WithTenantConfig 启用多租户：HTTP 请求按已认证身份（mcp.tenant.principals）确定租户，X-TaskBridge-Token-* 头可附带 token，
+// 由 loader 为每个租户加载独立的 Provider，工具调用只能访问所属租户的 Provider
+func WithTenantConfig(cfg *pkgconfig.TenantConfig, loader TenantProviderLoader) ServerOption {
+	return func(s *Server) {
+		s.tenantConfig = cfg
+		s.tenantLoader = loader
+	}
+}
+
+// WithTenantStores 设置为租户打开独立任务存储的函数。默认租户且未携带 token 的请求使用服务自身的存储，
+// 其余租户请求读写 loader 返回的存储；未设置时这些请求被拒绝
+func WithTenantStores(loader TenantStoreLoader) ServerOption {
+	return func(s *Server) {
+		s.tenantStoreLoader = loader
+	}
+}
+
+// tenantEnabled 是否启用了多租户
+func (s *Server) tenantEnabled() bool {
+	return s.tenantConfig != nil && s.tenantConfig.Enabled && s.tenantLoader != nil
+}
+
+// tenantProvidersFrom 返回 context 中所属租户的 Provider 集合；非多租户请求返回 false
+func tenantProvidersFrom(ctx context.Context) (map[string]provider.Provider, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	providers, ok := ctx.Value(tenantProvidersKey{}).(map[string]provider.Provider)
+	return providers, ok
+}
+
+// principalTenant 返回请求的已认证身份在 mcp.tenant.principals 中映射到的租户：
+// OAuth 请求按访问令牌的 subject 匹配，其余请求按 Authorization: Bearer 或 X-API-Key 携带的 API key 匹配
+func (s *Server) principalTenant(extra *mcp.RequestExtra) (string, bool) {
+	subject, apiKey := "", ""
+	if extra.TokenInfo != nil {
+		subject = extra.TokenInfo.UserID
+	} else {
+		apiKey = requestToken(&http.Request{Header: extra.Header})
+	}
+	for key, principal := range s.tenantConfig.Principals {
+		if subject != "" && slices.Contains(principal.Subjects, subject) {
+			return key, true
+		}
+		if apiKey != "" && validRequestToken(apiKey, principal.APIKeys) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// permittedTenant 返回请求可以使用的租户 key：身份映射到的租户，未映射时为 default_tenant。
+// 请求头指定了其他租户时返回错误
+func (s *Server) permittedTenant(extra *mcp.RequestExtra, requested string) (string, error) {
+	permitted := strings.TrimSpace(s.tenantConfig.DefaultTenant)
+	if key, ok := s.principalTenant(extra); ok {
+		permitted = key
+	}
+	if requested != "" && requested != permitted {
+		return "", fmt.Errorf("tenant %q is not permitted for this client", requested)
+	}
+	return permitted, nil
+}
+
+// tenantFromHeader 从请求头读取租户 key 与直接携带的 Provider token
+func (s *Server) tenantFromHeader(header http.Header) Tenant {
+	headerKey := strings.TrimSpace(s.tenantConfig.HeaderKey)
+	if headerKey == "" {
+		headerKey = "X-TaskBridge-Tenant"
+	}
+	tenant := Tenant{Key: strings.TrimSpace(header.Get(headerKey))}
+	if !s.tenantConfig.AllowCredentialHeaders {
+		return tenant
+	}
+	prefix := http.CanonicalHeaderKey(TenantTokenHeaderPrefix)
+	for name, values := range header {
+		name = http.CanonicalHeaderKey(name)
+		if !strings.HasPrefix(name, prefix) || len(values) == 0 {
+			continue
+		}
+		providerName := strings.ToLower(strings.TrimPrefix(name, prefix))
+		if token := strings.TrimSpace(values[0]); providerName != "" && token != "" {
+			if tenant.Tokens == nil {
+				tenant.Tokens = make(map[string]string)
+			}
+			tenant.Tokens[providerName] = token
+		}
+	}
+	return tenant
+}
+
+// tenantMiddleware 为 HTTP 请求解析所属租户并把该租户的 Provider 与任务存储放入 context。
+// 租户由请求的已认证身份决定，租户头只能指定该身份可用的租户；initialize 时的租户绑定到会话，会话不能切换到其他租户。
+// stdio 等没有请求头的传输不受影响，使用服务自身的 Provider 与存储。
+func (s *Server) tenantMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if !s.tenantEnabled() {
+			return next(ctx, method, req)
+		}
+		extra := req.GetExtra()
+		if extra == nil || extra.Header == nil {
+			return next(ctx, method, req)
+		}
+
+		tenant := s.tenantFromHeader(extra.Header)
+		if tenant.Key != "" {
+			if err := paths.ValidateProfileName(tenant.Key); err != nil {
+				return nil, fmt.Errorf("invalid tenant: %w", err)
+			}
+		}
+		key, err := s.permittedTenant(extra, tenant.Key)
+		if err != nil {
+			return nil, err
+		}
+		tenant.Key = key
+		session, _ := req.GetSession().(*mcp.ServerSession)
+		if bound, ok := s.sessionTenant(session); ok {
+			if tenant.Key != bound.Key {
+				return nil, fmt.Errorf("session is bound to tenant %q", bound.Key)
+			}
+			if len(tenant.Tokens) == 0 {
+				tenant.Tokens = bound.Tokens
+			}
+		}
+		if err := paths.ValidateProfileName(tenant.Key); err != nil {
+			return nil, fmt.Errorf("invalid tenant: %w", err)
+		}
+		if method == "initialize" && session != nil {
+			s.bindSessionTenant(session, tenant)
+		}
+
+		// 默认租户且未携带 token 时直接使用服务自身的 Provider
+		if tenant.Key == paths.DefaultProfile && len(tenant.Tokens) == 0 {
+			return next(ctx, method, req)
+		}
+		if err := checkTenantSharedData(method, req); err != nil {
+			return nil, err
+		}
+		providers, err := s.tenantProviders(ctx, tenant)
+		if err != nil {
+			return nil, fmt.Errorf("load providers for tenant %q: %w", tenant.Key, err)
+		}
+		store, err := s.tenantStore(ctx, tenant)
+		if err != nil {
+			return nil, fmt.Errorf("open task store for tenant %q: %w", tenant.Key, err)
+		}
+		ctx = context.WithValue(ctx, tenantProvidersKey{}, providers)
+		return next(context.WithValue(ctx, tenantStoreKey{}, store), method, req)
+	}
+}
+
+// checkTenantSharedData 拒绝租户请求调用 tenantSharedTools 或读取项目列表、任务历史资源
+func checkTenantSharedData(method string, req mcp.Request) error {
+	switch r := req.(type) {
+	case *mcp.CallToolRequest:
+		if method == "tools/call" && r.Params != nil && tenantSharedTools[r.Params.Name] {
+			return fmt.Errorf("tool %q is not available to tenants", r.Params.Name)
+		}
+	case *mcp.ReadResourceRequest:
+		if r.Params != nil && (r.Params.URI == "taskbridge://projects" || strings.HasSuffix(r.Params.URI, "/history")) {
+			return fmt.Errorf("resource %q is not available to tenants", r.Params.URI)
+		}
+	}
+	return nil
+}
+
+// tenantStore 返回租户的任务存储，打开后一直复用
+func (s *Server) tenantStore(ctx context.Context, tenant Tenant) (storage.Storage, error) {
+	if s.tenantStoreLoader == nil {
+		return nil, fmt.Errorf("tenant task stores are not configured")
+	}
+	cacheKey := tenantCacheKey(tenant)
+	s.tenants.mu.Lock()
+	defer s.tenants.mu.Unlock()
+	if store, ok := s.tenants.stores[cacheKey]; ok {
+		return store, nil
+	}
+	store, err := s.tenantStoreLoader(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if s.tenants.stores == nil {
+		s.tenants.stores = make(map[string]storage.Storage)
+	}
+	s.tenants.stores[cacheKey] = store
+	return store, nil
+}
+
+// tenantProviders 返回租户的 Provider 集合，在 tenantCacheTTL 内复用已加载的 Provider
+func (s *Server) tenantProviders(ctx context.Context, tenant Tenant) (map[string]provider.Provider, error) {
+	cacheKey := tenantCacheKey(tenant)
+	now := time.Now()
+
+	s.tenants.mu.Lock()
+	for key, entry := range s.tenants.entries {
+		if now.Sub(entry.loadedAt) > tenantCacheTTL {
+			delete(s.tenants.entries, key)
+		}
+	}
+	if entry, ok := s.tenants.entries[cacheKey]; ok {
+		s.tenants.mu.Unlock()
+		return entry.providers, nil
+	}
+	s.tenants.mu.Unlock()
+
+	providers, err := s.tenantLoader(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if providers == nil {
+		providers = make(map[string]provider.Provider)
+	}
+	log.Debug().Str("component", "mcp").Str("tenant", tenant.Key).Int("providers", len(providers)).Msg("tenant providers loaded")
+
+	s.tenants.mu.Lock()
+	defer s.tenants.mu.Unlock()
+	if s.tenants.entries == nil {
+		s.tenants.entries = make(map[string]*tenantEntry)
+	}
+	s.tenants.entries[cacheKey] = &tenantEntry{providers: providers, loadedAt: now}
+	return providers, nil
+}
+
+// tenantCacheKey 由租户 key 与 token 摘要组成，不在内存索引中保留 token 明文
+func tenantCacheKey(tenant Tenant) string {
+	if digest := tenant.CredentialDigest(); digest != "" {
+		return tenant.Key + "#" + digest
+	}
+	return tenant.Key
+}
+
+// sessionTenant 返回会话初始化时绑定的租户
+func (s *Server) sessionTenant(session *mcp.ServerSession) (Tenant, bool) {
+	if session == nil {
+		return Tenant{}, false
+	}
+	s.tenants.mu.Lock()
+	defer s.tenants.mu.Unlock()
+	tenant, ok := s.tenants.sessions[session]
+	return tenant, ok
+}
+
+// bindSessionTenant 将租户绑定到会话，会话结束后自动清理
+func (s *Server) bindSessionTenant(session *mcp.ServerSession, tenant Tenant) {
+	s.tenants.mu.Lock()
+	defer s.tenants.mu.Unlock()
+	if s.tenants.sessions == nil {
+		s.tenants.sessions = make(map[*mcp.ServerSession]Tenant)
+	}
+	if _, exists := s.tenants.sessions[session]; !exists {
+		go func() {
+			_ = session.Wait()
+			s.tenants.mu.Lock()
+			delete(s.tenants.sessions, session)
+			s.tenants.mu.Unlock()
+		}()
+	}
+	s.tenants.sessions[session] = tenant
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func newTenantTestServer(t *testing.T, cfg pkgconfig.TenantConfig, loads *[]Tenant) *Server {
+	t.Helper()
+	loader := func(_ context.Context, tenant Tenant) (map[string]provider.Provider, error) {
+		*loads = append(*loads, tenant)
+		return map[string]provider.Provider{"google": &mockProvider{}}, nil
+	}
+	storeLoader := func(_ context.Context, _ Tenant) (storage.Storage, error) {
+		return filestore.New(t.TempDir(), "json")
+	}
+	base, err := filestore.New(t.TempDir(), "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	return NewServer(
+		WithTaskStorage(base),
+		WithProviders(map[string]provider.Provider{"todoist": &mockProvider{}}),
+		WithTenantConfig(&cfg, loader),
+		WithTenantStores(storeLoader),
+	)
+}
+
+// tenantDo 以给定请求经过 tenantMiddleware 执行 fn，fn 收到的 context 带有所属租户
+func tenantDo(s *Server, method string, req mcp.Request, fn func(ctx context.Context)) error {
+	handler := s.tenantMiddleware(func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		fn(ctx)
+		return &mcp.CallToolResult{}, nil
+	})
+	_, err := handler(context.Background(), method, req)
+	return err
+}
+
+// tenantCall 以给定请求头经过 tenantMiddleware 调用工具，返回处理器看到的 Provider 名称
+func tenantCall(t *testing.T, s *Server, header http.Header) ([]string, error) {
+	t.Helper()
+	var names []string
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "list_tasks"}, Extra: &mcp.RequestExtra{Header: header}}
+	err := tenantDo(s, "tools/call", req, func(ctx context.Context) {
+		names = s.providerNames(ctx)
+	})
+	return names, err
+}
+
+func TestTenantMiddlewareScopesProvidersByPrincipal(t *testing.T) {
+	var loads []Tenant
+	s := newTenantTestServer(t, pkgconfig.TenantConfig{
+		Enabled:       true,
+		DefaultTenant: "default",
+		HeaderKey:     "X-TaskBridge-Tenant",
+		Principals:    map[string]pkgconfig.TenantPrincipalConfig{"alice": {APIKeys: []string{"alice-key"}}},
+	}, &loads)
+
+	names, err := tenantCall(t, s, http.Header{})
+	if err != nil || strings.Join(names, ",") != "todoist" {
+		t.Fatalf("default tenant should use server providers, got %v err=%v", names, err)
+	}
+
+	header := http.Header{}
+	header.Set("X-API-Key", "alice-key")
+	for i := 0; i < 2; i++ {
+		names, err = tenantCall(t, s, header)
+		if err != nil || strings.Join(names, ",") != "google" {
+			t.Fatalf("alice's key should select tenant alice, got %v err=%v", names, err)
+		}
+	}
+	header.Set("X-TaskBridge-Tenant", "alice")
+	if names, err = tenantCall(t, s, header); err != nil || strings.Join(names, ",") != "google" {
+		t.Fatalf("matching tenant header should be accepted, got %v err=%v", names, err)
+	}
+	if len(loads) != 1 || loads[0].Key != "alice" {
+		t.Fatalf("expected tenant providers loaded once and cached, got %+v", loads)
+	}
+
+	header.Set("X-TaskBridge-Tenant", "bob")
+	if _, err := tenantCall(t, s, header); err == nil {
+		t.Fatal("a client must not select a tenant its key is not mapped to")
+	}
+	// 没有映射身份的客户端只能使用默认租户
+	anonymous := http.Header{}
+	anonymous.Set("X-TaskBridge-Tenant", "alice")
+	if _, err := tenantCall(t, s, anonymous); err == nil {
+		t.Fatal("an unmapped client must not select another tenant by header")
+	}
+	anonymous.Set("X-API-Key", "wrong-key")
+	if _, err := tenantCall(t, s, anonymous); err == nil {
+		t.Fatal("an unknown key must not select another tenant by header")
+	}
+
+	header.Set("X-TaskBridge-Tenant", "../etc")
+	if _, err := tenantCall(t, s, header); err == nil {
+		t.Fatal("expected invalid tenant key to be rejected")
+	}
+}
+
+func TestTenantMiddlewareOAuthSubject(t *testing.T) {
+	var loads []Tenant
+	s := newTenantTestServer(t, pkgconfig.TenantConfig{
+		Enabled:       true,
+		DefaultTenant: "default",
+		Principals:    map[string]pkgconfig.TenantPrincipalConfig{"alice": {Subjects: []string{"user-1"}, APIKeys: []string{"alice-key"}}},
+	}, &loads)
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer alice-key")
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "list_tasks"}, Extra: &mcp.RequestExtra{Header: header, TokenInfo: &auth.TokenInfo{UserID: "user-1"}}}
+	var names []string
+	if err := tenantDo(s, "tools/call", req, func(ctx context.Context) { names = s.providerNames(ctx) }); err != nil || strings.Join(names, ",") != "google" {
+		t.Fatalf("oauth subject should select tenant alice, got %v err=%v", names, err)
+	}
+
+	// OAuth 请求只按 subject 匹配，Bearer 中的访问令牌不会被当作 API key
+	req.Extra.TokenInfo = &auth.TokenInfo{UserID: "user-2"}
+	if err := tenantDo(s, "tools/call", req, func(ctx context.Context) { names = s.providerNames(ctx) }); err != nil || strings.Join(names, ",") != "todoist" {
+		t.Fatalf("unmapped subject should use the default tenant, got %v err=%v", names, err)
+	}
+}
+
+func TestTenantMiddlewareIsolatesTaskStores(t *testing.T) {
+	var loads []Tenant
+	s := newTenantTestServer(t, pkgconfig.TenantConfig{
+		Enabled:       true,
+		DefaultTenant: "default",
+		Principals: map[string]pkgconfig.TenantPrincipalConfig{
+			"alice": {APIKeys: []string{"alice-key"}},
+			"bob":   {APIKeys: []string{"bob-key"}},
+		},
+	}, &loads)
+	call := func(key string, fn func(ctx context.Context)) {
+		t.Helper()
+		header := http.Header{}
+		if key != "" {
+			header.Set("X-API-Key", key)
+		}
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "list_tasks"}, Extra: &mcp.RequestExtra{Header: header}}
+		if err := tenantDo(s, "tools/call", req, fn); err != nil {
+			t.Fatalf("tenant call: %v", err)
+		}
+	}
+	count := func(key string) int {
+		t.Helper()
+		var tasks []model.Task
+		var err error
+		call(key, func(ctx context.Context) { tasks, err = s.taskStore.ListTasks(ctx, storage.ListOptions{}) })
+		if err != nil {
+			t.Fatalf("list tasks: %v", err)
+		}
+		return len(tasks)
+	}
+
+	call("alice-key", func(ctx context.Context) {
+		if err := s.taskStore.SaveTask(ctx, &model.Task{ID: "alice-1", Title: "alice 的任务", Status: model.StatusTodo, Source: model.SourceLocal}); err != nil {
+			t.Fatalf("save task: %v", err)
+		}
+	})
+	if got := count("alice-key"); got != 1 {
+		t.Fatalf("alice should see her task, got %d", got)
+	}
+	if got := count("bob-key"); got != 0 {
+		t.Fatalf("bob must not see alice's tasks, got %d", got)
+	}
+	if got := count(""); got != 0 {
+		t.Fatalf("the default tenant must not see alice's tasks, got %d", got)
+	}
+
+	header := http.Header{}
+	header.Set("X-API-Key", "alice-key")
+	shared := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "time_report"}, Extra: &mcp.RequestExtra{Header: header}}
+	if err := tenantDo(s, "tools/call", shared, func(context.Context) {}); err == nil {
+		t.Fatal("tools backed by server-wide stores must be refused for tenants")
+	}
+}
+
+func TestTenantMiddlewareCredentialHeaders(t *testing.T) {
+	var loads []Tenant
+	header := http.Header{}
+	header.Set("X-TaskBridge-Token-Todoist", "secret")
+
+	s := newTenantTestServer(t, pkgconfig.TenantConfig{Enabled: true, DefaultTenant: "default"}, &loads)
+	if _, err := tenantCall(t, s, header); err != nil || len(loads) != 0 {
+		t.Fatalf("credential headers must be ignored unless allowed, loads=%+v err=%v", loads, err)
+	}
+
+	s = newTenantTestServer(t, pkgconfig.TenantConfig{Enabled: true, DefaultTenant: "default", AllowCredentialHeaders: true}, &loads)
+	if _, err := tenantCall(t, s, header); err != nil {
+		t.Fatalf("tenantCall: %v", err)
+	}
+	if len(loads) != 1 || loads[0].Key != "default" || loads[0].Tokens["todoist"] != "secret" {
+		t.Fatalf("expected inline token passed to loader, got %+v", loads)
+	}
+	if key := tenantCacheKey(loads[0]); strings.Contains(key, "secret") {
+		t.Fatalf("cache key must not contain the token: %s", key)
+	}
+}
+
+func TestTenantMiddlewareSkipsRequestsWithoutHeaders(t *testing.T) {
+	var loads []Tenant
+	s := newTenantTestServer(t, pkgconfig.TenantConfig{Enabled: true, DefaultTenant: "alice"}, &loads)
+
+	var names []string
+	handler := s.tenantMiddleware(func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		names = s.providerNames(ctx)
+		return &mcp.CallToolResult{}, nil
+	})
+	// stdio 请求没有 HTTP 请求头，始终使用服务自身的 Provider
+	if _, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "list_tasks"}}); err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+	if strings.Join(names, ",") != "todoist" || len(loads) != 0 {
+		t.Fatalf("expected server providers for stdio request, got %v loads=%+v", names, loads)
+	}
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+)
+
+// tenantTaskStore 按请求所属租户分派任务存储：tenantMiddleware 放入 context 的租户存储优先，
+// 其余请求与后台任务使用服务自身的存储
+type tenantTaskStore struct {
+	base storage.Storage
+}
+
+var _ storage.Storage = tenantTaskStore{}
+
+// of 返回 ctx 所属租户的存储
+func (t tenantTaskStore) of(ctx context.Context) storage.Storage {
+	if ctx != nil {
+		if store, ok := ctx.Value(tenantStoreKey{}).(storage.Storage); ok && store != nil {
+			return store
+		}
+	}
+	return t.base
+}
+
+func (t tenantTaskStore) SaveTask(ctx context.Context, task *model.Task) error {
+	return t.of(ctx).SaveTask(ctx, task)
+}
+
+func (t tenantTaskStore) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	return t.of(ctx).GetTask(ctx, id)
+}
+
+func (t tenantTaskStore) ListTasks(ctx context.Context, opts storage.ListOptions) ([]model.Task, error) {
+	return t.of(ctx).ListTasks(ctx, opts)
+}
+
+func (t tenantTaskStore) DeleteTask(ctx context.Context, id string) error {
+	return t.of(ctx).DeleteTask(ctx, id)
+}
+
+func (t tenantTaskStore) SaveTasks(ctx context.Context, tasks []*model.Task) error {
+	return t.of(ctx).SaveTasks(ctx, tasks)
+}
+
+func (t tenantTaskStore) QueryTasks(ctx context.Context, query storage.Query) ([]model.Task, error) {
+	return t.of(ctx).QueryTasks(ctx, query)
+}
+
+func (t tenantTaskStore) SaveTaskList(ctx context.Context, list *model.TaskList) error {
+	return t.of(ctx).SaveTaskList(ctx, list)
+}
+
+func (t tenantTaskStore) GetTaskList(ctx context.Context, id string) (*model.TaskList, error) {
+	return t.of(ctx).GetTaskList(ctx, id)
+}
+
+func (t tenantTaskStore) ListTaskLists(ctx context.Context) ([]model.TaskList, error) {
+	return t.of(ctx).ListTaskLists(ctx)
+}
+
+func (t tenantTaskStore) DeleteTaskList(ctx context.Context, id string) error {
+	return t.of(ctx).DeleteTaskList(ctx, id)
+}
+
+func (t tenantTaskStore) ExportToJSON(ctx context.Context, opts storage.ExportOptions) ([]byte, error) {
+	return t.of(ctx).ExportToJSON(ctx, opts)
+}
+
+func (t tenantTaskStore) ExportToMarkdown(ctx context.Context, opts storage.ExportOptions) ([]byte, error) {
+	return t.of(ctx).ExportToMarkdown(ctx, opts)
+}
+
+func (t tenantTaskStore) GetLastSyncTime(ctx context.Context, source model.TaskSource) (*time.Time, error) {
+	return t.of(ctx).GetLastSyncTime(ctx, source)
+}
+
+func (t tenantTaskStore) SetLastSyncTime(ctx context.Context, source model.TaskSource, at time.Time) error {
+	return t.of(ctx).SetLastSyncTime(ctx, source, at)
+}
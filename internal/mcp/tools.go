@@ -0,0 +1,327 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/provider"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+// toolRequirement 工具对外暴露所需的前置条件
+type toolRequirement int
+
+const (
+	// toolRequiresNone 始终可用（仅依赖本地存储）
+	toolRequiresNone toolRequirement = iota
+	// toolRequiresProvider 至少有一个已配置且已认证的 Provider
+	toolRequiresProvider
+	// toolRequiresWritableProvider 至少有一个已认证且获得写 scope 的 Provider
+	toolRequiresWritableProvider
+	// toolRequiresEmbeddings 启用了任务向量（storage.embeddings.enabled）
+	toolRequiresEmbeddings
+)
+
+// toolRequirements 依赖远端 Provider 或可选功能的工具；没有可用（或可写入）的 Provider、功能未启用时这些工具必然失败，因此不注册。
+var toolRequirements = map[string]toolRequirement{
+	"sync_push":                    toolRequiresWritableProvider,
+	"sync_pull":                    toolRequiresProvider,
+	"sync_now":                     toolRequiresProvider,
+	"sync_project":                 toolRequiresProvider,
+	"resolve_conflict":             toolRequiresProvider,
+	"decompose_task_with_provider": toolRequiresWritableProvider,
+	"semantic_search":              toolRequiresEmbeddings,
+}
+
+// readOnlyTools 不修改本地或远端数据的工具；read_only 模式下仅注册这些工具，并为其标注 readOnlyHint。
+var readOnlyTools = map[string]bool{
+	"list_tasks":                      true,
+	"list_task_lists":                 true,
+	"get_task":                        true,
+	"overdue_tasks":                   true,
+	"list_blockers":                   true,
+	"ready_tasks":                     true,
+	"export_tasks":                    true,
+	"analyze_quadrant":                true,
+	"analyze_priority":                true,
+	"task_statistics":                 true,
+	"summarize_tasks":                 true,
+	"analyze_overdue_health":          true,
+	"detect_decomposition_candidates": true,
+	"analyze_achievement":             true,
+	"find_duplicates":                 true,
+	"list_projects":                   true,
+	"list_templates":                  true,
+	"get_prompt":                      true,
+	"sync_status":                     true,
+	"list_providers":                  true,
+	"get_provider_info":               true,
+	"get_provider_config_template":    true,
+	"get_server_info":                 true,
+	"set_context":                     true,
+	"get_context":                     true,
+	"task_history":                    true,
+	"semantic_search":                 true,
+	"time_report":                     true,
+}
+
+// registeredTool 工具定义与处理器，保留下来以便运行时重新注册
+type registeredTool struct {
+	tool    *mcp.Tool
+	handler mcp.ToolHandler
+}
+
+// addTool 记录工具定义；实际是否注册到 MCP 服务由 RefreshTools 决定。
+func (s *Server) addTool(tool *mcp.Tool, handler mcp.ToolHandler) {
+	s.toolMu.Lock()
+	defer s.toolMu.Unlock()
+	if s.toolDefs == nil {
+		s.toolDefs = make(map[string]registeredTool)
+	}
+	if readOnlyTools[tool.Name] {
+		if tool.Annotations == nil {
+			tool.Annotations = &mcp.ToolAnnotations{}
+		}
+		tool.Annotations.ReadOnlyHint = true
+		handler = s.refreshStaleOnRead(handler)
+	} else {
+		handler = s.notifyAfterWrite(handler)
+	}
+	handler = withToolOrigin(tool.Name, handler)
+	handler = s.withSessionDefaults(tool, handler)
+	handler = logToolCall(tool.Name, handler)
+	if _, exists := s.toolDefs[tool.Name]; !exists {
+		s.toolOrder = append(s.toolOrder, tool.Name)
+	}
+	s.toolDefs[tool.Name] = registeredTool{tool: tool, handler: handler}
+}
+
+// RefreshTools 按当前 Provider 状态增删已注册工具。
+// go-sdk 在工具集合变化时会向已连接会话发送 notifications/tools/list_changed。
+func (s *Server) RefreshTools() {
+	s.toolMu.Lock()
+	defer s.toolMu.Unlock()
+	if s.activeTools == nil {
+		s.activeTools = make(map[string]bool)
+	}
+
+	removed := make([]string, 0)
+	for _, name := range s.toolOrder {
+		def := s.toolDefs[name]
+		available := s.toolAvailable(name)
+		switch {
+		case available && !s.activeTools[name]:
+			s.server.AddTool(def.tool, def.handler)
+			s.activeTools[name] = true
+		case !available && s.activeTools[name]:
+			removed = append(removed, name)
+			delete(s.activeTools, name)
+		}
+	}
+	if len(removed) > 0 {
+		s.server.RemoveTools(removed...)
+	}
+}
+
+// toolAvailable 判断工具是否被工具策略允许且前置条件满足。
+func (s *Server) toolAvailable(name string) bool {
+	if !toolAllowedByPolicy(s.toolPolicy, name) {
+		return false
+	}
+	switch toolRequirements[name] {
+	case toolRequiresProvider:
+		// 多租户模式下 Provider 按请求所属租户加载，服务自身未认证任何 Provider 时也需要提供这些工具
+		return s.tenantEnabled() || s.hasHealthyProvider()
+	case toolRequiresWritableProvider:
+		return s.tenantEnabled() || s.hasWritableProvider()
+	case toolRequiresEmbeddings:
+		return s.embeddings != nil
+	default:
+		return true
+	}
+}
+
+// toolAllowedByPolicy 按 mcp.tools 配置过滤工具：
+//   - read_only 为 true 时只允许只读工具（不受 enabled 影响）
+//   - enabled 为 true 时 deny_list 优先；allow_list 非空时仅允许列出的工具或分组（groups），否则按 default_enabled
+func toolAllowedByPolicy(policy *pkgconfig.ToolGovernanceConfig, name string) bool {
+	if policy == nil {
+		return true
+	}
+	if policy.ReadOnly && !readOnlyTools[name] {
+		return false
+	}
+	if !policy.Enabled {
+		return true
+	}
+	if toolListMatches(policy.DenyList, policy.Groups, name) {
+		return false
+	}
+	if len(policy.AllowList) > 0 {
+		return toolListMatches(policy.AllowList, policy.Groups, name)
+	}
+	return policy.DefaultEnabled
+}
+
+// toolListMatches 判断工具是否命中名单；名单项可以是工具名或 groups 中的分组名，大小写不敏感。
+func toolListMatches(list []string, groups map[string][]string, name string) bool {
+	for _, item := range list {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if item == name || item == "*" {
+			return true
+		}
+		for group, members := range groups {
+			if strings.ToLower(strings.TrimSpace(group)) != item {
+				continue
+			}
+			for _, member := range members {
+				if strings.ToLower(strings.TrimSpace(member)) == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// SetProvider 在运行时启用或替换 Provider，并刷新工具列表。
+func (s *Server) SetProvider(name string, p provider.Provider) {
+	s.providersMu.Lock()
+	if s.providers == nil {
+		s.providers = make(map[string]provider.Provider)
+	}
+	s.providers[name] = p
+	s.providersMu.Unlock()
+	s.invalidateAdapterReads(name)
+	s.RefreshTools()
+}
+
+// RemoveProvider 在运行时禁用 Provider，并刷新工具列表。
+func (s *Server) RemoveProvider(name string) {
+	s.providersMu.Lock()
+	delete(s.providers, name)
+	s.providersMu.Unlock()
+	s.invalidateAdapterReads(name)
+	s.RefreshTools()
+}
+
+// ApplyProviders 以新的 Provider 集合整体替换当前集合（用于配置热加载启用/禁用适配器），返回新增与移除的名称。
+// 工具列表只在替换完成后刷新一次，已连接会话只会收到一次合并后的 notifications/tools/list_changed。
+func (s *Server) ApplyProviders(next map[string]provider.Provider) (added, removed []string) {
+	s.providersMu.Lock()
+	for name := range s.providers {
+		if _, ok := next[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	for name := range next {
+		if _, ok := s.providers[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	providers := make(map[string]provider.Provider, len(next))
+	for name, p := range next {
+		providers[name] = p
+	}
+	s.providers = providers
+	s.providersMu.Unlock()
+	// 凭证可能随配置一起变化，缓存的读取结果不再可靠
+	s.invalidateAdapterReads("")
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	if len(added) > 0 || len(removed) > 0 {
+		log.Info().Str("component", "mcp").Strs("added", added).Strs("removed", removed).Msg("providers changed")
+	}
+	s.RefreshTools()
+	return added, removed
+}
+
+// SetToolPolicy 在运行时替换工具治理配置并刷新工具列表。
+func (s *Server) SetToolPolicy(policy *pkgconfig.ToolGovernanceConfig) {
+	s.toolMu.Lock()
+	s.toolPolicy = policy
+	s.toolMu.Unlock()
+	s.RefreshTools()
+}
+
+// lookupProvider 并发安全地按名称获取 Provider；多租户请求只查找所属租户的 Provider。
+// 只获得只读 scope 的 Provider 返回只读包装，写入类工具调用时直接报错。
+func (s *Server) lookupProvider(ctx context.Context, name string) (provider.Provider, bool) {
+	if providers, ok := tenantProvidersFrom(ctx); ok {
+		p, ok := providers[name]
+		return guardReadOnly(p), ok
+	}
+	s.providersMu.RLock()
+	defer s.providersMu.RUnlock()
+	p, ok := s.providers[name]
+	return s.cachedReads(ctx, name, guardReadOnly(p)), ok
+}
+
+// providerNames 返回已配置 Provider 名称（升序）。
+func (s *Server) providerNames(ctx context.Context) []string {
+	snapshot := s.providerSnapshot(ctx)
+	names := make([]string, 0, len(snapshot))
+	for name := range snapshot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// providerSnapshot 返回 Provider 映射的副本，供同步引擎在锁外使用。
+func (s *Server) providerSnapshot(ctx context.Context) map[string]provider.Provider {
+	if providers, ok := tenantProvidersFrom(ctx); ok {
+		snapshot := make(map[string]provider.Provider, len(providers))
+		for name, p := range providers {
+			snapshot[name] = guardReadOnly(p)
+		}
+		return snapshot
+	}
+	s.providersMu.RLock()
+	defer s.providersMu.RUnlock()
+	snapshot := make(map[string]provider.Provider, len(s.providers))
+	for name, p := range s.providers {
+		snapshot[name] = guardReadOnly(p)
+	}
+	return snapshot
+}
+
+// guardReadOnly 为只获得只读 scope 的 Provider 套上只读包装
+func guardReadOnly(p provider.Provider) provider.Provider {
+	if p != nil && provider.IsReadOnly(p) {
+		return provider.ReadOnlyGuard(p)
+	}
+	return p
+}
+
+// hasWritableProvider 是否至少有一个已认证且未被限制为只读 scope 的 Provider。
+func (s *Server) hasWritableProvider() bool {
+	s.providersMu.RLock()
+	defer s.providersMu.RUnlock()
+	for _, p := range s.providers {
+		if p != nil && p.IsAuthenticated() && !provider.IsReadOnly(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasHealthyProvider 是否至少有一个已认证的 Provider。
+func (s *Server) hasHealthyProvider() bool {
+	s.providersMu.RLock()
+	defer s.providersMu.RUnlock()
+	for _, p := range s.providers {
+		if p != nil && p.IsAuthenticated() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func listToolNames(t *testing.T, session *sdkmcp.ClientSession) map[string]bool {
+	t.Helper()
+	res, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	names := make(map[string]bool, len(res.Tools))
+	for _, tool := range res.Tools {
+		names[tool.Name] = true
+	}
+	return names
+}
+
+func TestProviderToolsHiddenWithoutProvider(t *testing.T) {
+	s := NewServer()
+	tools := s.GetTools()
+	for name := range toolRequirements {
+		if tools[name] {
+			t.Fatalf("%s should not be registered without providers", name)
+		}
+	}
+	if !tools["list_tasks"] || !tools["get_server_info"] {
+		t.Fatalf("local tools should always be registered")
+	}
+
+	s = NewServer(WithProviders(map[string]provider.Provider{"google": &mockProvider{}}))
+	if !s.GetTools()["sync_push"] {
+		t.Fatalf("sync_push should be registered when a provider is authenticated")
+	}
+}
+
+// readOnlyMockProvider 登录时只获得只读 scope 的 Provider
+type readOnlyMockProvider struct {
+	mockProvider
+}
+
+func (m *readOnlyMockProvider) ReadOnly() bool { return true }
+
+func TestReadOnlyProviderBlocksWrites(t *testing.T) {
+	s := NewServer(WithProviders(map[string]provider.Provider{"google": &readOnlyMockProvider{}}))
+	tools := s.GetTools()
+	if tools["sync_push"] || tools["decompose_task_with_provider"] {
+		t.Fatalf("write tools should be hidden when every provider is read-only")
+	}
+	if !tools["sync_pull"] {
+		t.Fatalf("sync_pull should stay registered for a read-only provider")
+	}
+
+	p, ok := s.lookupProvider(context.Background(), "google")
+	if !ok {
+		t.Fatal("expected provider lookup to succeed")
+	}
+	if _, err := p.ListTaskLists(context.Background()); err != nil {
+		t.Fatalf("reads should pass through: %v", err)
+	}
+	if _, err := p.CreateTask(context.Background(), "@default", &model.Task{Title: "x"}); !errors.Is(err, provider.ErrReadOnlyScopes) {
+		t.Fatalf("expected ErrReadOnlyScopes, got %v", err)
+	}
+}
+
+func TestGrantedReadOnly(t *testing.T) {
+	cases := []struct {
+		granted []string
+		want    bool
+	}{
+		{nil, false},
+		{[]string{"https://graph.microsoft.com/Tasks.Read", "offline_access"}, true},
+		{[]string{"https://graph.microsoft.com/tasks.readwrite"}, false},
+		{[]string{"Tasks.ReadWrite"}, false},
+	}
+	for _, tc := range cases {
+		if got := provider.GrantedReadOnly(tc.granted, "Tasks.ReadWrite"); got != tc.want {
+			t.Fatalf("GrantedReadOnly(%v) = %v, want %v", tc.granted, got, tc.want)
+		}
+	}
+}
+
+func TestSetProviderNotifiesToolListChanged(t *testing.T) {
+	s := NewServer()
+	changed := make(chan struct{}, 4)
+	session := connectBreakdownClient(t, s, &sdkmcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *sdkmcp.ToolListChangedRequest) {
+			changed <- struct{}{}
+		},
+	})
+	if listToolNames(t, session)["sync_pull"] {
+		t.Fatalf("sync_pull should be hidden before a provider is enabled")
+	}
+
+	waitChanged := func() {
+		t.Helper()
+		select {
+		case <-changed:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected tools/list_changed notification")
+		}
+	}
+
+	s.SetProvider("google", &mockProvider{})
+	waitChanged()
+	if !listToolNames(t, session)["sync_pull"] {
+		t.Fatalf("sync_pull should be listed after enabling provider")
+	}
+
+	s.RemoveProvider("google")
+	waitChanged()
+	tools := listToolNames(t, session)
+	if tools["sync_pull"] || tools["decompose_task_with_provider"] {
+		t.Fatalf("provider tools should be removed after disabling provider")
+	}
+	if !tools["list_tasks"] {
+		t.Fatalf("local tools should stay registered")
+	}
+}
+
+func TestToolPolicyFiltersRegisteredTools(t *testing.T) {
+	s := NewServer(WithToolPolicy(&pkgconfig.ToolGovernanceConfig{ReadOnly: true}))
+	tools := s.GetTools()
+	if !tools["list_tasks"] || !tools["export_tasks"] {
+		t.Fatalf("read tools should stay registered in read-only mode")
+	}
+	for _, name := range []string{"create_task", "delete_task", "update_task", "import_tasks"} {
+		if tools[name] {
+			t.Fatalf("%s should be hidden in read-only mode", name)
+		}
+	}
+
+	s = NewServer(WithToolPolicy(&pkgconfig.ToolGovernanceConfig{
+		Enabled:   true,
+		AllowList: []string{"reading", "create_task", "delete_task"},
+		DenyList:  []string{"Delete_Task"},
+		Groups:    map[string][]string{"reading": {"list_tasks", "get_task"}},
+	}))
+	tools = s.GetTools()
+	if len(tools) != 3 || !tools["list_tasks"] || !tools["get_task"] || !tools["create_task"] {
+		t.Fatalf("unexpected tools with allow/deny list: %v", tools)
+	}
+
+	// enabled=false 时忽略名单
+	s = NewServer(WithToolPolicy(&pkgconfig.ToolGovernanceConfig{DenyList: []string{"create_task"}}))
+	if !s.GetTools()["create_task"] {
+		t.Fatalf("deny_list should be ignored when governance is disabled")
+	}
+
+	s = NewServer(WithToolPolicy(&pkgconfig.ToolGovernanceConfig{Enabled: true, DefaultEnabled: false}))
+	if len(s.GetTools()) != 0 {
+		t.Fatalf("default_enabled=false without allow_list should register nothing")
+	}
+}
+
+func TestReadOnlyToolsAnnotated(t *testing.T) {
+	s := NewServer()
+	session := connectBreakdownClient(t, s, nil)
+	res, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	for _, tool := range res.Tools {
+		readOnly := tool.Annotations != nil && tool.Annotations.ReadOnlyHint
+		if readOnly != readOnlyTools[tool.Name] {
+			t.Fatalf("%s readOnlyHint=%v", tool.Name, readOnly)
+		}
+	}
+}
+
+func TestApplyProvidersBatchesToolListChanged(t *testing.T) {
+	s := NewServer()
+	changed := make(chan struct{}, 4)
+	session := connectBreakdownClient(t, s, &sdkmcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *sdkmcp.ToolListChangedRequest) {
+			changed <- struct{}{}
+		},
+	})
+	init := session.InitializeResult()
+	if init.Capabilities.Tools == nil || !init.Capabilities.Tools.ListChanged {
+		t.Fatalf("expected tools.listChanged capability, got %+v", init.Capabilities.Tools)
+	}
+
+	added, removed := s.ApplyProviders(map[string]provider.Provider{"google": &mockProvider{}, "todoist": &mockProvider{}})
+	if len(added) != 2 || added[0] != "google" || added[1] != "todoist" || len(removed) != 0 {
+		t.Fatalf("unexpected diff: added=%v removed=%v", added, removed)
+	}
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected tools/list_changed notification")
+	}
+	if !listToolNames(t, session)["sync_now"] {
+		t.Fatalf("sync_now should be listed after providers are enabled")
+	}
+
+	// 只保留 todoist：工具集合不变，不应再次通知
+	added, removed = s.ApplyProviders(map[string]provider.Provider{"todoist": &mockProvider{}})
+	if len(added) != 0 || len(removed) != 1 || removed[0] != "google" {
+		t.Fatalf("unexpected diff: added=%v removed=%v", added, removed)
+	}
+	select {
+	case <-changed:
+		t.Fatalf("unexpected notification when tool surface is unchanged")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	s.SetToolPolicy(&pkgconfig.ToolGovernanceConfig{ReadOnly: true})
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected tools/list_changed after policy change")
+	}
+	tools := listToolNames(t, session)
+	if tools["sync_now"] || tools["create_task"] || !tools["list_tasks"] {
+		t.Fatalf("unexpected tools after switching to read-only: %v", tools)
+	}
+}
@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/history"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+const (
+	// webhookMaxBodyBytes 未配置 mcp.http.max_request_bytes 时 webhook 请求体的上限
+	webhookMaxBodyBytes = 1 << 20
+	// webhookSyncTimeout webhook 触发的单次拉取（含等待正在运行的 sync_now）的时限
+	webhookSyncTimeout = 2 * time.Minute
+)
+
+// webhookVerifier 校验 webhook 请求的签名
+type webhookVerifier func(header http.Header, body []byte, secret string) bool
+
+// webhookVerifiers 支持接收 webhook 的 Provider 及其签名校验方式
+var webhookVerifiers = map[string]webhookVerifier{
+	"todoist": verifyTodoistWebhook,
+}
+
+// verifyTodoistWebhook Todoist 在 X-Todoist-Hmac-SHA256 头中携带以应用 client secret 计算的请求体 HMAC-SHA256（base64）
+func verifyTodoistWebhook(header http.Header, body []byte, secret string) bool {
+	signature, err := base64.StdEncoding.DecodeString(header.Get("X-Todoist-Hmac-SHA256"))
+	if err != nil || len(signature) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(signature, mac.Sum(nil))
+}
+
+// webhookState webhook 触发的拉取状态：同一 Provider 同时只拉取一次，期间收到的 webhook 合并为结束后的一次拉取
+type webhookState struct {
+	mu      gosync.Mutex
+	running map[string]bool
+	pending map[string]bool
+}
+
+// WithWebhookConfig 设置 Provider webhook 接收配置
+func WithWebhookConfig(cfg *pkgconfig.WebhookConfig) ServerOption {
+	return func(s *Server) {
+		s.webhookConfig = cfg
+	}
+}
+
+func (s *Server) webhooksEnabled() bool {
+	return s.webhookConfig != nil && s.webhookConfig.Enabled
+}
+
+// webhookSecret 返回校验 Provider webhook 的密钥：优先 mcp.webhooks.secrets，其次该适配器的 client_secret
+func (s *Server) webhookSecret(name string) string {
+	if secret := strings.TrimSpace(s.webhookConfig.Secrets[name]); secret != "" {
+		return secret
+	}
+	return strings.TrimSpace(s.adapterConfig[name].ClientSecret)
+}
+
+// withWebhooks 在认证中间件之外挂载 webhook 端点：Provider 回调不携带 MCP token，由请求签名认证
+func (s *Server) withWebhooks(handler http.Handler) http.Handler {
+	if !s.webhooksEnabled() {
+		return handler
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+s.routePath("/webhooks/{provider}"), s.handleWebhook)
+	mux.Handle("/", handler)
+	if trusted := s.trustedProxies(); len(trusted) > 0 {
+		return forwardedHeadersMiddleware(trusted, mux)
+	}
+	return mux
+}
+
+// handleWebhook 校验 webhook 签名后立即应答，并在后台拉取该 Provider、推送资源更新通知
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("provider")
+	verify, ok := webhookVerifiers[name]
+	if !ok {
+		http.Error(w, "webhooks not supported for provider "+name, http.StatusNotFound)
+		return
+	}
+	secret := s.webhookSecret(name)
+	if secret == "" {
+		http.Error(w, "webhook secret not configured for provider "+name, http.StatusNotFound)
+		return
+	}
+
+	limit := int64(webhookMaxBodyBytes)
+	if s.httpConfig != nil && s.httpConfig.MaxRequestBytes > 0 {
+		limit = s.httpConfig.MaxRequestBytes
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !verify(r.Header, body, secret) {
+		log.Warn().Str("provider", name).Str("remote", r.RemoteAddr).Msg("webhook 签名校验失败")
+		http.Error(w, "invalid webhook signature", http.StatusUnauthorized)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	s.triggerWebhookSync(name)
+}
+
+// triggerWebhookSync 在后台拉取 Provider；已在拉取时只标记结束后再拉取一次
+func (s *Server) triggerWebhookSync(name string) {
+	state := &s.webhooks
+	state.mu.Lock()
+	if state.running[name] {
+		state.pending[name] = true
+		state.mu.Unlock()
+		return
+	}
+	if state.running == nil {
+		state.running = make(map[string]bool)
+		state.pending = make(map[string]bool)
+	}
+	state.running[name] = true
+	state.mu.Unlock()
+
+	go func() {
+		for {
+			s.syncFromWebhook(name)
+
+			state.mu.Lock()
+			if !state.pending[name] {
+				state.running[name] = false
+				state.mu.Unlock()
+				return
+			}
+			state.pending[name] = false
+			state.mu.Unlock()
+		}
+	}()
+}
+
+// syncFromWebhook 从 Provider 拉取到本地并通知订阅者；与 sync_now 共用运行状态，避免并发写入本地存储
+func (s *Server) syncFromWebhook(name string) {
+	if s.taskStore == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(history.WithOrigin(context.Background(), history.OriginWebhook, name), webhookSyncTimeout)
+	defer cancel()
+	if p, ok := s.lookupProvider(ctx, name); !ok || p == nil || !p.IsAuthenticated() {
+		log.Warn().Str("provider", name).Msg("收到 webhook，但 Provider 未启用或未认证")
+		return
+	}
+
+	switch err := s.pullProvider(ctx, name); {
+	case errors.Is(err, errSyncBusy):
+		log.Warn().Str("provider", name).Msg("等待正在进行的同步超时，跳过本次 webhook 拉取")
+	case err != nil:
+		log.Error().Err(err).Str("provider", name).Msg("webhook 触发的同步失败")
+	}
+}
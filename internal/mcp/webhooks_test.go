@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	pkgconfig "github.com/yeisme/taskbridge/pkg/config"
+)
+
+func todoistSignature(body, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookTriggersPullAndBypassesTokenAuth(t *testing.T) {
+	cfg := pkgconfig.DefaultConfig().MCP.Intelligence
+	remote := &remoteTasksProvider{remote: []model.Task{
+		{ID: "todoist-inbox-r1", Title: "远端任务", Status: model.StatusTodo, Source: model.SourceTodoist, SourceRawID: "r1"},
+	}}
+	s, store, ctx := newIntelligenceTestServer(t, cfg, map[string]provider.Provider{"todoist": remote, "google": &mockProvider{}})
+	s.securityConfig = &pkgconfig.SecurityConfig{Enabled: true, AuthMode: "token", Tokens: []string{"mcp-token"}}
+	s.webhookConfig = &pkgconfig.WebhookConfig{Enabled: true, Secrets: map[string]string{"todoist": "s3cret"}}
+
+	wrapped, err := s.wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	if err != nil {
+		t.Fatalf("wrap handler: %v", err)
+	}
+	handler := s.withWebhooks(wrapped)
+
+	body := `{"event_name":"item:added","event_data":{"id":"r1"}}`
+	cases := []struct {
+		path, signature string
+		want            int
+	}{
+		{"/webhooks/todoist", "", http.StatusUnauthorized},
+		{"/webhooks/todoist", todoistSignature(body, "wrong"), http.StatusUnauthorized},
+		{"/webhooks/google", todoistSignature(body, "s3cret"), http.StatusNotFound},
+		{"/mcp", "", http.StatusUnauthorized},
+		{"/webhooks/todoist", todoistSignature(body, "s3cret"), http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(body))
+		if tc.signature != "" {
+			req.Header.Set("X-Todoist-Hmac-SHA256", tc.signature)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Fatalf("%s with signature %q: got %d, want %d", tc.path, tc.signature, rec.Code, tc.want)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := store.GetTask(ctx, "todoist-inbox-r1"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("webhook should pull the remote task into local storage")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 拉取记录在任务写入后才更新，等待后台协程结束
+	for {
+		s.syncState.mu.Lock()
+		record := s.syncState.lastRun["todoist"]
+		s.syncState.mu.Unlock()
+		if record != nil {
+			if record.Direction != "pull" || record.Error != "" {
+				t.Fatalf("unexpected webhook sync record: %+v", record)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("webhook sync should be recorded for sync_status")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebhooksDisabledLeavesHandlerUnchanged(t *testing.T) {
+	s := NewServer()
+	handler := http.NotFoundHandler()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/todoist", strings.NewReader("{}"))
+	rec := httptest.NewRecorder()
+	s.withWebhooks(handler).ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("webhook endpoint should not exist when disabled, got %d", rec.Code)
+	}
+}
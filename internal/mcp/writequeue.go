@@ -0,0 +1,485 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+// WriteQueueFileName 离线写入队列文件名（位于存储目录）
+const WriteQueueFileName = "write_queue.json"
+
+// 排队写入的操作类型
+const (
+	queuedCreate   = "create"
+	queuedUpdate   = "update"
+	queuedComplete = "complete"
+)
+
+// 排队写入的状态
+const (
+	// queuedPending 等待 Provider 恢复后重放
+	queuedPending = "pending"
+	// queuedConflict 远端在离线期间也被修改，不再自动重放，需用 resolve_conflict 处理
+	queuedConflict = "conflict"
+	// queuedFailed 连接恢复后远端拒绝了写入
+	queuedFailed = "failed"
+)
+
+// errQueuedConflict 重放时发现远端在离线期间也被修改
+var errQueuedConflict = errors.New("remote task changed while offline")
+
+// queuedWrite Provider 不可达时暂存的写入。重放时写入本地任务的最新内容，同一任务的多次写入合并为一条
+type queuedWrite struct {
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+	Op       string `json:"op"`
+	// TaskID 本地任务 ID
+	TaskID string `json:"task_id"`
+	Title  string `json:"title,omitempty"`
+	ListID string `json:"list_id,omitempty"`
+	// Since 已知远端版本的时间，重放时远端更新时间晚于它视为冲突
+	Since         time.Time  `json:"since"`
+	QueuedAt      time.Time  `json:"queued_at"`
+	State         string     `json:"state"`
+	Attempts      int        `json:"attempts"`
+	LastAttemptAt *time.Time `json:"last_attempt_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// WriteQueue 离线写入队列，保存在存储目录，服务重启后继续重放
+type WriteQueue struct {
+	mu       sync.Mutex
+	filePath string
+	entries  []queuedWrite
+	// offline 最近一次写入因不可达失败的 Provider 及时间，写入成功后清除
+	offline map[string]time.Time
+}
+
+// NewWriteQueue 打开 basePath/write_queue.json 中的离线写入队列
+func NewWriteQueue(basePath string) (*WriteQueue, error) {
+	if err := os.MkdirAll(basePath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create write queue dir: %w", err)
+	}
+	q := &WriteQueue{filePath: filepath.Join(basePath, WriteQueueFileName), offline: make(map[string]time.Time)}
+	data, err := storecrypt.ReadFile(q.filePath)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read write queue: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &q.entries); err != nil {
+			return nil, fmt.Errorf("failed to parse write queue: %w", err)
+		}
+	}
+	return q, nil
+}
+
+// WithWriteQueue 设置离线写入队列；未设置时 Provider 不可达的写入直接失败
+func WithWriteQueue(queue *WriteQueue) ServerOption {
+	return func(s *Server) {
+		s.writeQueue = queue
+	}
+}
+
+// save 写入队列文件，调用方持有锁
+func (q *WriteQueue) save() error {
+	data, err := json.MarshalIndent(q.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal write queue: %w", err)
+	}
+	tmp := q.filePath + ".tmp"
+	if err := storecrypt.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write write queue: %w", err)
+	}
+	return os.Rename(tmp, q.filePath)
+}
+
+// enqueue 加入一条写入；同一任务已有排队的写入时合并：新建操作保持为新建，
+// 冲突或失败的写入重新等待重放（冲突检测仍以最早的 Since 为准）
+func (q *WriteQueue) enqueue(write queuedWrite) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range q.entries {
+		entry := &q.entries[i]
+		if entry.Provider != write.Provider || entry.TaskID != write.TaskID {
+			continue
+		}
+		if entry.Op != queuedCreate {
+			entry.Op = write.Op
+		}
+		entry.Title = write.Title
+		entry.State = queuedPending
+		if write.ListID != "" {
+			entry.ListID = write.ListID
+		}
+		return q.save()
+	}
+	q.entries = append(q.entries, write)
+	return q.save()
+}
+
+// snapshot 返回队列副本，按入队顺序排列
+func (q *WriteQueue) snapshot() []queuedWrite {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]queuedWrite(nil), q.entries...)
+}
+
+// forTask 返回任务排队的写入
+func (q *WriteQueue) forTask(taskID string) []queuedWrite {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var writes []queuedWrite
+	for _, entry := range q.entries {
+		if entry.TaskID == taskID {
+			writes = append(writes, entry)
+		}
+	}
+	return writes
+}
+
+// hasPending 任务是否有等待重放的写入
+func (q *WriteQueue) hasPending(taskID string) bool {
+	for _, entry := range q.forTask(taskID) {
+		if entry.State == queuedPending {
+			return true
+		}
+	}
+	return false
+}
+
+// settle 记录一条写入的重放结果：done 时移出队列，否则更新其状态
+func (q *WriteQueue) settle(entry queuedWrite, done bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range q.entries {
+		if q.entries[i].ID != entry.ID {
+			continue
+		}
+		if done {
+			q.entries = append(q.entries[:i], q.entries[i+1:]...)
+		} else {
+			q.entries[i] = entry
+		}
+		return q.save()
+	}
+	return nil
+}
+
+// dropTask 移除任务排队的全部写入，如冲突已通过 resolve_conflict 处理
+func (q *WriteQueue) dropTask(taskID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	kept := q.entries[:0]
+	for _, entry := range q.entries {
+		if entry.TaskID != taskID {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == len(q.entries) {
+		return nil
+	}
+	q.entries = kept
+	return q.save()
+}
+
+// markOffline 记录 Provider 不可达
+func (q *WriteQueue) markOffline(name string, at time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.offline[name] = at
+}
+
+// markOnline 清除 Provider 的不可达标记
+func (q *WriteQueue) markOnline(name string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.offline, name)
+}
+
+// offlineSince 返回 Provider 最近一次不可达的时间
+func (q *WriteQueue) offlineSince(name string) (time.Time, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	at, ok := q.offline[name]
+	return at, ok
+}
+
+// providerUnreachable 判断错误是否因无法连接 Provider（网络错误、超时），而不是远端拒绝了请求
+func providerUnreachable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// queueWrite 将任务的写入加入离线队列，返回是否已排队。
+// 租户的 Provider 不在服务自身的 Provider 中，无法在后台重放，因此不排队
+func (s *Server) queueWrite(ctx context.Context, task *model.Task, providerName, op, listID string) bool {
+	if s.writeQueue == nil {
+		return false
+	}
+	if _, tenantScoped := tenantProvidersFrom(ctx); tenantScoped {
+		return false
+	}
+	now := time.Now()
+	since := now
+	if task.Metadata != nil && !task.Metadata.LastSyncAt.IsZero() {
+		since = task.Metadata.LastSyncAt
+	}
+	write := queuedWrite{
+		ID:       generateID(),
+		Provider: providerName,
+		Op:       op,
+		TaskID:   task.ID,
+		Title:    task.Title,
+		ListID:   listID,
+		Since:    since,
+		QueuedAt: now,
+		State:    queuedPending,
+	}
+	if err := s.writeQueue.enqueue(write); err != nil {
+		log.Warn().Err(err).Str("component", "mcp").Str("task", task.ID).Msg("failed to queue offline write")
+		return false
+	}
+	log.Info().Str("component", "mcp").Str("provider", providerName).Str("task", task.ID).Str("op", op).Msg("write queued for offline replay")
+	return true
+}
+
+// pushTaskWrite 将任务的修改回写到所属 provider，返回回写状态（updated、queued、failed、skipped）和说明。
+// provider 不可达，或该任务已有排队的写入（保持写入顺序）时加入离线队列
+func (s *Server) pushTaskWrite(ctx context.Context, task *model.Task, op string) (string, string) {
+	if task.Source == "" || task.Source == model.SourceLocal || strings.TrimSpace(task.SourceRawID) == "" {
+		return "skipped", "local task"
+	}
+	if parseMicrosoftStepID(task.SourceRawID) != "" {
+		return "skipped", "microsoft checklist step is synced with its parent task"
+	}
+	name := string(task.Source)
+	if s.writeQueue != nil && s.writeQueue.hasPending(task.ID) && s.queueWrite(ctx, task, name, op, task.ListID) {
+		return "queued", "earlier writes to this task are still queued"
+	}
+	p, ok := s.lookupProvider(ctx, name)
+	if !ok || p == nil || !p.IsAuthenticated() {
+		return "skipped", fmt.Sprintf("provider %s not available", name)
+	}
+	remoteTask := sanitizeTaskForRemote(*task)
+	if _, err := p.UpdateTask(ctx, task.ListID, &remoteTask); err != nil {
+		if providerUnreachable(err) && s.queueWrite(ctx, task, name, op, task.ListID) {
+			s.writeQueue.markOffline(name, time.Now())
+			return "queued", err.Error()
+		}
+		return "failed", err.Error()
+	}
+	s.markRemoteSynced(ctx, task)
+	// 直接写入成功后，之前冲突或失败的排队写入已过时
+	if s.writeQueue != nil {
+		if err := s.writeQueue.dropTask(task.ID); err != nil {
+			log.Warn().Err(err).Str("component", "mcp").Msg("failed to save write queue")
+		}
+	}
+	return "updated", ""
+}
+
+// markRemoteSynced 记录任务已写入远端，作为之后离线写入冲突检测的基准
+func (s *Server) markRemoteSynced(ctx context.Context, task *model.Task) {
+	if s.writeQueue != nil {
+		s.writeQueue.markOnline(string(task.Source))
+	}
+	if task.Metadata == nil {
+		task.Metadata = &model.TaskMetadata{Version: "1.0"}
+	}
+	task.Metadata.LastSyncAt = time.Now()
+	if s.taskStore != nil {
+		_ = s.taskStore.SaveTask(ctx, task)
+	}
+}
+
+// withLastSync 返回 LastSyncAt 为 at 的任务副本
+func withLastSync(task *model.Task, at time.Time) *model.Task {
+	clone := *task
+	metadata := model.TaskMetadata{Version: "1.0"}
+	if task.Metadata != nil {
+		metadata = *task.Metadata
+	}
+	metadata.LastSyncAt = at
+	clone.Metadata = &metadata
+	return &clone
+}
+
+// watchWriteQueue 按间隔重放离线写入队列，启动时立即重放一次
+func (s *Server) watchWriteQueue(ctx context.Context, interval time.Duration) {
+	s.ReplayWriteQueue(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ReplayWriteQueue(ctx)
+		}
+	}
+}
+
+// ReplayWriteQueue 按入队顺序重放排队的写入，返回完成的写入数。
+// 某个 Provider 仍不可达时保留它之后的写入，等待下次重放；远端在离线期间也被修改的写入标记为冲突
+func (s *Server) ReplayWriteQueue(ctx context.Context) int {
+	if s.writeQueue == nil || s.taskStore == nil {
+		return 0
+	}
+	providers := s.providerSnapshot(context.WithoutCancel(ctx))
+	blocked := make(map[string]bool)
+	done := 0
+	for _, entry := range s.writeQueue.snapshot() {
+		if ctx.Err() != nil {
+			return done
+		}
+		if entry.State != queuedPending || blocked[entry.Provider] {
+			continue
+		}
+		p := providers[entry.Provider]
+		if p == nil || !p.IsAuthenticated() {
+			blocked[entry.Provider] = true
+			continue
+		}
+
+		now := time.Now()
+		entry.Attempts++
+		entry.LastAttemptAt = &now
+		err := s.applyQueuedWrite(ctx, p, entry)
+		if err == nil {
+			s.invalidateAdapterReads(entry.Provider, entry.ListID)
+		}
+		switch {
+		case err == nil:
+			s.writeQueue.markOnline(entry.Provider)
+			done++
+		case providerUnreachable(err):
+			blocked[entry.Provider] = true
+			s.writeQueue.markOffline(entry.Provider, now)
+			entry.LastError = err.Error()
+		case errors.Is(err, errQueuedConflict):
+			s.writeQueue.markOnline(entry.Provider)
+			entry.State = queuedConflict
+			entry.LastError = err.Error()
+			log.Warn().Str("component", "mcp").Str("provider", entry.Provider).Str("task", entry.TaskID).Msg("queued write conflicts with remote changes; use resolve_conflict")
+		default:
+			s.writeQueue.markOnline(entry.Provider)
+			entry.State = queuedFailed
+			entry.LastError = err.Error()
+			log.Warn().Err(err).Str("component", "mcp").Str("provider", entry.Provider).Str("task", entry.TaskID).Msg("queued write rejected by provider")
+		}
+		if saveErr := s.writeQueue.settle(entry, err == nil); saveErr != nil {
+			log.Warn().Err(saveErr).Str("component", "mcp").Msg("failed to save write queue")
+		}
+	}
+	if done > 0 {
+		log.Info().Str("component", "mcp").Int("replayed", done).Msg("replayed queued writes")
+	}
+	return done
+}
+
+// applyQueuedWrite 将本地任务的最新内容写入 provider；本地任务已删除或已由同步推送时视为完成
+func (s *Server) applyQueuedWrite(ctx context.Context, p provider.Provider, entry queuedWrite) error {
+	local, err := s.taskStore.GetTask(ctx, entry.TaskID)
+	if err != nil || local == nil {
+		return nil
+	}
+
+	if entry.Op == queuedCreate {
+		if string(local.Source) == entry.Provider && strings.TrimSpace(local.SourceRawID) != "" {
+			return nil
+		}
+		listID := entry.ListID
+		if listID == "" {
+			if listID, err = defaultRemoteListID(ctx, p); err != nil {
+				return err
+			}
+		}
+		taskToSync := *local
+		if parentRemote := s.remoteParentID(ctx, local); parentRemote != "" {
+			taskToSync.ParentID = &parentRemote
+		}
+		taskToSync = sanitizeTaskForRemote(taskToSync)
+		created, err := p.CreateTask(ctx, listID, &taskToSync)
+		if err != nil {
+			return err
+		}
+		if created == nil {
+			return fmt.Errorf("provider %s returned no task", entry.Provider)
+		}
+		local.SourceRawID = created.SourceRawID
+		local.ListID = listID
+		local.Source = model.TaskSource(entry.Provider)
+		s.markRemoteSynced(ctx, local)
+		return nil
+	}
+
+	remote, err := p.GetTask(ctx, local.ListID, local.SourceRawID)
+	if err != nil {
+		return err
+	}
+	if remote != nil && remote.UpdatedAt.After(entry.Since) {
+		return fmt.Errorf("%w (remote updated at %s)", errQueuedConflict, remote.UpdatedAt.Format(time.RFC3339))
+	}
+	remoteTask := sanitizeTaskForRemote(*local)
+	if _, err := p.UpdateTask(ctx, local.ListID, &remoteTask); err != nil {
+		return err
+	}
+	s.markRemoteSynced(ctx, local)
+	return nil
+}
+
+// writeQueueStatus 汇总离线写入队列，供状态资源展示
+func (s *Server) writeQueueStatus() map[string]interface{} {
+	if s.writeQueue == nil {
+		return map[string]interface{}{"enabled": false}
+	}
+	entries := s.writeQueue.snapshot()
+	counts := map[string]int{queuedPending: 0, queuedConflict: 0, queuedFailed: 0}
+	for _, entry := range entries {
+		counts[entry.State]++
+	}
+	return map[string]interface{}{
+		"enabled":   true,
+		"pending":   counts[queuedPending],
+		"conflicts": counts[queuedConflict],
+		"failed":    counts[queuedFailed],
+		"writes":    entries,
+	}
+}
+
+// queuedWritesFor 返回 provider 排队中的写入数
+func (s *Server) queuedWritesFor(name string) int {
+	if s.writeQueue == nil {
+		return 0
+	}
+	count := 0
+	for _, entry := range s.writeQueue.snapshot() {
+		if entry.Provider == name {
+			count++
+		}
+	}
+	return count
+}
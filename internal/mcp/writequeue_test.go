@@ -0,0 +1,216 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+// offlineProvider 在 offline 时对所有请求返回网络错误
+type offlineProvider struct {
+	mockProvider
+	offline bool
+	remote  model.Task
+	updated []model.Task
+}
+
+func (p *offlineProvider) unreachable(op string) error {
+	return &url.Error{Op: op, URL: "https://tasks.googleapis.com", Err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", IsNotFound: true}}}
+}
+
+func (p *offlineProvider) GetTask(ctx context.Context, listID, taskID string) (*model.Task, error) {
+	if p.offline {
+		return nil, p.unreachable("Get")
+	}
+	cp := p.remote
+	return &cp, nil
+}
+
+func (p *offlineProvider) UpdateTask(ctx context.Context, listID string, task *model.Task) (*model.Task, error) {
+	if p.offline {
+		return nil, p.unreachable("Patch")
+	}
+	p.updated = append(p.updated, *task)
+	return task, nil
+}
+
+func (p *offlineProvider) CreateTask(ctx context.Context, listID string, task *model.Task) (*model.Task, error) {
+	if p.offline {
+		return nil, p.unreachable("Post")
+	}
+	return p.mockProvider.CreateTask(ctx, listID, task)
+}
+
+func newOfflineTestServer(t *testing.T) (*Server, *offlineProvider, *filestore.FileStorage, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := filestore.New(dir, "json")
+	if err != nil {
+		t.Fatalf("new task store: %v", err)
+	}
+	queue, err := NewWriteQueue(dir)
+	if err != nil {
+		t.Fatalf("new write queue: %v", err)
+	}
+	lastSync := time.Now().Add(-time.Hour)
+	local := &model.Task{
+		ID: "google-l1-r1", Title: "提交季度报告", Status: model.StatusTodo, Source: model.SourceGoogle, SourceRawID: "r1", ListID: "l1",
+		Metadata: &model.TaskMetadata{Version: "1.0", LastSyncAt: lastSync},
+	}
+	if err := store.SaveTask(context.Background(), local); err != nil {
+		t.Fatalf("seed task: %v", err)
+	}
+	remote := &offlineProvider{offline: true, remote: model.Task{
+		ID: "r1", Title: "提交季度报告", Status: model.StatusTodo, Source: model.SourceGoogle, SourceRawID: "r1", ListID: "l1",
+		UpdatedAt: lastSync.Add(-time.Minute),
+	}}
+	s := NewServer(WithTaskStorage(store), WithProviders(map[string]provider.Provider{"google": remote}), WithWriteQueue(queue))
+	return s, remote, store, dir
+}
+
+func TestOfflineWritesAreQueuedAndReplayed(t *testing.T) {
+	s, remote, store, dir := newOfflineTestServer(t)
+	ctx := context.Background()
+
+	res, err := s.handleUpdateTask(ctx, buildCallToolRequest(t, map[string]interface{}{"id": "google-l1-r1", "title": "提交 Q1 报告"}))
+	if err != nil {
+		t.Fatalf("update task: %v", err)
+	}
+	if len(res.Content) != 2 || !strings.Contains(res.Content[1].(*sdkmcp.TextContent).Text, "离线队列") {
+		t.Fatalf("expected queued note: %+v", res.Content)
+	}
+	if _, err := s.handleCompleteTask(ctx, buildCallToolRequest(t, map[string]interface{}{"id": "google-l1-r1"})); err != nil {
+		t.Fatalf("complete task: %v", err)
+	}
+	writes := s.writeQueue.snapshot()
+	if len(writes) != 1 || writes[0].Op != queuedComplete || writes[0].State != queuedPending {
+		t.Fatalf("writes to the same task should be merged: %+v", writes)
+	}
+	if _, offline := s.writeQueue.offlineSince("google"); !offline {
+		t.Fatal("provider should be marked offline")
+	}
+
+	// 本地版本较新，即使要求 refresh，get_task 也不用远端版本覆盖
+	res, err = s.handleGetTask(ctx, buildCallToolRequest(t, map[string]interface{}{"id": "google-l1-r1", "refresh": true}))
+	if err != nil {
+		t.Fatalf("get task: %v", err)
+	}
+	if got := parseJSONResult(t, res); got["fetched_from"] != "local" || got["queued_writes"] != float64(1) {
+		t.Fatalf("get_task should serve the local copy: %v", got)
+	}
+
+	// 队列保存在存储目录，重启后仍在
+	reopened, err := NewWriteQueue(dir)
+	if err != nil || len(reopened.snapshot()) != 1 {
+		t.Fatalf("queue should persist: %+v, %v", reopened.snapshot(), err)
+	}
+
+	if done := s.ReplayWriteQueue(ctx); done != 0 || len(s.writeQueue.snapshot()) != 1 {
+		t.Fatalf("replay should wait while offline: %d", done)
+	}
+	remote.offline = false
+	if done := s.ReplayWriteQueue(ctx); done != 1 {
+		t.Fatalf("expected one replayed write, got %d", done)
+	}
+	if len(remote.updated) != 1 || remote.updated[0].Title != "提交 Q1 报告" || remote.updated[0].Status != model.StatusCompleted {
+		t.Fatalf("latest local content should be written: %+v", remote.updated)
+	}
+	if len(s.writeQueue.snapshot()) != 0 {
+		t.Fatalf("replayed write should leave the queue: %+v", s.writeQueue.snapshot())
+	}
+	if _, offline := s.writeQueue.offlineSince("google"); offline {
+		t.Fatal("provider should be back online")
+	}
+	saved, _ := store.GetTask(ctx, "google-l1-r1")
+	if saved.Metadata == nil || time.Since(saved.Metadata.LastSyncAt) > time.Minute {
+		t.Fatalf("last sync time should be refreshed: %+v", saved.Metadata)
+	}
+}
+
+func TestOfflineWriteConflict(t *testing.T) {
+	s, remote, store, _ := newOfflineTestServer(t)
+	ctx := context.Background()
+
+	if _, err := s.handleUpdateTask(ctx, buildCallToolRequest(t, map[string]interface{}{"id": "google-l1-r1", "title": "本地修改"})); err != nil {
+		t.Fatalf("update task: %v", err)
+	}
+	// 离线期间远端也被修改
+	remote.offline = false
+	remote.remote.Title = "远端修改"
+	remote.remote.UpdatedAt = time.Now()
+
+	if done := s.ReplayWriteQueue(ctx); done != 0 || len(remote.updated) != 0 {
+		t.Fatalf("conflicting write should not be replayed: %d, %+v", done, remote.updated)
+	}
+	writes := s.writeQueue.snapshot()
+	if len(writes) != 1 || writes[0].State != queuedConflict || writes[0].Attempts != 1 {
+		t.Fatalf("write should be marked as conflict: %+v", writes)
+	}
+
+	session := connectBreakdownClient(t, s, nil)
+	res, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "taskbridge://status"})
+	if err != nil {
+		t.Fatalf("read status: %v", err)
+	}
+	var status struct {
+		Adapters []adapterStatus `json:"adapters"`
+		Offline  struct {
+			Enabled   bool `json:"enabled"`
+			Conflicts int  `json:"conflicts"`
+		} `json:"offline"`
+	}
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if !status.Offline.Enabled || status.Offline.Conflicts != 1 || len(status.Adapters) != 1 || status.Adapters[0].QueuedWrites != 1 {
+		t.Fatalf("status should report the conflict: %+v", status)
+	}
+
+	resolved, err := s.handleResolveConflict(ctx, buildCallToolRequest(t, map[string]interface{}{
+		"task_id": "google-l1-r1",
+		"keep":    "local",
+	}))
+	if err != nil {
+		t.Fatalf("resolve conflict: %v", err)
+	}
+	if out := parseJSONResult(t, resolved); out["remote"] != "updated" || len(remote.updated) != 1 || remote.updated[0].Title != "本地修改" {
+		t.Fatalf("resolve_conflict should push the merged task: %v, %+v", out, remote.updated)
+	}
+	if len(s.writeQueue.snapshot()) != 0 {
+		t.Fatalf("resolved conflict should leave the queue: %+v", s.writeQueue.snapshot())
+	}
+	if saved, _ := store.GetTask(ctx, "google-l1-r1"); saved.Title != "本地修改" {
+		t.Fatalf("unexpected local task: %+v", saved)
+	}
+}
+
+func TestProviderUnreachable(t *testing.T) {
+	p := &offlineProvider{}
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{p.unreachable("Get"), true},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+		{&url.Error{Op: "Get", URL: "https://example.com", Err: context.Canceled}, false},
+		{errors.New("googleapi: Error 400: Invalid task"), false},
+	}
+	for _, tc := range cases {
+		if got := providerUnreachable(tc.err); got != tc.want {
+			t.Errorf("providerUnreachable(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
 )
 
 type persistData struct {
@@ -131,7 +133,7 @@ func (s *FileStore) GetLatestPlan(_ context.Context, projectID string) (*PlanSug
 }
 
 func (s *FileStore) load() error {
-	data, err := os.ReadFile(s.filePath)
+	data, err := storecrypt.ReadFile(s.filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil
@@ -181,7 +183,7 @@ func (s *FileStore) save() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal project store: %w", err)
 	}
-	if err := os.WriteFile(s.filePath, bytes, 0o644); err != nil {
+	if err := storecrypt.WriteFile(s.filePath, bytes, 0o644); err != nil {
 		return fmt.Errorf("failed to write project store: %w", err)
 	}
 	return nil
@@ -14,6 +14,8 @@ import (
 	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	larktaskv2 "github.com/larksuite/oapi-sdk-go/v3/service/task/v2"
 	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/provider"
 )
 
 const (
@@ -610,7 +612,7 @@ func (c *Client) GetTask(ctx context.Context, taskID string) (*Task, error) {
 		return nil, fmt.Errorf("get task failed: code=%d msg=%s", resp.Code, resp.Msg)
 	}
 	if resp.Data == nil || resp.Data.Task == nil {
-		return nil, fmt.Errorf("task not found")
+		return nil, fmt.Errorf("%w: task %s", provider.ErrNotFound, taskID)
 	}
 
 	local := sdkTaskToLocal(resp.Data.Task)
@@ -20,6 +20,7 @@ import (
 	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	larkauthenv1 "github.com/larksuite/oapi-sdk-go/v3/service/authen/v1"
 	"github.com/rs/zerolog/log"
+	"github.com/yeisme/taskbridge/pkg/browser"
 	"github.com/yeisme/taskbridge/pkg/tokenstore"
 	"golang.org/x/oauth2"
 )
@@ -49,12 +50,13 @@ type OAuthConfig struct {
 
 // OAuth2Client OAuth2 客户端
 type OAuth2Client struct {
-	config     *OAuthConfig
-	token      *TokenResponse
-	tokenFile  string
-	state      string
-	mu         sync.RWMutex
-	httpClient *http.Client
+	config      *OAuthConfig
+	token       *TokenResponse
+	tokenFile   string
+	state       string
+	openBrowser bool
+	mu          sync.RWMutex
+	httpClient  *http.Client
 }
 
 // DefaultScopes 默认权限范围
@@ -66,6 +68,13 @@ var DefaultScopes = []string{
 	"contact:user.base:readonly",
 }
 
+// ReadOnlyScopes adapters.feishu.read_only 时申请的权限范围
+var ReadOnlyScopes = []string{
+	"task:tasklist:read",
+	"task:task:read",
+	"contact:user.base:readonly",
+}
+
 // NewOAuth2Client 创建 OAuth2 客户端
 func NewOAuth2Client(cfg *OAuthConfig) *OAuth2Client {
 	// 默认重定向 URL
@@ -118,6 +127,18 @@ func (c *OAuth2Client) SetTokenFile(path string) {
 	c.tokenFile = path
 }
 
+// SetScopes 设置授权时申请的 scope（如 adapters.feishu.scopes），为空时保持默认值
+func (c *OAuth2Client) SetScopes(scopes []string) {
+	if len(scopes) > 0 {
+		c.config.Scopes = append([]string(nil), scopes...)
+	}
+}
+
+// SetOpenBrowser 设置 StartAuthServer 是否自动在浏览器中打开授权链接
+func (c *OAuth2Client) SetOpenBrowser(open bool) {
+	c.openBrowser = open
+}
+
 // ExchangeCode 交换授权码获取 token
 func (c *OAuth2Client) ExchangeCode(ctx context.Context, code string) (*TokenResponse, error) {
 	client := lark.NewClient(
@@ -208,6 +229,11 @@ func (c *OAuth2Client) StartAuthServer(ctx context.Context, port int) (*TokenRes
 
 	// 打印授权 URL
 	fmt.Printf("\n请在浏览器中打开以下链接进行授权:\n\n%s\n\n", authURL)
+	if c.openBrowser {
+		if err := browser.Open(authURL); err != nil {
+			fmt.Printf("无法自动打开浏览器（%v），请手动复制上面的链接\n", err)
+		}
+	}
 	fmt.Println("等待授权回调...")
 
 	// 创建回调处理
@@ -634,6 +660,24 @@ func (c *OAuth2Client) GetUserInfo(ctx context.Context) (*UserInfoResponse, erro
 	return &userInfo, nil
 }
 
+// Account 查询当前登录用户（优先邮箱，无邮箱时使用姓名），并记录到已保存的 token 中供 auth status 显示
+func (c *OAuth2Client) Account(ctx context.Context) (string, error) {
+	userInfo, err := c.GetUserInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	account := userInfo.Data.Email
+	if account == "" {
+		account = userInfo.Data.Name
+	}
+	if account != "" && c.tokenFile != "" {
+		if err := tokenstore.SaveGrant(c.tokenFile, "feishu", tokenstore.Grant{Account: account}); err != nil {
+			return account, fmt.Errorf("failed to save token grant: %w", err)
+		}
+	}
+	return account, nil
+}
+
 // ================ 辅助类型和函数 ================
 
 // authTransport 实现 http.RoundTripper 接口，自动添加认证头
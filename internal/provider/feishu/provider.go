@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/yeisme/taskbridge/internal/model"
 	"github.com/yeisme/taskbridge/internal/provider"
 	"github.com/yeisme/taskbridge/pkg/paths"
+	"github.com/yeisme/taskbridge/pkg/tokenstore"
 )
 
 // Provider 飞书任务 Provider
@@ -485,6 +487,15 @@ func (p *Provider) Capabilities() provider.Capabilities {
 
 // ================ Token 管理 ================
 
+// ReadOnly 登录时未获得任务写权限时返回 true（实现 provider.ScopeLimiter）
+func (p *Provider) ReadOnly() bool {
+	if p.config.TokenFile == "" {
+		return false
+	}
+	grant, err := tokenstore.LoadGrant(p.config.TokenFile, "feishu")
+	return err == nil && provider.GrantedReadOnly(grant.Scopes, "task:task:write", "task:tasklist:write")
+}
+
 // GetTokenInfo 获取 token 信息
 func (p *Provider) GetTokenInfo() *provider.TokenInfo {
 	p.mu.RLock()
@@ -541,8 +552,13 @@ func (p *Provider) Logout() error {
 
 // NewProviderFromHome 从 HOME 目录加载凭证创建 Provider
 func NewProviderFromHome() (*Provider, error) {
-	credentialsPath := paths.GetCredentialsPath("feishu")
-	tokenPath := paths.GetTokenPath("feishu")
+	return NewProviderFromDir(paths.GetCredentialsDir())
+}
+
+// NewProviderFromDir 从指定凭证目录（如其他 profile 的 credentials 目录）加载凭证与 token 创建 Provider
+func NewProviderFromDir(credentialsDir string) (*Provider, error) {
+	credentialsPath := filepath.Join(credentialsDir, "feishu_credentials.json")
+	tokenPath := filepath.Join(credentialsDir, paths.TokenFileName)
 
 	if _, err := os.Stat(credentialsPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("credentials file not found at %s", credentialsPath)
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/provider/httpcache"
 )
 
 const (
@@ -27,22 +29,26 @@ type Client struct {
 	httpClient *http.Client
 	baseURL    string
 	token      string // OAuth2 access token
+	// cache 条件请求缓存，未变化的清单与任务拉取只收到 304
+	cache *httpcache.Cache
 }
 
 // NewClient 创建新的 Google Tasks 客户端
 func NewClient(token string) *Client {
+	cache := httpcache.New(0)
 	return &Client{
-		httpClient: &http.Client{
+		httpClient: httpcache.WrapClient(&http.Client{
 			Timeout: DefaultTimeout,
-		},
+		}, cache),
 		baseURL: BaseURL,
 		token:   token,
+		cache:   cache,
 	}
 }
 
-// SetHTTPClient 设置自定义 HTTP 客户端
+// SetHTTPClient 设置自定义 HTTP 客户端，保留条件请求缓存
 func (c *Client) SetHTTPClient(client *http.Client) {
-	c.httpClient = client
+	c.httpClient = httpcache.WrapClient(client, c.cache)
 }
 
 // TaskList Google任务列表
@@ -342,7 +348,7 @@ func (c *Client) doRequest(ctx context.Context, method, url string, body interfa
 	if resp.StatusCode >= 400 {
 		var apiErr APIError
 		if err := json.Unmarshal(respBody, &apiErr); err != nil {
-			return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+			apiErr.ErrorInfo.Message = string(respBody)
 		}
 		apiErr.StatusCode = resp.StatusCode
 		return &apiErr
@@ -371,6 +377,14 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("Google API error (status %d): %s", e.StatusCode, e.ErrorInfo.Message)
 }
 
+// Unwrap 404/410 响应返回 provider.ErrNotFound
+func (e *APIError) Unwrap() error {
+	if e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone {
+		return provider.ErrNotFound
+	}
+	return nil
+}
+
 // ToModelTask 将 Google Task 转换为统一任务模型
 func (t *Task) ToModelTask(listID, listName string) *model.Task {
 	task := &model.Task{
@@ -40,3 +40,38 @@ func TestCreateTask_WithParentQueryParam(t *testing.T) {
 	}
 }
 
+
+func TestListTaskLists_RevalidatesWithETag(t *testing.T) {
+	requests, notModified := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"lists-v1"`)
+		if r.Header.Get("If-None-Match") == `"lists-v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"items": []map[string]string{{"id": "list-1", "title": "My Tasks"}},
+		})
+	}))
+	defer srv.Close()
+
+	c := NewClient("test-token")
+	c.SetHTTPClient(srv.Client())
+	c.baseURL = srv.URL
+
+	for i := 0; i < 2; i++ {
+		lists, err := c.ListTaskLists(context.Background(), "", 0)
+		if err != nil {
+			t.Fatalf("ListTaskLists failed: %v", err)
+		}
+		if len(lists.Items) != 1 || lists.Items[0].Title != "My Tasks" {
+			t.Fatalf("unexpected lists: %+v", lists)
+		}
+	}
+	if requests != 2 || notModified != 1 {
+		t.Fatalf("second fetch should be answered with 304, requests=%d notModified=%d", requests, notModified)
+	}
+}
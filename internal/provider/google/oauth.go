@@ -3,8 +3,10 @@ package google
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -13,6 +15,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/yeisme/taskbridge/internal/auth"
+	"github.com/yeisme/taskbridge/pkg/browser"
 	"github.com/yeisme/taskbridge/pkg/paths"
 	"github.com/yeisme/taskbridge/pkg/tokenstore"
 	"golang.org/x/oauth2"
@@ -25,8 +29,21 @@ const (
 	ScopeTasks = "https://www.googleapis.com/auth/tasks"
 	// ScopeTasksReadOnly Google Tasks API read-only scope
 	ScopeTasksReadOnly = "https://www.googleapis.com/auth/tasks.readonly"
+	// ScopeOpenID 返回 ID token，用于识别登录账号
+	ScopeOpenID = "openid"
+	// ScopeEmail ID token 中包含账号邮箱
+	ScopeEmail = "email"
 )
 
+// RevokeURL Google OAuth2 撤销端点
+const RevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// DefaultScopes 默认授权范围：Tasks 读写，以及在 auth status 中显示账号邮箱所需的 openid/email
+var DefaultScopes = []string{ScopeTasks, ScopeOpenID, ScopeEmail}
+
+// ReadOnlyScopes adapters.google.read_only 时申请的授权范围
+var ReadOnlyScopes = []string{ScopeTasksReadOnly, ScopeOpenID, ScopeEmail}
+
 // OAuthConfig OAuth2 配置
 type OAuthConfig struct {
 	// ClientID OAuth2 客户端 ID
@@ -49,12 +66,18 @@ type OAuth2Client struct {
 	tokenFile string
 	// token 当前 Token
 	token *oauth2.Token
+	// openBrowser 启动回调服务后是否自动在浏览器中打开授权链接
+	openBrowser bool
+	// revokeURL 撤销端点，为空时使用 RevokeURL
+	revokeURL string
+	// verifier 当前授权请求的 PKCE code_verifier，由 GetAuthURL 生成、Exchange 时发送
+	verifier string
 }
 
 // NewOAuth2Client 创建 OAuth2 客户端
 func NewOAuth2Client(cfg *OAuthConfig) *OAuth2Client {
 	if len(cfg.Scopes) == 0 {
-		cfg.Scopes = []string{ScopeTasks}
+		cfg.Scopes = DefaultScopes
 	}
 
 	return &OAuth2Client{
@@ -63,20 +86,25 @@ func NewOAuth2Client(cfg *OAuthConfig) *OAuth2Client {
 			ClientSecret: cfg.ClientSecret,
 			RedirectURL:  cfg.RedirectURL,
 			Scopes:       cfg.Scopes,
-			Endpoint:     google.Endpoint,
+			Endpoint:     auth.PublicClientEndpoint(google.Endpoint, cfg.ClientSecret),
 		},
 		tokenFile: cfg.TokenFile,
 	}
 }
 
-// GetAuthURL 获取授权 URL
+// GetAuthURL 获取授权 URL，附带 PKCE code_challenge，未配置 client secret 时同样可以安全地交换授权码
 func (c *OAuth2Client) GetAuthURL(state string) string {
-	return c.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce)
+	c.verifier = oauth2.GenerateVerifier()
+	return c.config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce, oauth2.S256ChallengeOption(c.verifier))
 }
 
 // Exchange 使用授权码交换 token
 func (c *OAuth2Client) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
-	token, err := c.config.Exchange(ctx, code)
+	var opts []oauth2.AuthCodeOption
+	if c.verifier != "" {
+		opts = append(opts, oauth2.VerifierOption(c.verifier))
+	}
+	token, err := c.config.Exchange(ctx, code, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange token: %w", err)
 	}
@@ -170,6 +198,11 @@ func (c *OAuth2Client) StartAuthServer(ctx context.Context, port int) (*oauth2.T
 	fmt.Println()
 	fmt.Println(authURL)
 	fmt.Println()
+	if c.openBrowser {
+		if err := browser.Open(authURL); err != nil {
+			fmt.Printf("无法自动打开浏览器（%v），请手动复制上面的链接\n", err)
+		}
+	}
 	fmt.Println("等待授权回调...")
 
 	resultChan := make(chan *oauth2.Token, 1)
@@ -248,6 +281,34 @@ func (c *OAuth2Client) StartAuthServer(ctx context.Context, port int) (*oauth2.T
 	}
 }
 
+// DeviceLogin 使用设备授权流程（device code）获取 token，适用于无浏览器的服务器：
+// prompt 收到验证地址与用户码后展示给用户，用户在任意设备（如手机）上完成授权后返回 token。
+// 需要“电视和受限输入设备”类型的 OAuth 客户端；Google 只允许部分 scope 使用该流程，不支持时返回 invalid_scope 错误。
+func (c *OAuth2Client) DeviceLogin(ctx context.Context, prompt func(*oauth2.DeviceAuthResponse)) (*oauth2.Token, error) {
+	if c.config == nil {
+		return nil, fmt.Errorf("oauth config not initialized")
+	}
+	config := *c.config
+	if config.Endpoint.DeviceAuthURL == "" {
+		config.Endpoint.DeviceAuthURL = google.Endpoint.DeviceAuthURL
+	}
+	if config.Endpoint.TokenURL == "" {
+		config.Endpoint.TokenURL = google.Endpoint.TokenURL
+	}
+
+	resp, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+	prompt(resp)
+	token, err := config.DeviceAccessToken(ctx, resp)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+	c.token = token
+	return token, nil
+}
+
 // SetToken 设置 token
 func (c *OAuth2Client) SetToken(token *oauth2.Token) {
 	c.token = token
@@ -278,6 +339,18 @@ func (c *OAuth2Client) SetTokenFile(path string) {
 	c.tokenFile = path
 }
 
+// SetScopes 设置授权时申请的 scope（如 adapters.google.scopes），为空时保持默认值
+func (c *OAuth2Client) SetScopes(scopes []string) {
+	if len(scopes) > 0 {
+		c.config.Scopes = append([]string(nil), scopes...)
+	}
+}
+
+// SetOpenBrowser 设置 StartAuthServer 是否自动在浏览器中打开授权链接
+func (c *OAuth2Client) SetOpenBrowser(open bool) {
+	c.openBrowser = open
+}
+
 // SaveToken 保存 token 到文件
 func (c *OAuth2Client) SaveToken(token *oauth2.Token) error {
 	if c.tokenFile == "" {
@@ -286,11 +359,89 @@ func (c *OAuth2Client) SaveToken(token *oauth2.Token) error {
 	if err := tokenstore.Save(c.tokenFile, "google", token); err != nil {
 		return fmt.Errorf("failed to save token: %w", err)
 	}
+	if grant := tokenGrant(token); len(grant.Scopes) > 0 || grant.Account != "" {
+		if err := tokenstore.SaveGrant(c.tokenFile, "google", grant); err != nil {
+			return fmt.Errorf("failed to save token grant: %w", err)
+		}
+	}
 
 	c.token = token
 	return nil
 }
 
+// tokenGrant 从 token 响应中取出授予的 scope 与 ID token 中的账号邮箱；
+// 从文件加载的 token 不含这些信息，返回零值
+func tokenGrant(token *oauth2.Token) tokenstore.Grant {
+	scope, _ := token.Extra("scope").(string)
+	idToken, _ := token.Extra("id_token").(string)
+	return tokenstore.Grant{Scopes: strings.Fields(scope), Account: idTokenEmail(idToken)}
+}
+
+// idTokenEmail 读取 ID token 中的 email 声明。ID token 直接来自 token 端点的 TLS 响应，这里只解码不校验签名
+func idTokenEmail(idToken string) string {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.Email
+}
+
+// RevokeToken 调用 Google 撤销端点使授权失效并删除本地 token。
+// 撤销 refresh token 会同时使其签发的 access token 失效；token 已失效时 Google 返回 invalid_token，视为撤销成功。
+func (c *OAuth2Client) RevokeToken(ctx context.Context) error {
+	if c.token == nil {
+		if _, err := c.LoadToken(); err != nil {
+			return err
+		}
+	}
+	value := c.token.RefreshToken
+	if value == "" {
+		value = c.token.AccessToken
+	}
+
+	endpoint := c.revokeURL
+	if endpoint == "" {
+		endpoint = RevokeURL
+	}
+	form := url.Values{"token": {value}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create revoke request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("revoke request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), "invalid_token") {
+			return fmt.Errorf("revoke failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+	}
+
+	c.token = nil
+	if c.tokenFile != "" {
+		if err := tokenstore.Delete(c.tokenFile, "google"); err != nil {
+			return fmt.Errorf("failed to remove token: %w", err)
+		}
+	}
+	return nil
+}
+
 // TokenSource 获取 token source（自动刷新）
 func (c *OAuth2Client) TokenSource(ctx context.Context) oauth2.TokenSource {
 	if c.token == nil {
@@ -333,14 +484,51 @@ func (c *OAuth2Client) ValidToken(ctx context.Context) (*oauth2.Token, error) {
 	return c.RefreshToken(ctx)
 }
 
-// HTTPClient 获取配置了认证的 HTTP 客户端
+// HTTPClient 获取配置了认证的 HTTP 客户端：access token 过期时自动刷新并保存，收到 401 时强制刷新后重试一次
 func (c *OAuth2Client) HTTPClient(ctx context.Context) (*http.Client, error) {
+	if _, err := c.ValidToken(ctx); err != nil {
+		return nil, err
+	}
+	return auth.NewHTTPClient(c, 30*time.Second), nil
+}
+
+// AccessToken 返回有效的 access token，过期时自动刷新并保存（实现 auth.TokenRefresher）
+func (c *OAuth2Client) AccessToken(ctx context.Context) (string, error) {
+	if c.token != nil && c.token.Valid() {
+		return c.token.AccessToken, nil
+	}
 	token, err := c.ValidToken(ctx)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	if err := c.saveRefreshed(token); err != nil {
+		return "", err
 	}
+	return token.AccessToken, nil
+}
 
-	return c.config.Client(ctx, token), nil
+// ForceRefresh 忽略 access token 的过期时间，使用 refresh token 换取新 token 并保存（实现 auth.TokenRefresher）
+func (c *OAuth2Client) ForceRefresh(ctx context.Context) (string, error) {
+	if c.token == nil || c.token.RefreshToken == "" {
+		return "", fmt.Errorf("no refresh token available, please run 'taskbridge auth login google'")
+	}
+	token, err := c.config.TokenSource(ctx, &oauth2.Token{RefreshToken: c.token.RefreshToken}).Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh token: %w", err)
+	}
+	c.token = token
+	if err := c.saveRefreshed(token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// saveRefreshed 保存刷新后的 token（含 refresh token），未设置 token 文件时跳过
+func (c *OAuth2Client) saveRefreshed(token *oauth2.Token) error {
+	if c.tokenFile == "" {
+		return nil
+	}
+	return c.SaveToken(token)
 }
 
 // IsExpired 检查 token 是否过期
@@ -386,6 +574,10 @@ func LoadCredentials(credentialsFile string) (*OAuth2Client, error) {
 			RedirectURIs            []string `json:"redirect_uris"`
 			ProjectID               string   `json:"project_id"`
 		} `json:"installed"`
+		// 只提供 client ID 的精简格式（公共客户端 + PKCE）：{"client_id": "...", "redirect_url": "..."}
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		RedirectURL  string `json:"redirect_url"`
 	}
 
 	if err := json.Unmarshal(data, &rawCreds); err != nil {
@@ -401,12 +593,9 @@ func LoadCredentials(credentialsFile string) (*OAuth2Client, error) {
 		config = &oauth2.Config{
 			ClientID:     rawCreds.Web.ClientID,
 			ClientSecret: rawCreds.Web.ClientSecret,
-			Endpoint: oauth2.Endpoint{
-				AuthURL:  rawCreds.Web.AuthURI,
-				TokenURL: rawCreds.Web.TokenURI,
-			},
-			RedirectURL: redirectURL,
-			Scopes:      []string{ScopeTasks},
+			Endpoint:     credentialsEndpoint(rawCreds.Web.AuthURI, rawCreds.Web.TokenURI),
+			RedirectURL:  redirectURL,
+			Scopes:       DefaultScopes,
 		}
 	} else if rawCreds.Installed.ClientID != "" {
 		redirectURL := "http://localhost:8080/callback"
@@ -416,16 +605,25 @@ func LoadCredentials(credentialsFile string) (*OAuth2Client, error) {
 		config = &oauth2.Config{
 			ClientID:     rawCreds.Installed.ClientID,
 			ClientSecret: rawCreds.Installed.ClientSecret,
-			Endpoint: oauth2.Endpoint{
-				AuthURL:  rawCreds.Installed.AuthURI,
-				TokenURL: rawCreds.Installed.TokenURI,
-			},
-			RedirectURL: redirectURL,
-			Scopes:      []string{ScopeTasks},
+			Endpoint:     credentialsEndpoint(rawCreds.Installed.AuthURI, rawCreds.Installed.TokenURI),
+			RedirectURL:  redirectURL,
+			Scopes:       DefaultScopes,
+		}
+	} else if rawCreds.ClientID != "" {
+		redirectURL := rawCreds.RedirectURL
+		if redirectURL == "" {
+			redirectURL = "http://localhost:8080/callback"
+		}
+		config = &oauth2.Config{
+			ClientID:     rawCreds.ClientID,
+			ClientSecret: rawCreds.ClientSecret,
+			Endpoint:     google.Endpoint,
+			RedirectURL:  redirectURL,
+			Scopes:       DefaultScopes,
 		}
 	} else {
 		// 尝试使用 google.ConfigFromJSON
-		config, err = google.ConfigFromJSON(data, ScopeTasks)
+		config, err = google.ConfigFromJSON(data, DefaultScopes...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse credentials: %w", err)
 		}
@@ -433,14 +631,27 @@ func LoadCredentials(credentialsFile string) (*OAuth2Client, error) {
 
 	// 确保 Scopes 已设置
 	if len(config.Scopes) == 0 {
-		config.Scopes = []string{ScopeTasks}
+		config.Scopes = DefaultScopes
 	}
+	config.Endpoint = auth.PublicClientEndpoint(config.Endpoint, config.ClientSecret)
 
 	return &OAuth2Client{
 		config: config,
 	}, nil
 }
 
+// credentialsEndpoint 凭证文件中的授权与 token 地址，省略时使用 Google 默认端点
+func credentialsEndpoint(authURI, tokenURI string) oauth2.Endpoint {
+	endpoint := google.Endpoint
+	if authURI != "" {
+		endpoint.AuthURL = authURI
+	}
+	if tokenURI != "" {
+		endpoint.TokenURL = tokenURI
+	}
+	return endpoint
+}
+
 // TokenInfo Token 信息（用于调试）
 type TokenInfo struct {
 	AccessToken  string    `json:"access_token"`
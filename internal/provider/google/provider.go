@@ -5,19 +5,23 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/yeisme/taskbridge/internal/model"
 	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/pkg/paths"
+	"github.com/yeisme/taskbridge/pkg/tokenstore"
 )
 
 // Provider Google Tasks Provider
 type Provider struct {
-	client       *Client
-	oauth        *OAuth2Client
-	config       Config
-	capabilities provider.Capabilities
+	client         *Client
+	oauth          *OAuth2Client
+	serviceAccount *ServiceAccount
+	config         Config
+	capabilities   provider.Capabilities
 }
 
 // Config Google Provider 配置
@@ -27,6 +31,8 @@ type Config struct {
 	RedirectURL     string
 	CredentialsFile string
 	TokenFile       string
+	// Subject CredentialsFile 为服务账号密钥时通过全域委派代为访问的 Workspace 用户
+	Subject string
 }
 
 // NewProvider 创建 Google Tasks Provider
@@ -50,8 +56,19 @@ func NewProvider(cfg Config) (*Provider, error) {
 		},
 	}
 
-	// 初始化 OAuth2
+	// 初始化 OAuth2；凭证文件为服务账号密钥时改用服务账号认证
 	if cfg.CredentialsFile != "" {
+		data, err := os.ReadFile(cfg.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials file: %w", err)
+		}
+		if IsServiceAccountKey(data) {
+			p.serviceAccount, err = NewServiceAccount(data, cfg.Subject)
+			if err != nil {
+				return nil, err
+			}
+			return p, nil
+		}
 		oauth, err := LoadCredentials(cfg.CredentialsFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load credentials: %w", err)
@@ -60,7 +77,8 @@ func NewProvider(cfg Config) (*Provider, error) {
 			oauth.tokenFile = cfg.TokenFile
 		}
 		p.oauth = oauth
-	} else if cfg.ClientID != "" && cfg.ClientSecret != "" {
+	} else if cfg.ClientID != "" {
+		// 未提供 client secret 时作为公共客户端，授权码由 PKCE 保护
 		p.oauth = NewOAuth2Client(&OAuthConfig{
 			ClientID:     cfg.ClientID,
 			ClientSecret: cfg.ClientSecret,
@@ -84,6 +102,16 @@ func (p *Provider) DisplayName() string {
 
 // Authenticate 认证
 func (p *Provider) Authenticate(ctx context.Context, _ map[string]interface{}) error {
+	if p.serviceAccount != nil {
+		httpClient, err := p.serviceAccount.HTTPClient(ctx)
+		if err != nil {
+			return err
+		}
+		p.client = NewClient("")
+		p.client.SetHTTPClient(httpClient)
+		return nil
+	}
+
 	// 如果已有有效 token，直接返回
 	if p.oauth != nil {
 		_, err := p.oauth.ValidToken(ctx)
@@ -116,6 +144,10 @@ func (p *Provider) Authenticate(ctx context.Context, _ map[string]interface{}) e
 
 // IsAuthenticated 检查是否已认证
 func (p *Provider) IsAuthenticated() bool {
+	if p.serviceAccount != nil {
+		// 服务账号随时可以签发 JWT 换取 token，无需登录
+		return true
+	}
 	if p.oauth == nil {
 		return false
 	}
@@ -124,6 +156,10 @@ func (p *Provider) IsAuthenticated() bool {
 
 // RefreshToken 刷新 token
 func (p *Provider) RefreshToken(ctx context.Context) error {
+	if p.serviceAccount != nil {
+		_, err := p.serviceAccount.ForceRefresh(ctx)
+		return err
+	}
 	if p.oauth == nil {
 		return fmt.Errorf("OAuth2 not configured")
 	}
@@ -369,6 +405,32 @@ func (p *Provider) UpdateTask(ctx context.Context, listID string, task *model.Ta
 	return result.ToModelTask(listID, listName), nil
 }
 
+// ReorderTasks 按给定顺序依次调用 move 接口重排任务，保留各任务原有的父任务。
+func (p *Provider) ReorderTasks(ctx context.Context, listID string, orderedTaskIDs []string) error {
+	if err := p.ensureClient(ctx); err != nil {
+		return err
+	}
+
+	previousByParent := make(map[string]string)
+	for _, taskID := range orderedTaskIDs {
+		taskID = strings.TrimSpace(taskID)
+		if taskID == "" {
+			continue
+		}
+		current, err := p.client.GetTask(ctx, listID, taskID)
+		if err != nil {
+			return fmt.Errorf("get task %s: %w", taskID, err)
+		}
+		parent := strings.TrimSpace(current.Parent)
+		opts := MoveTaskOptions{Parent: parent, Previous: previousByParent[parent]}
+		if _, err := p.client.MoveTask(ctx, listID, taskID, opts); err != nil {
+			return fmt.Errorf("move task %s: %w", taskID, err)
+		}
+		previousByParent[parent] = taskID
+	}
+	return nil
+}
+
 // DeleteTask 删除任务
 func (p *Provider) DeleteTask(ctx context.Context, listID, taskID string) error {
 	if err := p.ensureClient(ctx); err != nil {
@@ -420,6 +482,15 @@ func (p *Provider) Capabilities() provider.Capabilities {
 	return p.capabilities
 }
 
+// ReadOnly 登录时只获得 Tasks 只读 scope 时返回 true（实现 provider.ScopeLimiter）
+func (p *Provider) ReadOnly() bool {
+	if p.serviceAccount != nil || p.config.TokenFile == "" {
+		return false
+	}
+	grant, err := tokenstore.LoadGrant(p.config.TokenFile, "google")
+	return err == nil && provider.GrantedReadOnly(grant.Scopes, ScopeTasks)
+}
+
 // GetTokenInfo 获取 Token 信息
 func (p *Provider) GetTokenInfo() *provider.TokenInfo {
 	info := &provider.TokenInfo{
@@ -428,6 +499,20 @@ func (p *Provider) GetTokenInfo() *provider.TokenInfo {
 		IsValid:  false,
 	}
 
+	if p.serviceAccount != nil {
+		token := p.serviceAccount.Token()
+		if token == nil {
+			return info
+		}
+		info.HasToken = true
+		info.IsValid = token.Valid()
+		info.ExpiresAt = token.Expiry
+		info.TimeUntilExpiry = formatDurationGoogle(time.Until(token.Expiry))
+		// 服务账号无 refresh token，过期后重新签发 JWT 即可
+		info.Refreshable = true
+		return info
+	}
+
 	if p.oauth == nil {
 		return info
 	}
@@ -484,6 +569,9 @@ func (p *Provider) ensureClient(ctx context.Context) error {
 		return nil
 	}
 
+	if p.serviceAccount != nil {
+		return p.Authenticate(ctx, nil)
+	}
 	if p.oauth == nil {
 		return fmt.Errorf("OAuth2 not configured")
 	}
@@ -517,13 +605,22 @@ func (p *Provider) CompleteAuth(ctx context.Context, code string) error {
 // 凭证文件路径: ~/.taskbridge/credentials/google_credentials.json
 // Token 文件路径: ~/.taskbridge/credentials/tokens.json
 func NewProviderFromHome() (*Provider, error) {
+	return NewProviderFromHomeWithSubject("")
+}
+
+// NewProviderFromHomeWithSubject 同 NewProviderFromHome；凭证文件为服务账号密钥时代为访问 subject 用户
+func NewProviderFromHomeWithSubject(subject string) (*Provider, error) {
 	// 确保凭证目录存在
 	if err := EnsureCredentialsDir(); err != nil {
 		return nil, fmt.Errorf("failed to create credentials directory: %w", err)
 	}
+	return NewProviderFromDir(paths.GetCredentialsDir(), subject)
+}
 
+// NewProviderFromDir 从指定凭证目录（如其他 profile 的 credentials 目录）加载凭证与 token 创建 Provider
+func NewProviderFromDir(credentialsDir, subject string) (*Provider, error) {
 	// 获取凭证文件路径
-	credentialsPath := GetCredentialsPath()
+	credentialsPath := filepath.Join(credentialsDir, "google_credentials.json")
 
 	// 检查凭证文件是否存在
 	if _, err := os.Stat(credentialsPath); os.IsNotExist(err) {
@@ -533,7 +630,8 @@ func NewProviderFromHome() (*Provider, error) {
 	// 创建 Provider
 	p, err := NewProvider(Config{
 		CredentialsFile: credentialsPath,
-		TokenFile:       GetTokenPath(),
+		TokenFile:       filepath.Join(credentialsDir, paths.TokenFileName),
+		Subject:         subject,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create provider: %w", err)
@@ -140,3 +140,37 @@ func TestProviderUpdateTask_MoveToParent(t *testing.T) {
 	}
 }
 
+func TestProviderReorderTasks_ChainsPrevious(t *testing.T) {
+	moves := make([]string, 0)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/lists/list-1/tasks/"):
+			id := strings.TrimPrefix(r.URL.Path, "/lists/list-1/tasks/")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "title": id, "status": "needsAction"})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/move"):
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/lists/list-1/tasks/"), "/move")
+			moves = append(moves, id+"<"+r.URL.Query().Get("previous"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "title": id, "status": "needsAction"})
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.String())
+		}
+	}))
+	defer srv.Close()
+
+	p, err := NewProvider(Config{})
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	p.client = NewClient("token")
+	p.client.baseURL = srv.URL
+
+	if err := p.ReorderTasks(context.Background(), "list-1", []string{"c", "a", "b"}); err != nil {
+		t.Fatalf("ReorderTasks failed: %v", err)
+	}
+	want := []string{"c<", "a<c", "b<a"}
+	if strings.Join(moves, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected move sequence: %v", moves)
+	}
+}
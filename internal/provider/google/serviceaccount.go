@@ -0,0 +1,104 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/auth"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
+)
+
+// ServiceAccount 使用服务账号密钥签发 JWT 换取 access token，通过 Workspace 全域委派代为访问 Subject 用户的任务，
+// 无需交互式登录，适合服务器自动化部署。服务账号本身没有 Google Tasks 数据，因此必须指定 Subject。
+type ServiceAccount struct {
+	config *jwt.Config
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+// IsServiceAccountKey 判断凭证文件内容是否为服务账号 JSON 密钥（"type": "service_account"）
+func IsServiceAccountKey(data []byte) bool {
+	var key struct {
+		Type string `json:"type"`
+	}
+	return json.Unmarshal(data, &key) == nil && key.Type == "service_account"
+}
+
+// NewServiceAccount 从服务账号 JSON 密钥创建客户端，subject 为被代为访问的 Workspace 用户邮箱
+func NewServiceAccount(key []byte, subject string) (*ServiceAccount, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("service account requires a user to impersonate (adapters.google.impersonate)")
+	}
+	config, err := google.JWTConfigFromJSON(key, ScopeTasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service account key: %w", err)
+	}
+	config.Subject = subject
+	return &ServiceAccount{config: config}, nil
+}
+
+// LoadServiceAccount 从服务账号密钥文件创建客户端
+func LoadServiceAccount(keyFile, subject string) (*ServiceAccount, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account key: %w", err)
+	}
+	return NewServiceAccount(data, subject)
+}
+
+// Email 返回服务账号邮箱
+func (s *ServiceAccount) Email() string {
+	return s.config.Email
+}
+
+// Subject 返回被代为访问的用户
+func (s *ServiceAccount) Subject() string {
+	return s.config.Subject
+}
+
+// Token 返回当前缓存的 token，尚未获取时为 nil
+func (s *ServiceAccount) Token() *oauth2.Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// AccessToken 返回有效的 access token，过期时重新签发 JWT 换取（实现 auth.TokenRefresher）
+func (s *ServiceAccount) AccessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+	if token.Valid() {
+		return token.AccessToken, nil
+	}
+	return s.ForceRefresh(ctx)
+}
+
+// ForceRefresh 重新签发 JWT 换取 access token（实现 auth.TokenRefresher）。
+// 服务账号未获授权全域委派或 scope 未登记时 Google 返回 unauthorized_client
+func (s *ServiceAccount) ForceRefresh(ctx context.Context) (string, error) {
+	token, err := s.config.TokenSource(ctx).Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain service account token for %s: %w", s.config.Subject, err)
+	}
+	s.mu.Lock()
+	s.token = token
+	s.mu.Unlock()
+	return token.AccessToken, nil
+}
+
+// HTTPClient 获取附带服务账号 token 的 HTTP 客户端，token 过期或收到 401 时自动重新获取
+func (s *ServiceAccount) HTTPClient(ctx context.Context) (*http.Client, error) {
+	if _, err := s.AccessToken(ctx); err != nil {
+		return nil, err
+	}
+	return auth.NewHTTPClient(s, 30*time.Second), nil
+}
@@ -0,0 +1,97 @@
+package google
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func serviceAccountKey(t *testing.T, tokenURI string) []byte {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "bot@project.iam.gserviceaccount.com",
+		"client_id":      "1234",
+		"private_key_id": "kid",
+		"private_key":    string(keyPEM),
+		"token_uri":      tokenURI,
+	})
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return data
+}
+
+func TestServiceAccountImpersonatesSubject(t *testing.T) {
+	var claims struct {
+		Issuer  string `json:"iss"`
+		Subject string `json:"sub"`
+		Scope   string `json:"scope"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(r.FormValue("assertion"), ".")
+		if len(parts) != 3 {
+			t.Errorf("unexpected assertion %q", r.FormValue("assertion"))
+			return
+		}
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		_ = json.Unmarshal(payload, &claims)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"sa-token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	key := serviceAccountKey(t, server.URL)
+	if !IsServiceAccountKey(key) || IsServiceAccountKey([]byte(`{"client_id":"cid"}`)) {
+		t.Fatal("IsServiceAccountKey misdetected key type")
+	}
+
+	account, err := NewServiceAccount(key, "user@example.com")
+	if err != nil {
+		t.Fatalf("NewServiceAccount: %v", err)
+	}
+	token, err := account.AccessToken(context.Background())
+	if err != nil {
+		t.Fatalf("AccessToken: %v", err)
+	}
+	if token != "sa-token" {
+		t.Fatalf("unexpected access token %q", token)
+	}
+	if claims.Issuer != "bot@project.iam.gserviceaccount.com" || claims.Subject != "user@example.com" || claims.Scope != ScopeTasks {
+		t.Fatalf("unexpected assertion claims %+v", claims)
+	}
+}
+
+func TestNewProviderWithServiceAccountKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "google.json")
+	if err := os.WriteFile(keyFile, serviceAccountKey(t, "http://127.0.0.1/token"), 0600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	if _, err := NewProvider(Config{CredentialsFile: keyFile}); err == nil || !strings.Contains(err.Error(), "impersonate") {
+		t.Fatalf("expected missing subject error, got %v", err)
+	}
+
+	p, err := NewProvider(Config{CredentialsFile: keyFile, Subject: "user@example.com"})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	if !p.IsAuthenticated() {
+		t.Fatal("service account provider should be authenticated without OAuth login")
+	}
+}
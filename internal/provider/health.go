@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// 凭证检查结果状态
+const (
+	// CredentialOK 凭证有效且未临近过期
+	CredentialOK = "ok"
+	// CredentialRefreshed 临近过期的 token 已主动刷新
+	CredentialRefreshed = "refreshed"
+	// CredentialExpiring token 即将过期且无法自动刷新，需要重新登录
+	CredentialExpiring = "expiring"
+	// CredentialExpired token 已过期且无法刷新
+	CredentialExpired = "expired"
+	// CredentialInvalid 未认证、刷新失败或远端拒绝了凭证
+	CredentialInvalid = "invalid"
+)
+
+// CredentialHealth 单个 Provider 的凭证检查结果
+type CredentialHealth struct {
+	Provider  string     `json:"provider"`
+	Status    string     `json:"status"`
+	CheckedAt time.Time  `json:"checked_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Warning 需要处理时的提示，状态正常时为空
+	Warning string `json:"warning,omitempty"`
+}
+
+// Healthy 凭证当前是否可用；expiring 仍可使用，但需在过期前重新登录
+func (h CredentialHealth) Healthy() bool {
+	return h.Status != CredentialExpired && h.Status != CredentialInvalid
+}
+
+// CheckCredential 检查 Provider 凭证：token 在 refreshBefore 内过期时尝试刷新，
+// 随后列出任务清单确认远端仍接受该凭证，以便在工具调用失败之前发现问题
+func CheckCredential(ctx context.Context, p Provider, refreshBefore time.Duration) CredentialHealth {
+	health := CredentialHealth{Provider: p.Name(), Status: CredentialOK, CheckedAt: time.Now()}
+	if !p.IsAuthenticated() {
+		health.Status = CredentialInvalid
+		health.Warning = "not authenticated; run taskbridge auth login " + p.Name()
+		return health
+	}
+
+	info := p.GetTokenInfo()
+	if info != nil && info.HasToken && !info.ExpiresAt.IsZero() {
+		health.ExpiresAt = timePtr(info.ExpiresAt)
+		remaining := time.Until(info.ExpiresAt)
+		if remaining <= refreshBefore || !info.IsValid {
+			switch {
+			case info.Refreshable:
+				if err := p.RefreshToken(ctx); err != nil {
+					health.Status = CredentialInvalid
+					health.Warning = fmt.Sprintf("refresh token: %v", err)
+					return health
+				}
+				health.Status = CredentialRefreshed
+				if refreshed := p.GetTokenInfo(); refreshed != nil && !refreshed.ExpiresAt.IsZero() {
+					health.ExpiresAt = timePtr(refreshed.ExpiresAt)
+				}
+			case remaining <= 0 || !info.IsValid:
+				health.Status = CredentialExpired
+				health.Warning = "token expired and cannot be refreshed; run taskbridge auth login " + p.Name()
+				return health
+			default:
+				health.Status = CredentialExpiring
+				health.Warning = fmt.Sprintf("token expires in %s and cannot be refreshed; run taskbridge auth login %s", remaining.Round(time.Minute), p.Name())
+			}
+		}
+	}
+
+	if _, err := p.ListTaskLists(ctx); err != nil {
+		health.Status = CredentialInvalid
+		health.Warning = fmt.Sprintf("validate credentials: %v", err)
+	}
+	return health
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,257 @@
+// Package httpcache 为适配器的 HTTP 客户端提供条件请求缓存：
+// 保存 GET 响应的 ETag/Last-Modified，之后的请求携带 If-None-Match/If-Modified-Since，
+// 远端返回 304 时直接使用缓存的响应体，未变化的清单拉取不再重复传输，也更少消耗速率限制配额。
+// 远端未返回校验值时不缓存，因此可以用于任意适配器。
+package httpcache
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMaxEntries 缓存的响应数上限，超出时淘汰最久未使用的响应
+	DefaultMaxEntries = 256
+	// MaxBodyBytes 单个可缓存响应体的上限，更大的响应不缓存
+	MaxBodyBytes = 4 << 20
+)
+
+// Stats 缓存统计
+type Stats struct {
+	// Entries 当前缓存的响应数
+	Entries int `json:"entries"`
+	// Revalidated 远端返回 304、使用缓存响应的请求数
+	Revalidated int `json:"revalidated"`
+	// Stored 保存到缓存的响应数
+	Stored int `json:"stored"`
+}
+
+// Cache 按请求 URL 保存带校验值的 GET 响应，可在同一适配器的多个 HTTP 客户端之间共享
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+	stats      Stats
+}
+
+type entry struct {
+	key          string
+	etag         string
+	lastModified string
+	status       int
+	header       http.Header
+	body         []byte
+}
+
+// New 创建缓存，maxEntries 不大于 0 时使用 DefaultMaxEntries
+func New(maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Cache{maxEntries: maxEntries, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// Stats 返回缓存统计
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// Clear 清空缓存
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+func (c *Cache) get(key string) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*entry)
+}
+
+func (c *Cache) put(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Stored++
+	if elem, ok := c.entries[e.key]; ok {
+		elem.Value = e
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[e.key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+}
+
+func (c *Cache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+func (c *Cache) revalidated() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Revalidated++
+}
+
+// Transport 发送条件请求并以缓存应答 304 的 http.RoundTripper。
+// 调用方始终看到完整的 200 响应，无需区分响应是否来自缓存
+type Transport struct {
+	// Base 实际发送请求的 RoundTripper，为空时使用 http.DefaultTransport
+	Base  http.RoundTripper
+	Cache *Cache
+}
+
+// WrapClient 返回使用 cache 的 HTTP 客户端副本，保留原客户端的超时、Cookie 与底层 Transport（如 OAuth2）。
+// client 已使用同一缓存时原样返回，client 为空时基于默认客户端创建
+func WrapClient(client *http.Client, cache *Cache) *http.Client {
+	if client == nil {
+		client = &http.Client{}
+	}
+	if t, ok := client.Transport.(*Transport); ok && t.Cache == cache {
+		return client
+	}
+	wrapped := *client
+	wrapped.Transport = &Transport{Base: client.Transport, Cache: cache}
+	return &wrapped
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Cache == nil {
+		return base.RoundTrip(req)
+	}
+	if req.Method != http.MethodGet {
+		resp, err := base.RoundTrip(req)
+		// 写入后清空缓存：Last-Modified 只精确到秒，同一秒内的写入可能让远端对旧列表返回 304
+		if err == nil && req.Method != http.MethodHead && resp.StatusCode < 400 {
+			t.Cache.Clear()
+		}
+		return resp, err
+	}
+	// 调用方自己发送的条件请求或要求绕过缓存的请求不处理
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" || noStore(req.Header) {
+		return base.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	cached := t.Cache.get(key)
+	outReq := req
+	if cached != nil {
+		outReq = req.Clone(req.Context())
+		if cached.etag != "" {
+			outReq.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			outReq.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := base.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		t.Cache.revalidated()
+		return cached.response(req, resp), nil
+	case resp.StatusCode == http.StatusOK:
+		return t.store(key, resp), nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		t.Cache.remove(key)
+	}
+	return resp, nil
+}
+
+// store 保存带校验值的 200 响应，返回可供调用方继续读取的响应
+func (t *Transport) store(key string, resp *http.Response) *http.Response {
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if (etag == "" && lastModified == "") || noStore(resp.Header) {
+		t.Cache.remove(key)
+		return resp
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxBodyBytes+1))
+	if err != nil || len(body) > MaxBodyBytes {
+		// 读取失败或响应过大：不缓存，将已读取的部分与剩余内容一起交给调用方
+		t.Cache.remove(key)
+		resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+		return resp
+	}
+	_ = resp.Body.Close()
+
+	t.Cache.put(&entry{
+		key:          key,
+		etag:         etag,
+		lastModified: lastModified,
+		status:       resp.StatusCode,
+		header:       resp.Header.Clone(),
+		body:         body,
+	})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp
+}
+
+// response 以缓存内容构造响应，并采用 304 响应中更新的头
+func (e *entry) response(req *http.Request, notModified *http.Response) *http.Response {
+	header := e.header.Clone()
+	for name, values := range notModified.Header {
+		if name == "Content-Length" {
+			continue
+		}
+		header[name] = values
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.status, http.StatusText(e.status)),
+		StatusCode:    e.status,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+func noStore(header http.Header) bool {
+	cacheControl := strings.ToLower(header.Get("Cache-Control"))
+	return strings.Contains(cacheControl, "no-store")
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
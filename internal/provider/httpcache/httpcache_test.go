@@ -0,0 +1,166 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeRemote struct {
+	etag         string
+	lastModified string
+	body         string
+	requests     int
+	notModified  int
+}
+
+func (f *fakeRemote) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests++
+	if r.Method != http.MethodGet {
+		f.body = "changed"
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if f.etag != "" {
+		w.Header().Set("ETag", f.etag)
+		if r.Header.Get("If-None-Match") == f.etag {
+			f.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	if f.lastModified != "" {
+		w.Header().Set("Last-Modified", f.lastModified)
+		if f.etag == "" && r.Header.Get("If-Modified-Since") == f.lastModified {
+			f.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	_, _ = io.WriteString(w, f.body)
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: status %d", url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(body)
+}
+
+func TestTransportRevalidatesWithETag(t *testing.T) {
+	remote := &fakeRemote{etag: `"v1"`, body: "lists"}
+	srv := httptest.NewServer(remote)
+	defer srv.Close()
+	cache := New(0)
+	client := WrapClient(srv.Client(), cache)
+
+	for i := 0; i < 3; i++ {
+		if body := get(t, client, srv.URL+"/lists"); body != "lists" {
+			t.Fatalf("unexpected body %q", body)
+		}
+	}
+	if remote.requests != 3 || remote.notModified != 2 {
+		t.Fatalf("later requests should be conditional: %+v", remote)
+	}
+	if stats := cache.Stats(); stats.Entries != 1 || stats.Revalidated != 2 || stats.Stored != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// 远端变化后返回新内容并替换缓存
+	remote.etag, remote.body = `"v2"`, "lists v2"
+	if body := get(t, client, srv.URL+"/lists"); body != "lists v2" {
+		t.Fatalf("changed resource should be fetched again, got %q", body)
+	}
+	if body := get(t, client, srv.URL+"/lists"); body != "lists v2" || remote.notModified != 3 {
+		t.Fatalf("new version should be cached: %q, %+v", body, remote)
+	}
+}
+
+func TestTransportRevalidatesWithLastModified(t *testing.T) {
+	remote := &fakeRemote{lastModified: "Wed, 14 Oct 2026 08:00:00 GMT", body: "tasks"}
+	srv := httptest.NewServer(remote)
+	defer srv.Close()
+	client := WrapClient(srv.Client(), New(0))
+
+	get(t, client, srv.URL+"/tasks")
+	if body := get(t, client, srv.URL+"/tasks"); body != "tasks" || remote.notModified != 1 {
+		t.Fatalf("expected If-Modified-Since revalidation: %q, %+v", body, remote)
+	}
+}
+
+func TestTransportSkipsUncacheableResponses(t *testing.T) {
+	remote := &fakeRemote{body: "no validators"}
+	srv := httptest.NewServer(remote)
+	defer srv.Close()
+	cache := New(0)
+	client := WrapClient(srv.Client(), cache)
+
+	get(t, client, srv.URL+"/tasks")
+	get(t, client, srv.URL+"/tasks")
+	if cache.Stats().Entries != 0 || remote.notModified != 0 {
+		t.Fatalf("responses without validators should not be cached: %+v", cache.Stats())
+	}
+}
+
+func TestTransportClearsAfterWrites(t *testing.T) {
+	remote := &fakeRemote{lastModified: "Wed, 14 Oct 2026 08:00:00 GMT", body: "tasks"}
+	srv := httptest.NewServer(remote)
+	defer srv.Close()
+	cache := New(0)
+	client := WrapClient(srv.Client(), cache)
+
+	get(t, client, srv.URL+"/tasks")
+	// 写入后 Last-Modified 不变（同一秒内），仍应拿到新内容
+	resp, err := client.Post(srv.URL+"/tasks", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	_ = resp.Body.Close()
+	if cache.Stats().Entries != 0 {
+		t.Fatal("writes should clear the cache")
+	}
+	if body := get(t, client, srv.URL+"/tasks"); body != "changed" {
+		t.Fatalf("expected fresh body after write, got %q", body)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	remote := &fakeRemote{etag: `"v1"`, body: "x"}
+	srv := httptest.NewServer(remote)
+	defer srv.Close()
+	cache := New(2)
+	client := WrapClient(srv.Client(), cache)
+
+	get(t, client, srv.URL+"/a")
+	get(t, client, srv.URL+"/b")
+	get(t, client, srv.URL+"/a")
+	get(t, client, srv.URL+"/c")
+	if cache.get(srv.URL+"/b") != nil || cache.get(srv.URL+"/a") == nil || cache.Stats().Entries != 2 {
+		t.Fatalf("least recently used entry should be evicted: %+v", cache.Stats())
+	}
+}
+
+func TestWrapClientKeepsSettings(t *testing.T) {
+	cache := New(0)
+	base := &http.Client{Timeout: 5 * time.Second}
+	wrapped := WrapClient(base, cache)
+	if wrapped == base || wrapped.Timeout != base.Timeout || base.Transport != nil {
+		t.Fatalf("wrap should copy the client without modifying it")
+	}
+	if WrapClient(wrapped, cache) != wrapped {
+		t.Fatal("client already using the cache should not be wrapped twice")
+	}
+}
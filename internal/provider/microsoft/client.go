@@ -13,6 +13,9 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/provider/httpcache"
 )
 
 const (
@@ -28,6 +31,8 @@ type Client struct {
 	baseURL    string
 	betaURL    string
 	authToken  string // 直接存储 token
+	// cache 条件请求缓存，未变化的清单与任务拉取只收到 304
+	cache *httpcache.Cache
 }
 
 // NewClient 创建 API 客户端
@@ -35,18 +40,20 @@ func NewClient(baseURL string) *Client {
 	if baseURL == "" {
 		baseURL = DefaultBaseURL
 	}
+	cache := httpcache.New(0)
 	return &Client{
-		httpClient: &http.Client{
+		httpClient: httpcache.WrapClient(&http.Client{
 			Timeout: 30 * time.Second,
-		},
+		}, cache),
 		baseURL: baseURL,
 		betaURL: BetaBaseURL,
+		cache:   cache,
 	}
 }
 
-// SetHTTPClient 设置 HTTP 客户端（用于 OAuth2）
+// SetHTTPClient 设置 HTTP 客户端（用于 OAuth2），保留条件请求缓存
 func (c *Client) SetHTTPClient(httpClient *http.Client) {
-	c.httpClient = httpClient
+	c.httpClient = httpcache.WrapClient(httpClient, c.cache)
 }
 
 // SetAuthToken 设置认证 token
@@ -54,6 +61,15 @@ func (c *Client) SetAuthToken(token string) {
 	c.authToken = token
 }
 
+// GetMe 获取当前登录用户（需要 User.Read 权限）
+func (c *Client) GetMe(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.get(ctx, "/me", &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // ================ 任务列表操作 ================
 
 // ListTodoLists 获取所有任务列表
@@ -202,6 +218,15 @@ func (c *Client) GetDelta(ctx context.Context, listID, deltaToken string) (*Delt
 	return &resp, nil
 }
 
+// FollowDelta 读取 @odata.nextLink 或上次保存的 @odata.deltaLink
+func (c *Client) FollowDelta(ctx context.Context, link string) (*DeltaResponse, error) {
+	var resp DeltaResponse
+	if err := c.get(ctx, link, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // ================ 检查项（子任务）操作 ================
 
 // ListChecklistItems 获取任务的检查项
@@ -261,6 +286,28 @@ func (c *Client) ListLinkedResources(ctx context.Context, listID, taskID string)
 	return resp.Value, nil
 }
 
+// ================ 附件操作 ================
+
+// ListAttachments 获取任务的附件列表（不含文件内容）
+func (c *Client) ListAttachments(ctx context.Context, listID, taskID string) ([]TaskAttachment, error) {
+	var resp struct {
+		Value []TaskAttachment `json:"value"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/me/todo/lists/%s/tasks/%s/attachments", listID, taskID), &resp); err != nil {
+		return nil, err
+	}
+	return resp.Value, nil
+}
+
+// GetAttachment 获取单个附件，包含文件内容
+func (c *Client) GetAttachment(ctx context.Context, listID, taskID, attachmentID string) (*TaskAttachment, error) {
+	var attachment TaskAttachment
+	if err := c.get(ctx, fmt.Sprintf("/me/todo/lists/%s/tasks/%s/attachments/%s", listID, taskID, attachmentID), &attachment); err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
 // ================ HTTP 请求方法 ================
 
 // get 发送 GET 请求
@@ -348,10 +395,16 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	// 检查错误状态码
 	if resp.StatusCode >= 400 {
 		var errResp ErrorResponse
-		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Code != "" {
-			return fmt.Errorf("API error: %s - %s", errResp.Error.Code, errResp.Error.Message)
+		var err error
+		if jsonErr := json.Unmarshal(respBody, &errResp); jsonErr == nil && errResp.Error.Code != "" {
+			err = fmt.Errorf("API error: %s - %s", errResp.Error.Code, errResp.Error.Message)
+		} else {
+			err = fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(respBody))
+		}
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+			return fmt.Errorf("%w: %w", provider.ErrNotFound, err)
 		}
-		return fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, string(respBody))
+		return err
 	}
 
 	// 解析响应
@@ -17,6 +17,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/yeisme/taskbridge/internal/auth"
+	"github.com/yeisme/taskbridge/pkg/browser"
 	"github.com/yeisme/taskbridge/pkg/tokenstore"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/microsoft"
@@ -39,6 +41,8 @@ type OAuth2Client struct {
 	token        *oauth2.Token
 	tokenFile    string
 	codeVerifier string
+	openBrowser  bool
+	graphBaseURL string // 为空时使用 DefaultBaseURL，测试时替换
 	mu           sync.RWMutex
 }
 
@@ -49,6 +53,13 @@ var DefaultScopes = []string{
 	"offline_access",
 }
 
+// ReadOnlyScopes adapters.microsoft.read_only 时申请的权限范围
+var ReadOnlyScopes = []string{
+	"https://graph.microsoft.com/Tasks.Read",
+	"https://graph.microsoft.com/User.Read",
+	"offline_access",
+}
+
 // NewOAuth2Client 创建 OAuth2 客户端
 func NewOAuth2Client(cfg *OAuthConfig) *OAuth2Client {
 	tenantID := cfg.TenantID
@@ -74,7 +85,7 @@ func NewOAuth2Client(cfg *OAuthConfig) *OAuth2Client {
 		ClientSecret: cfg.ClientSecret,
 		RedirectURL:  redirectURL,
 		Scopes:       scopes,
-		Endpoint:     microsoft.AzureADEndpoint(tenantID),
+		Endpoint:     auth.PublicClientEndpoint(microsoft.AzureADEndpoint(tenantID), cfg.ClientSecret),
 	}
 
 	return &OAuth2Client{
@@ -111,6 +122,18 @@ func (c *OAuth2Client) SetTokenFile(path string) {
 	c.tokenFile = path
 }
 
+// SetScopes 设置授权时申请的 scope（如 adapters.microsoft.scopes），为空时保持默认值
+func (c *OAuth2Client) SetScopes(scopes []string) {
+	if len(scopes) > 0 {
+		c.config.Scopes = append([]string(nil), scopes...)
+	}
+}
+
+// SetOpenBrowser 设置 StartAuthServer 是否自动在浏览器中打开授权链接
+func (c *OAuth2Client) SetOpenBrowser(open bool) {
+	c.openBrowser = open
+}
+
 // ExchangeCode 交换授权码获取 token
 func (c *OAuth2Client) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
 	c.mu.RLock()
@@ -167,6 +190,11 @@ func (c *OAuth2Client) StartAuthServer(ctx context.Context, port int) (*oauth2.T
 
 	// 打印授权 URL
 	fmt.Printf("\n请在浏览器中打开以下链接进行授权:\n\n%s\n\n", authURL)
+	if c.openBrowser {
+		if err := browser.Open(authURL); err != nil {
+			fmt.Printf("无法自动打开浏览器（%v），请手动复制上面的链接\n", err)
+		}
+	}
 	fmt.Println("等待授权回调...")
 
 	// 创建回调处理
@@ -255,6 +283,33 @@ func (c *OAuth2Client) StartAuthServer(ctx context.Context, port int) (*oauth2.T
 	}
 }
 
+// DeviceLogin 使用设备授权流程（device code）获取 token，适用于无浏览器的服务器：
+// prompt 收到验证地址与用户码后展示给用户，用户在任意设备（如手机）上完成授权后返回 token 并保存。
+// 设备授权属于公共客户端流程，应用需开启“允许公共客户端流”，请求中不携带 client_secret。
+func (c *OAuth2Client) DeviceLogin(ctx context.Context, prompt func(*oauth2.DeviceAuthResponse)) (*oauth2.Token, error) {
+	config := *c.config
+	config.ClientSecret = ""
+	config.RedirectURL = ""
+
+	resp, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+	prompt(resp)
+	token, err := config.DeviceAccessToken(ctx, resp)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+	if err := c.SaveToken(); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
 // ValidToken 获取有效的 token（必要时刷新）
 func (c *OAuth2Client) ValidToken(ctx context.Context) (*oauth2.Token, error) {
 	c.mu.RLock()
@@ -343,18 +398,77 @@ func (c *OAuth2Client) SaveToken() error {
 	if err := tokenstore.Save(c.tokenFile, "microsoft", token); err != nil {
 		return fmt.Errorf("failed to save token: %w", err)
 	}
+	// token 响应中的 scope 为实际授予的权限，从文件加载的 token 不含该字段
+	if scope, _ := token.Extra("scope").(string); scope != "" {
+		if err := tokenstore.SaveGrant(c.tokenFile, "microsoft", tokenstore.Grant{Scopes: strings.Fields(scope)}); err != nil {
+			return fmt.Errorf("failed to save token grant: %w", err)
+		}
+	}
 
 	return nil
 }
 
-// HTTPClient 获取配置好的 HTTP 客户端
+// Account 通过 Graph /me 查询当前登录账号（优先 userPrincipalName），并记录到已保存的 token 中供 auth status 显示
+func (c *OAuth2Client) Account(ctx context.Context) (string, error) {
+	httpClient, err := c.HTTPClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	client := NewClient(c.graphBaseURL)
+	client.SetHTTPClient(httpClient)
+	user, err := client.GetMe(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	account := user.UserPrincipalName
+	if account == "" {
+		account = user.Mail
+	}
+	if account != "" && c.tokenFile != "" {
+		if err := tokenstore.SaveGrant(c.tokenFile, "microsoft", tokenstore.Grant{Account: account}); err != nil {
+			return account, fmt.Errorf("failed to save token grant: %w", err)
+		}
+	}
+	return account, nil
+}
+
+// HTTPClient 获取配置好的 HTTP 客户端：access token 过期时自动刷新并保存，收到 401 时强制刷新后重试一次
 func (c *OAuth2Client) HTTPClient(ctx context.Context) (*http.Client, error) {
+	if _, err := c.ValidToken(ctx); err != nil {
+		return nil, err
+	}
+	return auth.NewHTTPClient(c, 30*time.Second), nil
+}
+
+// AccessToken 返回有效的 access token，过期时自动刷新并保存（实现 auth.TokenRefresher）
+func (c *OAuth2Client) AccessToken(ctx context.Context) (string, error) {
 	token, err := c.ValidToken(ctx)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	return token.AccessToken, nil
+}
 
-	return c.config.Client(ctx, token), nil
+// ForceRefresh 忽略 access token 的过期时间，使用 refresh token 换取新 token 并保存（实现 auth.TokenRefresher）
+func (c *OAuth2Client) ForceRefresh(ctx context.Context) (string, error) {
+	c.mu.RLock()
+	token := c.token
+	c.mu.RUnlock()
+	if token == nil || token.RefreshToken == "" {
+		return "", fmt.Errorf("no refresh token available, please run 'taskbridge auth login microsoft'")
+	}
+
+	c.mu.Lock()
+	c.token = &oauth2.Token{RefreshToken: token.RefreshToken}
+	c.mu.Unlock()
+	refreshed, err := c.RefreshToken(ctx)
+	if err != nil {
+		c.mu.Lock()
+		c.token = token
+		c.mu.Unlock()
+		return "", err
+	}
+	return refreshed.AccessToken, nil
 }
 
 // IsAuthenticated 检查是否已认证
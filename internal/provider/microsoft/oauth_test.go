@@ -0,0 +1,121 @@
+package microsoft
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/pkg/tokenstore"
+	"golang.org/x/oauth2"
+)
+
+func TestDeviceLoginSavesToken(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("client_secret") != "" {
+			t.Errorf("device flow must not send client_secret")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/devicecode":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"device_code":      "dev-code",
+				"user_code":        "ABCD-EFGH",
+				"verification_uri": "https://microsoft.com/devicelogin",
+				"expires_in":       900,
+				"interval":         1,
+			})
+		case "/token":
+			if r.Form.Get("device_code") != "dev-code" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			polls++
+			if polls == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "device-token", "refresh_token": "r", "token_type": "Bearer", "expires_in": 3600, "scope": "Tasks.ReadWrite User.Read"})
+		case "/me":
+			if r.Header.Get("Authorization") != "Bearer device-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"displayName": "Me", "userPrincipalName": "me@example.com"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "tokens.json")
+	client := NewOAuth2Client(&OAuthConfig{ClientID: "cid", ClientSecret: "secret", TokenFile: tokenFile})
+	client.config.Endpoint = oauth2.Endpoint{DeviceAuthURL: server.URL + "/devicecode", TokenURL: server.URL + "/token"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var userCode string
+	token, err := client.DeviceLogin(ctx, func(resp *oauth2.DeviceAuthResponse) { userCode = resp.UserCode })
+	if err != nil {
+		t.Fatalf("DeviceLogin: %v", err)
+	}
+	if userCode != "ABCD-EFGH" || token.AccessToken != "device-token" || polls != 2 {
+		t.Fatalf("unexpected result: code=%q token=%q polls=%d", userCode, token.AccessToken, polls)
+	}
+
+	reloaded := NewOAuth2Client(&OAuthConfig{ClientID: "cid", TokenFile: tokenFile})
+	if err := reloaded.LoadToken(); err != nil || reloaded.token.AccessToken != "device-token" {
+		t.Fatalf("expected token persisted, got %v err=%v", reloaded.token, err)
+	}
+
+	client.graphBaseURL = server.URL
+	if account, err := client.Account(ctx); err != nil || account != "me@example.com" {
+		t.Fatalf("unexpected account %q err=%v", account, err)
+	}
+	grant, err := tokenstore.LoadGrant(tokenFile, "microsoft")
+	if err != nil {
+		t.Fatalf("LoadGrant: %v", err)
+	}
+	if grant.Account != "me@example.com" || strings.Join(grant.Scopes, " ") != "Tasks.ReadWrite User.Read" {
+		t.Fatalf("unexpected grant %+v", grant)
+	}
+}
+
+func TestPublicClientExchangeUsesPKCE(t *testing.T) {
+	client := NewOAuth2Client(&OAuthConfig{ClientID: "public-client"})
+	authURL, err := url.Parse(client.AuthURL())
+	if err != nil {
+		t.Fatalf("parse auth URL: %v", err)
+	}
+	challenge := authURL.Query().Get("code_challenge")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if challenge == "" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			t.Errorf("code_verifier does not match challenge")
+		}
+		if _, _, ok := r.BasicAuth(); ok || r.PostForm.Has("client_secret") {
+			t.Errorf("public client must not send a client secret")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "pkce-token", "token_type": "Bearer", "expires_in": 3600})
+	}))
+	defer server.Close()
+	client.config.Endpoint.TokenURL = server.URL
+
+	token, err := client.ExchangeCode(context.Background(), "auth-code")
+	if err != nil || token.AccessToken != "pkce-token" {
+		t.Fatalf("unexpected token %v err=%v", token, err)
+	}
+}
@@ -3,8 +3,10 @@ package microsoft
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/yeisme/taskbridge/internal/model"
 	"github.com/yeisme/taskbridge/internal/provider"
 	"github.com/yeisme/taskbridge/pkg/paths"
+	"github.com/yeisme/taskbridge/pkg/tokenstore"
 )
 
 // Provider Microsoft To Do Provider
@@ -447,6 +450,52 @@ func (p *Provider) UpdateChecklistItem(ctx context.Context, listID, taskID, item
 	return p.client.UpdateChecklistItem(ctx, listID, taskID, itemID, displayName, isChecked)
 }
 
+// ListTaskAttachments 列出任务附件（Microsoft To Do taskFileAttachment）。
+func (p *Provider) ListTaskAttachments(ctx context.Context, listID, taskID string) ([]provider.Attachment, error) {
+	if !p.IsAuthenticated() {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	attachments, err := p.client.ListAttachments(ctx, listID, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	result := make([]provider.Attachment, 0, len(attachments))
+	for i := range attachments {
+		result = append(result, toProviderAttachment(&attachments[i]))
+	}
+	return result, nil
+}
+
+// GetTaskAttachment 读取单个附件及其文件内容。
+func (p *Provider) GetTaskAttachment(ctx context.Context, listID, taskID, attachmentID string) (*provider.AttachmentContent, error) {
+	if !p.IsAuthenticated() {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	attachment, err := p.client.GetAttachment(ctx, listID, taskID, attachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	return &provider.AttachmentContent{
+		Attachment: toProviderAttachment(attachment),
+		Data:       attachment.ContentBytes,
+	}, nil
+}
+
+// toProviderAttachment 转换附件元数据
+func toProviderAttachment(a *TaskAttachment) provider.Attachment {
+	result := provider.Attachment{
+		ID:       a.ID,
+		Name:     a.Name,
+		MIMEType: a.ContentType,
+		Size:     a.Size,
+	}
+	if !a.LastModifiedDateTime.IsZero() {
+		updated := a.LastModifiedDateTime
+		result.UpdatedAt = &updated
+	}
+	return result
+}
+
 // ================ 批量操作 ================
 
 // BatchCreate 批量创建任务
@@ -539,6 +588,66 @@ func (p *Provider) GetChanges(ctx context.Context, since time.Time) (*provider.S
 	return changes, nil
 }
 
+// ListTaskDelta 通过每个列表的 delta 查询增量读取任务（实现 provider.DeltaSyncer）。
+// 游标为列表 ID 到 @odata.deltaLink 的 JSON 映射；新出现的列表从头读取。
+func (p *Provider) ListTaskDelta(ctx context.Context, cursor string) (*provider.SyncChanges, error) {
+	if !p.IsAuthenticated() {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	links := make(map[string]string)
+	if cursor != "" {
+		if err := json.Unmarshal([]byte(cursor), &links); err != nil {
+			return nil, fmt.Errorf("invalid delta cursor: %w", err)
+		}
+	}
+
+	lists, err := p.client.ListTodoLists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task lists: %w", err)
+	}
+
+	changes := &provider.SyncChanges{Tasks: []model.Task{}, DeletedIDs: []string{}, Full: cursor == ""}
+	next := make(map[string]string, len(lists))
+	for _, list := range lists {
+		var resp *DeltaResponse
+		if link := links[list.ID]; link != "" {
+			resp, err = p.client.FollowDelta(ctx, link)
+		} else {
+			resp, err = p.client.GetDelta(ctx, list.ID, "")
+		}
+		for err == nil {
+			for i := range resp.Value {
+				task := &resp.Value[i]
+				if task.Removed != nil {
+					changes.DeletedIDs = append(changes.DeletedIDs, task.ID)
+					continue
+				}
+				if modelTask := ToModelTask(task); modelTask != nil {
+					modelTask.ListID = list.ID
+					modelTask.ListName = list.DisplayName
+					changes.Tasks = append(changes.Tasks, *modelTask)
+				}
+			}
+			if resp.NextLink == "" {
+				next[list.ID] = resp.DeltaLink
+				break
+			}
+			resp, err = p.client.FollowDelta(ctx, resp.NextLink)
+		}
+		if err != nil {
+			// 游标过期（410）等错误时由调用方回退为全量读取
+			return nil, fmt.Errorf("failed to get delta for list %s: %w", list.DisplayName, err)
+		}
+	}
+
+	data, err := json.Marshal(next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal delta cursor: %w", err)
+	}
+	changes.NextToken = string(data)
+	return changes, nil
+}
+
 // ================ 能力查询 ================
 
 // Capabilities 返回 Provider 能力
@@ -546,6 +655,15 @@ func (p *Provider) Capabilities() provider.Capabilities {
 	return p.capabilities
 }
 
+// ReadOnly 登录时只获得 Tasks.Read 时返回 true（实现 provider.ScopeLimiter）
+func (p *Provider) ReadOnly() bool {
+	if p.config.TokenFile == "" {
+		return false
+	}
+	grant, err := tokenstore.LoadGrant(p.config.TokenFile, "microsoft")
+	return err == nil && provider.GrantedReadOnly(grant.Scopes, "Tasks.ReadWrite", "Tasks.ReadWrite.Shared")
+}
+
 // GetTokenInfo 获取 Token 信息
 func (p *Provider) GetTokenInfo() *provider.TokenInfo {
 	p.mu.RLock()
@@ -759,9 +877,13 @@ func NewProviderFromHome() (*Provider, error) {
 	if err := os.MkdirAll(credentialsDir, 0700); err != nil {
 		return nil, fmt.Errorf("failed to create credentials directory: %w", err)
 	}
+	return NewProviderFromDir(credentialsDir)
+}
 
+// NewProviderFromDir 从指定凭证目录（如其他 profile 的 credentials 目录）加载凭证与 token 创建 Provider
+func NewProviderFromDir(credentialsDir string) (*Provider, error) {
 	// 获取凭证文件路径
-	credentialsPath := paths.GetCredentialsPath("microsoft")
+	credentialsPath := filepath.Join(credentialsDir, "microsoft_credentials.json")
 
 	// 检查凭证文件是否存在
 	if _, err := os.Stat(credentialsPath); os.IsNotExist(err) {
@@ -769,7 +891,7 @@ func NewProviderFromHome() (*Provider, error) {
 	}
 
 	// 获取 Token 文件路径
-	tokenPath := paths.GetTokenPath("microsoft")
+	tokenPath := filepath.Join(credentialsDir, paths.TokenFileName)
 
 	// 创建 Provider
 	p, err := NewProvider(Config{
@@ -799,8 +921,12 @@ func NewProviderFromHome() (*Provider, error) {
 			Bool("valid", validToken.Valid()).
 			Msg("Microsoft token loaded successfully")
 
-		// 直接设置 token 到 client
-		p.client.SetAuthToken(validToken.AccessToken)
+		// token 过期后由 HTTP 客户端自动刷新，长时间运行的 MCP 服务无需重新登录
+		httpClient, err := p.oauth.HTTPClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create http client: %w", err)
+		}
+		p.client.SetHTTPClient(httpClient)
 	}
 
 	return p, nil
@@ -21,6 +21,19 @@ type TokenResponse struct {
 
 // ================ Microsoft Graph API 类型 ================
 
+// User 当前登录用户
+// https://learn.microsoft.com/en-us/graph/api/resources/user
+type User struct {
+	// ID 用户唯一标识
+	ID string `json:"id"`
+	// DisplayName 显示名称
+	DisplayName string `json:"displayName"`
+	// Mail 主邮箱，个人账户可能为空
+	Mail string `json:"mail"`
+	// UserPrincipalName 登录名
+	UserPrincipalName string `json:"userPrincipalName"`
+}
+
 // TodoTaskList Microsoft To Do 任务列表
 // https://learn.microsoft.com/en-us/graph/api/resources/todotasklist
 type TodoTaskList struct {
@@ -83,6 +96,13 @@ type TodoTask struct {
 	Categories []string `json:"categories,omitempty"`
 	// Attachments 附件列表
 	Attachments []TaskAttachment `json:"attachments,omitempty"`
+	// Removed delta 响应中已删除的任务带有该字段
+	Removed *DeltaRemoved `json:"@removed,omitempty"`
+}
+
+// DeltaRemoved delta 响应中的删除标记
+type DeltaRemoved struct {
+	Reason string `json:"reason"`
 }
 
 // TaskStatus 任务状态
@@ -255,6 +275,8 @@ type TaskAttachment struct {
 	Name string `json:"name"`
 	// Size 文件大小
 	Size int64 `json:"size"`
+	// ContentBytes 文件内容，仅读取单个附件时返回（base64 编码，解码后填充）
+	ContentBytes []byte `json:"contentBytes,omitempty"`
 }
 
 // ================ API 响应包装 ================
@@ -3,11 +3,16 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/yeisme/taskbridge/internal/model"
 )
 
+// ErrNotFound Provider 明确答复资源不存在（如 HTTP 404/410）。
+// 限流、服务端错误与网络错误不属于此类：调用方只有在 errors.Is(err, ErrNotFound) 时才能认定任务已被删除
+var ErrNotFound = errors.New("not found")
+
 // Provider Todo 软件适配器接口
 type Provider interface {
 	// 基础信息
@@ -48,6 +53,56 @@ type Provider interface {
 	GetTokenInfo() *TokenInfo
 }
 
+// TaskReorderer 可选接口：支持调整清单内任务顺序的 Provider 实现。
+type TaskReorderer interface {
+	// ReorderTasks 按 orderedTaskIDs（远端原始 ID）的先后顺序重排清单内的同级任务。
+	ReorderTasks(ctx context.Context, listID string, orderedTaskIDs []string) error
+}
+
+// DeltaSyncer 可选接口：支持按游标增量读取任务变更的 Provider 实现（如 Microsoft Graph delta、Todoist sync_token）。
+type DeltaSyncer interface {
+	// ListTaskDelta 返回自 cursor 以来新建、修改与删除的任务，SyncChanges.NextToken 为下次调用的游标。
+	// cursor 为空时返回全部任务并将 SyncChanges.Full 置为 true；DeletedIDs 为远端原始 ID。
+	ListTaskDelta(ctx context.Context, cursor string) (*SyncChanges, error)
+}
+
+// TaskDependencyLinker 可选接口：支持原生任务依赖（阻塞关系）的 Provider 实现。
+type TaskDependencyLinker interface {
+	// AddTaskDependency 记录 blockerTaskID 阻塞 blockedTaskID（均为远端原始 ID）。
+	AddTaskDependency(ctx context.Context, blockerTaskID, blockedTaskID string) error
+	// RemoveTaskDependency 移除 blockerTaskID 对 blockedTaskID 的阻塞关系。
+	RemoveTaskDependency(ctx context.Context, blockerTaskID, blockedTaskID string) error
+}
+
+// Attachment 任务附件元数据
+type Attachment struct {
+	// ID 附件 ID（Provider 原始 ID）
+	ID string `json:"id"`
+	// Name 文件名
+	Name string `json:"name"`
+	// MIMEType 内容类型
+	MIMEType string `json:"mime_type,omitempty"`
+	// Size 文件大小（字节），未知时为 0
+	Size int64 `json:"size,omitempty"`
+	// UpdatedAt 最后修改时间
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// AttachmentContent 附件元数据与文件内容
+type AttachmentContent struct {
+	Attachment
+	// Data 文件内容
+	Data []byte `json:"-"`
+}
+
+// TaskAttachmentReader 可选接口：支持读取任务附件的 Provider 实现。
+type TaskAttachmentReader interface {
+	// ListTaskAttachments 列出任务的附件（taskID 为远端原始 ID）。
+	ListTaskAttachments(ctx context.Context, listID, taskID string) ([]Attachment, error)
+	// GetTaskAttachment 读取单个附件的元数据与文件内容。
+	GetTaskAttachment(ctx context.Context, listID, taskID, attachmentID string) (*AttachmentContent, error)
+}
+
 // TokenInfo Token 信息
 type TokenInfo struct {
 	// Provider Provider 名称
@@ -122,6 +177,8 @@ type SyncChanges struct {
 	NextToken string `json:"next_token"`
 	// HasMore 是否还有更多
 	HasMore bool `json:"has_more"`
+	// Full Tasks 是否为全部任务（而非自游标以来的变更），见 DeltaSyncer
+	Full bool `json:"full,omitempty"`
 }
 
 // Conflict 同步冲突
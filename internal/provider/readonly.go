@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// ErrReadOnlyScopes Provider 登录时只获得了只读 scope，不能写入
+var ErrReadOnlyScopes = errors.New("provider was granted read-only scopes")
+
+// ScopeLimiter 可选接口：Provider 报告登录时实际授予的 scope 是否只读。
+// 未记录授予的 scope（如旧版本登录）时应返回 false，避免误拦截写入。
+type ScopeLimiter interface {
+	ReadOnly() bool
+}
+
+// IsReadOnly 判断 Provider 是否只获得了只读 scope
+func IsReadOnly(p Provider) bool {
+	limiter, ok := p.(ScopeLimiter)
+	return ok && limiter.ReadOnly()
+}
+
+// GrantedReadOnly 判断授予的 scope 是否只读：scope 已知且不含任一写 scope。
+// 写 scope 可以是完整 URL 或末段名称，如 Tasks.ReadWrite 同时匹配 https://graph.microsoft.com/Tasks.ReadWrite
+func GrantedReadOnly(granted []string, writeScopes ...string) bool {
+	if len(granted) == 0 {
+		return false
+	}
+	for _, scope := range granted {
+		for _, write := range writeScopes {
+			if strings.EqualFold(scope, write) || strings.HasSuffix(strings.ToLower(scope), "/"+strings.ToLower(write)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ReadOnlyGuard 包装只读 Provider：读取照常进行，写操作直接返回 ErrReadOnlyScopes，
+// 不会把注定失败的请求发给远端
+func ReadOnlyGuard(p Provider) Provider {
+	return &readOnlyProvider{Provider: p}
+}
+
+type readOnlyProvider struct {
+	Provider
+}
+
+func (p *readOnlyProvider) ReadOnly() bool { return true }
+
+func (p *readOnlyProvider) denied(operation string) error {
+	return fmt.Errorf("%s %s: %w; re-login with write scopes (adapters.%s.read_only: false)", p.Name(), operation, ErrReadOnlyScopes, p.Name())
+}
+
+func (p *readOnlyProvider) CreateTaskList(context.Context, string) (*model.TaskList, error) {
+	return nil, p.denied("create task list")
+}
+
+func (p *readOnlyProvider) DeleteTaskList(context.Context, string) error {
+	return p.denied("delete task list")
+}
+
+func (p *readOnlyProvider) CreateTask(context.Context, string, *model.Task) (*model.Task, error) {
+	return nil, p.denied("create task")
+}
+
+func (p *readOnlyProvider) UpdateTask(context.Context, string, *model.Task) (*model.Task, error) {
+	return nil, p.denied("update task")
+}
+
+func (p *readOnlyProvider) DeleteTask(context.Context, string, string) error {
+	return p.denied("delete task")
+}
+
+func (p *readOnlyProvider) BatchCreate(context.Context, string, []*model.Task) ([]model.Task, error) {
+	return nil, p.denied("create tasks")
+}
+
+func (p *readOnlyProvider) BatchUpdate(context.Context, string, []*model.Task) ([]model.Task, error) {
+	return nil, p.denied("update tasks")
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/yeisme/taskbridge/internal/provider/httpcache"
 )
 
 const (
@@ -40,7 +42,7 @@ func NewClient(baseURL, authBaseURL string) *Client {
 		authBaseURL = defaultAuthBaseURL
 	}
 	return &Client{
-		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		httpClient:  httpcache.WrapClient(&http.Client{Timeout: 30 * time.Second}, httpcache.New(0)),
 		baseURL:     strings.TrimRight(baseURL, "/"),
 		authBaseURL: strings.TrimRight(authBaseURL, "/"),
 		openBaseURL: openBaseURLFor(baseURL),
@@ -0,0 +1,234 @@
+package ticktick
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/yeisme/taskbridge/pkg/browser"
+)
+
+const (
+	tickTickAuthURL  = "https://ticktick.com/oauth/authorize"
+	tickTickTokenURL = "https://ticktick.com/oauth/token"
+	didaAuthURL      = "https://dida365.com/oauth/authorize"
+	didaTokenURL     = "https://dida365.com/oauth/token"
+
+	// DefaultRedirectURL 默认回调地址，需与开发者平台中登记的 OAuth redirect URL 一致
+	DefaultRedirectURL = "http://localhost:8080/callback"
+)
+
+// DefaultScopes OpenAPI 读写任务所需的权限范围
+var DefaultScopes = []string{"tasks:read", "tasks:write"}
+
+// ReadOnlyScopes adapters.<ticktick|dida>.read_only 时申请的权限范围
+var ReadOnlyScopes = []string{"tasks:read"}
+
+// OAuthConfig TickTick / 滴答清单 OpenAPI 的 OAuth2 配置
+type OAuthConfig struct {
+	ProviderName string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OAuth2Client TickTick / 滴答清单 OAuth2 授权码流程客户端。
+// OpenAPI 签发的 access token 有效期较长且不提供 refresh token，过期后需重新登录。
+type OAuth2Client struct {
+	config      *oauth2.Config
+	state       string
+	openBrowser bool
+	mu          sync.RWMutex
+}
+
+// NewOAuth2Client 创建 OAuth2 客户端
+func NewOAuth2Client(cfg *OAuthConfig) *OAuth2Client {
+	redirectURL := strings.TrimSpace(cfg.RedirectURL)
+	if redirectURL == "" {
+		redirectURL = DefaultRedirectURL
+	}
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	endpoint := oauth2.Endpoint{AuthURL: tickTickAuthURL, TokenURL: tickTickTokenURL, AuthStyle: oauth2.AuthStyleInHeader}
+	if normalizeProviderName(cfg.ProviderName) == "dida" {
+		endpoint.AuthURL, endpoint.TokenURL = didaAuthURL, didaTokenURL
+	}
+	return &OAuth2Client{
+		config: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+			Endpoint:     endpoint,
+		},
+	}
+}
+
+// SetScopes 设置授权时申请的 scope（如 adapters.ticktick.scopes），为空时保持默认值
+func (c *OAuth2Client) SetScopes(scopes []string) {
+	if len(scopes) > 0 {
+		c.config.Scopes = append([]string(nil), scopes...)
+	}
+}
+
+// SetOpenBrowser 设置 StartAuthServer 是否自动在浏览器中打开授权链接
+func (c *OAuth2Client) SetOpenBrowser(open bool) {
+	c.openBrowser = open
+}
+
+// AuthURL 生成授权 URL，每次调用都会生成新的 state
+func (c *OAuth2Client) AuthURL() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	c.mu.Lock()
+	c.state = hex.EncodeToString(buf)
+	state := c.state
+	c.mu.Unlock()
+	return c.config.AuthCodeURL(state)
+}
+
+// ExchangeCode 使用授权码交换 access token
+func (c *OAuth2Client) ExchangeCode(ctx context.Context, code string) (*oauth2.Token, error) {
+	token, err := c.config.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	return token, nil
+}
+
+// StartAuthServer 在 redirect URL 的地址上启动本地回调服务，等待浏览器完成授权后返回 token
+func (c *OAuth2Client) StartAuthServer(ctx context.Context) (*oauth2.Token, error) {
+	redirectURL, err := url.Parse(c.config.RedirectURL)
+	if err != nil || redirectURL.Host == "" {
+		return nil, fmt.Errorf("invalid redirect URL: %q", c.config.RedirectURL)
+	}
+	callbackPath := redirectURL.Path
+	if callbackPath == "" {
+		callbackPath = "/"
+	}
+	listenAddr := redirectURL.Host
+	if redirectURL.Port() == "" {
+		listenAddr = net.JoinHostPort(redirectURL.Hostname(), "80")
+	}
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback listener on %s: %w", listenAddr, err)
+	}
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	authURL := c.AuthURL()
+	fmt.Printf("\n请在浏览器中打开以下链接进行授权:\n\n%s\n\n", authURL)
+	if c.openBrowser {
+		if err := browser.Open(authURL); err != nil {
+			fmt.Printf("无法自动打开浏览器（%v），请手动复制上面的链接\n", err)
+		}
+	}
+	fmt.Println("等待授权回调...")
+
+	resultChan := make(chan *oauth2.Token, 1)
+	errChan := make(chan error, 1)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != callbackPath {
+				http.NotFound(w, r)
+				return
+			}
+			query := r.URL.Query()
+			if errParam := query.Get("error"); errParam != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprintf(w, "授权失败: %s - %s", errParam, query.Get("error_description"))
+				errChan <- fmt.Errorf("%s: %s", errParam, query.Get("error_description"))
+				return
+			}
+			c.mu.RLock()
+			state := c.state
+			c.mu.RUnlock()
+			if query.Get("state") != state {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprint(w, "授权失败: state 不匹配")
+				errChan <- fmt.Errorf("state mismatch")
+				return
+			}
+			code := strings.TrimSpace(query.Get("code"))
+			if code == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprint(w, "授权失败: 未收到授权码")
+				errChan <- fmt.Errorf("no authorization code in callback")
+				return
+			}
+			token, err := c.ExchangeCode(ctx, code)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = fmt.Fprintf(w, "授权失败: %v", err)
+				errChan <- err
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprint(w, `<!DOCTYPE html><html><head><title>授权成功</title></head><body><h2>授权成功</h2><p>请返回终端继续。</p></body></html>`)
+			resultChan <- token
+		}),
+	}
+
+	go func() {
+		if serveErr := server.Serve(listener); serveErr != nil && serveErr != http.ErrServerClosed {
+			errChan <- serveErr
+		}
+	}()
+
+	select {
+	case token := <-resultChan:
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+		return token, nil
+	case authErr := <-errChan:
+		_ = server.Shutdown(ctx)
+		return nil, authErr
+	case <-ctx.Done():
+		_ = server.Shutdown(ctx)
+		return nil, ctx.Err()
+	}
+}
+
+// LoadCredentials 从 JSON 凭证文件（client_id、client_secret、redirect_url、scopes）加载 OAuth2 客户端
+func LoadCredentials(credentialsFile, providerName string) (*OAuth2Client, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	var creds struct {
+		ClientID     string   `json:"client_id"`
+		ClientSecret string   `json:"client_secret"`
+		RedirectURL  string   `json:"redirect_url"`
+		Scopes       []string `json:"scopes"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	if strings.TrimSpace(creds.ClientID) == "" || strings.TrimSpace(creds.ClientSecret) == "" {
+		return nil, fmt.Errorf("credentials file %s must contain client_id and client_secret", credentialsFile)
+	}
+	return NewOAuth2Client(&OAuthConfig{
+		ProviderName: providerName,
+		ClientID:     creds.ClientID,
+		ClientSecret: creds.ClientSecret,
+		RedirectURL:  creds.RedirectURL,
+		Scopes:       creds.Scopes,
+	}), nil
+}
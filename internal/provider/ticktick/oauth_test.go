@@ -0,0 +1,108 @@
+package ticktick
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOAuth2ClientAuthorizationCodeFlow(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "cid" || pass != "csecret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.FormValue("grant_type") != "authorization_code" || r.FormValue("code") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "oauth-token", "token_type": "bearer", "expires_in": 15551999})
+	}))
+	defer tokenServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("reserve port: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	client := NewOAuth2Client(&OAuthConfig{ProviderName: "dida", ClientID: "cid", ClientSecret: "csecret", RedirectURL: "http://" + addr + "/callback"})
+	if !strings.HasPrefix(client.config.Endpoint.AuthURL, didaAuthURL) {
+		t.Fatalf("expected dida endpoint, got %s", client.config.Endpoint.AuthURL)
+	}
+	client.config.Endpoint.TokenURL = tokenServer.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	type outcome struct {
+		token string
+		err   error
+	}
+	result := make(chan outcome, 1)
+	start := func() {
+		token, err := client.StartAuthServer(ctx)
+		if err != nil {
+			result <- outcome{err: err}
+			return
+		}
+		result <- outcome{token: token.AccessToken}
+	}
+	go start()
+
+	var state string
+	for state == "" {
+		time.Sleep(10 * time.Millisecond)
+		client.mu.RLock()
+		state = client.state
+		client.mu.RUnlock()
+	}
+	resp := getWithRetry(t, "http://"+addr+"/callback?state=wrong&code=abc")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected state mismatch rejected, got %d", resp.StatusCode)
+	}
+	if got := <-result; got.err == nil || !strings.Contains(got.err.Error(), "state mismatch") {
+		t.Fatalf("expected state mismatch error, got %v", got.err)
+	}
+
+	go start()
+	for {
+		client.mu.RLock()
+		next := client.state
+		client.mu.RUnlock()
+		if next != state {
+			state = next
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	resp = getWithRetry(t, "http://"+addr+"/callback?state="+state+"&code=abc")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected callback success, got %d", resp.StatusCode)
+	}
+	if got := <-result; got.err != nil || got.token != "oauth-token" {
+		t.Fatalf("unexpected token %q err=%v", got.token, got.err)
+	}
+}
+
+// getWithRetry 等待回调服务开始监听后发送请求
+func getWithRetry(t *testing.T, target string) *http.Response {
+	t.Helper()
+	for i := 0; i < 50; i++ {
+		resp, err := http.Get(target)
+		if err == nil {
+			_ = resp.Body.Close()
+			return resp
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("callback server did not start")
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -90,8 +91,13 @@ func NewProviderFromHome() (*Provider, error) {
 }
 
 func NewProviderFromHomeByName(providerName string) (*Provider, error) {
+	return NewProviderFromDir(paths.GetCredentialsDir(), providerName)
+}
+
+// NewProviderFromDir 从指定凭证目录（如其他 profile 的 credentials 目录）加载 token 创建 Provider
+func NewProviderFromDir(credentialsDir, providerName string) (*Provider, error) {
 	resolvedName := normalizeProviderName(providerName)
-	tokenPath := paths.GetTokenPath(resolvedName)
+	tokenPath := filepath.Join(credentialsDir, paths.TokenFileName)
 	hasToken, err := tokenstore.Has(tokenPath, resolvedName)
 	if err != nil {
 		return nil, err
@@ -420,7 +426,7 @@ func (p *Provider) GetTask(ctx context.Context, listID, taskID string) (*model.T
 				return &copied, nil
 			}
 		}
-		return nil, fmt.Errorf("task not found: %s", taskID)
+		return nil, fmt.Errorf("%w: task %s", provider.ErrNotFound, taskID)
 	}
 
 	tasks, err := p.ListTasks(ctx, listID, provider.ListOptions{})
@@ -433,7 +439,7 @@ func (p *Provider) GetTask(ctx context.Context, listID, taskID string) (*model.T
 			return &copied, nil
 		}
 	}
-	return nil, fmt.Errorf("task not found: %s", taskID)
+	return nil, fmt.Errorf("%w: task %s", provider.ErrNotFound, taskID)
 }
 
 func (p *Provider) SearchTasks(ctx context.Context, query string) ([]model.Task, error) {
@@ -670,6 +676,15 @@ func (p *Provider) GetChanges(ctx context.Context, since time.Time) (*provider.S
 
 func (p *Provider) Capabilities() provider.Capabilities { return p.capabilities }
 
+// ReadOnly OAuth 登录时只获得 tasks:read 时返回 true（实现 provider.ScopeLimiter）；API Token 不记录 scope
+func (p *Provider) ReadOnly() bool {
+	if p.config.TokenFile == "" {
+		return false
+	}
+	grant, err := tokenstore.LoadGrant(p.config.TokenFile, p.name)
+	return err == nil && provider.GrantedReadOnly(grant.Scopes, "tasks:write")
+}
+
 func (p *Provider) GetTokenInfo() *provider.TokenInfo {
 	hasToken := p.client.IsAuthenticated()
 	return &provider.TokenInfo{
@@ -8,6 +8,11 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/internal/provider/httpcache"
 )
 
 // Client Todoist REST API 客户端。
@@ -17,10 +22,10 @@ type Client struct {
 	apiToken   string
 }
 
-// NewClient 创建客户端。
+// NewClient 创建客户端；GET 请求使用条件请求缓存。
 func NewClient(apiToken string) *Client {
 	return &Client{
-		httpClient: &http.Client{Timeout: defaultTimeout},
+		httpClient: httpcache.WrapClient(&http.Client{Timeout: defaultTimeout}, httpcache.New(0)),
 		baseURL:    defaultBaseURL,
 		apiToken:   apiToken,
 	}
@@ -172,6 +177,138 @@ func (c *Client) DeleteTask(ctx context.Context, taskID string) error {
 	return c.doRequest(ctx, http.MethodDelete, "/tasks/"+taskID, nil, nil)
 }
 
+// ListComments 列出任务评论。
+func (c *Client) ListComments(ctx context.Context, taskID string) ([]Comment, error) {
+	var all []Comment
+	cursor := ""
+	for {
+		v := url.Values{}
+		v.Set("task_id", taskID)
+		if cursor != "" {
+			v.Set("cursor", cursor)
+		}
+
+		var resp pagedCommentsResponse
+		if err := c.doRequest(ctx, http.MethodGet, "/comments?"+v.Encode(), nil, &resp); err != nil {
+			return nil, err
+		}
+
+		all = append(all, resp.Results...)
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+	return all, nil
+}
+
+// GetComment 获取评论。
+func (c *Client) GetComment(ctx context.Context, commentID string) (*Comment, error) {
+	var comment Comment
+	if err := c.doRequest(ctx, http.MethodGet, "/comments/"+commentID, nil, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// DownloadFile 下载附件文件，返回内容与响应的 Content-Type。
+func (c *Client) DownloadFile(ctx context.Context, fileURL string) ([]byte, string, error) {
+	if c.apiToken == "" {
+		return nil, "", fmt.Errorf("todoist api token is empty")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, "", fmt.Errorf("todoist file download error: status=%d", resp.StatusCode)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// ReorderTasks 通过 Sync API 的 item_reorder 命令批量设置同级任务的 child_order。
+// https://developer.todoist.com/api/v1/#tag/Sync/Items/Reorder
+func (c *Client) ReorderTasks(ctx context.Context, orderedTaskIDs []string) error {
+	if c.apiToken == "" {
+		return fmt.Errorf("todoist api token is empty")
+	}
+	items := make([]map[string]interface{}, 0, len(orderedTaskIDs))
+	for idx, id := range orderedTaskIDs {
+		items = append(items, map[string]interface{}{"id": id, "child_order": idx + 1})
+	}
+	commands, err := json.Marshal([]map[string]interface{}{{
+		"type": "item_reorder",
+		"uuid": fmt.Sprintf("tb-reorder-%d", time.Now().UnixNano()),
+		"args": map[string]interface{}{"items": items},
+	}})
+	if err != nil {
+		return fmt.Errorf("marshal reorder command: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("commands", string(commands))
+	var out syncResponse
+	if err := c.postSync(ctx, form, &out); err != nil {
+		return err
+	}
+	for _, status := range out.SyncStatus {
+		if string(status) != `"ok"` {
+			return fmt.Errorf("todoist reorder failed: %s", string(status))
+		}
+	}
+	return nil
+}
+
+// SyncItems 通过 Sync API 增量读取任务；syncToken 为空时读取全部未完成任务。
+// https://developer.todoist.com/api/v1/#tag/Sync/Overview/Read-resources
+func (c *Client) SyncItems(ctx context.Context, syncToken string) (*ItemsDelta, error) {
+	if c.apiToken == "" {
+		return nil, fmt.Errorf("todoist api token is empty")
+	}
+	if syncToken == "" {
+		syncToken = "*"
+	}
+	form := url.Values{}
+	form.Set("sync_token", syncToken)
+	form.Set("resource_types", `["items"]`)
+
+	var out syncResponse
+	if err := c.postSync(ctx, form, &out); err != nil {
+		return nil, err
+	}
+	delta := &ItemsDelta{SyncToken: out.SyncToken, FullSync: out.FullSync}
+	for _, item := range out.Items {
+		if item.IsDeleted {
+			delta.DeletedIDs = append(delta.DeletedIDs, item.ID.String())
+			continue
+		}
+		delta.Items = append(delta.Items, item.Task)
+	}
+	return delta, nil
+}
+
+// postSync 以表单形式调用 Sync API。
+func (c *Client) postSync(ctx context.Context, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/sync", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.send(req, out)
+}
+
 func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}, out interface{}) error {
 	if c.apiToken == "" {
 		return fmt.Errorf("todoist api token is empty")
@@ -190,8 +327,13 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+c.apiToken)
 	req.Header.Set("Content-Type", "application/json")
+	return c.send(req, out)
+}
+
+// send 附加认证头并执行请求，统一处理错误状态与响应解码。
+func (c *Client) send(req *http.Request, out interface{}) error {
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -209,6 +351,9 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 		if msg == "" {
 			msg = resp.Status
 		}
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+			return fmt.Errorf("%w: todoist api error: status=%d body=%s", provider.ErrNotFound, resp.StatusCode, msg)
+		}
 		return fmt.Errorf("todoist api error: status=%d body=%s", resp.StatusCode, msg)
 	}
 
@@ -2,6 +2,7 @@ package todoist
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -34,3 +35,125 @@ func TestListSections(t *testing.T) {
 		t.Fatalf("expected 2 sections, got %d", len(sections))
 	}
 }
+
+func TestReorderTasksSendsItemReorderCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sync" || r.Method != http.MethodPost {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		var commands []struct {
+			Type string `json:"type"`
+			UUID string `json:"uuid"`
+			Args struct {
+				Items []struct {
+					ID         string `json:"id"`
+					ChildOrder int    `json:"child_order"`
+				} `json:"items"`
+			} `json:"args"`
+		}
+		if err := json.Unmarshal([]byte(r.PostForm.Get("commands")), &commands); err != nil {
+			t.Fatalf("decode commands: %v", err)
+		}
+		if len(commands) != 1 || commands[0].Type != "item_reorder" || len(commands[0].Args.Items) != 2 {
+			t.Fatalf("unexpected commands: %#v", commands)
+		}
+		if commands[0].Args.Items[0].ID != "t2" || commands[0].Args.Items[1].ChildOrder != 2 {
+			t.Fatalf("unexpected reorder items: %#v", commands[0].Args.Items)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"sync_status":{"` + commands[0].UUID + `":"ok"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+
+	if err := client.ReorderTasks(context.Background(), []string{"t2", "t1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSyncItemsReadsChangesSinceToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sync" || r.Method != http.MethodPost {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		if r.PostForm.Get("sync_token") != "tok-1" || r.PostForm.Get("resource_types") != `["items"]` {
+			t.Fatalf("unexpected form: %v", r.PostForm)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"sync_token":"tok-2","full_sync":false,"items":[
+			{"id":"1","project_id":"p1","content":"Changed","checked":true},
+			{"id":"2","project_id":"p1","content":"Gone","is_deleted":true}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token")
+	client.baseURL = server.URL
+	p := &Provider{client: client}
+
+	changes, err := p.ListTaskDelta(context.Background(), "tok-1")
+	if err != nil {
+		t.Fatalf("ListTaskDelta: %v", err)
+	}
+	if changes.NextToken != "tok-2" || changes.Full {
+		t.Fatalf("unexpected cursor: %+v", changes)
+	}
+	if len(changes.Tasks) != 1 || changes.Tasks[0].Title != "Changed" || changes.Tasks[0].ListID != "p1" {
+		t.Fatalf("unexpected tasks: %+v", changes.Tasks)
+	}
+	if len(changes.DeletedIDs) != 1 || changes.DeletedIDs[0] != "2" {
+		t.Fatalf("unexpected deletions: %v", changes.DeletedIDs)
+	}
+}
+
+func TestTaskAttachmentsFromComments(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Fatalf("missing auth header for %s", r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/comments":
+			if r.URL.Query().Get("task_id") != "t1" {
+				t.Fatalf("unexpected query: %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"results":[{"id":"c1","item_id":"t1","content":"plain"},{"id":"c2","item_id":"t1","file_attachment":{"file_name":"a.png","file_type":"image/png","file_size":3,"file_url":"` + server.URL + `/files/a.png"}}],"next_cursor":""}`))
+		case "/comments/c2":
+			_, _ = w.Write([]byte(`{"id":"c2","item_id":"t1","file_attachment":{"file_name":"a.png","file_url":"` + server.URL + `/files/a.png"}}`))
+		case "/files/a.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte{0x89, 'P', 'N'})
+		default:
+			t.Fatalf("unexpected request: %s", r.URL.String())
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewProvider(Config{APIToken: "token"})
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	p.client.baseURL = server.URL
+
+	attachments, err := p.ListTaskAttachments(context.Background(), "", "t1")
+	if err != nil {
+		t.Fatalf("list attachments: %v", err)
+	}
+	if len(attachments) != 1 || attachments[0].ID != "c2" || attachments[0].MIMEType != "image/png" {
+		t.Fatalf("unexpected attachments: %+v", attachments)
+	}
+	content, err := p.GetTaskAttachment(context.Background(), "", "t1", "c2")
+	if err != nil {
+		t.Fatalf("get attachment: %v", err)
+	}
+	if content.MIMEType != "image/png" || content.Size != 3 || len(content.Data) != 3 {
+		t.Fatalf("unexpected attachment content: %+v", content)
+	}
+}
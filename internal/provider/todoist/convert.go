@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
 )
 
 const (
@@ -168,6 +169,26 @@ func toUpdateTaskRequest(task *model.Task) *UpdateTaskRequest {
 	return req
 }
 
+// toProviderAttachment 将带文件的评论转换为附件元数据；评论不含文件时返回 false。
+func toProviderAttachment(comment *Comment) (provider.Attachment, bool) {
+	if comment == nil || comment.FileAttachment == nil {
+		return provider.Attachment{}, false
+	}
+	file := comment.FileAttachment
+	attachment := provider.Attachment{
+		ID:       comment.ID.String(),
+		Name:     file.FileName,
+		MIMEType: file.FileType,
+		Size:     file.FileSize,
+	}
+	if comment.PostedAt != "" {
+		if posted, err := time.Parse(time.RFC3339, comment.PostedAt); err == nil {
+			attachment.UpdatedAt = &posted
+		}
+	}
+	return attachment, true
+}
+
 func parseDue(due *Due) *time.Time {
 	if due == nil {
 		return nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -41,7 +42,7 @@ func NewProvider(cfg Config) (*Provider, error) {
 			SupportsPriority:     true,
 			SupportsSearch:       true,
 			SupportsBatch:        true,
-			SupportsDeltaSync:    false,
+			SupportsDeltaSync:    true, // Sync API sync_token
 			MaxTaskLength:        500,
 			MaxDescriptionLength: 16384,
 		},
@@ -61,7 +62,12 @@ func NewProvider(cfg Config) (*Provider, error) {
 
 // NewProviderFromHome 从 HOME 目录加载凭证创建 Provider。
 func NewProviderFromHome() (*Provider, error) {
-	tokenPath := paths.GetTokenPath("todoist")
+	return NewProviderFromDir(paths.GetCredentialsDir())
+}
+
+// NewProviderFromDir 从指定凭证目录（如其他 profile 的 credentials 目录）加载 token 创建 Provider。
+func NewProviderFromDir(credentialsDir string) (*Provider, error) {
+	tokenPath := filepath.Join(credentialsDir, paths.TokenFileName)
 	hasToken, err := tokenstore.Has(tokenPath, "todoist")
 	if err != nil {
 		return nil, err
@@ -248,6 +254,55 @@ func (p *Provider) UpdateTask(ctx context.Context, listID string, task *model.Ta
 	return mTask, nil
 }
 
+// ReorderTasks 按顺序设置任务的 child_order；listID 对应 Todoist 项目，由任务自身决定，无需传递。
+func (p *Provider) ReorderTasks(ctx context.Context, listID string, orderedTaskIDs []string) error {
+	_ = listID
+	return p.client.ReorderTasks(ctx, orderedTaskIDs)
+}
+
+// ListTaskAttachments 列出任务评论中的文件附件，附件 ID 即评论 ID。
+func (p *Provider) ListTaskAttachments(ctx context.Context, listID, taskID string) ([]provider.Attachment, error) {
+	_ = listID
+	comments, err := p.client.ListComments(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]provider.Attachment, 0, len(comments))
+	for i := range comments {
+		if attachment, ok := toProviderAttachment(&comments[i]); ok {
+			result = append(result, attachment)
+		}
+	}
+	return result, nil
+}
+
+// GetTaskAttachment 读取评论附件并下载文件内容。
+func (p *Provider) GetTaskAttachment(ctx context.Context, listID, taskID, attachmentID string) (*provider.AttachmentContent, error) {
+	_ = listID
+	comment, err := p.client.GetComment(ctx, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if taskID != "" && comment.ItemID.String() != "" && comment.ItemID.String() != taskID {
+		return nil, fmt.Errorf("attachment %s does not belong to task %s", attachmentID, taskID)
+	}
+	attachment, ok := toProviderAttachment(comment)
+	if !ok || comment.FileAttachment.FileURL == "" {
+		return nil, fmt.Errorf("comment %s has no file attachment", attachmentID)
+	}
+	data, contentType, err := p.client.DownloadFile(ctx, comment.FileAttachment.FileURL)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.MIMEType == "" {
+		attachment.MIMEType = contentType
+	}
+	if attachment.Size == 0 {
+		attachment.Size = int64(len(data))
+	}
+	return &provider.AttachmentContent{Attachment: attachment, Data: data}, nil
+}
+
 func (p *Provider) DeleteTask(ctx context.Context, listID, taskID string) error {
 	return p.client.DeleteTask(ctx, taskID)
 }
@@ -284,6 +339,26 @@ func (p *Provider) GetChanges(ctx context.Context, since time.Time) (*provider.S
 	return nil, fmt.Errorf("delta sync not supported by Todoist REST API")
 }
 
+// ListTaskDelta 通过 Sync API 的 sync_token 增量读取任务（实现 provider.DeltaSyncer）。
+func (p *Provider) ListTaskDelta(ctx context.Context, cursor string) (*provider.SyncChanges, error) {
+	delta, err := p.client.SyncItems(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+	changes := &provider.SyncChanges{
+		Tasks:      make([]model.Task, 0, len(delta.Items)),
+		DeletedIDs: append([]string{}, delta.DeletedIDs...),
+		NextToken:  delta.SyncToken,
+		Full:       delta.FullSync || cursor == "",
+	}
+	for i := range delta.Items {
+		if task := toModelTask(&delta.Items[i]); task != nil {
+			changes.Tasks = append(changes.Tasks, *task)
+		}
+	}
+	return changes, nil
+}
+
 func (p *Provider) Capabilities() provider.Capabilities {
 	return p.capabilities
 }
@@ -79,9 +79,28 @@ type Task struct {
 	Priority    int      `json:"priority"`
 	Labels      []string `json:"labels"`
 	ParentID    ID       `json:"parent_id"`
+	ChildOrder  int      `json:"child_order"`
 	URL         string   `json:"url"`
 }
 
+// Comment Todoist 评论；文件附件随评论上传。
+type Comment struct {
+	ID             ID              `json:"id"`
+	ItemID         ID              `json:"item_id"`
+	Content        string          `json:"content"`
+	PostedAt       string          `json:"posted_at"`
+	FileAttachment *FileAttachment `json:"file_attachment"`
+}
+
+// FileAttachment 评论附带的文件。
+type FileAttachment struct {
+	FileName     string `json:"file_name"`
+	FileSize     int64  `json:"file_size"`
+	FileType     string `json:"file_type"`
+	FileURL      string `json:"file_url"`
+	ResourceType string `json:"resource_type"`
+}
+
 // CreateTaskRequest 创建任务请求。
 type CreateTaskRequest struct {
 	Content     string   `json:"content"`
@@ -105,6 +124,28 @@ type UpdateTaskRequest struct {
 	DueString   string   `json:"due_string,omitempty"`
 }
 
+// syncResponse Sync API 响应，sync_status 按命令 uuid 返回 "ok" 或错误对象。
+type syncResponse struct {
+	SyncStatus map[string]json.RawMessage `json:"sync_status"`
+	SyncToken  string                     `json:"sync_token"`
+	FullSync   bool                       `json:"full_sync"`
+	Items      []syncItem                 `json:"items"`
+}
+
+// syncItem Sync API 返回的任务，已删除的任务带有 is_deleted。
+type syncItem struct {
+	Task
+	IsDeleted bool `json:"is_deleted"`
+}
+
+// ItemsDelta 自上次 sync_token 以来的任务变更。
+type ItemsDelta struct {
+	Items      []Task
+	DeletedIDs []string
+	SyncToken  string
+	FullSync   bool
+}
+
 type pagedProjectsResponse struct {
 	Results    []Project `json:"results"`
 	NextCursor string    `json:"next_cursor"`
@@ -119,3 +160,8 @@ type pagedTasksResponse struct {
 	Results    []Task `json:"results"`
 	NextCursor string `json:"next_cursor"`
 }
+
+type pagedCommentsResponse struct {
+	Results    []Comment `json:"results"`
+	NextCursor string    `json:"next_cursor"`
+}
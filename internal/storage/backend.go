@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// BackendFile 保存在 storage.path 下的 JSON 文件（默认）
+	BackendFile = "file"
+	// BackendSQLite 保存在 storage.path/tasks.db 的 SQLite 数据库，多个进程可同时读写
+	BackendSQLite = "sqlite"
+	// BackendBolt 保存在 storage.path/tasks.bolt 的 bbolt 数据库，每次读写时短暂打开，多个进程轮流读写
+	BackendBolt = "bolt"
+	// BackendPostgres 保存在 storage.postgres.dsn 指向的 PostgreSQL 数据库，供多实例部署共享
+	BackendPostgres = "postgres"
+)
+
+// Options 存储后端选项
+type Options struct {
+	// Backend 后端名称，对应 storage.type，为空时使用 file
+	Backend string
+	// Path 数据目录（storage.path）
+	Path string
+	// Format file 后端的文件格式（storage.file.format）
+	Format string
+	// DSN 数据库连接串（postgres 后端的 storage.postgres.dsn）
+	DSN string
+}
+
+// Factory 按选项创建存储后端
+type Factory func(opts Options) (Storage, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{}
+)
+
+// Register 注册存储后端，之后 storage.type 即可使用该名称。内置的 file、sqlite、bolt、postgres 后端由各自的包在 init 中注册，
+// 其他后端实现 Storage 接口后以新的名称注册；同名后端不能重复注册
+func Register(backend string, factory Factory) error {
+	backend = strings.ToLower(strings.TrimSpace(backend))
+	if backend == "" || factory == nil {
+		return fmt.Errorf("storage backend name and factory are required")
+	}
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if _, ok := factories[backend]; ok {
+		return fmt.Errorf("storage backend %s is already registered", backend)
+	}
+	factories[backend] = factory
+	return nil
+}
+
+// Backends 返回已注册的后端名称
+func Backends() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	backends := make([]string, 0, len(factories))
+	for backend := range factories {
+		backends = append(backends, backend)
+	}
+	sort.Strings(backends)
+	return backends
+}
+
+// Open 按选项创建存储后端
+func Open(opts Options) (Storage, error) {
+	backend := strings.ToLower(strings.TrimSpace(opts.Backend))
+	if backend == "" {
+		backend = BackendFile
+	}
+	factoriesMu.RLock()
+	factory, ok := factories[backend]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown storage backend: %s (supported: %s)", opts.Backend, strings.Join(Backends(), ", "))
+	}
+	return factory(opts)
+}
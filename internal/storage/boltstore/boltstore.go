@@ -0,0 +1,355 @@
+// Package boltstore 提供基于 bbolt 的存储实现
+package boltstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+// DBFileName storage.path 下的数据库文件名
+const DBFileName = "tasks.bolt"
+
+// lockTimeout 等待其他进程释放数据库文件锁的最长时间
+const lockTimeout = 5 * time.Second
+
+// 任务与列表以 JSON 保存（启用静态加密时加密），同步时间保存为 RFC3339 字符串
+var (
+	tasksBucket     = []byte("tasks")
+	taskListsBucket = []byte("task_lists")
+	syncTimesBucket = []byte("sync_times")
+)
+
+func init() {
+	_ = storage.Register(storage.BackendBolt, func(opts storage.Options) (storage.Storage, error) {
+		return New(opts.Path)
+	})
+}
+
+// BoltStorage bbolt 存储实现。bbolt 同一时间只允许一个进程打开数据库，
+// 因此每次读写时打开、完成后立即关闭，mcp start、sync start 与命令行可以轮流使用同一个数据目录
+type BoltStorage struct {
+	path string
+}
+
+// New 打开 basePath/tasks.bolt；数据库为空且存在 file 后端的 tasks.json 等文件时导入其中的数据
+func New(basePath string) (*BoltStorage, error) {
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	s := &BoltStorage{path: filepath.Join(basePath, DBFileName)}
+	if err := s.update(func(tx *bolt.Tx) error {
+		empty := true
+		for _, name := range [][]byte{tasksBucket, taskListsBucket, syncTimesBucket} {
+			bucket, err := tx.CreateBucketIfNotExists(name)
+			if err != nil {
+				return fmt.Errorf("failed to init storage db: %w", err)
+			}
+			if k, _ := bucket.Cursor().First(); k != nil {
+				empty = false
+			}
+		}
+		if !empty {
+			return nil
+		}
+		return importFileStorage(tx, basePath)
+	}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// importFileStorage 从 file 后端迁移：导入 tasks.json、lists.json 与 sync.json
+func importFileStorage(tx *bolt.Tx, basePath string) error {
+	snapshot, err := filestore.ReadSnapshot(basePath)
+	if err != nil || snapshot == nil {
+		return err
+	}
+	for i := range snapshot.Tasks {
+		if err := putJSON(tx.Bucket(tasksBucket), snapshot.Tasks[i].ID, &snapshot.Tasks[i]); err != nil {
+			return fmt.Errorf("failed to save task %s: %w", snapshot.Tasks[i].ID, err)
+		}
+	}
+	for i := range snapshot.Lists {
+		if err := putJSON(tx.Bucket(taskListsBucket), snapshot.Lists[i].ID, &snapshot.Lists[i]); err != nil {
+			return fmt.Errorf("failed to save task list %s: %w", snapshot.Lists[i].ID, err)
+		}
+	}
+	for source, t := range snapshot.SyncTimes {
+		if err := tx.Bucket(syncTimesBucket).Put([]byte(source), []byte(t.Format(time.RFC3339))); err != nil {
+			return fmt.Errorf("failed to save sync time: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close 数据库只在每次读写期间打开，无需关闭
+func (s *BoltStorage) Close() error {
+	return nil
+}
+
+func (s *BoltStorage) open(readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(s.path, 0644, &bolt.Options{Timeout: lockTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage db: %w", err)
+	}
+	return db, nil
+}
+
+// view 以只读方式打开数据库执行 fn，多个进程可同时读取
+func (s *BoltStorage) view(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open(true)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return db.View(fn)
+}
+
+// update 独占打开数据库并在同一事务中执行 fn
+func (s *BoltStorage) update(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open(false)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	return db.Update(fn)
+}
+
+// putJSON 序列化并在启用静态加密时加密后写入 bucket
+func putJSON(bucket *bolt.Bucket, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sealed, err := storecrypt.SealString(string(data))
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), []byte(sealed))
+}
+
+// openJSON 解密并反序列化 putJSON 写入的值
+func openJSON(value []byte, v any) error {
+	plain, err := storecrypt.OpenString(string(value))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(plain), v)
+}
+
+// readTasks 读取满足 match 的任务，match 为 nil 时读取全部
+func (s *BoltStorage) readTasks(match func(task *model.Task) bool) ([]model.Task, error) {
+	var result []model.Task
+	err := s.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(tasksBucket).ForEach(func(_, value []byte) error {
+			var task model.Task
+			if err := openJSON(value, &task); err != nil {
+				return fmt.Errorf("failed to unmarshal task: %w", err)
+			}
+			if match == nil || match(&task) {
+				result = append(result, task)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SaveTask 保存任务
+func (s *BoltStorage) SaveTask(ctx context.Context, task *model.Task) error {
+	return s.SaveTasks(ctx, []*model.Task{task})
+}
+
+// GetTask 获取任务
+func (s *BoltStorage) GetTask(_ context.Context, id string) (*model.Task, error) {
+	var task *model.Task
+	err := s.view(func(tx *bolt.Tx) error {
+		value := tx.Bucket(tasksBucket).Get([]byte(id))
+		if value == nil {
+			return nil
+		}
+		task = &model.Task{}
+		if err := openJSON(value, task); err != nil {
+			return fmt.Errorf("failed to unmarshal task: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, fmt.Errorf("task not found: %s", id)
+	}
+	return task, nil
+}
+
+// ListTasks 列出任务
+func (s *BoltStorage) ListTasks(_ context.Context, opts storage.ListOptions) ([]model.Task, error) {
+	return s.readTasks(func(task *model.Task) bool {
+		return (opts.Source == "" || task.Source == opts.Source) && (opts.ListID == "" || task.ListID == opts.ListID)
+	})
+}
+
+// DeleteTask 删除任务
+func (s *BoltStorage) DeleteTask(_ context.Context, id string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(tasksBucket).Delete([]byte(id)); err != nil {
+			return fmt.Errorf("failed to delete task %s: %w", id, err)
+		}
+		return nil
+	})
+}
+
+// SaveTasks 批量保存任务，在同一事务中写入
+func (s *BoltStorage) SaveTasks(_ context.Context, tasks []*model.Task) error {
+	now := time.Now()
+	return s.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(tasksBucket)
+		for _, task := range tasks {
+			task.UpdatedAt = now
+			if err := putJSON(bucket, task.ID, task); err != nil {
+				return fmt.Errorf("failed to save task %s: %w", task.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// QueryTasks 查询任务
+func (s *BoltStorage) QueryTasks(_ context.Context, query storage.Query) ([]model.Task, error) {
+	tasks, err := s.readTasks(func(task *model.Task) bool {
+		return storage.MatchQuery(task, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return storage.SortAndPage(tasks, query), nil
+}
+
+// SaveTaskList 保存任务列表
+func (s *BoltStorage) SaveTaskList(_ context.Context, list *model.TaskList) error {
+	list.UpdatedAt = time.Now()
+	return s.update(func(tx *bolt.Tx) error {
+		if err := putJSON(tx.Bucket(taskListsBucket), list.ID, list); err != nil {
+			return fmt.Errorf("failed to save task list %s: %w", list.ID, err)
+		}
+		return nil
+	})
+}
+
+// GetTaskList 获取任务列表
+func (s *BoltStorage) GetTaskList(_ context.Context, id string) (*model.TaskList, error) {
+	var list *model.TaskList
+	err := s.view(func(tx *bolt.Tx) error {
+		value := tx.Bucket(taskListsBucket).Get([]byte(id))
+		if value == nil {
+			return nil
+		}
+		list = &model.TaskList{}
+		if err := openJSON(value, list); err != nil {
+			return fmt.Errorf("failed to unmarshal task list: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, fmt.Errorf("task list not found: %s", id)
+	}
+	return list, nil
+}
+
+// ListTaskLists 列出任务列表
+func (s *BoltStorage) ListTaskLists(_ context.Context) ([]model.TaskList, error) {
+	var result []model.TaskList
+	err := s.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(taskListsBucket).ForEach(func(_, value []byte) error {
+			var list model.TaskList
+			if err := openJSON(value, &list); err != nil {
+				return fmt.Errorf("failed to unmarshal task list: %w", err)
+			}
+			result = append(result, list)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteTaskList 删除任务列表
+func (s *BoltStorage) DeleteTaskList(_ context.Context, id string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(taskListsBucket).Delete([]byte(id)); err != nil {
+			return fmt.Errorf("failed to delete task list %s: %w", id, err)
+		}
+		return nil
+	})
+}
+
+// ExportToJSON 导出为 JSON
+func (s *BoltStorage) ExportToJSON(ctx context.Context, opts storage.ExportOptions) ([]byte, error) {
+	tasks, err := s.QueryTasks(ctx, opts.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Pretty {
+		return json.MarshalIndent(tasks, "", "  ")
+	}
+	return json.Marshal(tasks)
+}
+
+// ExportToMarkdown 导出为 Markdown
+func (s *BoltStorage) ExportToMarkdown(ctx context.Context, opts storage.ExportOptions) ([]byte, error) {
+	tasks, err := s.QueryTasks(ctx, opts.Query)
+	if err != nil {
+		return nil, err
+	}
+	return storage.RenderMarkdown(tasks), nil
+}
+
+// GetLastSyncTime 获取上次同步时间
+func (s *BoltStorage) GetLastSyncTime(_ context.Context, source model.TaskSource) (*time.Time, error) {
+	var value string
+	if err := s.view(func(tx *bolt.Tx) error {
+		value = string(tx.Bucket(syncTimesBucket).Get([]byte(source)))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+// SetLastSyncTime 设置上次同步时间
+func (s *BoltStorage) SetLastSyncTime(_ context.Context, source model.TaskSource, t time.Time) error {
+	return s.update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(syncTimesBucket).Put([]byte(source), []byte(t.Format(time.RFC3339))); err != nil {
+			return fmt.Errorf("failed to save sync time: %w", err)
+		}
+		return nil
+	})
+}
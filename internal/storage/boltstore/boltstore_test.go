@@ -0,0 +1,142 @@
+package boltstore
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+func TestBoltStorageQuery(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.Open(storage.Options{Backend: storage.BackendBolt, Path: dir})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	ctx := context.Background()
+	due := time.Now().Add(24 * time.Hour)
+	if err := store.SaveTasks(ctx, []*model.Task{
+		{ID: "t1", Title: "写周报", Source: model.SourceGoogle, ListID: "work", Priority: model.PriorityHigh, Tags: []string{"Work"}},
+		{ID: "t2", Title: "买牛奶", Source: model.SourceMicrosoft, ListID: "home", Priority: model.PriorityLow, DueDate: &due},
+		{ID: "t3", Title: "整理周报模板", Source: model.SourceGoogle, ListID: "work", Priority: model.PriorityMedium},
+	}); err != nil {
+		t.Fatalf("SaveTasks: %v", err)
+	}
+
+	tasks, err := store.ListTasks(ctx, storage.ListOptions{Source: model.SourceGoogle, ListID: "work"})
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("ListTasks: %d tasks, %v", len(tasks), err)
+	}
+	tasks, err = store.QueryTasks(ctx, storage.Query{QueryText: "周报", OrderBy: "priority", OrderDesc: true, Limit: 1})
+	if err != nil || len(tasks) != 1 || tasks[0].ID != "t1" {
+		t.Fatalf("QueryTasks: %+v, %v", tasks, err)
+	}
+
+	// 另一个实例（如同时运行的 mcp start 与命令行）看到同样的数据
+	other, err := New(dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := other.DeleteTask(ctx, "t2"); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := store.GetTask(ctx, "t2"); err == nil {
+		t.Fatal("deleted task should not be found")
+	}
+
+	if err := store.SaveTaskList(ctx, &model.TaskList{ID: "work", Name: "Work"}); err != nil {
+		t.Fatalf("SaveTaskList: %v", err)
+	}
+	if lists, err := other.ListTaskLists(ctx); err != nil || len(lists) != 1 || lists[0].Name != "Work" {
+		t.Fatalf("ListTaskLists: %+v, %v", lists, err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	if err := store.SetLastSyncTime(ctx, model.SourceGoogle, now); err != nil {
+		t.Fatalf("SetLastSyncTime: %v", err)
+	}
+	if got, err := other.GetLastSyncTime(ctx, model.SourceGoogle); err != nil || got == nil || !got.Equal(now) {
+		t.Fatalf("GetLastSyncTime: %v, %v", got, err)
+	}
+	if got, _ := store.GetLastSyncTime(ctx, model.SourceFeishu); got != nil {
+		t.Fatalf("unsynced source should have no sync time, got %v", got)
+	}
+}
+
+func TestBoltStorageImportsFileStorage(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	fs, err := filestore.New(dir, "json")
+	if err != nil {
+		t.Fatalf("filestore.New: %v", err)
+	}
+	if err := fs.SaveTask(ctx, &model.Task{ID: "t1", Title: "旧任务", Source: model.SourceGoogle}); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	if err := fs.SaveTaskList(ctx, &model.TaskList{ID: "l1", Name: "Inbox"}); err != nil {
+		t.Fatalf("SaveTaskList: %v", err)
+	}
+	if err := fs.SetLastSyncTime(ctx, model.SourceGoogle, time.Now()); err != nil {
+		t.Fatalf("SetLastSyncTime: %v", err)
+	}
+
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if task, err := store.GetTask(ctx, "t1"); err != nil || task.Title != "旧任务" {
+		t.Fatalf("task should be imported: %+v, %v", task, err)
+	}
+	if list, err := store.GetTaskList(ctx, "l1"); err != nil || list.Name != "Inbox" {
+		t.Fatalf("list should be imported: %+v, %v", list, err)
+	}
+	if got, _ := store.GetLastSyncTime(ctx, model.SourceGoogle); got == nil {
+		t.Fatal("sync time should be imported")
+	}
+}
+
+func TestBoltStorageEncryptsTaskContent(t *testing.T) {
+	t.Cleanup(func() { storecrypt.Use(nil) })
+	dir := t.TempDir()
+	raw, _ := storecrypt.GenerateKey()
+	key, err := storecrypt.UnlockRaw(dir, raw)
+	if err != nil {
+		t.Fatalf("UnlockRaw: %v", err)
+	}
+	storecrypt.Use(key)
+
+	store, err := New(dir)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+	if err := store.SaveTask(ctx, &model.Task{ID: "t1", Title: "Q3 budget", Source: model.SourceGoogle}); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	if task, err := store.GetTask(ctx, "t1"); err != nil || task.Title != "Q3 budget" {
+		t.Fatalf("GetTask: %+v, %v", task, err)
+	}
+
+	db, err := bolt.Open(filepath.Join(dir, DBFileName), 0644, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	var data string
+	_ = db.View(func(tx *bolt.Tx) error {
+		data = string(tx.Bucket(tasksBucket).Get([]byte("t1")))
+		return nil
+	})
+	if data == "" || strings.Contains(data, "budget") {
+		t.Fatalf("task content should be encrypted: %q", data)
+	}
+}
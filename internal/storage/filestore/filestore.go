@@ -7,14 +7,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/yeisme/taskbridge/internal/filter"
 	"github.com/yeisme/taskbridge/internal/model"
 	"github.com/yeisme/taskbridge/internal/storage"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
 )
 
 // FileStorage 文件存储实现
@@ -72,7 +70,7 @@ func New(basePath, format string) (*FileStorage, error) {
 // load 从文件加载数据
 func (fs *FileStorage) load() error {
 	// 加载任务
-	if data, err := os.ReadFile(fs.tasksFile); err == nil {
+	if data, err := storecrypt.ReadFile(fs.tasksFile); err == nil {
 		var tasks []*model.Task
 		if err := json.Unmarshal(data, &tasks); err != nil {
 			return fmt.Errorf("failed to unmarshal tasks: %w", err)
@@ -80,10 +78,12 @@ func (fs *FileStorage) load() error {
 		for _, task := range tasks {
 			fs.tasks[task.ID] = task
 		}
+	} else if !os.IsNotExist(err) {
+		return err
 	}
 
 	// 加载任务列表
-	if data, err := os.ReadFile(fs.listsFile); err == nil {
+	if data, err := storecrypt.ReadFile(fs.listsFile); err == nil {
 		var lists []*model.TaskList
 		if err := json.Unmarshal(data, &lists); err != nil {
 			return fmt.Errorf("failed to unmarshal lists: %w", err)
@@ -91,10 +91,12 @@ func (fs *FileStorage) load() error {
 		for _, list := range lists {
 			fs.taskLists[list.ID] = list
 		}
+	} else if !os.IsNotExist(err) {
+		return err
 	}
 
 	// 加载同步时间
-	if data, err := os.ReadFile(fs.syncFile); err == nil {
+	if data, err := storecrypt.ReadFile(fs.syncFile); err == nil {
 		var syncData map[string]string
 		if err := json.Unmarshal(data, &syncData); err != nil {
 			return fmt.Errorf("failed to unmarshal sync times: %w", err)
@@ -105,6 +107,8 @@ func (fs *FileStorage) load() error {
 				fs.syncTimes[model.TaskSource(source)] = t
 			}
 		}
+	} else if !os.IsNotExist(err) {
+		return err
 	}
 
 	return nil
@@ -121,7 +125,7 @@ func (fs *FileStorage) save() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal tasks: %w", err)
 	}
-	if err := os.WriteFile(fs.tasksFile, tasksData, 0644); err != nil {
+	if err := storecrypt.WriteFile(fs.tasksFile, tasksData, 0644); err != nil {
 		return fmt.Errorf("failed to write tasks file: %w", err)
 	}
 
@@ -134,7 +138,7 @@ func (fs *FileStorage) save() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal lists: %w", err)
 	}
-	if err := os.WriteFile(fs.listsFile, listsData, 0644); err != nil {
+	if err := storecrypt.WriteFile(fs.listsFile, listsData, 0644); err != nil {
 		return fmt.Errorf("failed to write lists file: %w", err)
 	}
 
@@ -147,7 +151,7 @@ func (fs *FileStorage) save() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal sync times: %w", err)
 	}
-	if err := os.WriteFile(fs.syncFile, syncDataBytes, 0644); err != nil {
+	if err := storecrypt.WriteFile(fs.syncFile, syncDataBytes, 0644); err != nil {
 		return fmt.Errorf("failed to write sync file: %w", err)
 	}
 
@@ -226,212 +230,14 @@ func (fs *FileStorage) QueryTasks(ctx context.Context, query storage.Query) ([]m
 	defer fs.mu.RUnlock()
 
 	var result []model.Task
-
 	for _, task := range fs.tasks {
-		if !fs.matchQuery(task, query) {
+		if !storage.MatchQuery(task, query) {
 			continue
 		}
 		result = append(result, *task)
 	}
 
-	// 排序
-	if query.OrderBy != "" {
-		fs.sortTasks(result, query.OrderBy, query.OrderDesc)
-	}
-
-	// 分页
-	if query.Offset > 0 || query.Limit > 0 {
-		start := query.Offset
-		if start > len(result) {
-			return []model.Task{}, nil
-		}
-		end := len(result)
-		if query.Limit > 0 && start+query.Limit < end {
-			end = start + query.Limit
-		}
-		result = result[start:end]
-	}
-
-	return result, nil
-}
-
-// matchQuery 检查任务是否匹配查询条件
-func (fs *FileStorage) matchQuery(task *model.Task, query storage.Query) bool {
-	// 任务 ID 过滤
-	if len(query.TaskIDs) > 0 && !containsString(query.TaskIDs, task.ID) {
-		return false
-	}
-
-	// 来源过滤
-	if len(query.Sources) > 0 {
-		found := false
-		for _, s := range query.Sources {
-			if task.Source == s {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
-	}
-
-	// 列表 ID 过滤
-	if len(query.ListIDs) > 0 && !containsString(query.ListIDs, task.ListID) {
-		return false
-	}
-
-	// 列表名称过滤（规范化精确匹配）
-	if len(query.ListNames) > 0 {
-		matched := false
-		for _, name := range query.ListNames {
-			if filter.MatchListNameExactNormalized(name, task.ListName) {
-				matched = true
-				break
-			}
-		}
-		if !matched {
-			return false
-		}
-	}
-
-	// 状态过滤
-	if len(query.Statuses) > 0 {
-		found := false
-		for _, s := range query.Statuses {
-			if task.Status == s {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
-	}
-
-	// 象限过滤
-	if len(query.Quadrants) > 0 {
-		found := false
-		for _, q := range query.Quadrants {
-			if task.Quadrant == q {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
-	}
-
-	// 优先级过滤
-	if len(query.Priorities) > 0 {
-		found := false
-		for _, p := range query.Priorities {
-			if task.Priority == p {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
-	}
-
-	// 标签过滤
-	if len(query.Tags) > 0 {
-		for _, tag := range query.Tags {
-			found := false
-			for _, t := range task.Tags {
-				if strings.EqualFold(t, tag) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				return false
-			}
-		}
-	}
-
-	// 截止日期过滤
-	if query.DueBefore != nil && task.DueDate != nil {
-		if task.DueDate.After(*query.DueBefore) {
-			return false
-		}
-	}
-	if query.DueAfter != nil && task.DueDate != nil {
-		if task.DueDate.Before(*query.DueAfter) {
-			return false
-		}
-	}
-
-	// 文本搜索（兼容 FullText）
-	queryText := query.QueryText
-	if queryText == "" {
-		queryText = query.FullText
-	}
-	if queryText != "" && !filter.MatchQueryText(task, queryText) {
-		return false
-	}
-
-	return true
-}
-
-// sortTasks 排序任务
-func (fs *FileStorage) sortTasks(tasks []model.Task, orderBy string, orderDesc bool) {
-	sort.Slice(tasks, func(i, j int) bool {
-		a, b := tasks[i], tasks[j]
-		cmp := 0
-		switch orderBy {
-		case "due_date":
-			cmp = compareTimePtr(a.DueDate, b.DueDate)
-		case "priority":
-			cmp = int(a.Priority) - int(b.Priority)
-		case "created_at":
-			cmp = compareTime(a.CreatedAt, b.CreatedAt)
-		case "updated_at":
-			cmp = compareTime(a.UpdatedAt, b.UpdatedAt)
-		default:
-			cmp = compareTime(a.UpdatedAt, b.UpdatedAt)
-		}
-
-		if orderDesc {
-			return cmp > 0
-		}
-		return cmp < 0
-	})
-}
-
-func compareTimePtr(a, b *time.Time) int {
-	switch {
-	case a == nil && b == nil:
-		return 0
-	case a == nil:
-		return 1
-	case b == nil:
-		return -1
-	default:
-		return compareTime(*a, *b)
-	}
-}
-
-func compareTime(a, b time.Time) int {
-	if a.Before(b) {
-		return -1
-	}
-	if a.After(b) {
-		return 1
-	}
-	return 0
-}
-
-func containsString(values []string, target string) bool {
-	for _, v := range values {
-		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(target)) {
-			return true
-		}
-	}
-	return false
+	return storage.SortAndPage(result, query), nil
 }
 
 // SaveTaskList 保存任务列表
@@ -497,48 +303,7 @@ func (fs *FileStorage) ExportToMarkdown(ctx context.Context, opts storage.Export
 	if err != nil {
 		return nil, err
 	}
-
-	var sb strings.Builder
-	sb.WriteString("# Tasks\n\n")
-
-	// 按象限分组
-	quadrants := map[model.Quadrant][]model.Task{
-		model.QuadrantUrgentImportant:       {},
-		model.QuadrantNotUrgentImportant:    {},
-		model.QuadrantUrgentNotImportant:    {},
-		model.QuadrantNotUrgentNotImportant: {},
-	}
-
-	for _, task := range tasks {
-		quadrants[task.Quadrant] = append(quadrants[task.Quadrant], task)
-	}
-
-	quadrantNames := map[model.Quadrant]string{
-		model.QuadrantUrgentImportant:       "🔥 紧急且重要 (Q1)",
-		model.QuadrantNotUrgentImportant:    "📋 重要不紧急 (Q2)",
-		model.QuadrantUrgentNotImportant:    "⚡ 紧急不重要 (Q3)",
-		model.QuadrantNotUrgentNotImportant: "🗑️ 不紧急不重要 (Q4)",
-	}
-
-	for q := model.Quadrant(1); q <= 4; q++ {
-		fmt.Fprintf(&sb, "## %s\n\n", quadrantNames[q])
-		for _, task := range quadrants[q] {
-			status := " "
-			if task.IsCompleted() {
-				status = "x"
-			}
-			fmt.Fprintf(&sb, "- [%s] %s\n", status, task.Title)
-			if task.DueDate != nil {
-				fmt.Fprintf(&sb, "  - 截止日期: %s\n", task.DueDate.Format("2006-01-02"))
-			}
-			if task.Priority != model.PriorityNone {
-				fmt.Fprintf(&sb, "  - 优先级: %s\n", task.Priority.String())
-			}
-		}
-		sb.WriteString("\n")
-	}
-
-	return []byte(sb.String()), nil
+	return storage.RenderMarkdown(tasks), nil
 }
 
 // GetLastSyncTime 获取上次同步时间
@@ -561,3 +326,32 @@ func (fs *FileStorage) SetLastSyncTime(ctx context.Context, source model.TaskSou
 	fs.syncTimes[source] = t
 	return fs.save()
 }
+
+// Snapshot file 后端保存的全部数据
+type Snapshot struct {
+	Tasks     []model.Task
+	Lists     []model.TaskList
+	SyncTimes map[model.TaskSource]time.Time
+}
+
+// ReadSnapshot 读取 basePath 下 file 后端的数据，供其他后端首次打开空库时导入；不存在 tasks.json 时返回 nil
+func ReadSnapshot(basePath string) (*Snapshot, error) {
+	if _, err := os.Stat(filepath.Join(basePath, "tasks.json")); os.IsNotExist(err) {
+		return nil, nil
+	}
+	fs, err := New(basePath, "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to import file storage: %w", err)
+	}
+	snapshot := &Snapshot{SyncTimes: make(map[model.TaskSource]time.Time, len(fs.syncTimes))}
+	for _, task := range fs.tasks {
+		snapshot.Tasks = append(snapshot.Tasks, *task)
+	}
+	for _, list := range fs.taskLists {
+		snapshot.Lists = append(snapshot.Lists, *list)
+	}
+	for source, t := range fs.syncTimes {
+		snapshot.SyncTimes[source] = t
+	}
+	return snapshot, nil
+}
@@ -7,14 +7,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/yeisme/taskbridge/internal/filter"
 	"github.com/yeisme/taskbridge/internal/model"
 	"github.com/yeisme/taskbridge/internal/storage"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
 )
 
 // MultiProviderStorage 多 Provider 存储实现
@@ -89,7 +87,7 @@ func NewMultiProviderStorage(basePath, format string) (*MultiProviderStorage, er
 // loadGlobalData 加载全局数据
 func (mps *MultiProviderStorage) loadGlobalData() error {
 	// 加载清单
-	if data, err := os.ReadFile(mps.manifestFile); err == nil {
+	if data, err := storecrypt.ReadFile(mps.manifestFile); err == nil {
 		var manifest model.Manifest
 		if err := json.Unmarshal(data, &manifest); err != nil {
 			return fmt.Errorf("failed to unmarshal manifest: %w", err)
@@ -102,7 +100,7 @@ func (mps *MultiProviderStorage) loadGlobalData() error {
 	}
 
 	// 加载同步状态
-	if data, err := os.ReadFile(mps.syncStateFile); err == nil {
+	if data, err := storecrypt.ReadFile(mps.syncStateFile); err == nil {
 		var syncState model.SyncState
 		if err := json.Unmarshal(data, &syncState); err != nil {
 			return fmt.Errorf("failed to unmarshal sync state: %w", err)
@@ -115,7 +113,7 @@ func (mps *MultiProviderStorage) loadGlobalData() error {
 	}
 
 	// 加载映射
-	if data, err := os.ReadFile(mps.mappingsFile); err == nil {
+	if data, err := storecrypt.ReadFile(mps.mappingsFile); err == nil {
 		var mappings model.MappingDatabase
 		if err := json.Unmarshal(data, &mappings); err != nil {
 			return fmt.Errorf("failed to unmarshal mappings: %w", err)
@@ -138,7 +136,7 @@ func (mps *MultiProviderStorage) saveGlobalData() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal manifest: %w", err)
 	}
-	if err := os.WriteFile(mps.manifestFile, manifestData, 0644); err != nil {
+	if err := storecrypt.WriteFile(mps.manifestFile, manifestData, 0644); err != nil {
 		return fmt.Errorf("failed to write manifest: %w", err)
 	}
 
@@ -147,7 +145,7 @@ func (mps *MultiProviderStorage) saveGlobalData() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal sync state: %w", err)
 	}
-	if err := os.WriteFile(mps.syncStateFile, syncStateData, 0644); err != nil {
+	if err := storecrypt.WriteFile(mps.syncStateFile, syncStateData, 0644); err != nil {
 		return fmt.Errorf("failed to write sync state: %w", err)
 	}
 
@@ -156,7 +154,7 @@ func (mps *MultiProviderStorage) saveGlobalData() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal mappings: %w", err)
 	}
-	if err := os.WriteFile(mps.mappingsFile, mappingsData, 0644); err != nil {
+	if err := storecrypt.WriteFile(mps.mappingsFile, mappingsData, 0644); err != nil {
 		return fmt.Errorf("failed to write mappings: %w", err)
 	}
 
@@ -415,23 +413,7 @@ func (mps *MultiProviderStorage) QueryTasks(ctx context.Context, query storage.Q
 		result = append(result, tasks...)
 	}
 
-	if query.OrderBy != "" {
-		sortTasks(result, query.OrderBy, query.OrderDesc)
-	}
-
-	if query.Offset > 0 || query.Limit > 0 {
-		start := query.Offset
-		if start > len(result) {
-			return []model.Task{}, nil
-		}
-		end := len(result)
-		if query.Limit > 0 && start+query.Limit < end {
-			end = start + query.Limit
-		}
-		result = result[start:end]
-	}
-
-	return result, nil
+	return storage.SortAndPage(result, query), nil
 }
 
 // SaveTaskList 保存任务列表
@@ -507,9 +489,11 @@ func (mps *MultiProviderStorage) ExportToJSON(ctx context.Context, opts storage.
 
 // ExportToMarkdown 导出为 Markdown
 func (mps *MultiProviderStorage) ExportToMarkdown(ctx context.Context, opts storage.ExportOptions) ([]byte, error) {
-	// 复用 FileStorage 的实现
-	fs := &FileStorage{}
-	return fs.ExportToMarkdown(ctx, opts)
+	tasks, err := mps.QueryTasks(ctx, opts.Query)
+	if err != nil {
+		return nil, err
+	}
+	return storage.RenderMarkdown(tasks), nil
 }
 
 // GetLastSyncTime 获取上次同步时间
@@ -564,7 +548,7 @@ func NewProviderStorage(provider, basePath string) (*ProviderStorage, error) {
 // load 加载 Provider 数据
 func (ps *ProviderStorage) load() error {
 	// 加载任务
-	if data, err := os.ReadFile(ps.tasksFile); err == nil {
+	if data, err := storecrypt.ReadFile(ps.tasksFile); err == nil {
 		var tasks []*model.Task
 		if err := json.Unmarshal(data, &tasks); err != nil {
 			return fmt.Errorf("failed to unmarshal tasks: %w", err)
@@ -572,10 +556,12 @@ func (ps *ProviderStorage) load() error {
 		for _, task := range tasks {
 			ps.tasks[task.ID] = task
 		}
+	} else if !os.IsNotExist(err) {
+		return err
 	}
 
 	// 加载列表
-	if data, err := os.ReadFile(ps.listsFile); err == nil {
+	if data, err := storecrypt.ReadFile(ps.listsFile); err == nil {
 		var lists []*model.TaskList
 		if err := json.Unmarshal(data, &lists); err != nil {
 			return fmt.Errorf("failed to unmarshal lists: %w", err)
@@ -583,10 +569,12 @@ func (ps *ProviderStorage) load() error {
 		for _, list := range lists {
 			ps.taskLists[list.ID] = list
 		}
+	} else if !os.IsNotExist(err) {
+		return err
 	}
 
 	// 加载元数据
-	if data, err := os.ReadFile(ps.metaFile); err == nil {
+	if data, err := storecrypt.ReadFile(ps.metaFile); err == nil {
 		var meta model.ProviderData
 		if err := json.Unmarshal(data, &meta); err != nil {
 			return fmt.Errorf("failed to unmarshal meta: %w", err)
@@ -597,6 +585,8 @@ func (ps *ProviderStorage) load() error {
 			Provider:     ps.provider,
 			Capabilities: model.Capabilities{},
 		}
+	} else {
+		return err
 	}
 
 	return nil
@@ -613,7 +603,7 @@ func (ps *ProviderStorage) save() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal tasks: %w", err)
 	}
-	if err := os.WriteFile(ps.tasksFile, tasksData, 0644); err != nil {
+	if err := storecrypt.WriteFile(ps.tasksFile, tasksData, 0644); err != nil {
 		return fmt.Errorf("failed to write tasks file: %w", err)
 	}
 
@@ -626,7 +616,7 @@ func (ps *ProviderStorage) save() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal lists: %w", err)
 	}
-	if err := os.WriteFile(ps.listsFile, listsData, 0644); err != nil {
+	if err := storecrypt.WriteFile(ps.listsFile, listsData, 0644); err != nil {
 		return fmt.Errorf("failed to write lists file: %w", err)
 	}
 
@@ -635,7 +625,7 @@ func (ps *ProviderStorage) save() error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal meta: %w", err)
 	}
-	if err := os.WriteFile(ps.metaFile, metaData, 0644); err != nil {
+	if err := storecrypt.WriteFile(ps.metaFile, metaData, 0644); err != nil {
 		return fmt.Errorf("failed to write meta file: %w", err)
 	}
 
@@ -708,216 +698,16 @@ func (ps *ProviderStorage) QueryTasks(_ context.Context, query storage.Query) ([
 	ps.mu.RLock()
 	defer ps.mu.RUnlock()
 
-	queryText := normalizedQueryText(query)
-
 	var result []model.Task
 	for _, task := range ps.tasks {
-		if !taskMatchesQuery(task, query, queryText) {
+		if !storage.MatchQuery(task, query) {
 			continue
 		}
 
 		result = append(result, *task)
 	}
 
-	if query.OrderBy != "" {
-		sortTasks(result, query.OrderBy, query.OrderDesc)
-	}
-	if query.Offset > 0 || query.Limit > 0 {
-		start := query.Offset
-		if start > len(result) {
-			return []model.Task{}, nil
-		}
-		end := len(result)
-		if query.Limit > 0 && start+query.Limit < end {
-			end = start + query.Limit
-		}
-		result = result[start:end]
-	}
-
-	return result, nil
-}
-
-func normalizedQueryText(query storage.Query) string {
-	if query.QueryText != "" {
-		return query.QueryText
-	}
-	return query.FullText
-}
-
-func taskMatchesQuery(task *model.Task, query storage.Query, queryText string) bool {
-	return matchTaskID(query.TaskIDs, task.ID) &&
-		matchSource(query.Sources, task.Source) &&
-		matchListID(query.ListIDs, task.ListID) &&
-		matchListName(query.ListNames, task.ListName) &&
-		matchStatus(query.Statuses, task.Status) &&
-		matchQuadrant(query.Quadrants, task.Quadrant) &&
-		matchPriority(query.Priorities, task.Priority) &&
-		matchTags(query.Tags, task.Tags) &&
-		matchDueDate(query.DueBefore, query.DueAfter, task.DueDate) &&
-		matchQueryText(queryText, task)
-}
-
-func matchTaskID(ids []string, taskID string) bool {
-	return len(ids) == 0 || containsStringCI(ids, taskID)
-}
-
-func matchSource(sources []model.TaskSource, source model.TaskSource) bool {
-	if len(sources) == 0 {
-		return true
-	}
-	for _, s := range sources {
-		if source == s {
-			return true
-		}
-	}
-	return false
-}
-
-func matchListID(ids []string, listID string) bool {
-	return len(ids) == 0 || containsStringCI(ids, listID)
-}
-
-func matchListName(listNames []string, listName string) bool {
-	if len(listNames) == 0 {
-		return true
-	}
-	for _, name := range listNames {
-		if filter.MatchListNameExactNormalized(name, listName) {
-			return true
-		}
-	}
-	return false
-}
-
-func matchStatus(statuses []model.TaskStatus, status model.TaskStatus) bool {
-	if len(statuses) == 0 {
-		return true
-	}
-	for _, s := range statuses {
-		if status == s {
-			return true
-		}
-	}
-	return false
-}
-
-func matchQuadrant(quadrants []model.Quadrant, quadrant model.Quadrant) bool {
-	if len(quadrants) == 0 {
-		return true
-	}
-	for _, q := range quadrants {
-		if quadrant == q {
-			return true
-		}
-	}
-	return false
-}
-
-func matchPriority(priorities []model.Priority, priority model.Priority) bool {
-	if len(priorities) == 0 {
-		return true
-	}
-	for _, p := range priorities {
-		if priority == p {
-			return true
-		}
-	}
-	return false
-}
-
-func matchTags(queryTags []string, taskTags []string) bool {
-	if len(queryTags) == 0 {
-		return true
-	}
-	for _, tag := range queryTags {
-		found := false
-		for _, taskTag := range taskTags {
-			if strings.EqualFold(strings.TrimSpace(tag), strings.TrimSpace(taskTag)) {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
-	}
-	return true
-}
-
-func matchDueDate(dueBefore, dueAfter, dueDate *time.Time) bool {
-	if dueBefore != nil && dueDate != nil && dueDate.After(*dueBefore) {
-		return false
-	}
-	if dueAfter != nil && dueDate != nil && dueDate.Before(*dueAfter) {
-		return false
-	}
-	return true
-}
-
-func matchQueryText(queryText string, task *model.Task) bool {
-	if queryText == "" {
-		return true
-	}
-	return filter.MatchQueryText(task, queryText)
-}
-
-func sortTasks(tasks []model.Task, orderBy string, orderDesc bool) {
-	sort.Slice(tasks, func(i, j int) bool {
-		a, b := tasks[i], tasks[j]
-		cmp := compareTaskByOrder(a, b, orderBy)
-		if orderDesc {
-			return cmp > 0
-		}
-		return cmp < 0
-	})
-}
-
-func compareTaskByOrder(a, b model.Task, orderBy string) int {
-	switch orderBy {
-	case "due_date":
-		return compareTimePointers(a.DueDate, b.DueDate)
-	case "priority":
-		return int(a.Priority) - int(b.Priority)
-	case "created_at":
-		return compareTimes(a.CreatedAt, b.CreatedAt)
-	case "updated_at":
-		return compareTimes(a.UpdatedAt, b.UpdatedAt)
-	default:
-		return compareTimes(a.UpdatedAt, b.UpdatedAt)
-	}
-}
-
-func compareTimePointers(a, b *time.Time) int {
-	switch {
-	case a == nil && b == nil:
-		return 0
-	case a == nil:
-		return 1
-	case b == nil:
-		return -1
-	default:
-		return compareTimes(*a, *b)
-	}
-}
-
-func compareTimes(a, b time.Time) int {
-	if a.Before(b) {
-		return -1
-	}
-	if a.After(b) {
-		return 1
-	}
-	return 0
-}
-
-func containsStringCI(values []string, target string) bool {
-	target = strings.TrimSpace(target)
-	for _, v := range values {
-		if strings.EqualFold(strings.TrimSpace(v), target) {
-			return true
-		}
-	}
-	return false
+	return storage.SortAndPage(result, query), nil
 }
 
 // SaveTaskList 保存任务列表
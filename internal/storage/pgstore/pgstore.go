@@ -0,0 +1,376 @@
+// Package pgstore 提供基于 PostgreSQL 的存储实现
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+
+	// 纯 Go 实现的 PostgreSQL 驱动
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// pgSchema 表名带 taskbridge_ 前缀，便于与其他应用共用数据库。
+// 任务与列表以 JSON 保存在 data 列（启用静态加密时加密），source、list_id 保留明文用于按来源与列表筛选
+const pgSchema = `
+CREATE TABLE IF NOT EXISTS taskbridge_tasks (
+	id         TEXT PRIMARY KEY,
+	source     TEXT NOT NULL DEFAULT '',
+	list_id    TEXT NOT NULL DEFAULT '',
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS taskbridge_tasks_source_list ON taskbridge_tasks (source, list_id);
+CREATE TABLE IF NOT EXISTS taskbridge_task_lists (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS taskbridge_sync_times (
+	source    TEXT PRIMARY KEY,
+	synced_at TIMESTAMPTZ NOT NULL
+);
+`
+
+func init() {
+	_ = storage.Register(storage.BackendPostgres, func(opts storage.Options) (storage.Storage, error) {
+		return New(opts.DSN, opts.Path)
+	})
+}
+
+// PostgresStorage PostgreSQL 存储实现。每次读写直接访问数据库，
+// 多台机器上的 mcp start、sync start 与命令行可以共享同一份任务数据
+type PostgresStorage struct {
+	db *sql.DB
+}
+
+// New 连接 dsn 指向的数据库并创建所需的表；数据库为空且 basePath 下存在 file 后端的 tasks.json 等文件时导入其中的数据
+func New(dsn, basePath string) (*PostgresStorage, error) {
+	if strings.TrimSpace(dsn) == "" {
+		return nil, fmt.Errorf("postgres storage requires storage.postgres.dsn")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage db: %w", err)
+	}
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, pgSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to init storage db: %w", err)
+	}
+
+	store := &PostgresStorage{db: db}
+	if basePath != "" {
+		if err := store.importFileStorage(ctx, basePath); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// importFileStorage 从 file 后端迁移：数据库中没有任何任务与列表时导入 tasks.json、lists.json 与 sync.json
+func (s *PostgresStorage) importFileStorage(ctx context.Context, basePath string) error {
+	var count int
+	if err := s.db.QueryRowContext(ctx, `SELECT (SELECT COUNT(*) FROM taskbridge_tasks) + (SELECT COUNT(*) FROM taskbridge_task_lists)`).Scan(&count); err != nil {
+		return fmt.Errorf("failed to read storage db: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+	snapshot, err := filestore.ReadSnapshot(basePath)
+	if err != nil || snapshot == nil {
+		return err
+	}
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for i := range snapshot.Tasks {
+			if err := putTask(ctx, tx, &snapshot.Tasks[i]); err != nil {
+				return err
+			}
+		}
+		for i := range snapshot.Lists {
+			if err := putTaskList(ctx, tx, &snapshot.Lists[i]); err != nil {
+				return err
+			}
+		}
+		for source, t := range snapshot.SyncTimes {
+			if err := putSyncTime(ctx, tx, source, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close 关闭数据库连接
+func (s *PostgresStorage) Close() error {
+	return s.db.Close()
+}
+
+// execer 事务与数据库共有的写入方法
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (s *PostgresStorage) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin storage transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit storage transaction: %w", err)
+	}
+	return nil
+}
+
+// sealJSON 序列化并在启用静态加密时加密
+func sealJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return storecrypt.SealString(string(data))
+}
+
+// openJSON 解密并反序列化 sealJSON 的结果
+func openJSON(value string, v any) error {
+	plain, err := storecrypt.OpenString(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(plain), v)
+}
+
+func putTask(ctx context.Context, db execer, task *model.Task) error {
+	data, err := sealJSON(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO taskbridge_tasks (id, source, list_id, data) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET source = excluded.source, list_id = excluded.list_id, data = excluded.data`,
+		task.ID, string(task.Source), task.ListID, data,
+	); err != nil {
+		return fmt.Errorf("failed to save task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+func putTaskList(ctx context.Context, db execer, list *model.TaskList) error {
+	data, err := sealJSON(list)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task list: %w", err)
+	}
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO taskbridge_task_lists (id, data) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET data = excluded.data`,
+		list.ID, data,
+	); err != nil {
+		return fmt.Errorf("failed to save task list %s: %w", list.ID, err)
+	}
+	return nil
+}
+
+func putSyncTime(ctx context.Context, db execer, source model.TaskSource, t time.Time) error {
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO taskbridge_sync_times (source, synced_at) VALUES ($1, $2) ON CONFLICT (source) DO UPDATE SET synced_at = excluded.synced_at`,
+		string(source), t,
+	); err != nil {
+		return fmt.Errorf("failed to save sync time: %w", err)
+	}
+	return nil
+}
+
+// queryTasks 读取满足 SQL 条件的任务
+func (s *PostgresStorage) queryTasks(ctx context.Context, where string, args ...any) ([]model.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM taskbridge_tasks`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []model.Task
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to query tasks: %w", err)
+		}
+		var task model.Task
+		if err := openJSON(data, &task); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal task: %w", err)
+		}
+		result = append(result, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	return result, nil
+}
+
+// SaveTask 保存任务
+func (s *PostgresStorage) SaveTask(ctx context.Context, task *model.Task) error {
+	task.UpdatedAt = time.Now()
+	return putTask(ctx, s.db, task)
+}
+
+// GetTask 获取任务
+func (s *PostgresStorage) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	tasks, err := s.queryTasks(ctx, ` WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("task not found: %s", id)
+	}
+	return &tasks[0], nil
+}
+
+// ListTasks 列出任务
+func (s *PostgresStorage) ListTasks(ctx context.Context, opts storage.ListOptions) ([]model.Task, error) {
+	return s.queryTasks(ctx, ` WHERE ($1::text = '' OR source = $1) AND ($2::text = '' OR list_id = $2)`,
+		string(opts.Source), opts.ListID)
+}
+
+// DeleteTask 删除任务
+func (s *PostgresStorage) DeleteTask(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM taskbridge_tasks WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete task %s: %w", id, err)
+	}
+	return nil
+}
+
+// SaveTasks 批量保存任务，在同一事务中写入
+func (s *PostgresStorage) SaveTasks(ctx context.Context, tasks []*model.Task) error {
+	now := time.Now()
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, task := range tasks {
+			task.UpdatedAt = now
+			if err := putTask(ctx, tx, task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// QueryTasks 查询任务
+func (s *PostgresStorage) QueryTasks(ctx context.Context, query storage.Query) ([]model.Task, error) {
+	tasks, err := s.queryTasks(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []model.Task
+	for i := range tasks {
+		if storage.MatchQuery(&tasks[i], query) {
+			result = append(result, tasks[i])
+		}
+	}
+	return storage.SortAndPage(result, query), nil
+}
+
+// SaveTaskList 保存任务列表
+func (s *PostgresStorage) SaveTaskList(ctx context.Context, list *model.TaskList) error {
+	list.UpdatedAt = time.Now()
+	return putTaskList(ctx, s.db, list)
+}
+
+// GetTaskList 获取任务列表
+func (s *PostgresStorage) GetTaskList(ctx context.Context, id string) (*model.TaskList, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM taskbridge_task_lists WHERE id = $1`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("task list not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task list: %w", err)
+	}
+	var list model.TaskList
+	if err := openJSON(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task list: %w", err)
+	}
+	return &list, nil
+}
+
+// ListTaskLists 列出任务列表
+func (s *PostgresStorage) ListTaskLists(ctx context.Context) ([]model.TaskList, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM taskbridge_task_lists`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task lists: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []model.TaskList
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to query task lists: %w", err)
+		}
+		var list model.TaskList
+		if err := openJSON(data, &list); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal task list: %w", err)
+		}
+		result = append(result, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query task lists: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteTaskList 删除任务列表
+func (s *PostgresStorage) DeleteTaskList(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM taskbridge_task_lists WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete task list %s: %w", id, err)
+	}
+	return nil
+}
+
+// ExportToJSON 导出为 JSON
+func (s *PostgresStorage) ExportToJSON(ctx context.Context, opts storage.ExportOptions) ([]byte, error) {
+	tasks, err := s.QueryTasks(ctx, opts.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Pretty {
+		return json.MarshalIndent(tasks, "", "  ")
+	}
+	return json.Marshal(tasks)
+}
+
+// ExportToMarkdown 导出为 Markdown
+func (s *PostgresStorage) ExportToMarkdown(ctx context.Context, opts storage.ExportOptions) ([]byte, error) {
+	tasks, err := s.QueryTasks(ctx, opts.Query)
+	if err != nil {
+		return nil, err
+	}
+	return storage.RenderMarkdown(tasks), nil
+}
+
+// GetLastSyncTime 获取上次同步时间
+func (s *PostgresStorage) GetLastSyncTime(ctx context.Context, source model.TaskSource) (*time.Time, error) {
+	var t time.Time
+	err := s.db.QueryRowContext(ctx, `SELECT synced_at FROM taskbridge_sync_times WHERE source = $1`, string(source)).Scan(&t)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync time: %w", err)
+	}
+	return &t, nil
+}
+
+// SetLastSyncTime 设置上次同步时间
+func (s *PostgresStorage) SetLastSyncTime(ctx context.Context, source model.TaskSource, t time.Time) error {
+	return putSyncTime(ctx, s.db, source, t)
+}
@@ -0,0 +1,115 @@
+package pgstore
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+)
+
+// openTestStorage 连接 TASKBRIDGE_TEST_POSTGRES_DSN 指向的测试库并清空 taskbridge_ 表，未设置时跳过
+func openTestStorage(t *testing.T, basePath string) *PostgresStorage {
+	t.Helper()
+	dsn := os.Getenv("TASKBRIDGE_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TASKBRIDGE_TEST_POSTGRES_DSN is not set")
+	}
+	store, err := New(dsn, "")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := store.db.Exec(`TRUNCATE taskbridge_tasks, taskbridge_task_lists, taskbridge_sync_times`); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	_ = store.Close()
+
+	store, err = New(dsn, basePath)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestPostgresStorageQuery(t *testing.T) {
+	store := openTestStorage(t, "")
+	ctx := context.Background()
+	due := time.Now().Add(24 * time.Hour)
+	if err := store.SaveTasks(ctx, []*model.Task{
+		{ID: "t1", Title: "写周报", Source: model.SourceGoogle, ListID: "work", Priority: model.PriorityHigh, Tags: []string{"Work"}},
+		{ID: "t2", Title: "买牛奶", Source: model.SourceMicrosoft, ListID: "home", Priority: model.PriorityLow, DueDate: &due},
+		{ID: "t3", Title: "整理周报模板", Source: model.SourceGoogle, ListID: "work", Priority: model.PriorityMedium},
+	}); err != nil {
+		t.Fatalf("SaveTasks: %v", err)
+	}
+
+	tasks, err := store.ListTasks(ctx, storage.ListOptions{Source: model.SourceGoogle, ListID: "work"})
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("ListTasks: %d tasks, %v", len(tasks), err)
+	}
+	if tasks, err := store.ListTasks(ctx, storage.ListOptions{}); err != nil || len(tasks) != 3 {
+		t.Fatalf("ListTasks without filter: %d tasks, %v", len(tasks), err)
+	}
+	tasks, err = store.QueryTasks(ctx, storage.Query{QueryText: "周报", OrderBy: "priority", OrderDesc: true, Limit: 1})
+	if err != nil || len(tasks) != 1 || tasks[0].ID != "t1" {
+		t.Fatalf("QueryTasks: %+v, %v", tasks, err)
+	}
+
+	if err := store.DeleteTask(ctx, "t2"); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := store.GetTask(ctx, "t2"); err == nil {
+		t.Fatal("deleted task should not be found")
+	}
+
+	if err := store.SaveTaskList(ctx, &model.TaskList{ID: "work", Name: "Work"}); err != nil {
+		t.Fatalf("SaveTaskList: %v", err)
+	}
+	if list, err := store.GetTaskList(ctx, "work"); err != nil || list.Name != "Work" {
+		t.Fatalf("GetTaskList: %+v, %v", list, err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	if err := store.SetLastSyncTime(ctx, model.SourceGoogle, now); err != nil {
+		t.Fatalf("SetLastSyncTime: %v", err)
+	}
+	if got, err := store.GetLastSyncTime(ctx, model.SourceGoogle); err != nil || got == nil || !got.Equal(now) {
+		t.Fatalf("GetLastSyncTime: %v, %v", got, err)
+	}
+	if got, _ := store.GetLastSyncTime(ctx, model.SourceFeishu); got != nil {
+		t.Fatalf("unsynced source should have no sync time, got %v", got)
+	}
+}
+
+func TestPostgresStorageImportsFileStorage(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	fs, err := filestore.New(dir, "json")
+	if err != nil {
+		t.Fatalf("filestore.New: %v", err)
+	}
+	if err := fs.SaveTask(ctx, &model.Task{ID: "t1", Title: "旧任务", Source: model.SourceGoogle}); err != nil {
+		t.Fatalf("SaveTask: %v", err)
+	}
+	if err := fs.SetLastSyncTime(ctx, model.SourceGoogle, time.Now()); err != nil {
+		t.Fatalf("SetLastSyncTime: %v", err)
+	}
+
+	store := openTestStorage(t, dir)
+	if task, err := store.GetTask(ctx, "t1"); err != nil || task.Title != "旧任务" {
+		t.Fatalf("task should be imported: %+v, %v", task, err)
+	}
+	if got, _ := store.GetLastSyncTime(ctx, model.SourceGoogle); got == nil {
+		t.Fatal("sync time should be imported")
+	}
+}
+
+func TestPostgresStorageRequiresDSN(t *testing.T) {
+	if _, err := storage.Open(storage.Options{Backend: storage.BackendPostgres}); err == nil {
+		t.Fatal("expected error without dsn")
+	}
+}
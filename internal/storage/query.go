@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/filter"
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// 以下辅助函数供各存储后端实现 QueryTasks 与导出，保证不同后端的筛选、排序与分页结果一致
+
+// MatchQuery 检查任务是否匹配查询条件（不含排序与分页）
+func MatchQuery(task *model.Task, query Query) bool {
+	queryText := query.QueryText
+	if queryText == "" {
+		queryText = query.FullText
+	}
+	return matchTaskID(query.TaskIDs, task.ID) &&
+		matchSource(query.Sources, task.Source) &&
+		matchListID(query.ListIDs, task.ListID) &&
+		matchListName(query.ListNames, task.ListName) &&
+		matchStatus(query.Statuses, task.Status) &&
+		matchQuadrant(query.Quadrants, task.Quadrant) &&
+		matchPriority(query.Priorities, task.Priority) &&
+		matchTags(query.Tags, task.Tags) &&
+		matchDueDate(query.DueBefore, query.DueAfter, task.DueDate) &&
+		(queryText == "" || filter.MatchQueryText(task, queryText))
+}
+
+// SortAndPage 按查询的 OrderBy/OrderDesc 排序，再按 Offset/Limit 截取
+func SortAndPage(tasks []model.Task, query Query) []model.Task {
+	if query.OrderBy != "" {
+		SortTasks(tasks, query.OrderBy, query.OrderDesc)
+	}
+	if query.Offset > 0 || query.Limit > 0 {
+		start := query.Offset
+		if start > len(tasks) {
+			return []model.Task{}
+		}
+		end := len(tasks)
+		if query.Limit > 0 && start+query.Limit < end {
+			end = start + query.Limit
+		}
+		tasks = tasks[start:end]
+	}
+	return tasks
+}
+
+// SortTasks 排序任务，orderBy 为 due_date、priority、created_at 或 updated_at（默认）；没有截止日期的任务排在最后
+func SortTasks(tasks []model.Task, orderBy string, orderDesc bool) {
+	sort.Slice(tasks, func(i, j int) bool {
+		cmp := compareTaskByOrder(tasks[i], tasks[j], orderBy)
+		if orderDesc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
+
+// RenderMarkdown 将任务按四象限分组渲染为 Markdown 清单
+func RenderMarkdown(tasks []model.Task) []byte {
+	var sb strings.Builder
+	sb.WriteString("# Tasks\n\n")
+
+	// 按象限分组
+	quadrants := map[model.Quadrant][]model.Task{
+		model.QuadrantUrgentImportant:       {},
+		model.QuadrantNotUrgentImportant:    {},
+		model.QuadrantUrgentNotImportant:    {},
+		model.QuadrantNotUrgentNotImportant: {},
+	}
+
+	for _, task := range tasks {
+		quadrants[task.Quadrant] = append(quadrants[task.Quadrant], task)
+	}
+
+	quadrantNames := map[model.Quadrant]string{
+		model.QuadrantUrgentImportant:       "🔥 紧急且重要 (Q1)",
+		model.QuadrantNotUrgentImportant:    "📋 重要不紧急 (Q2)",
+		model.QuadrantUrgentNotImportant:    "⚡ 紧急不重要 (Q3)",
+		model.QuadrantNotUrgentNotImportant: "🗑️ 不紧急不重要 (Q4)",
+	}
+
+	for q := model.Quadrant(1); q <= 4; q++ {
+		fmt.Fprintf(&sb, "## %s\n\n", quadrantNames[q])
+		for _, task := range quadrants[q] {
+			status := " "
+			if task.IsCompleted() {
+				status = "x"
+			}
+			fmt.Fprintf(&sb, "- [%s] %s\n", status, task.Title)
+			if task.DueDate != nil {
+				fmt.Fprintf(&sb, "  - 截止日期: %s\n", task.DueDate.Format("2006-01-02"))
+			}
+			if task.Priority != model.PriorityNone {
+				fmt.Fprintf(&sb, "  - 优先级: %s\n", task.Priority.String())
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return []byte(sb.String())
+}
+
+func matchTaskID(ids []string, taskID string) bool {
+	return len(ids) == 0 || containsStringCI(ids, taskID)
+}
+
+func matchSource(sources []model.TaskSource, source model.TaskSource) bool {
+	if len(sources) == 0 {
+		return true
+	}
+	for _, s := range sources {
+		if source == s {
+			return true
+		}
+	}
+	return false
+}
+
+func matchListID(ids []string, listID string) bool {
+	return len(ids) == 0 || containsStringCI(ids, listID)
+}
+
+func matchListName(listNames []string, listName string) bool {
+	if len(listNames) == 0 {
+		return true
+	}
+	for _, name := range listNames {
+		if filter.MatchListNameExactNormalized(name, listName) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchStatus(statuses []model.TaskStatus, status model.TaskStatus) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+func matchQuadrant(quadrants []model.Quadrant, quadrant model.Quadrant) bool {
+	if len(quadrants) == 0 {
+		return true
+	}
+	for _, q := range quadrants {
+		if quadrant == q {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPriority(priorities []model.Priority, priority model.Priority) bool {
+	if len(priorities) == 0 {
+		return true
+	}
+	for _, p := range priorities {
+		if priority == p {
+			return true
+		}
+	}
+	return false
+}
+
+func matchTags(queryTags []string, taskTags []string) bool {
+	if len(queryTags) == 0 {
+		return true
+	}
+	for _, tag := range queryTags {
+		found := false
+		for _, taskTag := range taskTags {
+			if strings.EqualFold(strings.TrimSpace(tag), strings.TrimSpace(taskTag)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func matchDueDate(dueBefore, dueAfter, dueDate *time.Time) bool {
+	if dueBefore != nil && dueDate != nil && dueDate.After(*dueBefore) {
+		return false
+	}
+	if dueAfter != nil && dueDate != nil && dueDate.Before(*dueAfter) {
+		return false
+	}
+	return true
+}
+
+func compareTaskByOrder(a, b model.Task, orderBy string) int {
+	switch orderBy {
+	case "due_date":
+		return compareTimePointers(a.DueDate, b.DueDate)
+	case "priority":
+		return int(a.Priority) - int(b.Priority)
+	case "created_at":
+		return compareTimes(a.CreatedAt, b.CreatedAt)
+	default:
+		return compareTimes(a.UpdatedAt, b.UpdatedAt)
+	}
+}
+
+func compareTimePointers(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	default:
+		return compareTimes(*a, *b)
+	}
+}
+
+func compareTimes(a, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	}
+	if a.After(b) {
+		return 1
+	}
+	return 0
+}
+
+func containsStringCI(values []string, target string) bool {
+	target = strings.TrimSpace(target)
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,386 @@
+// Package sqlitestore 提供基于 SQLite 的存储实现
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/storage"
+	"github.com/yeisme/taskbridge/internal/storage/filestore"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+
+	// 纯 Go 实现的 SQLite 驱动，无需 cgo
+	_ "modernc.org/sqlite"
+)
+
+// DBFileName storage.path 下的数据库文件名
+const DBFileName = "tasks.db"
+
+// sqliteSchema 任务与列表以 JSON 保存在 data 列（启用静态加密时加密），
+// source、list_id 保留明文用于按来源与列表筛选
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tasks (
+	id         TEXT PRIMARY KEY,
+	source     TEXT NOT NULL DEFAULT '',
+	list_id    TEXT NOT NULL DEFAULT '',
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS tasks_source_list ON tasks (source, list_id);
+CREATE TABLE IF NOT EXISTS task_lists (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sync_times (
+	source    TEXT PRIMARY KEY,
+	synced_at TEXT NOT NULL
+);
+`
+
+func init() {
+	_ = storage.Register(storage.BackendSQLite, func(opts storage.Options) (storage.Storage, error) {
+		return New(opts.Path)
+	})
+}
+
+// SQLiteStorage SQLite 存储实现。每次读写直接访问数据库，
+// 因此 mcp start、sync start 与命令行可以同时使用同一个数据目录
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+// New 打开 basePath/tasks.db；数据库为空且存在 file 后端的 tasks.json 等文件时导入其中的数据
+func New(basePath string) (*SQLiteStorage, error) {
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	dsn := "file:" + filepath.Join(basePath, DBFileName) + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage db: %w", err)
+	}
+	// SQLite 同一时间只允许一个写入者，单连接避免进程内的 SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to init storage db: %w", err)
+	}
+
+	store := &SQLiteStorage{db: db}
+	if err := store.importFileStorage(basePath); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// importFileStorage 从 file 后端迁移：数据库中没有任何任务与列表时导入 tasks.json、lists.json 与 sync.json
+func (s *SQLiteStorage) importFileStorage(basePath string) error {
+	var count int
+	if err := s.db.QueryRow(`SELECT (SELECT COUNT(*) FROM tasks) + (SELECT COUNT(*) FROM task_lists)`).Scan(&count); err != nil {
+		return fmt.Errorf("failed to read storage db: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+	snapshot, err := filestore.ReadSnapshot(basePath)
+	if err != nil || snapshot == nil {
+		return err
+	}
+
+	ctx := context.Background()
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for i := range snapshot.Tasks {
+			if err := putTask(ctx, tx, &snapshot.Tasks[i]); err != nil {
+				return err
+			}
+		}
+		for i := range snapshot.Lists {
+			if err := putTaskList(ctx, tx, &snapshot.Lists[i]); err != nil {
+				return err
+			}
+		}
+		for source, t := range snapshot.SyncTimes {
+			if err := putSyncTime(ctx, tx, source, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close 关闭数据库
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// execer 事务与数据库共有的写入方法
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (s *SQLiteStorage) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin storage transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit storage transaction: %w", err)
+	}
+	return nil
+}
+
+// sealJSON 序列化并在启用静态加密时加密
+func sealJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return storecrypt.SealString(string(data))
+}
+
+// openJSON 解密并反序列化 sealJSON 的结果
+func openJSON(value string, v any) error {
+	plain, err := storecrypt.OpenString(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(plain), v)
+}
+
+func putTask(ctx context.Context, db execer, task *model.Task) error {
+	data, err := sealJSON(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO tasks (id, source, list_id, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET source = excluded.source, list_id = excluded.list_id, data = excluded.data`,
+		task.ID, string(task.Source), task.ListID, data,
+	); err != nil {
+		return fmt.Errorf("failed to save task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+func putTaskList(ctx context.Context, db execer, list *model.TaskList) error {
+	data, err := sealJSON(list)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task list: %w", err)
+	}
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO task_lists (id, data) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET data = excluded.data`,
+		list.ID, data,
+	); err != nil {
+		return fmt.Errorf("failed to save task list %s: %w", list.ID, err)
+	}
+	return nil
+}
+
+func putSyncTime(ctx context.Context, db execer, source model.TaskSource, t time.Time) error {
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO sync_times (source, synced_at) VALUES (?, ?) ON CONFLICT (source) DO UPDATE SET synced_at = excluded.synced_at`,
+		string(source), t.Format(time.RFC3339),
+	); err != nil {
+		return fmt.Errorf("failed to save sync time: %w", err)
+	}
+	return nil
+}
+
+// queryTasks 读取满足 SQL 条件的任务
+func (s *SQLiteStorage) queryTasks(ctx context.Context, where string, args ...any) ([]model.Task, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM tasks`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []model.Task
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to query tasks: %w", err)
+		}
+		var task model.Task
+		if err := openJSON(data, &task); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal task: %w", err)
+		}
+		result = append(result, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %w", err)
+	}
+	return result, nil
+}
+
+// SaveTask 保存任务
+func (s *SQLiteStorage) SaveTask(ctx context.Context, task *model.Task) error {
+	task.UpdatedAt = time.Now()
+	return putTask(ctx, s.db, task)
+}
+
+// GetTask 获取任务
+func (s *SQLiteStorage) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	tasks, err := s.queryTasks(ctx, ` WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("task not found: %s", id)
+	}
+	return &tasks[0], nil
+}
+
+// ListTasks 列出任务
+func (s *SQLiteStorage) ListTasks(ctx context.Context, opts storage.ListOptions) ([]model.Task, error) {
+	return s.queryTasks(ctx, ` WHERE (? = '' OR source = ?) AND (? = '' OR list_id = ?)`,
+		string(opts.Source), string(opts.Source), opts.ListID, opts.ListID)
+}
+
+// DeleteTask 删除任务
+func (s *SQLiteStorage) DeleteTask(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete task %s: %w", id, err)
+	}
+	return nil
+}
+
+// SaveTasks 批量保存任务，在同一事务中写入
+func (s *SQLiteStorage) SaveTasks(ctx context.Context, tasks []*model.Task) error {
+	now := time.Now()
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, task := range tasks {
+			task.UpdatedAt = now
+			if err := putTask(ctx, tx, task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// QueryTasks 查询任务
+func (s *SQLiteStorage) QueryTasks(ctx context.Context, query storage.Query) ([]model.Task, error) {
+	tasks, err := s.queryTasks(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []model.Task
+	for i := range tasks {
+		if storage.MatchQuery(&tasks[i], query) {
+			result = append(result, tasks[i])
+		}
+	}
+	return storage.SortAndPage(result, query), nil
+}
+
+// SaveTaskList 保存任务列表
+func (s *SQLiteStorage) SaveTaskList(ctx context.Context, list *model.TaskList) error {
+	list.UpdatedAt = time.Now()
+	return putTaskList(ctx, s.db, list)
+}
+
+// GetTaskList 获取任务列表
+func (s *SQLiteStorage) GetTaskList(ctx context.Context, id string) (*model.TaskList, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM task_lists WHERE id = ?`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("task list not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task list: %w", err)
+	}
+	var list model.TaskList
+	if err := openJSON(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task list: %w", err)
+	}
+	return &list, nil
+}
+
+// ListTaskLists 列出任务列表
+func (s *SQLiteStorage) ListTaskLists(ctx context.Context) ([]model.TaskList, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM task_lists`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task lists: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []model.TaskList
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to query task lists: %w", err)
+		}
+		var list model.TaskList
+		if err := openJSON(data, &list); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal task list: %w", err)
+		}
+		result = append(result, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query task lists: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteTaskList 删除任务列表
+func (s *SQLiteStorage) DeleteTaskList(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM task_lists WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete task list %s: %w", id, err)
+	}
+	return nil
+}
+
+// ExportToJSON 导出为 JSON
+func (s *SQLiteStorage) ExportToJSON(ctx context.Context, opts storage.ExportOptions) ([]byte, error) {
+	tasks, err := s.QueryTasks(ctx, opts.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Pretty {
+		return json.MarshalIndent(tasks, "", "  ")
+	}
+	return json.Marshal(tasks)
+}
+
+// ExportToMarkdown 导出为 Markdown
+func (s *SQLiteStorage) ExportToMarkdown(ctx context.Context, opts storage.ExportOptions) ([]byte, error) {
+	tasks, err := s.QueryTasks(ctx, opts.Query)
+	if err != nil {
+		return nil, err
+	}
+	return storage.RenderMarkdown(tasks), nil
+}
+
+// GetLastSyncTime 获取上次同步时间
+func (s *SQLiteStorage) GetLastSyncTime(ctx context.Context, source model.TaskSource) (*time.Time, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, `SELECT synced_at FROM sync_times WHERE source = ?`, string(source)).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync time: %w", err)
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+// SetLastSyncTime 设置上次同步时间
+func (s *SQLiteStorage) SetLastSyncTime(ctx context.Context, source model.TaskSource, t time.Time) error {
+	return putSyncTime(ctx, s.db, source, t)
+}
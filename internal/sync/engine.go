@@ -183,6 +183,10 @@ func (e *Engine) pull(ctx context.Context, p provider.Provider, result *Result,
 
 	// 从每个列表拉取任务
 	for _, list := range taskLists {
+		// 请求被取消时立即停止，且不能进入幽灵任务清理（未拉取的列表会被误判为远程已删除）
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		// 从远程获取任务
 		tasks, err := p.ListTasks(ctx, list.ID, provider.ListOptions{})
 		if err != nil {
@@ -254,6 +258,10 @@ func (e *Engine) pull(ctx context.Context, p provider.Provider, result *Result,
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	// 清理幽灵任务：删除本地存在但远程不存在的任务
 	if !opts.DryRun {
 		ghostCount := 0
@@ -426,10 +434,16 @@ func (e *Engine) push(ctx context.Context, p provider.Provider, result *Result,
 	// 推送本地任务
 	source := model.TaskSource(opts.Provider)
 	e.pushLocalTasks(ctx, p, localTasks, defaultListID, source, opts, result)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	// 双向比对：删除远程存在但本地不存在的任务
 	if opts.DeleteRemote {
 		e.deleteRemoteTasks(ctx, p, taskLists, localSourceRawIDs, opts.DryRun, result)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 	}
 
 	log.Info().Int("pushed", result.Pushed).Int("updated", result.Updated).Int("deleted", result.Deleted).Msg("推送完成")
@@ -465,6 +479,9 @@ func (e *Engine) buildLocalSourceRawIDs(tasks []model.Task) map[string]bool {
 // pushLocalTasks 推送本地任务到远程
 func (e *Engine) pushLocalTasks(ctx context.Context, p provider.Provider, tasks []model.Task, defaultListID string, source model.TaskSource, opts Options, result *Result) {
 	for _, task := range tasks {
+		if ctx.Err() != nil {
+			return
+		}
 		// 跳过已经从该 Provider 同步的任务
 		if task.Source != "" && task.Source != source && task.Source != "local" {
 			continue
@@ -529,6 +546,9 @@ func (e *Engine) pushLocalTasks(ctx context.Context, p provider.Provider, tasks
 func (e *Engine) deleteRemoteTasks(ctx context.Context, p provider.Provider, taskLists []model.TaskList, localSourceRawIDs map[string]bool, dryRun bool, result *Result) {
 	log.Info().Msg("开始比对远程任务，查找需要删除的任务")
 	for _, list := range taskLists {
+		if ctx.Err() != nil {
+			return
+		}
 		// 获取远程任务
 		remoteTasks, err := p.ListTasks(ctx, list.ID, provider.ListOptions{})
 		if err != nil {
@@ -540,6 +560,9 @@ func (e *Engine) deleteRemoteTasks(ctx context.Context, p provider.Provider, tas
 		}
 
 		for _, remoteTask := range remoteTasks {
+			if ctx.Err() != nil {
+				return
+			}
 			// 检查远程任务是否在本地存在
 			if !localSourceRawIDs[remoteTask.SourceRawID] {
 				// 远程任务在本地不存在，需要删除
@@ -590,6 +613,9 @@ func (e *Engine) SyncAll(ctx context.Context, opts Options) (map[string]*Result,
 	results := make(map[string]*Result)
 
 	for name, p := range e.providers {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
 		if !p.IsAuthenticated() {
 			log.Warn().Str("provider", name).Msg("Provider 未认证，跳过")
 			continue
@@ -4,6 +4,7 @@ package sync
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -19,7 +20,9 @@ type MockProvider struct {
 	authenticated bool
 	taskLists     []model.TaskList
 	tasks         map[string][]model.Task // key: listID
-	mu            sync.Mutex
+	// getTaskErr 非 nil 时 GetTask 返回该错误（模拟限流、网络错误等）
+	getTaskErr error
+	mu         sync.Mutex
 }
 
 func (m *MockProvider) Name() string {
@@ -81,6 +84,9 @@ func (m *MockProvider) ListTasks(ctx context.Context, listID string, opts provid
 func (m *MockProvider) GetTask(ctx context.Context, listID, taskID string) (*model.Task, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.getTaskErr != nil {
+		return nil, m.getTaskErr
+	}
 	if tasks, ok := m.tasks[listID]; ok {
 		for _, task := range tasks {
 			if task.ID == taskID {
@@ -88,7 +94,7 @@ func (m *MockProvider) GetTask(ctx context.Context, listID, taskID string) (*mod
 			}
 		}
 	}
-	return nil, errors.New("task not found")
+	return nil, fmt.Errorf("%w: task %s", provider.ErrNotFound, taskID)
 }
 
 func (m *MockProvider) SearchTasks(ctx context.Context, query string) ([]model.Task, error) {
@@ -466,6 +472,72 @@ func TestSyncPush(t *testing.T) {
 	}
 }
 
+// cancellingProvider 在拉取列表任务时取消上下文，模拟客户端中途取消请求
+type cancellingProvider struct {
+	*MockProvider
+	cancel context.CancelFunc
+}
+
+func (c *cancellingProvider) ListTasks(ctx context.Context, listID string, opts provider.ListOptions) ([]model.Task, error) {
+	c.cancel()
+	return nil, ctx.Err()
+}
+
+// TestSyncPullCancelled 测试拉取被取消时立即返回且不清理“幽灵任务”
+func TestSyncPullCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := &cancellingProvider{
+		MockProvider: &MockProvider{
+			name:          "mock",
+			authenticated: true,
+			taskLists: []model.TaskList{
+				{ID: "list1", Name: "List 1", Source: "mock"},
+				{ID: "list2", Name: "List 2", Source: "mock"},
+			},
+		},
+		cancel: cancel,
+	}
+	store := NewMockStorage()
+	if err := store.SaveTask(context.Background(), &model.Task{ID: "task1", Title: "Task 1", Source: "mock", SourceRawID: "task1"}); err != nil {
+		t.Fatalf("failed to seed task: %v", err)
+	}
+
+	engine := NewEngine(map[string]provider.Provider{"mock": p}, store)
+	_, err := engine.Sync(ctx, Options{Direction: DirectionPull, Provider: "mock"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if task, _ := store.GetTask(context.Background(), "task1"); task == nil {
+		t.Fatalf("local task should not be deleted when pull is cancelled")
+	}
+}
+
+// TestSyncPushCancelled 测试推送在上下文取消后不再调用 Provider
+func TestSyncPushCancelled(t *testing.T) {
+	mockProvider := &MockProvider{
+		name:          "mock",
+		authenticated: true,
+		taskLists:     []model.TaskList{{ID: "list1", Name: "我的任务", Source: "mock"}},
+		tasks:         make(map[string][]model.Task),
+	}
+	store := NewMockStorage()
+	if err := store.SaveTask(context.Background(), &model.Task{ID: "local1", Title: "Local Task 1", Status: model.StatusTodo, Source: "local"}); err != nil {
+		t.Fatalf("failed to seed local task: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	engine := NewEngine(map[string]provider.Provider{"mock": mockProvider}, store)
+	result, err := engine.Sync(ctx, Options{Direction: DirectionPush, Provider: "mock"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if result.Pushed != 0 || len(mockProvider.tasks["list1"]) != 0 {
+		t.Fatalf("expected no remote writes after cancellation, got %+v", result)
+	}
+}
+
 // TestSyncBidirectional 测试双向同步
 func TestSyncBidirectional(t *testing.T) {
 	mockProvider := &MockProvider{
@@ -0,0 +1,821 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+)
+
+// MirrorOptions 两个 Provider 之间双向镜像的选项
+type MirrorOptions struct {
+	// Left、Right 互为镜像的两个 Provider 名称，顺序不影响镜像关系
+	Left  string
+	Right string
+	// DryRun 只统计将要执行的变更，不修改任一侧，也不保存镜像状态
+	DryRun bool
+	// Full 忽略保存的增量游标，全量读取两侧
+	Full bool
+	// ConflictResolve 两侧都修改了同一任务时的处理策略，见 ConflictNewer 等常量；
+	// 也可以填写 Left 或 Right 的 Provider 名称，表示以该侧为准
+	ConflictResolve string
+	// Filter 只镜像符合规则的任务，两侧按同一规则判断；为 nil 时不过滤
+	Filter *MirrorFilter
+	// FieldMap 两侧取值不同的字段映射，排在默认映射（DefaultMirrorFieldMap）之前；为 nil 时只使用默认映射
+	FieldMap *MirrorFieldMap
+	// Source 单向镜像的来源（Left 或 Right），变更只从来源写入另一侧，另一侧独有的任务不会复制到来源；
+	// 为空时双向镜像。单向镜像不使用 ConflictResolve
+	Source string
+	// MirrorEdits 单向镜像时镜像侧修改的处理方式：MirrorEditsOverwrite（默认）或 MirrorEditsFlag
+	MirrorEdits string
+	// OnDelete 一侧任务删除后另一侧镜像任务的处理方式：MirrorDeleteRemove（默认）或 MirrorDeleteArchive
+	OnDelete string
+	// Journal 记录本次应用的每一项变更，供 UndoMirrorRun 撤销；为 nil 时不记录
+	Journal MirrorJournal
+}
+
+// MirrorCounts 应用到某一侧的变更数
+type MirrorCounts struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Completed int `json:"completed"`
+	Deleted   int `json:"deleted"`
+}
+
+// MirrorResult 镜像同步结果
+type MirrorResult struct {
+	// RunID 本次镜像的运行 ID，变更日志按此记录
+	RunID string `json:"run_id"`
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	// Source 单向镜像的来源，双向镜像时为空
+	Source string `json:"source,omitempty"`
+	// ToLeft 应用到 Left 的变更，ToRight 应用到 Right 的变更
+	ToLeft  MirrorCounts `json:"to_left"`
+	ToRight MirrorCounts `json:"to_right"`
+	// Conflicts 两侧都修改过的任务数
+	Conflicts int `json:"conflicts"`
+	// PendingConflicts 按 manual 策略排队、等待人工处理的冲突数
+	PendingConflicts int `json:"pending_conflicts,omitempty"`
+	// Skipped 未建立镜像的已完成任务数
+	Skipped int `json:"skipped"`
+	// Overwritten 单向镜像时被来源覆盖（含重新创建）的镜像侧修改数
+	Overwritten int `json:"overwritten,omitempty"`
+	// Filtered 不符合过滤规则、未镜像的任务数
+	Filtered int `json:"filtered,omitempty"`
+	// Ghosts 删除后再次出现、按墓碑忽略的任务数
+	Ghosts int `json:"ghosts,omitempty"`
+	// Incremental 本次按增量游标只读取了变更的一侧
+	Incremental  []string      `json:"incremental,omitempty"`
+	Errors       []Error       `json:"errors,omitempty"`
+	Duration     time.Duration `json:"duration"`
+	LastSyncTime time.Time     `json:"last_sync_time"`
+}
+
+// mirrorSide 镜像一侧在本次同步开始时的快照
+type mirrorSide struct {
+	name   string
+	p      provider.Provider
+	counts *MirrorCounts
+	// lists 按 ID 索引的任务列表，listsByName 为小写名称到 ID 的映射
+	lists       map[string]model.TaskList
+	listsByName map[string]string
+	// tasks 按远端原始 ID 索引的任务
+	tasks map[string]*model.Task
+	// failedLists 拉取失败的列表，其中的任务缺失时不能判定为已删除
+	failedLists map[string]bool
+	// delta 为 true 时 tasks 只包含自游标以来变更的任务，deleted 为其间删除的任务
+	delta   bool
+	deleted map[string]bool
+	// cursor 下次增量读取使用的游标，为空表示不支持或本次未能取得
+	cursor string
+}
+
+// Mirror 在两个 Provider 之间双向镜像任务：检测每一侧自上次同步以来的新建、修改、完成与删除并应用到另一侧。
+// 镜像关系与上次同步时的内容指纹保存在 store 中，据此区分“一侧修改”与“两侧都修改”（冲突）；
+// opts.Source 非空时为单向镜像，见 MirrorEditsOverwrite、MirrorEditsFlag。
+// 每次镜像（DryRun 除外）无论成功与否都记录为 MirrorState.LastRun。
+func (e *Engine) Mirror(ctx context.Context, store MirrorStore, opts MirrorOptions) (result *MirrorResult, err error) {
+	startTime := time.Now()
+	if opts.Left == "" || opts.Right == "" || opts.Left == opts.Right {
+		return nil, fmt.Errorf("mirror requires two different providers")
+	}
+	var previous *MirrorRun
+	if !opts.DryRun {
+		defer func() {
+			recordMirrorRun(ctx, store, opts.Left, opts.Right, startTime, previous, result, err)
+		}()
+	}
+	strategy, err := NormalizeConflictStrategy(opts.ConflictResolve, opts.Left, opts.Right)
+	if err != nil {
+		return nil, err
+	}
+	opts.ConflictResolve = strategy
+	if err := normalizeOneWay(&opts); err != nil {
+		return nil, err
+	}
+	if err := normalizeOnDelete(&opts); err != nil {
+		return nil, err
+	}
+	fields, err := newMirrorFieldMapper(opts.FieldMap, opts.Left, opts.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &MirrorResult{RunID: newMirrorRunID(startTime), Left: opts.Left, Right: opts.Right, Source: opts.Source, LastSyncTime: startTime}
+	state, err := store.LoadMirrorState(ctx, opts.Left, opts.Right)
+	if err != nil {
+		return nil, fmt.Errorf("load mirror state: %w", err)
+	}
+	previous = state.LastRun
+	cursors := state.Cursors
+	if opts.Full || len(state.Links) == 0 {
+		// 没有镜像关系时需要两侧的全部任务来初次配对
+		cursors = nil
+	}
+	left, err := e.mirrorSide(ctx, opts.Left, cursors[opts.Left], &result.ToLeft, result)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.mirrorSide(ctx, opts.Right, cursors[opts.Right], &result.ToRight, result)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("left", opts.Left).Str("right", opts.Right).Str("source", opts.Source).Int("links", len(state.Links)).Msg("开始镜像")
+	m := &mirrorRun{engine: e, left: left, right: right, opts: opts, fields: fields, result: result, queued: conflictsByID(state.Conflicts)}
+	m.loadTombstones(state.Tombstones)
+	links := m.syncLinks(ctx, state.Links)
+	m.skipGhosts()
+	links = append(links, m.linkUnmatched(ctx)...)
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	if !opts.DryRun {
+		state.Links = links
+		state.Conflicts = m.pending
+		state.Tombstones = m.keptTombstones()
+		state.LastSyncTime = startTime
+		if len(result.Errors) == 0 {
+			// 有失败的变更时保留旧游标，下次重新读取这些变更
+			state.Cursors = nextCursors(left, right)
+		}
+		if err := store.SaveMirrorState(ctx, opts.Left, opts.Right, state); err != nil {
+			return result, fmt.Errorf("save mirror state: %w", err)
+		}
+	}
+	result.Duration = time.Since(startTime)
+	log.Info().
+		Interface("to_left", result.ToLeft).
+		Interface("to_right", result.ToRight).
+		Int("conflicts", result.Conflicts).
+		Msg("镜像完成")
+	return result, nil
+}
+
+// mirrorSide 拉取一侧的全部任务列表与任务；Provider 支持增量读取且 cursor 非空时只读取变更的任务
+func (e *Engine) mirrorSide(ctx context.Context, name, cursor string, counts *MirrorCounts, result *MirrorResult) (*mirrorSide, error) {
+	p, ok := e.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("provider %s not found", name)
+	}
+	if !p.IsAuthenticated() {
+		return nil, fmt.Errorf("provider %s is not authenticated", name)
+	}
+	lists, err := p.ListTaskLists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list %s task lists: %w", name, err)
+	}
+
+	side := &mirrorSide{
+		name:        name,
+		p:           p,
+		counts:      counts,
+		lists:       make(map[string]model.TaskList, len(lists)),
+		listsByName: make(map[string]string, len(lists)),
+		tasks:       make(map[string]*model.Task),
+		failedLists: make(map[string]bool),
+		deleted:     make(map[string]bool),
+	}
+	for _, list := range lists {
+		side.addList(list)
+	}
+	if syncer, ok := p.(provider.DeltaSyncer); ok {
+		changes, err := syncer.ListTaskDelta(ctx, cursor)
+		if err == nil {
+			side.applyDelta(changes)
+			if side.delta {
+				result.Incremental = append(result.Incremental, name)
+			}
+			return side, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Warn().Err(err).Str("provider", name).Msg("增量读取失败，改为全量读取")
+	}
+
+	for _, list := range lists {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		tasks, err := p.ListTasks(ctx, list.ID, provider.ListOptions{})
+		if err != nil {
+			side.failedLists[list.ID] = true
+			result.Errors = append(result.Errors, Error{
+				Operation: "list_tasks",
+				Error:     fmt.Sprintf("获取 %s 列表 %s 的任务失败: %v", name, list.Name, err),
+			})
+			continue
+		}
+		for i := range tasks {
+			task := tasks[i]
+			if task.ListID == "" {
+				task.ListID = list.ID
+			}
+			if task.ListName == "" {
+				task.ListName = list.Name
+			}
+			side.tasks[rawTaskID(&task)] = &task
+		}
+	}
+	return side, nil
+}
+
+// applyDelta 以增量读取的结果作为该侧的任务快照
+func (s *mirrorSide) applyDelta(changes *provider.SyncChanges) {
+	s.delta = !changes.Full
+	s.cursor = changes.NextToken
+	for i := range changes.Tasks {
+		task := changes.Tasks[i]
+		if list, ok := s.lists[task.ListID]; ok && task.ListName == "" {
+			task.ListName = list.Name
+		}
+		s.tasks[rawTaskID(&task)] = &task
+	}
+	for _, id := range changes.DeletedIDs {
+		s.deleted[id] = true
+	}
+}
+
+// unchanged 确定任务自上次同步后未修改时返回 true：增量读取时不在变更中，全量读取时指纹未变
+func (s *mirrorSide) unchanged(ref MirrorRef) bool {
+	if s.delta {
+		_, changed := s.tasks[ref.TaskID]
+		return !changed && !s.deleted[ref.TaskID]
+	}
+	task, ok := s.tasks[ref.TaskID]
+	return ok && taskFingerprint(task) == ref.Hash
+}
+
+// nextCursors 两侧下次增量读取使用的游标
+func nextCursors(sides ...*mirrorSide) map[string]string {
+	var cursors map[string]string
+	for _, side := range sides {
+		if side.cursor == "" {
+			continue
+		}
+		if cursors == nil {
+			cursors = make(map[string]string)
+		}
+		cursors[side.name] = side.cursor
+	}
+	return cursors
+}
+
+func (s *mirrorSide) addList(list model.TaskList) {
+	s.lists[list.ID] = list
+	if key := strings.ToLower(strings.TrimSpace(list.Name)); key != "" {
+		if _, exists := s.listsByName[key]; !exists {
+			s.listsByName[key] = list.ID
+		}
+	}
+}
+
+// mirrorRun 一次镜像同步的执行状态
+type mirrorRun struct {
+	engine *Engine
+	left   *mirrorSide
+	right  *mirrorSide
+	opts   MirrorOptions
+	fields *mirrorFieldMapper
+	result *MirrorResult
+	// linked 已处于镜像关系中的任务，键为 Provider 名称 + 原始 ID
+	linked map[string]bool
+	// queued 上次同步时排队的冲突，pending 本次同步后仍待人工处理的冲突
+	queued  map[string]MirrorConflict
+	pending []MirrorConflict
+	// tombstones 墓碑，buried 为其中的任务（Provider 名称 + 原始 ID）到 tombstones 下标的索引
+	tombstones []MirrorTombstone
+	buried     map[string]int
+}
+
+func (m *mirrorRun) markLinked(side *mirrorSide, taskID string) {
+	if m.linked == nil {
+		m.linked = make(map[string]bool)
+	}
+	m.linked[side.name+"\x00"+taskID] = true
+}
+
+func (m *mirrorRun) isLinked(side *mirrorSide, taskID string) bool {
+	return m.linked[side.name+"\x00"+taskID]
+}
+
+// syncLinks 处理已有的镜像关系，返回同步后仍然有效的关系
+func (m *mirrorRun) syncLinks(ctx context.Context, links []MirrorLink) []MirrorLink {
+	kept := make([]MirrorLink, 0, len(links))
+	for _, link := range links {
+		if ctx.Err() != nil {
+			// 未处理的关系与其排队的冲突原样保留，下次继续
+			kept = append(kept, link)
+			m.keepQueued(link)
+			continue
+		}
+		leftRef, okLeft := link[m.left.name]
+		rightRef, okRight := link[m.right.name]
+		if !okLeft || !okRight {
+			continue
+		}
+		if _, queued := m.queued[mirrorConflictID(link)]; !queued && m.left.unchanged(leftRef) && m.right.unchanged(rightRef) {
+			leftTask, rightTask := m.left.tasks[leftRef.TaskID], m.right.tasks[rightRef.TaskID]
+			if leftTask != nil && rightTask != nil && !m.linkInScope(leftTask, rightTask) {
+				// 如已完成超过保留期限：两侧都不再符合规则，停止跟踪
+				continue
+			}
+			m.markLinked(m.left, leftRef.TaskID)
+			m.markLinked(m.right, rightRef.TaskID)
+			kept = append(kept, link)
+			continue
+		}
+		leftTask, leftKnown := m.lookup(ctx, m.left, leftRef)
+		rightTask, rightKnown := m.lookup(ctx, m.right, rightRef)
+		if !leftKnown || !rightKnown {
+			// 列表拉取失败时无法判断任务是否被删除，保持原状
+			m.markLinked(m.left, leftRef.TaskID)
+			m.markLinked(m.right, rightRef.TaskID)
+			kept = append(kept, link)
+			continue
+		}
+
+		if !m.linkInScope(leftTask, rightTask) {
+			// 两侧都已删除，或都不再符合过滤规则
+			if leftTask == nil && rightTask == nil {
+				m.bury(link, nil, false)
+			}
+			continue
+		}
+
+		switch {
+		case leftTask == nil:
+			if next, ok := m.propagateDelete(ctx, link, m.right, rightTask, m.left); ok {
+				kept = append(kept, next)
+			}
+		case rightTask == nil:
+			if next, ok := m.propagateDelete(ctx, link, m.left, leftTask, m.right); ok {
+				kept = append(kept, next)
+			}
+		default:
+			m.markLinked(m.left, leftRef.TaskID)
+			m.markLinked(m.right, rightRef.TaskID)
+			kept = append(kept, m.reconcile(ctx, link, leftTask, rightTask, true))
+		}
+	}
+	return kept
+}
+
+// lookup 返回镜像关系中一侧的当前任务；known 为 false 表示无法确定任务是否存在。
+// 列表中找不到时再按 ID 获取一次：部分 Provider 的列表接口不返回已完成任务，完成不应被当作删除；
+// 只有 Provider 明确答复不存在（provider.ErrNotFound）时才认定任务已被删除。
+func (m *mirrorRun) lookup(ctx context.Context, side *mirrorSide, ref MirrorRef) (task *model.Task, known bool) {
+	if task, ok := side.tasks[ref.TaskID]; ok {
+		return task, true
+	}
+	if side.deleted[ref.TaskID] {
+		return nil, true
+	}
+	if side.failedLists[ref.ListID] {
+		return nil, false
+	}
+	if _, ok := side.lists[ref.ListID]; !ok {
+		// 所在列表已被删除
+		return nil, true
+	}
+	task, err := side.p.GetTask(ctx, ref.ListID, ref.TaskID)
+	if errors.Is(err, provider.ErrNotFound) {
+		return nil, true
+	}
+	if err != nil || task == nil {
+		// 限流、服务端或网络错误：无法判断任务是否被删除
+		return nil, false
+	}
+	if task.ListID == "" {
+		task.ListID = ref.ListID
+	}
+	side.tasks[ref.TaskID] = task
+	return task, true
+}
+
+// propagateDelete 一侧任务已删除：另一侧自上次同步后未修改时一并删除；已修改时以修改为准，在删除侧重新创建
+func (m *mirrorRun) propagateDelete(ctx context.Context, link MirrorLink, survivor *mirrorSide, task *model.Task, deleted *mirrorSide) (MirrorLink, bool) {
+	if m.oneWay() {
+		return m.propagateDeleteOneWay(ctx, link, survivor, task, deleted)
+	}
+	ref := link[survivor.name]
+	m.markLinked(survivor, ref.TaskID)
+	if taskFingerprint(task) != ref.Hash {
+		log.Info().Str("task", task.Title).Str("provider", deleted.name).Msg("任务在一侧删除但另一侧已修改，重新创建")
+		m.bury(MirrorLink{deleted.name: link[deleted.name]}, deleted, false)
+		return m.create(ctx, survivor, task, deleted)
+	}
+	return m.deleteMirrored(ctx, link, survivor, task, deleted)
+}
+
+// deleteMirrored 删除（OnDelete 为 archive 时归档）survivor 侧的镜像任务并记录墓碑；删除失败时保留关系
+func (m *mirrorRun) deleteMirrored(ctx context.Context, link MirrorLink, survivor *mirrorSide, task *model.Task, deleted *mirrorSide) (MirrorLink, bool) {
+	if m.opts.OnDelete == MirrorDeleteArchive {
+		return m.archiveMirrored(ctx, link, survivor, task, deleted)
+	}
+	ref := link[survivor.name]
+	if m.opts.DryRun {
+		log.Info().Str("task", task.Title).Str("provider", survivor.name).Msg("[DryRun] 将删除镜像任务")
+		survivor.counts.Deleted++
+		return nil, false
+	}
+	if err := survivor.p.DeleteTask(ctx, ref.ListID, ref.TaskID); err != nil {
+		m.addError(task.ID, "delete_task", fmt.Sprintf("删除 %s 任务失败: %v", survivor.name, err))
+		// 删除失败时保留关系，下次重试
+		return link, true
+	}
+	survivor.counts.Deleted++
+	m.record(ctx, survivor, MirrorOpDelete, ref.ListID, task, nil)
+	m.bury(link, deleted, false)
+	return nil, false
+}
+
+// reconcile 两侧任务都存在：按各自的指纹判断哪一侧在上次同步后被修改；
+// 两侧都修改时按冲突策略选择，countConflict 为 false 用于初次配对（没有上次同步的指纹，不算冲突）
+func (m *mirrorRun) reconcile(ctx context.Context, link MirrorLink, leftTask, rightTask *model.Task, countConflict bool) MirrorLink {
+	leftRef, rightRef := link[m.left.name], link[m.right.name]
+	leftHash, rightHash := taskFingerprint(leftTask), taskFingerprint(rightTask)
+	leftChanged, rightChanged := leftHash != leftRef.Hash, rightHash != rightRef.Hash
+	if !leftChanged && !rightChanged {
+		return link
+	}
+	if m.sameContent(leftTask, rightTask) {
+		// 内容已一致（如两侧做了相同修改，或 Provider 规范化了字段），只更新指纹
+		return m.link(leftTask, rightTask)
+	}
+	if m.oneWay() {
+		return m.reconcileOneWay(ctx, link, leftTask, rightTask, countConflict)
+	}
+
+	source, target := m.left, m.right
+	if leftChanged && rightChanged {
+		preferRight := m.preferRight(leftTask, rightTask)
+		if countConflict {
+			m.result.Conflicts++
+			switch m.opts.ConflictResolve {
+			case ConflictManual:
+				keep, fields, ok := m.manualResolution(link, leftTask, rightTask)
+				if !ok {
+					return link
+				}
+				if len(fields) > 0 {
+					return m.applyChoices(ctx, link, leftTask, rightTask, keep, fields)
+				}
+				preferRight = keep == m.right
+			case ConflictMerge:
+				if merged, ok := m.merge(ctx, link, leftTask, rightTask); ok {
+					return merged
+				}
+			}
+		}
+		if preferRight {
+			source, target = m.right, m.left
+		}
+	} else if rightChanged {
+		source, target = m.right, m.left
+	}
+
+	sourceTask, targetTask := leftTask, rightTask
+	if source == m.right {
+		sourceTask, targetTask = rightTask, leftTask
+	}
+	updated, ok := m.update(ctx, source, sourceTask, target, targetTask)
+	if !ok {
+		return link
+	}
+	if source == m.left {
+		return m.link(sourceTask, updated)
+	}
+	return m.link(updated, sourceTask)
+}
+
+// preferRight 冲突时是否以 Right 为准；merge、manual 无法处理时按更新时间
+func (m *mirrorRun) preferRight(leftTask, rightTask *model.Task) bool {
+	switch m.opts.ConflictResolve {
+	case ConflictLeft:
+		return false
+	case ConflictRight:
+		return true
+	default:
+		return rightTask.UpdatedAt.After(leftTask.UpdatedAt)
+	}
+}
+
+// linkUnmatched 为尚未建立镜像关系的任务建立关系：两侧同一列表下标题相同的任务直接配对，其余在另一侧创建
+func (m *mirrorRun) linkUnmatched(ctx context.Context) []MirrorLink {
+	var links []MirrorLink
+	rightByTitle := make(map[string]*model.Task)
+	for _, id := range sortedTaskIDs(m.right) {
+		task := m.right.tasks[id]
+		if m.isLinked(m.right, id) {
+			continue
+		}
+		key := m.matchKey(m.right, task)
+		if _, exists := rightByTitle[key]; !exists {
+			rightByTitle[key] = task
+		}
+	}
+
+	for _, id := range sortedTaskIDs(m.left) {
+		if ctx.Err() != nil {
+			return links
+		}
+		task := m.left.tasks[id]
+		if m.isLinked(m.left, id) {
+			continue
+		}
+		key := m.matchKey(m.left, task)
+		if match, ok := rightByTitle[key]; ok && (m.inScope(m.left, task) || m.inScope(m.right, match)) {
+			delete(rightByTitle, key)
+			m.markLinked(m.left, id)
+			m.markLinked(m.right, rawTaskID(match))
+			links = append(links, m.reconcile(ctx, MirrorLink{
+				m.left.name:  MirrorRef{ListID: task.ListID, TaskID: id},
+				m.right.name: MirrorRef{ListID: match.ListID, TaskID: rawTaskID(match)},
+			}, task, match, false))
+			continue
+		}
+		if !m.canWrite(m.right) {
+			continue
+		}
+		if !m.inScope(m.left, task) {
+			m.result.Filtered++
+			continue
+		}
+		if link, ok := m.createUnlinked(ctx, m.left, task, m.right); ok {
+			links = append(links, link)
+		}
+	}
+
+	if !m.canWrite(m.left) {
+		// 单向镜像时镜像侧独有的任务不复制到来源
+		return links
+	}
+	for _, id := range sortedTaskIDs(m.right) {
+		if ctx.Err() != nil {
+			return links
+		}
+		task := m.right.tasks[id]
+		if m.isLinked(m.right, id) {
+			continue
+		}
+		if !m.inScope(m.right, task) {
+			m.result.Filtered++
+			continue
+		}
+		if link, ok := m.createUnlinked(ctx, m.right, task, m.left); ok {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// createUnlinked 在另一侧创建尚未镜像的任务；建立镜像前已完成的任务不再复制
+func (m *mirrorRun) createUnlinked(ctx context.Context, source *mirrorSide, task *model.Task, target *mirrorSide) (MirrorLink, bool) {
+	if task.Status == model.StatusCompleted {
+		m.result.Skipped++
+		return nil, false
+	}
+	return m.create(ctx, source, task, target)
+}
+
+// create 在 target 中同名列表（不存在时创建）下新建 task 的副本并返回新的镜像关系
+func (m *mirrorRun) create(ctx context.Context, source *mirrorSide, task *model.Task, target *mirrorSide) (MirrorLink, bool) {
+	m.markLinked(source, rawTaskID(task))
+	if m.opts.DryRun {
+		log.Info().Str("task", task.Title).Str("provider", target.name).Msg("[DryRun] 将创建镜像任务")
+		target.counts.Created++
+		return nil, false
+	}
+
+	listID, err := m.targetList(ctx, source, task, target)
+	if err != nil {
+		m.addError(task.ID, "create_task", err.Error())
+		return nil, false
+	}
+	copied := m.toSide(source, task, target, nil)
+	copied.ListID = listID
+	copied.ListName = target.lists[listID].Name
+	created, err := target.p.CreateTask(ctx, listID, copied)
+	if err != nil {
+		m.addError(task.ID, "create_task", fmt.Sprintf("在 %s 创建任务失败: %v", target.name, err))
+		return nil, false
+	}
+	if created == nil {
+		created = copied
+	}
+	if created.ListID == "" {
+		created.ListID = listID
+	}
+	target.counts.Created++
+	m.record(ctx, target, MirrorOpCreate, listID, nil, created)
+	m.markLinked(target, rawTaskID(created))
+	if source == m.left {
+		return m.link(task, created), true
+	}
+	return m.link(created, task), true
+}
+
+// targetList 返回 target 中与 task 所在列表同名的列表，不存在时创建；创建失败则使用默认列表
+func (m *mirrorRun) targetList(ctx context.Context, source *mirrorSide, task *model.Task, target *mirrorSide) (string, error) {
+	name := m.listNameOn(source, task, target)
+	if id, ok := target.listsByName[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return id, nil
+	}
+	if name != "" {
+		list, err := target.p.CreateTaskList(ctx, name)
+		if err == nil && list != nil {
+			target.addList(*list)
+			return list.ID, nil
+		}
+		log.Warn().Err(err).Str("provider", target.name).Str("list", name).Msg("创建镜像列表失败，使用默认列表")
+	}
+	lists := make([]model.TaskList, 0, len(target.lists))
+	for _, list := range target.lists {
+		lists = append(lists, list)
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].ID < lists[j].ID })
+	if id := m.engine.findDefaultListID(lists); id != "" {
+		return id, nil
+	}
+	return "", fmt.Errorf("%s 没有可用的任务列表", target.name)
+}
+
+// update 将 from 侧任务 source 的内容写入 target 侧的任务，返回写入后的任务
+func (m *mirrorRun) update(ctx context.Context, from *mirrorSide, source *model.Task, target *mirrorSide, existing *model.Task) (*model.Task, bool) {
+	updated := m.toSide(from, source, target, existing)
+	completed := updated.Status == model.StatusCompleted && existing.Status != model.StatusCompleted
+	if m.opts.DryRun {
+		log.Info().Str("task", source.Title).Str("provider", target.name).Msg("[DryRun] 将更新镜像任务")
+		countUpdate(target.counts, completed)
+		return existing, false
+	}
+
+	saved, err := target.p.UpdateTask(ctx, existing.ListID, updated)
+	if err != nil {
+		m.addError(existing.ID, "update_task", fmt.Sprintf("更新 %s 任务失败: %v", target.name, err))
+		return nil, false
+	}
+	if saved == nil {
+		saved = updated
+	}
+	if saved.ListID == "" {
+		saved.ListID = existing.ListID
+	}
+	countUpdate(target.counts, completed)
+	op := MirrorOpUpdate
+	if completed {
+		op = MirrorOpComplete
+	}
+	m.record(ctx, target, op, existing.ListID, existing, saved)
+	return saved, true
+}
+
+func countUpdate(counts *MirrorCounts, completed bool) {
+	if completed {
+		counts.Completed++
+		return
+	}
+	counts.Updated++
+}
+
+// link 以两侧任务的当前内容建立镜像关系
+func (m *mirrorRun) link(leftTask, rightTask *model.Task) MirrorLink {
+	return MirrorLink{
+		m.left.name:  mirrorRef(leftTask, m.result.LastSyncTime),
+		m.right.name: mirrorRef(rightTask, m.result.LastSyncTime),
+	}
+}
+
+func mirrorRef(task *model.Task, syncedAt time.Time) MirrorRef {
+	fields := fieldsOf(task)
+	return MirrorRef{ListID: task.ListID, TaskID: rawTaskID(task), Hash: taskFingerprint(task), Fields: &fields, SyncedAt: syncedAt}
+}
+
+func (m *mirrorRun) addError(taskID, operation, message string) {
+	m.result.Errors = append(m.result.Errors, Error{TaskID: taskID, Operation: operation, Error: message})
+}
+
+// mirroredCopy 将 source 中参与镜像的字段写入 target 的副本，target 的 ID、列表等 Provider 字段保持不变
+func mirroredCopy(source, target *model.Task) *model.Task {
+	copied := *target
+	copied.Title = source.Title
+	copied.Description = source.Description
+	copied.Status = source.Status
+	copied.Priority = source.Priority
+	copied.DueDate = source.DueDate
+	copied.CompletedAt = source.CompletedAt
+	copied.Tags = append([]string(nil), source.Tags...)
+	return &copied
+}
+
+// MirrorFields 参与镜像与变更检测的字段，按比较时的规范化形式保存
+type MirrorFields struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Completed   bool             `json:"completed"`
+	Priority    model.Priority   `json:"priority"`
+	Due         string           `json:"due"`
+	Tags        []string         `json:"tags"`
+	Status      model.TaskStatus `json:"status"`
+}
+
+func fieldsOf(task *model.Task) MirrorFields {
+	fields := MirrorFields{
+		Title:       strings.TrimSpace(task.Title),
+		Description: strings.TrimSpace(task.Description),
+		Completed:   task.Status == model.StatusCompleted,
+		Priority:    task.Priority,
+		Status:      task.Status,
+	}
+	// 各 Provider 的截止时间精度不同，按日期比较
+	if task.DueDate != nil {
+		fields.Due = task.DueDate.UTC().Format("2006-01-02")
+	}
+	for _, tag := range task.Tags {
+		fields.Tags = append(fields.Tags, strings.ToLower(tag))
+	}
+	sort.Strings(fields.Tags)
+	return fields
+}
+
+// taskFingerprint 任务在镜像字段上的内容指纹，用于判断自上次同步后是否被修改
+func taskFingerprint(task *model.Task) string {
+	data, _ := json.Marshal(fieldsOf(task))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sameMirroredContent 两侧任务的镜像字段是否一致；状态只比较是否完成，
+// 因为不是所有 Provider 都能表示进行中、延期等状态
+func sameMirroredContent(a, b *model.Task) bool {
+	fa, fb := fieldsOf(a), fieldsOf(b)
+	fa.Status, fb.Status = "", ""
+	ja, _ := json.Marshal(fa)
+	jb, _ := json.Marshal(fb)
+	return string(ja) == string(jb)
+}
+
+// matchKey 初次镜像时配对两侧已有任务的键：Right 侧的列表名 + 标题（不区分大小写）
+func (m *mirrorRun) matchKey(side *mirrorSide, task *model.Task) string {
+	return strings.ToLower(strings.TrimSpace(m.listNameOn(side, task, m.right))) + "\x00" + strings.ToLower(strings.TrimSpace(task.Title))
+}
+
+// listName 任务所在列表的名称，优先使用该侧列表快照中的名称
+func (s *mirrorSide) listName(task *model.Task) string {
+	if list, ok := s.lists[task.ListID]; ok && list.Name != "" {
+		return list.Name
+	}
+	return task.ListName
+}
+
+func sortedTaskIDs(side *mirrorSide) []string {
+	ids := make([]string, 0, len(side.tasks))
+	for id := range side.tasks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// rawTaskID 任务在 Provider 中的原始 ID
+func rawTaskID(task *model.Task) string {
+	if task.SourceRawID != "" {
+		return task.SourceRawID
+	}
+	return task.ID
+}
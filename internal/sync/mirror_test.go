@@ -0,0 +1,660 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+)
+
+func newMirrorTestEngine(t *testing.T) (*Engine, *MockProvider, *MockProvider, MirrorStore) {
+	t.Helper()
+	left := &MockProvider{
+		name:          "todoist",
+		authenticated: true,
+		taskLists:     []model.TaskList{{ID: "inbox-l", Name: "Inbox"}},
+		tasks: map[string][]model.Task{
+			"inbox-l": {
+				{ID: "a-l", Title: "Write report", Status: model.StatusTodo, UpdatedAt: time.Now().Add(-time.Hour)},
+				{ID: "done-l", Title: "Old chore", Status: model.StatusCompleted},
+			},
+		},
+	}
+	right := &MockProvider{
+		name:          "notion",
+		authenticated: true,
+		taskLists:     []model.TaskList{{ID: "inbox-r", Name: "inbox"}},
+		tasks: map[string][]model.Task{
+			"inbox-r": {
+				{ID: "a-r", Title: "write report", Status: model.StatusTodo, UpdatedAt: time.Now().Add(-time.Hour)},
+				{ID: "c-r", Title: "Call Bob", Status: model.StatusTodo},
+			},
+		},
+	}
+	store, err := NewFileMirrorStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileMirrorStore: %v", err)
+	}
+	engine := NewEngine(map[string]provider.Provider{"todoist": left, "notion": right}, nil)
+	return engine, left, right, store
+}
+
+func mockTask(t *testing.T, p *MockProvider, listID, title string) *model.Task {
+	t.Helper()
+	for i := range p.tasks[listID] {
+		if strings.EqualFold(p.tasks[listID][i].Title, title) {
+			return &p.tasks[listID][i]
+		}
+	}
+	return nil
+}
+
+func runMirror(t *testing.T, engine *Engine, store MirrorStore, opts MirrorOptions) *MirrorResult {
+	t.Helper()
+	if opts.Left == "" {
+		opts.Left, opts.Right = "todoist", "notion"
+	}
+	result, err := engine.Mirror(context.Background(), store, opts)
+	if err != nil {
+		t.Fatalf("Mirror: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected mirror errors: %+v", result.Errors)
+	}
+	return result
+}
+
+func TestMirrorInitialPairsAndCreates(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+
+	dry := runMirror(t, engine, store, MirrorOptions{DryRun: true})
+	if dry.ToLeft.Created != 1 || len(left.tasks["inbox-l"]) != 2 {
+		t.Fatalf("dry run should only count changes: %+v", dry)
+	}
+
+	result := runMirror(t, engine, store, MirrorOptions{})
+	if result.ToLeft.Created != 1 || result.ToRight.Created != 0 || result.Skipped != 1 {
+		t.Fatalf("unexpected initial mirror result: %+v", result)
+	}
+	if mockTask(t, left, "inbox-l", "Call Bob") == nil {
+		t.Fatal("task created on the right should be mirrored to the left")
+	}
+	if mockTask(t, right, "inbox-r", "Old chore") != nil {
+		t.Fatal("tasks completed before mirroring should not be copied")
+	}
+
+	again := runMirror(t, engine, store, MirrorOptions{})
+	if again.ToLeft != (MirrorCounts{}) || again.ToRight != (MirrorCounts{}) {
+		t.Fatalf("second run without changes should be a no-op: %+v", again)
+	}
+}
+
+func TestMirrorPropagatesUpdatesCompletionsAndDeletions(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	runMirror(t, engine, store, MirrorOptions{})
+
+	mockTask(t, left, "inbox-l", "Call Bob").Description = "about the budget"
+	result := runMirror(t, engine, store, MirrorOptions{})
+	if result.ToRight.Updated != 1 || mockTask(t, right, "inbox-r", "Call Bob").Description != "about the budget" {
+		t.Fatalf("update on the left should reach the right: %+v", result)
+	}
+
+	mockTask(t, right, "inbox-r", "write report").Status = model.StatusCompleted
+	result = runMirror(t, engine, store, MirrorOptions{})
+	if result.ToLeft.Completed != 1 || mockTask(t, left, "inbox-l", "write report").Status != model.StatusCompleted {
+		t.Fatalf("completion on the right should reach the left: %+v", result)
+	}
+
+	_ = right.DeleteTask(context.Background(), "inbox-r", "c-r")
+	result = runMirror(t, engine, store, MirrorOptions{})
+	if result.ToLeft.Deleted != 1 || mockTask(t, left, "inbox-l", "Call Bob") != nil {
+		t.Fatalf("deletion on the right should reach the left: %+v", result)
+	}
+}
+
+func TestMirrorKeepsLinkWhenLookupFails(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	runMirror(t, engine, store, MirrorOptions{})
+
+	// 列表接口不再返回任务，按 ID 获取时又遇到限流：无法判断任务是否被删除，不应删除或重新创建
+	callBob := *mockTask(t, right, "inbox-r", "Call Bob")
+	_ = right.DeleteTask(context.Background(), "inbox-r", callBob.ID)
+	right.getTaskErr = fmt.Errorf("API error: status 429, body: rate limited")
+	result := runMirror(t, engine, store, MirrorOptions{})
+	if result.ToLeft.Deleted != 0 || result.ToRight.Created != 0 || mockTask(t, left, "inbox-l", "Call Bob") == nil {
+		t.Fatalf("transient lookup errors should not propagate a deletion: %+v", result)
+	}
+
+	right.getTaskErr = nil
+	right.tasks["inbox-r"] = append(right.tasks["inbox-r"], callBob)
+	result = runMirror(t, engine, store, MirrorOptions{})
+	if result.ToLeft != (MirrorCounts{}) || result.ToRight != (MirrorCounts{}) || len(right.tasks["inbox-r"]) != 2 {
+		t.Fatalf("link should be intact after the error clears: %+v", result)
+	}
+}
+
+func TestMirrorTombstonesIgnoreGhosts(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	runMirror(t, engine, store, MirrorOptions{})
+	ghost := *mockTask(t, right, "inbox-r", "Call Bob")
+	mirrored := *mockTask(t, left, "inbox-l", "Call Bob")
+
+	_ = right.DeleteTask(context.Background(), "inbox-r", ghost.ID)
+	result := runMirror(t, engine, store, MirrorOptions{})
+	if result.ToLeft.Deleted != 1 {
+		t.Fatalf("deletion on the right should reach the left: %+v", result)
+	}
+	state, _ := store.LoadMirrorState(context.Background(), "todoist", "notion")
+	if len(state.Tombstones) != 1 || state.Tombstones[0].DeletedOn != "notion" || state.Tombstones[0].Tasks["todoist"].TaskID != mirrored.ID {
+		t.Fatalf("deletion should leave a tombstone: %+v", state.Tombstones)
+	}
+
+	// Provider 再次返回已删除的任务
+	right.tasks["inbox-r"] = append(right.tasks["inbox-r"], ghost)
+	result = runMirror(t, engine, store, MirrorOptions{})
+	if result.Ghosts != 1 || result.ToLeft.Created != 0 || mockTask(t, left, "inbox-l", "Call Bob") != nil {
+		t.Fatalf("ghost of a deleted task should not be re-created: %+v", result)
+	}
+	state, _ = store.LoadMirrorState(context.Background(), "todoist", "notion")
+	if state.Tombstones[0].LastSeenAt.IsZero() {
+		t.Fatalf("seeing a ghost should keep its tombstone alive: %+v", state.Tombstones)
+	}
+
+	// 超过保留期且不再出现的墓碑被清理
+	_ = right.DeleteTask(context.Background(), "inbox-r", ghost.ID)
+	state.Tombstones[0].DeletedAt = time.Now().Add(-MirrorTombstoneRetention - time.Hour)
+	state.Tombstones[0].LastSeenAt = state.Tombstones[0].DeletedAt
+	_ = store.SaveMirrorState(context.Background(), "todoist", "notion", state)
+	runMirror(t, engine, store, MirrorOptions{})
+	if state, _ = store.LoadMirrorState(context.Background(), "todoist", "notion"); len(state.Tombstones) != 0 {
+		t.Fatalf("expired tombstones should be pruned: %+v", state.Tombstones)
+	}
+}
+
+func TestMirrorArchivesOnDelete(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	runMirror(t, engine, store, MirrorOptions{})
+
+	_ = right.DeleteTask(context.Background(), "inbox-r", "c-r")
+	result := runMirror(t, engine, store, MirrorOptions{OnDelete: MirrorDeleteArchive})
+	archived := mockTask(t, left, "inbox-l", "Call Bob")
+	if result.ToLeft.Completed != 1 || result.ToLeft.Deleted != 0 || archived == nil || archived.Status != model.StatusCompleted {
+		t.Fatalf("archive mode should complete the mirrored task instead of deleting it: %+v", result)
+	}
+
+	// 归档的任务重新打开后也不会复制回已删除的一侧
+	archived.Status = model.StatusTodo
+	result = runMirror(t, engine, store, MirrorOptions{OnDelete: MirrorDeleteArchive})
+	if result.ToRight.Created != 0 || mockTask(t, right, "inbox-r", "Call Bob") != nil {
+		t.Fatalf("archived task should not be copied back: %+v", result)
+	}
+
+	if _, err := engine.Mirror(context.Background(), store, MirrorOptions{Left: "todoist", Right: "notion", OnDelete: "trash"}); err == nil {
+		t.Fatal("unknown on-delete mode should be rejected")
+	}
+}
+
+func TestMirrorResolvesConflicts(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	runMirror(t, engine, store, MirrorOptions{})
+
+	leftTask := mockTask(t, left, "inbox-l", "write report")
+	leftTask.Description = "left edit"
+	leftTask.UpdatedAt = time.Now().Add(-time.Minute)
+	rightTask := mockTask(t, right, "inbox-r", "write report")
+	rightTask.Description = "right edit"
+	rightTask.UpdatedAt = time.Now()
+
+	result := runMirror(t, engine, store, MirrorOptions{})
+	if result.Conflicts != 1 || result.ToLeft.Updated != 1 {
+		t.Fatalf("newer edit should win the conflict: %+v", result)
+	}
+	if got := mockTask(t, left, "inbox-l", "write report").Description; got != "right edit" {
+		t.Fatalf("expected right edit on the left, got %q", got)
+	}
+
+	if _, err := engine.Mirror(context.Background(), store, MirrorOptions{Left: "todoist", Right: "todoist"}); err == nil {
+		t.Fatal("mirroring a provider with itself should fail")
+	}
+}
+
+// editBoth 两侧都修改同一任务，右侧更新时间较新
+func editBoth(t *testing.T, left, right *MockProvider, editLeft, editRight func(*model.Task)) {
+	t.Helper()
+	leftTask := mockTask(t, left, "inbox-l", "write report")
+	editLeft(leftTask)
+	leftTask.UpdatedAt = time.Now().Add(-time.Minute)
+	rightTask := mockTask(t, right, "inbox-r", "write report")
+	editRight(rightTask)
+	rightTask.UpdatedAt = time.Now()
+}
+
+func TestMirrorConflictSourceOfTruth(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	runMirror(t, engine, store, MirrorOptions{})
+	editBoth(t, left, right,
+		func(task *model.Task) { task.Description = "left edit" },
+		func(task *model.Task) { task.Description = "right edit" })
+
+	result := runMirror(t, engine, store, MirrorOptions{ConflictResolve: "todoist"})
+	if result.Conflicts != 1 || result.ToRight.Updated != 1 {
+		t.Fatalf("the source-of-truth side should win: %+v", result)
+	}
+	if got := mockTask(t, right, "inbox-r", "write report").Description; got != "left edit" {
+		t.Fatalf("expected left edit on the right, got %q", got)
+	}
+}
+
+func TestMirrorConflictFieldMerge(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	runMirror(t, engine, store, MirrorOptions{})
+	editBoth(t, left, right,
+		func(task *model.Task) { task.Description = "left notes"; task.Priority = model.PriorityHigh },
+		func(task *model.Task) { task.Title = "Write final report"; task.Priority = model.PriorityLow })
+
+	result := runMirror(t, engine, store, MirrorOptions{ConflictResolve: ConflictMerge})
+	if result.Conflicts != 1 || result.ToLeft.Updated != 1 || result.ToRight.Updated != 1 {
+		t.Fatalf("merge should update both sides: %+v", result)
+	}
+	for _, task := range []*model.Task{mockTask(t, left, "inbox-l", "Write final report"), mockTask(t, right, "inbox-r", "Write final report")} {
+		if task == nil || task.Description != "left notes" || task.Priority != model.PriorityLow {
+			t.Fatalf("expected title from the right, notes from the left and the newer priority, got %+v", task)
+		}
+	}
+
+	again := runMirror(t, engine, store, MirrorOptions{ConflictResolve: ConflictMerge})
+	if again.Conflicts != 0 || again.ToLeft != (MirrorCounts{}) || again.ToRight != (MirrorCounts{}) {
+		t.Fatalf("merged tasks should be in sync: %+v", again)
+	}
+}
+
+func TestMirrorConflictManualQueue(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	runMirror(t, engine, store, MirrorOptions{})
+	editBoth(t, left, right,
+		func(task *model.Task) { task.Description = "left edit" },
+		func(task *model.Task) { task.Description = "right edit" })
+
+	result := runMirror(t, engine, store, MirrorOptions{ConflictResolve: ConflictManual})
+	if result.PendingConflicts != 1 || result.ToLeft != (MirrorCounts{}) || result.ToRight != (MirrorCounts{}) {
+		t.Fatalf("manual conflicts should be queued without changes: %+v", result)
+	}
+	state, err := store.LoadMirrorState(context.Background(), "todoist", "notion")
+	if err != nil || len(state.Conflicts) != 1 {
+		t.Fatalf("expected one queued conflict, got %+v (%v)", state, err)
+	}
+	conflict := state.Conflicts[0]
+	if conflict.Tasks["notion"].Fields.Description != "right edit" {
+		t.Fatalf("queued conflict should record both sides: %+v", conflict)
+	}
+
+	runMirror(t, engine, store, MirrorOptions{ConflictResolve: ConflictManual})
+	if state, _ = store.LoadMirrorState(context.Background(), "todoist", "notion"); len(state.Conflicts) != 1 || state.Conflicts[0].DetectedAt != conflict.DetectedAt {
+		t.Fatalf("unresolved conflict should stay queued: %+v", state.Conflicts)
+	}
+
+	if err := ResolveMirrorConflict(context.Background(), store, "todoist", "notion", conflict.ID, "slack"); err == nil {
+		t.Fatal("keeping an unknown side should fail")
+	}
+	if err := ResolveMirrorConflict(context.Background(), store, "todoist", "notion", conflict.ID, ConflictLeft); err != nil {
+		t.Fatalf("ResolveMirrorConflict: %v", err)
+	}
+	result = runMirror(t, engine, store, MirrorOptions{ConflictResolve: ConflictManual})
+	if result.PendingConflicts != 0 || result.ToRight.Updated != 1 {
+		t.Fatalf("resolution should be applied: %+v", result)
+	}
+	if got := mockTask(t, right, "inbox-r", "write report").Description; got != "left edit" {
+		t.Fatalf("expected kept left edit on the right, got %q", got)
+	}
+	if state, _ = store.LoadMirrorState(context.Background(), "todoist", "notion"); len(state.Conflicts) != 0 {
+		t.Fatalf("resolved conflict should be dropped: %+v", state.Conflicts)
+	}
+}
+
+func TestMirrorConflictManualFieldChoices(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	ctx := context.Background()
+	runMirror(t, engine, store, MirrorOptions{})
+	editBoth(t, left, right,
+		func(task *model.Task) { task.Description = "left notes"; task.Priority = model.PriorityHigh },
+		func(task *model.Task) { task.Description = "right notes"; task.Priority = model.PriorityLow })
+
+	runMirror(t, engine, store, MirrorOptions{ConflictResolve: ConflictManual})
+	state, err := store.LoadMirrorState(ctx, "todoist", "notion")
+	if err != nil || len(state.Conflicts) != 1 {
+		t.Fatalf("expected one queued conflict, got %+v (%v)", state, err)
+	}
+	id := state.Conflicts[0].ID
+	if got := state.Conflicts[0].DifferingFields("todoist", "notion"); !slices.Equal(got, []string{"description", "priority"}) {
+		t.Fatalf("unexpected differing fields: %v", got)
+	}
+
+	if _, err := ResolveMirrorConflictFields(ctx, store, "todoist", "notion", id, "todoist", map[string]string{"color": "notion"}); err == nil {
+		t.Fatal("choosing an unknown field should fail")
+	}
+	conflict, err := ResolveMirrorConflictFields(ctx, store, "todoist", "notion", id, ConflictLeft, map[string]string{"priority": ConflictRight, "description": "todoist"})
+	if err != nil {
+		t.Fatalf("ResolveMirrorConflictFields: %v", err)
+	}
+	if conflict.Resolution != "todoist" || len(conflict.Fields) != 1 || conflict.Fields["priority"] != "notion" {
+		t.Fatalf("only choices differing from keep should be recorded: %+v", conflict)
+	}
+
+	result := runMirror(t, engine, store, MirrorOptions{ConflictResolve: ConflictManual})
+	if result.PendingConflicts != 0 || result.ToLeft.Updated != 1 || result.ToRight.Updated != 1 {
+		t.Fatalf("field choices should be merged into both sides: %+v", result)
+	}
+	for _, task := range []*model.Task{mockTask(t, left, "inbox-l", "write report"), mockTask(t, right, "inbox-r", "write report")} {
+		if task.Description != "left notes" || task.Priority != model.PriorityLow {
+			t.Fatalf("expected notes from the left and priority from the right, got %+v", task)
+		}
+	}
+	again := runMirror(t, engine, store, MirrorOptions{ConflictResolve: ConflictManual})
+	if again.Conflicts != 0 || again.ToLeft != (MirrorCounts{}) || again.ToRight != (MirrorCounts{}) {
+		t.Fatalf("merged tasks should be in sync: %+v", again)
+	}
+}
+
+func TestNormalizeConflictStrategy(t *testing.T) {
+	cases := map[string]string{"": ConflictNewer, "last-write-wins": ConflictNewer, "Merge": ConflictMerge, "todoist": ConflictLeft, "notion": ConflictRight}
+	for input, want := range cases {
+		if got, err := NormalizeConflictStrategy(input, "todoist", "notion"); err != nil || got != want {
+			t.Fatalf("NormalizeConflictStrategy(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := NormalizeConflictStrategy("google", "todoist", "notion"); err == nil {
+		t.Fatal("a provider outside the pair is not a valid strategy")
+	}
+}
+
+func TestMirrorFilterScopesBothSides(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	report := mockTask(t, left, "inbox-l", "Write report")
+	report.Tags = []string{"Work"}
+	report.UpdatedAt = time.Now()
+	filter := &MirrorFilter{Tags: []string{"work"}}
+
+	result := runMirror(t, engine, store, MirrorOptions{Filter: filter})
+	if result.ToLeft.Created != 0 || result.Filtered != 2 {
+		t.Fatalf("only the tagged task should be mirrored: %+v", result)
+	}
+	if tags := mockTask(t, right, "inbox-r", "write report").Tags; len(tags) != 1 {
+		t.Fatalf("paired task should take the tag from the newer side, got %v", tags)
+	}
+
+	// 一侧删除标签：先同步到另一侧，之后两侧都不在范围内，停止跟踪
+	mockTask(t, right, "inbox-r", "write report").Tags = nil
+	result = runMirror(t, engine, store, MirrorOptions{Filter: filter})
+	if result.ToLeft.Updated != 1 || len(mockTask(t, left, "inbox-l", "Write report").Tags) != 0 {
+		t.Fatalf("tag removal should reach the other side: %+v", result)
+	}
+	mockTask(t, left, "inbox-l", "Write report").Description = "draft"
+	result = runMirror(t, engine, store, MirrorOptions{Filter: filter})
+	if result.ToRight.Updated != 0 || mockTask(t, right, "inbox-r", "write report").Description != "" {
+		t.Fatalf("tasks out of scope on both sides should no longer be mirrored: %+v", result)
+	}
+}
+
+func TestMirrorFilterMatch(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-10 * 24 * time.Hour)
+	filter := &MirrorFilter{Lists: []string{"Work"}, ExcludeCompletedOlderThan: 7 * 24 * time.Hour}
+	cases := []struct {
+		task     model.Task
+		listName string
+		want     bool
+	}{
+		{model.Task{Status: model.StatusTodo}, "work", true},
+		{model.Task{Status: model.StatusTodo}, "Personal", false},
+		{model.Task{Status: model.StatusCompleted, CompletedAt: &old}, "Work", false},
+		{model.Task{Status: model.StatusCompleted, CompletedAt: &now}, "Work", true},
+	}
+	for i, tc := range cases {
+		if got := filter.Match(&tc.task, tc.listName, now); got != tc.want {
+			t.Fatalf("case %d: Match = %v, want %v", i, got, tc.want)
+		}
+	}
+}
+
+func TestMirrorFieldMapRoundTrip(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	report := mockTask(t, left, "inbox-l", "Write report")
+	report.Priority = model.PriorityUrgent
+	report.Status = model.StatusDeferred
+	report.UpdatedAt = time.Now()
+	// 按 notion → todoist 方向配置，镜像时自动互换
+	fieldMap := &MirrorFieldMap{
+		Left: "notion", Right: "todoist",
+		Priority: []MirrorValuePair{{Left: "high", Right: "high"}, {Left: "high", Right: "4"}},
+		Status:   []MirrorValuePair{{Left: "todo", Right: "todo"}, {Left: "todo", Right: "deferred"}},
+	}
+
+	runMirror(t, engine, store, MirrorOptions{FieldMap: fieldMap})
+	mirrored := mockTask(t, right, "inbox-r", "write report")
+	if mirrored.Priority != model.PriorityHigh || mirrored.Status != model.StatusTodo {
+		t.Fatalf("values should be mapped to the right side, got %v %v", mirrored.Priority, mirrored.Status)
+	}
+
+	mirrored.Description = "edited on the right"
+	result := runMirror(t, engine, store, MirrorOptions{FieldMap: fieldMap})
+	report = mockTask(t, left, "inbox-l", "Write report")
+	if result.ToLeft.Updated != 1 || report.Description != "edited on the right" {
+		t.Fatalf("edit on the right should reach the left: %+v", result)
+	}
+	if report.Priority != model.PriorityUrgent || report.Status != model.StatusDeferred {
+		t.Fatalf("equivalent values should survive the round trip, got %v %v", report.Priority, report.Status)
+	}
+
+	again := runMirror(t, engine, store, MirrorOptions{FieldMap: fieldMap})
+	if again.ToLeft != (MirrorCounts{}) || again.ToRight != (MirrorCounts{}) {
+		t.Fatalf("mapped values should not be treated as changes: %+v", again)
+	}
+}
+
+func TestDefaultMirrorFieldMap(t *testing.T) {
+	fm := DefaultMirrorFieldMap("todoist", "microsoft")
+	want := []MirrorValuePair{{"low", "low"}, {"medium", "medium"}, {"high", "high"}, {"urgent", "high"}}
+	if fmt.Sprint(fm.Priority) != fmt.Sprint(want) {
+		t.Fatalf("DefaultMirrorFieldMap priority = %v, want %v", fm.Priority, want)
+	}
+	if fm := DefaultMirrorFieldMap("google", "ticktick"); len(fm.Priority) != 0 {
+		t.Fatalf("providers storing every priority need no default map, got %v", fm.Priority)
+	}
+}
+
+// deltaMockProvider 支持增量读取的 MockProvider：空游标返回全部任务，否则返回 pending 中登记的变更
+type deltaMockProvider struct {
+	*MockProvider
+	pending provider.SyncChanges
+	cursors []string
+}
+
+func (d *deltaMockProvider) ListTaskDelta(_ context.Context, cursor string) (*provider.SyncChanges, error) {
+	d.cursors = append(d.cursors, cursor)
+	next := fmt.Sprintf("c%d", len(d.cursors))
+	if cursor == "" {
+		changes := &provider.SyncChanges{NextToken: next, Full: true}
+		for listID, tasks := range d.tasks {
+			for _, task := range tasks {
+				task.ListID = listID
+				changes.Tasks = append(changes.Tasks, task)
+			}
+		}
+		return changes, nil
+	}
+	changes := d.pending
+	changes.NextToken = next
+	d.pending = provider.SyncChanges{}
+	return &changes, nil
+}
+
+func TestMirrorIncrementalWithDeltaProvider(t *testing.T) {
+	_, leftMock, right, store := newMirrorTestEngine(t)
+	left := &deltaMockProvider{MockProvider: leftMock}
+	engine := NewEngine(map[string]provider.Provider{"todoist": left, "notion": right}, nil)
+	runMirror(t, engine, store, MirrorOptions{})
+	if len(left.cursors) != 1 || left.cursors[0] != "" {
+		t.Fatalf("initial mirror should read everything: %v", left.cursors)
+	}
+
+	// 未出现在增量中的修改不会被读取
+	mockTask(t, leftMock, "inbox-l", "write report").Description = "silent edit"
+	bob := mockTask(t, leftMock, "inbox-l", "Call Bob")
+	bob.Description = "reported edit"
+	left.pending = provider.SyncChanges{Tasks: []model.Task{*bob}}
+	result := runMirror(t, engine, store, MirrorOptions{})
+	if left.cursors[1] != "c1" || len(result.Incremental) != 1 || result.Incremental[0] != "todoist" {
+		t.Fatalf("second mirror should read changes since the saved cursor: %v %+v", left.cursors, result)
+	}
+	if result.ToRight.Updated != 1 || mockTask(t, right, "inbox-r", "Call Bob").Description != "reported edit" {
+		t.Fatalf("reported change should reach the right: %+v", result)
+	}
+	if mockTask(t, right, "inbox-r", "write report").Description == "silent edit" {
+		t.Fatal("unreported change should not be read in an incremental mirror")
+	}
+
+	left.pending = provider.SyncChanges{DeletedIDs: []string{bob.ID}}
+	_ = leftMock.DeleteTask(context.Background(), "inbox-l", bob.ID)
+	result = runMirror(t, engine, store, MirrorOptions{})
+	if left.cursors[2] != "c2" || result.ToRight.Deleted != 1 || mockTask(t, right, "inbox-r", "Call Bob") != nil {
+		t.Fatalf("deletion reported by the delta should reach the right: %+v", result)
+	}
+
+	result = runMirror(t, engine, store, MirrorOptions{Full: true})
+	if left.cursors[3] != "" || len(result.Incremental) != 0 || result.ToRight.Updated != 1 {
+		t.Fatalf("full mirror should ignore the cursor and pick up every change: %v %+v", left.cursors, result)
+	}
+}
+
+func TestMirrorRecordsRunsForStatusReport(t *testing.T) {
+	engine, _, right, store := newMirrorTestEngine(t)
+	ctx := context.Background()
+
+	runMirror(t, engine, store, MirrorOptions{DryRun: true})
+	if pairs, _ := store.ListMirrorPairs(ctx); len(pairs) != 0 {
+		t.Fatalf("dry runs should not be recorded: %+v", pairs)
+	}
+
+	runMirror(t, engine, store, MirrorOptions{})
+	right.authenticated = false
+	if _, err := engine.Mirror(ctx, store, MirrorOptions{Left: "todoist", Right: "notion"}); err == nil {
+		t.Fatal("mirror with an unauthenticated provider should fail")
+	}
+
+	reports, err := MirrorStatusReport(ctx, store)
+	if err != nil {
+		t.Fatalf("MirrorStatusReport: %v", err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("expected one mirror pair, got %+v", reports)
+	}
+	report := reports[0]
+	run := report.LastRun
+	if run == nil || run.Runs != 2 || run.Failures != 1 || run.LastSuccessAt.IsZero() || run.Result != nil {
+		t.Fatalf("unexpected last run: %+v", run)
+	}
+	if report.Links != 2 || len(report.Errors) != 1 || !strings.Contains(report.Errors[0], "not authenticated") {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	right.authenticated = true
+	runMirror(t, engine, store, MirrorOptions{})
+	reports, _ = MirrorStatusReport(ctx, store)
+	if report = reports[0]; report.Left != "todoist" || len(report.Errors) != 0 || report.LastRun.Failures != 1 || report.LastRun.Result == nil {
+		t.Fatalf("a successful run should replace the error: %+v", report)
+	}
+}
+
+func TestMirrorOneWayOverwritesMirrorEdits(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	ctx := context.Background()
+	opts := MirrorOptions{Left: "todoist", Right: "notion", Source: "notion"}
+
+	result := runMirror(t, engine, store, opts)
+	if result.ToLeft.Created != 1 || result.ToRight != (MirrorCounts{}) || result.Source != "notion" {
+		t.Fatalf("one-way mirror should only write to the mirror side: %+v", result)
+	}
+	if got := mockTask(t, left, "inbox-l", "write report").Title; got != "write report" {
+		t.Fatalf("initial pairing should take the source content, got %q", got)
+	}
+
+	mockTask(t, left, "inbox-l", "Call Bob").Description = "local note"
+	left.tasks["inbox-l"] = append(left.tasks["inbox-l"], model.Task{ID: "p-l", Title: "Personal errand", Status: model.StatusTodo})
+	result = runMirror(t, engine, store, opts)
+	if result.Overwritten != 1 || result.ToLeft.Updated != 1 || mockTask(t, left, "inbox-l", "Call Bob").Description != "" {
+		t.Fatalf("edits on the mirror side should be overwritten: %+v", result)
+	}
+	if mockTask(t, right, "inbox-r", "Personal errand") != nil {
+		t.Fatal("tasks created on the mirror side should not reach the source")
+	}
+
+	_ = left.DeleteTask(ctx, "inbox-l", mockTask(t, left, "inbox-l", "Call Bob").ID)
+	result = runMirror(t, engine, store, opts)
+	if result.ToLeft.Created != 1 || result.Overwritten != 1 || mockTask(t, left, "inbox-l", "Call Bob") == nil {
+		t.Fatalf("deleted mirror copies should be recreated: %+v", result)
+	}
+
+	_ = right.DeleteTask(ctx, "inbox-r", "c-r")
+	result = runMirror(t, engine, store, opts)
+	if result.ToLeft.Deleted != 1 || mockTask(t, left, "inbox-l", "Call Bob") != nil {
+		t.Fatalf("deletions on the source should reach the mirror side: %+v", result)
+	}
+
+	if _, err := engine.Mirror(ctx, store, MirrorOptions{Left: "todoist", Right: "notion", Source: "slack"}); err == nil {
+		t.Fatal("a source outside the pair should be rejected")
+	}
+}
+
+func TestMirrorOneWayFlagsMirrorEdits(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	ctx := context.Background()
+	opts := MirrorOptions{Left: "todoist", Right: "notion", Source: "notion", MirrorEdits: MirrorEditsFlag}
+	runMirror(t, engine, store, opts)
+
+	mockTask(t, left, "inbox-l", "Call Bob").Description = "local note"
+	mockTask(t, right, "inbox-r", "Call Bob").Description = "source note"
+	result := runMirror(t, engine, store, opts)
+	if result.PendingConflicts != 1 || result.ToLeft != (MirrorCounts{}) || result.Overwritten != 0 {
+		t.Fatalf("edits on the mirror side should be flagged without changes: %+v", result)
+	}
+	state, _ := store.LoadMirrorState(ctx, "todoist", "notion")
+	if len(state.Conflicts) != 1 {
+		t.Fatalf("flagged edit should be queued: %+v", state.Conflicts)
+	}
+	id := state.Conflicts[0].ID
+
+	// 接受镜像侧的修改后不再标记，来源之后的修改照常写入
+	if err := ResolveMirrorConflict(ctx, store, "todoist", "notion", id, "todoist"); err != nil {
+		t.Fatalf("ResolveMirrorConflict: %v", err)
+	}
+	result = runMirror(t, engine, store, opts)
+	if result.PendingConflicts != 0 || result.ToRight != (MirrorCounts{}) || mockTask(t, left, "inbox-l", "Call Bob").Description != "local note" {
+		t.Fatalf("accepted edit should be kept and never reach the source: %+v", result)
+	}
+	mockTask(t, right, "inbox-r", "Call Bob").Description = "source update"
+	result = runMirror(t, engine, store, opts)
+	if result.ToLeft.Updated != 1 || mockTask(t, left, "inbox-l", "Call Bob").Description != "source update" {
+		t.Fatalf("later source edits should flow to the mirror side: %+v", result)
+	}
+
+	// 删除副本同样被标记，选择来源时重新创建
+	_ = left.DeleteTask(ctx, "inbox-l", mockTask(t, left, "inbox-l", "Call Bob").ID)
+	result = runMirror(t, engine, store, opts)
+	if result.PendingConflicts != 1 || result.ToLeft.Created != 0 {
+		t.Fatalf("deleted mirror copy should be flagged: %+v", result)
+	}
+	state, _ = store.LoadMirrorState(ctx, "todoist", "notion")
+	if err := ResolveMirrorConflict(ctx, store, "todoist", "notion", state.Conflicts[0].ID, "notion"); err != nil {
+		t.Fatalf("ResolveMirrorConflict: %v", err)
+	}
+	result = runMirror(t, engine, store, opts)
+	if result.ToLeft.Created != 1 || result.PendingConflicts != 0 || mockTask(t, left, "inbox-l", "Call Bob") == nil {
+		t.Fatalf("keeping the source should recreate the copy: %+v", result)
+	}
+}
@@ -0,0 +1,347 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// 镜像冲突策略：两侧都修改了同一任务时如何处理
+const (
+	// ConflictNewer 以更新时间较新的一侧为准（last-write-wins）
+	ConflictNewer = "newer"
+	// ConflictLeft 以 Left 为准
+	ConflictLeft = "left"
+	// ConflictRight 以 Right 为准
+	ConflictRight = "right"
+	// ConflictMerge 字段级合并：只有一侧修改的字段取该侧的值，两侧都修改的字段取较新的一侧
+	ConflictMerge = "merge"
+	// ConflictManual 两侧都保持不变，冲突排队等待人工选择保留哪一侧
+	ConflictManual = "manual"
+)
+
+// NormalizeConflictStrategy 规范化冲突策略：空值与 last-write-wins 视为 newer，
+// Left、Right 的 Provider 名称视为以该侧为准
+func NormalizeConflictStrategy(strategy, left, right string) (string, error) {
+	switch value := strings.ToLower(strings.TrimSpace(strategy)); value {
+	case "", ConflictNewer, "last_write_wins", "last-write-wins":
+		return ConflictNewer, nil
+	case ConflictLeft, ConflictRight, ConflictMerge, ConflictManual:
+		return value, nil
+	case left:
+		return ConflictLeft, nil
+	case right:
+		return ConflictRight, nil
+	default:
+		return "", fmt.Errorf("unknown conflict strategy %q", strategy)
+	}
+}
+
+// MirrorConflict 排队等待人工处理的冲突
+type MirrorConflict struct {
+	ID string `json:"id"`
+	// Tasks 两侧任务在检测到冲突时的内容，键为 Provider 名称
+	Tasks      map[string]MirrorConflictTask `json:"tasks"`
+	DetectedAt time.Time                     `json:"detected_at"`
+	// Resolution 人工选择保留的一侧（Provider 名称），下次镜像时应用到另一侧
+	Resolution string `json:"resolution,omitempty"`
+	// Fields 逐字段选择取值的一侧（字段名 → Provider 名称），未列出的字段取 Resolution 一侧；
+	// 合并结果写入两侧（单向镜像时只写入镜像侧）
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// MirrorConflictFields 可逐字段选择的字段名，按展示顺序排列
+var MirrorConflictFields = []string{"title", "description", "status", "priority", "due", "tags"}
+
+// FieldValue 字段的展示文本，字段名见 MirrorConflictFields
+func (f MirrorFields) FieldValue(name string) string {
+	switch name {
+	case "title":
+		return f.Title
+	case "description":
+		return f.Description
+	case "status":
+		if f.Status == "" && f.Completed {
+			return string(model.StatusCompleted)
+		}
+		return string(f.Status)
+	case "priority":
+		return priorityName(f.Priority)
+	case "due":
+		return f.Due
+	case "tags":
+		return strings.Join(f.Tags, ", ")
+	default:
+		return ""
+	}
+}
+
+// DifferingFields 两侧取值不同的字段；一侧任务已删除时返回 nil
+func (c MirrorConflict) DifferingFields(left, right string) []string {
+	leftTask, ok := c.Tasks[left]
+	if !ok {
+		return nil
+	}
+	rightTask, ok := c.Tasks[right]
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, name := range MirrorConflictFields {
+		if leftTask.Fields.FieldValue(name) != rightTask.Fields.FieldValue(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// MirrorConflictTask 冲突中一侧的任务
+type MirrorConflictTask struct {
+	ListID    string       `json:"list_id"`
+	TaskID    string       `json:"task_id"`
+	Fields    MirrorFields `json:"fields"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// ResolveMirrorConflict 为排队的冲突选择保留的一侧；keep 为 Provider 名称或 left、right。
+// 选择在下次镜像（sync mirror 或 sync start）时应用。
+func ResolveMirrorConflict(ctx context.Context, store MirrorStore, left, right, id, keep string) error {
+	_, err := ResolveMirrorConflictFields(ctx, store, left, right, id, keep, nil)
+	return err
+}
+
+// ResolveMirrorConflictFields 与 ResolveMirrorConflict 相同，fields 逐字段选择取值的一侧（字段名 → Provider 名称或 left、right），
+// 未列出的字段取 keep 一侧。返回记录了选择的冲突
+func ResolveMirrorConflictFields(ctx context.Context, store MirrorStore, left, right, id, keep string, fields map[string]string) (*MirrorConflict, error) {
+	side := func(value string) (string, error) {
+		switch value {
+		case ConflictLeft:
+			return left, nil
+		case ConflictRight:
+			return right, nil
+		case left, right:
+			return value, nil
+		default:
+			return "", fmt.Errorf("keep must be %s or %s, got %q", left, right, value)
+		}
+	}
+	keep, err := side(keep)
+	if err != nil {
+		return nil, err
+	}
+	var choices map[string]string
+	for name, value := range fields {
+		if !slices.Contains(MirrorConflictFields, name) {
+			return nil, fmt.Errorf("unsupported field %q, expected one of %s", name, strings.Join(MirrorConflictFields, ", "))
+		}
+		chosen, err := side(value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		if chosen == keep {
+			continue
+		}
+		if choices == nil {
+			choices = make(map[string]string)
+		}
+		choices[name] = chosen
+	}
+
+	state, err := store.LoadMirrorState(ctx, left, right)
+	if err != nil {
+		return nil, fmt.Errorf("load mirror state: %w", err)
+	}
+	for i := range state.Conflicts {
+		conflict := &state.Conflicts[i]
+		if conflict.ID != id {
+			continue
+		}
+		if len(choices) > 0 && len(conflict.Tasks) < 2 {
+			return nil, fmt.Errorf("conflict %s involves a deleted task and cannot be merged by field", id)
+		}
+		conflict.Resolution = keep
+		conflict.Fields = choices
+		if err := store.SaveMirrorState(ctx, left, right, state); err != nil {
+			return nil, err
+		}
+		return conflict, nil
+	}
+	return nil, fmt.Errorf("conflict %s not found", id)
+}
+
+func conflictsByID(conflicts []MirrorConflict) map[string]MirrorConflict {
+	byID := make(map[string]MirrorConflict, len(conflicts))
+	for _, conflict := range conflicts {
+		byID[conflict.ID] = conflict
+	}
+	return byID
+}
+
+// mirrorConflictID 由镜像关系两侧的任务 ID 得到的稳定冲突 ID
+func mirrorConflictID(link MirrorLink) string {
+	keys := make([]string, 0, len(link))
+	for name, ref := range link {
+		keys = append(keys, name+":"+ref.TaskID)
+	}
+	sort.Strings(keys)
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\x00")))
+	return hex.EncodeToString(sum[:4])
+}
+
+// manualResolution 返回人工选择保留的一侧及逐字段的选择；尚未选择时冲突继续排队，两侧都不修改
+func (m *mirrorRun) manualResolution(link MirrorLink, leftTask, rightTask *model.Task) (*mirrorSide, map[string]string, bool) {
+	id := mirrorConflictID(link)
+	previous, queued := m.queued[id]
+	switch {
+	case previous.Resolution == m.left.name:
+		return m.left, previous.Fields, true
+	case previous.Resolution == m.right.name:
+		return m.right, previous.Fields, true
+	}
+
+	conflict := MirrorConflict{
+		ID:         id,
+		DetectedAt: time.Now(),
+		Tasks: map[string]MirrorConflictTask{
+			m.left.name:  conflictTask(leftTask),
+			m.right.name: conflictTask(rightTask),
+		},
+	}
+	if queued {
+		conflict.DetectedAt = previous.DetectedAt
+	}
+	m.pending = append(m.pending, conflict)
+	m.result.PendingConflicts++
+	log.Warn().Str("task", leftTask.Title).Str("conflict", id).Msg("两侧都修改了任务，等待人工处理")
+	return nil, nil, false
+}
+
+// applyChoices 按人工的逐字段选择合并两侧并写回：以 keep 一侧为基础，fields 中选择另一侧的字段取另一侧的值
+func (m *mirrorRun) applyChoices(ctx context.Context, link MirrorLink, leftTask, rightTask *model.Task, keep *mirrorSide, fields map[string]string) MirrorLink {
+	rightAsLeft := m.toSide(m.right, rightTask, m.left, leftTask)
+	base, other := leftTask, rightAsLeft
+	if keep == m.right {
+		base, other = rightAsLeft, leftTask
+	}
+	merged := mirroredCopy(base, leftTask)
+	for name, provider := range fields {
+		if provider == keep.name {
+			continue
+		}
+		switch name {
+		case "title":
+			merged.Title = other.Title
+		case "description":
+			merged.Description = other.Description
+		case "status":
+			merged.Status = other.Status
+			merged.CompletedAt = other.CompletedAt
+		case "priority":
+			merged.Priority = other.Priority
+		case "due":
+			merged.DueDate = other.DueDate
+		case "tags":
+			merged.Tags = append([]string(nil), other.Tags...)
+		}
+	}
+
+	newLeft, newRight := leftTask, rightTask
+	if m.canWrite(m.left) && !sameMirroredContent(merged, leftTask) {
+		updated, ok := m.update(ctx, m.left, merged, m.left, leftTask)
+		if !ok {
+			return link
+		}
+		newLeft = updated
+	}
+	if m.canWrite(m.right) && !m.sameContent(merged, rightTask) {
+		updated, ok := m.update(ctx, m.left, merged, m.right, rightTask)
+		if !ok {
+			return link
+		}
+		newRight = updated
+	}
+	return m.link(newLeft, newRight)
+}
+
+// keepQueued 本次未处理的镜像关系保留其排队的冲突
+func (m *mirrorRun) keepQueued(link MirrorLink) {
+	if conflict, ok := m.queued[mirrorConflictID(link)]; ok {
+		m.pending = append(m.pending, conflict)
+		m.result.PendingConflicts++
+	}
+}
+
+func conflictTask(task *model.Task) MirrorConflictTask {
+	return MirrorConflictTask{ListID: task.ListID, TaskID: rawTaskID(task), Fields: fieldsOf(task), UpdatedAt: task.UpdatedAt}
+}
+
+// merge 按字段合并两侧的修改并写回两侧；缺少上次同步的字段记录时返回 false，由调用方按更新时间处理
+func (m *mirrorRun) merge(ctx context.Context, link MirrorLink, leftTask, rightTask *model.Task) (MirrorLink, bool) {
+	leftBase, rightBase := link[m.left.name].Fields, link[m.right.name].Fields
+	if leftBase == nil || rightBase == nil {
+		return nil, false
+	}
+	rightAsLeft := m.toSide(m.right, rightTask, m.left, leftTask)
+	merged := mergeTasks(*leftBase, *rightBase, leftTask, rightTask, rightAsLeft, rightTask.UpdatedAt.After(leftTask.UpdatedAt))
+
+	newLeft, newRight := leftTask, rightTask
+	if !sameMirroredContent(merged, leftTask) {
+		updated, ok := m.update(ctx, m.left, merged, m.left, leftTask)
+		if !ok {
+			return link, true
+		}
+		newLeft = updated
+	}
+	if !m.sameContent(merged, rightTask) {
+		updated, ok := m.update(ctx, m.left, merged, m.right, rightTask)
+		if !ok {
+			return link, true
+		}
+		newRight = updated
+	}
+	return m.link(newLeft, newRight), true
+}
+
+// mergeTasks 字段级合并：每个字段与该侧上次同步时的值比较，只有一侧修改时取该侧，两侧都修改时 preferRight 决定。
+// 合并结果使用 Left 侧的取值，取自 Right 侧的字段使用映射到 Left 侧的 rightAsLeft
+func mergeTasks(leftBase, rightBase MirrorFields, leftTask, rightTask, rightAsLeft *model.Task, preferRight bool) *model.Task {
+	lf, rf := fieldsOf(leftTask), fieldsOf(rightTask)
+	takeRight := func(leftChanged, rightChanged bool) bool {
+		if leftChanged && rightChanged {
+			return preferRight
+		}
+		return rightChanged
+	}
+
+	merged := mirroredCopy(leftTask, leftTask)
+	if takeRight(lf.Title != leftBase.Title, rf.Title != rightBase.Title) {
+		merged.Title = rightAsLeft.Title
+	}
+	if takeRight(lf.Description != leftBase.Description, rf.Description != rightBase.Description) {
+		merged.Description = rightAsLeft.Description
+	}
+	if takeRight(lf.Status != leftBase.Status, rf.Status != rightBase.Status) {
+		merged.Status = rightAsLeft.Status
+		merged.CompletedAt = rightAsLeft.CompletedAt
+	}
+	if takeRight(lf.Priority != leftBase.Priority, rf.Priority != rightBase.Priority) {
+		merged.Priority = rightAsLeft.Priority
+	}
+	if takeRight(lf.Due != leftBase.Due, rf.Due != rightBase.Due) {
+		merged.DueDate = rightAsLeft.DueDate
+	}
+	if takeRight(!slices.Equal(lf.Tags, leftBase.Tags), !slices.Equal(rf.Tags, rightBase.Tags)) {
+		merged.Tags = append([]string(nil), rightAsLeft.Tags...)
+	}
+	return merged
+}
@@ -0,0 +1,286 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// MirrorDaemonStatusFile 镜像守护进程在存储目录中写入的状态文件名
+const MirrorDaemonStatusFile = "mirror_daemon.json"
+
+// MirrorPair 互为镜像的一对 Provider
+type MirrorPair struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	// Conflict 该对的冲突策略，为空时使用守护进程的默认选项
+	Conflict string `json:"conflict,omitempty"`
+	// Filter 该对的过滤规则，为 nil 时不过滤
+	Filter *MirrorFilter `json:"filter,omitempty"`
+	// FieldMap 该对的字段映射，为 nil 时只使用默认映射
+	FieldMap *MirrorFieldMap `json:"field_map,omitempty"`
+	// Source、MirrorEdits 该对的单向镜像设置，Source 为空时双向镜像
+	Source      string `json:"source,omitempty"`
+	MirrorEdits string `json:"mirror_edits,omitempty"`
+	// OnDelete 该对删除任务的处理方式（见 MirrorDeleteRemove），为空时使用守护进程的默认选项
+	OnDelete string `json:"on_delete,omitempty"`
+	// Schedule 该对的 cron 表达式（见 ParseMirrorSchedule），为空时按守护进程的间隔镜像
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// String 返回 left:right 形式
+func (p MirrorPair) String() string {
+	return p.Left + ":" + p.Right
+}
+
+// ParseMirrorPair 解析 left:right 形式的 Provider 对，resolve 用于解析简写（可为 nil）
+func ParseMirrorPair(value string, resolve func(string) string) (MirrorPair, error) {
+	left, right, ok := strings.Cut(value, ":")
+	left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+	if !ok || left == "" || right == "" {
+		return MirrorPair{}, fmt.Errorf("invalid pair %q, expected <provider>:<provider>", value)
+	}
+	if resolve != nil {
+		left, right = resolve(left), resolve(right)
+	}
+	if left == right {
+		return MirrorPair{}, fmt.Errorf("invalid pair %q: providers must differ", value)
+	}
+	return MirrorPair{Left: left, Right: right}, nil
+}
+
+// ParseMirrorSchedule 解析镜像对的 cron 表达式：标准五段（分 时 日 月 周），支持 @hourly 等描述符与 CRON_TZ= 前缀，
+// 如 "*/15 8-18 * * 1-5" 表示工作日 8–18 点每 15 分钟
+func ParseMirrorSchedule(expr string) (cron.Schedule, error) {
+	return cron.ParseStandard(strings.TrimSpace(expr))
+}
+
+// MirrorPairStatus 守护进程中一对 Provider 的运行状态
+type MirrorPairStatus struct {
+	Pair       string        `json:"pair"`
+	Schedule   string        `json:"schedule,omitempty"`
+	Runs       int           `json:"runs"`
+	Failures   int           `json:"failures"`
+	LastRunAt  time.Time     `json:"last_run_at,omitempty"`
+	LastResult *MirrorResult `json:"last_result,omitempty"`
+	LastError  string        `json:"last_error,omitempty"`
+	NextRunAt  time.Time     `json:"next_run_at,omitempty"`
+}
+
+// MirrorDaemonStatus 镜像守护进程状态，写入 MirrorDaemonStatusFile 供 sync status 读取
+type MirrorDaemonStatus struct {
+	PID       int                `json:"pid"`
+	Running   bool               `json:"running"`
+	StartedAt time.Time          `json:"started_at"`
+	StoppedAt *time.Time         `json:"stopped_at,omitempty"`
+	Interval  string             `json:"interval"`
+	Pairs     []MirrorPairStatus `json:"pairs"`
+}
+
+// MirrorDaemon 持续镜像一组 Provider 对：设置了 Schedule 的对按各自的 cron 表达式运行，其余按固定间隔运行
+type MirrorDaemon struct {
+	engine   *Engine
+	store    MirrorStore
+	pairs    []MirrorPair
+	interval time.Duration
+	// options 每次镜像使用的选项模板，Left/Right 由 pairs 填充
+	options MirrorOptions
+	// statusPath 状态文件路径，为空时不写入
+	statusPath string
+	// OnRun 每对 Provider 镜像完成后的回调，用于输出运行状态
+	OnRun func(status MirrorPairStatus)
+
+	mu     sync.Mutex
+	status MirrorDaemonStatus
+}
+
+// NewMirrorDaemon 创建镜像守护进程；statusDir 非空时在其中写入 MirrorDaemonStatusFile
+func NewMirrorDaemon(engine *Engine, store MirrorStore, pairs []MirrorPair, interval time.Duration, options MirrorOptions, statusDir string) *MirrorDaemon {
+	d := &MirrorDaemon{
+		engine:   engine,
+		store:    store,
+		pairs:    pairs,
+		interval: interval,
+		options:  options,
+		status: MirrorDaemonStatus{
+			PID:      os.Getpid(),
+			Interval: interval.String(),
+			Pairs:    make([]MirrorPairStatus, len(pairs)),
+		},
+	}
+	for i, pair := range pairs {
+		d.status.Pairs[i].Pair = pair.String()
+		d.status.Pairs[i].Schedule = pair.Schedule
+	}
+	if statusDir != "" {
+		d.statusPath = filepath.Join(statusDir, MirrorDaemonStatusFile)
+	}
+	return d
+}
+
+// Run 按各对的 cron 表达式或固定间隔持续镜像，直到 ctx 取消；按间隔运行的对立即执行第一轮，按 cron 运行的对等到下一个匹配的时间。
+// ctx 取消后不会中断正在进行的一轮，而是等它完成、保存镜像状态后退出，避免镜像关系丢失导致重复创建。
+func (d *MirrorDaemon) Run(ctx context.Context) error {
+	if len(d.pairs) == 0 {
+		return fmt.Errorf("no provider pairs to mirror")
+	}
+	schedules := make([]cron.Schedule, len(d.pairs))
+	for i, pair := range d.pairs {
+		if pair.Schedule == "" {
+			if d.interval <= 0 {
+				return fmt.Errorf("interval must be positive")
+			}
+			continue
+		}
+		schedule, err := ParseMirrorSchedule(pair.Schedule)
+		if err != nil {
+			return fmt.Errorf("invalid schedule for pair %s: %w", pair, err)
+		}
+		schedules[i] = schedule
+	}
+
+	now := time.Now()
+	next := make([]time.Time, len(d.pairs))
+	d.mu.Lock()
+	d.status.Running = true
+	d.status.StartedAt = now
+	d.status.StoppedAt = nil
+	for i := range d.pairs {
+		next[i] = now
+		if schedules[i] != nil {
+			next[i] = schedules[i].Next(now)
+		}
+		d.status.Pairs[i].NextRunAt = next[i]
+	}
+	d.mu.Unlock()
+	d.writeStatus()
+	log.Info().Dur("interval", d.interval).Int("pairs", len(d.pairs)).Msg("镜像守护进程已启动")
+
+	for {
+		if ctx.Err() != nil {
+			d.stop()
+			return nil
+		}
+		if wait := time.Until(slices.MinFunc(next, time.Time.Compare)); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				d.stop()
+				return nil
+			case <-timer.C:
+			}
+		}
+		d.runDue(context.WithoutCancel(ctx), ctx, schedules, next)
+	}
+}
+
+// runDue 依次镜像到期的 Provider 对并计算其下次运行时间；stop 取消后不再开始下一对
+func (d *MirrorDaemon) runDue(ctx, stop context.Context, schedules []cron.Schedule, next []time.Time) {
+	for i, pair := range d.pairs {
+		if stop.Err() != nil {
+			return
+		}
+		if time.Now().Before(next[i]) {
+			continue
+		}
+		opts := d.options
+		opts.Left, opts.Right = pair.Left, pair.Right
+		if pair.Conflict != "" {
+			opts.ConflictResolve = pair.Conflict
+		}
+		opts.Filter = pair.Filter
+		opts.FieldMap = pair.FieldMap
+		opts.Source, opts.MirrorEdits = pair.Source, pair.MirrorEdits
+		if pair.OnDelete != "" {
+			opts.OnDelete = pair.OnDelete
+		}
+		result, err := d.engine.Mirror(ctx, d.store, opts)
+
+		d.mu.Lock()
+		status := &d.status.Pairs[i]
+		status.Runs++
+		status.LastRunAt = time.Now()
+		if schedules[i] != nil {
+			next[i] = schedules[i].Next(status.LastRunAt)
+		} else {
+			next[i] = status.LastRunAt.Add(d.interval)
+		}
+		status.NextRunAt = next[i]
+		status.LastResult = result
+		status.LastError = ""
+		if err != nil {
+			status.Failures++
+			status.LastError = err.Error()
+			log.Error().Err(err).Str("pair", pair.String()).Msg("镜像失败")
+		}
+		snapshot := *status
+		d.mu.Unlock()
+
+		d.writeStatus()
+		if d.OnRun != nil {
+			d.OnRun(snapshot)
+		}
+	}
+}
+
+func (d *MirrorDaemon) stop() {
+	now := time.Now()
+	d.mu.Lock()
+	d.status.Running = false
+	d.status.StoppedAt = &now
+	for i := range d.status.Pairs {
+		d.status.Pairs[i].NextRunAt = time.Time{}
+	}
+	d.mu.Unlock()
+	d.writeStatus()
+	log.Info().Msg("镜像守护进程已停止")
+}
+
+// Status 返回当前运行状态的副本
+func (d *MirrorDaemon) Status() MirrorDaemonStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := d.status
+	status.Pairs = append([]MirrorPairStatus(nil), d.status.Pairs...)
+	return status
+}
+
+func (d *MirrorDaemon) writeStatus() {
+	if d.statusPath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(d.Status(), "", "  ")
+	if err == nil {
+		err = os.WriteFile(d.statusPath, data, 0o644)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("path", d.statusPath).Msg("写入镜像守护进程状态失败")
+	}
+}
+
+// LoadMirrorDaemonStatus 读取 statusDir 中守护进程写入的状态，不存在时返回 nil
+func LoadMirrorDaemonStatus(statusDir string) (*MirrorDaemonStatus, error) {
+	data, err := os.ReadFile(filepath.Join(statusDir, MirrorDaemonStatusFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read mirror daemon status: %w", err)
+	}
+	var status MirrorDaemonStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mirror daemon status: %w", err)
+	}
+	return &status, nil
+}
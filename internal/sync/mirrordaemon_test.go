@@ -0,0 +1,95 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseMirrorPair(t *testing.T) {
+	pair, err := ParseMirrorPair(" todo : ms ", func(name string) string {
+		return map[string]string{"todo": "todoist", "ms": "microsoft"}[name]
+	})
+	if err != nil || pair != (MirrorPair{Left: "todoist", Right: "microsoft"}) {
+		t.Fatalf("unexpected pair %+v, err %v", pair, err)
+	}
+	for _, value := range []string{"todoist", "todoist:", ":notion", "todoist:todoist"} {
+		if _, err := ParseMirrorPair(value, nil); err == nil {
+			t.Fatalf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestMirrorDaemonRunsUntilCancelled(t *testing.T) {
+	engine, left, _, store := newMirrorTestEngine(t)
+	dir := t.TempDir()
+	daemon := NewMirrorDaemon(engine, store, []MirrorPair{{Left: "todoist", Right: "notion"}}, 10*time.Millisecond, MirrorOptions{}, dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	daemon.OnRun = func(status MirrorPairStatus) {
+		runs++
+		if runs == 2 {
+			cancel()
+		}
+	}
+	if err := daemon.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if runs != 2 {
+		t.Fatalf("expected the daemon to stop after the second run, got %d runs", runs)
+	}
+	if mockTask(t, left, "inbox-l", "Call Bob") == nil {
+		t.Fatal("the first run should mirror tasks")
+	}
+
+	status, err := LoadMirrorDaemonStatus(dir)
+	if err != nil || status == nil {
+		t.Fatalf("LoadMirrorDaemonStatus: %+v, %v", status, err)
+	}
+	if status.Running || status.StoppedAt == nil || len(status.Pairs) != 1 || status.Pairs[0].Runs != 2 {
+		t.Fatalf("unexpected stopped status: %+v", status)
+	}
+	if status.Pairs[0].LastResult == nil || status.Pairs[0].LastError != "" {
+		t.Fatalf("expected a successful last result: %+v", status.Pairs[0])
+	}
+
+	if err := NewMirrorDaemon(engine, store, nil, time.Minute, MirrorOptions{}, "").Run(context.Background()); err == nil {
+		t.Fatal("a daemon without pairs should fail to start")
+	}
+}
+
+func TestMirrorDaemonPairSchedule(t *testing.T) {
+	engine, _, _, store := newMirrorTestEngine(t)
+	// cron 的最小粒度为 1 秒，@every 对齐到整秒
+	pairs := []MirrorPair{{Left: "todoist", Right: "notion", Schedule: "@every 1s"}}
+	// 所有对都按 cron 运行时不需要间隔
+	daemon := NewMirrorDaemon(engine, store, pairs, 0, MirrorOptions{}, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := time.Now()
+	var run MirrorPairStatus
+	daemon.OnRun = func(status MirrorPairStatus) {
+		run = status
+		cancel()
+	}
+	if err := daemon.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if run.Runs != 1 || run.LastRunAt.Before(started.Truncate(time.Second).Add(time.Second)) {
+		t.Fatalf("scheduled pairs should wait for their first slot: %+v (started %s)", run, started)
+	}
+	if run.Schedule != "@every 1s" || !run.NextRunAt.Equal(run.LastRunAt.Truncate(time.Second).Add(time.Second)) {
+		t.Fatalf("next run should follow the pair schedule: %+v", run)
+	}
+
+	pairs[0].Schedule = "every weekday"
+	if err := NewMirrorDaemon(engine, store, pairs, time.Minute, MirrorOptions{}, "").Run(context.Background()); err == nil {
+		t.Fatal("an invalid schedule should fail to start")
+	}
+	pairs[0].Schedule = ""
+	if err := NewMirrorDaemon(engine, store, pairs, 0, MirrorOptions{}, "").Run(context.Background()); err == nil {
+		t.Fatal("pairs without a schedule need a positive interval")
+	}
+}
@@ -0,0 +1,288 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// MirrorValuePair 两侧等价的一对字段值
+type MirrorValuePair struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+// MirrorFieldMap 一对 Provider 之间的字段值映射，用于两侧取值范围不同的字段。
+// 写入另一侧时按顺序取第一条匹配的映射，没有匹配时原样写入；另一侧现有的值与写入值等价时保持不变，
+// 因此多对一的映射（如 urgent、high 都写为 high）在往返后不会覆盖原值。
+type MirrorFieldMap struct {
+	// Left、Right 映射中 Left、Right 值所属的 Provider；与镜像方向相反时自动互换
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	// Priority 优先级：none、low、medium、high、urgent 或 0-4
+	Priority []MirrorValuePair `json:"priority,omitempty"`
+	// Status 状态：todo、in_progress、completed、cancelled、deferred
+	Status []MirrorValuePair `json:"status,omitempty"`
+	Tags   []MirrorValuePair `json:"tags,omitempty"`
+	// Lists 列表/项目名称，新建任务时据此选择另一侧的列表，初次配对时据此匹配同一列表
+	Lists []MirrorValuePair `json:"lists,omitempty"`
+}
+
+// mirrorPriorityStorage 各 Provider 无法原样保存的优先级及其实际保存的值，用于生成默认映射
+var mirrorPriorityStorage = map[string]map[model.Priority]model.Priority{
+	// importance 只有 low、normal、high 三档
+	"microsoft": {model.PriorityUrgent: model.PriorityHigh, model.PriorityNone: model.PriorityLow},
+	// priority 最低为 1
+	"todoist": {model.PriorityNone: model.PriorityLow},
+}
+
+// DefaultMirrorFieldMap 一对 Provider 的默认字段映射：按两侧 Provider 能保存的优先级生成，
+// 使一侧特有的优先级（如 Todoist 的 urgent）不会在往返 Microsoft To Do 后降级
+func DefaultMirrorFieldMap(left, right string) MirrorFieldMap {
+	fm := MirrorFieldMap{Left: left, Right: right}
+	stored := func(provider string, p model.Priority) model.Priority {
+		if value, ok := mirrorPriorityStorage[provider][p]; ok {
+			return value
+		}
+		return p
+	}
+	if mirrorPriorityStorage[left] == nil && mirrorPriorityStorage[right] == nil {
+		return fm
+	}
+	// 两侧都能原样保存的值在前，反向写入时优先原样写入
+	seen := make(map[MirrorValuePair]bool)
+	for _, exact := range []bool{true, false} {
+		for p := model.PriorityNone; p <= model.PriorityUrgent; p++ {
+			l, r := stored(left, p), stored(right, p)
+			pair := MirrorValuePair{Left: priorityName(l), Right: priorityName(r)}
+			if (l == p && r == p) != exact || seen[pair] {
+				continue
+			}
+			seen[pair] = true
+			fm.Priority = append(fm.Priority, pair)
+		}
+	}
+	return fm
+}
+
+// swapped 互换两侧
+func (f MirrorFieldMap) swapped() MirrorFieldMap {
+	swap := func(pairs []MirrorValuePair) []MirrorValuePair {
+		out := make([]MirrorValuePair, len(pairs))
+		for i, pair := range pairs {
+			out[i] = MirrorValuePair{Left: pair.Right, Right: pair.Left}
+		}
+		return out
+	}
+	return MirrorFieldMap{
+		Left: f.Right, Right: f.Left,
+		Priority: swap(f.Priority), Status: swap(f.Status), Tags: swap(f.Tags), Lists: swap(f.Lists),
+	}
+}
+
+// valueMap 单个字段的映射，值比较忽略大小写
+type valueMap []MirrorValuePair
+
+// translate 将一侧的值转换为另一侧的值，toRight 为 true 表示 Left → Right
+func (v valueMap) translate(value string, toRight bool) string {
+	for _, pair := range v {
+		from, to := pair.Right, pair.Left
+		if toRight {
+			from, to = pair.Left, pair.Right
+		}
+		if strings.EqualFold(from, value) {
+			return to
+		}
+	}
+	return value
+}
+
+// equivalent Left 侧的值与 Right 侧的值是否等价
+func (v valueMap) equivalent(left, right string) bool {
+	if strings.EqualFold(left, right) {
+		return true
+	}
+	for _, pair := range v {
+		if strings.EqualFold(pair.Left, left) && strings.EqualFold(pair.Right, right) {
+			return true
+		}
+	}
+	return false
+}
+
+// write 返回写入目标侧的值：现有值与来源值等价时保持现有值，否则转换来源值
+func (v valueMap) write(value, existing string, hasExisting, toRight bool) string {
+	if hasExisting {
+		left, right := value, existing
+		if !toRight {
+			left, right = existing, value
+		}
+		if v.equivalent(left, right) {
+			return existing
+		}
+	}
+	return v.translate(value, toRight)
+}
+
+// mirrorFieldMapper 本次镜像使用的字段映射：配置的映射在前，默认映射在后
+type mirrorFieldMapper struct {
+	priority valueMap
+	status   valueMap
+	tags     valueMap
+	lists    valueMap
+}
+
+// newMirrorFieldMapper 合并配置的映射与默认映射，并规范化优先级与状态的取值
+func newMirrorFieldMapper(configured *MirrorFieldMap, left, right string) (*mirrorFieldMapper, error) {
+	maps := []MirrorFieldMap{DefaultMirrorFieldMap(left, right)}
+	if configured != nil {
+		fm := *configured
+		if fm.Left == right || fm.Right == left {
+			fm = fm.swapped()
+		}
+		maps = append([]MirrorFieldMap{fm}, maps...)
+	}
+
+	mapper := &mirrorFieldMapper{}
+	for _, fm := range maps {
+		for _, pair := range fm.Priority {
+			l, err := normalizePriorityValue(pair.Left)
+			if err != nil {
+				return nil, err
+			}
+			r, err := normalizePriorityValue(pair.Right)
+			if err != nil {
+				return nil, err
+			}
+			mapper.priority = append(mapper.priority, MirrorValuePair{Left: l, Right: r})
+		}
+		for _, pair := range fm.Status {
+			pair = MirrorValuePair{Left: strings.ToLower(strings.TrimSpace(pair.Left)), Right: strings.ToLower(strings.TrimSpace(pair.Right))}
+			for _, value := range []string{pair.Left, pair.Right} {
+				if !validTaskStatus(value) {
+					return nil, fmt.Errorf("invalid status %q in field map", value)
+				}
+			}
+			mapper.status = append(mapper.status, pair)
+		}
+		mapper.tags = append(mapper.tags, fm.Tags...)
+		mapper.lists = append(mapper.lists, fm.Lists...)
+	}
+	return mapper, nil
+}
+
+// apply 将 copied（来源侧内容）转换为目标侧的值；existing 为目标侧现有任务，新建时为 nil
+func (f *mirrorFieldMapper) apply(copied, existing *model.Task, toRight bool) {
+	hasExisting := existing != nil
+	var current model.Task
+	if hasExisting {
+		current = *existing
+	}
+
+	priority := f.priority.write(priorityName(copied.Priority), priorityName(current.Priority), hasExisting, toRight)
+	copied.Priority = parsePriorityName(priority)
+
+	status := model.TaskStatus(f.status.write(string(copied.Status), string(current.Status), hasExisting, toRight))
+	if status != copied.Status {
+		copied.Status = status
+		switch {
+		case hasExisting && status == current.Status:
+			copied.CompletedAt = current.CompletedAt
+		case status != model.StatusCompleted:
+			copied.CompletedAt = nil
+		}
+	}
+
+	tags := make([]string, 0, len(copied.Tags))
+	for _, tag := range copied.Tags {
+		written := f.tags.translate(tag, toRight)
+		for _, existingTag := range current.Tags {
+			left, right := tag, existingTag
+			if !toRight {
+				left, right = existingTag, tag
+			}
+			if f.tags.equivalent(left, right) {
+				written = existingTag
+				break
+			}
+		}
+		tags = append(tags, written)
+	}
+	copied.Tags = tags
+}
+
+// toSide 将 source 侧的任务内容转换为写入 target 侧的副本；existing 为 target 侧现有任务，新建时为 nil
+func (m *mirrorRun) toSide(source *mirrorSide, task *model.Task, target *mirrorSide, existing *model.Task) *model.Task {
+	base := existing
+	if base == nil {
+		base = &model.Task{}
+	}
+	copied := mirroredCopy(task, base)
+	if source != target {
+		m.fields.apply(copied, existing, target == m.right)
+	}
+	return copied
+}
+
+// sameContent Left、Right 两侧任务的镜像字段在映射后是否一致
+func (m *mirrorRun) sameContent(leftTask, rightTask *model.Task) bool {
+	return sameMirroredContent(m.toSide(m.left, leftTask, m.right, rightTask), rightTask)
+}
+
+// listNameOn side 侧任务所在列表在 target 侧对应的名称
+func (m *mirrorRun) listNameOn(side *mirrorSide, task *model.Task, target *mirrorSide) string {
+	name := side.listName(task)
+	if side == target {
+		return name
+	}
+	return m.fields.lists.translate(name, target == m.right)
+}
+
+func priorityName(p model.Priority) string {
+	switch p {
+	case model.PriorityLow:
+		return "low"
+	case model.PriorityMedium:
+		return "medium"
+	case model.PriorityHigh:
+		return "high"
+	case model.PriorityUrgent:
+		return "urgent"
+	default:
+		return "none"
+	}
+}
+
+func parsePriorityName(name string) model.Priority {
+	for p := model.PriorityNone; p <= model.PriorityUrgent; p++ {
+		if priorityName(p) == name {
+			return p
+		}
+	}
+	return model.PriorityNone
+}
+
+// normalizePriorityValue 将 none、low 等名称或 0-4 规范化为名称
+func normalizePriorityValue(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 4 {
+		return priorityName(model.PriorityFromInt(n)), nil
+	}
+	for p := model.PriorityNone; p <= model.PriorityUrgent; p++ {
+		if priorityName(p) == value {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("invalid priority %q in field map", value)
+}
+
+func validTaskStatus(value string) bool {
+	switch model.TaskStatus(value) {
+	case model.StatusTodo, model.StatusInProgress, model.StatusCompleted, model.StatusCancelled, model.StatusDeferred:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// MirrorFilter 限定一对 Provider 镜像的任务范围；各条件同时满足才镜像，未设置的条件不限制
+type MirrorFilter struct {
+	// Tags 只镜像带有其中任一标签的任务（不区分大小写）
+	Tags []string `json:"tags,omitempty"`
+	// Lists 只镜像这些列表/项目中的任务，按名称匹配（不区分大小写）
+	Lists []string `json:"lists,omitempty"`
+	// ExcludeCompletedOlderThan 排除完成时间早于该时长之前的任务，0 表示不排除
+	ExcludeCompletedOlderThan time.Duration `json:"exclude_completed_older_than,omitempty"`
+}
+
+// Match 任务是否在镜像范围内；listName 为任务所在列表的名称，now 为本次镜像的开始时间。f 为 nil 时总是返回 true
+func (f *MirrorFilter) Match(task *model.Task, listName string, now time.Time) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Tags) > 0 && !containsFold(f.Tags, task.Tags...) {
+		return false
+	}
+	if len(f.Lists) > 0 && !containsFold(f.Lists, listName) {
+		return false
+	}
+	if f.ExcludeCompletedOlderThan > 0 && task.Status == model.StatusCompleted {
+		completedAt := task.UpdatedAt
+		if task.CompletedAt != nil {
+			completedAt = *task.CompletedAt
+		}
+		if !completedAt.IsZero() && completedAt.Before(now.Add(-f.ExcludeCompletedOlderThan)) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold values 中是否有任一值在 set 中（忽略大小写与首尾空白）
+func containsFold(set []string, values ...string) bool {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		for _, item := range set {
+			if strings.EqualFold(strings.TrimSpace(item), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inScope 任务是否符合本次镜像的过滤规则；规则中的标签、列表名按任一侧的取值匹配，与字段映射的方向无关
+func (m *mirrorRun) inScope(side *mirrorSide, task *model.Task) bool {
+	if m.opts.Filter == nil {
+		return true
+	}
+	if m.opts.Filter.Match(task, side.listName(task), m.result.LastSyncTime) {
+		return true
+	}
+	other := m.left
+	if side == m.left {
+		other = m.right
+	}
+	return m.opts.Filter.Match(m.toSide(side, task, other, nil), m.listNameOn(side, task, other), m.result.LastSyncTime)
+}
+
+// linkInScope 已建立的镜像关系是否仍在范围内：任一侧符合规则即保留，使一侧移出范围的修改（如删除标签）
+// 先同步到另一侧，两侧都不符合时才停止跟踪，而不是在另一侧重新创建
+func (m *mirrorRun) linkInScope(leftTask, rightTask *model.Task) bool {
+	return (leftTask != nil && m.inScope(m.left, leftTask)) || (rightTask != nil && m.inScope(m.right, rightTask))
+}
@@ -0,0 +1,217 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+// MirrorJournalFileName 镜像变更日志文件名（位于存储目录）
+const MirrorJournalFileName = "mirror_journal.jsonl"
+
+// 镜像变更的操作类型
+const (
+	MirrorOpCreate   = "create"
+	MirrorOpUpdate   = "update"
+	MirrorOpComplete = "complete"
+	MirrorOpDelete   = "delete"
+	MirrorOpArchive  = "archive"
+)
+
+// MirrorChange 镜像对某个 Provider 应用的一次变更，Before、After 为变更前后的任务（新建时 Before 为 nil，删除时 After 为 nil）
+type MirrorChange struct {
+	RunID string    `json:"run_id"`
+	At    time.Time `json:"at"`
+	// Left、Right 所属的镜像对
+	Left     string      `json:"left"`
+	Right    string      `json:"right"`
+	Provider string      `json:"provider"`
+	Op       string      `json:"op"`
+	ListID   string      `json:"list_id"`
+	TaskID   string      `json:"task_id"`
+	Before   *model.Task `json:"before,omitempty"`
+	After    *model.Task `json:"after,omitempty"`
+	// UndoOf、Reverts 撤销操作产生的变更记录被撤销的运行 ID 与其中的原变更（见 key）
+	UndoOf  string `json:"undo_of,omitempty"`
+	Reverts string `json:"reverts,omitempty"`
+}
+
+// key 在一次运行中标识变更
+func (c MirrorChange) key() string {
+	return c.Provider + "/" + c.Op + "/" + c.TaskID
+}
+
+// MirrorJournalRun 变更日志中一次运行的摘要
+type MirrorJournalRun struct {
+	RunID     string    `json:"run_id"`
+	Left      string    `json:"left"`
+	Right     string    `json:"right"`
+	StartedAt time.Time `json:"started_at"`
+	Changes   int       `json:"changes"`
+	// UndoOf 撤销运行所撤销的运行 ID；UndoneBy 撤销了该运行的运行 ID
+	UndoOf   string `json:"undo_of,omitempty"`
+	UndoneBy string `json:"undone_by,omitempty"`
+}
+
+// MirrorJournal 只追加的镜像变更日志
+type MirrorJournal interface {
+	Append(ctx context.Context, change MirrorChange) error
+	// Changes 返回全部变更，按写入顺序排列
+	Changes(ctx context.Context) ([]MirrorChange, error)
+}
+
+// FileMirrorJournal 以 JSON Lines 保存的变更日志，每行一条变更
+type FileMirrorJournal struct {
+	mu       sync.Mutex
+	filePath string
+}
+
+// NewFileMirrorJournal 创建变更日志，保存在 basePath/mirror_journal.jsonl
+func NewFileMirrorJournal(basePath string) (*FileMirrorJournal, error) {
+	if err := os.MkdirAll(basePath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mirror journal dir: %w", err)
+	}
+	return &FileMirrorJournal{filePath: filepath.Join(basePath, MirrorJournalFileName)}, nil
+}
+
+// Append 追加一条变更
+func (j *FileMirrorJournal) Append(_ context.Context, change MirrorChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mirror change: %w", err)
+	}
+	// 启用存储加密时每行单独加密，保持只追加
+	line, err := storecrypt.SealString(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt mirror change: %w", err)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.OpenFile(j.filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open mirror journal: %w", err)
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write mirror journal: %w", err)
+	}
+	return f.Close()
+}
+
+// Changes 读取全部变更；无法解析的行（如写入中断留下的半行）被跳过
+func (j *FileMirrorJournal) Changes(_ context.Context) ([]MirrorChange, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	f, err := os.Open(j.filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mirror journal: %w", err)
+	}
+	defer f.Close()
+
+	var changes []MirrorChange
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line, err := storecrypt.OpenString(scanner.Text())
+		if errors.Is(err, storecrypt.ErrLocked) {
+			return nil, fmt.Errorf("failed to read mirror journal: %w", err)
+		}
+		var change MirrorChange
+		if err == nil {
+			err = json.Unmarshal([]byte(line), &change)
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("file", j.filePath).Msg("跳过无法解析的镜像变更记录")
+			continue
+		}
+		changes = append(changes, change)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mirror journal: %w", err)
+	}
+	return changes, nil
+}
+
+// MirrorJournalRuns 汇总变更日志中的运行，最近的在前
+func MirrorJournalRuns(ctx context.Context, journal MirrorJournal) ([]MirrorJournalRun, error) {
+	changes, err := journal.Changes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*MirrorJournalRun)
+	var order []string
+	for _, change := range changes {
+		run, ok := byID[change.RunID]
+		if !ok {
+			run = &MirrorJournalRun{RunID: change.RunID, Left: change.Left, Right: change.Right, StartedAt: change.At, UndoOf: change.UndoOf}
+			byID[change.RunID] = run
+			order = append(order, change.RunID)
+		}
+		run.Changes++
+	}
+	for _, id := range order {
+		run := byID[id]
+		if undone, ok := byID[run.UndoOf]; ok {
+			undone.UndoneBy = run.RunID
+		}
+	}
+	runs := make([]MirrorJournalRun, 0, len(order))
+	for _, id := range order {
+		runs = append(runs, *byID[id])
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+	return runs, nil
+}
+
+// newMirrorRunID 生成运行 ID：开始时间加随机后缀
+func newMirrorRunID(startedAt time.Time) string {
+	suffix := make([]byte, 2)
+	_, _ = rand.Read(suffix)
+	return startedAt.UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// record 将本次镜像对 side 应用的变更写入日志；写入失败只记录警告，不影响镜像
+func (m *mirrorRun) record(ctx context.Context, side *mirrorSide, op, listID string, before, after *model.Task) {
+	if m.opts.Journal == nil || m.opts.DryRun {
+		return
+	}
+	task := after
+	if task == nil {
+		task = before
+	}
+	change := MirrorChange{
+		RunID:    m.result.RunID,
+		At:       time.Now(),
+		Left:     m.left.name,
+		Right:    m.right.name,
+		Provider: side.name,
+		Op:       op,
+		ListID:   listID,
+		TaskID:   rawTaskID(task),
+		Before:   before,
+		After:    after,
+	}
+	if err := m.opts.Journal.Append(context.WithoutCancel(ctx), change); err != nil {
+		log.Warn().Err(err).Str("provider", side.name).Str("op", op).Msg("写入镜像变更日志失败")
+	}
+}
@@ -0,0 +1,165 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// 单向镜像时镜像侧（非来源一侧）修改的处理方式
+const (
+	// MirrorEditsOverwrite 以来源的内容覆盖镜像侧的修改，被删除的副本重新创建
+	MirrorEditsOverwrite = "overwrite"
+	// MirrorEditsFlag 保留镜像侧的修改并排队等待处理：sync resolve 选择来源时覆盖，选择镜像侧时接受修改
+	MirrorEditsFlag = "flag"
+)
+
+// normalizeOneWay 校验单向镜像选项：Source 必须是 Left 或 Right，MirrorEdits 为空时使用 overwrite
+func normalizeOneWay(opts *MirrorOptions) error {
+	if opts.Source == "" {
+		return nil
+	}
+	if opts.Source != opts.Left && opts.Source != opts.Right {
+		return fmt.Errorf("one-way source must be %s or %s, got %q", opts.Left, opts.Right, opts.Source)
+	}
+	switch value := strings.ToLower(strings.TrimSpace(opts.MirrorEdits)); value {
+	case "":
+		opts.MirrorEdits = MirrorEditsOverwrite
+	case MirrorEditsOverwrite, MirrorEditsFlag:
+		opts.MirrorEdits = value
+	default:
+		return fmt.Errorf("unknown mirror edits mode %q", opts.MirrorEdits)
+	}
+	return nil
+}
+
+func (m *mirrorRun) oneWay() bool {
+	return m.opts.Source != ""
+}
+
+// oneWaySides 单向镜像的来源与镜像侧
+func (m *mirrorRun) oneWaySides() (source, target *mirrorSide) {
+	if m.opts.Source == m.right.name {
+		return m.right, m.left
+	}
+	return m.left, m.right
+}
+
+// canWrite 是否可以向 side 写入变更：单向镜像时只写入镜像侧
+func (m *mirrorRun) canWrite(side *mirrorSide) bool {
+	if !m.oneWay() {
+		return true
+	}
+	_, target := m.oneWaySides()
+	return side == target
+}
+
+// reconcileOneWay 单向镜像时两侧内容不一致：总是以来源为准，镜像侧自上次同步后的修改按 MirrorEdits 覆盖或标记。
+// countConflict 为 false 用于初次配对，此时镜像侧没有“修改”可言，直接以来源为准
+func (m *mirrorRun) reconcileOneWay(ctx context.Context, link MirrorLink, leftTask, rightTask *model.Task, countConflict bool) MirrorLink {
+	source, target := m.oneWaySides()
+	sourceTask, targetTask := leftTask, rightTask
+	if source == m.right {
+		sourceTask, targetTask = rightTask, leftTask
+	}
+
+	if countConflict && taskFingerprint(targetTask) != link[target.name].Hash {
+		if m.opts.MirrorEdits == MirrorEditsFlag {
+			keep, fields, ok := m.flagEdit(link, source, sourceTask, target, targetTask)
+			if !ok {
+				return link
+			}
+			if len(fields) > 0 {
+				// 逐字段选择：接受部分镜像侧的修改，其余字段以来源为准，只写入镜像侧
+				return m.applyChoices(ctx, link, leftTask, rightTask, keep, fields)
+			}
+			if keep == target {
+				// 接受镜像侧的修改：以当前内容为基准，来源再次修改时照常写入
+				return m.link(leftTask, rightTask)
+			}
+		}
+		m.result.Overwritten++
+		log.Info().Str("task", targetTask.Title).Str("provider", target.name).Msg("以来源内容覆盖镜像侧的修改")
+	}
+
+	updated, ok := m.update(ctx, source, sourceTask, target, targetTask)
+	if !ok {
+		return link
+	}
+	if source == m.left {
+		return m.link(sourceTask, updated)
+	}
+	return m.link(updated, sourceTask)
+}
+
+// propagateDeleteOneWay 单向镜像时一侧任务已删除：来源删除时一并删除副本；副本被删除时重新创建或标记
+func (m *mirrorRun) propagateDeleteOneWay(ctx context.Context, link MirrorLink, survivor *mirrorSide, task *model.Task, deleted *mirrorSide) (MirrorLink, bool) {
+	source, target := m.oneWaySides()
+	ref := link[survivor.name]
+	m.markLinked(survivor, ref.TaskID)
+
+	if deleted == source {
+		if m.opts.MirrorEdits == MirrorEditsFlag && taskFingerprint(task) != ref.Hash {
+			log.Warn().Str("task", task.Title).Str("provider", target.name).Msg("来源任务已删除，但镜像侧的副本有修改，保留副本并停止镜像")
+			m.bury(link, source, false)
+			return nil, false
+		}
+		return m.deleteMirrored(ctx, link, survivor, task, deleted)
+	}
+
+	if m.opts.MirrorEdits == MirrorEditsFlag {
+		keep, _, ok := m.flagEdit(link, source, task, target, nil)
+		if !ok {
+			return link, true
+		}
+		if keep == target {
+			// 接受删除：保留关系与处理结果，不再重新创建，直到来源任务也被删除
+			m.pending = append(m.pending, m.queued[mirrorConflictID(link)])
+			return link, true
+		}
+	}
+	if task.Status == model.StatusCompleted {
+		// 已完成的任务不再重新创建
+		m.bury(link, target, false)
+		return nil, false
+	}
+	m.result.Overwritten++
+	log.Info().Str("task", task.Title).Str("provider", target.name).Msg("镜像侧的副本已删除，重新创建")
+	m.bury(MirrorLink{target.name: link[target.name]}, target, false)
+	return m.create(ctx, source, task, target)
+}
+
+// flagEdit 返回对镜像侧修改的处理结果（sync resolve 选择的一侧及逐字段的选择）；尚未处理时排队，两侧都不修改。
+// targetTask 为 nil 表示副本已被删除
+func (m *mirrorRun) flagEdit(link MirrorLink, source *mirrorSide, sourceTask *model.Task, target *mirrorSide, targetTask *model.Task) (*mirrorSide, map[string]string, bool) {
+	id := mirrorConflictID(link)
+	previous, queued := m.queued[id]
+	switch previous.Resolution {
+	case source.name:
+		return source, previous.Fields, true
+	case target.name:
+		return target, previous.Fields, true
+	}
+
+	conflict := MirrorConflict{
+		ID:         id,
+		DetectedAt: time.Now(),
+		Tasks:      map[string]MirrorConflictTask{source.name: conflictTask(sourceTask)},
+	}
+	if targetTask != nil {
+		conflict.Tasks[target.name] = conflictTask(targetTask)
+	}
+	if queued {
+		conflict.DetectedAt = previous.DetectedAt
+	}
+	m.pending = append(m.pending, conflict)
+	m.result.PendingConflicts++
+	log.Warn().Str("task", sourceTask.Title).Str("provider", target.name).Str("conflict", id).Msg("镜像侧修改了单向镜像的任务，等待处理")
+	return nil, nil, false
+}
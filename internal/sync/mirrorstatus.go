@@ -0,0 +1,114 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MirrorRun 一对 Provider 最近一次镜像的记录，供 sync status 与 sync_status 查询
+type MirrorRun struct {
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Result     *MirrorResult `json:"result,omitempty"`
+	// Error 镜像中止的原因；单个任务的失败记录在 Result.Errors 中
+	Error string `json:"error,omitempty"`
+	// Runs、Failures 累计镜像次数与其中失败（中止或有任务失败）的次数
+	Runs          int       `json:"runs"`
+	Failures      int       `json:"failures"`
+	LastSuccessAt time.Time `json:"last_success_at,omitempty"`
+}
+
+// Transferred 应用到两侧的变更总数
+func (r *MirrorResult) Transferred() int {
+	if r == nil {
+		return 0
+	}
+	total := 0
+	for _, counts := range []MirrorCounts{r.ToLeft, r.ToRight} {
+		total += counts.Created + counts.Updated + counts.Completed + counts.Deleted
+	}
+	return total
+}
+
+// MirrorPairReport 一对 Provider 的镜像状态汇总
+type MirrorPairReport struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	// Source 最近一次为单向镜像时的来源
+	Source string `json:"source,omitempty"`
+	// Links 已建立的镜像关系数
+	Links        int       `json:"links"`
+	LastSyncTime time.Time `json:"last_sync_time,omitempty"`
+	// Transferred 最近一次镜像应用到两侧的变更数
+	Transferred      int `json:"transferred"`
+	PendingConflicts int `json:"pending_conflicts"`
+	// Tombstones 保留中的墓碑数，见 MirrorTombstone
+	Tombstones int        `json:"tombstones,omitempty"`
+	Errors     []string   `json:"errors,omitempty"`
+	LastRun    *MirrorRun `json:"last_run,omitempty"`
+}
+
+// MirrorStatusReport 汇总 store 中每对 Provider 的镜像状态
+func MirrorStatusReport(ctx context.Context, store MirrorStore) ([]MirrorPairReport, error) {
+	pairs, err := store.ListMirrorPairs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]MirrorPairReport, 0, len(pairs))
+	for _, pair := range pairs {
+		state, err := store.LoadMirrorState(ctx, pair.Left, pair.Right)
+		if err != nil {
+			return nil, fmt.Errorf("load mirror state %s: %w", pair, err)
+		}
+		report := MirrorPairReport{
+			Left:             pair.Left,
+			Right:            pair.Right,
+			Links:            len(state.Links),
+			LastSyncTime:     state.LastSyncTime,
+			PendingConflicts: len(state.Conflicts),
+			Tombstones:       len(state.Tombstones),
+			LastRun:          state.LastRun,
+		}
+		if run := state.LastRun; run != nil {
+			if run.Result != nil {
+				// 沿用最近一次镜像时的方向
+				report.Left, report.Right = run.Result.Left, run.Result.Right
+				report.Source = run.Result.Source
+				report.Transferred = run.Result.Transferred()
+				for _, e := range run.Result.Errors {
+					report.Errors = append(report.Errors, e.Error)
+				}
+			}
+			if run.Error != "" {
+				report.Errors = append(report.Errors, run.Error)
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// recordMirrorRun 保存一次镜像的记录；previous 为上一次的记录，用于累计次数
+func recordMirrorRun(ctx context.Context, store MirrorStore, left, right string, startedAt time.Time, previous *MirrorRun, result *MirrorResult, err error) {
+	run := &MirrorRun{StartedAt: startedAt, FinishedAt: time.Now(), Result: result}
+	if previous != nil {
+		run.Runs, run.Failures, run.LastSuccessAt = previous.Runs, previous.Failures, previous.LastSuccessAt
+	}
+	run.Runs++
+	if err != nil {
+		run.Error = err.Error()
+	}
+	if err != nil || (result != nil && len(result.Errors) > 0) {
+		run.Failures++
+	} else {
+		run.LastSuccessAt = run.FinishedAt
+	}
+	// 镜像被取消时仍需记录
+	if saveErr := store.SaveMirrorRun(context.WithoutCancel(ctx), left, right, run); saveErr != nil {
+		log.Warn().Err(saveErr).Str("left", left).Str("right", right).Msg("保存镜像记录失败")
+	}
+}
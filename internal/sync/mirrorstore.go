@@ -0,0 +1,166 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+// MirrorRef 镜像关系中一侧的任务
+type MirrorRef struct {
+	ListID string `json:"list_id"`
+	TaskID string `json:"task_id"`
+	// Hash 上次同步时该任务镜像字段的内容指纹
+	Hash string `json:"hash"`
+	// Fields 上次同步时的镜像字段，merge 策略据此判断每个字段由哪一侧修改
+	Fields *MirrorFields `json:"fields,omitempty"`
+	// SyncedAt 该任务最近一次被镜像写入或确认一致的时间
+	SyncedAt time.Time `json:"synced_at,omitempty"`
+}
+
+// MirrorLink 互为镜像的一对任务，键为 Provider 名称
+type MirrorLink map[string]MirrorRef
+
+// MirrorState 一对 Provider 的镜像状态
+type MirrorState struct {
+	Links []MirrorLink `json:"links"`
+	// Conflicts 按 manual 策略排队、等待人工处理的冲突
+	Conflicts []MirrorConflict `json:"conflicts,omitempty"`
+	// Tombstones 因删除而结束的镜像关系，见 MirrorTombstone
+	Tombstones []MirrorTombstone `json:"tombstones,omitempty"`
+	// Cursors 支持增量读取的 Provider 下次使用的游标，键为 Provider 名称
+	Cursors      map[string]string `json:"cursors,omitempty"`
+	LastSyncTime time.Time         `json:"last_sync_time"`
+	// LastRun 最近一次镜像的记录，成功与失败都会记录
+	LastRun *MirrorRun `json:"last_run,omitempty"`
+}
+
+// MirrorStore 镜像状态存储接口；left、right 的顺序不影响读取的状态
+type MirrorStore interface {
+	LoadMirrorState(ctx context.Context, left, right string) (*MirrorState, error)
+	SaveMirrorState(ctx context.Context, left, right string, state *MirrorState) error
+	// SaveMirrorRun 只更新最近一次镜像的记录，镜像失败、状态未保存时也会调用
+	SaveMirrorRun(ctx context.Context, left, right string, run *MirrorRun) error
+	// ListMirrorPairs 列出保存了镜像状态的 Provider 对，按名称排序
+	ListMirrorPairs(ctx context.Context) ([]MirrorPair, error)
+}
+
+// FileMirrorStore 镜像状态文件存储
+type FileMirrorStore struct {
+	mu       sync.Mutex
+	filePath string
+}
+
+// NewFileMirrorStore 创建镜像状态存储，状态保存在 basePath/mirror_state.json
+func NewFileMirrorStore(basePath string) (*FileMirrorStore, error) {
+	if err := os.MkdirAll(basePath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mirror state dir: %w", err)
+	}
+	return &FileMirrorStore{filePath: filepath.Join(basePath, "mirror_state.json")}, nil
+}
+
+// LoadMirrorState 读取一对 Provider 的镜像状态，尚未同步过时返回空状态
+func (s *FileMirrorStore) LoadMirrorState(_ context.Context, left, right string) (*MirrorState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if state, ok := states[mirrorPairKey(left, right)]; ok && state != nil {
+		return state, nil
+	}
+	return &MirrorState{}, nil
+}
+
+// SaveMirrorState 保存一对 Provider 的镜像状态
+func (s *FileMirrorStore) SaveMirrorState(_ context.Context, left, right string, state *MirrorState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states, err := s.load()
+	if err != nil {
+		return err
+	}
+	states[mirrorPairKey(left, right)] = state
+	return s.write(states)
+}
+
+// SaveMirrorRun 更新一对 Provider 最近一次镜像的记录
+func (s *FileMirrorStore) SaveMirrorRun(_ context.Context, left, right string, run *MirrorRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states, err := s.load()
+	if err != nil {
+		return err
+	}
+	key := mirrorPairKey(left, right)
+	if states[key] == nil {
+		states[key] = &MirrorState{}
+	}
+	states[key].LastRun = run
+	return s.write(states)
+}
+
+// ListMirrorPairs 列出保存了镜像状态的 Provider 对
+func (s *FileMirrorStore) ListMirrorPairs(_ context.Context) ([]MirrorPair, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([]MirrorPair, 0, len(states))
+	for key := range states {
+		if left, right, ok := strings.Cut(key, "+"); ok {
+			pairs = append(pairs, MirrorPair{Left: left, Right: right})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].String() < pairs[j].String() })
+	return pairs, nil
+}
+
+func (s *FileMirrorStore) write(states map[string]*MirrorState) error {
+	bytes, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mirror state: %w", err)
+	}
+	if err := storecrypt.WriteFile(s.filePath, bytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write mirror state: %w", err)
+	}
+	return nil
+}
+
+func (s *FileMirrorStore) load() (map[string]*MirrorState, error) {
+	states := make(map[string]*MirrorState)
+	data, err := storecrypt.ReadFile(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return states, nil
+		}
+		return nil, fmt.Errorf("failed to read mirror state: %w", err)
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mirror state: %w", err)
+	}
+	return states, nil
+}
+
+// mirrorPairKey 与顺序无关的 Provider 对标识，如 notion+todoist
+func mirrorPairKey(left, right string) string {
+	pair := []string{left, right}
+	sort.Strings(pair)
+	return pair[0] + "+" + pair[1]
+}
@@ -0,0 +1,451 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+
+	// 纯 Go 实现的 SQLite 驱动，无需 cgo
+	_ "modernc.org/sqlite"
+)
+
+// mirrorSQLiteSchema 镜像状态表：mirror_pairs 每对 Provider 一行，mirror_links 每条镜像关系一行。
+// 两表中 adapter_a 总是按名称排序较小的一侧，因此 left、right 的顺序不影响读写。
+const mirrorSQLiteSchema = `
+CREATE TABLE IF NOT EXISTS mirror_pairs (
+	adapter_a      TEXT NOT NULL,
+	adapter_b      TEXT NOT NULL,
+	last_sync_time TEXT NOT NULL DEFAULT '',
+	conflicts      TEXT NOT NULL DEFAULT '[]',
+	last_run       TEXT NOT NULL DEFAULT '',
+	tombstones     TEXT NOT NULL DEFAULT '[]',
+	PRIMARY KEY (adapter_a, adapter_b)
+);
+CREATE TABLE IF NOT EXISTS mirror_links (
+	adapter_a      TEXT NOT NULL,
+	list_a         TEXT NOT NULL,
+	id_a           TEXT NOT NULL,
+	hash_a         TEXT NOT NULL,
+	fields_a       TEXT NOT NULL DEFAULT '',
+	adapter_b      TEXT NOT NULL,
+	list_b         TEXT NOT NULL,
+	id_b           TEXT NOT NULL,
+	hash_b         TEXT NOT NULL,
+	fields_b       TEXT NOT NULL DEFAULT '',
+	last_synced_at TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (adapter_a, id_a, adapter_b)
+);
+CREATE INDEX IF NOT EXISTS mirror_links_b ON mirror_links (adapter_b, id_b, adapter_a);
+CREATE TABLE IF NOT EXISTS mirror_cursors (
+	adapter_a TEXT NOT NULL,
+	adapter_b TEXT NOT NULL,
+	provider  TEXT NOT NULL,
+	cursor    TEXT NOT NULL,
+	PRIMARY KEY (adapter_a, adapter_b, provider)
+);
+`
+
+// SQLiteMirrorStore 基于 SQLite 的镜像状态存储，记录 (Provider A, 任务 ID) ↔ (Provider B, 任务 ID)、
+// 两侧的内容指纹与最近同步时间。支持多个进程同时访问（如 sync start 运行时执行 sync resolve）。
+type SQLiteMirrorStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteMirrorStore 打开 basePath/mirror_state.db；数据库为空且存在 mirror_state.json 时导入其中的镜像状态
+func NewSQLiteMirrorStore(basePath string) (*SQLiteMirrorStore, error) {
+	if err := os.MkdirAll(basePath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mirror state dir: %w", err)
+	}
+	dsn := "file:" + filepath.Join(basePath, "mirror_state.db") + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mirror state db: %w", err)
+	}
+	// SQLite 同一时间只允许一个写入者，单连接避免进程内的 SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(mirrorSQLiteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to init mirror state db: %w", err)
+	}
+
+	store := &SQLiteMirrorStore{db: db}
+	if err := store.migrate(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := store.importFileStates(basePath); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// migrate 为旧版本创建的数据库补充新增的列
+func (s *SQLiteMirrorStore) migrate() error {
+	rows, err := s.db.Query(`SELECT name FROM pragma_table_info('mirror_pairs')`)
+	if err != nil {
+		return fmt.Errorf("failed to read mirror state schema: %w", err)
+	}
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to read mirror state schema: %w", err)
+		}
+		columns[name] = true
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read mirror state schema: %w", err)
+	}
+	for _, column := range []struct{ name, definition string }{
+		{"last_run", `TEXT NOT NULL DEFAULT ''`},
+		{"tombstones", `TEXT NOT NULL DEFAULT '[]'`},
+	} {
+		if columns[column.name] {
+			continue
+		}
+		if _, err := s.db.Exec(`ALTER TABLE mirror_pairs ADD COLUMN ` + column.name + ` ` + column.definition); err != nil {
+			return fmt.Errorf("failed to migrate mirror state db: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close 关闭数据库
+func (s *SQLiteMirrorStore) Close() error {
+	return s.db.Close()
+}
+
+// LoadMirrorState 读取一对 Provider 的镜像状态，尚未同步过时返回空状态
+func (s *SQLiteMirrorStore) LoadMirrorState(ctx context.Context, left, right string) (*MirrorState, error) {
+	a, b := sortedPair(left, right)
+	state := &MirrorState{}
+
+	var lastSync, conflicts, lastRun, tombstones string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT last_sync_time, conflicts, last_run, tombstones FROM mirror_pairs WHERE adapter_a = ? AND adapter_b = ?`, a, b,
+	).Scan(&lastSync, &conflicts, &lastRun, &tombstones)
+	if err == sql.ErrNoRows {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror state: %w", err)
+	}
+	if err := openMirrorColumns(&conflicts, &lastRun, &tombstones); err != nil {
+		return nil, err
+	}
+	state.LastSyncTime = parseMirrorTime(lastSync)
+	if err := json.Unmarshal([]byte(conflicts), &state.Conflicts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mirror conflicts: %w", err)
+	}
+	if err := json.Unmarshal([]byte(tombstones), &state.Tombstones); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mirror tombstones: %w", err)
+	}
+	if state.LastRun, err = unmarshalMirrorRun(lastRun); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT list_a, id_a, hash_a, fields_a, list_b, id_b, hash_b, fields_b, last_synced_at
+		FROM mirror_links WHERE adapter_a = ? AND adapter_b = ? ORDER BY rowid`, a, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror links: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var refA, refB MirrorRef
+		var fieldsA, fieldsB, syncedAt string
+		if err := rows.Scan(&refA.ListID, &refA.TaskID, &refA.Hash, &fieldsA, &refB.ListID, &refB.TaskID, &refB.Hash, &fieldsB, &syncedAt); err != nil {
+			return nil, fmt.Errorf("failed to read mirror link: %w", err)
+		}
+		if err := openMirrorColumns(&fieldsA, &fieldsB); err != nil {
+			return nil, err
+		}
+		if refA.Fields, err = unmarshalMirrorFields(fieldsA); err != nil {
+			return nil, err
+		}
+		if refB.Fields, err = unmarshalMirrorFields(fieldsB); err != nil {
+			return nil, err
+		}
+		refA.SyncedAt = parseMirrorTime(syncedAt)
+		refB.SyncedAt = refA.SyncedAt
+		state.Links = append(state.Links, MirrorLink{a: refA, b: refB})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mirror links: %w", err)
+	}
+
+	cursorRows, err := s.db.QueryContext(ctx,
+		`SELECT provider, cursor FROM mirror_cursors WHERE adapter_a = ? AND adapter_b = ?`, a, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror cursors: %w", err)
+	}
+	defer cursorRows.Close()
+	for cursorRows.Next() {
+		var name, cursor string
+		if err := cursorRows.Scan(&name, &cursor); err != nil {
+			return nil, fmt.Errorf("failed to read mirror cursor: %w", err)
+		}
+		if state.Cursors == nil {
+			state.Cursors = make(map[string]string)
+		}
+		state.Cursors[name] = cursor
+	}
+	if err := cursorRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mirror cursors: %w", err)
+	}
+	return state, nil
+}
+
+// SaveMirrorState 在一个事务中替换一对 Provider 的镜像状态
+func (s *SQLiteMirrorStore) SaveMirrorState(ctx context.Context, left, right string, state *MirrorState) error {
+	a, b := sortedPair(left, right)
+	conflicts, err := json.Marshal(state.Conflicts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mirror conflicts: %w", err)
+	}
+	tombstones, err := json.Marshal(state.Tombstones)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mirror tombstones: %w", err)
+	}
+	lastRun, err := marshalMirrorRun(state.LastRun)
+	if err != nil {
+		return err
+	}
+	sealedConflicts, sealedTombstones := string(conflicts), string(tombstones)
+	if err := sealMirrorColumns(&sealedConflicts, &lastRun, &sealedTombstones); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin mirror state transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO mirror_pairs (adapter_a, adapter_b, last_sync_time, conflicts, last_run, tombstones) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (adapter_a, adapter_b) DO UPDATE SET
+			last_sync_time = excluded.last_sync_time, conflicts = excluded.conflicts, last_run = excluded.last_run,
+			tombstones = excluded.tombstones`,
+		a, b, formatMirrorTime(state.LastSyncTime), sealedConflicts, lastRun, sealedTombstones); err != nil {
+		return fmt.Errorf("failed to write mirror state: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mirror_cursors WHERE adapter_a = ? AND adapter_b = ?`, a, b); err != nil {
+		return fmt.Errorf("failed to write mirror cursors: %w", err)
+	}
+	for name, cursor := range state.Cursors {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO mirror_cursors (adapter_a, adapter_b, provider, cursor) VALUES (?, ?, ?, ?)`,
+			a, b, name, cursor); err != nil {
+			return fmt.Errorf("failed to write mirror cursor: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mirror_links WHERE adapter_a = ? AND adapter_b = ?`, a, b); err != nil {
+		return fmt.Errorf("failed to write mirror links: %w", err)
+	}
+	insert, err := tx.PrepareContext(ctx, `
+		INSERT OR REPLACE INTO mirror_links
+			(adapter_a, list_a, id_a, hash_a, fields_a, adapter_b, list_b, id_b, hash_b, fields_b, last_synced_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to write mirror links: %w", err)
+	}
+	defer insert.Close()
+	for _, link := range state.Links {
+		refA, okA := link[a]
+		refB, okB := link[b]
+		if !okA || !okB {
+			continue
+		}
+		syncedAt := refA.SyncedAt
+		if refB.SyncedAt.After(syncedAt) {
+			syncedAt = refB.SyncedAt
+		}
+		fieldsA, fieldsB := marshalMirrorFields(refA.Fields), marshalMirrorFields(refB.Fields)
+		if err := sealMirrorColumns(&fieldsA, &fieldsB); err != nil {
+			return err
+		}
+		if _, err := insert.ExecContext(ctx,
+			a, refA.ListID, refA.TaskID, refA.Hash, fieldsA,
+			b, refB.ListID, refB.TaskID, refB.Hash, fieldsB,
+			formatMirrorTime(syncedAt)); err != nil {
+			return fmt.Errorf("failed to write mirror link: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit mirror state: %w", err)
+	}
+	return nil
+}
+
+// SaveMirrorRun 只更新一对 Provider 最近一次镜像的记录
+func (s *SQLiteMirrorStore) SaveMirrorRun(ctx context.Context, left, right string, run *MirrorRun) error {
+	a, b := sortedPair(left, right)
+	lastRun, err := marshalMirrorRun(run)
+	if err != nil {
+		return err
+	}
+	if err := sealMirrorColumns(&lastRun); err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO mirror_pairs (adapter_a, adapter_b, last_run) VALUES (?, ?, ?)
+		ON CONFLICT (adapter_a, adapter_b) DO UPDATE SET last_run = excluded.last_run`,
+		a, b, lastRun); err != nil {
+		return fmt.Errorf("failed to write mirror run: %w", err)
+	}
+	return nil
+}
+
+// ListMirrorPairs 列出保存了镜像状态的 Provider 对
+func (s *SQLiteMirrorStore) ListMirrorPairs(ctx context.Context) ([]MirrorPair, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT adapter_a, adapter_b FROM mirror_pairs ORDER BY adapter_a, adapter_b`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror pairs: %w", err)
+	}
+	defer rows.Close()
+	var pairs []MirrorPair
+	for rows.Next() {
+		var pair MirrorPair
+		if err := rows.Scan(&pair.Left, &pair.Right); err != nil {
+			return nil, fmt.Errorf("failed to read mirror pair: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mirror pairs: %w", err)
+	}
+	return pairs, nil
+}
+
+// importFileStates 首次使用 SQLite 时导入 FileMirrorStore 保存的镜像关系，避免切换存储后重复创建任务
+func (s *SQLiteMirrorStore) importFileStates(basePath string) error {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM mirror_pairs`).Scan(&count); err != nil {
+		return fmt.Errorf("failed to read mirror state: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	fileStore := &FileMirrorStore{filePath: filepath.Join(basePath, "mirror_state.json")}
+	states, err := fileStore.load()
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(states))
+	for key := range states {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		left, right, ok := strings.Cut(key, "+")
+		if !ok || states[key] == nil {
+			continue
+		}
+		if err := s.SaveMirrorState(context.Background(), left, right, states[key]); err != nil {
+			return fmt.Errorf("import %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func sortedPair(left, right string) (string, string) {
+	if right < left {
+		return right, left
+	}
+	return left, right
+}
+
+// sealMirrorColumns 启用存储加密时加密含任务内容的列（冲突、墓碑、运行记录与字段快照）；
+// Provider、清单与任务 ID 以及内容指纹保持明文，以便按它们查询
+func sealMirrorColumns(values ...*string) error {
+	for _, value := range values {
+		if *value == "" {
+			continue
+		}
+		sealed, err := storecrypt.SealString(*value)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt mirror state: %w", err)
+		}
+		*value = sealed
+	}
+	return nil
+}
+
+// openMirrorColumns 解密 sealMirrorColumns 加密的列，未加密的旧数据原样保留
+func openMirrorColumns(values ...*string) error {
+	for _, value := range values {
+		plain, err := storecrypt.OpenString(*value)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt mirror state: %w", err)
+		}
+		*value = plain
+	}
+	return nil
+}
+
+func marshalMirrorFields(fields *MirrorFields) string {
+	if fields == nil {
+		return ""
+	}
+	data, _ := json.Marshal(fields)
+	return string(data)
+}
+
+func unmarshalMirrorFields(data string) (*MirrorFields, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var fields MirrorFields
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mirror fields: %w", err)
+	}
+	return &fields, nil
+}
+
+func marshalMirrorRun(run *MirrorRun) (string, error) {
+	if run == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal mirror run: %w", err)
+	}
+	return string(data), nil
+}
+
+func unmarshalMirrorRun(data string) (*MirrorRun, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var run MirrorRun
+	if err := json.Unmarshal([]byte(data), &run); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal mirror run: %w", err)
+	}
+	return &run, nil
+}
+
+func formatMirrorTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseMirrorTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, value)
+	return t
+}
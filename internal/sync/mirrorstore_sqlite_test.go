@@ -0,0 +1,224 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yeisme/taskbridge/internal/model"
+	"github.com/yeisme/taskbridge/internal/provider"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+func TestSQLiteMirrorStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewSQLiteMirrorStore(dir)
+	if err != nil {
+		t.Fatalf("NewSQLiteMirrorStore: %v", err)
+	}
+	ctx := context.Background()
+
+	empty, err := store.LoadMirrorState(ctx, "todoist", "notion")
+	if err != nil || len(empty.Links) != 0 || !empty.LastSyncTime.IsZero() {
+		t.Fatalf("expected empty state, got %+v (%v)", empty, err)
+	}
+
+	syncedAt := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	state := &MirrorState{
+		Links: []MirrorLink{{
+			"todoist": {ListID: "l1", TaskID: "t1", Hash: "h1", Fields: &MirrorFields{Title: "Write report"}, SyncedAt: syncedAt},
+			"notion":  {ListID: "l2", TaskID: "t2", Hash: "h2", SyncedAt: syncedAt},
+		}},
+		Conflicts:    []MirrorConflict{{ID: "abcd", Resolution: "notion"}},
+		Tombstones:   []MirrorTombstone{{Tasks: map[string]MirrorRef{"todoist": {ListID: "l1", TaskID: "t9"}}, DeletedOn: "todoist", DeletedAt: syncedAt}},
+		LastSyncTime: syncedAt,
+	}
+	if err := store.SaveMirrorState(ctx, "todoist", "notion", state); err != nil {
+		t.Fatalf("SaveMirrorState: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// 重新打开后按相反顺序读取
+	reopened, err := NewSQLiteMirrorStore(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	loaded, err := reopened.LoadMirrorState(ctx, "notion", "todoist")
+	if err != nil {
+		t.Fatalf("LoadMirrorState: %v", err)
+	}
+	if len(loaded.Links) != 1 || !loaded.LastSyncTime.Equal(syncedAt) || len(loaded.Conflicts) != 1 || loaded.Conflicts[0].Resolution != "notion" {
+		t.Fatalf("unexpected loaded state: %+v", loaded)
+	}
+	if len(loaded.Tombstones) != 1 || loaded.Tombstones[0].Tasks["todoist"].TaskID != "t9" || !loaded.Tombstones[0].DeletedAt.Equal(syncedAt) {
+		t.Fatalf("unexpected tombstones: %+v", loaded.Tombstones)
+	}
+	link := loaded.Links[0]
+	if link["todoist"].TaskID != "t1" || link["notion"].Hash != "h2" || link["todoist"].Fields == nil || link["todoist"].Fields.Title != "Write report" {
+		t.Fatalf("unexpected link: %+v", link)
+	}
+	if link["notion"].Fields != nil || !link["notion"].SyncedAt.Equal(syncedAt) {
+		t.Fatalf("unexpected right ref: %+v", link["notion"])
+	}
+
+	state.Links = nil
+	if err := reopened.SaveMirrorState(ctx, "notion", "todoist", state); err != nil {
+		t.Fatalf("SaveMirrorState: %v", err)
+	}
+	if loaded, _ = reopened.LoadMirrorState(ctx, "todoist", "notion"); len(loaded.Links) != 0 {
+		t.Fatalf("saving should replace the links of the pair: %+v", loaded.Links)
+	}
+}
+
+func TestSQLiteMirrorStoreImportsFileState(t *testing.T) {
+	dir := t.TempDir()
+	fileStore, err := NewFileMirrorStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileMirrorStore: %v", err)
+	}
+	ctx := context.Background()
+	link := MirrorLink{"todoist": {ListID: "l1", TaskID: "t1", Hash: "h1"}, "notion": {ListID: "l2", TaskID: "t2", Hash: "h2"}}
+	if err := fileStore.SaveMirrorState(ctx, "todoist", "notion", &MirrorState{Links: []MirrorLink{link}}); err != nil {
+		t.Fatalf("SaveMirrorState: %v", err)
+	}
+
+	store, err := NewSQLiteMirrorStore(dir)
+	if err != nil {
+		t.Fatalf("NewSQLiteMirrorStore: %v", err)
+	}
+	defer store.Close()
+	state, err := store.LoadMirrorState(ctx, "todoist", "notion")
+	if err != nil || len(state.Links) != 1 || state.Links[0]["notion"].TaskID != "t2" {
+		t.Fatalf("file state should be imported, got %+v (%v)", state, err)
+	}
+}
+
+func TestMirrorWithSQLiteStoreSurvivesRestart(t *testing.T) {
+	engine, left, right, _ := newMirrorTestEngine(t)
+	dir := t.TempDir()
+	store, err := NewSQLiteMirrorStore(dir)
+	if err != nil {
+		t.Fatalf("NewSQLiteMirrorStore: %v", err)
+	}
+	runMirror(t, engine, store, MirrorOptions{})
+	_ = store.Close()
+
+	restarted, err := NewSQLiteMirrorStore(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer restarted.Close()
+	engine = NewEngine(map[string]provider.Provider{"todoist": left, "notion": right}, nil)
+	again := runMirror(t, engine, restarted, MirrorOptions{})
+	if again.ToLeft != (MirrorCounts{}) || again.ToRight != (MirrorCounts{}) {
+		t.Fatalf("mirroring after a restart should not duplicate tasks: %+v", again)
+	}
+	if len(left.tasks["inbox-l"]) != 3 || len(right.tasks["inbox-r"]) != 2 {
+		t.Fatalf("unexpected task counts: %d %d", len(left.tasks["inbox-l"]), len(right.tasks["inbox-r"]))
+	}
+	reports, err := MirrorStatusReport(context.Background(), restarted)
+	if err != nil || len(reports) != 1 || reports[0].LastRun == nil || reports[0].LastRun.Runs != 2 {
+		t.Fatalf("mirror runs should be persisted across restarts: %+v (%v)", reports, err)
+	}
+}
+
+func TestSQLiteMirrorStoreMigratesLastRun(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(dir, "mirror_state.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// 新增 last_run、tombstones 列之前的 mirror_pairs
+	if _, err := db.Exec(`CREATE TABLE mirror_pairs (
+		adapter_a TEXT NOT NULL, adapter_b TEXT NOT NULL,
+		last_sync_time TEXT NOT NULL DEFAULT '', conflicts TEXT NOT NULL DEFAULT '[]',
+		PRIMARY KEY (adapter_a, adapter_b));
+		INSERT INTO mirror_pairs (adapter_a, adapter_b) VALUES ('notion', 'todoist')`); err != nil {
+		t.Fatalf("create old schema: %v", err)
+	}
+	_ = db.Close()
+
+	store, err := NewSQLiteMirrorStore(dir)
+	if err != nil {
+		t.Fatalf("NewSQLiteMirrorStore: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	run := &MirrorRun{Runs: 1, Error: "boom", FinishedAt: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)}
+	if err := store.SaveMirrorRun(ctx, "todoist", "notion", run); err != nil {
+		t.Fatalf("SaveMirrorRun: %v", err)
+	}
+	state, err := store.LoadMirrorState(ctx, "notion", "todoist")
+	if err != nil || state.LastRun == nil || state.LastRun.Error != "boom" || !state.LastRun.FinishedAt.Equal(run.FinishedAt) {
+		t.Fatalf("unexpected state after migration: %+v (%v)", state, err)
+	}
+	pairs, err := store.ListMirrorPairs(ctx)
+	if err != nil || len(pairs) != 1 || pairs[0].Left != "notion" || pairs[0].Right != "todoist" {
+		t.Fatalf("unexpected pairs: %+v (%v)", pairs, err)
+	}
+}
+
+func TestSQLiteMirrorStoreEncryptsTaskContent(t *testing.T) {
+	dir := t.TempDir()
+	raw, _ := storecrypt.GenerateKey()
+	key, err := storecrypt.UnlockRaw(dir, raw)
+	if err != nil {
+		t.Fatalf("UnlockRaw: %v", err)
+	}
+	storecrypt.Use(key)
+	t.Cleanup(func() { storecrypt.Use(nil) })
+
+	store, err := NewSQLiteMirrorStore(dir)
+	if err != nil {
+		t.Fatalf("NewSQLiteMirrorStore: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	state := &MirrorState{
+		Links: []MirrorLink{{
+			"todoist": {ListID: "l1", TaskID: "t1", Hash: "h1", Fields: &MirrorFields{Title: "Q3 budget review"}},
+			"notion":  {ListID: "l2", TaskID: "t2", Hash: "h2"},
+		}},
+		Conflicts: []MirrorConflict{{ID: "abcd", Resolution: "notion"}},
+	}
+	if err := store.SaveMirrorState(ctx, "todoist", "notion", state); err != nil {
+		t.Fatalf("SaveMirrorState: %v", err)
+	}
+
+	var fields, conflicts string
+	if err := store.db.QueryRow(`SELECT fields_a FROM mirror_links WHERE adapter_a = 'notion'`).Scan(&fields); err != nil {
+		t.Fatalf("query fields: %v", err)
+	}
+	if err := store.db.QueryRow(`SELECT conflicts FROM mirror_pairs`).Scan(&conflicts); err != nil {
+		t.Fatalf("query conflicts: %v", err)
+	}
+	if strings.Contains(fields, "budget") || strings.Contains(conflicts, "abcd") {
+		t.Fatalf("task content should be encrypted: %q, %q", fields, conflicts)
+	}
+	loaded, err := store.LoadMirrorState(ctx, "todoist", "notion")
+	if err != nil || loaded.Links[0]["todoist"].Fields.Title != "Q3 budget review" || loaded.Conflicts[0].ID != "abcd" {
+		t.Fatalf("encrypted state should load: %+v, %v", loaded, err)
+	}
+
+	journal, err := NewFileMirrorJournal(dir)
+	if err != nil {
+		t.Fatalf("NewFileMirrorJournal: %v", err)
+	}
+	if err := journal.Append(ctx, MirrorChange{RunID: "r1", Provider: "todoist", Op: "create", TaskID: "t1", After: &model.Task{ID: "t1", Title: "Q3 budget review"}}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, MirrorJournalFileName)); strings.Contains(string(data), "budget") {
+		t.Fatalf("journal lines should be encrypted: %q", data)
+	}
+	if changes, err := journal.Changes(ctx); err != nil || len(changes) != 1 || changes[0].After.Title != "Q3 budget review" {
+		t.Fatalf("Changes: %+v, %v", changes, err)
+	}
+}
@@ -0,0 +1,142 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// 一侧任务删除后另一侧镜像任务的处理方式
+const (
+	// MirrorDeleteRemove 一并删除另一侧的镜像任务
+	MirrorDeleteRemove = "delete"
+	// MirrorDeleteArchive 将另一侧的镜像任务标记为完成（归档），保留其内容
+	MirrorDeleteArchive = "archive"
+)
+
+// MirrorTombstoneRetention 墓碑在其中的任务最后一次出现后保留的时长
+const MirrorTombstoneRetention = 90 * 24 * time.Hour
+
+// MirrorTombstone 因删除而结束的镜像关系。其中的任务之后再次出现时（如 Provider 在删除后仍返回任务的残留、
+// 归档的副本），不会被当作新任务配对或复制到另一侧
+type MirrorTombstone struct {
+	// Tasks 关系中的任务，键为 Provider 名称；删除侧重新创建副本时只记录被删除的任务
+	Tasks map[string]MirrorRef `json:"tasks"`
+	// DeletedOn 任务被删除的一侧，两侧都已删除时为空
+	DeletedOn string    `json:"deleted_on,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// Archived 另一侧的任务被标记为完成而不是删除
+	Archived bool `json:"archived,omitempty"`
+	// LastSeenAt 其中的任务最近一次再次出现的时间
+	LastSeenAt time.Time `json:"last_seen_at,omitempty"`
+}
+
+// normalizeOnDelete 校验删除的处理方式，为空时使用 delete
+func normalizeOnDelete(opts *MirrorOptions) error {
+	switch value := strings.ToLower(strings.TrimSpace(opts.OnDelete)); value {
+	case "":
+		opts.OnDelete = MirrorDeleteRemove
+	case MirrorDeleteRemove, MirrorDeleteArchive:
+		opts.OnDelete = value
+	default:
+		return fmt.Errorf("unknown on-delete mode %q", opts.OnDelete)
+	}
+	return nil
+}
+
+// loadTombstones 建立上次保存的墓碑的索引
+func (m *mirrorRun) loadTombstones(tombstones []MirrorTombstone) {
+	for _, tombstone := range tombstones {
+		m.addTombstone(tombstone)
+	}
+}
+
+func (m *mirrorRun) addTombstone(tombstone MirrorTombstone) {
+	if m.buried == nil {
+		m.buried = make(map[string]int)
+	}
+	for name, ref := range tombstone.Tasks {
+		m.buried[name+"\x00"+ref.TaskID] = len(m.tombstones)
+	}
+	m.tombstones = append(m.tombstones, tombstone)
+}
+
+// bury 为因删除而结束的镜像关系记录墓碑；deleted 为任务被删除的一侧，两侧都已删除时为 nil
+func (m *mirrorRun) bury(link MirrorLink, deleted *mirrorSide, archived bool) {
+	if m.opts.DryRun {
+		return
+	}
+	tombstone := MirrorTombstone{Tasks: make(map[string]MirrorRef, len(link)), DeletedAt: m.result.LastSyncTime, Archived: archived}
+	for name, ref := range link {
+		tombstone.Tasks[name] = MirrorRef{ListID: ref.ListID, TaskID: ref.TaskID}
+	}
+	if deleted != nil {
+		tombstone.DeletedOn = deleted.name
+	}
+	m.addTombstone(tombstone)
+}
+
+// skipGhosts 将再次出现的已删除任务标记为已处理，使 linkUnmatched 不再配对或复制它们
+func (m *mirrorRun) skipGhosts() {
+	for _, side := range []*mirrorSide{m.left, m.right} {
+		for _, id := range sortedTaskIDs(side) {
+			i, ok := m.buried[side.name+"\x00"+id]
+			if !ok || m.isLinked(side, id) {
+				continue
+			}
+			m.markLinked(side, id)
+			m.tombstones[i].LastSeenAt = m.result.LastSyncTime
+			m.result.Ghosts++
+			log.Debug().Str("task", side.tasks[id].Title).Str("provider", side.name).Msg("忽略已删除任务的残留")
+		}
+	}
+}
+
+// keptTombstones 返回仍在保留期内的墓碑
+func (m *mirrorRun) keptTombstones() []MirrorTombstone {
+	cutoff := m.result.LastSyncTime.Add(-MirrorTombstoneRetention)
+	var kept []MirrorTombstone
+	for _, tombstone := range m.tombstones {
+		if tombstone.DeletedAt.Before(cutoff) && tombstone.LastSeenAt.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, tombstone)
+	}
+	return kept
+}
+
+// archiveMirrored 将 survivor 侧的镜像任务标记为完成而不是删除；已完成的任务保持不变
+func (m *mirrorRun) archiveMirrored(ctx context.Context, link MirrorLink, survivor *mirrorSide, task *model.Task, deleted *mirrorSide) (MirrorLink, bool) {
+	if task.Status == model.StatusCompleted {
+		m.bury(link, deleted, true)
+		return nil, false
+	}
+	if m.opts.DryRun {
+		log.Info().Str("task", task.Title).Str("provider", survivor.name).Msg("[DryRun] 将归档镜像任务")
+		survivor.counts.Completed++
+		return nil, false
+	}
+	archived := *task
+	archived.Status = model.StatusCompleted
+	now := time.Now()
+	archived.CompletedAt = &now
+	saved, err := survivor.p.UpdateTask(ctx, link[survivor.name].ListID, &archived)
+	if err != nil {
+		m.addError(task.ID, "archive_task", fmt.Sprintf("归档 %s 任务失败: %v", survivor.name, err))
+		// 归档失败时保留关系，下次重试
+		return link, true
+	}
+	if saved == nil {
+		saved = &archived
+	}
+	survivor.counts.Completed++
+	m.record(ctx, survivor, MirrorOpArchive, link[survivor.name].ListID, task, saved)
+	m.bury(link, deleted, true)
+	return nil, false
+}
@@ -0,0 +1,278 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yeisme/taskbridge/internal/model"
+)
+
+// MirrorUndoOptions 撤销镜像运行的选项
+type MirrorUndoOptions struct {
+	// DryRun 只列出将要撤销的变更，不修改任一侧
+	DryRun bool
+	// Force 运行之后又被修改过的任务也撤销；默认跳过，避免覆盖之后的修改
+	Force bool
+}
+
+// MirrorUndoSkip 未撤销的变更及原因
+type MirrorUndoSkip struct {
+	Provider string `json:"provider"`
+	Op       string `json:"op"`
+	TaskID   string `json:"task_id"`
+	Title    string `json:"title,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// MirrorUndoResult 撤销结果
+type MirrorUndoResult struct {
+	// RunID 撤销本身的运行 ID，撤销产生的变更同样记录在变更日志中
+	RunID  string `json:"run_id"`
+	UndoOf string `json:"undo_of"`
+	Left   string `json:"left"`
+	Right  string `json:"right"`
+	DryRun bool   `json:"dry_run,omitempty"`
+	// Reverted 已撤销（DryRun 时为将要撤销）的变更数
+	Reverted int              `json:"reverted"`
+	Skipped  []MirrorUndoSkip `json:"skipped,omitempty"`
+	Errors   []Error          `json:"errors,omitempty"`
+}
+
+// UndoMirrorRun 按变更日志逆序撤销一次镜像运行：删除新建的任务，恢复被修改、完成或归档的字段，重新创建被删除的任务。
+// 撤销后调整镜像状态，使下次镜像不会重做被撤销的变更：撤销新建的副本记录为墓碑；恢复的字段作为新的同步基准；
+// 重新创建的任务不在镜像关系中，下次镜像时复制到另一侧。
+// 之前的撤销已经撤销的变更不再处理，因此被跳过的变更可以之后用 Force 再次撤销；全部变更都已撤销时返回错误。
+func (e *Engine) UndoMirrorRun(ctx context.Context, store MirrorStore, journal MirrorJournal, runID string, opts MirrorUndoOptions) (*MirrorUndoResult, error) {
+	changes, err := journal.Changes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var run []MirrorChange
+	reverted := make(map[string]string)
+	found := false
+	for _, change := range changes {
+		switch {
+		case change.UndoOf == runID:
+			reverted[change.Reverts] = change.RunID
+		case change.RunID == runID:
+			found = true
+			run = append(run, change)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("run %s not found in mirror journal", runID)
+	}
+	run = slices.DeleteFunc(run, func(change MirrorChange) bool { return reverted[change.key()] != "" })
+	if len(run) == 0 {
+		undoneBy := ""
+		for _, by := range reverted {
+			undoneBy = by
+		}
+		return nil, fmt.Errorf("run %s was already undone by %s", runID, undoneBy)
+	}
+
+	startTime := time.Now()
+	left, right := run[0].Left, run[0].Right
+	result := &MirrorUndoResult{RunID: newMirrorRunID(startTime), UndoOf: runID, Left: left, Right: right, DryRun: opts.DryRun}
+	state, err := store.LoadMirrorState(ctx, left, right)
+	if err != nil {
+		return nil, fmt.Errorf("load mirror state: %w", err)
+	}
+	u := &mirrorUndo{engine: e, journal: journal, state: state, opts: opts, result: result, now: startTime}
+	for _, change := range slices.Backward(run) {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		u.revert(ctx, change)
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+	if err := store.SaveMirrorState(ctx, left, right, state); err != nil {
+		return result, fmt.Errorf("save mirror state: %w", err)
+	}
+	log.Info().Str("run", runID).Int("reverted", result.Reverted).Int("skipped", len(result.Skipped)).Msg("已撤销镜像运行")
+	return result, nil
+}
+
+// mirrorUndo 一次撤销的执行状态
+type mirrorUndo struct {
+	engine  *Engine
+	journal MirrorJournal
+	state   *MirrorState
+	opts    MirrorUndoOptions
+	result  *MirrorUndoResult
+	now     time.Time
+}
+
+// revert 撤销一项变更
+func (u *mirrorUndo) revert(ctx context.Context, change MirrorChange) {
+	p, ok := u.engine.providers[change.Provider]
+	if !ok || !p.IsAuthenticated() {
+		u.fail(change, fmt.Sprintf("%s 不可用或未认证", change.Provider))
+		return
+	}
+
+	if change.Op == MirrorOpDelete {
+		if change.Before == nil {
+			u.skip(change, "变更日志中没有删除前的内容")
+			return
+		}
+		if u.opts.DryRun {
+			u.result.Reverted++
+			return
+		}
+		restored := *change.Before
+		restored.ID, restored.SourceRawID = "", ""
+		restored.ListID = change.ListID
+		created, err := p.CreateTask(ctx, change.ListID, &restored)
+		if err != nil {
+			u.fail(change, fmt.Sprintf("在 %s 重新创建任务失败: %v", change.Provider, err))
+			return
+		}
+		if created == nil {
+			created = &restored
+		}
+		u.unbury(change.Provider, change.TaskID)
+		u.record(ctx, change, MirrorOpCreate, nil, created)
+		u.result.Reverted++
+		return
+	}
+
+	current, err := p.GetTask(ctx, change.ListID, change.TaskID)
+	if err != nil || current == nil {
+		u.skip(change, "任务已不存在")
+		return
+	}
+	if current.ListID == "" {
+		current.ListID = change.ListID
+	}
+	if !u.opts.Force && change.After != nil && taskFingerprint(current) != taskFingerprint(change.After) {
+		u.skip(change, "运行之后任务又被修改")
+		return
+	}
+	if u.opts.DryRun {
+		u.result.Reverted++
+		return
+	}
+
+	switch change.Op {
+	case MirrorOpCreate:
+		if err := p.DeleteTask(ctx, change.ListID, change.TaskID); err != nil {
+			u.fail(change, fmt.Sprintf("删除 %s 任务失败: %v", change.Provider, err))
+			return
+		}
+		u.buryCreated(change.Provider, change.TaskID)
+		u.record(ctx, change, MirrorOpDelete, current, nil)
+	case MirrorOpUpdate, MirrorOpComplete, MirrorOpArchive:
+		if change.Before == nil {
+			u.skip(change, "变更日志中没有修改前的内容")
+			return
+		}
+		saved, err := p.UpdateTask(ctx, change.ListID, mirroredCopy(change.Before, current))
+		if err != nil {
+			u.fail(change, fmt.Sprintf("恢复 %s 任务失败: %v", change.Provider, err))
+			return
+		}
+		if saved == nil {
+			saved = mirroredCopy(change.Before, current)
+		}
+		if saved.ListID == "" {
+			saved.ListID = change.ListID
+		}
+		if change.Op == MirrorOpArchive {
+			u.unbury(change.Provider, change.TaskID)
+		} else {
+			u.rebase(change.Provider, saved)
+		}
+		u.record(ctx, change, MirrorOpUpdate, current, saved)
+	default:
+		u.skip(change, fmt.Sprintf("未知的操作 %s", change.Op))
+		return
+	}
+	u.result.Reverted++
+}
+
+func (u *mirrorUndo) skip(change MirrorChange, reason string) {
+	u.result.Skipped = append(u.result.Skipped, MirrorUndoSkip{
+		Provider: change.Provider, Op: change.Op, TaskID: change.TaskID, Title: changeTitle(change), Reason: reason,
+	})
+}
+
+func (u *mirrorUndo) fail(change MirrorChange, message string) {
+	u.result.Errors = append(u.result.Errors, Error{TaskID: change.TaskID, Operation: "undo_" + change.Op, Error: message})
+}
+
+// record 将撤销产生的变更写入日志
+func (u *mirrorUndo) record(ctx context.Context, change MirrorChange, op string, before, after *model.Task) {
+	task := after
+	if task == nil {
+		task = before
+	}
+	entry := MirrorChange{
+		RunID:    u.result.RunID,
+		At:       time.Now(),
+		Left:     change.Left,
+		Right:    change.Right,
+		Provider: change.Provider,
+		Op:       op,
+		ListID:   change.ListID,
+		TaskID:   rawTaskID(task),
+		Before:   before,
+		After:    after,
+		UndoOf:   u.result.UndoOf,
+		Reverts:  change.key(),
+	}
+	if err := u.journal.Append(context.WithoutCancel(ctx), entry); err != nil {
+		log.Warn().Err(err).Str("provider", change.Provider).Str("op", op).Msg("写入镜像变更日志失败")
+	}
+}
+
+// buryCreated 撤销新建的副本后结束其镜像关系并记录墓碑，使原任务不再被复制
+func (u *mirrorUndo) buryCreated(provider, taskID string) {
+	for i, link := range u.state.Links {
+		if ref, ok := link[provider]; ok && ref.TaskID == taskID {
+			tombstone := MirrorTombstone{Tasks: make(map[string]MirrorRef, len(link)), DeletedOn: provider, DeletedAt: u.now}
+			for name, ref := range link {
+				tombstone.Tasks[name] = MirrorRef{ListID: ref.ListID, TaskID: ref.TaskID}
+			}
+			u.state.Tombstones = append(u.state.Tombstones, tombstone)
+			u.state.Links = slices.Delete(u.state.Links, i, i+1)
+			return
+		}
+	}
+}
+
+// unbury 删除包含该任务的墓碑，使恢复的任务在下次镜像时重新复制到另一侧
+func (u *mirrorUndo) unbury(provider, taskID string) {
+	u.state.Tombstones = slices.DeleteFunc(u.state.Tombstones, func(tombstone MirrorTombstone) bool {
+		ref, ok := tombstone.Tasks[provider]
+		return ok && ref.TaskID == taskID
+	})
+}
+
+// rebase 以恢复后的内容作为该任务新的同步基准，另一侧不会被改回
+func (u *mirrorUndo) rebase(provider string, task *model.Task) {
+	for _, link := range u.state.Links {
+		if ref, ok := link[provider]; ok && ref.TaskID == rawTaskID(task) {
+			link[provider] = mirrorRef(task, u.now)
+			return
+		}
+	}
+}
+
+func changeTitle(change MirrorChange) string {
+	switch {
+	case change.After != nil:
+		return change.After.Title
+	case change.Before != nil:
+		return change.Before.Title
+	default:
+		return ""
+	}
+}
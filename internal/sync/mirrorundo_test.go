@@ -0,0 +1,134 @@
+// Package sync 提供任务同步功能
+package sync
+
+import (
+	"context"
+	"testing"
+)
+
+func newTestMirrorJournal(t *testing.T) *FileMirrorJournal {
+	t.Helper()
+	journal, err := NewFileMirrorJournal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileMirrorJournal: %v", err)
+	}
+	return journal
+}
+
+func TestMirrorJournalRecordsChanges(t *testing.T) {
+	engine, left, _, store := newMirrorTestEngine(t)
+	journal := newTestMirrorJournal(t)
+
+	runMirror(t, engine, store, MirrorOptions{Journal: journal, DryRun: true})
+	if changes, _ := journal.Changes(context.Background()); len(changes) != 0 {
+		t.Fatalf("dry run should not be journaled: %+v", changes)
+	}
+
+	first := runMirror(t, engine, store, MirrorOptions{Journal: journal})
+	mockTask(t, left, "inbox-l", "Call Bob").Description = "about the budget"
+	second := runMirror(t, engine, store, MirrorOptions{Journal: journal})
+
+	changes, err := journal.Changes(context.Background())
+	if err != nil {
+		t.Fatalf("Changes: %v", err)
+	}
+	// 首次镜像：配对时以较新的一侧更新标题，复制 Call Bob；第二次：更新另一侧的 Call Bob
+	if len(changes) != 3 {
+		t.Fatalf("expected three changes, got %+v", changes)
+	}
+	if create := changes[1]; create.RunID != first.RunID || create.Op != MirrorOpCreate || create.Provider != "todoist" || create.Before != nil || create.After.Title != "Call Bob" {
+		t.Fatalf("unexpected create entry: %+v", create)
+	}
+	if update := changes[2]; update.RunID != second.RunID || update.Op != MirrorOpUpdate || update.Provider != "notion" ||
+		update.Before.Description != "" || update.After.Description != "about the budget" {
+		t.Fatalf("unexpected update entry: %+v", update)
+	}
+
+	runs, err := MirrorJournalRuns(context.Background(), journal)
+	if err != nil || len(runs) != 2 || runs[0].RunID != second.RunID || runs[1].Changes != 2 {
+		t.Fatalf("runs should be listed newest first: %+v, %v", runs, err)
+	}
+}
+
+func TestMirrorUndoRevertsRun(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	journal := newTestMirrorJournal(t)
+	ctx := context.Background()
+	runMirror(t, engine, store, MirrorOptions{Journal: journal})
+
+	mockTask(t, left, "inbox-l", "Call Bob").Description = "about the budget"
+	_ = left.DeleteTask(ctx, "inbox-l", "a-l")
+	bad := runMirror(t, engine, store, MirrorOptions{Journal: journal})
+	if bad.ToRight.Updated != 1 || bad.ToRight.Deleted != 1 {
+		t.Fatalf("setup: %+v", bad)
+	}
+
+	dry, err := engine.UndoMirrorRun(ctx, store, journal, bad.RunID, MirrorUndoOptions{DryRun: true})
+	if err != nil || dry.Reverted != 2 || mockTask(t, right, "inbox-r", "write report") != nil {
+		t.Fatalf("dry run should only count changes: %+v, %v", dry, err)
+	}
+
+	result, err := engine.UndoMirrorRun(ctx, store, journal, bad.RunID, MirrorUndoOptions{})
+	if err != nil {
+		t.Fatalf("UndoMirrorRun: %v", err)
+	}
+	if result.Reverted != 2 || len(result.Skipped) != 0 || len(result.Errors) != 0 {
+		t.Fatalf("both changes should be reverted: %+v", result)
+	}
+	if mockTask(t, right, "inbox-r", "Call Bob").Description != "" {
+		t.Fatal("field change should be reverted")
+	}
+	if mockTask(t, right, "inbox-r", "write report") == nil {
+		t.Fatal("deleted task should be recreated")
+	}
+
+	// 撤销的变更不会被再次应用，重新创建的任务复制回另一侧
+	next := runMirror(t, engine, store, MirrorOptions{Journal: journal})
+	if next.ToRight.Updated != 0 || next.ToLeft.Updated != 0 || next.ToLeft.Created != 1 || mockTask(t, left, "inbox-l", "write report") == nil {
+		t.Fatalf("next mirror should keep the undo: %+v", next)
+	}
+
+	if _, err := engine.UndoMirrorRun(ctx, store, journal, bad.RunID, MirrorUndoOptions{}); err == nil {
+		t.Fatal("a run should only be undone once")
+	}
+	if _, err := engine.UndoMirrorRun(ctx, store, journal, "missing", MirrorUndoOptions{}); err == nil {
+		t.Fatal("unknown run should be rejected")
+	}
+	runs, _ := MirrorJournalRuns(ctx, journal)
+	for _, run := range runs {
+		if run.RunID == bad.RunID && run.UndoneBy != result.RunID {
+			t.Fatalf("undone run should point at its undo: %+v", run)
+		}
+	}
+}
+
+func TestMirrorUndoSkipsLaterEdits(t *testing.T) {
+	engine, left, right, store := newMirrorTestEngine(t)
+	journal := newTestMirrorJournal(t)
+	ctx := context.Background()
+	first := runMirror(t, engine, store, MirrorOptions{Journal: journal})
+
+	mockTask(t, left, "inbox-l", "Call Bob").Priority = 3
+	result, err := engine.UndoMirrorRun(ctx, store, journal, first.RunID, MirrorUndoOptions{})
+	if err != nil {
+		t.Fatalf("UndoMirrorRun: %v", err)
+	}
+	if result.Reverted != 1 || len(result.Skipped) != 1 || mockTask(t, left, "inbox-l", "Call Bob") == nil {
+		t.Fatalf("task edited after the run should be kept: %+v", result)
+	}
+
+	// 再次撤销只处理上次跳过的变更
+	result, err = engine.UndoMirrorRun(ctx, store, journal, first.RunID, MirrorUndoOptions{Force: true})
+	if err != nil || result.Reverted != 1 || len(result.Skipped) != 0 || mockTask(t, left, "inbox-l", "Call Bob") != nil {
+		t.Fatalf("force should undo the remaining create: %+v, %v", result, err)
+	}
+	if _, err := engine.UndoMirrorRun(ctx, store, journal, first.RunID, MirrorUndoOptions{Force: true}); err == nil {
+		t.Fatal("a fully undone run should be rejected")
+	}
+
+	// 撤销的副本不会被重新复制，原任务保留
+	next := runMirror(t, engine, store, MirrorOptions{Journal: journal})
+	if next.ToLeft.Created != 0 || mockTask(t, left, "inbox-l", "Call Bob") != nil || mockTask(t, right, "inbox-r", "Call Bob") == nil {
+		t.Fatalf("undone copy should stay removed: %+v", next)
+	}
+}
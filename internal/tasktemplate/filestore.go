@@ -0,0 +1,149 @@
+package tasktemplate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+type persistData struct {
+	Templates []*Template `json:"templates"`
+}
+
+// FileStore 任务模板文件存储。
+type FileStore struct {
+	mu        sync.RWMutex
+	filePath  string
+	templates map[string]*Template
+}
+
+// NewFileStore 创建任务模板存储。
+func NewFileStore(basePath string) (*FileStore, error) {
+	store := &FileStore{
+		filePath:  filepath.Join(basePath, "templates.json"),
+		templates: make(map[string]*Template),
+	}
+
+	if err := os.MkdirAll(basePath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create template store dir: %w", err)
+	}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *FileStore) SaveTemplate(_ context.Context, tpl *Template) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, existing := range s.templates {
+		if id != tpl.ID && strings.EqualFold(existing.Name, tpl.Name) {
+			return fmt.Errorf("template name already exists: %s", tpl.Name)
+		}
+	}
+	now := time.Now()
+	if tpl.CreatedAt.IsZero() {
+		tpl.CreatedAt = now
+	}
+	tpl.UpdatedAt = now
+	s.templates[tpl.ID] = cloneTemplate(tpl)
+	return s.save()
+}
+
+func (s *FileStore) GetTemplate(_ context.Context, idOrName string) (*Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := strings.TrimSpace(idOrName)
+	if tpl, ok := s.templates[key]; ok {
+		return cloneTemplate(tpl), nil
+	}
+	for _, tpl := range s.templates {
+		if strings.EqualFold(tpl.Name, key) {
+			return cloneTemplate(tpl), nil
+		}
+	}
+	return nil, fmt.Errorf("template not found: %s", idOrName)
+}
+
+func (s *FileStore) ListTemplates(_ context.Context) ([]Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]Template, 0, len(s.templates))
+	for _, tpl := range s.templates {
+		items = append(items, *cloneTemplate(tpl))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+func (s *FileStore) DeleteTemplate(_ context.Context, templateID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.templates[templateID]; !ok {
+		return fmt.Errorf("template not found: %s", templateID)
+	}
+	delete(s.templates, templateID)
+	return s.save()
+}
+
+func (s *FileStore) load() error {
+	data, err := storecrypt.ReadFile(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read template store: %w", err)
+	}
+
+	var payload persistData
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal template store: %w", err)
+	}
+	for _, tpl := range payload.Templates {
+		s.templates[tpl.ID] = tpl
+	}
+	return nil
+}
+
+func (s *FileStore) save() error {
+	templates := make([]*Template, 0, len(s.templates))
+	for _, tpl := range s.templates {
+		templates = append(templates, tpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].CreatedAt.Before(templates[j].CreatedAt)
+	})
+
+	bytes, err := json.MarshalIndent(persistData{Templates: templates}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal template store: %w", err)
+	}
+	if err := storecrypt.WriteFile(s.filePath, bytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write template store: %w", err)
+	}
+	return nil
+}
+
+func cloneTemplate(tpl *Template) *Template {
+	cp := *tpl
+	cp.Tasks = make([]TemplateTask, len(tpl.Tasks))
+	for i, task := range tpl.Tasks {
+		task.Tags = append([]string(nil), task.Tags...)
+		cp.Tasks[i] = task
+	}
+	return &cp
+}
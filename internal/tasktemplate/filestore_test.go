@@ -0,0 +1,56 @@
+package tasktemplate
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFileStoreSaveAndLookupByName(t *testing.T) {
+	tmp := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewFileStore(tmp)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	tpl := &Template{
+		ID:   "tpl_1",
+		Name: "Release Checklist",
+		Tasks: []TemplateTask{
+			{Key: "1", Title: "发布 {{version}}"},
+			{Key: "2", ParentKey: "1", Title: "更新 CHANGELOG", Tags: []string{"docs"}},
+		},
+	}
+	if err := store.SaveTemplate(ctx, tpl); err != nil {
+		t.Fatalf("SaveTemplate: %v", err)
+	}
+	if err := store.SaveTemplate(ctx, &Template{ID: "tpl_2", Name: "release checklist"}); err == nil {
+		t.Fatalf("expected duplicate name to be rejected")
+	}
+
+	reloaded, err := NewFileStore(tmp)
+	if err != nil {
+		t.Fatalf("reload NewFileStore: %v", err)
+	}
+	got, err := reloaded.GetTemplate(ctx, "RELEASE checklist")
+	if err != nil {
+		t.Fatalf("GetTemplate by name: %v", err)
+	}
+	if got.ID != "tpl_1" || len(got.Tasks) != 2 || got.Tasks[1].ParentKey != "1" {
+		t.Fatalf("unexpected template: %#v", got)
+	}
+
+	if err := reloaded.DeleteTemplate(ctx, "tpl_1"); err != nil {
+		t.Fatalf("DeleteTemplate: %v", err)
+	}
+	if items, _ := reloaded.ListTemplates(ctx); len(items) != 0 {
+		t.Fatalf("expected empty list after delete: %#v", items)
+	}
+}
+
+func TestRender(t *testing.T) {
+	got := Render("发布 {{version}} 到 {{ env }}", map[string]string{"version": "v1.2.0", "env": "prod"})
+	if got != "发布 v1.2.0 到 {{ env }}" {
+		t.Fatalf("unexpected render result: %q", got)
+	}
+}
@@ -0,0 +1,12 @@
+package tasktemplate
+
+import "context"
+
+// Store 任务模板存储接口。
+type Store interface {
+	SaveTemplate(ctx context.Context, tpl *Template) error
+	// GetTemplate 按 ID 或名称（忽略大小写）查找模板。
+	GetTemplate(ctx context.Context, idOrName string) (*Template, error)
+	ListTemplates(ctx context.Context) ([]Template, error)
+	DeleteTemplate(ctx context.Context, templateID string) error
+}
@@ -0,0 +1,44 @@
+// Package tasktemplate 提供可复用的多任务模板（如“发布检查清单”）及其本地存储。
+package tasktemplate
+
+import (
+	"strings"
+	"time"
+)
+
+// Template 任务模板。
+type Template struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Tasks       []TemplateTask `json:"tasks"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TemplateTask 模板内的任务定义。
+type TemplateTask struct {
+	// Key 是模板内任务的稳定标识，用于表达父子关系。
+	Key string `json:"key"`
+	// ParentKey 是父任务的 Key，为空表示顶层任务。
+	ParentKey       string   `json:"parent_key,omitempty"`
+	Title           string   `json:"title"`
+	Description     string   `json:"description,omitempty"`
+	DueOffsetDays   int      `json:"due_offset_days,omitempty"`
+	EstimateMinutes int      `json:"estimate_minutes,omitempty"`
+	Priority        int      `json:"priority,omitempty"`
+	Quadrant        int      `json:"quadrant,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+}
+
+// Render 使用 {{name}} 形式的变量替换文本。
+func Render(text string, vars map[string]string) string {
+	if len(vars) == 0 || !strings.Contains(text, "{{") {
+		return text
+	}
+	pairs := make([]string, 0, len(vars)*2)
+	for k, v := range vars {
+		pairs = append(pairs, "{{"+strings.TrimSpace(k)+"}}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
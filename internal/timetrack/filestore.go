@@ -0,0 +1,155 @@
+package timetrack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
+)
+
+type persistData struct {
+	Entries []*Entry `json:"entries"`
+}
+
+// FileStore 时间记录文件存储。
+type FileStore struct {
+	mu       sync.RWMutex
+	filePath string
+	entries  map[string]*Entry
+}
+
+// NewFileStore 创建时间记录存储。
+func NewFileStore(basePath string) (*FileStore, error) {
+	store := &FileStore{
+		filePath: filepath.Join(basePath, "time_entries.json"),
+		entries:  make(map[string]*Entry),
+	}
+
+	if err := os.MkdirAll(basePath, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create time entry store dir: %w", err)
+	}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *FileStore) SaveEntry(_ context.Context, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	s.entries[entry.ID] = cloneEntry(entry)
+	return s.save()
+}
+
+func (s *FileStore) GetEntry(_ context.Context, entryID string) (*Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entries[entryID]
+	if !ok {
+		return nil, fmt.Errorf("time entry not found: %s", entryID)
+	}
+	return cloneEntry(e), nil
+}
+
+func (s *FileStore) ListEntries(_ context.Context, taskID string) ([]Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]Entry, 0)
+	for _, e := range s.entries {
+		if taskID != "" && e.TaskID != taskID {
+			continue
+		}
+		items = append(items, *cloneEntry(e))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].StartedAt.Equal(items[j].StartedAt) {
+			return items[i].ID < items[j].ID
+		}
+		return items[i].StartedAt.Before(items[j].StartedAt)
+	})
+	return items, nil
+}
+
+func (s *FileStore) GetRunning(_ context.Context, taskID string) (*Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest *Entry
+	for _, e := range s.entries {
+		if !e.Running() || (taskID != "" && e.TaskID != taskID) {
+			continue
+		}
+		if latest == nil || e.StartedAt.After(latest.StartedAt) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		if taskID != "" {
+			return nil, fmt.Errorf("no running timer for task: %s", taskID)
+		}
+		return nil, fmt.Errorf("no running timer")
+	}
+	return cloneEntry(latest), nil
+}
+
+func (s *FileStore) load() error {
+	data, err := storecrypt.ReadFile(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read time entry store: %w", err)
+	}
+
+	var payload persistData
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal time entry store: %w", err)
+	}
+	for _, e := range payload.Entries {
+		s.entries[e.ID] = e
+	}
+	return nil
+}
+
+func (s *FileStore) save() error {
+	entries := make([]*Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].StartedAt.Equal(entries[j].StartedAt) {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].StartedAt.Before(entries[j].StartedAt)
+	})
+
+	bytes, err := json.MarshalIndent(persistData{Entries: entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal time entry store: %w", err)
+	}
+	if err := storecrypt.WriteFile(s.filePath, bytes, 0o644); err != nil {
+		return fmt.Errorf("failed to write time entry store: %w", err)
+	}
+	return nil
+}
+
+func cloneEntry(e *Entry) *Entry {
+	cp := *e
+	if e.EndedAt != nil {
+		ended := *e.EndedAt
+		cp.EndedAt = &ended
+	}
+	return &cp
+}
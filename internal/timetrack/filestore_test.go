@@ -0,0 +1,52 @@
+package timetrack
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFileStoreRunningTimerAndReload(t *testing.T) {
+	tmp := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewFileStore(tmp)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	started := time.Now().Add(-30 * time.Minute)
+	if err := store.SaveEntry(ctx, &Entry{ID: "te_1", TaskID: "task_1", StartedAt: started}); err != nil {
+		t.Fatalf("SaveEntry: %v", err)
+	}
+	ended := started.Add(10 * time.Minute)
+	if err := store.SaveEntry(ctx, &Entry{ID: "te_0", TaskID: "task_1", StartedAt: started.Add(-time.Hour), EndedAt: &ended, Minutes: 10}); err != nil {
+		t.Fatalf("SaveEntry: %v", err)
+	}
+
+	running, err := store.GetRunning(ctx, "task_1")
+	if err != nil {
+		t.Fatalf("GetRunning: %v", err)
+	}
+	if running.ID != "te_1" {
+		t.Fatalf("unexpected running entry: %s", running.ID)
+	}
+	if _, err := store.GetRunning(ctx, "task_2"); err == nil {
+		t.Fatalf("expected no running timer for task_2")
+	}
+
+	reloaded, err := NewFileStore(tmp)
+	if err != nil {
+		t.Fatalf("reload NewFileStore: %v", err)
+	}
+	entries, err := reloaded.ListEntries(ctx, "task_1")
+	if err != nil {
+		t.Fatalf("ListEntries: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != "te_0" {
+		t.Fatalf("unexpected entries: %#v", entries)
+	}
+	if entries[1].ElapsedMinutes(started.Add(45*time.Minute)) != 45 {
+		t.Fatalf("unexpected elapsed minutes for running timer")
+	}
+}
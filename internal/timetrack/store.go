@@ -0,0 +1,13 @@
+package timetrack
+
+import "context"
+
+// Store 时间记录存储接口。
+type Store interface {
+	SaveEntry(ctx context.Context, entry *Entry) error
+	GetEntry(ctx context.Context, entryID string) (*Entry, error)
+	// ListEntries 按任务列出时间记录，taskID 为空时返回全部。
+	ListEntries(ctx context.Context, taskID string) ([]Entry, error)
+	// GetRunning 返回运行中的计时器，taskID 为空时返回任意一个。
+	GetRunning(ctx context.Context, taskID string) (*Entry, error)
+}
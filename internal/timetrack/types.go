@@ -0,0 +1,31 @@
+// Package timetrack 提供任务计时记录的本地存储。
+package timetrack
+
+import "time"
+
+// Entry 时间记录。EndedAt 为空表示计时器仍在运行。
+type Entry struct {
+	ID        string     `json:"id"`
+	TaskID    string     `json:"task_id"`
+	TaskTitle string     `json:"task_title,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Minutes   int        `json:"minutes"`
+	// Manual 表示通过 log_time 手动补录，而非计时器产生。
+	Manual    bool      `json:"manual,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Running 计时器是否仍在运行。
+func (e *Entry) Running() bool {
+	return e.EndedAt == nil
+}
+
+// ElapsedMinutes 返回记录时长；运行中的计时器按 now 计算。
+func (e *Entry) ElapsedMinutes(now time.Time) int {
+	if !e.Running() {
+		return e.Minutes
+	}
+	return int(now.Sub(e.StartedAt).Minutes())
+}
@@ -0,0 +1,45 @@
+// Package browser 在系统默认浏览器中打开链接，用于 OAuth2 登录
+package browser
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Open 在浏览器中打开 rawURL：优先使用 BROWSER 环境变量指定的命令，否则使用系统默认浏览器。
+// 命令启动后立即返回，不等待浏览器退出；无图形环境（如 SSH 会话）时返回错误，调用方应提示用户手动打开链接。
+func Open(rawURL string) error {
+	name, args, err := command(rawURL)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// command 返回打开链接使用的命令与参数
+func command(rawURL string) (string, []string, error) {
+	if custom := strings.Fields(os.Getenv("BROWSER")); len(custom) > 0 {
+		return custom[0], append(custom[1:], rawURL), nil
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return "open", []string{rawURL}, nil
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", rawURL}, nil
+	default:
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return "", nil, fmt.Errorf("no graphical display available")
+		}
+		return "xdg-open", []string{rawURL}, nil
+	}
+}
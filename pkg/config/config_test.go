@@ -3,8 +3,13 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/yeisme/taskbridge/pkg/secretstore"
 )
 
 func TestDefaultConfigIncludesMCPExpansionDefaults(t *testing.T) {
@@ -22,6 +27,9 @@ func TestDefaultConfigIncludesMCPExpansionDefaults(t *testing.T) {
 	if cfg.MCP.Reliability.DefaultTimeout != 30*time.Second {
 		t.Fatalf("unexpected default timeout: %s", cfg.MCP.Reliability.DefaultTimeout)
 	}
+	if cfg.MCP.Session.KeepAlive != 30*time.Second || cfg.MCP.Session.IdleTimeout != 30*time.Minute {
+		t.Fatalf("unexpected session defaults: %+v", cfg.MCP.Session)
+	}
 	if cfg.MCP.Cache.Backend != "memory" {
 		t.Fatalf("unexpected cache backend: %s", cfg.MCP.Cache.Backend)
 	}
@@ -46,6 +54,24 @@ func TestNormalizeTransport(t *testing.T) {
 	}
 }
 
+func TestNormalizeTransportList(t *testing.T) {
+	canonical, deprecated, err := NormalizeTransport(" stdio , http,stdio")
+	if err != nil || deprecated || canonical != "stdio,streamable" {
+		t.Fatalf("unexpected normalize result: %q %v %v", canonical, deprecated, err)
+	}
+	if got := HTTPTransport(canonical); got != "streamable" {
+		t.Fatalf("expected streamable http transport, got %q", got)
+	}
+	if got := HTTPTransport("stdio"); got != "" {
+		t.Fatalf("expected no http transport, got %q", got)
+	}
+	for _, value := range []string{"sse,streamable", "stdio,", "stdio,grpc"} {
+		if _, _, err := NormalizeTransport(value); err == nil {
+			t.Fatalf("expected error for %q", value)
+		}
+	}
+}
+
 func TestValidateCoversExpandedRules(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.MCP.Transport = "tcp"
@@ -57,6 +83,15 @@ func TestValidateCoversExpandedRules(t *testing.T) {
 	cfg.MCP.Reliability.MaxTimeout = 2 * time.Minute
 	cfg.MCP.Reliability.Retry.MaxAttempts = 0
 	cfg.MCP.Reliability.CircuitBreaker.FailureThreshold = 0
+	cfg.MCP.Compat.ProtocolVersions = []string{"2023-01-01"}
+	cfg.MCP.Compat.StructuredContent = "sometimes"
+	cfg.MCP.CORS.AllowedOrigins = []string{"*"}
+	cfg.MCP.CORS.AllowCredentials = true
+	cfg.MCP.RateLimit.Enabled = true
+	cfg.MCP.RateLimit.Burst = 0
+	cfg.MCP.Host = "127.0.0.1:8080"
+	cfg.MCP.HTTP.BasePath = "taskbridge"
+	cfg.MCP.HTTP.TrustedProxies = []string{"10.0.0.0/8", "not-an-ip"}
 
 	issues := cfg.Validate()
 	if !hasIssue(issues, ValidationLevelWarning, "mcp.transport") {
@@ -71,6 +106,107 @@ func TestValidateCoversExpandedRules(t *testing.T) {
 	if !hasIssue(issues, ValidationLevelError, "mcp.tools.allow_list") {
 		t.Fatalf("expected allow/deny conflict error: %#v", issues)
 	}
+	if !hasIssue(issues, ValidationLevelError, "mcp.compat.protocol_versions") || !hasIssue(issues, ValidationLevelError, "mcp.compat.structured_content") {
+		t.Fatalf("expected compat errors: %#v", issues)
+	}
+	if !hasIssue(issues, ValidationLevelError, "mcp.cors.allow_credentials") {
+		t.Fatalf("expected cors wildcard credentials error: %#v", issues)
+	}
+	if !hasIssue(issues, ValidationLevelError, "mcp.rate_limit.burst") {
+		t.Fatalf("expected rate limit burst error: %#v", issues)
+	}
+	if !hasIssue(issues, ValidationLevelError, "mcp.host") {
+		t.Fatalf("expected host with port error: %#v", issues)
+	}
+	if !hasIssue(issues, ValidationLevelError, "mcp.http.base_path") || !hasIssue(issues, ValidationLevelError, "mcp.http.trusted_proxies") {
+		t.Fatalf("expected reverse proxy config errors: %#v", issues)
+	}
+}
+
+func TestValidateSyncPairs(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Sync.Pairs = []SyncPairConfig{
+		{Left: "todoist", Right: "microsoft", Conflict: "todoist"},
+		{Left: "google", Right: "google"},
+		{Left: "google", Right: "ticktick", Conflict: "feishu"},
+	}
+	cfg.Sync.Pairs[0].FieldMap.Priority = []SyncValuePair{{Left: "4", Right: "high"}}
+	cfg.Sync.Pairs[2].FieldMap.Status = []SyncValuePair{{Left: "done", Right: "completed"}}
+	cfg.Sync.Pairs[0].Source, cfg.Sync.Pairs[0].MirrorEdits = "microsoft", "flag"
+	cfg.Sync.Pairs[2].Source, cfg.Sync.Pairs[2].MirrorEdits = "todoist", "ignore"
+	cfg.Sync.Pairs[0].Schedule, cfg.Sync.Pairs[2].Schedule = "*/15 8-18 * * 1-5", "every weekday"
+	cfg.Sync.Pairs[0].OnDelete, cfg.Sync.Pairs[2].OnDelete = "archive", "trash"
+	issues := cfg.Validate()
+	if hasIssue(issues, ValidationLevelError, "sync.pairs[0]") || hasIssue(issues, ValidationLevelError, "sync.pairs[0].conflict") {
+		t.Fatalf("a provider of the pair is a valid source of truth: %#v", issues)
+	}
+	if hasIssue(issues, ValidationLevelError, "sync.pairs[0].field_map.priority[0]") || !hasIssue(issues, ValidationLevelError, "sync.pairs[2].field_map.status[0]") {
+		t.Fatalf("unexpected field map issues: %#v", issues)
+	}
+	if hasIssue(issues, ValidationLevelError, "sync.pairs[0].source") || hasIssue(issues, ValidationLevelError, "sync.pairs[0].mirror_edits") ||
+		!hasIssue(issues, ValidationLevelError, "sync.pairs[2].source") || !hasIssue(issues, ValidationLevelError, "sync.pairs[2].mirror_edits") {
+		t.Fatalf("unexpected one-way mirror issues: %#v", issues)
+	}
+	if hasIssue(issues, ValidationLevelError, "sync.pairs[0].schedule") || !hasIssue(issues, ValidationLevelError, "sync.pairs[2].schedule") {
+		t.Fatalf("unexpected schedule issues: %#v", issues)
+	}
+	if hasIssue(issues, ValidationLevelError, "sync.pairs[0].on_delete") || !hasIssue(issues, ValidationLevelError, "sync.pairs[2].on_delete") {
+		t.Fatalf("unexpected on_delete issues: %#v", issues)
+	}
+	if !hasIssue(issues, ValidationLevelError, "sync.pairs[1]") || !hasIssue(issues, ValidationLevelError, "sync.pairs[2].conflict") {
+		t.Fatalf("expected sync pair errors: %#v", issues)
+	}
+	if pair := cfg.Sync.Pair("microsoft", "todoist"); pair == nil || pair.Conflict != "todoist" {
+		t.Fatalf("pair lookup should ignore order, got %+v", pair)
+	}
+}
+
+func TestValidateAuthModes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MCP.Transport = "sse"
+	cfg.MCP.Security.Enabled = true
+	cfg.MCP.Security.AuthMode = "token"
+	if issues := cfg.Validate(); !hasIssue(issues, ValidationLevelError, "mcp.security.tokens") {
+		t.Fatalf("expected missing tokens error: %#v", issues)
+	}
+
+	cfg.MCP.Security.AuthMode = "oauth"
+	issues := cfg.Validate()
+	for _, field := range []string{"mcp.security.oauth.issuer", "mcp.security.oauth.resource", "mcp.security.auth_mode"} {
+		if !hasIssue(issues, ValidationLevelError, field) {
+			t.Fatalf("expected %s error: %#v", field, issues)
+		}
+	}
+
+	cfg.MCP.Transport = "streamable"
+	cfg.MCP.Security.OAuth = OAuthConfig{Issuer: "https://auth.example.com", Resource: "https://mcp.example.com/mcp"}
+	if issues := cfg.Validate(); hasIssue(issues, ValidationLevelError, "mcp.security.auth_mode") || hasIssue(issues, ValidationLevelError, "mcp.security.oauth.issuer") {
+		t.Fatalf("unexpected oauth errors: %#v", issues)
+	}
+}
+
+func TestValidateTenantPrincipals(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MCP.Tenant.Enabled = true
+	cfg.MCP.Tenant.Principals = map[string]TenantPrincipalConfig{
+		"alice":  {APIKeys: []string{"shared-key"}},
+		"bob":    {APIKeys: []string{"shared-key"}, Subjects: []string{"user-2"}},
+		"../etc": {Subjects: []string{"user-3"}},
+	}
+	issues := cfg.Validate()
+	if !hasIssue(issues, ValidationLevelError, "mcp.tenant.principals.bob") {
+		t.Fatalf("expected duplicate api key error: %#v", issues)
+	}
+	if !hasIssue(issues, ValidationLevelError, "mcp.tenant.principals.../etc") {
+		t.Fatalf("expected invalid tenant key error: %#v", issues)
+	}
+
+	cfg.MCP.Tenant.Principals = map[string]TenantPrincipalConfig{
+		"alice": {APIKeys: []string{"alice-key"}, Subjects: []string{"alice-key"}},
+	}
+	if issues := cfg.Validate(); hasIssue(issues, ValidationLevelError, "mcp.tenant.principals.alice") {
+		t.Fatalf("api keys and subjects are matched separately: %#v", issues)
+	}
 }
 
 func TestLoadPreservesDefaultsForMissingNewFields(t *testing.T) {
@@ -97,6 +233,106 @@ func TestLoadPreservesDefaultsForMissingNewFields(t *testing.T) {
 	}
 }
 
+func TestLoadFileSupportsTOMLAndKeepsProviderDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.toml")
+	content := []byte("[app]\nlog_format = \"console\"\n\n[mcp]\nport = 9000\n\n[adapters.todoist]\nenabled = true\n")
+	if err := os.WriteFile(configPath, content, 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, used, err := LoadFile(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if used != configPath {
+		t.Fatalf("expected config file %s, got %s", configPath, used)
+	}
+	if cfg.App.LogFormat != "console" || cfg.App.LogOutput != "stderr" {
+		t.Fatalf("unexpected logging config: %+v", cfg.App)
+	}
+	if cfg.MCP.Port != 9000 || cfg.MCP.Transport != "stdio" {
+		t.Fatalf("unexpected mcp config: port=%d transport=%s", cfg.MCP.Port, cfg.MCP.Transport)
+	}
+	if !cfg.Adapters.Enabled("todoist") || cfg.Adapters.Enabled("google") {
+		t.Fatalf("unexpected adapters: %+v", cfg.Adapters)
+	}
+	if cfg.Templates.JSON.Path != "./templates/json/default.json" {
+		t.Fatalf("expected template defaults, got %q", cfg.Templates.JSON.Path)
+	}
+}
+
+func TestLoadFileMissingExplicitPath(t *testing.T) {
+	if _, _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatalf("expected error for missing explicit config file")
+	}
+}
+
+func TestLoadWithFlagsPrecedence(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	content := []byte("storage:\n  path: ./from-file\nmcp:\n  port: 9000\n  host: 10.0.0.1\n  cors:\n    allowed_headers: [Content-Type]\n")
+	if err := os.WriteFile(configPath, content, 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("TASKBRIDGE_MCP_PORT", "9100")
+	t.Setenv("TASKBRIDGE_STORAGE_PATH", "./from-env")
+	t.Setenv("TASKBRIDGE_MCP_HTTP_TRUSTED_PROXIES", " 10.0.0.0/8 , 127.0.0.1 ")
+	t.Setenv("TASKBRIDGE_MCP_SESSION_KEEP_ALIVE", "5s")
+	t.Setenv("TASKBRIDGE_LOG_LEVEL", "warn")
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("storage-path", "", "")
+	flags.String("host", "", "")
+	if err := flags.Parse([]string{"--storage-path", "./from-flag"}); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+
+	cfg, _, err := LoadWithFlags(configPath, FlagBindings{
+		"storage.path": flags.Lookup("storage-path"),
+		"mcp.host":     flags.Lookup("host"),
+	})
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if cfg.Storage.Path != "./from-flag" {
+		t.Fatalf("flag should override env and file, got %s", cfg.Storage.Path)
+	}
+	if cfg.MCP.Port != 9100 {
+		t.Fatalf("env should override file, got %d", cfg.MCP.Port)
+	}
+	if cfg.MCP.Host != "10.0.0.1" {
+		t.Fatalf("unset flag should not override file, got %q", cfg.MCP.Host)
+	}
+	if len(cfg.MCP.HTTP.TrustedProxies) != 2 || cfg.MCP.HTTP.TrustedProxies[1] != "127.0.0.1" {
+		t.Fatalf("expected comma separated env list, got %#v", cfg.MCP.HTTP.TrustedProxies)
+	}
+	if cfg.MCP.Session.KeepAlive != 5*time.Second {
+		t.Fatalf("expected duration from env, got %s", cfg.MCP.Session.KeepAlive)
+	}
+	if cfg.App.LogLevel != "warn" {
+		t.Fatalf("expected legacy env alias to apply, got %s", cfg.App.LogLevel)
+	}
+	if len(cfg.MCP.CORS.AllowedHeaders) != 1 {
+		t.Fatalf("file list should replace default list, got %#v", cfg.MCP.CORS.AllowedHeaders)
+	}
+}
+
+func TestLoadEnvTokensEnableTokenAuth(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("mcp:\n  transport: streamable\n"), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("TASKBRIDGE_MCP_TOKENS", "alpha, beta")
+	cfg, _, err := LoadFile(configPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if !cfg.MCP.Security.Enabled || cfg.MCP.Security.AuthMode != "token" || len(cfg.MCP.Security.Tokens) != 2 {
+		t.Fatalf("expected env tokens to enable token auth, got %+v", cfg.MCP.Security)
+	}
+}
+
 func hasIssue(issues []ValidationIssue, level, field string) bool {
 	for _, issue := range issues {
 		if issue.Level == level && issue.Field == field {
@@ -105,3 +341,232 @@ func hasIssue(issues []ValidationIssue, level, field string) bool {
 	}
 	return false
 }
+
+func TestLoadResolvesSecretRefs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("TASKBRIDGE_HOME", home)
+	store := secretstore.NewFileStore(filepath.Join(home, "credentials", secretstore.SecretsFileName))
+	if err := store.Set("google-client-secret", "s3cret"); err != nil {
+		t.Fatalf("set secret: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "adapters:\n  google:\n    client_id: google-client\n    client_secret: secret:google-client-secret\n  todoist:\n    api_token: secret:missing\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, _, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Adapters.Get("google").ClientSecret != "s3cret" {
+		t.Fatalf("expected resolved client secret, got %q", cfg.Adapters.Get("google").ClientSecret)
+	}
+	found := false
+	for _, issue := range cfg.Validate() {
+		if issue.Field == "adapters.todoist.api_token" && issue.Level == ValidationLevelError {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected unresolved secret reference to be reported")
+	}
+}
+
+func TestLoadRecordsSourcesAndRedactsSecretRefs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("TASKBRIDGE_HOME", home)
+	t.Setenv("TASKBRIDGE_MCP_HOST", "0.0.0.0")
+	store := secretstore.NewFileStore(filepath.Join(home, "credentials", secretstore.SecretsFileName))
+	if err := store.Set("todoist-url", "https://internal.example"); err != nil {
+		t.Fatalf("set secret: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "mcp:\n  port: 9000\n  host: 127.0.0.1\nadapters:\n  todoist:\n    base_url: secret:todoist-url\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, _, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	for key, want := range map[string]string{
+		"mcp.port":                  "file " + path,
+		"mcp.host":                  "env TASKBRIDGE_MCP_HOST",
+		"mcp.transport":             SourceDefault,
+		"adapters.todoist.base_url": "file " + path + " (secret:todoist-url)",
+	} {
+		if got := cfg.Source(key); got != want {
+			t.Fatalf("source of %s = %q, want %q", key, got, want)
+		}
+	}
+
+	adapters := cfg.RedactedMap()["adapters"].(map[string]any)
+	if got := adapters["todoist"].(map[string]any)["base_url"]; got != redactedValue {
+		t.Fatalf("expected secret reference value masked, got %v", got)
+	}
+}
+
+func TestLoadProfileOverlay(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("TASKBRIDGE_HOME", home)
+	t.Setenv("TASKBRIDGE_PROFILE", "work")
+	if err := os.WriteFile(filepath.Join(home, "config.yaml"), []byte("mcp:\n  port: 9000\n  host: 0.0.0.0\n"), 0600); err != nil {
+		t.Fatalf("write base config: %v", err)
+	}
+	profileDir := filepath.Join(home, "profiles", "work")
+	if err := os.MkdirAll(profileDir, 0700); err != nil {
+		t.Fatalf("mkdir profile: %v", err)
+	}
+	profileConfig := filepath.Join(profileDir, "config.yaml")
+	if err := os.WriteFile(profileConfig, []byte("mcp:\n  port: 9100\n"), 0600); err != nil {
+		t.Fatalf("write profile config: %v", err)
+	}
+
+	cfg, used, err := LoadFile("")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if used != profileConfig {
+		t.Fatalf("expected profile config reported as used, got %q", used)
+	}
+	if cfg.MCP.Port != 9100 || cfg.MCP.Host != "0.0.0.0" {
+		t.Fatalf("expected profile port over base host, got %s:%d", cfg.MCP.Host, cfg.MCP.Port)
+	}
+	if cfg.Storage.Path != filepath.Join(profileDir, "data") {
+		t.Fatalf("expected per-profile default storage path, got %s", cfg.Storage.Path)
+	}
+	if got := cfg.App.ResolvedLogOutput(); got != cfg.App.LogOutput {
+		t.Fatalf("expected stderr log output unchanged, got %s", got)
+	}
+	cfg.App.LogOutput = "file"
+	if got := cfg.App.ResolvedLogOutput(); got != filepath.Join(profileDir, "logs", LogFileName) {
+		t.Fatalf("expected log file in profile logs dir, got %s", got)
+	}
+}
+
+func TestLoadEnvironmentOverlay(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("TASKBRIDGE_HOME", home)
+	t.Setenv("TASKBRIDGE_PROFILE", "work")
+	t.Setenv(EnvironmentEnv, "prod")
+	profileDir := filepath.Join(home, "profiles", "work")
+	if err := os.MkdirAll(profileDir, 0700); err != nil {
+		t.Fatalf("mkdir profile: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join(home, "config.yaml"):            "mcp:\n  transport: stdio\n  port: 9000\n",
+		filepath.Join(home, "config.prod.yaml"):       "mcp:\n  transport: streamable\n  host: 0.0.0.0\n  port: 9443\n",
+		filepath.Join(profileDir, "config.yaml"):      "mcp:\n  port: 9100\n",
+		filepath.Join(profileDir, "config.prod.toml"): "[mcp]\nhost = \"10.0.0.1\"\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+
+	cfg, _, err := LoadFile("")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	// 合并顺序：config.yaml < config.prod.yaml < profile config.yaml < profile config.prod.toml
+	if cfg.MCP.Transport != "streamable" || cfg.MCP.Port != 9100 || cfg.MCP.Host != "10.0.0.1" {
+		t.Fatalf("unexpected merged mcp config %s %s:%d", cfg.MCP.Transport, cfg.MCP.Host, cfg.MCP.Port)
+	}
+	if len(cfg.Files()) != 4 || cfg.Source("mcp.transport") != "file "+filepath.Join(home, "config.prod.yaml") {
+		t.Fatalf("unexpected files %v or transport source %q", cfg.Files(), cfg.Source("mcp.transport"))
+	}
+
+	t.Setenv(EnvironmentEnv, "staging")
+	if _, _, err := LoadFile(""); err == nil || !strings.Contains(err.Error(), "config.staging.yaml") {
+		t.Fatalf("expected missing overlay error, got %v", err)
+	}
+}
+
+func TestLoadAdaptersAndLegacyProviders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `adapters:
+  todoist:
+    enabled: true
+    base_url: https://todoist.example.com/api
+    default_project: Inbox
+    field_mappings:
+      priority: p
+    rate_limit:
+      requests_per_minute: 60
+    sync_labels: true
+  google:
+    enabled: false
+providers:
+  microsoft:
+    enabled: true
+    clientid: ms-client
+    listnames: Tasks,Work
+  google:
+    enabled: true
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, _, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	todoist := cfg.Adapters.Get("todoist")
+	if !todoist.Enabled || todoist.BaseURL != "https://todoist.example.com/api" || todoist.DefaultProject != "Inbox" {
+		t.Fatalf("unexpected todoist adapter: %+v", todoist)
+	}
+	if todoist.FieldMappings["priority"] != "p" || todoist.RateLimit.RequestsPerMinute != 60 || todoist.Options["sync_labels"] != true {
+		t.Fatalf("unexpected todoist adapter options: %+v", todoist)
+	}
+
+	microsoft := cfg.Adapters.Get("microsoft")
+	if !microsoft.Enabled || microsoft.ClientID != "ms-client" || len(microsoft.ListNames) != 2 {
+		t.Fatalf("expected legacy providers.microsoft migrated, got %+v", microsoft)
+	}
+	if cfg.Adapters.Enabled("google") {
+		t.Fatalf("adapters.google should take precedence over legacy providers.google")
+	}
+	warnings := 0
+	for _, issue := range cfg.Validate() {
+		if issue.Level == ValidationLevelWarning && strings.HasPrefix(issue.Field, "providers.") {
+			warnings++
+		}
+	}
+	if warnings != 2 {
+		t.Fatalf("expected deprecation warning per legacy provider block, got %d", warnings)
+	}
+}
+
+func TestLoadSelectsEncryptedBackendWithPassphrase(t *testing.T) {
+	t.Setenv("TASKBRIDGE_HOME", t.TempDir())
+	t.Setenv(secretstore.PassphraseEnv, "correct horse")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("mcp:\n  port: 9000\n"), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, _, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Secrets.Backend != secretstore.BackendEncrypted || cfg.Source("secrets.backend") != "env "+secretstore.PassphraseEnv {
+		t.Fatalf("expected encrypted backend from passphrase, got %q (%s)", cfg.Secrets.Backend, cfg.Source("secrets.backend"))
+	}
+
+	// 显式配置的后端优先
+	if err := os.WriteFile(path, []byte("secrets:\n  backend: file\n"), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if cfg, _, err = LoadFile(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Secrets.Backend != secretstore.BackendFile {
+		t.Fatalf("expected explicit file backend kept, got %q", cfg.Secrets.Backend)
+	}
+}
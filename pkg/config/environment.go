@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// EnvironmentEnv 选择配置环境的环境变量
+const EnvironmentEnv = "TASKBRIDGE_ENV"
+
+// environmentName SetEnvironment 设置的配置环境，优先于 TASKBRIDGE_ENV
+var environmentName string
+
+var environmentNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// SetEnvironment 设置当前配置环境（如 --env 参数），空字符串表示使用 TASKBRIDGE_ENV
+func SetEnvironment(name string) error {
+	name = strings.TrimSpace(name)
+	if name != "" && !environmentNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid environment name %q: use letters, digits, - and _", name)
+	}
+	environmentName = name
+	return nil
+}
+
+// Environment 返回当前配置环境：SetEnvironment > TASKBRIDGE_ENV；未选择时返回空字符串
+func Environment() string {
+	name := environmentName
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv(EnvironmentEnv))
+	}
+	if !environmentNamePattern.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+// environmentOverlayFile 返回配置文件在指定环境下的叠加文件，如 config.yaml -> config.prod.yaml；
+// 优先使用与原文件相同的格式，不存在时返回空字符串
+func environmentOverlayFile(path, env string) string {
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(path, ext)
+	for _, candidate := range []string{ext, ".yaml", ".yml", ".toml", ".json"} {
+		if candidate == "" {
+			continue
+		}
+		overlay := stem + "." + env + candidate
+		if _, err := os.Stat(overlay); err == nil {
+			return overlay
+		}
+	}
+	return ""
+}
+
+// environmentOverlayName 返回配置文件在指定环境下叠加文件的默认名称，用于错误提示
+func environmentOverlayName(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
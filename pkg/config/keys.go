@@ -0,0 +1,409 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// redactedValue 替换敏感配置值的占位符
+const redactedValue = "******"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// configKey 配置结构中可通过单个键设置的字段
+type configKey struct {
+	key string
+	typ reflect.Type
+}
+
+// tagName 返回字段的 mapstructure 键名，未导出或 ,remain 字段返回空字符串
+func tagName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// leafKeys 按 mapstructure 标签列出可用单个值表示的配置键（标量与字符串列表）；
+// map 与结构体列表（如 mcp.upstreams）只能在配置文件中整体编写，不在其中。
+func leafKeys(t reflect.Type, prefix string) []configKey {
+	var keys []configKey
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := tagName(field)
+		if name == "" {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			keys = append(keys, leafKeys(field.Type, key)...)
+			continue
+		case reflect.Map, reflect.Interface:
+			continue
+		case reflect.Slice:
+			if field.Type.Elem().Kind() != reflect.String {
+				continue
+			}
+		}
+		keys = append(keys, configKey{key: key, typ: field.Type})
+	}
+	return keys
+}
+
+// Keys 返回全部可单独设置的配置键，按名称排序
+func Keys() []string {
+	leaves := leafKeys(reflect.TypeOf(Config{}), "")
+	keys := make([]string, 0, len(leaves))
+	for _, leaf := range leaves {
+		keys = append(keys, leaf.key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookupKey 返回配置键的字段类型；键可以穿过以名称索引的配置块（如 adapters.todoist.enabled），
+// 适配器的扩展选项按字符串处理
+func lookupKey(key string) (reflect.Type, bool) {
+	return leafType(reflect.TypeOf(Config{}), strings.Split(strings.ToLower(strings.TrimSpace(key)), "."))
+}
+
+func leafType(t reflect.Type, segments []string) (reflect.Type, bool) {
+	if len(segments) == 0 {
+		switch t.Kind() {
+		case reflect.Struct:
+			return t, t == durationType
+		case reflect.Map, reflect.Interface:
+			return nil, false
+		case reflect.Slice:
+			return t, t.Elem().Kind() == reflect.String
+		default:
+			return t, true
+		}
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return leafType(t.Elem(), segments[1:])
+	case reflect.Struct:
+		if t == durationType {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if tagName(field) == segments[0] {
+			return leafType(field.Type, segments[1:])
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Contains(t.Field(i).Tag.Get("mapstructure"), ",remain") && len(segments) == 1 {
+			return reflect.TypeOf(""), true
+		}
+	}
+	return nil, false
+}
+
+// ToMap 将配置转换为以配置键（mapstructure 标签）组织的嵌套 map，时长以 "30s" 形式表示，
+// 输出可直接作为配置文件使用。
+func ToMap(cfg *Config) map[string]any {
+	return valueToMap(reflect.ValueOf(cfg).Elem()).(map[string]any)
+}
+
+func valueToMap(v reflect.Value) any {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if strings.Contains(field.Tag.Get("mapstructure"), ",remain") {
+				// provider 的扩展字段与普通字段平铺在同一层
+				for _, key := range v.Field(i).MapKeys() {
+					out[key.String()] = valueToMap(v.Field(i).MapIndex(key))
+				}
+				continue
+			}
+			if name := tagName(field); name != "" {
+				out[name] = valueToMap(v.Field(i))
+			}
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		for _, key := range v.MapKeys() {
+			out[fmt.Sprint(key.Interface())] = valueToMap(v.MapIndex(key))
+		}
+		return out
+	case reflect.Slice:
+		out := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out[i] = valueToMap(v.Index(i))
+		}
+		return out
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return valueToMap(v.Elem())
+	default:
+		return v.Interface()
+	}
+}
+
+// Redact 将 ToMap 结果中的敏感值（token、secret、password、API key 等）替换为占位符，
+// 用于显示配置或附在问题报告中。
+func Redact(settings map[string]any) map[string]any {
+	out := make(map[string]any, len(settings))
+	for key, value := range settings {
+		switch typed := value.(type) {
+		case map[string]any:
+			if sensitiveKey(key) {
+				out[key] = redactMapValues(typed)
+			} else {
+				out[key] = Redact(typed)
+			}
+		case []any:
+			items := make([]any, len(typed))
+			for i, item := range typed {
+				switch {
+				case sensitiveKey(key) && item != "":
+					items[i] = redactedValue
+				case isMap(item):
+					items[i] = Redact(item.(map[string]any))
+				default:
+					items[i] = item
+				}
+			}
+			out[key] = items
+		default:
+			if sensitiveKey(key) && value != nil && value != "" {
+				out[key] = redactedValue
+			} else {
+				out[key] = value
+			}
+		}
+	}
+	return out
+}
+
+func isMap(value any) bool {
+	_, ok := value.(map[string]any)
+	return ok
+}
+
+// redactMapValues 遮盖 map 中的全部值（如上游服务的环境变量）
+func redactMapValues(values map[string]any) map[string]any {
+	out := make(map[string]any, len(values))
+	for key := range values {
+		out[key] = redactedValue
+	}
+	return out
+}
+
+// sensitiveKey 按键名判断是否为敏感配置；token_file 等路径类键不视为敏感
+func sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if key == "env" {
+		return true
+	}
+	if strings.HasSuffix(key, "_file") {
+		return false
+	}
+	for _, marker := range []string{"secret", "password", "token", "apikey", "api_key", "dsn"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get 按点分隔的配置键读取 ToMap 结果中的值，键可以指向单个值或整个配置段
+func Get(settings map[string]any, key string) (any, bool) {
+	var current any = settings
+	for _, segment := range strings.Split(strings.ToLower(strings.TrimSpace(key)), ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[segment]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// ParseValue 按配置键的类型解析命令行给出的字符串值；列表用逗号分隔，时长如 10m
+func ParseValue(key, raw string) (any, error) {
+	typ, ok := lookupKey(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown config key: %s", key)
+	}
+	raw = strings.TrimSpace(raw)
+	switch {
+	case typ == durationType:
+		if _, err := time.ParseDuration(raw); err != nil {
+			return nil, fmt.Errorf("%s must be a duration (e.g. 30s, 5m): %w", key, err)
+		}
+		return raw, nil
+	case typ.Kind() == reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", key)
+		}
+		return value, nil
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", key)
+		}
+		return value, nil
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", key)
+		}
+		return value, nil
+	case typ.Kind() == reflect.Slice:
+		items := make([]any, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	default:
+		return raw, nil
+	}
+}
+
+// readFileSettings 读取配置文件本身的内容（不含默认值与环境变量），文件不存在时返回空 map
+func readFileSettings(path string) (map[string]any, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return map[string]any{}, nil
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	return v.AllSettings(), nil
+}
+
+// SetFileValue 将单个配置键写入配置文件（不存在时创建），写入前校验结果，存在错误时不修改文件
+func SetFileValue(path, key string, value any) error {
+	settings, err := readFileSettings(path)
+	if err != nil {
+		return err
+	}
+	current := settings
+	segments := strings.Split(strings.ToLower(strings.TrimSpace(key)), ".")
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := current[segment].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			current[segment] = next
+		}
+		current = next
+	}
+	current[segments[len(segments)-1]] = value
+	return writeFileSettings(path, settings)
+}
+
+// UnsetFileValue 从配置文件中删除配置键，使其回退到默认值；键不存在时返回 false
+func UnsetFileValue(path, key string) (bool, error) {
+	settings, err := readFileSettings(path)
+	if err != nil {
+		return false, err
+	}
+	segments := strings.Split(strings.ToLower(strings.TrimSpace(key)), ".")
+	current := settings
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := current[segment].(map[string]any)
+		if !ok {
+			return false, nil
+		}
+		current = next
+	}
+	last := segments[len(segments)-1]
+	if _, ok := current[last]; !ok {
+		return false, nil
+	}
+	delete(current, last)
+	return true, writeFileSettings(path, settings)
+}
+
+// writeFileSettings 先写入同目录的临时文件并完整加载、校验，通过后再替换原文件
+func writeFileSettings(path string, settings map[string]any) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+	ext := filepath.Ext(path)
+	if ext == "" {
+		return fmt.Errorf("config file needs an extension (.yaml, .toml or .json): %s", path)
+	}
+	tmp, err := os.CreateTemp(dir, "."+strings.TrimSuffix(filepath.Base(path), ext)+".*"+ext)
+	if err != nil {
+		return fmt.Errorf("error creating temp config file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	defer os.Remove(tmpPath)
+
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return err
+	}
+	if err := v.WriteConfigAs(tmpPath); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	loaded, _, err := LoadFile(tmpPath)
+	var schemaErr *SchemaError
+	if errors.As(err, &schemaErr) {
+		schemaErr.File = path
+	}
+	if err != nil {
+		return err
+	}
+	for _, issue := range loaded.Validate() {
+		if issue.Level == ValidationLevelError {
+			return fmt.Errorf("invalid config: %s: %s", issue.Field, issue.Message)
+		}
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// ValidateFile 按 Schema 校验配置文件本身：未知的配置键（多为拼写错误）、类型不符或缺少必填项，
+// 以及完整加载时的其他错误
+func ValidateFile(path string) ([]ValidationIssue, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	issues := validateSettings(v.AllSettings())
+	if len(issues) > 0 {
+		return issues, nil
+	}
+	if _, _, err := LoadFile(path); err != nil {
+		issues = append(issues, ValidationIssue{Level: ValidationLevelError, Field: "config", Message: err.Error()})
+	}
+	return issues, nil
+}
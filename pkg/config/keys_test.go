@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestToMapRedactsSecrets(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MCP.Security.Tokens = []string{"secret-token"}
+	cfg.MCP.Security.TokenFile = "/run/secrets/tokens"
+	cfg.Adapters["google"] = AdapterConfig{ClientSecret: "google-secret"}
+
+	settings := Redact(ToMap(cfg))
+	if value, _ := Get(settings, "mcp.security.tokens"); value.([]any)[0] != redactedValue {
+		t.Fatalf("expected tokens redacted, got %v", value)
+	}
+	if value, _ := Get(settings, "mcp.security.token_file"); value != "/run/secrets/tokens" {
+		t.Fatalf("expected token_file kept, got %v", value)
+	}
+	if value, _ := Get(settings, "adapters.google.client_secret"); value != redactedValue {
+		t.Fatalf("expected client secret redacted, got %v", value)
+	}
+	if value, _ := Get(settings, "mcp.session.keep_alive"); value != "30s" {
+		t.Fatalf("expected duration as string, got %v", value)
+	}
+	if _, ok := Get(settings, "mcp.no_such_key"); ok {
+		t.Fatalf("expected unknown key lookup to fail")
+	}
+}
+
+func TestSetAndUnsetFileValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	port, err := ParseValue("mcp.port", "9090")
+	if err != nil {
+		t.Fatalf("parse port: %v", err)
+	}
+	if err := SetFileValue(path, "mcp.port", port); err != nil {
+		t.Fatalf("set port: %v", err)
+	}
+	origins, _ := ParseValue("mcp.cors.allowed_origins", "https://a.example.com, https://b.example.com")
+	if err := SetFileValue(path, "mcp.cors.allowed_origins", origins); err != nil {
+		t.Fatalf("set origins: %v", err)
+	}
+	enabled, err := ParseValue("adapters.todoist.enabled", "true")
+	if err != nil {
+		t.Fatalf("parse adapter key: %v", err)
+	}
+	if err := SetFileValue(path, "adapters.todoist.enabled", enabled); err != nil {
+		t.Fatalf("set adapter: %v", err)
+	}
+	interval, _ := ParseValue("sync.interval", "10m")
+	if err := SetFileValue(path, "sync.interval", interval); err != nil {
+		t.Fatalf("set interval: %v", err)
+	}
+
+	cfg, _, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !cfg.Adapters.Enabled("todoist") {
+		t.Fatalf("expected adapters.todoist.enabled set")
+	}
+	if cfg.MCP.Port != 9090 || len(cfg.MCP.CORS.AllowedOrigins) != 2 || cfg.Sync.Interval != 10*time.Minute {
+		t.Fatalf("unexpected config after set: port=%d origins=%v interval=%s", cfg.MCP.Port, cfg.MCP.CORS.AllowedOrigins, cfg.Sync.Interval)
+	}
+
+	removed, err := UnsetFileValue(path, "mcp.port")
+	if err != nil || !removed {
+		t.Fatalf("unset port: removed=%v err=%v", removed, err)
+	}
+	cfg, _, _ = LoadFile(path)
+	if cfg.MCP.Port != DefaultConfig().MCP.Port {
+		t.Fatalf("expected default port after unset, got %d", cfg.MCP.Port)
+	}
+}
+
+func TestSetFileValueRejectsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("mcp:\n  port: 8080\n"), 0600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := ParseValue("mcp.port", "abc"); err == nil {
+		t.Fatalf("expected non-numeric port to be rejected")
+	}
+	if _, err := ParseValue("mcp.no_such_key", "1"); err == nil {
+		t.Fatalf("expected unknown key to be rejected")
+	}
+	if err := SetFileValue(path, "mcp.transport", "carrier-pigeon"); err == nil {
+		t.Fatalf("expected invalid transport to be rejected")
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "mcp:\n  port: 8080\n" {
+		t.Fatalf("config file should be untouched, got %q", data)
+	}
+}
+
+func TestValidateFileReportsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "mcp:\n  prot: 8080\n  upstreams:\n    - name: a\n      command: a-server\n      env:\n        API_KEY: x\nadapters:\n  google:\n    custom_field: y\n    rate_limit:\n      brust: 1\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	issues, err := ValidateFile(path)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(issues) != 2 || issues[0].Field != "adapters.google.rate_limit.brust" || issues[1].Field != "mcp.prot" {
+		t.Fatalf("expected only typos reported, got %+v", issues)
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SourceDefault 配置值来自默认值
+const SourceDefault = "default"
+
+// configFileSource 参与合并的配置文件及其内容
+type configFileSource struct {
+	label    string
+	settings map[string]any
+}
+
+// Source 返回配置键的值来源，如 "default"、"file /etc/taskbridge/config.yaml"、"env TASKBRIDGE_MCP_PORT"、
+// "flag --port"；值由 secret: 引用解析而来时附带引用名称。键为 ToMap 结果中的叶子键，未知键返回 default
+func (c *Config) Source(key string) string {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// SetSource 记录配置键的值来源，供加载配置后再覆盖配置值的调用方（如 --providers）使用
+func (c *Config) SetSource(key, source string) {
+	if c.sources == nil {
+		c.sources = map[string]string{}
+	}
+	c.sources[key] = source
+}
+
+// RedactedMap 返回遮盖敏感值后的 ToMap 结果：除按键名判断的敏感值外，由 secret: 引用解析出的值也会被遮盖
+func (c *Config) RedactedMap() map[string]any {
+	settings := Redact(ToMap(c))
+	keys := make([]string, 0, len(c.secretRefs))
+	for key := range c.secretRefs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		maskKey(settings, strings.Split(key, "."))
+	}
+	return settings
+}
+
+// maskKey 遮盖 settings 中的键；途经列表时遮盖每个元素中的同名键
+func maskKey(value any, segments []string) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		if len(segments) == 0 {
+			return typed
+		}
+		if child, ok := typed[segments[0]]; ok {
+			typed[segments[0]] = maskKey(child, segments[1:])
+		}
+		return typed
+	case []any:
+		for i, item := range typed {
+			typed[i] = maskKey(item, segments)
+		}
+		return typed
+	default:
+		if len(segments) == 0 && value != nil && value != "" {
+			return redactedValue
+		}
+		return value
+	}
+}
+
+// recordSources 按 命令行参数 > 环境变量 > 配置文件 > 默认值 的优先级记录每个配置键的来源
+func recordSources(cfg *Config, files []configFileSource, flags FlagBindings) {
+	fileKeys := map[string]string{}
+	for _, file := range files {
+		for _, key := range flattenKeys(file.settings, "") {
+			fileKeys[key] = file.label
+		}
+		// 旧版 providers 配置块迁移到 adapters，未同时配置 adapters 时按迁移后的键记录
+		legacy, _ := file.settings["providers"].(map[string]any)
+		configured, _ := file.settings["adapters"].(map[string]any)
+		for name, block := range legacy {
+			if _, ok := configured[name]; ok {
+				continue
+			}
+			for _, key := range flattenKeys(block, "") {
+				head, rest, _ := strings.Cut(key, ".")
+				if renamed, ok := legacyProviderKeys[head]; ok {
+					head = renamed
+				}
+				fileKeys[joinKey("adapters."+name+"."+head, rest)] = file.label
+			}
+		}
+	}
+
+	envKeys := map[string]bool{}
+	for _, leaf := range leafKeys(reflect.TypeOf(Config{}), "") {
+		envKeys[leaf.key] = true
+	}
+
+	cfg.sources = map[string]string{}
+	for _, key := range flattenKeys(ToMap(cfg), "") {
+		source := SourceDefault
+		if label, ok := fileKeys[key]; ok {
+			source = label
+		}
+		if envKeys[key] {
+			if name := envSource(key); name != "" {
+				source = "env " + name
+			}
+		}
+		if flag := flags[key]; flag != nil && flag.Changed {
+			source = "flag --" + flag.Name
+		}
+		cfg.sources[key] = source
+	}
+}
+
+// envSource 返回为配置键提供值的环境变量名（规范名称优先于旧名称），未设置时返回空字符串
+func envSource(key string) string {
+	for _, name := range []string{EnvVar(key), envAliases[key]} {
+		if name != "" && os.Getenv(name) != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// flattenKeys 列出嵌套 map 中的叶子键（列表视为叶子），按名称排序
+func flattenKeys(value any, prefix string) []string {
+	settings, ok := value.(map[string]any)
+	if !ok {
+		if prefix == "" {
+			return nil
+		}
+		return []string{prefix}
+	}
+	var keys []string
+	for key, child := range settings {
+		keys = append(keys, flattenKeys(child, joinKey(prefix, key))...)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// secretSource 在来源后附加解析值所用的 secret 引用
+func secretSource(source, name string) string {
+	return fmt.Sprintf("%s (secret:%s)", source, name)
+}
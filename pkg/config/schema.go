@@ -0,0 +1,383 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+
+	"github.com/yeisme/taskbridge/pkg/secretstore"
+)
+
+// durationPattern Go 时长格式，如 30s、1m30s
+const durationPattern = `^(0|-?([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+
+// SchemaError 配置文件不符合配置 schema，Issues 列出全部问题
+type SchemaError struct {
+	File   string
+	Issues []ValidationIssue
+}
+
+func (e *SchemaError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid config file %s:", e.File)
+	for _, issue := range e.Issues {
+		fmt.Fprintf(&b, "\n  %s: %s", issue.Field, issue.Message)
+	}
+	return b.String()
+}
+
+// schemaRules 补充无法从字段类型推导的约束。键为配置键，* 匹配 map 中的任意名称，[] 表示列表元素
+var schemaRules = map[string]func(s *jsonschema.Schema){
+	"app.log_level":                 schemaEnum("debug", "info", "warn", "warning", "error", "fatal", "panic", "trace", "disabled", "none"),
+	"app.log_format":                schemaEnum("json", "console"),
+	"sync.mode":                     schemaEnum("once", "interval", "realtime"),
+	"sync.state_store":              schemaEnum("sqlite", "file"),
+	"storage.encryption.key_source": schemaEnum("passphrase", "keychain"),
+	"storage.embeddings.provider":   schemaEnum("ollama", "openai", "hash"),
+	"storage.embeddings.dimensions": schemaMinimum(0),
+	"storage.embeddings.batch_size": schemaMinimum(0),
+	"sync.pairs[]": func(s *jsonschema.Schema) {
+		s.Required = []string{"left", "right"}
+	},
+	"sync.pairs[].filter.exclude_completed_older_than_days": schemaMinimum(0),
+	"sync.pairs[].mirror_edits":                             schemaEnum("overwrite", "flag"),
+	"sync.pairs[].on_delete":                                schemaEnum("delete", "archive"),
+	"secrets.backend": func(s *jsonschema.Schema) {
+		// 外部后端可通过 secretstore.Register 注册，因此在生成 schema 时再取后端列表
+		schemaEnum(secretstore.Backends()...)(s)
+	},
+	"mcp.port":                                          schemaRange(0, 65535),
+	"mcp.security.auth_mode":                            schemaEnum("none", "token", "oauth", "mutual_tls"),
+	"mcp.observability.audit.output":                    schemaEnum("stdout", "file"),
+	"mcp.observability.trace.sample_rate":               schemaRange(0, 1),
+	"mcp.cache.backend":                                 schemaEnum("memory", "file"),
+	"mcp.reliability.retry.max_attempts":                schemaMinimum(1),
+	"mcp.reliability.circuit_breaker.failure_threshold": schemaMinimum(1),
+	"mcp.session.event_store":                           schemaEnum("none", "memory", "sqlite"),
+	"mcp.session.event_store_max_bytes":                 schemaMinimum(0),
+	"mcp.compat.structured_content":                     schemaEnum("auto", "always", "never"),
+	"mcp.compat.protocol_versions[]":                    schemaEnum(SupportedProtocolVersions...),
+	"mcp.cors.max_age":                                  schemaMinimum(0),
+	"mcp.http.max_request_bytes":                        schemaMinimum(0),
+	"mcp.upstreams[]": func(s *jsonschema.Schema) {
+		s.Required = []string{"name"}
+		s.If = &jsonschema.Schema{
+			Required:   []string{"transport"},
+			Properties: map[string]*jsonschema.Schema{"transport": {Enum: []any{"streamable", "sse"}}},
+		}
+		s.Then = &jsonschema.Schema{Required: []string{"url"}, Description: "transport 为 streamable 或 sse 时需要 url"}
+		s.Else = &jsonschema.Schema{Required: []string{"command"}, Description: "stdio 传输（默认）需要 command"}
+	},
+	"mcp.upstreams[].transport": schemaEnum("stdio", "streamable", "sse"),
+	"adapters.*": func(s *jsonschema.Schema) {
+		s.DependentRequired = map[string][]string{
+			"client_secret": {"client_id"},
+			"app_secret":    {"app_id"},
+		}
+	},
+	"adapters.*.rate_limit.requests_per_minute": schemaMinimum(0),
+	"adapters.*.rate_limit.burst":               schemaMinimum(0),
+}
+
+func schemaEnum(values ...string) func(s *jsonschema.Schema) {
+	return func(s *jsonschema.Schema) {
+		for _, value := range values {
+			s.Enum = append(s.Enum, value)
+		}
+	}
+}
+
+func schemaRange(minimum, maximum float64) func(s *jsonschema.Schema) {
+	return func(s *jsonschema.Schema) {
+		s.Minimum, s.Maximum = &minimum, &maximum
+	}
+}
+
+func schemaMinimum(minimum float64) func(s *jsonschema.Schema) {
+	return func(s *jsonschema.Schema) {
+		s.Minimum = &minimum
+	}
+}
+
+// Schema 返回配置文件的 JSON Schema（draft 2020-12），由配置结构的 mapstructure 标签生成，
+// 可用于编辑器补全与校验；加载配置时也按它校验配置文件
+func Schema() *jsonschema.Schema {
+	s := schemaForType(reflect.TypeOf(Config{}), "")
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	s.Title = "TaskBridge 配置"
+	s.Properties["providers"] = &jsonschema.Schema{
+		Type:        "object",
+		Deprecated:  true,
+		Description: "已弃用，改用 adapters",
+	}
+	return s
+}
+
+func schemaForType(t reflect.Type, key string) *jsonschema.Schema {
+	var s *jsonschema.Schema
+	switch {
+	case t == durationType:
+		s = &jsonschema.Schema{Type: "string", Pattern: durationPattern, Description: "时长，如 30s、5m、1h"}
+	case t.Kind() == reflect.Struct:
+		s = &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{}, AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}}}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if strings.Contains(field.Tag.Get("mapstructure"), ",remain") {
+				// 平铺在配置块中的扩展选项，允许任意键
+				s.AdditionalProperties = nil
+				continue
+			}
+			if name := tagName(field); name != "" && field.IsExported() {
+				s.Properties[name] = schemaForType(field.Type, joinKey(key, name))
+			}
+		}
+	case t.Kind() == reflect.Map:
+		s = &jsonschema.Schema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), joinKey(key, "*"))}
+	case t.Kind() == reflect.Slice:
+		s = &jsonschema.Schema{Type: "array", Items: schemaForType(t.Elem(), key+"[]")}
+		if t.Elem().Kind() == reflect.String {
+			// 字符串列表也可以写作逗号分隔的字符串
+			s.Type, s.Types = "", []string{"array", "string"}
+		}
+	case t.Kind() == reflect.Bool:
+		s = &jsonschema.Schema{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s = &jsonschema.Schema{Type: "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = &jsonschema.Schema{Type: "number"}
+	case t.Kind() == reflect.String:
+		s = &jsonschema.Schema{Type: "string"}
+	default:
+		s = &jsonschema.Schema{}
+	}
+	if rule, ok := schemaRules[key]; ok {
+		rule(s)
+	}
+	return s
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// validateSettings 按 schema 校验配置文件内容，返回按配置键排序的问题列表
+func validateSettings(settings map[string]any) []ValidationIssue {
+	issues := make([]ValidationIssue, 0)
+	validateSchemaValue(Schema(), settings, "", &issues)
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Field < issues[j].Field })
+	return issues
+}
+
+// validateSchemaValue 校验 schema 中本包生成的关键字。为与 Validate 一致，枚举值忽略大小写与首尾空白，
+// 空字符串与空值视为未设置（使用默认值）
+func validateSchemaValue(s *jsonschema.Schema, value any, field string, issues *[]ValidationIssue) {
+	if value == nil {
+		return
+	}
+	addIssue := func(field, message string) {
+		*issues = append(*issues, ValidationIssue{Level: ValidationLevelError, Field: field, Message: message})
+	}
+
+	types := s.Types
+	if s.Type != "" {
+		types = []string{s.Type}
+	}
+	if len(types) > 0 && !matchesSchemaType(value, types) {
+		addIssue(field, schemaTypeMessage(s, types, value))
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return
+		}
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(v) {
+			if s.Pattern == durationPattern {
+				addIssue(field, fmt.Sprintf("必须是时长，如 30s、5m（当前为 %q）", v))
+			} else {
+				addIssue(field, fmt.Sprintf("格式无效: %q", v))
+			}
+		}
+		if len(s.Enum) > 0 && !matchesEnum(s.Enum, v) {
+			addIssue(field, fmt.Sprintf("无效值: %s（可选 %s）", v, joinEnum(s.Enum)))
+		}
+	case []any:
+		if s.Items == nil {
+			return
+		}
+		for i, item := range v {
+			validateSchemaValue(s.Items, item, fmt.Sprintf("%s[%d]", field, i), issues)
+		}
+	case map[string]any:
+		validateSchemaObject(s, v, field, issues)
+	default:
+		number, ok := toFloat(value)
+		if !ok {
+			return
+		}
+		switch {
+		case s.Minimum != nil && s.Maximum != nil && (number < *s.Minimum || number > *s.Maximum):
+			addIssue(field, fmt.Sprintf("必须在 [%v,%v] 范围内", *s.Minimum, *s.Maximum))
+		case s.Minimum != nil && number < *s.Minimum:
+			addIssue(field, fmt.Sprintf("必须大于等于 %v", *s.Minimum))
+		case s.Maximum != nil && number > *s.Maximum:
+			addIssue(field, fmt.Sprintf("必须小于等于 %v", *s.Maximum))
+		}
+	}
+}
+
+func validateSchemaObject(s *jsonschema.Schema, value map[string]any, field string, issues *[]ValidationIssue) {
+	missing := func(key, reason string) {
+		message := "缺少必填项"
+		if reason != "" {
+			message += "（" + reason + "）"
+		}
+		*issues = append(*issues, ValidationIssue{Level: ValidationLevelError, Field: joinKey(field, key), Message: message})
+	}
+
+	for _, key := range s.Required {
+		if isUnset(value[key]) {
+			missing(key, "")
+		}
+	}
+	if s.If != nil {
+		branch := s.Else
+		if schemaMatches(s.If, value) {
+			branch = s.Then
+		}
+		if branch != nil {
+			for _, key := range branch.Required {
+				if isUnset(value[key]) {
+					missing(key, branch.Description)
+				}
+			}
+		}
+	}
+	for key, dependencies := range s.DependentRequired {
+		if isUnset(value[key]) {
+			continue
+		}
+		for _, dependency := range dependencies {
+			if isUnset(value[dependency]) {
+				missing(dependency, "设置了 "+key)
+			}
+		}
+	}
+
+	for key, item := range value {
+		if property, ok := s.Properties[key]; ok {
+			validateSchemaValue(property, item, joinKey(field, key), issues)
+			continue
+		}
+		switch {
+		case s.AdditionalProperties == nil:
+		case s.AdditionalProperties.Not != nil:
+			*issues = append(*issues, ValidationIssue{Level: ValidationLevelError, Field: joinKey(field, key), Message: "未知配置项（检查拼写或是否位于正确的配置段）"})
+		default:
+			validateSchemaValue(s.AdditionalProperties, item, joinKey(field, key), issues)
+		}
+	}
+}
+
+// schemaMatches 判断值是否满足 schema（用于 if 条件）
+func schemaMatches(s *jsonschema.Schema, value map[string]any) bool {
+	var issues []ValidationIssue
+	validateSchemaValue(s, value, "", &issues)
+	return len(issues) == 0
+}
+
+func isUnset(value any) bool {
+	text, ok := value.(string)
+	return value == nil || (ok && strings.TrimSpace(text) == "")
+}
+
+func matchesSchemaType(value any, types []string) bool {
+	for _, typ := range types {
+		switch typ {
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "integer":
+			if number, ok := toFloat(value); ok && number == math.Trunc(number) {
+				return true
+			}
+		case "number":
+			if _, ok := toFloat(value); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]any); ok {
+				return true
+			}
+		case "object":
+			if _, ok := value.(map[string]any); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func schemaTypeMessage(s *jsonschema.Schema, types []string, value any) string {
+	if s.Pattern == durationPattern {
+		return fmt.Sprintf("必须是时长，如 30s、5m（当前为 %v）", value)
+	}
+	names := map[string]string{
+		"string":  "字符串",
+		"boolean": "布尔值（true/false）",
+		"integer": "整数",
+		"number":  "数字",
+		"array":   "列表",
+		"object":  "配置段",
+	}
+	expected := make([]string, 0, len(types))
+	for _, typ := range types {
+		expected = append(expected, names[typ])
+	}
+	return fmt.Sprintf("必须是%s（当前为 %v）", strings.Join(expected, "或"), value)
+}
+
+func matchesEnum(enum []any, value string) bool {
+	for _, item := range enum {
+		if text, ok := item.(string); ok && strings.EqualFold(text, strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinEnum(enum []any) string {
+	values := make([]string, 0, len(enum))
+	for _, item := range enum {
+		values = append(values, fmt.Sprint(item))
+	}
+	return strings.Join(values, "、")
+}
+
+func toFloat(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestSchemaAcceptsDefaults(t *testing.T) {
+	if issues := validateSettings(ToMap(DefaultConfig())); len(issues) != 0 {
+		t.Fatalf("expected defaults to match schema, got %+v", issues)
+	}
+
+	data, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatalf("marshal schema: %v", err)
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("unmarshal schema: %v", err)
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		t.Fatalf("resolve schema: %v", err)
+	}
+	var instance map[string]any
+	defaults, _ := json.Marshal(ToMap(DefaultConfig()))
+	_ = json.Unmarshal(defaults, &instance)
+	if err := resolved.Validate(instance); err != nil {
+		t.Fatalf("expected defaults to validate against the published schema: %v", err)
+	}
+}
+
+func TestLoadReportsSchemaErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `sync:
+  interval: 5
+mcp:
+  port: "abc"
+  session:
+    keep_alive: soon
+  upstreams:
+    - name: remote
+      transport: streamable
+adapters:
+  todoist:
+    client_secret: x
+app:
+  log_format: xml
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	_, _, err := LoadFile(path)
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected schema error, got %v", err)
+	}
+	got := map[string]string{}
+	for _, issue := range schemaErr.Issues {
+		got[issue.Field] = issue.Message
+	}
+	for field, want := range map[string]string{
+		"adapters.todoist.client_id": "缺少必填项",
+		"app.log_format":             "无效值",
+		"mcp.port":                   "必须是整数",
+		"mcp.session.keep_alive":     "必须是时长",
+		"mcp.upstreams[0].url":       "streamable",
+		"sync.interval":              "必须是时长",
+	} {
+		if !strings.Contains(got[field], want) {
+			t.Fatalf("expected %s to report %q, got %+v", field, want, schemaErr.Issues)
+		}
+	}
+	if len(schemaErr.Issues) != 6 {
+		t.Fatalf("expected 6 issues, got %+v", schemaErr.Issues)
+	}
+}
@@ -2,7 +2,17 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"os"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/yeisme/taskbridge/pkg/paths"
+	"github.com/yeisme/taskbridge/pkg/secretstore"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
 )
 
 const (
@@ -19,19 +29,97 @@ type ValidationIssue struct {
 	Message string
 }
 
-// NormalizeTransport 将 transport 统一为规范值；tcp 作为兼容别名映射到 sse。
+// SupportedProtocolVersions 可协商的 MCP 规范版本（与 go-sdk 一致），从新到旧排列。
+var SupportedProtocolVersions = []string{"2025-11-25", "2025-06-18", "2025-03-26", "2024-11-05"}
+
+// IsSupportedProtocolVersion 判断是否为可协商的 MCP 规范版本。
+func IsSupportedProtocolVersion(version string) bool {
+	for _, supported := range SupportedProtocolVersions {
+		if supported == strings.TrimSpace(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTransport 将 transport 统一为规范值；tcp 作为兼容别名映射到 sse，http 映射到 streamable。
+// 支持逗号分隔的多个传输（如 stdio,streamable），同一进程同时提供；网络传输最多一个（共用端口）。
 func NormalizeTransport(value string) (canonical string, deprecated bool, err error) {
-	trimmed := strings.ToLower(strings.TrimSpace(value))
-	switch trimmed {
-	case "stdio", "sse", "streamable":
-		return trimmed, false, nil
-	case "tcp":
-		return "sse", true, nil
-	case "":
-		return "", false, fmt.Errorf("transport is empty")
-	default:
-		return "", false, fmt.Errorf("unsupported transport: %s", value)
+	parts := strings.Split(value, ",")
+	seen := make(map[string]bool, len(parts))
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		trimmed := strings.ToLower(strings.TrimSpace(part))
+		switch trimmed {
+		case "stdio", "sse", "streamable":
+		case "http":
+			trimmed = "streamable"
+		case "tcp":
+			trimmed = "sse"
+			deprecated = true
+		case "":
+			if len(parts) == 1 {
+				return "", false, fmt.Errorf("transport is empty")
+			}
+			return "", false, fmt.Errorf("transport list contains an empty entry: %s", value)
+		default:
+			return "", false, fmt.Errorf("unsupported transport: %s", strings.TrimSpace(part))
+		}
+		if !seen[trimmed] {
+			seen[trimmed] = true
+			result = append(result, trimmed)
+		}
+	}
+	if seen["sse"] && seen["streamable"] {
+		return "", false, fmt.Errorf("sse and streamable share the http port; use streamable with mcp.compat.legacy_sse")
+	}
+	return strings.Join(result, ","), deprecated, nil
+}
+
+// HTTPTransport 返回规范化 transport 列表中的网络传输（sse/streamable），没有时返回空字符串
+func HTTPTransport(canonical string) string {
+	for _, transport := range strings.Split(canonical, ",") {
+		if transport == "sse" || transport == "streamable" {
+			return transport
+		}
 	}
+	return ""
+}
+
+// validPriorityValue sync.pairs[].field_map.priority 中的取值：优先级名称或 0-4
+func validPriorityValue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "none", "low", "medium", "high", "urgent", "0", "1", "2", "3", "4":
+		return true
+	}
+	return false
+}
+
+// validStatusValue sync.pairs[].field_map.status 中的取值
+func validStatusValue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "todo", "in_progress", "completed", "cancelled", "deferred":
+		return true
+	}
+	return false
+}
+
+// ParseTrustedProxy 解析 mcp.http.trusted_proxies 中的一项：CIDR 或单个 IP（视为 /32 或 /128）
+func ParseTrustedProxy(value string) (*net.IPNet, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		return network, err
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip: %q", value)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
 }
 
 // Validate 校验配置并返回 error/warning 列表。
@@ -53,11 +141,77 @@ func (c *Config) Validate() []ValidationIssue {
 		})
 	}
 
+	switch strings.ToLower(strings.TrimSpace(c.App.LogFormat)) {
+	case "", "json", "console":
+	default:
+		addIssue(ValidationLevelError, "app.log_format", fmt.Sprintf("无效值: %s（可选 json、console）", c.App.LogFormat))
+	}
+	if strings.EqualFold(strings.TrimSpace(c.App.LogOutput), "stdout") {
+		addIssue(ValidationLevelWarning, "app.log_output", "stdout 会与 stdio 传输的 JSON-RPC 输出混在一起，建议使用 stderr 或日志文件")
+	}
+
+	issues = append(issues, c.loadIssues...)
+	switch strings.ToLower(strings.TrimSpace(c.Secrets.Backend)) {
+	case "encrypted":
+		if os.Getenv(secretstore.PassphraseEnv) == "" && strings.TrimSpace(c.Secrets.PassphraseFile) == "" {
+			addIssue(ValidationLevelError, "secrets.passphrase_file", "encrypted 后端需要口令：设置 TASKBRIDGE_SECRETS_PASSPHRASE 或 secrets.passphrase_file")
+		}
+	case "vault":
+		if strings.TrimSpace(c.Secrets.Vault.Address) == "" && os.Getenv("VAULT_ADDR") == "" {
+			addIssue(ValidationLevelError, "secrets.vault.address", "vault 后端需要地址：设置 secrets.vault.address 或 VAULT_ADDR")
+		}
+		if strings.TrimSpace(c.Secrets.Vault.TokenFile) == "" && os.Getenv("VAULT_TOKEN") == "" {
+			addIssue(ValidationLevelError, "secrets.vault.token_file", "vault 后端需要 token：设置 VAULT_TOKEN 或 secrets.vault.token_file")
+		}
+	default:
+		if !secretstore.IsBackend(c.Secrets.Backend) {
+			addIssue(ValidationLevelError, "secrets.backend", fmt.Sprintf("无效值: %s（可选 %s）", c.Secrets.Backend, strings.Join(secretstore.Backends(), "、")))
+		}
+	}
+	if c.Secrets.RefreshInterval < 0 {
+		addIssue(ValidationLevelError, "secrets.refresh_interval", "不能为负数")
+	}
+
 	if strings.TrimSpace(c.Storage.Type) == "" {
 		addIssue(ValidationLevelError, "storage.type", "不能为空")
 	}
-	if c.Storage.Type == "file" && strings.TrimSpace(c.Storage.Path) == "" {
-		addIssue(ValidationLevelError, "storage.path", "不能为空（文件存储模式）")
+	if (c.Storage.Type == "file" || c.Storage.Type == "sqlite" || c.Storage.Type == "bolt") && strings.TrimSpace(c.Storage.Path) == "" {
+		addIssue(ValidationLevelError, "storage.path", fmt.Sprintf("不能为空（%s 存储模式）", c.Storage.Type))
+	}
+	if c.Storage.Type == "postgres" && strings.TrimSpace(c.Storage.Postgres.DSN) == "" {
+		addIssue(ValidationLevelError, "storage.postgres.dsn", "不能为空（postgres 存储模式）")
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Storage.Encryption.KeySource)) {
+	case "", "passphrase":
+		if c.Storage.Encryption.Enabled && os.Getenv(storecrypt.PassphraseEnv) == "" && strings.TrimSpace(c.Storage.Encryption.PassphraseFile) == "" {
+			addIssue(ValidationLevelError, "storage.encryption.passphrase_file", "启用静态加密需要口令：设置 TASKBRIDGE_STORAGE_PASSPHRASE 或 storage.encryption.passphrase_file")
+		}
+	case "keychain":
+	default:
+		addIssue(ValidationLevelError, "storage.encryption.key_source", fmt.Sprintf("无效值: %s（可选 passphrase、keychain）", c.Storage.Encryption.KeySource))
+	}
+	if c.Storage.History.Retention < 0 {
+		addIssue(ValidationLevelError, "storage.history.retention", "不能为负数")
+	}
+	if c.Storage.Embeddings.Enabled {
+		switch strings.ToLower(strings.TrimSpace(c.Storage.Embeddings.Provider)) {
+		case "", "ollama", "hash":
+		case "openai":
+			if strings.TrimSpace(c.Storage.Embeddings.APIKey) == "" && os.Getenv("OPENAI_API_KEY") == "" && strings.TrimSpace(c.Storage.Embeddings.BaseURL) == "" {
+				addIssue(ValidationLevelError, "storage.embeddings.api_key", "openai 向量化需要 API key：设置 storage.embeddings.api_key 或 OPENAI_API_KEY")
+			}
+		default:
+			addIssue(ValidationLevelError, "storage.embeddings.provider", fmt.Sprintf("无效值: %s（可选 ollama、openai、hash）", c.Storage.Embeddings.Provider))
+		}
+	}
+	if c.Storage.Embeddings.Dimensions < 0 {
+		addIssue(ValidationLevelError, "storage.embeddings.dimensions", "不能为负数")
+	}
+	if c.Storage.Embeddings.BatchSize < 0 {
+		addIssue(ValidationLevelError, "storage.embeddings.batch_size", "不能为负数")
+	}
+	if c.Storage.Encryption.Enabled && c.Storage.Type != "" && c.Storage.Type != "file" && c.Storage.Type != "sqlite" && c.Storage.Type != "bolt" && c.Storage.Type != "postgres" {
+		addIssue(ValidationLevelWarning, "storage.encryption.enabled", fmt.Sprintf("storage.type 为 %s 时任务保存在数据库中，不由静态加密保护", c.Storage.Type))
 	}
 
 	if strings.TrimSpace(c.Sync.Mode) == "" {
@@ -70,6 +224,59 @@ func (c *Config) Validate() []ValidationIssue {
 		}
 	}
 
+	switch strings.ToLower(strings.TrimSpace(c.Sync.StateStore)) {
+	case "", "sqlite", "file":
+	default:
+		addIssue(ValidationLevelError, "sync.state_store", fmt.Sprintf("无效值: %s（可选 sqlite、file）", c.Sync.StateStore))
+	}
+	for i, pair := range c.Sync.Pairs {
+		field := fmt.Sprintf("sync.pairs[%d]", i)
+		left, right := strings.TrimSpace(pair.Left), strings.TrimSpace(pair.Right)
+		if left == "" || right == "" {
+			addIssue(ValidationLevelError, field, "left 与 right 都不能为空")
+			continue
+		}
+		if left == right {
+			addIssue(ValidationLevelError, field, "left 与 right 不能是同一个 Provider")
+		}
+		switch conflict := strings.ToLower(strings.TrimSpace(pair.Conflict)); conflict {
+		case "", "newer", "last_write_wins", "last-write-wins", "left", "right", "merge", "manual", left, right:
+		default:
+			addIssue(ValidationLevelError, field+".conflict", fmt.Sprintf("无效值: %s（可选 newer、left、right、%s、%s、merge、manual）", pair.Conflict, left, right))
+		}
+		if source := strings.TrimSpace(pair.Source); source != "" && source != left && source != right {
+			addIssue(ValidationLevelError, field+".source", fmt.Sprintf("必须是 %s 或 %s", left, right))
+		}
+		switch strings.ToLower(strings.TrimSpace(pair.MirrorEdits)) {
+		case "", "overwrite", "flag":
+		default:
+			addIssue(ValidationLevelError, field+".mirror_edits", fmt.Sprintf("无效值: %s（可选 overwrite、flag）", pair.MirrorEdits))
+		}
+		switch strings.ToLower(strings.TrimSpace(pair.OnDelete)) {
+		case "", "delete", "archive":
+		default:
+			addIssue(ValidationLevelError, field+".on_delete", fmt.Sprintf("无效值: %s（可选 delete、archive）", pair.OnDelete))
+		}
+		if schedule := strings.TrimSpace(pair.Schedule); schedule != "" {
+			if _, err := cron.ParseStandard(schedule); err != nil {
+				addIssue(ValidationLevelError, field+".schedule", fmt.Sprintf("无效的 cron 表达式: %v", err))
+			}
+		}
+		if pair.Filter.ExcludeCompletedOlderThanDays < 0 {
+			addIssue(ValidationLevelError, field+".filter.exclude_completed_older_than_days", "不能为负数")
+		}
+		for j, value := range pair.FieldMap.Priority {
+			if !validPriorityValue(value.Left) || !validPriorityValue(value.Right) {
+				addIssue(ValidationLevelError, fmt.Sprintf("%s.field_map.priority[%d]", field, j), "可选 none、low、medium、high、urgent 或 0-4")
+			}
+		}
+		for j, value := range pair.FieldMap.Status {
+			if !validStatusValue(value.Left) || !validStatusValue(value.Right) {
+				addIssue(ValidationLevelError, fmt.Sprintf("%s.field_map.status[%d]", field, j), "可选 todo、in_progress、completed、cancelled、deferred")
+			}
+		}
+	}
+
 	normalizedTransport := ""
 	if c.MCP.Enabled {
 		if strings.TrimSpace(c.MCP.Transport) == "" {
@@ -86,23 +293,48 @@ func (c *Config) Validate() []ValidationIssue {
 			}
 		}
 
-		if normalizedTransport == "sse" || normalizedTransport == "streamable" {
+		if HTTPTransport(normalizedTransport) != "" {
 			if c.MCP.Port < 1 || c.MCP.Port > 65535 {
 				addIssue(ValidationLevelError, "mcp.port", "仅在 sse/streamable 模式下允许 1-65535")
 			}
+			if host := strings.TrimSpace(c.MCP.Host); host != "" {
+				if _, _, err := net.SplitHostPort(host); err == nil {
+					addIssue(ValidationLevelError, "mcp.host", "只填写地址，端口使用 mcp.port")
+				} else if strings.ContainsAny(host, " /") {
+					addIssue(ValidationLevelError, "mcp.host", fmt.Sprintf("无效值: %s", c.MCP.Host))
+				}
+			}
 		}
 
-		if normalizedTransport != "" && normalizedTransport != "stdio" && !c.MCP.Security.Enabled {
+		if HTTPTransport(normalizedTransport) != "" && !c.MCP.Security.Enabled {
 			addIssue(ValidationLevelWarning, "mcp.security.enabled", "网络传输模式未启用 security，存在暴露风险")
 		}
 	}
 
 	switch strings.ToLower(strings.TrimSpace(c.MCP.Security.AuthMode)) {
-	case "none", "token", "mutual_tls":
+	case "none", "token", "oauth", "mutual_tls":
 	default:
 		addIssue(ValidationLevelError, "mcp.security.auth_mode", fmt.Sprintf("无效值: %s", c.MCP.Security.AuthMode))
 	}
 
+	if c.MCP.Security.Enabled && strings.EqualFold(strings.TrimSpace(c.MCP.Security.AuthMode), "token") &&
+		len(c.MCP.Security.Tokens) == 0 && strings.TrimSpace(c.MCP.Security.TokenFile) == "" {
+		addIssue(ValidationLevelError, "mcp.security.tokens", "auth_mode=token 时需配置 tokens 或 token_file")
+	}
+
+	if c.MCP.Security.Enabled && strings.EqualFold(strings.TrimSpace(c.MCP.Security.AuthMode), "oauth") {
+		oauth := c.MCP.Security.OAuth
+		if strings.TrimSpace(oauth.Issuer) == "" {
+			addIssue(ValidationLevelError, "mcp.security.oauth.issuer", "auth_mode=oauth 时需配置 issuer")
+		}
+		if strings.TrimSpace(oauth.Resource) == "" {
+			addIssue(ValidationLevelError, "mcp.security.oauth.resource", "auth_mode=oauth 时需配置 resource（MCP 端点 URL）")
+		}
+		if HTTPTransport(normalizedTransport) != "streamable" {
+			addIssue(ValidationLevelError, "mcp.security.auth_mode", "auth_mode=oauth 仅支持 streamable 传输")
+		}
+	}
+
 	switch strings.ToLower(strings.TrimSpace(c.MCP.Observability.Audit.Output)) {
 	case "stdout", "file":
 	default:
@@ -114,6 +346,12 @@ func (c *Config) Validate() []ValidationIssue {
 	default:
 		addIssue(ValidationLevelError, "mcp.cache.backend", fmt.Sprintf("无效值: %s", c.MCP.Cache.Backend))
 	}
+	if c.MCP.Cache.Enabled && c.MCP.Cache.DefaultTTL <= 0 {
+		addIssue(ValidationLevelError, "mcp.cache.default_ttl", "启用缓存时必须大于 0")
+	}
+	if c.MCP.Cache.MaxEntries < 0 {
+		addIssue(ValidationLevelError, "mcp.cache.max_entries", "不能为负数")
+	}
 
 	if c.MCP.Observability.Trace.SampleRate < 0 || c.MCP.Observability.Trace.SampleRate > 1 {
 		addIssue(ValidationLevelError, "mcp.observability.trace.sample_rate", "必须在 [0,1] 范围内")
@@ -129,8 +367,138 @@ func (c *Config) Validate() []ValidationIssue {
 		addIssue(ValidationLevelError, "mcp.reliability.circuit_breaker.failure_threshold", "必须大于等于 1")
 	}
 
+	if c.MCP.Credentials.CheckInterval < 0 {
+		addIssue(ValidationLevelError, "mcp.credentials.check_interval", "不能为负数")
+	}
+	if c.MCP.Credentials.RefreshBefore < 0 {
+		addIssue(ValidationLevelError, "mcp.credentials.refresh_before", "不能为负数")
+	}
+	if c.MCP.Offline.Enabled && c.MCP.Offline.ReplayInterval <= 0 {
+		addIssue(ValidationLevelError, "mcp.offline.replay_interval", "启用离线队列时必须大于 0")
+	}
+	if c.MCP.TaskCache.MaxAge < 0 {
+		addIssue(ValidationLevelError, "mcp.task_cache.max_age", "不能为负数")
+	}
+	if c.MCP.TaskCache.RefreshInterval < 0 {
+		addIssue(ValidationLevelError, "mcp.task_cache.refresh_interval", "不能为负数")
+	}
+
+	if c.MCP.Session.KeepAlive < 0 {
+		addIssue(ValidationLevelError, "mcp.session.keep_alive", "不能为负数")
+	}
+	if c.MCP.Session.IdleTimeout < 0 {
+		addIssue(ValidationLevelError, "mcp.session.idle_timeout", "不能为负数")
+	} else if c.MCP.Session.KeepAlive > 0 && c.MCP.Session.IdleTimeout > 0 && c.MCP.Session.IdleTimeout <= c.MCP.Session.KeepAlive {
+		addIssue(ValidationLevelWarning, "mcp.session.idle_timeout", "不大于 mcp.session.keep_alive 时，空闲会话会在保活 ping 之前被关闭")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.MCP.Session.EventStore)) {
+	case "", "none", "memory", "sqlite":
+	default:
+		addIssue(ValidationLevelError, "mcp.session.event_store", fmt.Sprintf("无效值: %s（支持 memory、sqlite、none）", c.MCP.Session.EventStore))
+	}
+	if c.MCP.Session.EventStoreMaxBytes < 0 {
+		addIssue(ValidationLevelError, "mcp.session.event_store_max_bytes", "不能为负数")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.MCP.Compat.StructuredContent)) {
+	case "", "auto", "always", "never":
+	default:
+		addIssue(ValidationLevelError, "mcp.compat.structured_content", fmt.Sprintf("无效值: %s", c.MCP.Compat.StructuredContent))
+	}
+	for _, version := range c.MCP.Compat.ProtocolVersions {
+		if !IsSupportedProtocolVersion(version) {
+			addIssue(ValidationLevelError, "mcp.compat.protocol_versions", fmt.Sprintf("不支持的协议版本: %s", version))
+		}
+	}
+	if c.MCP.Compat.LegacySSE && normalizedTransport != "" && HTTPTransport(normalizedTransport) != "streamable" {
+		addIssue(ValidationLevelWarning, "mcp.compat.legacy_sse", "仅在 streamable 模式下生效")
+	}
+
+	for _, origin := range c.MCP.CORS.AllowedOrigins {
+		if strings.TrimSpace(origin) == "*" && c.MCP.CORS.AllowCredentials {
+			addIssue(ValidationLevelError, "mcp.cors.allow_credentials", "allowed_origins 包含 * 时不能启用 allow_credentials")
+		}
+	}
+	if c.MCP.CORS.MaxAge < 0 {
+		addIssue(ValidationLevelError, "mcp.cors.max_age", "不能为负数")
+	}
+
+	if c.MCP.RateLimit.Enabled {
+		if c.MCP.RateLimit.RequestsPerSecond <= 0 {
+			addIssue(ValidationLevelError, "mcp.rate_limit.requests_per_second", "启用限流时必须大于 0")
+		}
+		if c.MCP.RateLimit.Burst < 1 {
+			addIssue(ValidationLevelError, "mcp.rate_limit.burst", "启用限流时必须至少为 1")
+		}
+	}
+
+	if c.MCP.HTTP.MaxRequestBytes < 0 {
+		addIssue(ValidationLevelError, "mcp.http.max_request_bytes", "不能为负数")
+	}
+	for _, timeout := range []struct {
+		field string
+		value time.Duration
+	}{
+		{"mcp.http.read_header_timeout", c.MCP.HTTP.ReadHeaderTimeout},
+		{"mcp.http.read_timeout", c.MCP.HTTP.ReadTimeout},
+		{"mcp.http.write_timeout", c.MCP.HTTP.WriteTimeout},
+		{"mcp.http.idle_timeout", c.MCP.HTTP.IdleTimeout},
+		{"mcp.http.request_timeout", c.MCP.HTTP.RequestTimeout},
+		{"mcp.http.sse_heartbeat", c.MCP.HTTP.SSEHeartbeat},
+		{"mcp.http.sse_retry", c.MCP.HTTP.SSERetry},
+	} {
+		if timeout.value < 0 {
+			addIssue(ValidationLevelError, timeout.field, "不能为负数")
+		}
+	}
+	if c.MCP.HTTP.WriteTimeout > 0 {
+		addIssue(ValidationLevelWarning, "mcp.http.write_timeout", "SSE 长连接会在 write_timeout 后被断开，客户端需重连")
+	}
+	if basePath := strings.TrimSpace(c.MCP.HTTP.BasePath); basePath != "" && !strings.HasPrefix(basePath, "/") {
+		addIssue(ValidationLevelError, "mcp.http.base_path", "必须以 / 开头")
+	}
+	for _, proxy := range c.MCP.HTTP.TrustedProxies {
+		if _, err := ParseTrustedProxy(proxy); err != nil {
+			addIssue(ValidationLevelError, "mcp.http.trusted_proxies", fmt.Sprintf("无效的 IP/CIDR: %s", proxy))
+		}
+	}
+
+	if c.MCP.Webhooks.Enabled {
+		for name, secret := range c.MCP.Webhooks.Secrets {
+			if strings.TrimSpace(secret) == "" {
+				addIssue(ValidationLevelError, "mcp.webhooks.secrets."+name, "不能为空")
+			}
+		}
+	}
+
 	if c.MCP.Tenant.Enabled && strings.TrimSpace(c.MCP.Tenant.DefaultTenant) == "" {
 		addIssue(ValidationLevelError, "mcp.tenant.default_tenant", "tenant 启用时不能为空")
+	} else if c.MCP.Tenant.Enabled && paths.ValidateProfileName(strings.TrimSpace(c.MCP.Tenant.DefaultTenant)) != nil {
+		addIssue(ValidationLevelError, "mcp.tenant.default_tenant", "必须是有效的 profile 名称（字母、数字、- 与 _）")
+	}
+	tenantKeys := make([]string, 0, len(c.MCP.Tenant.Principals))
+	for key := range c.MCP.Tenant.Principals {
+		tenantKeys = append(tenantKeys, key)
+	}
+	sort.Strings(tenantKeys)
+	principalOwner := make(map[string]string)
+	for _, key := range tenantKeys {
+		field := "mcp.tenant.principals." + key
+		if paths.ValidateProfileName(key) != nil {
+			addIssue(ValidationLevelError, field, "租户 key 必须是有效的 profile 名称（字母、数字、- 与 _）")
+		}
+		principal := c.MCP.Tenant.Principals[key]
+		for _, id := range append(prefixed("api_key:", principal.APIKeys), prefixed("subject:", principal.Subjects)...) {
+			if owner, ok := principalOwner[id]; ok && owner != key {
+				addIssue(ValidationLevelError, field, fmt.Sprintf("同一身份不能同时映射到租户 %s 与 %s", owner, key))
+				continue
+			}
+			principalOwner[id] = key
+		}
+	}
+	if c.MCP.Tenant.Enabled && strings.EqualFold(strings.TrimSpace(c.Storage.Type), "postgres") {
+		addIssue(ValidationLevelWarning, "mcp.tenant.enabled", "postgres 存储无法按租户拆分，默认租户以外的请求会被拒绝")
 	}
 
 	allowMap := make(map[string]struct{}, len(c.MCP.Tools.AllowList))
@@ -153,3 +521,14 @@ func (c *Config) Validate() []ValidationIssue {
 
 	return issues
 }
+
+// prefixed 为每个非空值加上前缀，用于区分不同类型的身份
+func prefixed(prefix string, values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			out = append(out, prefix+value)
+		}
+	}
+	return out
+}
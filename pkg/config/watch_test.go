@@ -0,0 +1,57 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFileDetectsWriteAndReplace(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("app:\n  log_level: info\n"), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 4)
+	if err := WatchFile(ctx, configPath, 20*time.Millisecond, func() { changed <- struct{}{} }); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	expectChange := func(step string) {
+		t.Helper()
+		select {
+		case <-changed:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected change notification after %s", step)
+		}
+	}
+
+	if err := os.WriteFile(configPath, []byte("app:\n  log_level: debug\n"), 0600); err != nil {
+		t.Fatalf("rewrite config: %v", err)
+	}
+	expectChange("write")
+
+	// 编辑器式保存：写临时文件后重命名覆盖
+	tmpPath := filepath.Join(dir, "config.yaml.tmp")
+	if err := os.WriteFile(tmpPath, []byte("app:\n  log_level: warn\n"), 0600); err != nil {
+		t.Fatalf("write temp config: %v", err)
+	}
+	if err := os.Rename(tmpPath, configPath); err != nil {
+		t.Fatalf("rename config: %v", err)
+	}
+	expectChange("rename")
+
+	// 同目录其他文件的变化不触发
+	if err := os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("x: 1\n"), 0600); err != nil {
+		t.Fatalf("write other file: %v", err)
+	}
+	select {
+	case <-changed:
+		t.Fatalf("unexpected notification for unrelated file")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
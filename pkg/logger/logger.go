@@ -10,6 +10,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// baseOutput Init 配置的输出，AddOutput 在其基础上追加
+var baseOutput io.Writer = os.Stderr
+
 // Config 日志配置
 type Config struct {
 	// Level 日志级别: debug, info, warn, error
@@ -79,6 +82,8 @@ func Init(cfg *Config) error {
 		}
 	}
 
+	baseOutput = output
+
 	// 创建 logger
 	logger := zerolog.New(output).With().Timestamp()
 
@@ -91,6 +96,15 @@ func Init(cfg *Config) error {
 	return nil
 }
 
+// AddOutput 在 Init 配置的输出之外追加一个输出，保留全局 logger 的上下文字段。
+// 实现 zerolog.LevelWriter 的输出可直接获得日志级别。
+func AddOutput(w io.Writer) {
+	if w == nil {
+		return
+	}
+	log.Logger = log.Logger.Output(zerolog.MultiLevelWriter(baseOutput, w))
+}
+
 // parseLevel 解析日志级别
 func parseLevel(level string) (zerolog.Level, error) {
 	switch strings.ToLower(level) {
@@ -2,8 +2,11 @@
 package paths
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -14,8 +17,104 @@ const (
 	CredentialsDir = "credentials"
 	// TokenFileName 统一 token 存储文件名
 	TokenFileName = "tokens.json"
+	// ProfilesDir profile 目录名称
+	ProfilesDir = "profiles"
+	// DefaultProfile 默认 profile 名称，使用根目录而非 profiles 子目录
+	DefaultProfile = "default"
+	// ProfileEnv 选择 profile 的环境变量
+	ProfileEnv = "TASKBRIDGE_PROFILE"
 )
 
+// profileName SetProfile 设置的 profile，优先于 TASKBRIDGE_PROFILE
+var profileName string
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidateProfileName 检查 profile 名称，只允许字母、数字、- 与 _
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, - and _", name)
+	}
+	return nil
+}
+
+// SetProfile 设置当前 profile（如 --profile 参数），空字符串表示使用 TASKBRIDGE_PROFILE
+func SetProfile(name string) error {
+	name = strings.TrimSpace(name)
+	if name != "" {
+		if err := ValidateProfileName(name); err != nil {
+			return err
+		}
+	}
+	profileName = name
+	return nil
+}
+
+// GetProfile 返回当前 profile 名称：SetProfile > TASKBRIDGE_PROFILE；默认 profile 返回空字符串
+func GetProfile() string {
+	name := profileName
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv(ProfileEnv))
+	}
+	if name == DefaultProfile || ValidateProfileName(name) != nil {
+		return ""
+	}
+	return name
+}
+
+// GetBaseDir 获取所有 profile 共享的根目录（TASKBRIDGE_HOME 或 ~/.taskbridge）
+func GetBaseDir() string {
+	if custom := strings.TrimSpace(os.Getenv("TASKBRIDGE_HOME")); custom != "" {
+		return custom
+	}
+	return filepath.Join(GetHomeDir(), "."+AppName)
+}
+
+// GetProfileDir 获取指定 profile 的目录 (~/.taskbridge/profiles/<name>)，默认 profile 为根目录
+func GetProfileDir(name string) string {
+	if name == "" || name == DefaultProfile {
+		return GetBaseDir()
+	}
+	return filepath.Join(GetBaseDir(), ProfilesDir, name)
+}
+
+// GetProfileCredentialsDir 获取指定 profile 的凭证目录，不受当前 profile 影响
+func GetProfileCredentialsDir(name string) string {
+	return filepath.Join(GetProfileDir(name), CredentialsDir)
+}
+
+// ProfileOfPath 返回 path 所在的非默认 profile 名称；不在 profiles 目录下时返回空字符串
+func ProfileOfPath(path string) string {
+	rel, err := filepath.Rel(filepath.Join(GetBaseDir(), ProfilesDir), filepath.Clean(path))
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	name, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	if ValidateProfileName(name) != nil {
+		return ""
+	}
+	return name
+}
+
+// ListProfiles 列出已创建的 profile（profiles 下的子目录），按名称排序，不含默认 profile
+func ListProfiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(GetBaseDir(), ProfilesDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && ValidateProfileName(entry.Name()) == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 // GetHomeDir 获取用户 HOME 目录
 func GetHomeDir() string {
 	home, err := os.UserHomeDir()
@@ -26,17 +125,15 @@ func GetHomeDir() string {
 	return home
 }
 
-// GetAppDir 获取应用配置目录 (~/.taskbridge)
+// GetAppDir 获取当前 profile 的应用目录 (~/.taskbridge，或 ~/.taskbridge/profiles/<name>)；
+// 凭证、数据、缓存与日志目录都在其下，因此各 profile 相互隔离
 func GetAppDir() string {
-	if custom := strings.TrimSpace(os.Getenv("TASKBRIDGE_HOME")); custom != "" {
-		return custom
-	}
-	return filepath.Join(GetHomeDir(), "."+AppName)
+	return GetProfileDir(GetProfile())
 }
 
 // GetCredentialsDir 获取凭证目录 (~/.taskbridge/credentials)
 func GetCredentialsDir() string {
-	return filepath.Join(GetAppDir(), CredentialsDir)
+	return GetProfileCredentialsDir(GetProfile())
 }
 
 // GetTokenPath 获取 token 存储文件路径（统一单文件）
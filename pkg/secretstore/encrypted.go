@@ -0,0 +1,252 @@
+package secretstore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// BackendEncrypted 以口令加密的 secrets 文件，适用于没有系统钥匙串的无头服务器
+	BackendEncrypted = "encrypted"
+
+	// EncryptedFileName encrypted 后端的默认文件名
+	EncryptedFileName = "secrets.enc"
+
+	// PassphraseEnv 提供 encrypted 后端口令的环境变量
+	PassphraseEnv = "TASKBRIDGE_SECRETS_PASSPHRASE"
+
+	encryptedVersion = 1
+	kdfIterations    = 600000
+	kdfSaltSize      = 16
+	kdfKeySize       = 32
+)
+
+// ErrWrongPassphrase 口令错误或文件被篡改
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted secrets file")
+
+// encryptedFile encrypted 后端的文件格式：PBKDF2-SHA256 派生 AES-256-GCM 密钥，加密整个 JSON 条目表
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Encrypt 使用口令加密 plaintext，返回 encrypted 后端的文件内容
+func Encrypt(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+	salt := make([]byte, kdfSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(passphrase, salt, kdfIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(encryptedFile{
+		Version:    encryptedVersion,
+		KDF:        "pbkdf2-sha256",
+		Iterations: kdfIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+}
+
+// Decrypt 使用口令解密 Encrypt 生成的文件内容
+func Decrypt(data []byte, passphrase string) ([]byte, error) {
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("not an encrypted secrets file: %w", err)
+	}
+	if file.Version != encryptedVersion || file.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported encrypted secrets file (version %d, kdf %s)", file.Version, file.KDF)
+	}
+	gcm, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, kdfKeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptedStore 以口令加密的 secrets 文件。解密后缓存全部条目，文件被其他进程修改后重新读取；
+// 每次写入都以新的 salt 与 nonce 重新加密整个文件并原子替换，token 刷新时旧密文随之轮换
+type EncryptedStore struct {
+	path       string
+	passphrase string
+	entries    map[string]string
+	loaded     fileState
+	mu         sync.Mutex
+}
+
+// NewEncryptedStore 创建保存在 path 的加密文件后端
+func NewEncryptedStore(path, passphrase string) *EncryptedStore {
+	return &EncryptedStore{path: path, passphrase: passphrase}
+}
+
+// Name 返回后端名称
+func (s *EncryptedStore) Name() string {
+	return BackendEncrypted
+}
+
+// Get 读取条目
+func (s *EncryptedStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return "", err
+	}
+	value, ok := s.entries[key]
+	if !ok {
+		return "", notFound(key)
+	}
+	return value, nil
+}
+
+// Set 写入条目
+func (s *EncryptedStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return err
+	}
+	s.entries[key] = value
+	return s.writeLocked()
+}
+
+// Delete 删除条目，条目不存在时不报错
+func (s *EncryptedStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return err
+	}
+	if _, ok := s.entries[key]; !ok {
+		return nil
+	}
+	delete(s.entries, key)
+	return s.writeLocked()
+}
+
+// Keys 返回全部条目名称，按名称排序
+func (s *EncryptedStore) Keys() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadLocked(); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(s.entries))
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *EncryptedStore) loadLocked() error {
+	current := statFile(s.path)
+	if s.entries != nil && current == s.loaded {
+		return nil
+	}
+	if s.passphrase == "" {
+		return fmt.Errorf("encrypted secrets backend needs a passphrase (set %s or secrets.passphrase_file)", PassphraseEnv)
+	}
+	entries := map[string]string{}
+	data, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read secrets file %s: %w", s.path, err)
+	}
+	if err == nil {
+		plaintext, err := Decrypt(data, s.passphrase)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.path, err)
+		}
+		if err := json.Unmarshal(plaintext, &entries); err != nil {
+			return fmt.Errorf("failed to parse secrets file %s: %w", s.path, err)
+		}
+	}
+	s.entries = entries
+	s.loaded = current
+	return nil
+}
+
+func (s *EncryptedStore) writeLocked() error {
+	plaintext, err := json.Marshal(s.entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+	data, err := Encrypt(plaintext, s.passphrase)
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(s.path, data, 0600); err != nil {
+		return err
+	}
+	s.loaded = statFile(s.path)
+	return nil
+}
+
+// fileState 文件的修改时间与大小，用于发现其他进程（如刷新 token 的 MCP 服务）写入的新内容
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
+
+// ReadPassphraseFile 读取口令文件，去除首尾空白
+func ReadPassphraseFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase file: %w", err)
+	}
+	return string(bytes.TrimSpace(data)), nil
+}
@@ -0,0 +1,66 @@
+package secretstore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// securityNotFound security 命令在条目不存在时的退出码
+const securityNotFound = 44
+
+// macKeychain 通过 /usr/bin/security 访问登录钥匙串中的通用密码条目
+type macKeychain struct {
+	service string
+}
+
+func newKeychain(service string) (Store, error) {
+	if _, err := exec.LookPath("security"); err != nil {
+		return nil, fmt.Errorf("macOS keychain unavailable: %w", err)
+	}
+	return &macKeychain{service: service}, nil
+}
+
+// Name 返回后端名称
+func (k *macKeychain) Name() string {
+	return "keychain"
+}
+
+// Get 读取条目
+func (k *macKeychain) Get(key string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", k.service, "-a", key, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == securityNotFound {
+			return "", notFound(key)
+		}
+		return "", fmt.Errorf("keychain lookup %s: %w", key, err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// Set 写入条目。通过交互模式从 stdin 传入十六进制编码的值，避免明文出现在进程参数中
+func (k *macKeychain) Set(key, value string) error {
+	command := fmt.Sprintf("add-generic-password -U -s %q -a %q -X %s\n", k.service, key, hex.EncodeToString([]byte(value)))
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(command)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("keychain store %s: %w: %s", key, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Delete 删除条目，条目不存在时不报错
+func (k *macKeychain) Delete(key string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", k.service, "-a", key).Run()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == securityNotFound) {
+		return fmt.Errorf("keychain delete %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package secretstore
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// libsecretKeychain 通过 secret-tool（libsecret-tools）访问 Secret Service（GNOME Keyring、KWallet 等）
+type libsecretKeychain struct {
+	service string
+}
+
+func newKeychain(service string) (Store, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil, fmt.Errorf("libsecret unavailable (install libsecret-tools): %w", err)
+	}
+	return &libsecretKeychain{service: service}, nil
+}
+
+// Name 返回后端名称
+func (k *libsecretKeychain) Name() string {
+	return "libsecret"
+}
+
+// Get 读取条目；secret-tool 在条目不存在时无输出并以非零状态退出
+func (k *libsecretKeychain) Get(key string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "lookup", "service", k.service, "account", key)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if stdout.Len() == 0 && strings.TrimSpace(stderr.String()) == "" {
+			return "", notFound(key)
+		}
+		return "", fmt.Errorf("libsecret lookup %s: %w: %s", key, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// Set 写入条目，值经 stdin 传入
+func (k *libsecretKeychain) Set(key, value string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "store", "--label", k.service+" "+key, "service", k.service, "account", key)
+	cmd.Stdin = strings.NewReader(value)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("libsecret store %s: %w: %s", key, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Delete 删除条目，条目不存在时不报错
+func (k *libsecretKeychain) Delete(key string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("secret-tool", "clear", "service", k.service, "account", key)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil && strings.TrimSpace(stderr.String()) != "" {
+		return fmt.Errorf("libsecret clear %s: %w: %s", key, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build !darwin && !linux && !windows
+
+package secretstore
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func newKeychain(service string) (Store, error) {
+	return nil, fmt.Errorf("keychain secret backend is not supported on %s, use the file backend", runtime.GOOS)
+}
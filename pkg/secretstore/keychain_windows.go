@@ -0,0 +1,47 @@
+package secretstore
+
+import (
+	"fmt"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/yeisme/taskbridge/pkg/paths"
+)
+
+// newKeychain 在 Windows 上使用 DPAPI 按当前用户加密，密文保存在凭证目录下的 secrets.<service>.dpapi.json
+func newKeychain(service string) (Store, error) {
+	return &FileStore{
+		name: "dpapi",
+		path: filepath.Join(paths.GetCredentialsDir(), "secrets."+service+".dpapi.json"),
+		seal: dpapiProtect,
+		open: dpapiUnprotect,
+	}, nil
+}
+
+func dpapiProtect(data []byte) ([]byte, error) {
+	return dpapiCall(data, func(in, out *windows.DataBlob) error {
+		return windows.CryptProtectData(in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, out)
+	})
+}
+
+func dpapiUnprotect(data []byte) ([]byte, error) {
+	return dpapiCall(data, func(in, out *windows.DataBlob) error {
+		return windows.CryptUnprotectData(in, nil, nil, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, out)
+	})
+}
+
+// dpapiCall 调用 DPAPI 并复制输出后释放系统分配的内存
+func dpapiCall(data []byte, call func(in, out *windows.DataBlob) error) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty secret")
+	}
+	in := windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	var out windows.DataBlob
+	if err := call(&in, &out); err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
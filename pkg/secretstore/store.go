@@ -0,0 +1,303 @@
+// Package secretstore 提供 provider token、client secret 等敏感信息的存储后端。
+//
+// 默认的 file 后端将敏感信息保存在 ~/.taskbridge/credentials/secrets.json（权限 0600）；
+// keychain 后端使用操作系统的凭证存储：macOS Keychain、Linux libsecret（Secret Service）、
+// Windows DPAPI（按当前用户加密后保存在凭证目录）；encrypted 后端是以口令加密的文件，
+// 用于没有系统钥匙串的无头服务器；vault 后端从 HashiCorp Vault 读取，敏感信息不落盘。
+// 其他外部存储实现 Store 接口后可通过 Register 注册为新的后端。
+// 配置文件中的值可写作 "secret:<name>"，加载配置时从当前后端读取同名条目。
+package secretstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yeisme/taskbridge/pkg/paths"
+)
+
+const (
+	// BackendFile 保存在凭证目录下的 JSON 文件
+	BackendFile = "file"
+	// BackendKeychain 操作系统凭证存储
+	BackendKeychain = "keychain"
+
+	// DefaultService 系统凭证存储中条目的服务名
+	DefaultService = "taskbridge"
+
+	// RefPrefix 配置值中引用 secret 的前缀，如 "secret:google-client-secret"
+	RefPrefix = "secret:"
+
+	// SecretsFileName file 后端的文件名
+	SecretsFileName = "secrets.json"
+)
+
+// Store 敏感信息存储后端。Get 在条目不存在时返回包装 os.ErrNotExist 的错误。
+type Store interface {
+	// Name 返回后端名称，用于日志与诊断输出
+	Name() string
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// Options 存储后端选项
+type Options struct {
+	// Backend 后端名称: file（默认）, keychain, encrypted, vault 或通过 Register 注册的名称
+	Backend string
+	// Service 系统凭证存储中条目的服务名，为空时使用 DefaultService
+	Service string
+	// File encrypted 后端的文件路径，为空时使用凭证目录下的 secrets.enc
+	File string
+	// Passphrase encrypted 后端的口令
+	Passphrase string
+	// Vault vault 后端的选项
+	Vault VaultOptions
+}
+
+// Factory 按选项创建存储后端
+type Factory func(opts Options) (Store, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{}
+)
+
+// Register 注册外部存储后端，之后 secrets.backend 即可使用该名称；不能覆盖内置后端
+func Register(backend string, factory Factory) error {
+	backend = strings.ToLower(strings.TrimSpace(backend))
+	if backend == "" || factory == nil {
+		return fmt.Errorf("secret backend name and factory are required")
+	}
+	for _, builtin := range builtinBackends {
+		if backend == builtin {
+			return fmt.Errorf("secret backend %s is built in", backend)
+		}
+	}
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[backend] = factory
+	return nil
+}
+
+var builtinBackends = []string{BackendFile, BackendKeychain, BackendEncrypted, BackendVault}
+
+// Backends 返回内置与已注册的后端名称
+func Backends() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	backends := append([]string{}, builtinBackends...)
+	registered := make([]string, 0, len(factories))
+	for backend := range factories {
+		registered = append(registered, backend)
+	}
+	sort.Strings(registered)
+	return append(backends, registered...)
+}
+
+// IsBackend 判断是否为内置或已注册的后端（空值视为 file）
+func IsBackend(backend string) bool {
+	backend = strings.ToLower(strings.TrimSpace(backend))
+	if backend == "" {
+		return true
+	}
+	for _, known := range Backends() {
+		if backend == known {
+			return true
+		}
+	}
+	return false
+}
+
+// New 按选项创建存储后端
+func New(opts Options) (Store, error) {
+	service := opts.Service
+	if strings.TrimSpace(service) == "" {
+		service = DefaultService
+	}
+	switch strings.ToLower(strings.TrimSpace(opts.Backend)) {
+	case "", BackendFile:
+		return NewFileStore(filepath.Join(paths.GetCredentialsDir(), SecretsFileName)), nil
+	case BackendKeychain:
+		return newKeychain(service)
+	case BackendEncrypted:
+		path := opts.File
+		if strings.TrimSpace(path) == "" {
+			path = filepath.Join(paths.GetCredentialsDir(), EncryptedFileName)
+		}
+		return NewEncryptedStore(path, opts.Passphrase), nil
+	case BackendVault:
+		return NewVaultStore(opts.Vault)
+	default:
+		factoriesMu.RLock()
+		factory, ok := factories[strings.ToLower(strings.TrimSpace(opts.Backend))]
+		factoriesMu.RUnlock()
+		if ok {
+			return factory(opts)
+		}
+		return nil, fmt.Errorf("unknown secret backend: %s (supported: %s)", opts.Backend, strings.Join(Backends(), ", "))
+	}
+}
+
+// ParseRef 解析 "secret:<name>" 形式的引用，返回条目名称
+func ParseRef(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, RefPrefix) {
+		return "", false
+	}
+	name := strings.TrimSpace(strings.TrimPrefix(value, RefPrefix))
+	return name, name != ""
+}
+
+// notFound 返回条目不存在的错误
+func notFound(key string) error {
+	return fmt.Errorf("%w: secret %s", os.ErrNotExist, key)
+}
+
+// FileStore 以 JSON 文件保存敏感信息；seal/open 非空时对每个值加解密（Windows 使用 DPAPI）
+type FileStore struct {
+	name string
+	path string
+	seal func([]byte) ([]byte, error)
+	open func([]byte) ([]byte, error)
+	mu   sync.Mutex
+}
+
+// NewFileStore 创建保存在 path 的明文文件后端
+func NewFileStore(path string) *FileStore {
+	return &FileStore{name: BackendFile, path: path}
+}
+
+// Name 返回后端名称
+func (s *FileStore) Name() string {
+	return s.name
+}
+
+// Get 读取条目
+func (s *FileStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.readLocked()
+	if err != nil {
+		return "", err
+	}
+	raw, ok := entries[key]
+	if !ok {
+		return "", notFound(key)
+	}
+	if s.open == nil {
+		return string(raw), nil
+	}
+	value, err := s.open(raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s: %w", key, err)
+	}
+	return string(value), nil
+}
+
+// Set 写入条目
+func (s *FileStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.readLocked()
+	if err != nil {
+		return err
+	}
+	raw := []byte(value)
+	if s.seal != nil {
+		if raw, err = s.seal(raw); err != nil {
+			return fmt.Errorf("failed to encrypt secret %s: %w", key, err)
+		}
+	}
+	entries[key] = raw
+	return s.writeLocked(entries)
+}
+
+// Delete 删除条目，条目不存在时不报错
+func (s *FileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.readLocked()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[key]; !ok {
+		return nil
+	}
+	delete(entries, key)
+	return s.writeLocked(entries)
+}
+
+// Keys 返回全部条目名称，按名称排序
+func (s *FileStore) Keys() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.readLocked()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// readLocked 读取文件；加密后端的值为 base64（[]byte 的 JSON 编码），明文后端为字符串
+func (s *FileStore) readLocked() (map[string][]byte, error) {
+	entries := map[string][]byte{}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file %s: %w", s.path, err)
+	}
+	if s.seal != nil {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse secrets file %s: %w", s.path, err)
+		}
+		return entries, nil
+	}
+	plain := map[string]string{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file %s: %w", s.path, err)
+	}
+	for key, value := range plain {
+		entries[key] = []byte(value)
+	}
+	return entries, nil
+}
+
+func (s *FileStore) writeLocked(entries map[string][]byte) error {
+	if len(entries) == 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove secrets file %s: %w", s.path, err)
+		}
+		return nil
+	}
+	var data []byte
+	var err error
+	if s.seal != nil {
+		data, err = json.MarshalIndent(entries, "", "  ")
+	} else {
+		plain := make(map[string]string, len(entries))
+		for key, value := range entries {
+			plain[key] = string(value)
+		}
+		data, err = json.MarshalIndent(plain, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+	return WriteFileAtomic(s.path, data, 0600)
+}
@@ -0,0 +1,130 @@
+package secretstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials", SecretsFileName)
+	store := NewFileStore(path)
+
+	if _, err := store.Get("missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist for missing secret, got %v", err)
+	}
+	if err := store.Set("google-client-secret", "s3cret"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	value, err := store.Get("google-client-secret")
+	if err != nil || value != "s3cret" {
+		t.Fatalf("unexpected value %q err=%v", value, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat secrets file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+
+	if err := store.Delete("google-client-secret"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected empty secrets file removed, err=%v", err)
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	cases := map[string]string{
+		"secret:google-client-secret": "google-client-secret",
+		" secret: todoist ":           "todoist",
+		"plain-value":                 "",
+		"secret:":                     "",
+	}
+	for input, want := range cases {
+		got, ok := ParseRef(input)
+		if got != want || ok != (want != "") {
+			t.Fatalf("ParseRef(%q) = %q, %v; want %q", input, got, ok, want)
+		}
+	}
+}
+
+func TestNewRejectsUnknownBackend(t *testing.T) {
+	if _, err := New(Options{Backend: "vault"}); err == nil {
+		t.Fatal("expected unknown backend to be rejected")
+	}
+}
+
+func TestEncryptedStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), EncryptedFileName)
+	store := NewEncryptedStore(path, "correct horse")
+	if err := store.Set("token/todoist", "abc123"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read encrypted file: %v", err)
+	}
+	if strings.Contains(string(data), "abc123") {
+		t.Fatal("secret value must not appear in plaintext")
+	}
+
+	reopened := NewEncryptedStore(path, "correct horse")
+	if value, err := reopened.Get("token/todoist"); err != nil || value != "abc123" {
+		t.Fatalf("unexpected value %q err=%v", value, err)
+	}
+	if _, err := NewEncryptedStore(path, "wrong").Get("token/todoist"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+	if _, err := NewEncryptedStore(path, "").Get("token/todoist"); err == nil {
+		t.Fatal("expected missing passphrase to be rejected")
+	}
+}
+
+func TestEncryptedStoreRotationVisibleToOtherProcess(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, EncryptedFileName)
+	cli := NewEncryptedStore(path, "correct horse")
+	server := NewEncryptedStore(path, "correct horse")
+	if err := cli.Set("token/google", `{"refresh_token":"r1"}`); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, err := server.Get("token/google"); err != nil || value != `{"refresh_token":"r1"}` {
+		t.Fatalf("unexpected value %q err=%v", value, err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read encrypted file: %v", err)
+	}
+
+	// 另一个进程刷新 token 后，已缓存的 store 应读到新值，且文件以新的 salt/nonce 重新加密
+	if err := cli.Set("token/google", `{"refresh_token":"r2-rotated"}`); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, err := server.Get("token/google"); err != nil || value != `{"refresh_token":"r2-rotated"}` {
+		t.Fatalf("expected rotated token, got %q err=%v", value, err)
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read encrypted file: %v", err)
+	}
+	if string(before) == string(after) {
+		t.Fatal("expected ciphertext to change on rewrite")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected no temp files left behind, got %d entries", len(entries))
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Fatalf("expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+}
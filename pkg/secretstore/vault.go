@@ -0,0 +1,246 @@
+package secretstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// BackendVault HashiCorp Vault KV v2 引擎，适用于禁止在磁盘上保存 token 的团队部署
+	BackendVault = "vault"
+
+	// DefaultVaultMount 默认的 KV v2 挂载路径
+	DefaultVaultMount = "secret"
+	// DefaultVaultPath 默认的条目路径前缀
+	DefaultVaultPath = "taskbridge"
+	// DefaultVaultField 条目中保存值的默认字段
+	DefaultVaultField = "value"
+
+	vaultTimeout = 10 * time.Second
+)
+
+// VaultOptions vault 后端选项；为空的字段依次使用 VAULT_ADDR、VAULT_TOKEN、VAULT_NAMESPACE 环境变量
+type VaultOptions struct {
+	// Address Vault 地址，如 https://vault.example.com:8200
+	Address string
+	// Token 访问 token
+	Token string
+	// TokenFile token 文件（如 Vault Agent 的 sink），每次请求时重新读取以跟随 token 轮换
+	TokenFile string
+	// Namespace Vault Enterprise 命名空间
+	Namespace string
+	// Mount KV v2 引擎的挂载路径，默认 secret
+	Mount string
+	// Path 条目路径前缀，默认 taskbridge
+	Path string
+}
+
+// VaultStore 通过 HTTP API 读写 Vault KV v2 中的敏感信息。
+// 条目 <key> 保存在 <mount>/data/<path>/<key> 的 value 字段；key 写作 <name>#<field> 时读写该条目的指定字段，
+// 便于直接引用团队已有的 Vault 条目（如 secret:todoist#api_token）。
+// 每次访问都会请求 Vault，不在本地缓存任何值。
+type VaultStore struct {
+	address   string
+	token     string
+	tokenFile string
+	namespace string
+	mount     string
+	path      string
+	client    *http.Client
+}
+
+// NewVaultStore 创建 vault 后端
+func NewVaultStore(opts VaultOptions) (*VaultStore, error) {
+	store := &VaultStore{
+		address:   firstNonEmpty(opts.Address, os.Getenv("VAULT_ADDR")),
+		token:     firstNonEmpty(opts.Token, os.Getenv("VAULT_TOKEN")),
+		tokenFile: strings.TrimSpace(opts.TokenFile),
+		namespace: firstNonEmpty(opts.Namespace, os.Getenv("VAULT_NAMESPACE")),
+		mount:     strings.Trim(firstNonEmpty(opts.Mount, DefaultVaultMount), "/"),
+		path:      strings.Trim(firstNonEmpty(opts.Path, DefaultVaultPath), "/"),
+		client:    &http.Client{Timeout: vaultTimeout},
+	}
+	store.address = strings.TrimRight(store.address, "/")
+	if store.address == "" {
+		return nil, fmt.Errorf("vault secret backend needs an address (set secrets.vault.address or VAULT_ADDR)")
+	}
+	if store.token == "" && store.tokenFile == "" {
+		return nil, fmt.Errorf("vault secret backend needs a token (set VAULT_TOKEN or secrets.vault.token_file)")
+	}
+	return store, nil
+}
+
+// Name 返回后端名称
+func (s *VaultStore) Name() string {
+	return BackendVault
+}
+
+// Get 读取条目
+func (s *VaultStore) Get(key string) (string, error) {
+	name, field := splitVaultKey(key)
+	data, err := s.read(name)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", notFound(key)
+	}
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s: field %s is not a string", name, field)
+	}
+	return text, nil
+}
+
+// Set 写入条目；同一条目的其他字段保持不变
+func (s *VaultStore) Set(key, value string) error {
+	name, field := splitVaultKey(key)
+	data, err := s.read(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	data[field] = value
+	return s.write(name, data)
+}
+
+// Delete 删除条目，条目不存在时不报错。删除字段后条目为空时删除整个条目（包括全部版本）
+func (s *VaultStore) Delete(key string) error {
+	name, field := splitVaultKey(key)
+	data, err := s.read(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, ok := data[field]; !ok {
+		return nil
+	}
+	delete(data, field)
+	if len(data) > 0 {
+		return s.write(name, data)
+	}
+	_, err = s.do(http.MethodDelete, "metadata", name, nil)
+	return err
+}
+
+func (s *VaultStore) read(name string) (map[string]any, error) {
+	body, err := s.do(http.MethodGet, "data", name, nil)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("vault secret %s: invalid response: %w", name, err)
+	}
+	if response.Data.Data == nil {
+		// 最新版本已被删除
+		return nil, notFound(name)
+	}
+	return response.Data.Data, nil
+}
+
+func (s *VaultStore) write(name string, data map[string]any) error {
+	payload, err := json.Marshal(map[string]any{"data": data})
+	if err != nil {
+		return err
+	}
+	_, err = s.do(http.MethodPost, "data", name, payload)
+	return err
+}
+
+// do 发送 KV v2 请求；kind 为 data 或 metadata。404 返回包装 os.ErrNotExist 的错误
+func (s *VaultStore) do(method, kind, name string, payload []byte) ([]byte, error) {
+	token, err := s.currentToken()
+	if err != nil {
+		return nil, err
+	}
+	endpoint := s.address + "/v1/" + s.mount + "/" + kind + "/" + escapeVaultPath(s.path+"/"+name)
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("X-Vault-Request", "true")
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("vault response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, notFound(name)
+	case resp.StatusCode >= 300:
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(body, &failure)
+		return nil, fmt.Errorf("vault %s %s: %s %s", method, name, resp.Status, strings.Join(failure.Errors, "; "))
+	}
+	return body, nil
+}
+
+// currentToken 返回访问 token；配置了 token 文件时每次重新读取
+func (s *VaultStore) currentToken() (string, error) {
+	if s.tokenFile == "" {
+		return s.token, nil
+	}
+	data, err := os.ReadFile(s.tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault token file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// splitVaultKey 将 <name>#<field> 拆分为条目名称与字段，未指定字段时使用 value
+func splitVaultKey(key string) (string, string) {
+	name, field, ok := strings.Cut(key, "#")
+	if !ok || field == "" {
+		return key, DefaultVaultField
+	}
+	return name, field
+}
+
+func escapeVaultPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package secretstore
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeVault 模拟 Vault KV v2 的 data/metadata 接口
+type fakeVault struct {
+	mu      sync.Mutex
+	token   string
+	entries map[string]map[string]any
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("X-Vault-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		name := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := f.entries[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"errors":[]}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": data}})
+		case http.MethodPost:
+			var body struct {
+				Data map[string]any `json:"data"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			f.entries[name] = body.Data
+			_, _ = w.Write([]byte(`{"data":{"version":1}}`))
+		}
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/") && r.Method == http.MethodDelete:
+		delete(f.entries, strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestVaultStoreRoundTrip(t *testing.T) {
+	vault := &fakeVault{token: "root", entries: map[string]map[string]any{
+		"taskbridge/todoist": {"api_token": "t0k3n", "note": "shared"},
+	}}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "vault-token")
+	if err := os.WriteFile(tokenFile, []byte("stale\n"), 0600); err != nil {
+		t.Fatalf("write token: %v", err)
+	}
+	store, err := New(Options{Backend: BackendVault, Vault: VaultOptions{Address: server.URL, TokenFile: tokenFile}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := store.Get("todoist#api_token"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("expected permission error with stale token, got %v", err)
+	}
+
+	// token 文件每次请求时重新读取，Vault Agent 轮换 token 后无需重启
+	if err := os.WriteFile(tokenFile, []byte("root\n"), 0600); err != nil {
+		t.Fatalf("rotate token: %v", err)
+	}
+	if value, err := store.Get("todoist#api_token"); err != nil || value != "t0k3n" {
+		t.Fatalf("unexpected field value %q err=%v", value, err)
+	}
+	if _, err := store.Get("missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected ErrNotExist for missing secret, got %v", err)
+	}
+
+	if err := store.Set("token/google", `{"access_token":"a"}`); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if vault.entries["taskbridge/token/google"]["value"] != `{"access_token":"a"}` {
+		t.Fatalf("expected value stored under taskbridge/token/google, got %v", vault.entries)
+	}
+	if err := store.Set("todoist#api_token", "rotated"); err != nil {
+		t.Fatalf("Set field: %v", err)
+	}
+	if vault.entries["taskbridge/todoist"]["note"] != "shared" {
+		t.Fatalf("expected other fields kept, got %v", vault.entries["taskbridge/todoist"])
+	}
+
+	if err := store.Delete("token/google"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := vault.entries["taskbridge/token/google"]; ok {
+		t.Fatalf("expected empty entry removed")
+	}
+	if err := store.Delete("token/google"); err != nil {
+		t.Fatalf("Delete missing: %v", err)
+	}
+}
+
+func TestRegisterBackend(t *testing.T) {
+	if err := Register(BackendVault, func(Options) (Store, error) { return nil, nil }); err == nil {
+		t.Fatalf("expected built-in backend to be protected")
+	}
+	memory := NewFileStore(filepath.Join(t.TempDir(), SecretsFileName))
+	if err := Register("test-memory", func(Options) (Store, error) { return memory, nil }); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	store, err := New(Options{Backend: "Test-Memory"})
+	if err != nil || store != memory {
+		t.Fatalf("expected registered backend, got %v err=%v", store, err)
+	}
+	if !IsBackend("test-memory") || IsBackend("nope") {
+		t.Fatalf("unexpected IsBackend results")
+	}
+}
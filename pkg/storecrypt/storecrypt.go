@@ -0,0 +1,300 @@
+// Package storecrypt 为本地缓存与状态文件提供静态加密：任务缓存、镜像状态、变更日志、离线写入队列与 token 文件
+// 在写入时用 AES-256-GCM 加密，读取时自动解密；未加密的旧文件仍可读取，下次写入时改为加密保存。
+//
+// 数据密钥由口令经 PBKDF2-SHA256 派生（盐与校验值保存在存储目录的 encryption.json），
+// 或是保存在系统钥匙串中的随机密钥。通过 Use 设置后对整个进程生效，未设置时读写保持明文。
+package storecrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// PassphraseEnv 提供存储加密口令的环境变量
+	PassphraseEnv = "TASKBRIDGE_STORAGE_PASSPHRASE"
+	// KeyFileName 存储目录中保存密钥派生参数与校验值的文件
+	KeyFileName = "encryption.json"
+	// KeySize 数据密钥长度（AES-256）
+	KeySize = 32
+
+	kdfPassphrase = "pbkdf2-sha256"
+	kdfRaw        = "raw"
+	kdfIterations = 600000
+	kdfSaltSize   = 16
+	keyFileVer    = 1
+	checkText     = "taskbridge-storage"
+)
+
+// fileMagic 加密文件的前缀，之后是 nonce 与密文
+var fileMagic = []byte("TBENC\x01")
+
+// stringPrefix 加密字符串（数据库列、日志行）的前缀，之后是 base64 编码的 nonce 与密文
+const stringPrefix = "tbenc:v1:"
+
+var (
+	// ErrLocked 数据已加密但未设置密钥（未启用 storage.encryption 或缺少口令）
+	ErrLocked = errors.New("data is encrypted: enable storage.encryption and provide the key")
+	// ErrWrongKey 口令或密钥与存储目录不匹配，或数据被篡改
+	ErrWrongKey = errors.New("wrong storage encryption key or corrupted data")
+)
+
+// Key 解锁后的数据密钥
+type Key struct {
+	aead cipher.AEAD
+}
+
+// keyFile encryption.json 的内容；Check 为用数据密钥加密的固定文本，用于在写入任何数据前发现口令错误
+type keyFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations,omitempty"`
+	Salt       []byte `json:"salt,omitempty"`
+	Check      string `json:"check"`
+}
+
+var current atomic.Pointer[Key]
+
+// Use 设置进程使用的数据密钥；传入 nil 关闭加密，之后写入的文件为明文
+func Use(key *Key) {
+	current.Store(key)
+}
+
+// Enabled 是否已设置数据密钥
+func Enabled() bool {
+	return current.Load() != nil
+}
+
+// UnlockPassphrase 用口令解锁 dir 中的密钥文件；文件不存在时生成新的盐并创建
+func UnlockPassphrase(dir, passphrase string) (*Key, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("storage encryption passphrase is empty")
+	}
+	return unlock(dir, kdfPassphrase, func(kf *keyFile) ([]byte, error) {
+		if kf.Salt == nil {
+			kf.Iterations = kdfIterations
+			kf.Salt = make([]byte, kdfSaltSize)
+			if _, err := rand.Read(kf.Salt); err != nil {
+				return nil, err
+			}
+		}
+		return pbkdf2.Key(sha256.New, passphrase, kf.Salt, kf.Iterations, KeySize)
+	})
+}
+
+// UnlockRaw 用随机密钥（如保存在系统钥匙串中的密钥）解锁 dir 中的密钥文件；文件不存在时创建
+func UnlockRaw(dir string, raw []byte) (*Key, error) {
+	if len(raw) != KeySize {
+		return nil, fmt.Errorf("storage encryption key must be %d bytes", KeySize)
+	}
+	return unlock(dir, kdfRaw, func(*keyFile) ([]byte, error) {
+		return raw, nil
+	})
+}
+
+// GenerateKey 生成随机数据密钥，供保存到系统钥匙串
+func GenerateKey() ([]byte, error) {
+	raw := make([]byte, KeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func unlock(dir, kdf string, derive func(kf *keyFile) ([]byte, error)) (*Key, error) {
+	path := filepath.Join(dir, KeyFileName)
+	var kf keyFile
+	data, err := os.ReadFile(path)
+	exists := err == nil
+	switch {
+	case exists:
+		if err := json.Unmarshal(data, &kf); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if kf.Version != keyFileVer {
+			return nil, fmt.Errorf("unsupported storage encryption version %d in %s", kf.Version, path)
+		}
+		if kf.KDF != kdf {
+			return nil, fmt.Errorf("%s was created with key source %s, not %s", path, kf.KDF, kdf)
+		}
+	case os.IsNotExist(err):
+		kf = keyFile{Version: keyFileVer, KDF: kdf}
+	default:
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	raw, err := derive(&kf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive storage encryption key: %w", err)
+	}
+	key, err := newKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if check, err := key.OpenString(kf.Check); err != nil || check != checkText {
+			return nil, ErrWrongKey
+		}
+		return key, nil
+	}
+
+	if kf.Check, err = key.SealString(checkText); err != nil {
+		return nil, err
+	}
+	data, err = json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func newKey(raw []byte) (*Key, error) {
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{aead: aead}, nil
+}
+
+// Seal 加密 plaintext，返回带前缀的文件内容
+func (k *Key) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(fileMagic)+len(nonce)+len(plaintext)+k.aead.Overhead())
+	out = append(out, fileMagic...)
+	out = append(out, nonce...)
+	return k.aead.Seal(out, nonce, plaintext, nil), nil
+}
+
+// Open 解密 Seal 生成的内容
+func (k *Key) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return nil, ErrWrongKey
+	}
+	return k.open(data[len(fileMagic):])
+}
+
+// open 解密去掉前缀的 nonce 与密文
+func (k *Key) open(body []byte) ([]byte, error) {
+	if len(body) < k.aead.NonceSize() {
+		return nil, ErrWrongKey
+	}
+	nonce, ciphertext := body[:k.aead.NonceSize()], body[k.aead.NonceSize():]
+	plaintext, err := k.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plaintext, nil
+}
+
+// SealString 加密字符串，结果为可保存在文本列或单行日志中的 ASCII 文本
+func (k *Key) SealString(plaintext string) (string, error) {
+	sealed, err := k.Seal([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return stringPrefix + base64.RawStdEncoding.EncodeToString(sealed[len(fileMagic):]), nil
+}
+
+// OpenString 解密 SealString 生成的文本
+func (k *Key) OpenString(value string) (string, error) {
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, stringPrefix))
+	if err != nil {
+		return "", ErrWrongKey
+	}
+	plaintext, err := k.open(raw)
+	return string(plaintext), err
+}
+
+// IsSealed 判断文件内容是否已加密
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, fileMagic)
+}
+
+// Seal 启用加密时加密 data，否则原样返回
+func Seal(data []byte) ([]byte, error) {
+	key := current.Load()
+	if key == nil {
+		return data, nil
+	}
+	return key.Seal(data)
+}
+
+// Open 解密已加密的内容，未加密的内容原样返回
+func Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	key := current.Load()
+	if key == nil {
+		return nil, ErrLocked
+	}
+	return key.Open(data)
+}
+
+// SealString 启用加密时加密字符串，否则原样返回
+func SealString(value string) (string, error) {
+	key := current.Load()
+	if key == nil {
+		return value, nil
+	}
+	return key.SealString(value)
+}
+
+// OpenString 解密 SealString 生成的文本，未加密的文本原样返回
+func OpenString(value string) (string, error) {
+	if !strings.HasPrefix(value, stringPrefix) {
+		return value, nil
+	}
+	key := current.Load()
+	if key == nil {
+		return "", ErrLocked
+	}
+	return key.OpenString(value)
+}
+
+// ReadFile 读取文件并在需要时解密
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plaintext, nil
+}
+
+// WriteFile 启用加密时加密后写入文件，否则与 os.WriteFile 相同
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	sealed, err := Seal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+	return os.WriteFile(path, sealed, perm)
+}
@@ -0,0 +1,91 @@
+package storecrypt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnlockPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	key, err := UnlockPassphrase(dir, "correct horse")
+	if err != nil {
+		t.Fatalf("UnlockPassphrase: %v", err)
+	}
+	sealed, err := key.Seal([]byte(`{"title":"Q3 budget"}`))
+	if err != nil || bytes.Contains(sealed, []byte("budget")) {
+		t.Fatalf("Seal should hide the content: %q, %v", sealed, err)
+	}
+
+	// 同一口令重新解锁得到相同的密钥
+	again, err := UnlockPassphrase(dir, "correct horse")
+	if err != nil {
+		t.Fatalf("unlock again: %v", err)
+	}
+	if plain, err := again.Open(sealed); err != nil || string(plain) != `{"title":"Q3 budget"}` {
+		t.Fatalf("Open: %q, %v", plain, err)
+	}
+
+	if _, err := UnlockPassphrase(dir, "wrong"); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("expected ErrWrongKey for a wrong passphrase, got %v", err)
+	}
+	raw, _ := GenerateKey()
+	if _, err := UnlockRaw(dir, raw); err == nil {
+		t.Fatal("a passphrase key file should not be unlocked with a raw key")
+	}
+}
+
+func TestReadWriteFile(t *testing.T) {
+	t.Cleanup(func() { Use(nil) })
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tasks.json")
+
+	// 未启用时读写明文
+	Use(nil)
+	if err := WriteFile(path, []byte("[]"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "[]" {
+		t.Fatalf("expected plaintext, got %q", data)
+	}
+
+	raw, _ := GenerateKey()
+	key, err := UnlockRaw(dir, raw)
+	if err != nil {
+		t.Fatalf("UnlockRaw: %v", err)
+	}
+	Use(key)
+	// 旧的明文文件仍可读取
+	if data, err := ReadFile(path); err != nil || string(data) != "[]" {
+		t.Fatalf("plaintext should stay readable: %q, %v", data, err)
+	}
+	if err := WriteFile(path, []byte(`[{"id":"t1"}]`), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !IsSealed(data) {
+		t.Fatalf("file should be encrypted, got %q", data)
+	}
+	if data, err := ReadFile(path); err != nil || string(data) != `[{"id":"t1"}]` {
+		t.Fatalf("ReadFile: %q, %v", data, err)
+	}
+
+	line, err := SealString(`{"op":"create"}`)
+	if err != nil || strings.Contains(line, "create") || strings.ContainsAny(line, "\n") {
+		t.Fatalf("SealString should produce a single opaque line: %q, %v", line, err)
+	}
+	if plain, err := OpenString(line); err != nil || plain != `{"op":"create"}` {
+		t.Fatalf("OpenString: %q, %v", plain, err)
+	}
+
+	// 未设置密钥时读取已加密的数据报告 ErrLocked
+	Use(nil)
+	if _, err := ReadFile(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+	if _, err := OpenString(line); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+}
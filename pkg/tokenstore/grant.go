@@ -0,0 +1,125 @@
+package tokenstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 记录授权信息的 token 字段；scope 与 OAuth2 token 响应一致，为空格分隔的字符串
+const (
+	grantScopeKey   = "scope"
+	grantAccountKey = "account"
+	grantExpiryKey  = "expiry"
+)
+
+// Grant 已保存 token 的授权信息：授予的 scope、所属账号与过期时间
+type Grant struct {
+	Scopes  []string
+	Account string
+	Expiry  time.Time
+}
+
+// LoadGrant 读取指定 provider 已保存 token 中的授权信息，token 中没有的字段保持零值。
+func LoadGrant(path, provider string) (Grant, error) {
+	raw, err := LoadRaw(path, provider)
+	if err != nil {
+		return Grant{}, err
+	}
+	fields, ok := decodeObject(raw)
+	if !ok {
+		return Grant{}, nil
+	}
+
+	var grant Grant
+	var scope, expiry string
+	_ = json.Unmarshal(fields[grantScopeKey], &scope)
+	_ = json.Unmarshal(fields[grantAccountKey], &grant.Account)
+	_ = json.Unmarshal(fields[grantExpiryKey], &expiry)
+	grant.Scopes = strings.Fields(scope)
+	if t, err := time.Parse(time.RFC3339Nano, expiry); err == nil {
+		grant.Expiry = t
+	}
+	return grant, nil
+}
+
+// SaveGrant 将授权信息写入已保存的 token，零值字段不覆盖原有内容；token 自带过期时间时通常无需设置 Expiry。
+// 之后刷新保存的 token 不含 scope 与账号时沿用已记录的值。
+func SaveGrant(path, provider string, grant Grant) error {
+	tokenPath, providerName, err := normalizeInput(path, provider)
+	if err != nil {
+		return err
+	}
+
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
+	previous := previousRawLocked(tokenPath, providerName)
+	if previous == nil {
+		return fmt.Errorf("no saved token for provider %s", providerName)
+	}
+	fields, ok := decodeObject(previous)
+	if !ok {
+		return fmt.Errorf("saved %s token is not a JSON object", providerName)
+	}
+	if len(grant.Scopes) > 0 {
+		fields[grantScopeKey], _ = json.Marshal(strings.Join(grant.Scopes, " "))
+	}
+	if grant.Account != "" {
+		fields[grantAccountKey], _ = json.Marshal(grant.Account)
+	}
+	if !grant.Expiry.IsZero() {
+		fields[grantExpiryKey], _ = json.Marshal(grant.Expiry)
+	}
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s token: %w", providerName, err)
+	}
+	return saveRawLocked(tokenPath, providerName, payload)
+}
+
+// carryGrant 新 token 缺少 scope 或账号时从先前保存的 token 中沿用，
+// 刷新响应通常不带这些字段，oauth2.Token 序列化时也会丢弃 scope
+func carryGrant(previous, payload []byte) []byte {
+	old, ok := decodeObject(previous)
+	if !ok {
+		return payload
+	}
+	fields, ok := decodeObject(payload)
+	if !ok {
+		return payload
+	}
+	changed := false
+	for _, key := range []string{grantScopeKey, grantAccountKey} {
+		if isEmptyJSON(fields[key]) && !isEmptyJSON(old[key]) {
+			fields[key] = old[key]
+			changed = true
+		}
+	}
+	if !changed {
+		return payload
+	}
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return payload
+	}
+	return merged
+}
+
+func decodeObject(raw []byte) (map[string]json.RawMessage, bool) {
+	if len(raw) == 0 {
+		return nil, false
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return nil, false
+	}
+	return fields, true
+}
+
+func isEmptyJSON(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) || bytes.Equal(trimmed, []byte(`""`))
+}
@@ -0,0 +1,45 @@
+package tokenstore
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestGrantSurvivesTokenRefresh(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "credentials", "tokens.json")
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if err := Save(tokenPath, "google", &oauth2.Token{AccessToken: "a1", RefreshToken: "r1", Expiry: expiry}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := SaveGrant(tokenPath, "google", Grant{Scopes: []string{"email", "https://www.googleapis.com/auth/tasks"}, Account: "me@example.com"}); err != nil {
+		t.Fatalf("SaveGrant failed: %v", err)
+	}
+
+	// 刷新后的 oauth2.Token 序列化时不含 scope 与账号，应沿用登录时记录的值
+	refreshed := expiry.Add(time.Hour)
+	if err := Save(tokenPath, "google", &oauth2.Token{AccessToken: "a2", RefreshToken: "r1", Expiry: refreshed}); err != nil {
+		t.Fatalf("Save refreshed failed: %v", err)
+	}
+	grant, err := LoadGrant(tokenPath, "google")
+	if err != nil {
+		t.Fatalf("LoadGrant failed: %v", err)
+	}
+	want := Grant{Scopes: []string{"email", "https://www.googleapis.com/auth/tasks"}, Account: "me@example.com", Expiry: refreshed}
+	if !reflect.DeepEqual(grant, want) {
+		t.Fatalf("unexpected grant %+v, want %+v", grant, want)
+	}
+
+	var token oauth2.Token
+	if err := Load(tokenPath, "google", &token); err != nil || token.AccessToken != "a2" {
+		t.Fatalf("expected refreshed token loadable, got %q err=%v", token.AccessToken, err)
+	}
+
+	if err := SaveGrant(tokenPath, "todoist", Grant{Account: "x"}); err == nil {
+		t.Fatal("expected error for provider without token")
+	}
+}
@@ -11,10 +11,15 @@ import (
 	"sync"
 
 	"github.com/yeisme/taskbridge/pkg/paths"
+	"github.com/yeisme/taskbridge/pkg/secretstore"
+	"github.com/yeisme/taskbridge/pkg/storecrypt"
 )
 
 const currentVersion = 1
 
+// secretKeyPrefix token 在 secret store 中的条目名前缀，如 token/google
+const secretKeyPrefix = "token/"
+
 type fileStore struct {
 	Version   int                        `json:"version"`
 	Providers map[string]json.RawMessage `json:"providers"`
@@ -22,6 +27,17 @@ type fileStore struct {
 
 var fileMu sync.Mutex
 
+// secrets 非空时 token 保存到该 secret store（如系统钥匙串），token 文件只作为迁移前的读取来源
+var secrets secretstore.Store
+
+// UseSecretStore 将 token 改为保存到 store；传入 nil 恢复为 token 文件。
+// 切换后首次读取仍可从 token 文件取得旧 token，下次保存时写入 store 并从文件中移除。
+func UseSecretStore(store secretstore.Store) {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	secrets = store
+}
+
 // Load 从统一 token 文件加载指定 provider 的 token 到 out。
 func Load(path, provider string, out interface{}) error {
 	raw, err := LoadRaw(path, provider)
@@ -44,6 +60,16 @@ func LoadRaw(path, provider string) ([]byte, error) {
 	fileMu.Lock()
 	defer fileMu.Unlock()
 
+	if secrets != nil {
+		value, err := secrets.Get(secretKey(tokenPath, providerName))
+		if err == nil {
+			return []byte(value), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s token from %s: %w", providerName, secrets.Name(), err)
+		}
+	}
+
 	raw, err := loadRawLocked(tokenPath, providerName)
 	if err != nil {
 		return nil, err
@@ -75,6 +101,21 @@ func SaveRaw(path, provider string, raw []byte) error {
 	fileMu.Lock()
 	defer fileMu.Unlock()
 
+	payload = carryGrant(previousRawLocked(tokenPath, providerName), payload)
+	return saveRawLocked(tokenPath, providerName, payload)
+}
+
+func saveRawLocked(tokenPath, providerName string, payload []byte) error {
+	if secrets != nil {
+		if err := secrets.Set(secretKey(tokenPath, providerName), string(payload)); err != nil {
+			return fmt.Errorf("failed to save %s token to %s: %w", providerName, secrets.Name(), err)
+		}
+		if err := deleteFromStoreLocked(tokenPath, providerName); err != nil {
+			return err
+		}
+		return removeLegacyFileLocked(tokenPath, providerName)
+	}
+
 	store, err := loadStoreForWriteLocked(tokenPath, providerName)
 	if err != nil {
 		return err
@@ -97,6 +138,11 @@ func Delete(path, provider string) error {
 	fileMu.Lock()
 	defer fileMu.Unlock()
 
+	if secrets != nil {
+		if err := secrets.Delete(secretKey(tokenPath, providerName)); err != nil {
+			return fmt.Errorf("failed to delete %s token from %s: %w", providerName, secrets.Name(), err)
+		}
+	}
 	if err := deleteFromStoreLocked(tokenPath, providerName); err != nil {
 		return err
 	}
@@ -115,8 +161,22 @@ func Has(path, provider string) (bool, error) {
 	return false, err
 }
 
+// previousRawLocked 返回当前保存的 token，不存在或无法读取时返回 nil
+func previousRawLocked(path, provider string) []byte {
+	if secrets != nil {
+		if value, err := secrets.Get(secretKey(path, provider)); err == nil {
+			return []byte(value)
+		}
+	}
+	raw, err := loadRawLocked(path, provider)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
 func loadRawLocked(path, provider string) ([]byte, error) {
-	data, err := os.ReadFile(path)
+	data, err := storecrypt.ReadFile(path)
 	if err == nil {
 		store, isStore, parseErr := decodeStore(data)
 		if parseErr != nil {
@@ -133,9 +193,9 @@ func loadRawLocked(path, provider string) ([]byte, error) {
 		return nil, fmt.Errorf("failed to read token file %s: %w", path, err)
 	}
 
-	legacyPath := paths.GetLegacyTokenPath(provider)
+	legacyPath := legacyTokenPath(path, provider)
 	if !samePath(path, legacyPath) {
-		legacyData, legacyErr := os.ReadFile(legacyPath)
+		legacyData, legacyErr := storecrypt.ReadFile(legacyPath)
 		if legacyErr == nil && len(bytes.TrimSpace(legacyData)) > 0 {
 			return legacyData, nil
 		}
@@ -148,7 +208,7 @@ func loadRawLocked(path, provider string) ([]byte, error) {
 }
 
 func loadStoreForWriteLocked(path, provider string) (*fileStore, error) {
-	data, err := os.ReadFile(path)
+	data, err := storecrypt.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return newStore(), nil
@@ -172,7 +232,7 @@ func loadStoreForWriteLocked(path, provider string) (*fileStore, error) {
 }
 
 func deleteFromStoreLocked(path, provider string) error {
-	data, err := os.ReadFile(path)
+	data, err := storecrypt.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil
@@ -196,7 +256,7 @@ func deleteFromStoreLocked(path, provider string) error {
 		return writeStoreLocked(path, &store)
 	}
 
-	if samePath(path, paths.GetLegacyTokenPath(provider)) {
+	if samePath(path, legacyTokenPath(path, provider)) {
 		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 			return fmt.Errorf("failed to remove token file %s: %w", path, err)
 		}
@@ -215,22 +275,18 @@ func writeStoreLocked(path string, store *fileStore) error {
 		store.Version = currentVersion
 	}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
-		return fmt.Errorf("failed to create token directory: %w", err)
-	}
-
 	data, err := json.MarshalIndent(store, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal token store: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0600); err != nil {
-		return fmt.Errorf("failed to write token file: %w", err)
+	if data, err = storecrypt.Seal(data); err != nil {
+		return fmt.Errorf("failed to encrypt token store: %w", err)
 	}
-	return nil
+	return secretstore.WriteFileAtomic(path, data, 0600)
 }
 
 func removeLegacyFileLocked(currentPath, provider string) error {
-	legacyPath := paths.GetLegacyTokenPath(provider)
+	legacyPath := legacyTokenPath(currentPath, provider)
 	if samePath(currentPath, legacyPath) {
 		return nil
 	}
@@ -280,6 +336,20 @@ func newStore() *fileStore {
 	}
 }
 
+// secretKey 返回 token 在 secret store 中的条目名；profile 目录下的 token 文件带上 profile 前缀，
+// 避免多个 profile（如 MCP 多租户）共用同一条目
+func secretKey(path, provider string) string {
+	if profile := paths.ProfileOfPath(path); profile != "" {
+		return paths.ProfilesDir + "/" + profile + "/" + secretKeyPrefix + provider
+	}
+	return secretKeyPrefix + provider
+}
+
+// legacyTokenPath 返回与 token 文件同目录的历史独立 token 文件，如 credentials/google_token.json
+func legacyTokenPath(path, provider string) string {
+	return filepath.Join(filepath.Dir(path), provider+"_token.json")
+}
+
 func normalizeInput(path, provider string) (string, string, error) {
 	tokenPath := strings.TrimSpace(path)
 	if tokenPath == "" {
@@ -299,7 +369,7 @@ func samePath(a, b string) bool {
 }
 
 func canUseDirectPayload(path, provider string) bool {
-	if samePath(path, paths.GetLegacyTokenPath(provider)) {
+	if samePath(path, legacyTokenPath(path, provider)) {
 		return true
 	}
 	return !samePath(path, paths.GetTokenPath(provider))
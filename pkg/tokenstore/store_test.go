@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/yeisme/taskbridge/pkg/paths"
+	"github.com/yeisme/taskbridge/pkg/secretstore"
 )
 
 func TestSaveLoadDeleteSingleFile(t *testing.T) {
@@ -124,3 +125,66 @@ func TestSaveRemovesLegacyFile(t *testing.T) {
 		t.Fatal("expected todoist token to exist in shared file")
 	}
 }
+
+func TestSecretStoreMigratesTokenFromFile(t *testing.T) {
+	dir := t.TempDir()
+	tokenPath := filepath.Join(dir, "credentials", "tokens.json")
+	if err := Save(tokenPath, "google", map[string]string{"access_token": "old"}); err != nil {
+		t.Fatalf("Save to file failed: %v", err)
+	}
+
+	secrets := secretstore.NewFileStore(filepath.Join(dir, "secrets.json"))
+	UseSecretStore(secrets)
+	t.Cleanup(func() { UseSecretStore(nil) })
+
+	var token map[string]string
+	if err := Load(tokenPath, "google", &token); err != nil || token["access_token"] != "old" {
+		t.Fatalf("expected token from file before migration, got %v err=%v", token, err)
+	}
+
+	if err := Save(tokenPath, "google", map[string]string{"access_token": "new"}); err != nil {
+		t.Fatalf("Save to secret store failed: %v", err)
+	}
+	if _, err := os.Stat(tokenPath); !os.IsNotExist(err) {
+		t.Fatalf("expected token file removed after migration, err=%v", err)
+	}
+	if raw, err := secrets.Get("token/google"); err != nil || raw != `{"access_token":"new"}` {
+		t.Fatalf("unexpected secret store entry %q err=%v", raw, err)
+	}
+
+	if err := Delete(tokenPath, "google"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if has, _ := Has(tokenPath, "google"); has {
+		t.Fatal("expected token deleted from secret store")
+	}
+}
+
+func TestSecretStoreSeparatesProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("TASKBRIDGE_HOME", home)
+
+	secrets := secretstore.NewFileStore(filepath.Join(home, "secrets.json"))
+	UseSecretStore(secrets)
+	t.Cleanup(func() { UseSecretStore(nil) })
+
+	defaultPath := filepath.Join(paths.GetProfileCredentialsDir(""), paths.TokenFileName)
+	tenantPath := filepath.Join(paths.GetProfileCredentialsDir("alice"), paths.TokenFileName)
+	if err := Save(defaultPath, "google", map[string]string{"access_token": "operator"}); err != nil {
+		t.Fatalf("Save default failed: %v", err)
+	}
+	if has, err := Has(tenantPath, "google"); err != nil || has {
+		t.Fatalf("expected no token for profile alice, has=%v err=%v", has, err)
+	}
+
+	if err := Save(tenantPath, "google", map[string]string{"access_token": "alice"}); err != nil {
+		t.Fatalf("Save profile failed: %v", err)
+	}
+	if raw, err := secrets.Get("profiles/alice/token/google"); err != nil || raw != `{"access_token":"alice"}` {
+		t.Fatalf("unexpected profile entry %q err=%v", raw, err)
+	}
+	var token map[string]string
+	if err := Load(defaultPath, "google", &token); err != nil || token["access_token"] != "operator" {
+		t.Fatalf("expected default profile token unchanged, got %v err=%v", token, err)
+	}
+}